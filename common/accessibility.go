@@ -0,0 +1,217 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package common
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+)
+
+// AccessibilitySeverity is the severity of an accessibility issue.
+type AccessibilitySeverity byte
+
+// AccessibilitySeverity constants.
+const (
+	// AccessibilityInfo marks issues that may need a manual review.
+	AccessibilityInfo AccessibilitySeverity = iota
+	// AccessibilityWarning marks issues that make content harder to use.
+	AccessibilityWarning
+	// AccessibilityError marks issues that make content unusable for some
+	// readers.
+	AccessibilityError
+)
+
+func (s AccessibilitySeverity) String() string {
+	switch s {
+	case AccessibilityInfo:
+		return "info"
+	case AccessibilityWarning:
+		return "warning"
+	case AccessibilityError:
+		return "error"
+	}
+	return fmt.Sprintf("AccessibilitySeverity(%d)", s)
+}
+
+// AccessibilityRule identifies the rule an accessibility issue violates.
+type AccessibilityRule string
+
+// AccessibilityRule constants.
+const (
+	// AccessibilityAltText is violated by images, charts and other graphics
+	// without alternative text.
+	AccessibilityAltText AccessibilityRule = "alt-text"
+	// AccessibilityHeadingOrder is violated by headings skipping a level.
+	AccessibilityHeadingOrder AccessibilityRule = "heading-order"
+	// AccessibilityTableHeader is violated by tables without a header row.
+	AccessibilityTableHeader AccessibilityRule = "table-header"
+	// AccessibilityMergedCells is violated by merged cells in data tables.
+	AccessibilityMergedCells AccessibilityRule = "merged-cells"
+	// AccessibilityContrast is violated by text with a low contrast to its
+	// background.
+	AccessibilityContrast AccessibilityRule = "contrast"
+	// AccessibilitySlideTitle is violated by slides without a title.
+	AccessibilitySlideTitle AccessibilityRule = "slide-title"
+	// AccessibilityLinkText is violated by hyperlinks without text.
+	AccessibilityLinkText AccessibilityRule = "link-text"
+	// AccessibilityReadingOrder is violated by shapes whose reading order
+	// differs from their visual order.
+	AccessibilityReadingOrder AccessibilityRule = "reading-order"
+)
+
+// AccessibilityIssue is a problem found by an accessibility check.
+type AccessibilityIssue struct {
+	Rule     AccessibilityRule
+	Severity AccessibilitySeverity
+
+	// Location describes where the issue is, e.g. "body, table 2",
+	// "Sheet1!B4" or "slide 3, shape \"Picture 2\"".
+	Location string
+	Message  string
+}
+
+func (i AccessibilityIssue) String() string {
+	return fmt.Sprintf("%s: %s [%s] %s", i.Location, i.Severity, i.Rule, i.Message)
+}
+
+// MinimumContrast returns the minimum contrast ratio WCAG 2 level AA requires
+// for text of the given size in points. Large text, 18pt or 14pt bold, needs a
+// lower contrast.
+func MinimumContrast(size float64, bold bool) float64 {
+	if size >= 18 || bold && size >= 14 {
+		return 3
+	}
+	return 4.5
+}
+
+// ContrastRatio returns the WCAG 2 contrast ratio of two colors given as
+// RRGGBB hex strings, between 1 and 21.
+func ContrastRatio(fg, bg string) (float64, bool) {
+	l1, ok1 := relativeLuminance(fg)
+	l2, ok2 := relativeLuminance(bg)
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05), true
+}
+
+func relativeLuminance(hex string) (float64, bool) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) == 8 {
+		// ARGB as used by spreadsheets
+		hex = hex[2:]
+	}
+	if len(hex) != 6 {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	channel := func(c uint64) float64 {
+		f := float64(c) / 255
+		if f <= 0.03928 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(v>>16&0xff) + 0.7152*channel(v>>8&0xff) + 0.0722*channel(v&0xff), true
+}
+
+// ThemeColor returns the RRGGBB value of a color of the color scheme of theme
+// by its name, such as "dk1", "accent2" or "hlink". The text and background
+// names "tx1", "bg1", "tx2" and "bg2" map to "dk1", "lt1", "dk2" and "lt2".
+func ThemeColor(theme *dml.Theme, name string) (string, bool) {
+	if theme == nil || theme.ThemeElements == nil || theme.ThemeElements.ClrScheme == nil {
+		return "", false
+	}
+	cs := theme.ThemeElements.ClrScheme
+	var c *dml.CT_Color
+	switch strings.ToLower(name) {
+	case "dk1", "tx1", "text1", "dark1":
+		c = cs.Dk1
+	case "lt1", "bg1", "background1", "light1":
+		c = cs.Lt1
+	case "dk2", "tx2", "text2", "dark2":
+		c = cs.Dk2
+	case "lt2", "bg2", "background2", "light2":
+		c = cs.Lt2
+	case "accent1":
+		c = cs.Accent1
+	case "accent2":
+		c = cs.Accent2
+	case "accent3":
+		c = cs.Accent3
+	case "accent4":
+		c = cs.Accent4
+	case "accent5":
+		c = cs.Accent5
+	case "accent6":
+		c = cs.Accent6
+	case "hlink", "hyperlink":
+		c = cs.Hlink
+	case "folhlink", "followedhyperlink":
+		c = cs.FolHlink
+	}
+	switch {
+	case c == nil:
+		return "", false
+	case c.SrgbClr != nil:
+		return c.SrgbClr.ValAttr, true
+	case c.SysClr != nil && c.SysClr.LastClrAttr != nil:
+		return *c.SysClr.LastClrAttr, true
+	}
+	return "", false
+}
+
+// SolidFillColor returns the RRGGBB value of a solid fill, resolving scheme
+// colors with theme. Color transforms are not applied.
+func SolidFillColor(theme *dml.Theme, fill *dml.CT_SolidColorFillProperties) (string, bool) {
+	switch {
+	case fill == nil:
+		return "", false
+	case fill.SrgbClr != nil:
+		return fill.SrgbClr.ValAttr, true
+	case fill.SysClr != nil && fill.SysClr.LastClrAttr != nil:
+		return *fill.SysClr.LastClrAttr, true
+	case fill.SchemeClr != nil:
+		return ThemeColor(theme, fill.SchemeClr.ValAttr.String())
+	}
+	return "", false
+}
+
+// decorativeExtURI is the URI of the extension marking graphics as decorative.
+const decorativeExtURI = "{C183D7F6-B498-43B3-948B-1728B52AA6E4}"
+
+// HasAltText reports whether a graphic has alternative text or is marked as
+// decorative, which doesn't need any.
+func HasAltText(pr *dml.CT_NonVisualDrawingProps) bool {
+	if pr == nil {
+		return false
+	}
+	if pr.DescrAttr != nil && strings.TrimSpace(*pr.DescrAttr) != "" {
+		return true
+	}
+	if pr.ExtLst != nil {
+		for _, ext := range pr.ExtLst.Ext {
+			if ext.UriAttr == decorativeExtURI {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,386 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package common
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/internal/mscfb"
+)
+
+// Relationship and content types of embedded objects.
+const (
+	PackageType           = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/package"
+	OLEObjectType         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/oleObject"
+	OLEObjectContentType  = "application/vnd.openxmlformats-officedocument.oleObject"
+	OLEPackageProgID      = "Package"
+	embeddedObjectsFolder = "/embeddings/"
+)
+
+// packageCLSID is the class ID of OLE packages,
+// {0003000C-0000-0000-C000-000000000046}.
+var packageCLSID = [16]byte{0x0C, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}
+
+// embeddedPackage describes an Office file type that is embedded as a package
+// rather than wrapped in an OLE object.
+type embeddedPackage struct {
+	ext, progID, name, contentType string
+	fill                           color.RGBA
+}
+
+var embeddedPackages = []embeddedPackage{
+	{"xlsx", "Excel.Sheet.12", "Microsoft_Excel_Worksheet", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", color.RGBA{0x21, 0x73, 0x46, 0xFF}},
+	{"docx", "Word.Document.12", "Microsoft_Word_Document", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", color.RGBA{0x2B, 0x57, 0x9A, 0xFF}},
+	{"pptx", "PowerPoint.Show.12", "Microsoft_PowerPoint_Presentation", "application/vnd.openxmlformats-officedocument.presentationml.presentation", color.RGBA{0xB7, 0x47, 0x2A, 0xFF}},
+}
+
+func findEmbeddedPackage(fileName string) (embeddedPackage, bool) {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(fileName), "."))
+	for _, p := range embeddedPackages {
+		if p.ext == ext {
+			return p, true
+		}
+	}
+	return embeddedPackage{}, false
+}
+
+// EmbeddedObject is an object embedded in a document, either an Office file
+// stored as a package or an OLE object stored as a compound file.
+type EmbeddedObject struct {
+	// ZipPath is the path of the embedded part within the package.
+	ZipPath string
+	// ProgID identifies the application handling the object, e.g.
+	// "Excel.Sheet.12" or "Package".
+	ProgID string
+	// FileName is the original file name of the object if it is known and
+	// the name of the embedded part otherwise.
+	FileName string
+	// Data is the embedded file: the Office file of a package, the file
+	// wrapped by an OLE package or the compound file of other OLE objects.
+	Data []byte
+}
+
+// IsOLE reports whether the object is stored as an OLE compound file.
+func (e EmbeddedObject) IsOLE() bool {
+	return strings.EqualFold(path.Ext(e.ZipPath), ".bin")
+}
+
+// RelationshipType returns the type of the relationship from the part
+// displaying the object to the object.
+func (e EmbeddedObject) RelationshipType() string {
+	if e.IsOLE() {
+		return OLEObjectType
+	}
+	return PackageType
+}
+
+// EmbedOptions controls how an embedded object is displayed.
+type EmbedOptions struct {
+	// ShowAsIcon displays the object as an icon rather than as its content.
+	ShowAsIcon bool
+	// Preview is the image displayed for the object. If nil, a generic icon
+	// is generated with EmbeddingIcon.
+	Preview *Image
+	// AsPackage wraps Office files in an OLE package instead of embedding
+	// them as packages, which opens them with the default application for
+	// their file type.
+	AsPackage bool
+}
+
+// ProgIDForFile returns the ProgID an object embedded from a file with the
+// given name is stored with.
+func ProgIDForFile(fileName string) string {
+	if p, ok := findEmbeddedPackage(fileName); ok {
+		return p.progID
+	}
+	return OLEPackageProgID
+}
+
+// AddEmbeddedObject stores data in the embeddings folder below dir ("word",
+// "xl" or "ppt") and returns the object to add a relationship to. Office files
+// are stored as packages unless asPackage is set, other files are wrapped in
+// an OLE package.
+func (d *DocBase) AddEmbeddedObject(dir, fileName string, data []byte, asPackage bool) (EmbeddedObject, error) {
+	obj := EmbeddedObject{ProgID: OLEPackageProgID, FileName: fileName, Data: data}
+	p, ok := findEmbeddedPackage(fileName)
+	if ok && !asPackage {
+		obj.ProgID = p.progID
+		obj.ZipPath = d.nextEmbeddingPath(dir, p.name, p.ext)
+		d.ContentTypes.EnsureDefault(p.ext, p.contentType)
+		return obj, d.AddExtraFileFromBytes(obj.ZipPath, data)
+	}
+	ole, err := EncodeOLEPackage(fileName, data)
+	if err != nil {
+		return EmbeddedObject{}, err
+	}
+	obj.ZipPath = d.nextEmbeddingPath(dir, "oleObject", "bin")
+	d.ContentTypes.EnsureDefault("bin", OLEObjectContentType)
+	return obj, d.AddExtraFileFromBytes(obj.ZipPath, ole)
+}
+
+func (d *DocBase) nextEmbeddingPath(dir, name, ext string) string {
+	for n := 1; ; n++ {
+		zipPath := fmt.Sprintf("%s/embeddings/%s%d.%s", dir, name, n, ext)
+		if !d.HasExtraFile(zipPath) {
+			return zipPath
+		}
+	}
+}
+
+// EmbeddedObjects returns the objects stored in the embeddings folders of the
+// package, including the workbooks holding chart data. OLE objects are
+// decoded with DecodeOLEObject.
+func (d *DocBase) EmbeddedObjects() ([]EmbeddedObject, error) {
+	objs := []EmbeddedObject{}
+	for _, ef := range d.ExtraFiles {
+		if !strings.Contains(ef.ZipPath, embeddedObjectsFolder) {
+			continue
+		}
+		data, err := d.ExtraFileBytes(ef.ZipPath)
+		if err != nil {
+			return nil, err
+		}
+		obj := EmbeddedObject{ZipPath: ef.ZipPath, ProgID: ProgIDForFile(ef.ZipPath), FileName: path.Base(ef.ZipPath), Data: data}
+		if obj.IsOLE() {
+			progID, fileName, payload, err := DecodeOLEObject(data)
+			if err != nil {
+				return nil, fmt.Errorf("decoding %s: %w", ef.ZipPath, err)
+			}
+			obj.ProgID, obj.Data = progID, payload
+			if fileName != "" {
+				obj.FileName = fileName
+			}
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// EncodeOLEPackage wraps the file data named fileName in an OLE package
+// compound file with an Ole10Native stream.
+func EncodeOLEPackage(fileName string, data []byte) ([]byte, error) {
+	name := path.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	native := bytes.Buffer{}
+	binary.Write(&native, binary.LittleEndian, uint16(2))
+	writeZString(&native, name)
+	writeZString(&native, fileName)
+	binary.Write(&native, binary.LittleEndian, uint32(0x00030000))
+	binary.Write(&native, binary.LittleEndian, uint32(len(fileName)+1))
+	writeZString(&native, fileName)
+	binary.Write(&native, binary.LittleEndian, uint32(len(data)))
+	native.Write(data)
+	ole10 := make([]byte, 4, native.Len()+4)
+	binary.LittleEndian.PutUint32(ole10, uint32(native.Len()))
+	ole10 = append(ole10, native.Bytes()...)
+
+	compObj := bytes.Buffer{}
+	binary.Write(&compObj, binary.LittleEndian, []uint32{0xFFFE0001, 0x00000A03, 0xFFFFFFFF})
+	compObj.Write(packageCLSID[:])
+	writeLengthPrefixed(&compObj, "OLE Package")
+	binary.Write(&compObj, binary.LittleEndian, uint32(0))
+	writeLengthPrefixed(&compObj, OLEPackageProgID)
+	binary.Write(&compObj, binary.LittleEndian, []uint32{0x71B239F4, 0, 0, 0})
+
+	ole := make([]byte, 20)
+	binary.LittleEndian.PutUint32(ole, 0x02000001)
+
+	buf := bytes.Buffer{}
+	err := mscfb.Write(&buf, packageCLSID,
+		mscfb.Stream{Name: "\x01Ole10Native", Data: ole10},
+		mscfb.Stream{Name: "\x01CompObj", Data: compObj.Bytes()},
+		mscfb.Stream{Name: "\x01Ole", Data: ole})
+	return buf.Bytes(), err
+}
+
+// DecodeOLEObject reads the ProgID of the OLE object compound file data and
+// extracts its content. The file wrapped by OLE packages is returned with its
+// original file name, the Package and CONTENTS streams of other objects are
+// returned if present and the compound file itself otherwise.
+func DecodeOLEObject(data []byte) (progID, fileName string, payload []byte, err error) {
+	r, err := mscfb.New(bytes.NewReader(data))
+	if err != nil {
+		return "", "", nil, err
+	}
+	streams := map[string][]byte{}
+	for {
+		f, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", "", nil, err
+		}
+		// the reader drops the control character prefix of names such as
+		// \x01CompObj
+		switch f.Name {
+		case "CompObj", "Ole10Native", "Package", "CONTENTS":
+			b, err := io.ReadAll(f)
+			if err != nil {
+				return "", "", nil, err
+			}
+			streams[f.Name] = b
+		}
+	}
+	progID = compObjProgID(streams["CompObj"])
+	if b, ok := streams["Ole10Native"]; ok {
+		fileName, payload = decodeOle10Native(b)
+		if progID == "" {
+			progID = OLEPackageProgID
+		}
+		return progID, fileName, payload, nil
+	}
+	for _, name := range []string{"Package", "CONTENTS"} {
+		if b, ok := streams[name]; ok {
+			return progID, "", b, nil
+		}
+	}
+	return progID, "", data, nil
+}
+
+// decodeOle10Native returns the file name and content of an Ole10Native
+// stream, or no name and the raw native data if it doesn't hold a packaged
+// file.
+func decodeOle10Native(b []byte) (string, []byte) {
+	if len(b) < 4 {
+		return "", nil
+	}
+	b = b[4:]
+	raw := b
+	if len(b) < 2 {
+		return "", raw
+	}
+	b = b[2:]
+	label, b, ok := readZString(b)
+	if !ok {
+		return "", raw
+	}
+	filePath, b, ok := readZString(b)
+	if !ok || len(b) < 8 {
+		return "", raw
+	}
+	n := int(binary.LittleEndian.Uint32(b[4:]))
+	b = b[8:]
+	if n > len(b) {
+		return "", raw
+	}
+	b = b[n:]
+	if len(b) < 4 {
+		return "", raw
+	}
+	n = int(binary.LittleEndian.Uint32(b))
+	b = b[4:]
+	if n > len(b) {
+		return "", raw
+	}
+	if label == "" {
+		label = path.Base(strings.ReplaceAll(filePath, "\\", "/"))
+	}
+	return label, b[:n]
+}
+
+// compObjProgID returns the ProgID stored in a CompObj stream.
+func compObjProgID(b []byte) string {
+	if len(b) < 28 {
+		return ""
+	}
+	b = b[28:]
+	// user type
+	_, b, ok := readLengthPrefixed(b)
+	if !ok || len(b) < 4 {
+		return ""
+	}
+	// clipboard format
+	switch marker := binary.LittleEndian.Uint32(b); marker {
+	case 0:
+		b = b[4:]
+	case 0xFFFFFFFF, 0xFFFFFFFE:
+		if len(b) < 8 {
+			return ""
+		}
+		b = b[8:]
+	default:
+		if _, b, ok = readLengthPrefixed(b); !ok {
+			return ""
+		}
+	}
+	progID, _, _ := readLengthPrefixed(b)
+	return progID
+}
+
+func writeZString(b *bytes.Buffer, s string) {
+	b.WriteString(s)
+	b.WriteByte(0)
+}
+
+func writeLengthPrefixed(b *bytes.Buffer, s string) {
+	binary.Write(b, binary.LittleEndian, uint32(len(s)+1))
+	writeZString(b, s)
+}
+
+func readZString(b []byte) (string, []byte, bool) {
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return "", nil, false
+	}
+	return string(b[:i]), b[i+1:], true
+}
+
+func readLengthPrefixed(b []byte) (string, []byte, bool) {
+	if len(b) < 4 {
+		return "", nil, false
+	}
+	n := int(binary.LittleEndian.Uint32(b))
+	b = b[4:]
+	if n > len(b) {
+		return "", nil, false
+	}
+	return strings.TrimRight(string(b[:n]), "\x00"), b[n:], true
+}
+
+// EmbeddingIcon returns a generic page icon for an object embedded from a
+// file with the given name, colored by the application of Office files.
+func EmbeddingIcon(fileName string) (Image, error) {
+	const w, h, fold = 64, 80, 18
+	band := color.RGBA{0x70, 0x70, 0x70, 0xFF}
+	if p, ok := findEmbeddedPackage(fileName); ok {
+		band = p.fill
+	}
+	border := color.RGBA{0x90, 0x90, 0x90, 0xFF}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			switch {
+			case x-(w-fold) > y:
+				// cut off corner stays transparent
+			case x == 0 || y == h-1 || x == w-1 || y == 0 || x-(w-fold) == y:
+				img.Set(x, y, border)
+			case x == w-fold && y <= fold || y == fold && x >= w-fold:
+				img.Set(x, y, border)
+			default:
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+	draw.Draw(img, image.Rect(1, h-24, w-1, h-8), image.NewUniform(band), image.Point{}, draw.Src)
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, img); err != nil {
+		return Image{}, err
+	}
+	return ImageFromBytes(buf.Bytes())
+}
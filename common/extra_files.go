@@ -0,0 +1,75 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package common
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/unidoc/unioffice/v2/common/tempstorage"
+)
+
+// AddExtraFileFromBytes stores data in the temporary storage and adds it to
+// the package as the file zipPath, replacing a file with the same path.
+func (d *DocBase) AddExtraFileFromBytes(zipPath string, data []byte) error {
+	f, err := tempstorage.TempFile(d.TmpPath, "zz")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	for i, ef := range d.ExtraFiles {
+		if ef.ZipPath == zipPath {
+			d.ExtraFiles[i].StoragePath = f.Name()
+			return nil
+		}
+	}
+	d.ExtraFiles = append(d.ExtraFiles, ExtraFile{ZipPath: zipPath, StoragePath: f.Name()})
+	return nil
+}
+
+// HasExtraFile reports whether the package contains the extra file zipPath.
+func (d *DocBase) HasExtraFile(zipPath string) bool {
+	for _, ef := range d.ExtraFiles {
+		if ef.ZipPath == zipPath {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveExtraFile removes the extra file zipPath from the package.
+func (d *DocBase) RemoveExtraFile(zipPath string) {
+	files := d.ExtraFiles[:0]
+	for _, ef := range d.ExtraFiles {
+		if ef.ZipPath != zipPath {
+			files = append(files, ef)
+		}
+	}
+	d.ExtraFiles = files
+}
+
+// ExtraFileBytes returns the content of the extra file zipPath.
+func (d *DocBase) ExtraFileBytes(zipPath string) ([]byte, error) {
+	for _, ef := range d.ExtraFiles {
+		if ef.ZipPath != zipPath {
+			continue
+		}
+		f, err := tempstorage.Open(ef.StoragePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}
+	return nil, fmt.Errorf("extra file %s not found", zipPath)
+}
@@ -0,0 +1,31 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package common
+
+import (
+	"testing"
+)
+
+func TestRemoveExtraFile(t *testing.T) {
+	d := &DocBase{}
+	for _, zipPath := range []string{"word/fonts/font1.odttf", "word/fonts/font2.odttf"} {
+		if err := d.AddExtraFileFromBytes(zipPath, []byte(zipPath)); err != nil {
+			t.Fatalf("AddExtraFileFromBytes: %s", err)
+		}
+	}
+	d.RemoveExtraFile("word/fonts/font1.odttf")
+	d.RemoveExtraFile("word/fonts/missing.odttf")
+	if d.HasExtraFile("word/fonts/font1.odttf") {
+		t.Errorf("expected font1.odttf to be removed")
+	}
+	if data, err := d.ExtraFileBytes("word/fonts/font2.odttf"); err != nil || string(data) != "word/fonts/font2.odttf" {
+		t.Errorf("expected font2.odttf to be kept, got %q, %v", data, err)
+	}
+}
@@ -0,0 +1,506 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package common
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/common/tempstorage"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/pkg/relationships"
+)
+
+// ImageOptimizeOptions controls OptimizeImages.
+type ImageOptimizeOptions struct {
+	// DPI is the resolution images are downsampled to, based on the largest
+	// size they are displayed at. Zero uses 150, a negative value disables
+	// downsampling.
+	DPI float64
+
+	// JPEGQuality is the quality of recompressed JPEG images, 80 if zero.
+	JPEGQuality int
+
+	// PNGToJPEG converts opaque PNG images to JPEG if that at least halves
+	// their size. TIFF and BMP images are always converted to PNG or JPEG.
+	PNGToJPEG bool
+
+	// KeepCropped disables removing the cropped-away parts of images.
+	KeepCropped bool
+
+	// KeepDuplicates disables merging identical images.
+	KeepDuplicates bool
+}
+
+// ImageOptimizeResult reports the changes made by OptimizeImages.
+type ImageOptimizeResult struct {
+	Images     int   // number of images before optimizing
+	Changed    int   // number of images recompressed, resized or converted
+	Removed    int   // number of duplicate images removed
+	SizeBefore int64 // total size of the images before optimizing
+	SizeAfter  int64 // total size of the images after optimizing
+}
+
+// ImagePlacement is a place an image is displayed at, it is used by
+// OptimizeImages to find the resolution an image is needed in.
+type ImagePlacement struct {
+	Rels          Relationships // the relationships of the part displaying the image
+	RelID         string        // the ID of the image relationship
+	Width, Height int64         // the displayed size in EMU
+	BlipFill      *dml.CT_BlipFillProperties
+}
+
+// OptimizeImages reduces the size of the images of a document. Images are
+// downsampled to the resolution they are displayed at, cropped-away parts are
+// removed, JPEG and PNG images are recompressed, TIFF and BMP images are
+// converted and identical images are merged. Images in other formats are left
+// as is.
+//
+// OptimizeImages is used by the OptimizeImages methods of the document types,
+// which collect the relationships of the loaded parts and the placements of
+// the images. Images are only downsampled or cropped if every relationship
+// to them has a placement. Image relationships in rels and in the
+// relationships parts stored as extra files are renamed when images are
+// renumbered.
+func OptimizeImages(d *DocBase, rels []Relationships, placements []ImagePlacement, opts ImageOptimizeOptions) (ImageOptimizeResult, error) {
+	if opts.DPI == 0 {
+		opts.DPI = 150
+	}
+	if opts.JPEGQuality == 0 {
+		opts.JPEGQuality = 80
+	}
+	res := ImageOptimizeResult{Images: len(d.Images)}
+	names := make([]string, len(d.Images))
+	index := map[string]int{}
+	for i, img := range d.Images {
+		names[i] = imageFileName(i, img)
+		index[names[i]] = i
+	}
+	extra, err := extraRelationships(d)
+	if err != nil {
+		return res, err
+	}
+	all := []Relationships{}
+	for _, r := range rels {
+		if r.X() != nil {
+			all = append(all, r)
+		}
+	}
+	for _, r := range extra {
+		all = append(all, r)
+	}
+
+	uses := make([][]ImagePlacement, len(d.Images))
+	placed := map[placementKey]bool{}
+	for _, p := range placements {
+		if i, ok := index[path.Base(p.Rels.GetTargetByRelId(p.RelID))]; ok {
+			uses[i] = append(uses[i], p)
+			placed[placementKey{p.Rels.X(), p.RelID}] = true
+		}
+	}
+	// images also used where their displayed size and cropping are unknown
+	// are kept at their resolution
+	for _, r := range all {
+		for _, rel := range r.X().Relationship {
+			if !isImageRelationship(rel) || placed[placementKey{r.X(), rel.IdAttr}] {
+				continue
+			}
+			if i, ok := index[path.Base(rel.TargetAttr)]; ok {
+				uses[i] = nil
+			}
+		}
+	}
+
+	data := make([][]byte, len(d.Images))
+	for i := range d.Images {
+		img := &d.Images[i]
+		b, err := imageBytes(*img)
+		if err != nil {
+			return res, err
+		}
+		res.SizeBefore += int64(len(b))
+		out, format, size, err := optimizeImage(b, strings.ToLower(img.Format()), uses[i], opts)
+		if err != nil {
+			return res, err
+		}
+		if out == nil {
+			data[i] = b
+			continue
+		}
+		img._bbg.Data = &out
+		img._bbg.Path = ""
+		img._bbg.Size = size
+		if format != "" {
+			img._bbg.Format = format
+		}
+		data[i] = out
+		res.Changed++
+	}
+
+	renames := map[string]string{}
+	seen := map[[sha256.Size]byte]string{}
+	kept := []ImageRef{}
+	for i, img := range d.Images {
+		sum := sha256.Sum256(data[i])
+		if name, ok := seen[sum]; ok && !opts.KeepDuplicates {
+			renames[names[i]] = name
+			res.Removed++
+			continue
+		}
+		name := fmt.Sprintf("image%d.%s", len(kept)+1, strings.ToLower(img.Format()))
+		renames[names[i]] = name
+		seen[sum] = name
+		if t := img.Target(); t != "" {
+			img.SetTarget(replaceFileName(t, name))
+		}
+		kept = append(kept, img)
+		res.SizeAfter += int64(len(data[i]))
+	}
+	d.Images = kept
+	if err := renameImages(d, rels, extra, renames); err != nil {
+		return res, err
+	}
+	d.ContentTypes.EnsureDefault("png", "image/png")
+	d.ContentTypes.EnsureDefault("jpeg", "image/jpeg")
+	return res, nil
+}
+
+// RemoveImage removes the image with index i from d unless a relationship in
+// rels or in the relationships parts stored as extra files still refers to
+// it, and reports whether it was removed. The media files of the following
+// images are renumbered and the relationships to them renamed.
+func RemoveImage(d *DocBase, rels []Relationships, i int) (bool, error) {
+	if i < 0 || i >= len(d.Images) {
+		return false, fmt.Errorf("image index %d out of range", i)
+	}
+	extra, err := extraRelationships(d)
+	if err != nil {
+		return false, err
+	}
+	removed := imageFileName(i, d.Images[i])
+	for _, r := range rels {
+		if r.X() != nil && refersTo(r, removed) {
+			return false, nil
+		}
+	}
+	for _, r := range extra {
+		if refersTo(r, removed) {
+			return false, nil
+		}
+	}
+	renames := map[string]string{}
+	kept := []ImageRef{}
+	for j, img := range d.Images {
+		if j == i {
+			continue
+		}
+		name := fmt.Sprintf("image%d.%s", len(kept)+1, strings.ToLower(img.Format()))
+		renames[imageFileName(j, img)] = name
+		if t := img.Target(); t != "" {
+			img.SetTarget(replaceFileName(t, name))
+		}
+		kept = append(kept, img)
+	}
+	d.Images = kept
+	return true, renameImages(d, rels, extra, renames)
+}
+
+// refersTo reports whether an image relationship of r targets the media file
+// name.
+func refersTo(r Relationships, name string) bool {
+	for _, rel := range r.X().Relationship {
+		if isImageRelationship(rel) && path.Base(rel.TargetAttr) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// renameImages renames the image relationships of rels and of the extra
+// relationships parts, which are written back if changed, according to
+// renames mapping old to new media file names.
+func renameImages(d *DocBase, rels []Relationships, extra map[string]Relationships, renames map[string]string) error {
+	rename := func(r Relationships) bool {
+		changed := false
+		for _, rel := range r.X().Relationship {
+			if !isImageRelationship(rel) {
+				continue
+			}
+			if name, ok := renames[path.Base(rel.TargetAttr)]; ok && name != path.Base(rel.TargetAttr) {
+				rel.TargetAttr = replaceFileName(rel.TargetAttr, name)
+				changed = true
+			}
+		}
+		return changed
+	}
+	for _, r := range rels {
+		if r.X() != nil {
+			rename(r)
+		}
+	}
+	for zipPath, r := range extra {
+		if !rename(r) {
+			continue
+		}
+		b, err := xml.Marshal(r.X())
+		if err != nil {
+			return err
+		}
+		if err := d.AddExtraFileFromBytes(zipPath, append([]byte(xml.Header), b...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placementKey identifies an image relationship of a part.
+type placementKey struct {
+	rels *relationships.Relationships
+	id   string
+}
+
+func isImageRelationship(rel *relationships.Relationship) bool {
+	return (rel.TypeAttr == unioffice.ImageType || rel.TypeAttr == unioffice.ImageTypeStrict) && !strings.Contains(rel.TargetAttr, "://")
+}
+
+// extraRelationships returns the relationships parts stored as extra files by
+// their path. These belong to parts the document types don't load.
+func extraRelationships(d *DocBase) (map[string]Relationships, error) {
+	extra := map[string]Relationships{}
+	for _, ef := range d.ExtraFiles {
+		if !strings.HasSuffix(ef.ZipPath, ".rels") {
+			continue
+		}
+		b, err := d.ExtraFileBytes(ef.ZipPath)
+		if err != nil {
+			return nil, err
+		}
+		r := relationships.NewRelationships()
+		if err := xml.Unmarshal(b, r); err != nil {
+			return nil, fmt.Errorf("error reading %s: %s", ef.ZipPath, err)
+		}
+		extra[ef.ZipPath] = Relationships{_gag: r}
+	}
+	return extra, nil
+}
+
+// imageFileName returns the name of the media file of the image with index i.
+func imageFileName(i int, img ImageRef) string {
+	if t := img.Target(); t != "" {
+		return path.Base(t)
+	}
+	return fmt.Sprintf("image%d.%s", i+1, strings.ToLower(img.Format()))
+}
+
+func replaceFileName(target, name string) string {
+	if i := strings.LastIndex(target, "/"); i >= 0 {
+		return target[:i+1] + name
+	}
+	return name
+}
+
+func imageBytes(img ImageRef) ([]byte, error) {
+	if img.Data() != nil && len(*img.Data()) > 0 {
+		return *img.Data(), nil
+	}
+	f, err := tempstorage.Open(img.Path())
+	if err != nil {
+		return nil, fmt.Errorf("error reading image: %s", err)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// optimizeImage returns the optimized image data, its format if it changed and
+// its size, or nil data if the image is left as is.
+func optimizeImage(b []byte, format string, uses []ImagePlacement, opts ImageOptimizeOptions) ([]byte, string, image.Point, error) {
+	convert := false
+	switch format {
+	case "jpeg", "jpg", "png":
+	case "bmp", "tiff", "tif":
+		convert = true
+	default:
+		return nil, "", image.Point{}, nil
+	}
+	src, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		// leave images the decoders don't support untouched
+		return nil, "", image.Point{}, nil
+	}
+	changed := convert
+	if !opts.KeepCropped {
+		if cropped, ok := cropImage(src, uses); ok {
+			src = cropped
+			changed = true
+		}
+	}
+	if opts.DPI > 0 {
+		if scaled, ok := downsampleImage(src, uses, opts.DPI); ok {
+			src = scaled
+			changed = true
+		}
+	}
+
+	var out []byte
+	outFormat := ""
+	switch {
+	case format == "jpeg" || format == "jpg":
+		out, err = encodeJPEG(src, opts.JPEGQuality)
+	default:
+		out, err = encodePNG(src)
+		if convert {
+			outFormat = "png"
+		}
+		if err == nil && (convert || opts.PNGToJPEG) && isOpaque(src) {
+			var jpg []byte
+			if jpg, err = encodeJPEG(src, opts.JPEGQuality); err == nil && len(jpg) <= len(out)/2 {
+				out, outFormat, changed = jpg, "jpeg", true
+			}
+		}
+	}
+	if err != nil {
+		return nil, "", image.Point{}, err
+	}
+	if !changed && len(out) >= len(b) {
+		return nil, "", image.Point{}, nil
+	}
+	return out, outFormat, src.Bounds().Size(), nil
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	buf := bytes.Buffer{}
+	err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	return buf.Bytes(), err
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	buf := bytes.Buffer{}
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	err := enc.Encode(&buf, img)
+	return buf.Bytes(), err
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// cropImage removes the cropped-away parts of img if all the placements crop
+// it the same way and resets their source rectangles.
+func cropImage(img image.Image, uses []ImagePlacement) (image.Image, bool) {
+	if len(uses) == 0 {
+		return nil, false
+	}
+	var crop [4]float64
+	for i, u := range uses {
+		c, ok := srcRect(u.BlipFill)
+		if !ok || i > 0 && c != crop {
+			return nil, false
+		}
+		crop = c
+	}
+	if crop == [4]float64{} {
+		return nil, false
+	}
+	for _, c := range crop {
+		if c < 0 {
+			// negative offsets extend the image
+			return nil, false
+		}
+	}
+	b := img.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	r := image.Rect(b.Min.X+int(w*crop[0]), b.Min.Y+int(h*crop[1]), b.Max.X-int(w*crop[2]), b.Max.Y-int(h*crop[3]))
+	if r.Empty() {
+		return nil, false
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+	for _, u := range uses {
+		u.BlipFill.SrcRect = nil
+	}
+	return dst, true
+}
+
+// srcRect returns the left, top, right and bottom crop fractions of a blip
+// fill.
+func srcRect(bf *dml.CT_BlipFillProperties) ([4]float64, bool) {
+	c := [4]float64{}
+	if bf == nil {
+		return c, false
+	}
+	if bf.SrcRect == nil {
+		return c, true
+	}
+	for i, p := range []*dml.ST_Percentage{bf.SrcRect.LAttr, bf.SrcRect.TAttr, bf.SrcRect.RAttr, bf.SrcRect.BAttr} {
+		switch {
+		case p == nil:
+		case p.ST_PercentageDecimal != nil:
+			c[i] = float64(*p.ST_PercentageDecimal) / 100000
+		case p.ST_Percentage != nil:
+			v, err := strconv.ParseFloat(strings.TrimSuffix(*p.ST_Percentage, "%"), 64)
+			if err != nil {
+				return c, false
+			}
+			c[i] = v / 100
+		}
+	}
+	return c, true
+}
+
+// downsampleImage scales img down to the resolution needed for the largest
+// placement.
+func downsampleImage(img image.Image, uses []ImagePlacement, dpi float64) (image.Image, bool) {
+	var w, h int64
+	for _, u := range uses {
+		if u.Width <= 0 || u.Height <= 0 {
+			// the displayed size is unknown
+			return nil, false
+		}
+		if u.Width > w {
+			w = u.Width
+		}
+		if u.Height > h {
+			h = u.Height
+		}
+	}
+	if w == 0 || h == 0 {
+		return nil, false
+	}
+	emuPerInch := float64(measurement.Inch / measurement.EMU)
+	tw, th := float64(w)/emuPerInch*dpi, float64(h)/emuPerInch*dpi
+	b := img.Bounds()
+	scale := math.Max(tw/float64(b.Dx()), th/float64(b.Dy()))
+	if scale >= 0.9 {
+		return nil, false
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, int(math.Ceil(float64(b.Dx())*scale)), int(math.Ceil(float64(b.Dy())*scale))))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst, true
+}
@@ -0,0 +1,142 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package common
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/measurement"
+)
+
+func testPNG(t *testing.T, w, h int) Image {
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			src.Set(x, y, color.RGBA{uint8(x), uint8(y), uint8(x ^ y), 0xff})
+		}
+	}
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatalf("error encoding image: %s", err)
+	}
+	img, err := ImageFromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("error reading image: %s", err)
+	}
+	return img
+}
+
+// testDocBase returns a DocBase with the images added to the relationships
+// rels as media/image1.png, media/image2.png and so on.
+func testDocBase(rels Relationships, imgs ...Image) (*DocBase, []Relationship) {
+	d := &DocBase{ContentTypes: NewContentTypes()}
+	added := []Relationship{}
+	for i, img := range imgs {
+		name := "image" + string(rune('1'+i)) + ".png"
+		added = append(added, rels.AddRelationship("media/"+name, unioffice.ImageType))
+		ref := MakeImageRef(img, d, rels)
+		ref.SetTarget("word/media/" + name)
+		d.Images = append(d.Images, ref)
+	}
+	return d, added
+}
+
+func TestOptimizeImagesDownsample(t *testing.T) {
+	inch := int64(measurement.Inch / measurement.EMU)
+	td := []struct {
+		name     string
+		otherUse bool
+		expW     int
+	}{
+		{"placed", false, 150},
+		{"used elsewhere", true, 400},
+	}
+	for _, tc := range td {
+		main := NewRelationships()
+		d, added := testDocBase(main, testPNG(t, 400, 400))
+		rels := []Relationships{main}
+		if tc.otherUse {
+			other := NewRelationships()
+			other.AddRelationship("media/image1.png", unioffice.ImageType)
+			rels = append(rels, other)
+		}
+		placements := []ImagePlacement{{Rels: main, RelID: added[0].ID(), Width: inch, Height: inch}}
+		if _, err := OptimizeImages(d, rels, placements, ImageOptimizeOptions{}); err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		if w := d.Images[0].Size().X; w != tc.expW {
+			t.Errorf("%s: expected width %d, got %d", tc.name, tc.expW, w)
+		}
+	}
+}
+
+func TestOptimizeImagesRenamesExtraRelationships(t *testing.T) {
+	img := testPNG(t, 16, 16)
+	main := NewRelationships()
+	d, _ := testDocBase(main, img, img)
+	extra := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="` + unioffice.ImageType + `" Target="media/image2.png"/></Relationships>`
+	if err := d.AddExtraFileFromBytes("word/_rels/footnotes.xml.rels", []byte(extra)); err != nil {
+		t.Fatalf("error adding extra file: %s", err)
+	}
+	res, err := OptimizeImages(d, []Relationships{main}, nil, ImageOptimizeOptions{})
+	if err != nil {
+		t.Fatalf("error optimizing: %s", err)
+	}
+	if res.Removed != 1 {
+		t.Errorf("expected 1 duplicate removed, got %d", res.Removed)
+	}
+	b, err := d.ExtraFileBytes("word/_rels/footnotes.xml.rels")
+	if err != nil {
+		t.Fatalf("error reading extra file: %s", err)
+	}
+	if !strings.Contains(string(b), `Target="media/image1.png"`) {
+		t.Errorf("expected the extra relationships to refer to the kept image, got %s", b)
+	}
+	for _, rel := range main.X().Relationship {
+		if rel.TargetAttr != "media/image1.png" {
+			t.Errorf("expected %s to be renamed to media/image1.png", rel.TargetAttr)
+		}
+	}
+}
+
+func TestRemoveImage(t *testing.T) {
+	main := NewRelationships()
+	d, added := testDocBase(main, testPNG(t, 4, 4), testPNG(t, 5, 5), testPNG(t, 6, 6))
+	if removed, err := RemoveImage(d, []Relationships{main}, 0); err != nil || removed {
+		t.Errorf("expected a referenced image to be kept, got %v, %v", removed, err)
+	}
+	main.Remove(added[0])
+	removed, err := RemoveImage(d, []Relationships{main}, 0)
+	if err != nil || !removed {
+		t.Fatalf("expected the image to be removed, got %v, %v", removed, err)
+	}
+	if len(d.Images) != 2 {
+		t.Fatalf("expected 2 images, got %d", len(d.Images))
+	}
+	exp := []string{"media/image1.png", "media/image2.png"}
+	for i, rel := range added[1:] {
+		if rel.Target() != exp[i] {
+			t.Errorf("expected target %s, got %s", exp[i], rel.Target())
+		}
+		if got := d.Images[i].Target(); got != "word/"+exp[i] {
+			t.Errorf("expected image target word/%s, got %s", exp[i], got)
+		}
+	}
+	if d.Images[0].Size().X != 5 {
+		t.Errorf("expected the second image to become the first")
+	}
+}
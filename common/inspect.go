@@ -0,0 +1,269 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package common
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/schema/soo/pkg/relationships"
+)
+
+// InspectionKind is a kind of personal information or hidden data found by
+// inspecting a document.
+type InspectionKind string
+
+// InspectionKind constants.
+const (
+	// InspectionDocumentProperties is the author, last modified by, manager
+	// and company properties and the hyperlink base.
+	InspectionDocumentProperties InspectionKind = "document-properties"
+	// InspectionCustomProperties is the custom document properties.
+	InspectionCustomProperties InspectionKind = "custom-properties"
+	// InspectionCommentAuthors is the names and initials of comment authors.
+	InspectionCommentAuthors InspectionKind = "comment-authors"
+	// InspectionRevisionAuthors is the authors and dates of tracked changes.
+	InspectionRevisionAuthors InspectionKind = "revision-authors"
+	// InspectionRevisionIDs is the revision save IDs (rsids) identifying
+	// editing sessions.
+	InspectionRevisionIDs InspectionKind = "revision-ids"
+	// InspectionPrinterSettings is the printer settings stored with sheets.
+	InspectionPrinterSettings InspectionKind = "printer-settings"
+	// InspectionHiddenText is text formatted as hidden.
+	InspectionHiddenText InspectionKind = "hidden-text"
+	// InspectionHiddenContent is hidden sheets and slides, which are only
+	// reported and never removed.
+	InspectionHiddenContent InspectionKind = "hidden-content"
+	// InspectionThumbnail is the thumbnail image of the document.
+	InspectionThumbnail InspectionKind = "thumbnail"
+	// InspectionPersonalPaths is local and network file paths of external
+	// links and templates, which may contain user names.
+	InspectionPersonalPaths InspectionKind = "personal-paths"
+)
+
+// InspectionFinding is personal information or hidden data found in a
+// document.
+type InspectionFinding struct {
+	Kind InspectionKind
+
+	// Location describes where the data is, e.g. "core properties",
+	// "comment 3" or "Sheet2".
+	Location string
+
+	// Detail describes the data found.
+	Detail string
+
+	// Removed is true if the data was removed.
+	Removed bool
+}
+
+func (f InspectionFinding) String() string {
+	s := string(f.Kind) + ": " + f.Location
+	if f.Detail != "" {
+		s += ": " + f.Detail
+	}
+	if f.Removed {
+		s += " (removed)"
+	}
+	return s
+}
+
+// Inspector collects the findings of a document inspection and decides which
+// of them are removed.
+type Inspector struct {
+	_remove map[InspectionKind]bool
+
+	// Findings are the findings so far.
+	Findings []InspectionFinding
+}
+
+// NewInspector returns an inspector. If remove is true, the given kinds of
+// data, or all kinds if none are given, are removed, otherwise data is only
+// reported.
+func NewInspector(remove bool, kinds ...InspectionKind) *Inspector {
+	i := &Inspector{}
+	if !remove {
+		return i
+	}
+	if len(kinds) == 0 {
+		kinds = []InspectionKind{InspectionDocumentProperties, InspectionCustomProperties, InspectionCommentAuthors,
+			InspectionRevisionAuthors, InspectionRevisionIDs, InspectionPrinterSettings, InspectionHiddenText,
+			InspectionThumbnail, InspectionPersonalPaths}
+	}
+	i._remove = map[InspectionKind]bool{}
+	for _, k := range kinds {
+		if k != InspectionHiddenContent {
+			i._remove[k] = true
+		}
+	}
+	return i
+}
+
+// Removes reports whether data of the given kind is removed.
+func (i *Inspector) Removes(kind InspectionKind) bool { return i._remove[kind] }
+
+// Found records a finding and reports whether the data should be removed.
+func (i *Inspector) Found(kind InspectionKind, loc, detail string) bool {
+	rm := i.Removes(kind)
+	i.Findings = append(i.Findings, InspectionFinding{Kind: kind, Location: loc, Detail: detail, Removed: rm})
+	return rm
+}
+
+// DocBase inspects the core, extended and custom properties, the thumbnail,
+// the printer settings and the package relationships of d.
+func (i *Inspector) DocBase(d *DocBase) error {
+	if cp := d.CoreProperties.X(); cp != nil {
+		if cp.Creator != nil && strings.TrimSpace(d.CoreProperties.Author()) != "" {
+			if i.Found(InspectionDocumentProperties, "core properties", "author "+d.CoreProperties.Author()) {
+				cp.Creator = nil
+			}
+		}
+		if cp.LastModifiedBy != nil && *cp.LastModifiedBy != "" {
+			if i.Found(InspectionDocumentProperties, "core properties", "last modified by "+*cp.LastModifiedBy) {
+				cp.LastModifiedBy = nil
+			}
+		}
+	}
+	if ap := d.AppProperties.X(); ap != nil {
+		for _, p := range []struct {
+			name  string
+			value **string
+		}{{"manager", &ap.Manager}, {"company", &ap.Company}, {"hyperlink base", &ap.HyperlinkBase}} {
+			if *p.value != nil && **p.value != "" {
+				if i.Found(InspectionDocumentProperties, "extended properties", p.name+" "+**p.value) {
+					*p.value = nil
+				}
+			}
+		}
+	}
+	if cp := d.CustomProperties.X(); cp != nil {
+		for _, p := range cp.Property {
+			name := ""
+			if p.NameAttr != nil {
+				name = *p.NameAttr
+			}
+			i.Found(InspectionCustomProperties, "custom properties", name)
+		}
+		if len(cp.Property) > 0 && i.Removes(InspectionCustomProperties) {
+			cp.Property = nil
+		}
+	}
+	if d.Thumbnail != nil && i.Found(InspectionThumbnail, "package", "thumbnail image") {
+		d.RemoveThumbnail()
+	}
+	i.Relationships("package", d.Rels)
+	files := d.ExtraFiles[:0]
+	for _, ef := range d.ExtraFiles {
+		// the printer settings are reported with the relationships to them
+		if strings.Contains(ef.ZipPath, "/printerSettings/") && i.Removes(InspectionPrinterSettings) {
+			d.ContentTypes.RemoveOverride(ef.ZipPath)
+			continue
+		}
+		files = append(files, ef)
+	}
+	d.ExtraFiles = files
+	return nil
+}
+
+// Relationships inspects the targets of external relationships for personal
+// paths, which are reduced to the file name when removed, and relationships to
+// printer settings, which are removed.
+func (i *Inspector) Relationships(loc string, rels Relationships) {
+	if rels.X() == nil {
+		return
+	}
+	for _, rel := range rels.Relationships() {
+		if strings.HasSuffix(rel.Type(), "/printerSettings") {
+			if i.Found(InspectionPrinterSettings, loc, rel.Target()) {
+				rels.Remove(rel)
+			}
+			continue
+		}
+		i.target(loc, rel.X())
+	}
+}
+
+// ExtraRelationships inspects the relationships of the extra files of d for
+// which match returns true, see Relationships.
+func (i *Inspector) ExtraRelationships(d *DocBase, match func(zipPath string) bool) error {
+	for _, ef := range d.ExtraFiles {
+		if !strings.HasSuffix(ef.ZipPath, ".rels") || !match(ef.ZipPath) {
+			continue
+		}
+		data, err := d.ExtraFileBytes(ef.ZipPath)
+		if err != nil {
+			return err
+		}
+		rels := relationships.NewRelationships()
+		if err := xml.Unmarshal(data, rels); err != nil {
+			return err
+		}
+		changed := false
+		for _, rel := range rels.Relationship {
+			changed = i.target(ef.ZipPath, rel) || changed
+		}
+		if !changed {
+			continue
+		}
+		buf := bytes.Buffer{}
+		buf.WriteString(xml.Header)
+		if err := xml.NewEncoder(&buf).Encode(rels); err != nil {
+			return err
+		}
+		if err := d.AddExtraFileFromBytes(ef.ZipPath, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// target inspects the target of an external relationship and reports whether
+// it was changed.
+func (i *Inspector) target(loc string, rel *relationships.Relationship) bool {
+	if rel.TargetModeAttr != relationships.ST_TargetModeExternal || !IsPersonalPath(rel.TargetAttr) {
+		return false
+	}
+	if !i.Found(InspectionPersonalPaths, loc, rel.TargetAttr) {
+		return false
+	}
+	rel.TargetAttr = StripPath(rel.TargetAttr)
+	return true
+}
+
+// IsPersonalPath reports whether target is a local or network file path, as
+// opposed to a web address or a path relative to the document.
+func IsPersonalPath(target string) bool {
+	t := strings.ToLower(strings.ReplaceAll(target, "\\", "/"))
+	switch {
+	case strings.HasPrefix(t, "file:"), strings.HasPrefix(t, "//"), strings.HasPrefix(t, "/"):
+		return true
+	case len(t) > 2 && t[1] == ':' && t[2] == '/':
+		return true
+	}
+	return false
+}
+
+// StripPath returns the file name of a path, dropping the directories.
+func StripPath(target string) string {
+	return path.Base(strings.ReplaceAll(target, "\\", "/"))
+}
+
+// RemoveThumbnail removes the thumbnail of the document and its relationship.
+func (d *DocBase) RemoveThumbnail() {
+	d.Thumbnail = nil
+	for _, rel := range d.Rels.Relationships() {
+		if rel.Type() == unioffice.ThumbnailType || rel.Type() == unioffice.ThumbnailTypeStrict {
+			d.Rels.Remove(rel)
+		}
+	}
+}
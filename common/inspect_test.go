@@ -0,0 +1,127 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/schema/soo/pkg/relationships"
+)
+
+func TestIsPersonalPath(t *testing.T) {
+	td := []struct {
+		target string
+		exp    bool
+	}{
+		{"file:///C:/Users/jdoe/report.docx", true},
+		{`C:\Users\jdoe\report.docx`, true},
+		{`\\server\share\report.docx`, true},
+		{"/home/jdoe/report.docx", true},
+		{"https://example.com/report.docx", false},
+		{"mailto:jdoe@example.com", false},
+		{"media/image1.png", false},
+		{"../report.docx", false},
+	}
+	for _, tc := range td {
+		if got := IsPersonalPath(tc.target); got != tc.exp {
+			t.Errorf("IsPersonalPath(%q): expected %v, got %v", tc.target, tc.exp, got)
+		}
+	}
+}
+
+func TestStripPath(t *testing.T) {
+	td := []struct {
+		target string
+		exp    string
+	}{
+		{"file:///C:/Users/jdoe/report.docx", "report.docx"},
+		{`C:\Users\jdoe\report.docx`, "report.docx"},
+		{`\\server\share\report.docx`, "report.docx"},
+		{"report.docx", "report.docx"},
+	}
+	for _, tc := range td {
+		if got := StripPath(tc.target); got != tc.exp {
+			t.Errorf("StripPath(%q): expected %q, got %q", tc.target, tc.exp, got)
+		}
+	}
+}
+
+func TestInspectorRemoves(t *testing.T) {
+	td := []struct {
+		remove bool
+		kinds  []InspectionKind
+		kind   InspectionKind
+		exp    bool
+	}{
+		{false, nil, InspectionThumbnail, false},
+		{true, nil, InspectionThumbnail, true},
+		{true, nil, InspectionHiddenContent, false},
+		{true, []InspectionKind{InspectionHiddenText}, InspectionHiddenText, true},
+		{true, []InspectionKind{InspectionHiddenText}, InspectionThumbnail, false},
+		{true, []InspectionKind{InspectionHiddenContent}, InspectionHiddenContent, false},
+	}
+	for _, tc := range td {
+		i := NewInspector(tc.remove, tc.kinds...)
+		if got := i.Found(tc.kind, "test", ""); got != tc.exp {
+			t.Errorf("NewInspector(%v, %v).Found(%s): expected %v, got %v", tc.remove, tc.kinds, tc.kind, tc.exp, got)
+		}
+		if len(i.Findings) != 1 || i.Findings[0].Removed != tc.exp {
+			t.Errorf("NewInspector(%v, %v): expected a finding with Removed %v, got %v", tc.remove, tc.kinds, tc.exp, i.Findings)
+		}
+	}
+}
+
+func TestInspectorRelationships(t *testing.T) {
+	for _, remove := range []bool{false, true} {
+		rels := NewRelationships()
+		ext := rels.AddRelationship(`C:\Users\jdoe\linked.xlsx`, unioffice.HyperLinkType)
+		ext.X().TargetModeAttr = relationships.ST_TargetModeExternal
+		rels.AddRelationship("https://example.com", unioffice.HyperLinkType).X().TargetModeAttr = relationships.ST_TargetModeExternal
+		rels.AddRelationship("../printerSettings/printerSettings1.bin", "http://schemas.openxmlformats.org/officeDocument/2006/relationships/printerSettings")
+
+		i := NewInspector(remove)
+		i.Relationships("sheet", rels)
+		if len(i.Findings) != 2 {
+			t.Fatalf("expected 2 findings, got %v", i.Findings)
+		}
+		expTarget, expRels := `C:\Users\jdoe\linked.xlsx`, 3
+		if remove {
+			expTarget, expRels = "linked.xlsx", 2
+		}
+		if got := ext.Target(); got != expTarget {
+			t.Errorf("remove %v: expected target %q, got %q", remove, expTarget, got)
+		}
+		if got := len(rels.Relationships()); got != expRels {
+			t.Errorf("remove %v: expected %d relationships, got %d", remove, expRels, got)
+		}
+	}
+}
+
+func TestInspectorExtraRelationships(t *testing.T) {
+	d := &DocBase{}
+	data := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="` + unioffice.HyperLinkType + `" Target="file:///C:/Users/jdoe/a.docx" TargetMode="External"/></Relationships>`
+	if err := d.AddExtraFileFromBytes("word/_rels/glossary.xml.rels", []byte(data)); err != nil {
+		t.Fatalf("error adding extra file: %s", err)
+	}
+	i := NewInspector(true)
+	if err := i.ExtraRelationships(d, func(string) bool { return true }); err != nil {
+		t.Fatalf("error inspecting: %s", err)
+	}
+	b, err := d.ExtraFileBytes("word/_rels/glossary.xml.rels")
+	if err != nil {
+		t.Fatalf("error reading extra file: %s", err)
+	}
+	if !strings.Contains(string(b), `Target="a.docx"`) || strings.Contains(string(b), "jdoe") {
+		t.Errorf("expected the personal path to be stripped, got %s", b)
+	}
+}
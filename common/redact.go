@@ -0,0 +1,362 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package common
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/ofc/docPropsVTypes"
+)
+
+// RedactOptions selects the text removed by redaction.
+type RedactOptions struct {
+	// Terms are redacted wherever they occur.
+	Terms []string
+
+	// Patterns are regular expressions whose matches are redacted.
+	Patterns []*regexp.Regexp
+
+	// IgnoreCase makes matching Terms case insensitive.
+	IgnoreCase bool
+
+	// Mask replaces every character of redacted text if set, otherwise
+	// redacted text is removed.
+	Mask rune
+}
+
+// Redactor removes or masks text matching a set of terms and patterns. Matches
+// are counted in Count.
+type Redactor struct {
+	_re   []*regexp.Regexp
+	_mask rune
+
+	// Count is the number of matches redacted so far.
+	Count int
+}
+
+// NewRedactor returns a redactor for opts.
+func NewRedactor(opts RedactOptions) (*Redactor, error) {
+	r := &Redactor{_mask: opts.Mask}
+	for _, t := range opts.Terms {
+		if t == "" {
+			continue
+		}
+		expr := regexp.QuoteMeta(t)
+		if opts.IgnoreCase {
+			expr = "(?i)" + expr
+		}
+		r._re = append(r._re, regexp.MustCompile(expr))
+	}
+	for _, p := range opts.Patterns {
+		if p != nil {
+			r._re = append(r._re, p)
+		}
+	}
+	if len(r._re) == 0 {
+		return nil, errors.New("no terms or patterns to redact")
+	}
+	return r, nil
+}
+
+// matches returns the sorted, merged byte ranges of s matching any expression.
+func (r *Redactor) matches(s string) [][2]int {
+	ranges := [][2]int{}
+	for _, re := range r._re {
+		for _, m := range re.FindAllStringIndex(s, -1) {
+			if m[1] > m[0] {
+				ranges = append(ranges, [2]int{m[0], m[1]})
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		return nil
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	merged := ranges[:1]
+	for _, m := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if m[0] <= last[1] {
+			if m[1] > last[1] {
+				last[1] = m[1]
+			}
+			continue
+		}
+		merged = append(merged, m)
+	}
+	r.Count += len(merged)
+	return merged
+}
+
+func (r *Redactor) replacement(s string) string {
+	if r._mask == 0 {
+		return ""
+	}
+	return strings.Repeat(string(r._mask), utf8.RuneCountInString(s))
+}
+
+// String returns s with matching text redacted.
+func (r *Redactor) String(s string) string {
+	ms := r.matches(s)
+	if ms == nil {
+		return s
+	}
+	sb := strings.Builder{}
+	pos := 0
+	for _, m := range ms {
+		sb.WriteString(s[pos:m[0]])
+		sb.WriteString(r.replacement(s[m[0]:m[1]]))
+		pos = m[1]
+	}
+	sb.WriteString(s[pos:])
+	return sb.String()
+}
+
+// StringPtr redacts the string s points to, if s is not nil.
+func (r *Redactor) StringPtr(s *string) {
+	if s != nil {
+		*s = r.String(*s)
+	}
+}
+
+// Segments redacts text split into consecutive segments, such as the text
+// elements of the runs of a paragraph. Matches may span several segments, the
+// redacted part of each segment is removed or masked in place.
+func (r *Redactor) Segments(segs []*string) {
+	if len(segs) == 0 {
+		return
+	}
+	sb := strings.Builder{}
+	for _, s := range segs {
+		sb.WriteString(*s)
+	}
+	all := sb.String()
+	ms := r.matches(all)
+	if ms == nil {
+		return
+	}
+	start := 0
+	for _, s := range segs {
+		end := start + len(*s)
+		out := strings.Builder{}
+		pos := start
+		for _, m := range ms {
+			lo, hi := m[0], m[1]
+			if hi <= start || lo >= end {
+				continue
+			}
+			if lo < start {
+				lo = start
+			}
+			if hi > end {
+				hi = end
+			}
+			out.WriteString(all[pos:lo])
+			out.WriteString(r.replacement(all[lo:hi]))
+			pos = hi
+		}
+		out.WriteString(all[pos:end])
+		*s = out.String()
+		start = end
+	}
+}
+
+// XSDAny redacts the attribute values and character data of an XML element
+// and its children.
+func (r *Redactor) XSDAny(x *unioffice.XSDAny) {
+	if x == nil {
+		return
+	}
+	for i := range x.Attrs {
+		x.Attrs[i].Value = r.String(x.Attrs[i].Value)
+	}
+	if len(x.Data) > 0 {
+		x.Data = []byte(r.String(string(x.Data)))
+	}
+	for _, n := range x.Nodes {
+		r.XSDAny(n)
+	}
+}
+
+// TextBody redacts the runs and fields of the paragraphs of a DrawingML text
+// body, matches may span runs but not paragraphs.
+func (r *Redactor) TextBody(tb *dml.CT_TextBody) {
+	if tb == nil {
+		return
+	}
+	for _, p := range tb.P {
+		segs := []*string{}
+		for _, tr := range p.EG_TextRun {
+			if tr.TextRunChoice == nil {
+				continue
+			}
+			if tr.TextRunChoice.R != nil {
+				segs = append(segs, &tr.TextRunChoice.R.T)
+			}
+			if tr.TextRunChoice.Fld != nil && tr.TextRunChoice.Fld.T != nil {
+				segs = append(segs, tr.TextRunChoice.Fld.T)
+			}
+		}
+		r.Segments(segs)
+	}
+}
+
+// DrawingProps redacts the name, description and title of a drawing object.
+func (r *Redactor) DrawingProps(pr *dml.CT_NonVisualDrawingProps) {
+	if pr == nil {
+		return
+	}
+	pr.NameAttr = r.String(pr.NameAttr)
+	r.StringPtr(pr.DescrAttr)
+	r.StringPtr(pr.TitleAttr)
+}
+
+// XML redacts the character data and the attribute values of a serialized
+// XML document. Namespace declarations, comments and processing instructions
+// are kept, empty elements are written with end tags.
+func (r *Redactor) XML(data []byte) ([]byte, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	buf := bytes.Buffer{}
+	enc := xml.NewEncoder(&buf)
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			t.Name = rawName(t.Name)
+			attrs := make([]xml.Attr, len(t.Attr))
+			for i, a := range t.Attr {
+				if a.Name.Space != "xmlns" && !(a.Name.Space == "" && a.Name.Local == "xmlns") {
+					a.Value = r.String(a.Value)
+				}
+				a.Name = rawName(a.Name)
+				attrs[i] = a
+			}
+			t.Attr = attrs
+			tok = t
+		case xml.EndElement:
+			t.Name = rawName(t.Name)
+			tok = t
+		case xml.CharData:
+			tok = xml.CharData(r.String(string(t)))
+		}
+		if err := enc.EncodeToken(tok); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rawName returns n with its prefix moved into the local name, so that the
+// encoder writes the name as it was read instead of declaring a namespace.
+func rawName(n xml.Name) xml.Name {
+	if n.Space == "" {
+		return n
+	}
+	return xml.Name{Local: n.Space + ":" + n.Local}
+}
+
+// DocBase redacts the core, extended and custom document properties and the
+// custom XML parts of d and removes its thumbnail, which may show redacted
+// text.
+func (r *Redactor) DocBase(d *DocBase) error {
+	if cp := d.CoreProperties.X(); cp != nil {
+		r.StringPtr(cp.Category)
+		r.StringPtr(cp.ContentStatus)
+		r.StringPtr(cp.LastModifiedBy)
+		r.StringPtr(cp.Revision)
+		r.StringPtr(cp.Version)
+		for _, x := range []*unioffice.XSDAny{cp.Creator, cp.Description, cp.Identifier, cp.Subject, cp.Title} {
+			r.XSDAny(x)
+		}
+		if cp.Keywords != nil {
+			for _, k := range cp.Keywords.Value {
+				k.Content = r.String(k.Content)
+			}
+		}
+	}
+	if ap := d.AppProperties.X(); ap != nil {
+		r.StringPtr(ap.Template)
+		r.StringPtr(ap.Manager)
+		r.StringPtr(ap.Company)
+		r.StringPtr(ap.HyperlinkBase)
+		if ap.TitlesOfParts != nil {
+			r.vector(ap.TitlesOfParts.Vector)
+		}
+	}
+	if cp := d.CustomProperties.X(); cp != nil {
+		for _, p := range cp.Property {
+			r.StringPtr(p.NameAttr)
+			if pc := p.PropertyChoice; pc != nil {
+				r.StringPtr(pc.Lpstr)
+				r.StringPtr(pc.Lpwstr)
+				r.StringPtr(pc.Bstr)
+			}
+		}
+	}
+	d.RemoveThumbnail()
+	return r.ExtraFiles(d, func(zipPath string) bool {
+		return strings.HasPrefix(zipPath, "customXml/")
+	})
+}
+
+// ExtraFiles redacts the XML files among the extra files of d for which match
+// returns true, see XML.
+func (r *Redactor) ExtraFiles(d *DocBase, match func(zipPath string) bool) error {
+	for _, ef := range d.ExtraFiles {
+		if !strings.HasSuffix(ef.ZipPath, ".xml") || !match(ef.ZipPath) {
+			continue
+		}
+		data, err := d.ExtraFileBytes(ef.ZipPath)
+		if err != nil {
+			return err
+		}
+		count := r.Count
+		redacted, err := r.XML(data)
+		if err != nil {
+			return fmt.Errorf("error redacting %s: %s", ef.ZipPath, err)
+		}
+		if r.Count == count {
+			continue
+		}
+		if err := d.AddExtraFileFromBytes(ef.ZipPath, redacted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Redactor) vector(v *docPropsVTypes.Vector) {
+	if v == nil {
+		return
+	}
+	for _, vc := range v.VectorChoice {
+		r.StringPtr(vc.Lpstr)
+		r.StringPtr(vc.Lpwstr)
+		r.StringPtr(vc.Bstr)
+	}
+}
@@ -0,0 +1,84 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package common
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRedactorString(t *testing.T) {
+	td := []struct {
+		opts RedactOptions
+		in   string
+		exp  string
+		n    int
+	}{
+		{RedactOptions{Terms: []string{"secret"}}, "a secret, another secret", "a , another ", 2},
+		{RedactOptions{Terms: []string{"Secret"}, IgnoreCase: true, Mask: 'x'}, "SECRET plan", "xxxxxx plan", 1},
+		{RedactOptions{Patterns: []*regexp.Regexp{regexp.MustCompile(`\d{3}-\d{4}`)}, Mask: '█'}, "call 555-1234", "call ████████", 1},
+		{RedactOptions{Terms: []string{"ab", "bc"}, Mask: '*'}, "abc", "***", 1},
+	}
+	for _, tc := range td {
+		r, err := NewRedactor(tc.opts)
+		if err != nil {
+			t.Fatalf("error creating redactor: %s", err)
+		}
+		if got := r.String(tc.in); got != tc.exp {
+			t.Errorf("String(%q): expected %q, got %q", tc.in, tc.exp, got)
+		}
+		if r.Count != tc.n {
+			t.Errorf("String(%q): expected %d matches, got %d", tc.in, tc.n, r.Count)
+		}
+	}
+	if _, err := NewRedactor(RedactOptions{Terms: []string{""}}); err == nil {
+		t.Errorf("expected an error for no terms")
+	}
+}
+
+func TestRedactorSegments(t *testing.T) {
+	r, _ := NewRedactor(RedactOptions{Terms: []string{"secret"}, Mask: '*'})
+	a, b, c := "top se", "cr", "et text"
+	r.Segments([]*string{&a, &b, &c})
+	if a != "top **" || b != "**" || c != "** text" {
+		t.Errorf("expected the match to be masked across segments, got %q %q %q", a, b, c)
+	}
+}
+
+func TestRedactorXML(t *testing.T) {
+	td := []struct {
+		in  string
+		exp string
+	}{
+		{`<?xml version="1.0"?><a x="secret">secret &amp; more</a>`,
+			`<?xml version="1.0"?><a x=""> &amp; more</a>`},
+		// entities are decoded before matching
+		{`<a>s&#101;cret</a>`, `<a></a>`},
+		// namespace declarations and prefixes are kept
+		{`<ns:a xmlns:ns="urn:secret" ns:v="secret"><ns:b/></ns:a>`,
+			`<ns:a xmlns:ns="urn:secret" ns:v=""><ns:b></ns:b></ns:a>`},
+		// markup looking like text is not matched
+		{`<secret>x</secret><!-- secret -->`, `<secret>x</secret><!-- secret -->`},
+	}
+	for _, tc := range td {
+		r, _ := NewRedactor(RedactOptions{Terms: []string{"secret"}})
+		got, err := r.XML([]byte(tc.in))
+		if err != nil {
+			t.Fatalf("XML(%q): %s", tc.in, err)
+		}
+		if string(got) != tc.exp {
+			t.Errorf("XML(%q): expected %q, got %q", tc.in, tc.exp, got)
+		}
+	}
+	r, _ := NewRedactor(RedactOptions{Terms: []string{"x"}})
+	if _, err := r.XML([]byte(`<a><b></a>`)); err == nil {
+		t.Errorf("expected an error for malformed XML")
+	}
+}
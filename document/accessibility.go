@@ -0,0 +1,306 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// CheckAccessibility checks the document for accessibility issues: images,
+// charts and other drawings without alternative text, headings skipping a
+// level, data tables without a header row or with merged cells, text with a
+// low contrast to its shading and hyperlinks without text. The body, headers
+// and footers are checked, headings only in the body.
+//
+// Only colors set directly on runs, paragraphs and table cells are checked for
+// contrast, colors inherited from styles are not.
+func (d *Document) CheckAccessibility() []common.AccessibilityIssue {
+	c := accessibilityChecker{_doc: d}
+	if len(d._dde) > 0 {
+		c._theme = d._dde[0]
+	}
+	c.part("body", d.X().Body.EG_BlockLevelElts, true)
+	for i, hdr := range d._ebg {
+		c.part(fmt.Sprintf("header %d", i+1), hdr.EG_BlockLevelElts, false)
+	}
+	for i, ftr := range d._cca {
+		c.part(fmt.Sprintf("footer %d", i+1), ftr.EG_BlockLevelElts, false)
+	}
+	for _, l := range d.Links() {
+		if l.Kind() != LinkKindImage && strings.TrimSpace(l.Text()) == "" {
+			c.add(linkLocationName(l.Location()), common.AccessibilityLinkText, common.AccessibilityError, fmt.Sprintf("hyperlink to %q has no text", l.Target()+l.Anchor()))
+		}
+	}
+	return c._issues
+}
+
+func linkLocationName(loc LinkLocation) string {
+	switch loc {
+	case LinkLocationHeader:
+		return "header"
+	case LinkLocationFooter:
+		return "footer"
+	case LinkLocationFootnote:
+		return "footnote"
+	case LinkLocationEndnote:
+		return "endnote"
+	case LinkLocationComment:
+		return "comment"
+	}
+	return "body"
+}
+
+type accessibilityChecker struct {
+	_doc        *Document
+	_theme      *dml.Theme
+	_issues     []common.AccessibilityIssue
+	_part       string
+	_body       bool
+	_tables     int
+	_paragraphs int
+	_heading    int
+}
+
+func (c *accessibilityChecker) add(loc string, rule common.AccessibilityRule, sev common.AccessibilitySeverity, msg string) {
+	c._issues = append(c._issues, common.AccessibilityIssue{Rule: rule, Severity: sev, Location: loc, Message: msg})
+}
+
+func (c *accessibilityChecker) part(name string, blocks []*wml.EG_BlockLevelElts, body bool) {
+	c._part, c._body, c._tables, c._paragraphs = name, body, 0, 0
+	c.blocks(blocks, "FFFFFF")
+}
+
+func (c *accessibilityChecker) blocks(blocks []*wml.EG_BlockLevelElts, bg string) {
+	for _, ble := range blocks {
+		c.content(ble.BlockLevelEltsChoice.EG_ContentBlockContent, bg)
+	}
+}
+
+func (c *accessibilityChecker) content(cbcs []*wml.EG_ContentBlockContent, bg string) {
+	for _, cbc := range cbcs {
+		if sdt := cbc.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			c.content(sdt.SdtContent.EG_ContentBlockContent, bg)
+		}
+		for _, p := range cbc.ContentBlockContentChoice.P {
+			c.paragraph(p, bg)
+		}
+		for _, tbl := range cbc.ContentBlockContentChoice.Tbl {
+			c.table(tbl, bg)
+		}
+	}
+}
+
+// color returns the RRGGBB value of a color given by value and theme color.
+func (c *accessibilityChecker) color(hex *wml.ST_HexColor, theme wml.ST_ThemeColor) (string, bool) {
+	if theme != wml.ST_ThemeColorUnset {
+		if v, ok := common.ThemeColor(c._theme, theme.String()); ok {
+			return v, true
+		}
+	}
+	if hex != nil && hex.ST_HexColorRGB != nil {
+		return *hex.ST_HexColorRGB, true
+	}
+	return "", false
+}
+
+func (c *accessibilityChecker) shading(shd *wml.CT_Shd, bg string) string {
+	if shd == nil {
+		return bg
+	}
+	if v, ok := c.color(shd.FillAttr, shd.ThemeFillAttr); ok {
+		return v
+	}
+	return bg
+}
+
+func (c *accessibilityChecker) paragraph(p *wml.CT_P, bg string) {
+	c._paragraphs++
+	loc := fmt.Sprintf("%s, paragraph %d", c._part, c._paragraphs)
+	if c._body {
+		if lvl := c._doc.headingLevel(p); lvl > 0 {
+			if lvl > c._heading+1 {
+				c.add(loc, common.AccessibilityHeadingOrder, common.AccessibilityWarning, fmt.Sprintf("heading level %d follows heading level %d", lvl, c._heading))
+			}
+			c._heading = lvl
+		}
+	}
+	if p.PPr != nil {
+		bg = c.shading(p.PPr.Shd, bg)
+	}
+	walkParagraphRuns(p, func(r *wml.CT_R) {
+		c.run(loc, r, bg)
+	})
+}
+
+func (c *accessibilityChecker) run(loc string, r *wml.CT_R, bg string) {
+	text := ""
+	for _, ric := range r.EG_RunInnerContent {
+		if t := ric.RunInnerContentChoice.T; t != nil {
+			text += t.Content
+		}
+		if d := ric.RunInnerContentChoice.Drawing; d != nil {
+			c.drawing(d)
+		}
+	}
+	for _, x := range r.Extra {
+		if acr, ok := x.(*wml.AlternateContentRun); ok && acr.Choice.Drawing != nil {
+			c.drawing(acr.Choice.Drawing)
+		}
+	}
+	if r.RPr == nil || r.RPr.Color == nil || strings.TrimSpace(text) == "" {
+		return
+	}
+	fg, ok := c.color(&r.RPr.Color.ValAttr, r.RPr.Color.ThemeColorAttr)
+	if !ok {
+		return
+	}
+	bg = c.shading(r.RPr.Shd, bg)
+	rp := RunProperties{r.RPr}
+	size := rp.SizeValue()
+	if size == 0 {
+		size = 11
+	}
+	if ratio, ok := common.ContrastRatio(fg, bg); ok && ratio < common.MinimumContrast(size, rp.IsBold()) {
+		c.add(loc, common.AccessibilityContrast, common.AccessibilityWarning, fmt.Sprintf("text %q has a contrast ratio of %.2f:1", text, ratio))
+	}
+}
+
+// drawing checks that the graphics of d other than text boxes have
+// alternative text.
+func (c *accessibilityChecker) drawing(d *wml.CT_Drawing) {
+	for _, dc := range d.DrawingChoice {
+		var pr *dml.CT_NonVisualDrawingProps
+		var g *dml.Graphic
+		switch {
+		case dc.Inline != nil:
+			pr, g = dc.Inline.DocPr, dc.Inline.Graphic
+		case dc.Anchor != nil:
+			pr, g = dc.Anchor.DocPr, dc.Anchor.Graphic
+		default:
+			continue
+		}
+		if pr == nil || g == nil || g.GraphicData == nil {
+			continue
+		}
+		textBox := false
+		for _, a := range g.GraphicData.Any {
+			if wsp, ok := a.(*wml.WdWsp); ok && wsp.WordprocessingShapeChoice1 != nil && wsp.WordprocessingShapeChoice1.Txbx != nil {
+				textBox = true
+			}
+		}
+		if !textBox && !common.HasAltText(pr) {
+			c.add(fmt.Sprintf("%s, drawing %q", c._part, pr.NameAttr), common.AccessibilityAltText, common.AccessibilityError, "drawing has no alternative text")
+		}
+	}
+}
+
+// table checks data tables, having more than one row and column, for a
+// header row and merged cells and checks the content of all tables.
+func (c *accessibilityChecker) table(tbl *wml.CT_Tbl, bg string) {
+	c._tables++
+	loc := fmt.Sprintf("%s, table %d", c._part, c._tables)
+	rows := []*wml.CT_Row{}
+	var collect func(rcs []*wml.EG_ContentRowContent)
+	collect = func(rcs []*wml.EG_ContentRowContent) {
+		for _, rc := range rcs {
+			if sdt := rc.ContentRowContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+				collect(sdt.SdtContent.EG_ContentRowContent)
+			}
+			rows = append(rows, rc.ContentRowContentChoice.Tr...)
+		}
+	}
+	collect(tbl.EG_ContentRowContent)
+	cols := 0
+	if tbl.TblGrid != nil {
+		cols = len(tbl.TblGrid.GridCol)
+	}
+	if len(rows) > 1 && cols > 1 {
+		header := false
+		if tr := rows[0].TrPr; tr != nil {
+			for _, ch := range tr.TrPrBaseChoice {
+				if ch.TblHeader != nil {
+					header = true
+				}
+			}
+		}
+		if !header {
+			c.add(loc, common.AccessibilityTableHeader, common.AccessibilityWarning, "table has no header row")
+		}
+		merged := false
+		walkTableCells(tbl, func(tc *wml.CT_Tc) {
+			if pr := tc.TcPr; pr != nil && (pr.GridSpan != nil && pr.GridSpan.ValAttr > 1 || pr.VMerge != nil) {
+				merged = true
+			}
+		})
+		if merged {
+			c.add(loc, common.AccessibilityMergedCells, common.AccessibilityWarning, "table has merged cells")
+		}
+	}
+	walkTableCells(tbl, func(tc *wml.CT_Tc) {
+		cbg := bg
+		if tc.TcPr != nil {
+			cbg = c.shading(tc.TcPr.Shd, bg)
+		}
+		c.blocks(tc.EG_BlockLevelElts, cbg)
+	})
+}
+
+// HeadingLevel returns the heading level of the paragraph from 1 to 9, or 0 if
+// it is not a heading. The level is taken from the outline level of the
+// paragraph or its style, or from the name of a built-in heading style.
+func (p Paragraph) HeadingLevel() int { return p._dfgee.headingLevel(p._efcg) }
+
+// headingLevel returns the heading level of p from 1 to 9, or 0 if p is not a
+// heading. The level is taken from the outline level of the paragraph or its
+// style, or from the name of a built-in heading style.
+func (d *Document) headingLevel(p *wml.CT_P) int {
+	outline := func(n *wml.CT_DecimalNumber) int {
+		if n.ValAttr >= 0 && n.ValAttr < 9 {
+			return int(n.ValAttr) + 1
+		}
+		return 0
+	}
+	if p.PPr == nil {
+		return 0
+	}
+	if p.PPr.OutlineLvl != nil {
+		return outline(p.PPr.OutlineLvl)
+	}
+	id := ""
+	if p.PPr.PStyle != nil {
+		id = p.PPr.PStyle.ValAttr
+	}
+	// follow the based-on chain, guarding against cycles
+	for depth := 0; id != "" && depth < 16; depth++ {
+		s := d.GetStyleByID(id)
+		if s.X() == nil {
+			break
+		}
+		if s.X().PPr != nil && s.X().PPr.OutlineLvl != nil {
+			return outline(s.X().PPr.OutlineLvl)
+		}
+		if s.X().Name != nil {
+			n := 0
+			if _, err := fmt.Sscanf(strings.ToLower(s.X().Name.ValAttr), "heading %d", &n); err == nil && n >= 1 && n <= 9 {
+				return n
+			}
+		}
+		id = ""
+		if s.X().BasedOn != nil {
+			id = s.X().BasedOn.ValAttr
+		}
+	}
+	return 0
+}
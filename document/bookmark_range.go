@@ -0,0 +1,1132 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// Cross-reference field codes.
+const (
+	FieldRef     = "REF"
+	FieldPageRef = "PAGEREF"
+)
+
+// BookmarkRange is the content between the start and the end of a bookmark,
+// which can span runs, paragraphs and tables. The start and the end can be in
+// different table cells, replacing such a range empties the cells in the
+// range and keeps the rows and cells of the table.
+type BookmarkRange struct {
+	_doc   *Document
+	_story *[]*wml.EG_BlockLevelElts
+	_start *wml.CT_Bookmark
+}
+
+// BookmarkRange returns the content range of the bookmark with the given name.
+// The body, headers, footers, footnotes, endnotes and comments are searched.
+func (d *Document) BookmarkRange(name string) (BookmarkRange, error) {
+	for _, story := range d.stories() {
+		if bm := findBookmarkStart(*story, name); bm != nil {
+			return BookmarkRange{d, story, bm}, nil
+		}
+	}
+	return BookmarkRange{}, fmt.Errorf("bookmark %q not found", name)
+}
+
+// stories returns the block level content of all the parts of the document.
+func (d *Document) stories() []*[]*wml.EG_BlockLevelElts {
+	stories := []*[]*wml.EG_BlockLevelElts{&d.X().Body.EG_BlockLevelElts}
+	for _, h := range d.Headers() {
+		stories = append(stories, &h.X().EG_BlockLevelElts)
+	}
+	for _, f := range d.Footers() {
+		stories = append(stories, &f.X().EG_BlockLevelElts)
+	}
+	for _, fn := range d.Footnotes() {
+		stories = append(stories, &fn.X().EG_BlockLevelElts)
+	}
+	for _, en := range d.Endnotes() {
+		stories = append(stories, &en.X().EG_BlockLevelElts)
+	}
+	for _, c := range d.Comments() {
+		stories = append(stories, &c.X().EG_BlockLevelElts)
+	}
+	return stories
+}
+
+func findBookmarkStart(blocks []*wml.EG_BlockLevelElts, name string) *wml.CT_Bookmark {
+	w := &rangeWalker{}
+	var found *wml.CT_Bookmark
+	w.onMarkup = func(rme *wml.EG_RangeMarkupElements) {
+		if bs := rme.RangeMarkupElementsChoice.BookmarkStart; bs != nil && bs.NameAttr == name && found == nil {
+			found = bs
+			w.done = true
+		}
+	}
+	w.blocks(blocks)
+	return found
+}
+
+// Bookmark returns the bookmark of the range.
+func (r BookmarkRange) Bookmark() Bookmark { return Bookmark{r._start} }
+
+// Text returns the text of the range with the paragraphs separated by line
+// breaks.
+func (r BookmarkRange) Text() string { return r.content().text.String() }
+
+// Paragraphs returns the paragraphs that are fully or partially in the range.
+func (r BookmarkRange) Paragraphs() []Paragraph {
+	ps := []Paragraph{}
+	for _, p := range r.content().paragraphs {
+		ps = append(ps, Paragraph{r._doc, p})
+	}
+	return ps
+}
+
+// Runs returns the runs in the range.
+func (r BookmarkRange) Runs() []Run {
+	rs := []Run{}
+	for _, run := range r.content().runs {
+		rs = append(rs, Run{r._doc, run})
+	}
+	return rs
+}
+
+// Tables returns the tables that are fully in the range.
+func (r BookmarkRange) Tables() []Table {
+	ts := []Table{}
+	for _, t := range r.content().tables {
+		ts = append(ts, Table{r._doc, t})
+	}
+	return ts
+}
+
+// Clear removes the content of the range, leaving an empty bookmark.
+func (r BookmarkRange) Clear() error { return r.replaceInline(nil) }
+
+// SetText replaces the content of the range with text. The formatting of the
+// first run of the range is kept. Line breaks and tabs in text are converted
+// to breaks and tabs.
+func (r BookmarkRange) SetText(text string) error {
+	var rpr *wml.CT_RPr
+	if runs := r.content().runs; len(runs) > 0 {
+		rpr = runs[0].RPr
+	}
+	return r.replaceInline([]*wml.EG_PContent{newTextContent(r._doc, rpr, text)})
+}
+
+// newTextContent returns paragraph content holding a run with text, with a
+// copy of the run properties rpr. Line breaks and tabs in text are converted
+// to breaks and tabs.
+func newTextContent(d *Document, rpr *wml.CT_RPr, text string) *wml.EG_PContent {
+	run := Run{d, wml.NewCT_R()}
+	if rpr != nil {
+		cp := *rpr
+		run.X().RPr = &cp
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			run.AddBreak()
+		}
+		for j, s := range strings.Split(line, "\t") {
+			if j > 0 {
+				run.AddTab()
+			}
+			if s != "" {
+				run.AddText(s)
+			}
+		}
+	}
+	pc := wml.NewEG_PContent()
+	crc := wml.NewEG_ContentRunContent()
+	crc.ContentRunContentChoice.R = run.X()
+	pc.PContentChoice.EG_ContentRunContent = []*wml.EG_ContentRunContent{crc}
+	return pc
+}
+
+// ReplaceWithParagraphs replaces the content of the range with paragraphs.
+// The paragraphs are moved into the document and are usually created in a
+// scratch document, in which case they should not refer to images,
+// hyperlinks or numbering of that document, use ReplaceWithDocument instead.
+func (r BookmarkRange) ReplaceWithParagraphs(paragraphs ...Paragraph) error {
+	blocks := []*wml.EG_BlockLevelElts{}
+	for _, p := range paragraphs {
+		c := wml.NewEG_ContentBlockContent()
+		c.ContentBlockContentChoice.P = []*wml.CT_P{p.X()}
+		blocks = append(blocks, newBlockUnit(c))
+	}
+	return r.replaceBlocks(blocks)
+}
+
+// ReplaceWithTable replaces the content of the range with a table. The same
+// restrictions as for ReplaceWithParagraphs apply.
+func (r BookmarkRange) ReplaceWithTable(t Table) error {
+	c := wml.NewEG_ContentBlockContent()
+	c.ContentBlockContentChoice.Tbl = []*wml.CT_Tbl{t.X()}
+	return r.replaceBlocks([]*wml.EG_BlockLevelElts{newBlockUnit(c)})
+}
+
+// ReplaceWithDocument replaces the content of the range with the body of src.
+// The images, hyperlinks, styles, numbering and notes used by the content are
+// carried over, section properties of src are dropped.
+func (r BookmarkRange) ReplaceWithDocument(src *Document) error {
+	cp, err := src.Copy()
+	if err != nil {
+		return err
+	}
+	blocks := cp.X().Body.EG_BlockLevelElts
+	if err := r._doc.importBlocks(cp, blocks); err != nil {
+		return err
+	}
+	return r.replaceBlocks(blocks)
+}
+
+// AddRefField adds a REF field showing the content of the bookmark, which is
+// also used as the field result. If hyperlink is set the field links to the
+// bookmark.
+func (r Run) AddRefField(bm Bookmark, hyperlink bool) {
+	result := ""
+	if br, err := r._fgggg.BookmarkRange(bm.Name()); err == nil {
+		result = br.Text()
+	}
+	r.addFieldWithResult(FieldRef+" "+bm.Name()+fieldSwitch(hyperlink), result)
+}
+
+// AddPageRefField adds a PAGEREF field showing the number of the page the
+// bookmark is on. The field is marked dirty so it is updated when the
+// document is opened. If hyperlink is set the field links to the bookmark.
+func (r Run) AddPageRefField(bm Bookmark, hyperlink bool) {
+	r.AddFieldWithFormatting(FieldPageRef, bm.Name()+fieldSwitch(hyperlink), true)
+}
+
+func fieldSwitch(hyperlink bool) string {
+	if hyperlink {
+		return " \\h"
+	}
+	return ""
+}
+
+// addFieldWithResult adds a complex field with a current result to the run.
+func (r Run) addFieldWithResult(code, result string) {
+	ic := r.newIC()
+	ic.RunInnerContentChoice.FldChar = wml.NewCT_FldChar()
+	ic.RunInnerContentChoice.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeBegin
+	ic = r.newIC()
+	ic.RunInnerContentChoice.InstrText = wml.NewCT_Text()
+	ic.RunInnerContentChoice.InstrText.Content = code
+	ic = r.newIC()
+	ic.RunInnerContentChoice.FldChar = wml.NewCT_FldChar()
+	ic.RunInnerContentChoice.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeSeparate
+	if result != "" {
+		r.AddText(result)
+	}
+	ic = r.newIC()
+	ic.RunInnerContentChoice.FldChar = wml.NewCT_FldChar()
+	ic.RunInnerContentChoice.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeEnd
+}
+
+// bookmarkContent is the content found between the start and the end of a
+// bookmark.
+type bookmarkContent struct {
+	paragraphs []*wml.CT_P
+	runs       []*wml.CT_R
+	tables     []*wml.CT_Tbl
+	text       strings.Builder
+}
+
+func (r BookmarkRange) content() *bookmarkContent {
+	bc := &bookmarkContent{}
+	w := &rangeWalker{}
+	var para *wml.CT_P
+	addParagraph := func(p *wml.CT_P) {
+		if len(bc.paragraphs) > 0 {
+			bc.text.WriteString("\n")
+		}
+		bc.paragraphs = append(bc.paragraphs, p)
+	}
+	w.onMarkup = func(rme *wml.EG_RangeMarkupElements) {
+		c := rme.RangeMarkupElementsChoice
+		if c.BookmarkStart == r._start {
+			w.inside = true
+			if para != nil {
+				addParagraph(para)
+			}
+		} else if c.BookmarkEnd != nil && c.BookmarkEnd.IdAttr == r._start.IdAttr && w.inside {
+			w.inside = false
+			w.done = true
+		}
+	}
+	w.onParagraph = func(p *wml.CT_P) {
+		para = p
+		if w.inside {
+			addParagraph(p)
+		}
+	}
+	w.onRun = func(run *wml.CT_R) {
+		if !w.inside {
+			return
+		}
+		bc.runs = append(bc.runs, run)
+		for _, ric := range run.EG_RunInnerContent {
+			c := ric.RunInnerContentChoice
+			switch {
+			case c.T != nil:
+				bc.text.WriteString(c.T.Content)
+			case c.Tab != nil:
+				bc.text.WriteString("\t")
+			case c.Br != nil, c.Cr != nil:
+				bc.text.WriteString("\n")
+			}
+		}
+	}
+	w.onTable = func(t *wml.CT_Tbl) { bc.tables = append(bc.tables, t) }
+	w.blocks(*r._story)
+	return bc
+}
+
+// rangeWalker walks block level content in document order and reports
+// paragraphs, runs, range markup and tables that are completely within the
+// walk range (between inside being set and reset by onMarkup).
+type rangeWalker struct {
+	inside, done bool
+	onMarkup     func(rme *wml.EG_RangeMarkupElements)
+	onParagraph  func(p *wml.CT_P)
+	onRun        func(r *wml.CT_R)
+	onTable      func(t *wml.CT_Tbl)
+}
+
+func (w *rangeWalker) blocks(blocks []*wml.EG_BlockLevelElts) {
+	for _, ble := range blocks {
+		w.contentBlocks(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+	}
+}
+
+func (w *rangeWalker) contentBlocks(cbcs []*wml.EG_ContentBlockContent) {
+	for _, c := range cbcs {
+		if w.done {
+			return
+		}
+		if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			w.contentBlocks(sdt.SdtContent.EG_ContentBlockContent)
+		}
+		for _, p := range c.ContentBlockContentChoice.P {
+			if w.onParagraph != nil {
+				w.onParagraph(p)
+			}
+			w.paragraphContent(p.EG_PContent)
+		}
+		for _, tbl := range c.ContentBlockContentChoice.Tbl {
+			inside := w.inside
+			walkTableCells(tbl, func(tc *wml.CT_Tc) {
+				if !w.done {
+					w.blocks(tc.EG_BlockLevelElts)
+				}
+			})
+			if inside && w.inside && w.onTable != nil {
+				w.onTable(tbl)
+			}
+		}
+		w.runLevel(c.ContentBlockContentChoice.EG_RunLevelElts)
+	}
+}
+
+func (w *rangeWalker) paragraphContent(pcs []*wml.EG_PContent) {
+	for _, pc := range pcs {
+		w.runContent(pc.PContentChoice.EG_ContentRunContent)
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			w.runContent(hl.PContentChoice.EG_ContentRunContent)
+		}
+		for _, fs := range pc.PContentChoice.FldSimple {
+			w.paragraphContent(fs.EG_PContent)
+		}
+	}
+}
+
+func (w *rangeWalker) runContent(crcs []*wml.EG_ContentRunContent) {
+	for _, crc := range crcs {
+		w.runContentChoice(crc.ContentRunContentChoice, false)
+	}
+}
+
+func (w *rangeWalker) runContentChoice(c *wml.EG_ContentRunContentChoice, deleted bool) {
+	if c == nil || w.done {
+		return
+	}
+	if c.R != nil && !deleted && w.onRun != nil {
+		w.onRun(c.R)
+	}
+	if sdt := c.Sdt; sdt != nil && sdt.SdtContent != nil {
+		w.paragraphContent(sdt.SdtContent.EG_PContent)
+	}
+	for _, rle := range c.EG_RunLevelElts {
+		if ins := rle.RunLevelEltsChoice.Ins; ins != nil {
+			for _, tc := range ins.RunTrackChangeChoice {
+				w.runContentChoice(tc.ContentRunContentChoice, deleted)
+			}
+		}
+		if del := rle.RunLevelEltsChoice.Del; del != nil {
+			for _, tc := range del.RunTrackChangeChoice {
+				w.runContentChoice(tc.ContentRunContentChoice, true)
+			}
+		}
+	}
+	w.runLevel(c.EG_RunLevelElts)
+}
+
+func (w *rangeWalker) runLevel(rles []*wml.EG_RunLevelElts) {
+	for _, rle := range rles {
+		for _, rme := range rle.RunLevelEltsChoice.EG_RangeMarkupElements {
+			if w.done {
+				return
+			}
+			if w.onMarkup != nil {
+				w.onMarkup(rme)
+			}
+		}
+	}
+}
+
+// blockContainer is an editable list of block level elements such as the
+// body, a table cell or a content control. Every unit holds one paragraph,
+// table, content control or group of run level elements.
+type blockContainer struct {
+	units []*wml.EG_BlockLevelElts
+	store func(units []*wml.EG_BlockLevelElts)
+}
+
+func newBlockUnit(c *wml.EG_ContentBlockContent) *wml.EG_BlockLevelElts {
+	ble := wml.NewEG_BlockLevelElts()
+	ble.BlockLevelEltsChoice.EG_ContentBlockContent = []*wml.EG_ContentBlockContent{c}
+	return ble
+}
+
+// splitBlockContent splits c into content blocks holding a single element.
+func splitBlockContent(c *wml.EG_ContentBlockContent) []*wml.EG_ContentBlockContent {
+	cc := c.ContentBlockContentChoice
+	n := len(cc.P) + len(cc.Tbl)
+	if cc.Sdt != nil {
+		n++
+	}
+	if len(cc.EG_RunLevelElts) > 0 {
+		n++
+	}
+	if n <= 1 {
+		return []*wml.EG_ContentBlockContent{c}
+	}
+	parts := []*wml.EG_ContentBlockContent{}
+	if cc.Sdt != nil {
+		part := wml.NewEG_ContentBlockContent()
+		part.ContentBlockContentChoice.Sdt = cc.Sdt
+		parts = append(parts, part)
+	}
+	for _, p := range cc.P {
+		part := wml.NewEG_ContentBlockContent()
+		part.ContentBlockContentChoice.P = []*wml.CT_P{p}
+		parts = append(parts, part)
+	}
+	for _, tbl := range cc.Tbl {
+		part := wml.NewEG_ContentBlockContent()
+		part.ContentBlockContentChoice.Tbl = []*wml.CT_Tbl{tbl}
+		parts = append(parts, part)
+	}
+	if len(cc.EG_RunLevelElts) > 0 {
+		part := wml.NewEG_ContentBlockContent()
+		part.ContentBlockContentChoice.EG_RunLevelElts = cc.EG_RunLevelElts
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+func newBlockContainer(blocks *[]*wml.EG_BlockLevelElts) *blockContainer {
+	bc := &blockContainer{store: func(units []*wml.EG_BlockLevelElts) { *blocks = units }}
+	for _, ble := range *blocks {
+		if len(ble.BlockLevelEltsChoice.EG_ContentBlockContent) == 0 {
+			bc.units = append(bc.units, ble)
+			continue
+		}
+		for _, c := range ble.BlockLevelEltsChoice.EG_ContentBlockContent {
+			for _, part := range splitBlockContent(c) {
+				bc.units = append(bc.units, newBlockUnit(part))
+			}
+		}
+	}
+	return bc
+}
+
+func newContentBlockContainer(cbcs *[]*wml.EG_ContentBlockContent) *blockContainer {
+	bc := &blockContainer{store: func(units []*wml.EG_BlockLevelElts) {
+		*cbcs = nil
+		for _, u := range units {
+			*cbcs = append(*cbcs, u.BlockLevelEltsChoice.EG_ContentBlockContent...)
+		}
+	}}
+	for _, c := range *cbcs {
+		for _, part := range splitBlockContent(c) {
+			bc.units = append(bc.units, newBlockUnit(part))
+		}
+	}
+	return bc
+}
+
+// unitParagraph returns the paragraph held by the unit at index i, if any.
+func (bc *blockContainer) unitParagraph(i int) *wml.CT_P {
+	for _, c := range bc.units[i].BlockLevelEltsChoice.EG_ContentBlockContent {
+		if len(c.ContentBlockContentChoice.P) == 1 {
+			return c.ContentBlockContentChoice.P[0]
+		}
+	}
+	return nil
+}
+
+// inlineWrapper is the kind of paragraph content a marker is nested in.
+type inlineWrapper byte
+
+const (
+	wrapHyperlink inlineWrapper = iota + 1
+	wrapField
+	wrapSdt
+	wrapChange
+)
+
+// markerPos is the position of a bookmark start or end marker.
+type markerPos struct {
+	rme  *wml.EG_RangeMarkupElements
+	c    *blockContainer
+	unit *wml.EG_BlockLevelElts
+	// para is the paragraph of the unit or nil if the marker is between
+	// blocks, top is the paragraph content element holding the marker and
+	// wrappers are the elements within top the marker is nested in, outermost
+	// first
+	para     *wml.CT_P
+	top      *wml.EG_PContent
+	wrappers []inlineWrapper
+}
+
+// index returns the index of the unit of the marker in its container.
+func (m *markerPos) index() int {
+	for i, u := range m.c.units {
+		if u == m.unit {
+			return i
+		}
+	}
+	return -1
+}
+
+// bookmarkLocator finds the positions of the markers of a bookmark without
+// changing the document.
+type bookmarkLocator struct {
+	start *wml.CT_Bookmark
+	s, e  *markerPos
+}
+
+func (l *bookmarkLocator) isMarker(rme *wml.EG_RangeMarkupElements) bool {
+	c := rme.RangeMarkupElementsChoice
+	return c.BookmarkStart == l.start || c.BookmarkEnd != nil && c.BookmarkEnd.IdAttr == l.start.IdAttr
+}
+
+func (l *bookmarkLocator) found(pos *markerPos) {
+	if pos.rme.RangeMarkupElementsChoice.BookmarkStart == l.start {
+		l.s = pos
+	} else if l.s != nil && l.e == nil {
+		l.e = pos
+	}
+}
+
+func (l *bookmarkLocator) container(bc *blockContainer) {
+	for _, u := range bc.units {
+		for _, c := range u.BlockLevelEltsChoice.EG_ContentBlockContent {
+			cc := c.ContentBlockContentChoice
+			if sdt := cc.Sdt; sdt != nil && sdt.SdtContent != nil {
+				l.container(newContentBlockContainer(&sdt.SdtContent.EG_ContentBlockContent))
+			}
+			for _, p := range cc.P {
+				for _, pc := range p.EG_PContent {
+					l.inline(pc, nil, &markerPos{c: bc, unit: u, para: p, top: pc})
+				}
+			}
+			for _, tbl := range cc.Tbl {
+				walkTableCells(tbl, func(tc *wml.CT_Tc) {
+					l.container(newBlockContainer(&tc.EG_BlockLevelElts))
+				})
+			}
+			for _, rme := range rangeMarkup(cc.EG_RunLevelElts) {
+				if l.isMarker(rme) {
+					l.found(&markerPos{rme: rme, c: bc, unit: u})
+				}
+			}
+		}
+	}
+}
+
+// inline records the markers in paragraph content, at records the element
+// of the paragraph being walked.
+func (l *bookmarkLocator) inline(pc *wml.EG_PContent, wrappers []inlineWrapper, at *markerPos) {
+	l.runContent(pc.PContentChoice.EG_ContentRunContent, wrappers, at)
+	if hl := pc.PContentChoice.Hyperlink; hl != nil {
+		l.runContent(hl.PContentChoice.EG_ContentRunContent, wrapped(wrappers, wrapHyperlink), at)
+	}
+	for _, fs := range pc.PContentChoice.FldSimple {
+		for _, fpc := range fs.EG_PContent {
+			l.inline(fpc, wrapped(wrappers, wrapField), at)
+		}
+	}
+}
+
+func (l *bookmarkLocator) runContent(crcs []*wml.EG_ContentRunContent, wrappers []inlineWrapper, at *markerPos) {
+	for _, crc := range crcs {
+		l.runContentChoice(crc.ContentRunContentChoice, wrappers, at)
+	}
+}
+
+func (l *bookmarkLocator) runContentChoice(c *wml.EG_ContentRunContentChoice, wrappers []inlineWrapper, at *markerPos) {
+	if c == nil {
+		return
+	}
+	if sdt := c.Sdt; sdt != nil && sdt.SdtContent != nil {
+		for _, pc := range sdt.SdtContent.EG_PContent {
+			l.inline(pc, wrapped(wrappers, wrapSdt), at)
+		}
+	}
+	for _, rle := range c.EG_RunLevelElts {
+		for _, rme := range rle.RunLevelEltsChoice.EG_RangeMarkupElements {
+			if l.isMarker(rme) {
+				pos := *at
+				pos.rme = rme
+				pos.wrappers = wrappers
+				l.found(&pos)
+			}
+		}
+		for _, tc := range trackChanges(rle) {
+			l.runContentChoice(tc.ContentRunContentChoice, wrapped(wrappers, wrapChange), at)
+		}
+	}
+}
+
+func wrapped(wrappers []inlineWrapper, w inlineWrapper) []inlineWrapper {
+	return append(append([]inlineWrapper{}, wrappers...), w)
+}
+
+func rangeMarkup(rles []*wml.EG_RunLevelElts) []*wml.EG_RangeMarkupElements {
+	var rmes []*wml.EG_RangeMarkupElements
+	for _, rle := range rles {
+		rmes = append(rmes, rle.RunLevelEltsChoice.EG_RangeMarkupElements...)
+	}
+	return rmes
+}
+
+// locate returns the positions of the markers of the bookmark. The document is
+// not changed. If blocks is set the range must allow inserting blocks at its
+// start.
+func (r BookmarkRange) locate(blocks bool) (*markerPos, *markerPos, error) {
+	l := &bookmarkLocator{start: r._start}
+	l.container(newBlockContainer(r._story))
+	s, e := l.s, l.e
+	if s == nil || e == nil {
+		return nil, nil, errors.New("bookmark end not found")
+	}
+	for _, m := range []*markerPos{s, e} {
+		for _, w := range m.wrappers {
+			if w == wrapChange {
+				return nil, nil, errors.New("bookmark markers in tracked changes are not supported")
+			}
+		}
+	}
+	if s.para == nil && s.unit == e.unit {
+		return nil, nil, errors.New("bookmark markers are nested in the same element")
+	}
+	if blocks && s.para != nil && s.para == e.para && s.top == e.top && len(s.wrappers) > 0 &&
+		(len(s.wrappers) > 1 || len(e.wrappers) != 1 || s.wrappers[0] != wrapHyperlink || e.wrappers[0] != wrapHyperlink) {
+		return nil, nil, errors.New("cannot insert blocks into a field or content control")
+	}
+	return s, e, nil
+}
+
+// prepare isolates the markers of the range in their own run content elements,
+// splits the paragraph holding both markers if split is set, and removes the
+// content of the range. It returns the positions of the markers afterwards.
+func (r BookmarkRange) prepare(s, e *markerPos, split bool) (*markerPos, *markerPos, error) {
+	l := &bookmarkLocator{start: r._start}
+	for _, p := range []*wml.CT_P{s.para, e.para} {
+		if p != nil {
+			p.EG_PContent = isolateMarkers(p.EG_PContent, l.isMarker)
+		}
+	}
+	if split && s.para != nil && s.para == e.para {
+		splitParagraphAt(s, e)
+	}
+	cl := &rangeClearer{l: l}
+	cl.container(newBlockContainer(r._story), false)
+	return r.locate(false)
+}
+
+// splitParagraphAt splits the paragraph of the start marker m after the
+// marker, the second half is added as a new unit and keeps the properties with
+// a possible section break. A hyperlink holding both markers is split in two.
+func splitParagraphAt(m, end *markerPos) {
+	p := m.para
+	k := markerIndex(p.EG_PContent, m.rme)
+	head := append([]*wml.EG_PContent{}, p.EG_PContent[:k+1]...)
+	tailPcs := append([]*wml.EG_PContent{}, p.EG_PContent[k+1:]...)
+	if hl := p.EG_PContent[k].PContentChoice.Hyperlink; hl != nil && markerIndex(p.EG_PContent, end.rme) == k {
+		crcs := hl.PContentChoice.EG_ContentRunContent
+		j := 0
+		for i, crc := range crcs {
+			if holdsMarker(crc, m.rme) {
+				j = i
+			}
+		}
+		second := wml.NewCT_Hyperlink()
+		choice := second.PContentChoice
+		*second = *hl
+		second.PContentChoice = choice
+		second.PContentChoice.EG_ContentRunContent = append([]*wml.EG_ContentRunContent{}, crcs[j+1:]...)
+		hl.PContentChoice.EG_ContentRunContent = crcs[:j+1]
+		pc := wml.NewEG_PContent()
+		pc.PContentChoice.Hyperlink = second
+		tailPcs = concatContent([]*wml.EG_PContent{pc}, tailPcs)
+	}
+	tail := wml.NewCT_P()
+	tail.PPr = p.PPr
+	tail.EG_PContent = tailPcs
+	if p.PPr != nil {
+		ppr := *p.PPr
+		ppr.SectPr = nil
+		p.PPr = &ppr
+	}
+	p.EG_PContent = head
+	cbc := wml.NewEG_ContentBlockContent()
+	cbc.ContentBlockContentChoice.P = []*wml.CT_P{tail}
+	i := m.index()
+	m.c.units = concatUnits(m.c.units[:i+1], []*wml.EG_BlockLevelElts{newBlockUnit(cbc)}, m.c.units[i+1:])
+	m.c.store(m.c.units)
+}
+
+// markerIndex returns the index of the paragraph content element holding rme.
+func markerIndex(pcs []*wml.EG_PContent, rme *wml.EG_RangeMarkupElements) int {
+	for i, pc := range pcs {
+		found := false
+		w := &rangeWalker{onMarkup: func(m *wml.EG_RangeMarkupElements) {
+			if m == rme {
+				found = true
+			}
+		}}
+		w.paragraphContent([]*wml.EG_PContent{pc})
+		if found {
+			return i
+		}
+	}
+	return -1
+}
+
+// holdsMarker reports whether rme is directly held by crc.
+func holdsMarker(crc *wml.EG_ContentRunContent, rme *wml.EG_RangeMarkupElements) bool {
+	if crc.ContentRunContentChoice == nil {
+		return false
+	}
+	for _, m := range rangeMarkup(crc.ContentRunContentChoice.EG_RunLevelElts) {
+		if m == rme {
+			return true
+		}
+	}
+	return false
+}
+
+// isolateMarkers rewrites paragraph content so that every marker is held by
+// its own run content element, also within hyperlinks, simple fields and
+// content controls. Paragraph content elements directly holding run content
+// are split to hold one run content element each. This does not change the
+// markup of the paragraph.
+func isolateMarkers(pcs []*wml.EG_PContent, isMarker func(rme *wml.EG_RangeMarkupElements) bool) []*wml.EG_PContent {
+	res := []*wml.EG_PContent{}
+	for _, pc := range pcs {
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			hl.PContentChoice.EG_ContentRunContent = isolateRunContent(hl.PContentChoice.EG_ContentRunContent, isMarker)
+			res = append(res, pc)
+			continue
+		}
+		if len(pc.PContentChoice.FldSimple) > 0 {
+			for _, fs := range pc.PContentChoice.FldSimple {
+				fs.EG_PContent = isolateMarkers(fs.EG_PContent, isMarker)
+			}
+			res = append(res, pc)
+			continue
+		}
+		crcs := pc.PContentChoice.EG_ContentRunContent
+		if len(crcs) == 0 {
+			res = append(res, pc)
+			continue
+		}
+		for _, part := range isolateRunContent(crcs, isMarker) {
+			npc := wml.NewEG_PContent()
+			npc.PContentChoice.EG_ContentRunContent = []*wml.EG_ContentRunContent{part}
+			res = append(res, npc)
+		}
+	}
+	return res
+}
+
+func isolateRunContent(crcs []*wml.EG_ContentRunContent, isMarker func(rme *wml.EG_RangeMarkupElements) bool) []*wml.EG_ContentRunContent {
+	res := []*wml.EG_ContentRunContent{}
+	for _, crc := range crcs {
+		if sdt := crc.ContentRunContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			sdt.SdtContent.EG_PContent = isolateMarkers(sdt.SdtContent.EG_PContent, isMarker)
+		}
+		res = append(res, splitRunContent(crc, isMarker)...)
+	}
+	return res
+}
+
+// splitRunContent splits the run level elements of crc so that every marker is
+// in its own run content element.
+func splitRunContent(crc *wml.EG_ContentRunContent, isMarker func(rme *wml.EG_RangeMarkupElements) bool) []*wml.EG_ContentRunContent {
+	c := crc.ContentRunContentChoice
+	if c.R != nil || c.Sdt != nil {
+		return []*wml.EG_ContentRunContent{crc}
+	}
+	parts := []*wml.EG_ContentRunContent{}
+	var pending []*wml.EG_RunLevelElts
+	flush := func() {
+		if len(pending) > 0 {
+			part := wml.NewEG_ContentRunContent()
+			part.ContentRunContentChoice.EG_RunLevelElts = pending
+			parts = append(parts, part)
+			pending = nil
+		}
+	}
+	for _, rle := range c.EG_RunLevelElts {
+		rmes := rle.RunLevelEltsChoice.EG_RangeMarkupElements
+		if len(rmes) == 0 {
+			pending = append(pending, rle)
+			continue
+		}
+		var group []*wml.EG_RangeMarkupElements
+		addGroup := func() {
+			if len(group) > 0 {
+				nrle := wml.NewEG_RunLevelElts()
+				nrle.RunLevelEltsChoice.EG_RangeMarkupElements = group
+				pending = append(pending, nrle)
+				group = nil
+			}
+		}
+		for _, rme := range rmes {
+			if !isMarker(rme) {
+				group = append(group, rme)
+				continue
+			}
+			addGroup()
+			flush()
+			group = []*wml.EG_RangeMarkupElements{rme}
+			addGroup()
+			flush()
+		}
+		addGroup()
+	}
+	flush()
+	return parts
+}
+
+// rangeClearer removes the content between the markers of a bookmark in
+// document order. Elements holding a marker are kept and their content is
+// cleared, table cells that are emptied keep an empty paragraph.
+type rangeClearer struct {
+	l      *bookmarkLocator
+	inside bool
+}
+
+func (cl *rangeClearer) markup(rme *wml.EG_RangeMarkupElements) {
+	if !cl.l.isMarker(rme) {
+		return
+	}
+	cl.inside = rme.RangeMarkupElementsChoice.BookmarkStart == cl.l.start
+}
+
+// holds reports whether the markers of the bookmark are within the content
+// walked by walk.
+func (cl *rangeClearer) holds(walk func(w *rangeWalker)) bool {
+	found := false
+	w := &rangeWalker{onMarkup: func(rme *wml.EG_RangeMarkupElements) {
+		if cl.l.isMarker(rme) {
+			found = true
+		}
+	}}
+	walk(w)
+	return found
+}
+
+func (cl *rangeClearer) container(bc *blockContainer, cell bool) {
+	units := []*wml.EG_BlockLevelElts{}
+	removed := false
+	for _, u := range bc.units {
+		if !cl.holds(func(w *rangeWalker) { w.blocks([]*wml.EG_BlockLevelElts{u}) }) {
+			if cl.inside {
+				removed = true
+			} else {
+				units = append(units, u)
+			}
+			continue
+		}
+		for _, c := range u.BlockLevelEltsChoice.EG_ContentBlockContent {
+			cc := c.ContentBlockContentChoice
+			if sdt := cc.Sdt; sdt != nil && sdt.SdtContent != nil {
+				cl.container(newContentBlockContainer(&sdt.SdtContent.EG_ContentBlockContent), false)
+			}
+			for _, p := range cc.P {
+				p.EG_PContent = cl.inline(p.EG_PContent)
+			}
+			for _, tbl := range cc.Tbl {
+				walkTableCells(tbl, func(tc *wml.CT_Tc) {
+					cl.container(newBlockContainer(&tc.EG_BlockLevelElts), true)
+				})
+			}
+			for _, rme := range rangeMarkup(cc.EG_RunLevelElts) {
+				cl.markup(rme)
+			}
+		}
+		units = append(units, u)
+	}
+	if !removed {
+		return
+	}
+	if cell {
+		// a cell has to end with a paragraph
+		if n := len(units); n == 0 || (&blockContainer{units: units}).unitParagraph(n-1) == nil {
+			cbc := wml.NewEG_ContentBlockContent()
+			cbc.ContentBlockContentChoice.P = []*wml.CT_P{wml.NewCT_P()}
+			units = append(units, newBlockUnit(cbc))
+		}
+	}
+	bc.units = units
+	bc.store(units)
+}
+
+func (cl *rangeClearer) inline(pcs []*wml.EG_PContent) []*wml.EG_PContent {
+	res := []*wml.EG_PContent{}
+	for _, pc := range pcs {
+		if !cl.holds(func(w *rangeWalker) { w.paragraphContent([]*wml.EG_PContent{pc}) }) {
+			if !cl.inside {
+				res = append(res, pc)
+			}
+			continue
+		}
+		pc.PContentChoice.EG_ContentRunContent = cl.runContent(pc.PContentChoice.EG_ContentRunContent)
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			hl.PContentChoice.EG_ContentRunContent = cl.runContent(hl.PContentChoice.EG_ContentRunContent)
+		}
+		for _, fs := range pc.PContentChoice.FldSimple {
+			fs.EG_PContent = cl.inline(fs.EG_PContent)
+		}
+		res = append(res, pc)
+	}
+	return res
+}
+
+func (cl *rangeClearer) runContent(crcs []*wml.EG_ContentRunContent) []*wml.EG_ContentRunContent {
+	res := []*wml.EG_ContentRunContent{}
+	for _, crc := range crcs {
+		if !cl.holds(func(w *rangeWalker) { w.runContent([]*wml.EG_ContentRunContent{crc}) }) {
+			if !cl.inside {
+				res = append(res, crc)
+			}
+			continue
+		}
+		c := crc.ContentRunContentChoice
+		if sdt := c.Sdt; sdt != nil && sdt.SdtContent != nil {
+			sdt.SdtContent.EG_PContent = cl.inline(sdt.SdtContent.EG_PContent)
+		}
+		for _, rme := range rangeMarkup(c.EG_RunLevelElts) {
+			cl.markup(rme)
+		}
+		res = append(res, crc)
+	}
+	return res
+}
+
+// insertAfterMarker inserts ins after the run content element holding rme,
+// descending into hyperlinks, simple fields and content controls. Only the
+// run content of ins is inserted into hyperlinks.
+func insertAfterMarker(pcs []*wml.EG_PContent, rme *wml.EG_RangeMarkupElements, ins []*wml.EG_PContent) ([]*wml.EG_PContent, bool) {
+	for i, pc := range pcs {
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			if crcs, ok := insertRunsAfterMarker(hl.PContentChoice.EG_ContentRunContent, rme, ins); ok {
+				hl.PContentChoice.EG_ContentRunContent = crcs
+				return pcs, true
+			}
+		}
+		for _, fs := range pc.PContentChoice.FldSimple {
+			if fpcs, ok := insertAfterMarker(fs.EG_PContent, rme, ins); ok {
+				fs.EG_PContent = fpcs
+				return pcs, true
+			}
+		}
+		for _, crc := range pc.PContentChoice.EG_ContentRunContent {
+			if holdsMarker(crc, rme) {
+				return concatContent(pcs[:i+1], ins, pcs[i+1:]), true
+			}
+			if sdt := crc.ContentRunContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+				if spcs, ok := insertAfterMarker(sdt.SdtContent.EG_PContent, rme, ins); ok {
+					sdt.SdtContent.EG_PContent = spcs
+					return pcs, true
+				}
+			}
+		}
+	}
+	return pcs, false
+}
+
+func insertRunsAfterMarker(crcs []*wml.EG_ContentRunContent, rme *wml.EG_RangeMarkupElements, ins []*wml.EG_PContent) ([]*wml.EG_ContentRunContent, bool) {
+	for i, crc := range crcs {
+		if holdsMarker(crc, rme) {
+			res := append([]*wml.EG_ContentRunContent{}, crcs[:i+1]...)
+			for _, pc := range ins {
+				res = append(res, pc.PContentChoice.EG_ContentRunContent...)
+			}
+			return append(res, crcs[i+1:]...), true
+		}
+		if sdt := crc.ContentRunContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			if spcs, ok := insertAfterMarker(sdt.SdtContent.EG_PContent, rme, ins); ok {
+				sdt.SdtContent.EG_PContent = spcs
+				return crcs, true
+			}
+		}
+	}
+	return crcs, false
+}
+
+// isMarkupOnly reports whether the paragraph content elements hold no text,
+// only range markup such as bookmarks.
+func isMarkupOnly(pcs []*wml.EG_PContent) bool {
+	for _, pc := range pcs {
+		if pc.PContentChoice.Hyperlink != nil || len(pc.PContentChoice.FldSimple) > 0 {
+			return false
+		}
+		for _, crc := range pc.PContentChoice.EG_ContentRunContent {
+			c := crc.ContentRunContentChoice
+			if c.R != nil || c.Sdt != nil {
+				return false
+			}
+			for _, rle := range c.EG_RunLevelElts {
+				if rle.RunLevelEltsChoice.Ins != nil || rle.RunLevelEltsChoice.Del != nil {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func concatContent(lists ...[]*wml.EG_PContent) []*wml.EG_PContent {
+	pcs := []*wml.EG_PContent{}
+	for _, l := range lists {
+		pcs = append(pcs, l...)
+	}
+	return pcs
+}
+
+// replaceInline replaces the content of the range with paragraph content,
+// which is inserted at the start of the range. If the range spans several
+// paragraphs of the same container, the first and the last paragraph are
+// merged. Tables partly in the range keep their rows and cells, the cells in
+// the range are emptied.
+func (r BookmarkRange) replaceInline(pcs []*wml.EG_PContent) error {
+	s, e, err := r.locate(false)
+	if err != nil {
+		return err
+	}
+	if s, e, err = r.prepare(s, e, false); err != nil {
+		return err
+	}
+	c := s.c
+	switch {
+	case s.para != nil:
+		s.para.EG_PContent, _ = insertAfterMarker(s.para.EG_PContent, s.rme, pcs)
+		if e.c != c || e.para == nil || e.para == s.para {
+			break
+		}
+		s.para.EG_PContent = concatContent(s.para.EG_PContent, e.para.EG_PContent)
+		if e.para.PPr != nil && e.para.PPr.SectPr != nil {
+			// keep the section break of the last paragraph
+			if s.para.PPr == nil {
+				s.para.PPr = wml.NewCT_PPr()
+			}
+			s.para.PPr.SectPr = e.para.PPr.SectPr
+		}
+		i := e.index()
+		c.units = concatUnits(c.units[:i], c.units[i+1:])
+	case e.c == c && e.para != nil:
+		e.para.EG_PContent = concatContent(pcs, e.para.EG_PContent)
+	case len(pcs) > 0:
+		p := wml.NewCT_P()
+		p.EG_PContent = pcs
+		cbc := wml.NewEG_ContentBlockContent()
+		cbc.ContentBlockContentChoice.P = []*wml.CT_P{p}
+		i := s.index()
+		c.units = concatUnits(c.units[:i+1], []*wml.EG_BlockLevelElts{newBlockUnit(cbc)}, c.units[i+1:])
+	}
+	c.store(c.units)
+	return nil
+}
+
+// replaceBlocks replaces the content of the range with blocks, which are
+// inserted after the paragraph holding the start of the range. A paragraph
+// holding only a marker after the replacement is merged into the adjacent
+// inserted paragraph.
+func (r BookmarkRange) replaceBlocks(blocks []*wml.EG_BlockLevelElts) error {
+	s, e, err := r.locate(true)
+	if err != nil {
+		return err
+	}
+	if s, e, err = r.prepare(s, e, true); err != nil {
+		return err
+	}
+	c := s.c
+	i := s.index()
+	head := c.units[:i]
+	first := []*wml.EG_BlockLevelElts{c.units[i]}
+	// the units between the markers were removed, so the unit of an end
+	// marker in the same container follows the unit of the start
+	last := []*wml.EG_BlockLevelElts{}
+	rest := c.units[i+1:]
+	if e.c == c && e.index() == i+1 {
+		last = []*wml.EG_BlockLevelElts{c.units[i+1]}
+		rest = c.units[i+2:]
+	}
+	inserted := &blockContainer{units: blocks}
+	if len(blocks) > 0 {
+		if fp := inserted.unitParagraph(0); fp != nil && s.para != nil && canMerge(s.para) {
+			fp.EG_PContent = concatContent(s.para.EG_PContent, fp.EG_PContent)
+			first = nil
+		}
+		if lp := inserted.unitParagraph(len(blocks) - 1); lp != nil && len(last) > 0 && e.para != nil && canMerge(e.para) {
+			lp.EG_PContent = concatContent(lp.EG_PContent, e.para.EG_PContent)
+			last = nil
+		}
+	}
+	c.units = concatUnits(head, first, blocks, last, rest)
+	c.store(c.units)
+	return nil
+}
+
+// canMerge reports whether p holds only markers and can be merged into an
+// adjacent paragraph.
+func canMerge(p *wml.CT_P) bool {
+	return isMarkupOnly(p.EG_PContent) && (p.PPr == nil || p.PPr.SectPr == nil)
+}
+
+func concatUnits(lists ...[]*wml.EG_BlockLevelElts) []*wml.EG_BlockLevelElts {
+	units := []*wml.EG_BlockLevelElts{}
+	for _, l := range lists {
+		units = append(units, l...)
+	}
+	return units
+}
@@ -0,0 +1,75 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"testing"
+
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// moveInto makes the content of p the destination of a tracked move.
+func moveInto(p Paragraph) {
+	rtc := wml.NewCT_RunTrackChange()
+	rtc.AuthorAttr = "author"
+	for _, pc := range p.X().EG_PContent {
+		for _, crc := range pc.PContentChoice.EG_ContentRunContent {
+			tc := wml.NewCT_RunTrackChangeChoice()
+			tc.ContentRunContentChoice = crc.ContentRunContentChoice
+			rtc.RunTrackChangeChoice = append(rtc.RunTrackChangeChoice, tc)
+		}
+	}
+	rle := wml.NewEG_RunLevelElts()
+	rle.RunLevelEltsChoice.MoveTo = rtc
+	crc := wml.NewEG_ContentRunContent()
+	crc.ContentRunContentChoice.EG_RunLevelElts = []*wml.EG_RunLevelElts{rle}
+	pc := wml.NewEG_PContent()
+	pc.PContentChoice.EG_ContentRunContent = []*wml.EG_ContentRunContent{crc}
+	p.X().EG_PContent = []*wml.EG_PContent{pc}
+}
+
+func TestBookmarkRangeInMovedText(t *testing.T) {
+	d := New()
+	p := d.AddParagraph()
+	p.AddBookmark("moved")
+	p.AddRun().AddText("moved text")
+	// move the end of the bookmark after the run
+	rmes := &p.X().EG_PContent[0].PContentChoice.EG_ContentRunContent[0].ContentRunContentChoice.EG_RunLevelElts[0].RunLevelEltsChoice.EG_RangeMarkupElements
+	end := (*rmes)[1]
+	*rmes = (*rmes)[:1]
+	rle := wml.NewEG_RunLevelElts()
+	rle.RunLevelEltsChoice.EG_RangeMarkupElements = []*wml.EG_RangeMarkupElements{end}
+	crc := wml.NewEG_ContentRunContent()
+	crc.ContentRunContentChoice.EG_RunLevelElts = []*wml.EG_RunLevelElts{rle}
+	pc := wml.NewEG_PContent()
+	pc.PContentChoice.EG_ContentRunContent = []*wml.EG_ContentRunContent{crc}
+	p.X().EG_PContent = append(p.X().EG_PContent, pc)
+	moveInto(p)
+
+	runs := 0
+	walkParagraphRuns(p.X(), func(r *wml.CT_R) { runs++ })
+	if runs != 1 {
+		t.Errorf("expected the run of the moved text, got %d runs", runs)
+	}
+
+	br, err := d.BookmarkRange("moved")
+	if err != nil {
+		t.Fatalf("BookmarkRange: %s", err)
+	}
+	if got := br.Text(); got != "moved text" {
+		t.Errorf("expected the text of the moved run, got %q", got)
+	}
+	if err := br.SetText("replaced"); err != nil {
+		t.Fatalf("SetText: %s", err)
+	}
+	if got := br.Text(); got != "replaced" {
+		t.Errorf("expected the replaced text, got %q", got)
+	}
+}
@@ -0,0 +1,436 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2"
+	uchart "github.com/unidoc/unioffice/v2/chart"
+	"github.com/unidoc/unioffice/v2/color"
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	crt "github.com/unidoc/unioffice/v2/schema/soo/dml/chart"
+	"github.com/unidoc/unioffice/v2/spreadsheet"
+	"github.com/unidoc/unioffice/v2/spreadsheet/reference"
+)
+
+const (
+	chartURI        = "http://schemas.openxmlformats.org/drawingml/2006/chart"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// Chart is a chart part of the document. The data of its series is cached in
+// the chart and kept in an embedded workbook so that it can be edited in
+// Word.
+type Chart struct {
+	_doc   *Document
+	_chart *chart
+}
+
+// ChartData is the data of the series of a chart, laid out in the embedded
+// workbook with the categories in the first column and the values of each
+// series in a column of its own.
+type ChartData struct {
+	Categories []string
+	Series     []ChartSeriesData
+}
+
+// ChartSeriesData is the name and values of a series.
+type ChartSeriesData struct {
+	Name   string
+	Values []float64
+}
+
+// AddChartInline adds an inline drawing holding a new empty chart. Plots,
+// series and axes are added through Chart, the data of the series is set
+// with SetData.
+func (r Run) AddChartInline(width, height measurement.Distance) (InlineDrawing, Chart) {
+	inline := r.addInlineGraphic(chartURI, "Chart", width, height)
+	return InlineDrawing{r._fgggg, inline}, r._fgggg.addChart(inline.Graphic.GraphicData)
+}
+
+// AddChartAnchored adds an anchored (floating) drawing holding a new empty
+// chart.
+func (r Run) AddChartAnchored(width, height measurement.Distance) (AnchoredDrawing, Chart) {
+	anchor := r.addAnchoredGraphic(chartURI, "Chart", width, height)
+	return AnchoredDrawing{r._fgggg, anchor}, r._fgggg.addChart(anchor.Graphic.GraphicData)
+}
+
+func (d *Document) addChart(gd *dml.CT_GraphicalObjectData) Chart {
+	c := &chart{_bbd: crt.NewChartSpace()}
+	idx := d.nextChartIndex()
+	d._gabc = append(d._gabc, c)
+	c._gaga = unioffice.RelativeFilename(unioffice.DocTypeDocument, unioffice.OfficeDocumentType, unioffice.ChartType, idx)
+	c._ecb = d._fgg.AddRelationship(c._gaga, unioffice.ChartType).ID()
+	d.ContentTypes.AddOverride(unioffice.AbsoluteFilename(unioffice.DocTypeDocument, unioffice.ChartContentType, idx), unioffice.ChartContentType)
+	ref := crt.NewChart()
+	ref.IdAttr = c._ecb
+	gd.Any = append(gd.Any, ref)
+	uc := uchart.MakeChart(c._bbd)
+	uc.Properties().SetSolidFill(color.White)
+	uc.SetDisplayBlanksAs(crt.ST_DispBlanksAsGap)
+	return Chart{d, c}
+}
+
+// nextChartIndex returns the number of the first chart part name that is
+// neither used by a chart of the document nor by a file kept as it was read.
+func (d *Document) nextChartIndex() int {
+	used := map[string]bool{}
+	for _, c := range d._gabc {
+		used[c._gaga] = true
+	}
+	for n := 1; ; n++ {
+		target := unioffice.RelativeFilename(unioffice.DocTypeDocument, unioffice.OfficeDocumentType, unioffice.ChartType, n)
+		if !used[target] && !d.HasExtraFile(unioffice.AbsoluteFilename(unioffice.DocTypeDocument, unioffice.ChartType, n)) {
+			return n
+		}
+	}
+}
+
+// Charts returns the charts of the document.
+func (d *Document) Charts() []Chart {
+	charts := []Chart{}
+	for _, c := range d._gabc {
+		charts = append(charts, Chart{d, c})
+	}
+	return charts
+}
+
+// X returns the inner wrapped XML type.
+func (c Chart) X() *crt.ChartSpace { return c._chart._bbd }
+
+// RelID returns the relationship ID of the chart used by the drawings
+// showing it.
+func (c Chart) RelID() string { return c._chart._ecb }
+
+// Chart returns the chart for adding plots, series and axes.
+func (c Chart) Chart() uchart.Chart { return uchart.MakeChart(c._chart._bbd) }
+
+// chartSeries holds the name, categories and values of a series of any plot
+// type, the x and y values for scatter and bubble plots.
+type chartSeries struct {
+	tx  **crt.CT_SerTx
+	cat **crt.CT_AxDataSource
+	val **crt.CT_NumDataSource
+}
+
+// series returns the series of all plots of the chart in document order.
+func (c Chart) series() []chartSeries {
+	res := []chartSeries{}
+	cs := c.X()
+	if cs.Chart == nil || cs.Chart.PlotArea == nil {
+		return res
+	}
+	for _, pc := range cs.Chart.PlotArea.PlotAreaChoice {
+		if pc.AreaChart != nil {
+			for _, s := range pc.AreaChart.Ser {
+				res = append(res, chartSeries{&s.Tx, &s.Cat, &s.Val})
+			}
+		}
+		if pc.Area3DChart != nil {
+			for _, s := range pc.Area3DChart.Ser {
+				res = append(res, chartSeries{&s.Tx, &s.Cat, &s.Val})
+			}
+		}
+		var lineSer []*crt.CT_LineSer
+		if pc.LineChart != nil {
+			lineSer = append(lineSer, pc.LineChart.Ser...)
+		}
+		if pc.Line3DChart != nil {
+			lineSer = append(lineSer, pc.Line3DChart.Ser...)
+		}
+		if pc.StockChart != nil {
+			lineSer = append(lineSer, pc.StockChart.Ser...)
+		}
+		for _, s := range lineSer {
+			res = append(res, chartSeries{&s.Tx, &s.Cat, &s.Val})
+		}
+		if pc.RadarChart != nil {
+			for _, s := range pc.RadarChart.Ser {
+				res = append(res, chartSeries{&s.Tx, &s.Cat, &s.Val})
+			}
+		}
+		var pieSer []*crt.CT_PieSer
+		if pc.PieChart != nil {
+			pieSer = append(pieSer, pc.PieChart.Ser...)
+		}
+		if pc.Pie3DChart != nil {
+			pieSer = append(pieSer, pc.Pie3DChart.Ser...)
+		}
+		if pc.DoughnutChart != nil {
+			pieSer = append(pieSer, pc.DoughnutChart.Ser...)
+		}
+		if pc.OfPieChart != nil {
+			pieSer = append(pieSer, pc.OfPieChart.Ser...)
+		}
+		for _, s := range pieSer {
+			res = append(res, chartSeries{&s.Tx, &s.Cat, &s.Val})
+		}
+		var barSer []*crt.CT_BarSer
+		if pc.BarChart != nil {
+			barSer = append(barSer, pc.BarChart.Ser...)
+		}
+		if pc.Bar3DChart != nil {
+			barSer = append(barSer, pc.Bar3DChart.Ser...)
+		}
+		for _, s := range barSer {
+			res = append(res, chartSeries{&s.Tx, &s.Cat, &s.Val})
+		}
+		var surfaceSer []*crt.CT_SurfaceSer
+		if pc.SurfaceChart != nil {
+			surfaceSer = append(surfaceSer, pc.SurfaceChart.Ser...)
+		}
+		if pc.Surface3DChart != nil {
+			surfaceSer = append(surfaceSer, pc.Surface3DChart.Ser...)
+		}
+		for _, s := range surfaceSer {
+			res = append(res, chartSeries{&s.Tx, &s.Cat, &s.Val})
+		}
+		if pc.ScatterChart != nil {
+			for _, s := range pc.ScatterChart.Ser {
+				res = append(res, chartSeries{&s.Tx, &s.XVal, &s.YVal})
+			}
+		}
+		if pc.BubbleChart != nil {
+			for _, s := range pc.BubbleChart.Ser {
+				res = append(res, chartSeries{&s.Tx, &s.XVal, &s.YVal})
+			}
+		}
+	}
+	return res
+}
+
+// Data returns the data of the series as cached in the chart, the
+// categories are those of the first series.
+func (c Chart) Data() ChartData {
+	data := ChartData{}
+	for i, s := range c.series() {
+		sd := ChartSeriesData{}
+		if tx := *s.tx; tx != nil && tx.SerTxChoice != nil {
+			if tx.SerTxChoice.V != nil {
+				sd.Name = *tx.SerTxChoice.V
+			} else if ref := tx.SerTxChoice.StrRef; ref != nil && ref.StrCache != nil && len(ref.StrCache.Pt) > 0 {
+				sd.Name = ref.StrCache.Pt[0].V
+			}
+		}
+		if val := *s.val; val != nil && val.NumDataSourceChoice != nil {
+			nd := val.NumDataSourceChoice.NumLit
+			if ref := val.NumDataSourceChoice.NumRef; ref != nil {
+				nd = ref.NumCache
+			}
+			if nd != nil {
+				sd.Values = make([]float64, pointCount(nd.PtCount, len(nd.Pt)))
+				for _, pt := range nd.Pt {
+					if int(pt.IdxAttr) < len(sd.Values) {
+						sd.Values[pt.IdxAttr], _ = strconv.ParseFloat(pt.V, 64)
+					}
+				}
+			}
+		}
+		if cat := *s.cat; i == 0 && cat != nil && cat.AxDataSourceChoice != nil {
+			data.Categories = categories(cat.AxDataSourceChoice)
+		}
+		data.Series = append(data.Series, sd)
+	}
+	return data
+}
+
+func pointCount(n *crt.CT_UnsignedInt, pts int) int {
+	if n != nil {
+		return int(n.ValAttr)
+	}
+	return pts
+}
+
+func categories(c *crt.CT_AxDataSourceChoice) []string {
+	var sd *crt.CT_StrData
+	var nd *crt.CT_NumData
+	switch {
+	case c.StrRef != nil:
+		sd = c.StrRef.StrCache
+	case c.StrLit != nil:
+		sd = c.StrLit
+	case c.NumRef != nil:
+		nd = c.NumRef.NumCache
+	case c.NumLit != nil:
+		nd = c.NumLit
+	}
+	var cats []string
+	if sd != nil {
+		cats = make([]string, pointCount(sd.PtCount, len(sd.Pt)))
+		for _, pt := range sd.Pt {
+			if int(pt.IdxAttr) < len(cats) {
+				cats[pt.IdxAttr] = pt.V
+			}
+		}
+	}
+	if nd != nil {
+		cats = make([]string, pointCount(nd.PtCount, len(nd.Pt)))
+		for _, pt := range nd.Pt {
+			if int(pt.IdxAttr) < len(cats) {
+				cats[pt.IdxAttr] = pt.V
+			}
+		}
+	}
+	return cats
+}
+
+// SetData sets the names, categories and values of the series of the chart,
+// which must have as many series as data has. The caches of the chart and
+// the embedded workbook are both updated, the workbook is created if the
+// chart has none.
+func (c Chart) SetData(data ChartData) error {
+	series := c.series()
+	if len(series) != len(data.Series) {
+		return fmt.Errorf("chart has %d series, data has %d", len(series), len(data.Series))
+	}
+	wb, zipPath, err := c.workbook()
+	if err != nil {
+		return err
+	}
+	defer wb.Close()
+	if len(wb.Sheets()) == 0 {
+		wb.AddSheet()
+	}
+	sheet := wb.Sheets()[0]
+	sheet.X().SheetData.Row = nil
+	name := sheet.Name()
+	if name == "" {
+		name = "Sheet1"
+	}
+	ref := func(col uint32, from, to int) string {
+		r := fmt.Sprintf("'%s'!$%s$%d", strings.ReplaceAll(name, "'", "''"), reference.IndexToColumn(col), from)
+		if to > from {
+			r += fmt.Sprintf(":$%s$%d", reference.IndexToColumn(col), to)
+		}
+		return r
+	}
+	last := len(data.Categories) + 1
+	numeric := len(data.Categories) > 0
+	nums := make([]float64, len(data.Categories))
+	for i, cat := range data.Categories {
+		v, err := strconv.ParseFloat(cat, 64)
+		if err != nil {
+			numeric = false
+		}
+		nums[i] = v
+	}
+	for i, cat := range data.Categories {
+		cell := sheet.Cell(fmt.Sprintf("A%d", i+2))
+		if numeric {
+			cell.SetNumber(nums[i])
+		} else {
+			cell.SetString(cat)
+		}
+	}
+	for i, sd := range data.Series {
+		col := uint32(i + 1)
+		sheet.Cell(fmt.Sprintf("%s1", reference.IndexToColumn(col))).SetString(sd.Name)
+		for j, v := range sd.Values {
+			sheet.Cell(fmt.Sprintf("%s%d", reference.IndexToColumn(col), j+2)).SetNumber(v)
+		}
+
+		s := series[i]
+		*s.tx = &crt.CT_SerTx{SerTxChoice: &crt.CT_SerTxChoice{StrRef: &crt.CT_StrRef{F: ref(col, 1, 1), StrCache: strData([]string{sd.Name})}}}
+		cat := &crt.CT_AxDataSource{AxDataSourceChoice: &crt.CT_AxDataSourceChoice{}}
+		if numeric {
+			cat.AxDataSourceChoice.NumRef = &crt.CT_NumRef{F: ref(0, 2, last), NumCache: numData(nums)}
+		} else {
+			cat.AxDataSourceChoice.StrRef = &crt.CT_StrRef{F: ref(0, 2, last), StrCache: strData(data.Categories)}
+		}
+		*s.cat = cat
+		*s.val = &crt.CT_NumDataSource{NumDataSourceChoice: &crt.CT_NumDataSourceChoice{
+			NumRef: &crt.CT_NumRef{F: ref(col, 2, len(sd.Values)+1), NumCache: numData(sd.Values)}}}
+	}
+	buf := bytes.Buffer{}
+	if err := wb.Save(&buf); err != nil {
+		return err
+	}
+	c._doc.ContentTypes.EnsureDefault("xlsx", xlsxContentType)
+	return c._doc.AddExtraFileFromBytes(zipPath, buf.Bytes())
+}
+
+func strData(vals []string) *crt.CT_StrData {
+	sd := &crt.CT_StrData{PtCount: &crt.CT_UnsignedInt{ValAttr: uint32(len(vals))}}
+	for i, v := range vals {
+		sd.Pt = append(sd.Pt, &crt.CT_StrVal{IdxAttr: uint32(i), V: v})
+	}
+	return sd
+}
+
+func numData(vals []float64) *crt.CT_NumData {
+	nd := &crt.CT_NumData{FormatCode: unioffice.String("General"), PtCount: &crt.CT_UnsignedInt{ValAttr: uint32(len(vals))}}
+	for i, v := range vals {
+		nd.Pt = append(nd.Pt, &crt.CT_NumVal{IdxAttr: uint32(i), V: strconv.FormatFloat(v, 'f', -1, 64)})
+	}
+	return nd
+}
+
+// workbook returns the workbook embedded with the chart and its path in the
+// package, adding the relationship to a new workbook if there is none.
+func (c Chart) workbook() (*spreadsheet.Workbook, string, error) {
+	part := "word/" + c._chart._gaga
+	relsPath := path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+	rels := common.NewRelationships()
+	if c._doc.HasExtraFile(relsPath) {
+		data, err := c._doc.ExtraFileBytes(relsPath)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := xml.Unmarshal(data, rels.X()); err != nil {
+			return nil, "", err
+		}
+	}
+	cs := c.X()
+	if cs.ExternalData != nil {
+		for _, rel := range rels.X().Relationship {
+			if rel.IdAttr != cs.ExternalData.IdAttr {
+				continue
+			}
+			zipPath := path.Join(path.Dir(part), rel.TargetAttr)
+			if !c._doc.HasExtraFile(zipPath) {
+				break
+			}
+			data, err := c._doc.ExtraFileBytes(zipPath)
+			if err != nil {
+				return nil, "", err
+			}
+			wb, err := spreadsheet.Read(bytes.NewReader(data), int64(len(data)))
+			return wb, zipPath, err
+		}
+	}
+
+	n := 1
+	for c._doc.HasExtraFile(fmt.Sprintf("word/embeddings/Microsoft_Excel_Worksheet%d.xlsx", n)) {
+		n++
+	}
+	zipPath := fmt.Sprintf("word/embeddings/Microsoft_Excel_Worksheet%d.xlsx", n)
+	rel := rels.AddRelationship("../embeddings/"+path.Base(zipPath), common.PackageType)
+	cs.ExternalData = crt.NewCT_ExternalData()
+	cs.ExternalData.IdAttr = rel.ID()
+	cs.ExternalData.AutoUpdate = &crt.CT_Boolean{ValAttr: unioffice.Bool(false)}
+	data, err := xml.Marshal(rels.X())
+	if err != nil {
+		return nil, "", err
+	}
+	if err := c._doc.AddExtraFileFromBytes(relsPath, append([]byte(xml.Header), data...)); err != nil {
+		return nil, "", err
+	}
+	wb := spreadsheet.New()
+	wb.AddSheet()
+	return wb, zipPath, nil
+}
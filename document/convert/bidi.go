@@ -0,0 +1,58 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	"github.com/unidoc/unioffice/v2/internal/convertutils"
+)
+
+// reorderLine moves the symbols of l, which are laid out left to right in
+// logical order, to their visual positions per the Unicode Bidirectional
+// Algorithm. The paragraph direction is right to left if rtl, and the lines
+// of right to left paragraphs are aligned to the right. Lines without right
+// to left text are left as they are.
+func reorderLine(l *line, rtl bool) {
+	if l._ordered {
+		return
+	}
+	l._ordered = true
+	rtl = rtl || l._ac
+	var (
+		syms   []*symbol
+		words  []*word
+		glyphs []convertutils.LineGlyph
+	)
+	for _, sp := range l._da {
+		for _, w := range sp._adb {
+			for _, s := range w._db {
+				syms = append(syms, s)
+				words = append(words, w)
+				glyphs = append(glyphs, convertutils.LineGlyph{Text: s._ggg, RTL: s._rtl, X: w._fgd + s._ga, W: s._ceeg})
+			}
+		}
+	}
+	if !convertutils.ReorderLine(glyphs, rtl) {
+		return
+	}
+	shift := 0.0
+	if rtl {
+		right := glyphs[0].X + glyphs[0].W
+		for _, g := range glyphs {
+			if g.X+g.W > right {
+				right = g.X + g.W
+			}
+		}
+		shift = l._de - right
+	}
+	for i, s := range syms {
+		s._ga = glyphs[i].X + shift - words[i]._fgd
+		s._ggg = glyphs[i].Text
+	}
+}
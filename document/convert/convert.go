@@ -12,9 +12,9 @@
 package convert ;import (_ad "bytes";_c "errors";_a "fmt";_b "github.com/unidoc/emf";_age "github.com/unidoc/unioffice/v2/color";_eaa "github.com/unidoc/unioffice/v2/common/logger";_bb "github.com/unidoc/unioffice/v2/common/tempstorage";_ba "github.com/unidoc/unioffice/v2/document";
 _d "github.com/unidoc/unioffice/v2/internal/convertutils";_ffb "github.com/unidoc/unioffice/v2/internal/formatutils";_fc "github.com/unidoc/unioffice/v2/measurement";_fd "github.com/unidoc/unioffice/v2/schema/soo/dml";_gg "github.com/unidoc/unioffice/v2/schema/soo/dml/chart";
 _be "github.com/unidoc/unioffice/v2/schema/soo/dml/picture";_ef "github.com/unidoc/unioffice/v2/schema/soo/ofc/sharedTypes";_ff "github.com/unidoc/unioffice/v2/schema/soo/pkg/relationships";_ec "github.com/unidoc/unioffice/v2/schema/soo/wml";_ce "github.com/unidoc/unioffice/v2/schema/urn/schemas_microsoft_com/vml";
-_f "github.com/unidoc/unioffice/v2/vmldrawing";_eb "github.com/unidoc/unipdf/v4/core";_eg "github.com/unidoc/unipdf/v4/creator";_gf "github.com/unidoc/unipdf/v4/model";_gc "github.com/unidoc/unipdf/v4/textshaping";_g "image/png";_ag "io";_cb "regexp";_ca "strconv";
+_f "github.com/unidoc/unioffice/v2/vmldrawing";_eb "github.com/unidoc/unipdf/v4/core";_eg "github.com/unidoc/unipdf/v4/creator";_gf "github.com/unidoc/unipdf/v4/model";_g "image/png";_ag "io";_cb "regexp";_ca "strconv";
 _ea "strings";);type paragraph struct{_bg float64 ;_aa *_d .Rectangle ;_aec float64 ;_cff float64 ;_fcc float64 ;_fgf float64 ;_gd float64 ;_bab _eg .TextAlignment ;_bgg float64 ;_dc float64 ;_abf []*line ;_baf *tableWrapper ;_aed []*image ;_gdg []*image ;
-_eac []*block ;_gcg []*block ;_fga []*note ;_cc float64 ;_fda []*zoneToSkip ;_gccg float64 ;_cfb bool ;_bbe []*borderLine ;_bbf bool ;};func _gecce (_bafed *_ec .CT_OnOff )bool {if _bafed !=nil {if _addgd :=_bafed .ValAttr ;_addgd !=nil {if _aadf :=_addgd .Bool ;
+_eac []*block ;_gcg []*block ;_fga []*note ;_cc float64 ;_fda []*zoneToSkip ;_gccg float64 ;_cfb bool ;_bbe []*borderLine ;_bbf bool ;_src *_ec .CT_P ;_rtl bool ;};func _gecce (_bafed *_ec .CT_OnOff )bool {if _bafed !=nil {if _addgd :=_bafed .ValAttr ;_addgd !=nil {if _aadf :=_addgd .Bool ;
 _aadf !=nil {return *_aadf ;};return _addgd .ST_OnOff1 ==_ef .ST_OnOff1On ;};return true ;};return false ;};type word struct{_db []*symbol ;_fgd float64 ;_aggc float64 ;_bee bool ;};type span struct{_caf float64 ;_cgb float64 ;_adb []*word ;};func (_ecdf *convertContext )newParagraph (){if _ecdf ._gfga ==nil {_ecdf .newPage ();
 };_bbeg :=&paragraph {};_bbeg ._aa =&_d .Rectangle {};_bbeg ._fgf =_ecdf ._gfga ._fg ;_ecdf ._fegf =_bbeg ;};func (_bbea *convertContext )determineParagraphBounds (){_bbea ._fegf ._cff =_bbea ._gfga ._gcc .Left +_bbea ._fegf ._aa .Left ;_bbea ._fegf ._aec =_bbea ._fegf ._cff +_bbea ._fegf ._bg ;
 _bbea ._fegf ._fcc =_bbea ._gfga ._gcc .Right -_bbea ._fegf ._aa .Right ;};func (_ecd *convertContext )adjustRightBoundOfLastSpan (){_gecc :=_ecd ._gfgec ._cgb ;_edgd :=_ecd ._dfac ._efe +_ecd ._fegf ._fgf ;_bffb :=_edgd +_ecd ._dfac ._cea ;for _ ,_gdcdg :=range _ecd ._gfga ._bc {if ((_edgd > _gdcdg ._ebg .Top &&_edgd < _gdcdg ._ebg .Bottom )||(_bffb > _gdcdg ._ebg .Top &&_edgd < _gdcdg ._ebg .Bottom ))&&(_gecc > _gdcdg ._ebg .Left ){_gecc =_gdcdg ._ebg .Left ;
@@ -22,7 +22,7 @@ _bbea ._fegf ._fcc =_bbea ._gfga ._gcc .Right -_bbea ._fegf ._aa .Right ;};func
 _gddfd :=_cdbc ._dcfba .GetChartSpaceByRelId (_dbbfc );if _gddfd ==nil {return nil ,_c .New ("\u004e\u006f\u0020\u0063\u0068\u0061\u0072\u0074\u0073\u0070\u0061\u0063\u0065");};var _eeae *_fd .Theme ;_gbef :=_cdbc ._dcfba .Themes ();if len (_gbef )> 0{_eeae =_gbef [0];
 };return _d .MakeBlockFromChartSpace (_gddfd ,_bgfd ,_cecb ,_eeae );};func _aefd (_dbac ,_bcbdd *_ec .CT_HpsMeasure )float64 {var _aggf float64 ;_bbgad :=_d .DefaultFontSize ;if _dbac !=nil {_aggf =float64 (*_dbac .ValAttr .ST_UnsignedDecimalNumber );}else if _bcbdd !=nil {_aggf =float64 (*_bcbdd .ValAttr .ST_UnsignedDecimalNumber );
 };if _aggf !=0{_bbgad =_aggf /2;};return _bbgad ;};func (_bccf *convertContext )addTextSymbol (_eed *symbol ){_fbag :=_eg .New ();_eeb :=_fbag .NewStyledParagraph ();_eeb .SetMargins (0,0,0,0);_cegf :=_eed ._ggg ;if _eed ._dda {_cegf ="";};_cdfaa :=_eeb .Append (_cegf );
-_dacb :=0.0;if _eed ._df !=nil {_cdfaa .Style =*_eed ._df ;if _eed ._df .CharSpacing !=0{_dacb =_eed ._df .CharSpacing ;};};if _eed ._af ==nil &&_eed ._ffge ==nil {_eed ._dd =_eeb .Height ()*_ae ;_eed ._gea =_eeb .Height ();};if _eed ._ceeg ==0&&!_eed ._dda {_eed ._ceeg =_eeb .Width ()+_dacb ;
+_dacb :=0.0;if _eed ._df !=nil {_cdfaa .Style =*_eed ._df ;_bccf ._fallback .Chunk (_eeb ,_cdfaa );if _eed ._df .CharSpacing !=0{_dacb =_eed ._df .CharSpacing ;};};if _eed ._af ==nil &&_eed ._ffge ==nil {_eed ._dd =_eeb .Height ()*_ae ;_eed ._gea =_eeb .Height ();};if _eed ._ceeg ==0&&!_eed ._dda {_eed ._ceeg =_eeb .Width ()+_dacb ;
 };if _eed ._dd < _bccf ._fegf ._bgg {_eed ._dd =_bccf ._fegf ._bgg ;};if len (_bccf ._fagd ._db )> 0{_edgc :=_bccf ._fagd ._db [len (_bccf ._fagd ._db )-1]._ggg ;if _d .IsNoSpaceLanguage (_edgc )||(_edgc =="\u0020")!=(_eed ._ggg =="\u0020"){_bccf .addCurrentWordToParagraph ();
 _bccf .newWord ();};};_bccf ._fagd ._db =append (_bccf ._fagd ._db ,_eed );_eed ._ga =_bccf ._fagd ._aggc ;_bccf ._fagd ._aggc +=_eed ._ceeg ;if _eed ._ggg !="\u0020"{_bccf ._fagd ._bee =false ;};if _eed ._ggg =="\u000d"{_bccf .adjustHeights (_eed ._dd *1.13);
 _bccf .adjustHeights (_eed ._dd );};};func (_cafc *convertContext )addCellToTable (_aacf *_eg .Table ,_gcbb *_ec .CT_Tc ,_ffab *_ec .CT_TblPr ,_edaf *_ec .CT_TblPrEx ,_gfag ,_gecdb ,_adeg ,_gac int ,_cgda []*_ec .CT_TblStylePr ,_bggc *_ec .CT_PPrGeneral ,_gebfc *_ec .CT_RPr ,_dcda bool ,_dece int ,_ddd []float64 )int {_gebfc ,_gdebd ,_aefgc ,_cfeb ,_bgdfc ,_cabde ,_efbf :=_cafc .getTableCellProperties (_aacf ,_ffab ,_edaf ,_cgda ,_gfag ,_gcbb .TcPr ,_gebfc ,_gecdb ,_adeg ,_gac );
@@ -35,7 +35,7 @@ _dfbgb =_cffb (_bef .PPr ,_bfdf ,_geef );};};}else {if _ddae {_daec :=_aabc .Add
 };if _ddda :=_fefb .ST_OnOff1 ;_ddda ==_ef .ST_OnOff1On {_dfbgb =_cffb (_bef .PPr ,_dccc .X ().PPr ,_dccc .X ().RPr );};break ;};};};if !_gddf {_dfbgb =_cffb (_bef .PPr ,_bggc ,_gebfc );};var _gbgd *_eg .TextStyle ;if _ggda !=nil &&_ggda ._aabb {_ddae =true ;
 };if _bef .EG_PContent ==nil {_ecbg :="\u0020";_gcbg =true ;_ggdb .Append (_ecbg );}else {for _gabb ,_fbdc :=range _bef .EG_PContent {if _dece !=-1&&_gabb < _dece {continue ;};_edce :=_fbdc .PContentChoice .EG_ContentRunContent ;_eag :=&link {};if _fbdc .PContentChoice .Hyperlink !=nil {_edce =_fbdc .PContentChoice .Hyperlink .PContentChoice .EG_ContentRunContent ;
 if _fbdc .PContentChoice .Hyperlink .IdAttr !=nil {_ddaed :=_cafc ._dcfba .DocRels ().GetByRelId (*_fbdc .PContentChoice .Hyperlink .IdAttr );_eag ._bafe =_ddaed .X ().CT_Relationship .TargetAttr ;_eag ._cga =_ddaed .X ().CT_Relationship .TargetModeAttr ;
-}else if _fbdc .PContentChoice .Hyperlink .AnchorAttr !=nil {_eag ._bafe =*_fbdc .PContentChoice .Hyperlink .AnchorAttr ;_eag ._cga =_ff .ST_TargetModeInternal ;};};_aceb :=_eg .TextStyle {};for _ ,_eabd :=range _edce {if _cegd :=_eabd .ContentRunContentChoice .Sdt ;
+}else if _fbdc .PContentChoice .Hyperlink .AnchorAttr !=nil {_eag ._bafe =*_fbdc .PContentChoice .Hyperlink .AnchorAttr ;_eag ._cga =_ff .ST_TargetModeInternal ;};};_aceb :=_eg .TextStyle {};for _ ,_eabd :=range _cafc .withTrackedRuns (_edce ){if _cegd :=_eabd .ContentRunContentChoice .Sdt ;
 _cegd !=nil {if _cegd .SdtContent !=nil {for _ ,_fcde :=range _cegd .SdtContent .EG_PContent {for _ ,_efa :=range _fcde .PContentChoice .EG_ContentRunContent {if _baagd :=_efa .ContentRunContentChoice .R ;_baagd !=nil {_gcbg ,_bfdg ,_dbga ,_aceb =_cafc .processCtr (_baagd ,_dfbgb ,_gcbg ,_eag ,_ggdb ,_dcda ,_dece ,_gabb ,_bfdg ,_aabc ,_dbga );
 if _gbgd ==nil {_gbgd =&_aceb ;};};if _bfdg > -1{break ;};};};};};if _adad :=_eabd .ContentRunContentChoice .R ;_adad !=nil {_gcbg ,_bfdg ,_dbga ,_aceb =_cafc .processCtr (_adad ,_dfbgb ,_gcbg ,_eag ,_ggdb ,_dcda ,_dece ,_gabb ,_bfdg ,_aabc ,_dbga );if _gbgd ==nil {_gbgd =&_aceb ;
 };};};};};if !_gcbg {_beeg :=_dbgb (_cafc ._dcfba ,_ec .NewCT_RPr (),_dfbgb );_dggd :=_ggdb .Append ("\u0020");_dggd .Style ,_ ,_ ,_ =_cafc .makeRunStyle (_beeg ,false ,false ,false ,false ,false );};if _ggdb !=nil {if _gdebd ==_eg .CellVerticalAlignmentTop {_dfbgb .TextAlignment =_ec .NewCT_TextAlignment ();
@@ -59,10 +59,10 @@ _abegb ._bbe =append (_abegb ._bbe ,_adce );}else {_dafea :=&borderLine {_gggg :
 
 // RegisterFont makes a PdfFont accessible for using in converting to PDF.
 func RegisterFont (name string ,style FontStyle ,font *_gf .PdfFont ){_d .RegisterFont (name ,style ,font );};type tableCellProperties struct{_gbg *_ec .CT_Tc ;_gbf *_ec .CT_TblPr ;_cba *_ec .CT_TblPrEx ;_bgf int ;_ebd int ;_egb int ;_cfbb int ;_ge []*_ec .CT_TblStylePr ;
-_cfd *_ec .CT_PPrGeneral ;_dga *_ec .CT_RPr ;_ffg bool ;_edc int ;_eab bool ;_aaf bool ;_cg float64 ;};type borderLine struct{_cdc _eg .Color ;_gggg _d .BorderPosition ;_bfde float64 ;_fe float64 ;_bbg float64 ;};type convertContext struct{_fggf *_eg .Creator ;
+_cfd *_ec .CT_PPrGeneral ;_dga *_ec .CT_RPr ;_ffg bool ;_edc int ;_eab bool ;_aaf bool ;_cg float64 ;};type borderLine struct{_cdc _eg .Color ;_gggg _d .BorderPosition ;_bfde float64 ;_fe float64 ;_bbg float64 ;};type convertContext struct{_fggf *_eg .Creator ;_fallback *_d .Fallback ;_fields map[*_ec .CT_R ]*paragraph ;
 _dcfba *_ba .Document ;_aged *_ec .CT_PPrGeneral ;_bbda *_ec .CT_RPr ;_dcaab []*page ;_gfga *page ;_afda *_d .Rectangle ;_fegf *paragraph ;_dfac *line ;_gfgec *span ;_fagd *word ;_ceea *_ec .CT_Hyperlink ;_ebbgd *_ec .CT_PPr ;_ebc []note ;_bdde *prefix ;
 _fbae bool ;_egdf bool ;_dbcf float64 ;_ggdae float64 ;_fggfc float64 ;_fcce float64 ;_cbcbf bool ;_acbd map[int64 ]map[int64 ]int64 ;_bbef map[string ]string ;_bgfda *Options ;_ebaa []*headerFooterRef ;_eabf []*headerFooterRef ;_bdeae map[string ]map[int64 ]*_ec .CT_Ind ;
-_ddeda float64 ;_affc float64 ;_afgf []float64 ;_bgfec *_d .Rectangle ;_fded *_ec .CT_PPr ;_gcdaf []*_ec .CT_Tbl ;_aeaa []float64 ;_efba map[*_eg .TextChunk ]string ;_ffaad map[string ]*_gf .PdfAnnotation ;};func _bafd (_aafag *_ba .Document ,_gdgdd string )[]*_ec .CT_TblStylePr {_cedga :=_aafag .GetStyleByID (_gdgdd );
+_ddeda float64 ;_affc float64 ;_afgf []float64 ;_bgfec *_d .Rectangle ;_fded *_ec .CT_PPr ;_gcdaf []*_ec .CT_Tbl ;_aeaa []float64 ;_efba map[*_eg .TextChunk ]string ;_ffaad map[string ]*_gf .PdfAnnotation ;_tags *documentTagger ;_review *review ;};func _bafd (_aafag *_ba .Document ,_gdgdd string )[]*_ec .CT_TblStylePr {_cedga :=_aafag .GetStyleByID (_gdgdd );
 var _bfae []*_ec .CT_TblStylePr ;if _bced :=_cedga .X ();_bced !=nil {if _becba :=_bced .BasedOn ;_becba !=nil {_bafd (_aafag ,_becba .ValAttr );};if len (_bced .TblStylePr )> 0{_bfae =_bced .TblStylePr ;};};return _bfae ;};func _cfcde (_baec *_ec .CT_TblWidth ,_eddbf ,_bgdec float64 )float64 {if _baec !=nil {if _cbef :=_baec .WAttr ;
 _cbef !=nil {if _febb :=_cbef .ST_DecimalNumberOrPercent ;_febb !=nil {if _abee :=_febb .ST_UnqualifiedPercentage ;_abee !=nil {switch _baec .TypeAttr {case _ec .ST_TblWidthDxa :return float64 (*_abee )/20;case _ec .ST_TblWidthPct :return float64 (*_abee )/100/50*_eddbf ;
 default:return _bgdec ;};};};};};return _bgdec ;};func _bcefe (_faaf string )uint16 {_efddd ,_aagf :=_bgce [_faaf ];if !_aagf {return 0;};return _efddd ;};
@@ -71,33 +71,32 @@ default:return _bgdec ;};};};};};return _bgdec ;};func _bcefe (_faaf string )uin
 func RegisterFontsFromDirectory (dirName string )error {return _d .RegisterFontsFromDirectory (dirName )};func (_bgaf *convertContext )shouldApplyContextualSpacing (Ppr *_ec .CT_PPr )bool {_aafcb :=_bgaf ._fded .PStyle ;return Ppr !=nil &&Ppr .ContextualSpacing !=nil &&_gecce (Ppr .ContextualSpacing )&&_bgaf ._fded .ContextualSpacing !=nil &&_gecce (_bgaf ._fded .ContextualSpacing )&&Ppr .PStyle !=nil &&_aafcb !=nil &&Ppr .PStyle .ValAttr ==_aafcb .ValAttr ;
 };func (_agcc *convertContext )moveCurrentParagraphToNewPage (){_agcc .newPage ();_adge :=_agcc ._fegf ._fgf -_agcc ._gfga ._fg ;_agcc ._fegf ._fgf -=_adge ;for _ ,_efea :=range _agcc ._fegf ._fda {_efea ._ebg .Translate (0,-_adge );};for _ ,_egggc :=range _agcc ._fegf ._eac {_egggc ._dgf -=_adge ;
 };for _ ,_cdbd :=range _agcc ._fegf ._gcg {_cdbd ._dgf -=_adge ;};for _ ,_afeg :=range _agcc ._fegf ._aed {_afeg ._bgc -=_adge ;};for _ ,_ccgb :=range _agcc ._fegf ._gdg {_ccgb ._bgc -=_adge ;};};type line struct{_efe float64 ;_eba float64 ;_de float64 ;
-_ebf float64 ;_cea float64 ;_da []*span ;_ac bool ;};func (_bbfe *convertContext )currentParagraphOverflowsCurrentPage ()bool {_geab :=_bbfe ._fegf ._fgf +_bbfe ._fegf ._aa .Top +_bbfe ._fegf ._aa .Bottom ;_ecbc :=_bbfe ._gfga ._gcc .Bottom -_bbfe ._fegf ._cc ;
+_ebf float64 ;_cea float64 ;_da []*span ;_ac bool ;_ordered bool ;};func (_bbfe *convertContext )currentParagraphOverflowsCurrentPage ()bool {_geab :=_bbfe ._fegf ._fgf +_bbfe ._fegf ._aa .Top +_bbfe ._fegf ._aa .Bottom ;_ecbc :=_bbfe ._gfga ._gcc .Bottom -_bbfe ._fegf ._cc ;
 if len (_bbfe ._gfga ._eaf )==0&&len (_bbfe ._fegf ._fga )> 0{_ecbc -=_adg ;};return _geab +_bbfe ._fegf ._gd > _ecbc ||_geab +_bbfe ._fegf ._gccg > _ecbc ;};func (_fgcdb *convertContext )autofitColumns (_agff *_eg .Table ,_bcgd float64 ,_dcf []float64 ,_aecg []float64 ){_cegc :=0.0;
 for _ ,_egecg :=range _dcf {_cegc +=_egecg ;};if _cegc <=0||_bcgd <=0||len (_dcf )!=len (_aecg ){return ;};_egbga :=map[int ]float64 {};_bfa :=map[int ]float64 {};for _ffa ,_bgdf :=range _aecg {if _bgdf *_cegc /_bcgd > _dcf [_ffa ]{_egbga [_ffa ]=_bgdf *_cegc /_bcgd -_dcf [_ffa ];
 }else {_dfff :=1.5;if _dcf [_ffa ]-_bgdf *_dfff *_cegc /_bcgd > 0{_bfa [_ffa ]=_dcf [_ffa ]-_bgdf *_dfff *_cegc /_bcgd ;};};};if len (_egbga )==0||len (_bfa )==0{return ;};for _efdg ,_dfed :=range _egbga {for _fadbe ,_egbb :=range _bfa {if _dfed < _egbb {_egbga [_efdg ]=0;
 _bfa [_fadbe ]-=_dfed ;_dcf [_efdg ]+=_egbb -_dfed ;_dcf [_fadbe ]-=_egbb -_dfed ;break ;}else {_bfa [_fadbe ]=0;_egbga [_efdg ]-=_egbb ;_dcf [_efdg ]+=_egbb ;_dcf [_fadbe ]-=_egbb ;};};};_acec :=_agff .SetColumnWidths (_dcf ...);if _acec !=nil {_eaa .Log .Debug ("\u0045\u0052\u0052\u004f\u0052\u003a \u0055\u006e\u0061\u0062\u006c\u0065\u0020\u0074\u006f\u0020\u0073\u0065\u0074\u0020\u0063\u006f\u006c\u0075\u006d\u006e \u0077\u0069\u0064\u0074\u0068\u0073\u0020\u0066\u006f\u0072\u0020\u0074\u0061\u0062l\u0065 \u0028\u0025\u0073\u0029",_acec .Error ());
-};};func (_fae *convertContext )processRtlLine (_ced *line ){_eaad :=_ced ._de ;for _ ,_cae :=range _ced ._da {for _ ,_ffc :=range _cae ._adb {_ffc ._fgd =_eaad -_ffc ._aggc ;_dbgf :=_ffc ._aggc ;for _ ,_ada :=range _ffc ._db {_ada ._ga =_dbgf -_ada ._ceeg ;
-_dbgf -=_ada ._ceeg ;};_eaad =_ffc ._fgd ;};};};func _bbfg (_feb *_eg .Creator ,_dbg *block ){_dbg ._cbg .SetPos (_dbg ._cca ,_dbg ._dgf );_eace :=_feb .Draw (_dbg ._cbg );if _eace !=nil {_eaa .Log .Debug ("\u0045\u0072\u0072or\u0020\u0064\u0072\u0061\u0077\u0069\u006e\u0067\u0020\u0062\u006c\u006f\u0063\u006b\u003a\u0020\u0025\u0073",_eace );
-};if _dbg ._bfd {_d .DrawRectangle (_feb ,&_d .Rectangle {Top :_dbg ._dgf ,Bottom :_dbg ._dgf +_dbg ._cbg .Height (),Left :_dbg ._cca ,Right :_dbg ._cca +_dbg ._cbg .Width ()},_dbg ._fdd ,_dbg ._agd );};};func (_fab *convertContext )addAbsoluteCRC (_dacg []*_ec .EG_ContentRunContent ,_aacb *_ec .CT_PPr )bool {for _ ,_bca :=range _dacg {if _ggcd :=_bca .ContentRunContentChoice .R ;
+};};func _bbfg (_feb *_eg .Creator ,_dbg *block ){_dbg ._cbg .SetPos (_dbg ._cca ,_dbg ._dgf );_eace :=_feb .Draw (_dbg ._cbg );if _eace !=nil {_eaa .Log .Debug ("\u0045\u0072\u0072or\u0020\u0064\u0072\u0061\u0077\u0069\u006e\u0067\u0020\u0062\u006c\u006f\u0063\u006b\u003a\u0020\u0025\u0073",_eace );
+};if _dbg ._bfd {_d .DrawRectangle (_feb ,&_d .Rectangle {Top :_dbg ._dgf ,Bottom :_dbg ._dgf +_dbg ._cbg .Height (),Left :_dbg ._cca ,Right :_dbg ._cca +_dbg ._cbg .Width ()},_dbg ._fdd ,_dbg ._agd );};};func (_fab *convertContext )addAbsoluteCRC (_dacg []*_ec .EG_ContentRunContent ,_aacb *_ec .CT_PPr )bool {for _ ,_bca :=range _fab .withTrackedRuns (_dacg ){if _ggcd :=_bca .ContentRunContentChoice .R ;
 _ggcd !=nil {if _aacb !=nil &&_aacb .PStyle !=nil {_gff :=_fab ._dcfba .GetStyleByID (_aacb .PStyle .ValAttr );if _ebfb :=_gff .X ();_ebfb !=nil {if _ebfb .QFormat !=nil &&_gecce (_ebfb .QFormat ){if _ebfb .RPr !=nil &&_aacb .RPr !=nil {_aacb .RPr =_acecf (_aacb .RPr ,_ebfb .RPr );
 };};if _ebfb .RPr !=nil {if _ebfb .UiPriority !=nil &&_ebfb .UiPriority .ValAttr > 0&&_ggcd .RPr ==nil {_aacb .RPr =_acecf (_aacb .RPr ,_ebfb .RPr );};_ggcd .RPr =_cfec (_ggcd .RPr ,_ebfb .RPr );};if _fab ._bdde !=nil {_ace ,_bafg :=_fab .getStyleProps (_aacb .PStyle .ValAttr ,_gff );
 _aacb =_cffb (_aacb ,_ace ,_bafg );_ggcd .RPr =_cfec (_ggcd .RPr ,_bafg );};};};_egc :=_aacb !=nil ||_ggcd .RPr !=nil ;if len (_ggcd .EG_RunInnerContent )==0&&_egc {_fab .addEmptyLine ();};_dca :=_dbgb (_fab ._dcfba ,_ggcd .RPr ,_aacb );if _fab ._bdde !=nil {_fab .addAbsoluteRIC (nil ,_dca ,_aacb );
-_fab ._bdde =nil ;_fab ._fegf ._bbf =true ;};for _ ,_cgaa :=range _ggcd .EG_RunInnerContent {if _fab .addAbsoluteRIC (_cgaa ,_dca ,_aacb ){return true ;};_fab ._fegf ._bbf =false ;};for _ ,_ee :=range _ggcd .Extra {if _caa ,_cdd :=_ee .(*_ec .AlternateContentRun );
+_fab ._bdde =nil ;_fab ._fegf ._bbf =true ;};for _ ,_cgaa :=range _ggcd .EG_RunInnerContent {if _cgaa .RunInnerContentChoice .InstrText !=nil &&_fab ._fields !=nil {_fab ._fields [_ggcd ]=_fab ._fegf ;};if _fab .addAbsoluteRIC (_cgaa ,_dca ,_aacb ){return true ;};_fab ._fegf ._bbf =false ;};for _ ,_ee :=range _ggcd .Extra {if _caa ,_cdd :=_ee .(*_ec .AlternateContentRun );
 _cdd {if _geg :=_caa .Choice ;_geg !=nil {if _dfea :=_geg .Drawing ;_dfea !=nil {for _ ,_dfga :=range _dfea .DrawingChoice {if _dfga .Inline ==nil {continue ;};_dfa :=_dfga .Inline ;_fccc :=_dfa .Extent ;if _fccc ==nil {return false ;};_ebad :=_fc .FromEMU (_fccc .CxAttr );
 _adgc :=_fc .FromEMU (_fccc .CyAttr );if _baba :=_dfa .Graphic ;_baba !=nil {if _bda :=_baba .GraphicData ;_bda !=nil {for _ ,_agf :=range _bda .Any {if _fafe ,_gcdg :=_agf .(*_ec .WdWsp );_gcdg {_gabg ,_ddca :=_fab .makeBlockFromWdWsp (_fafe );if _ddca !=nil {_eaa .Log .Debug ("C\u0061\u006e\u006e\u006ft \u0072e\u0061\u0064\u0020\u0062\u006co\u0063\u006b\u003a\u0020\u0025\u0073",_ddca );
 };if _gabg ==nil {continue ;};_gabg ._cbg .Scale (_ebad /_gabg ._cbg .Width (),_adgc /_gabg ._cbg .Height ());_fab .addInlineSymbol (&symbol {_dd :_adgc ,_ceeg :_ebad ,_af :_gabg });};};};};};};};};};};};return false ;};func (_dccd *convertContext )addAbsoluteRIC (_dec *_ec .EG_RunInnerContent ,_cgg *_ec .CT_RPr ,_afd *_ec .CT_PPr )bool {var _eec ,_dffc bool ;
 _fceg :=[]*symbol {};_ebdd :=false ;if _dec ==nil {if _dccd ._bdde !=nil {_cgee :=true ;for _ ,_bgfg :=range _dccd ._bdde ._bcfcd {if _ecad ,_gfff :=_fabf [_bgfg ];_gfff {_dffc =_dccd ._bdde ._edde ;_dccd ._bdde ._bcfcd =string (rune (_ecad ));_cgee =false ;
 };};_fceg =_gddd (_dccd ._bdde ._bcfcd ,"",true ,false ,_cgee );};}else {if _adac (_dec ){return true ;}else if _dec .RunInnerContentChoice .T !=nil {_fefa :=_dec .RunInnerContentChoice .T .Content ;_ggcb :=_afd ==nil ||_afd .Bidi ==nil ||_gecce (_afd .Bidi );
-if _cgg !=nil &&_gecce (_cgg .Rtl )&&_ggcb {_dccd ._dfac ._ac =true ;if _aeegd (_fefa ){_gfc ,_afee :=_gc .ArabicShape (_fefa );if _afee ==nil {_fefa =_gfc ;};};};if _afd !=nil &&_gecce (_afd .PageBreakBefore ){_dccd .moveCurrentParagraphToNewPage ();};
+_fbrtl :=_cgg !=nil &&_gecce (_cgg .Rtl );if (_fbrtl &&_ggcb )||(_afd !=nil &&_gecce (_afd .Bidi )){_dccd ._dfac ._ac =true ;_dccd ._fegf ._rtl =true ;};_fefa =_d .ShapeText (_fefa );if _afd !=nil &&_gecce (_afd .PageBreakBefore ){_dccd .moveCurrentParagraphToNewPage ();};
 if _cgg !=nil &&_gecce (_cgg .Caps ){_fefa =_ea .ToUpper (_fefa );};if _fefa ==""{_fefa ="\u0020";};_cgbf ,_ :=_cb .MatchString ("\u00ab\u002e\u002a\u00bb",_fefa );if len (_dccd ._fagd ._db )> 0&&_dccd ._fagd ._db [len (_dccd ._fagd ._db )-1]._dda &&_dccd ._fagd ._db [len (_dccd ._fagd ._db )-1]._ggg ==""&&!_cgbf {return false ;
-};if _efed :=_dccd ._ceea ;_efed !=nil &&_efed .IdAttr !=nil {_ebdd =true ;_fceg =_gddd (_fefa ,_dccd ._dcfba .GetTargetByRelId (*_efed .IdAttr ),false ,false ,false );}else {_fceg =_gddd (_fefa ,"",false ,false ,false );};if _cgg .Highlight !=nil {_bacd :=_age .HighlightColorToCreatorColorMap [_cgg .Highlight .ValAttr ];
+};if _efed :=_dccd ._ceea ;_efed !=nil &&_efed .IdAttr !=nil {_ebdd =true ;_fceg =_gddd (_fefa ,_dccd ._dcfba .GetTargetByRelId (*_efed .IdAttr ),false ,false ,false );}else {_fceg =_gddd (_fefa ,"",false ,false ,false );};for _ ,_fbsym :=range _fceg {_fbsym ._rtl =_fbrtl ;};if _cgg .Highlight !=nil {_bacd :=_age .HighlightColorToCreatorColorMap [_cgg .Highlight .ValAttr ];
 for _ ,_aefg :=range _fceg {_aefg ._agc =&_bacd ;};};}else if _cfcb :=_dec .RunInnerContentChoice .EndnoteReference ;_cfcb !=nil {_dbed :=_dccd ._dcfba .BodySection ().X ();_aeac :=_cfcb .IdAttr ;_ebfc :=_aeac ;_gffe :=_ec .ST_NumberFormatLowerRoman ;if _cdcf :=_dbed .EndnotePr ;
 _cdcf !=nil {if _abdc :=_cdcf .NumFmt ;_abdc !=nil {_gffe =_abdc .ValAttr ;};if _dfd :=_cdcf .NumStart ;_dfd !=nil {_ebfc +=_dfd .ValAttr -1;};};_cdg :=_affg (_ebfc ,_gffe );_aaab :=_dccd ._dcfba .Endnote (_aeac ).X ();if _aaab !=nil {_dccd ._ebc =append (_dccd ._ebc ,note {_gb :_cdg ,_bd :_aaab .EG_BlockLevelElts });
 _fceg =_gddd (_cdg ,"",true ,false ,false );};}else if _fecg :=_dec .RunInnerContentChoice .FootnoteReference ;_fecg !=nil {_bfgb :=_dccd ._dcfba .BodySection ().X ();_eaeg :=_fecg .IdAttr ;_fbfe :=_eaeg ;_deee :=_ec .ST_NumberFormatDecimal ;if _deb :=_bfgb .FootnotePr ;
 _deb !=nil {if _ccb :=_deb .NumFmt ;_ccb !=nil {_deee =_ccb .ValAttr ;};if _agaf :=_deb .NumStart ;_agaf !=nil {_fbfe +=_agaf .ValAttr -1;};};_abea :=_affg (_fbfe ,_deee );_fde :=_dccd ._dcfba .Footnote (_eaeg ).X ();if _fde !=nil {_bfdb :=&note {_gb :_abea ,_bd :_fde .EG_BlockLevelElts };
 _fbgb :=[][]*_ec .EG_ContentBlockContent {};for _ ,_dgabg :=range _fde .EG_BlockLevelElts {_fbgb =append (_fbgb ,_dgabg .BlockLevelEltsChoice .EG_ContentBlockContent );};_fdeb :=&prefix {_bcfcd :_abea };_dbb ,_eaaa :=_dccd .makePdfBlockFromCBCs (_fbgb ,_dccd ._gfga ._gcc .Right -_dccd ._gfga ._gcc .Left ,_dffg (1000),nil ,true ,_fdeb );
 if _eaaa !=nil {_eaa .Log .Debug ("C\u0061\u006e\u006e\u006f\u0074\u0020c\u006f\u006e\u0076\u0065\u0072\u0074\u0020\u0066\u006fo\u0074\u006e\u006ft\u0065:\u0020\u0025\u0073",_eaaa );return false ;};_bfdb ._ebe =_dbb ;_dccd ._fegf ._fga =append (_dccd ._fegf ._fga ,_bfdb );
-_dccd ._fegf ._cc +=_bfdb ._ebe .Height ();_fceg =_gddd (_abea ,"",true ,false ,false );};}else if _fdee :=_dec .RunInnerContentChoice .InstrText ;_fdee !=nil {_ebfg :=_gfba (_fdee .Content );if _ebfg !=""{_fceg =_gddd (_dccd ._bbef [_ebfg ],"",false ,false ,false );
+_dccd ._fegf ._cc +=_bfdb ._ebe .Height ();_fceg =_gddd (_abea ,"",true ,false ,false );};}else if _cdbf :=_dec .RunInnerContentChoice .CommentReference ;_cdbf !=nil {_dccd .commentReference (_cdbf .IdAttr );}else if _fdee :=_dec .RunInnerContentChoice .InstrText ;_fdee !=nil {_ebfg :=_gfba (_fdee .Content );if _ebfg !=""{_fceg =_gddd (_dccd ._bbef [_ebfg ],"",false ,false ,false );
 };if _fdee .Content ==_ba .FieldCurrentPage {_fceg =_fbg ("\u005b\u0046\u0049E\u004c\u0044\u005f\u0050\u0041\u0047\u0045\u005d");};if _fdee .Content ==_ba .FieldNumberOfPages {_fceg =_fbg ("\u005b\u0046I\u0045\u004c\u0044_\u004e\u0055\u004d\u0050\u0041\u0047\u0045\u0053\u005d");
 };}else if _fbga :=_dec .RunInnerContentChoice .Drawing ;_fbga !=nil {for _ ,_edbe :=range _fbga .DrawingChoice {if _edbe .Inline ==nil {continue ;};_ggce :=_edbe .Inline ;if _efce :=_ggce .Graphic ;_efce !=nil {if _gabe :=_efce .GraphicData ;_gabe !=nil {_fedc :=_ggce .Extent ;
 if _fedc ==nil {return false ;};_agdca :=_fc .FromEMU (_fedc .CxAttr );_faeg :=_fc .FromEMU (_fedc .CyAttr );if _ddea :=_ggce .EffectExtent ;_ddea !=nil {if _ddea .LAttr .ST_CoordinateUnqualified !=nil {_agdca +=_fc .FromEMU (*_ddea .LAttr .ST_CoordinateUnqualified );
@@ -106,7 +105,7 @@ if _fedc ==nil {return false ;};_agdca :=_fc .FromEMU (_fedc .CxAttr );_faeg :=_
 };if _eece ==nil {_dabd ._ggg ="\u0020";}else {_gffff :=_fbe .BlipFill ;if _gffff .SrcRect !=nil {var _edab ,_gbbf ,_bce ,_ggf float64 ;_cgad :=_gffff .SrcRect ;if _cgad .LAttr !=nil {_edab =float64 (*_cgad .LAttr .ST_PercentageDecimal )/1000.0;};if _cgad .RAttr !=nil {_bce =float64 (*_cgad .RAttr .ST_PercentageDecimal )/1000.0;
 };if _cgad .TAttr !=nil {_gbbf =float64 (*_cgad .TAttr .ST_PercentageDecimal )/1000.0;};if _cgad .BAttr !=nil {_ggf =float64 (*_gffff .SrcRect .BAttr .ST_PercentageDecimal )/1000.0;};_abeg :=_eece .Width ();_ggggg :=_eece .Height ();_eece .Crop (int (_edab /100.0*_abeg ),int (_gbbf /100.0*_ggggg ),int (_abeg -(_bce /100.0*_abeg )),int (_ggggg -(_ggf /100.0*_ggggg )));
 };_gcaa :=false ;if _fbe .SpPr !=nil &&_fbe .SpPr .Xfrm !=nil {if _fbe .SpPr .Xfrm .RotAttr !=nil {_abcd :=_fc .DegreeFromSTAngle (*_fbe .SpPr .Xfrm .RotAttr );_eece .SetAngle (_abcd );};if _fbe .SpPr .Xfrm .Ext !=nil {_gcaa =true ;};};if _gcaa {_eece .ScaleToWidth (_agdca );
-}else {_eece .Scale (_agdca /_eece .Width (),_agdca /_eece .Width ());};_dabd ._ffge =_eece ;_eec =true ;};_fceg =[]*symbol {_dabd };}else if _gdbe ,_ffe :=_cfcc .(*_gg .Chart );_ffe {_cfbga :=&symbol {_dd :_faeg ,_ceeg :_agdca };_cabg ,_bgb :=_dccd .makePdfBlockFromChart (_gdbe ,_agdca ,_faeg );
+}else {_eece .Scale (_agdca /_eece .Width (),_agdca /_eece .Width ());};_dabd ._ffge =_eece ;_dabd ._inline =_ggce ;_eec =true ;};_fceg =[]*symbol {_dabd };}else if _gdbe ,_ffe :=_cfcc .(*_gg .Chart );_ffe {_cfbga :=&symbol {_dd :_faeg ,_ceeg :_agdca };_cabg ,_bgb :=_dccd .makePdfBlockFromChart (_gdbe ,_agdca ,_faeg );
 if _bgb !=nil {_eaa .Log .Debug ("C\u0061\u006e\u006e\u006ft \u0072e\u0061\u0064\u0020\u0062\u006co\u0063\u006b\u003a\u0020\u0025\u0073",_bgb );};if _cabg ==nil {_cfbga ._ggg ="\u0020";}else {_cfbga ._af =&block {_cbg :_cabg };_eec =true ;};_fceg =[]*symbol {_cfbga };
 };};};};};}else if _dfba :=_dec .RunInnerContentChoice .Pict ;_dfba !=nil {for _ ,_bbcac :=range _dfba .Any {if _beaa ,_gagg :=_bbcac .(*_ce .Group );_gagg {for _ ,_cbgc :=range _beaa .GroupChoice {if _cbgc .Rect !=nil {_dccd .addRect (_cbgc .Rect );}else if _cbgc .Shape !=nil {_cdfe :=_cbgc .Shape ;
 _afef :=_f .NewShapeStyle ("");if _cdfe .StyleAttr !=nil {_afef =_f .NewShapeStyle (*_cdfe .StyleAttr );};_gefgg :=_d .PointsFromTwips (int64 (_afef .Width ()));_gafe :=_d .PointsFromTwips (int64 (_afef .Height ()));_gagb :=_d .PointsFromTwips (int64 (_afef .Left ()-_afef .Right ()));
@@ -134,7 +133,7 @@ _dccd .newLine ();_dccd .newWord ();_dccd .addEmptyLine ();}else if _dec .RunInn
 };case _ec .ST_FldCharTypeEnd :if _dccd .isKnownField (){_dccd ._fagd ._db =append (_dccd ._fagd ._db ,&symbol {_dda :false });};};return false ;};};var _ebgf _eg .TextStyle ;var _bafeb ,_fdad bool ;var _dcdeb *_eg .Color ;if !_eec {_ebgf ,_bafeb ,_fdad ,_dcdeb =_dccd .makeRunStyle (_cgg ,false ,false ,false ,_dffc ,_ebdd );
 if _ebgf .Font !=nil &&(_dccd ._bgfda ==nil ||(_dccd ._bgfda !=nil &&_dccd ._bgfda .EnableFontSubsetting )){_dccd ._fggf .EnableFontSubsetting (_ebgf .Font );};};for _ ,_gcba :=range _fceg {if _gcba ._cd &&_dccd ._gfga ._fg > _dccd ._afda .Top {_dccd .addCurrentParagraphToCurrentPage ();
 _dccd .newPage ();_dccd .newParagraph ();_dccd .determineParagraphBounds ();_dccd .newLine ();_dccd .newWord ();continue ;};if _gcba ._ffge !=nil ||_gcba ._af !=nil {_dccd .addInlineSymbol (_gcba );}else {_gcba ._df =&_ebgf ;_gcba ._gdb =_bafeb ;_gcba ._bbff =_fdad ;
-_gcba ._fa =_dcdeb ;if _gcba ._eaag {_dbae :=*_cgg ;_dbae .B =nil ;_dbae .U =nil ;_fgb ,_ ,_ ,_ :=_dccd .makeRunStyle (&_dbae ,false ,false ,false ,_dffc ,_ebdd );_gcba ._df =&_fgb ;_gcba ._fa =nil ;};_dccd .addTextSymbol (_gcba );};};if _dccd ._bdde !=nil &&_dccd ._bdde ._aabb {var _gbge ,_babe float64 ;
+_gcba ._fa =_dcdeb ;_gcba ._strike =strikeColor (_cgg ,&_ebgf );if _gcba ._eaag {_dbae :=*_cgg ;_dbae .B =nil ;_dbae .U =nil ;_fgb ,_ ,_ ,_ :=_dccd .makeRunStyle (&_dbae ,false ,false ,false ,_dffc ,_ebdd );_gcba ._df =&_fgb ;_gcba ._fa =nil ;};_dccd .addTextSymbol (_gcba );_dccd .textLaidOut (_gcba );};};if _dccd ._bdde !=nil &&_dccd ._bdde ._aabb {var _gbge ,_babe float64 ;
 for _ ,_gcf :=range _fceg {_gbge +=_gcf ._ceeg ;};_dabc :=0;_cdca :=_dccd ._gfga ._gcc .Left ;_ddgc :=len (_dccd ._bdde ._bfbd );if _ddgc > 1&&_dccd ._bdde ._aabb {_ddgc =len (_dccd ._bdde ._bfbd )-1;};_edfae :=_dccd ._fegf ._bg < _gbge ;_gcdb :=_dccd ._dfac ._eba +_gbge ;
 for {var _bdg float64 ;if _edfae ||_dabc >=_ddgc {_bdg =_afge ;}else {_bdg =_dccd ._bdde ._bfbd [_dabc ];_dabc ++;};_cdca +=_bdg ;if _cdca > _gcdb {_babe =_cdca -_gcdb ;break ;};};_dccd .addTextSymbol (&symbol {_ggg :"\u0020",_ceeg :_babe });};return false ;
 };func (_cfed *convertContext )addAbsoluteTable (_dgd *_ec .CT_Tbl ){_dbc :=_dgd .TblGrid ;if _dbc ==nil {return ;};_efcd :=len (_dbc .GridCol );_bcb :=false ;if _efcd ==0{_efcd ,_bcb =_cfed .calculateTotalColumn (_dgd );};_cbcb :=[]float64 {};_eeg :=[]float64 {};
@@ -168,10 +167,10 @@ if _efbg .VertAlign ==nil {_efbg .VertAlign =_fece .VertAlign ;};if _efbg .Rtl =
 };if _efbg .SpecVanish ==nil {_efbg .SpecVanish =_fece .SpecVanish ;};if _efbg .OMath ==nil {_efbg .OMath =_fece .OMath ;};if _efbg .RPrChange ==nil {_efbg .RPrChange =_fece .RPrChange ;};return _efbg ;};
 
 // ConvertToPdfWithOptions convert the document to PDF with given options.
-func ConvertToPdfWithOptions (d *_ba .Document ,opts *Options )*_eg .Creator {var _aggab map[string ]string ;_d .DefaultFontSize =12;if opts !=nil {if opts .ProcessFields {_aggab =_ecfd (d );};if len (opts .FontFiles )> 0{_gbae :=_d .RegisterFontsFromFiles (opts .FontFiles );
+func ConvertToPdfWithOptions (d *_ba .Document ,opts *Options )*_eg .Creator {return convertDocument (d ,opts )._fggf };func convertDocument (d *_ba .Document ,opts *Options )*convertContext {_fcage :=layoutDocument (d ,opts );_fcage .tagDocument (d );_fcage .drawPages ();_fcage .drawHeaderFooter ();_fcage .setPdfOutput (d );return _fcage ;};func layoutDocument (d *_ba .Document ,opts *Options )*convertContext {var _aggab map[string ]string ;_d .DefaultFontSize =12;if opts !=nil {if opts .ProcessFields {_aggab =_ecfd (d );};if len (opts .FontFiles )> 0{_gbae :=_d .RegisterFontsFromFiles (opts .FontFiles );
 if _gbae !=nil {_eaa .Log .Debug ("\u0046\u0061\u0069\u006c t\u006f\u0020\u006c\u006f\u0061\u0064\u0020\u0066\u006f\u006e\u0074\u0073\u003a\u0020%\u0076",opts .FontDirectory );};};if opts .FontDirectory !=""{_egde :=_d .RegisterFontsFromDirectory (opts .FontDirectory );
 if _egde !=nil {_eaa .Log .Debug ("\u0046\u0061\u0069l\u0020\u0074\u006f\u0020l\u006f\u0061\u0064\u0020\u0066\u006f\u006et\u0020\u0064\u0069\u0072\u0065\u0063\u0074\u006f\u0072\u0079\u003a\u0020\u0025\u0076",_egde .Error ());};};if opts .DefaultFontSize > 0{_d .DefaultFontSize =float64 (opts .DefaultFontSize );
-};if len (opts .RtlFontFile )> 0{_d .RtlFontFile ,_ =_d .LoadFontFromFile (opts .RtlFontFile );};if opts .DefaultImageEncoder !=nil {_d .DefaultImageEncoder =opts .DefaultImageEncoder ;};};_faec :=_d .RegisterEmbeddedFonts (d );if _faec !=nil {_eaa .Log .Debug ("\u0046\u0061\u0069l\u0020\u0074\u006f\u0020l\u006f\u0061\u0064\u0020\u0065\u006d\u0062e\u0064\u0064\u0065\u0064\u0020\u0066\u006f\u006e\u0074\u0073\u003a\u0020\u0025\u0076",_faec .Error ());
+};if opts .DefaultImageEncoder !=nil {_d .DefaultImageEncoder =opts .DefaultImageEncoder ;};};_faec :=_d .RegisterEmbeddedFonts (d );if _faec !=nil {_eaa .Log .Debug ("\u0046\u0061\u0069l\u0020\u0074\u006f\u0020l\u006f\u0061\u0064\u0020\u0065\u006d\u0062e\u0064\u0064\u0065\u0064\u0020\u0066\u006f\u006e\u0074\u0073\u003a\u0020\u0025\u0076",_faec .Error ());
 };var (_cdcec *_ec .CT_PPrGeneral ;_abcc *_ec .CT_RPr ;);if _geagb :=d .Styles .X ().DocDefaults ;_geagb !=nil {if _egcfc :=_geagb .PPrDefault ;_egcfc !=nil {_cdcec =_egcfc .PPr ;};if _aaaeb :=_geagb .RPrDefault ;_aaaeb !=nil {_abcc =_aaaeb .RPr ;};};_abfa :=_d .GetDefaultPageSize ();
 if opts !=nil &&opts .DefaultPageSize !=_d .DefaultPageSize {_abfa =_d .GetPageDimensions (opts .DefaultPageSize );};_ccc :=_abfa [0];_geccf :=_abfa [1];_dfgdd :=_fc .Inch *1.0;_bdddg :=_fc .Inch *0.5;_fged ,_eddf ,_bdef ,_acdab :=_dfgdd ,_dfgdd ,_dfgdd ,_dfgdd ;
 _fbgge ,_caba :=_bdddg ,_bdddg ;var (_gbgdg []*headerFooterRef ;_bebc []*headerFooterRef ;);if _cfba :=d .BodySection ().X ();_cfba !=nil {if _ddaeg :=_cfba .PgMar ;_ddaeg !=nil {if _ddaeg .LeftAttr .ST_UnsignedDecimalNumber !=nil {_fged =_d .PointsFromTwips (int64 (*_ddaeg .LeftAttr .ST_UnsignedDecimalNumber ));
@@ -180,13 +179,13 @@ _fbgge ,_caba :=_bdddg ,_bdddg ;var (_gbgdg []*headerFooterRef ;_bebc []*headerF
 };};if _aeeg :=_cfba .PgSz ;_aeeg !=nil {if _aeeg .WAttr !=nil {_ccc =_d .PointsFromTwips (int64 (*_aeeg .WAttr .ST_UnsignedDecimalNumber ));};if _aeeg .HAttr !=nil {_geccf =_d .PointsFromTwips (int64 (*_aeeg .HAttr .ST_UnsignedDecimalNumber ));};};for _ ,_ecdc :=range _cfba .EG_HdrFtrReferences {if _dgdfe :=_ecdc .HdrFtrReferencesChoice .HeaderReference ;
 _dgdfe !=nil {_decg :=&headerFooterRef {_dgga :true ,_facab :_dgdfe .IdAttr ,_ageda :_dgdfe .TypeAttr ,_cfcd :-1};_gbgdg =append (_gbgdg ,_decg );};if _cebaf :=_ecdc .HdrFtrReferencesChoice .FooterReference ;_cebaf !=nil {_agaag :=&headerFooterRef {_eegb :true ,_facab :_cebaf .IdAttr ,_ageda :_cebaf .TypeAttr ,_cfcd :-1};
 _bebc =append (_bebc ,_agaag );};};if len (_cfba .EG_HdrFtrReferences )< 1{_dfcg :=&headerFooterRef {_eegb :false ,_dgga :false ,_cfcd :-1};_gbgdg =append (_gbgdg ,_dfcg );_bebc =append (_bebc ,_dfcg );};};if d .Settings .X ().DefaultTabStop ==nil {_afge =_dffg (12.7);
-}else {_afge =_d .PointsFromTwips (int64 (*d .Settings .X ().DefaultTabStop .ValAttr .ST_UnsignedDecimalNumber ));};_gacg :=_eg .New ();_gacg .SetPageSize (_eg .PageSize {_ccc ,_geccf });_gacg .SetPageMargins (_fged ,_eddf ,_bdef ,_acdab );_fcage :=&convertContext {_fggf :_gacg ,_dcfba :d ,_aged :_cdcec ,_bbda :_abcc ,_afda :&_d .Rectangle {Top :_bdef ,Bottom :_geccf -_acdab ,Left :_fged ,Right :_ccc -_eddf },_bgfec :&_d .Rectangle {Top :_bdef ,Bottom :_acdab ,Left :_fged ,Right :_eddf },_ebc :[]note {},_acbd :map[int64 ]map[int64 ]int64 {},_bbef :_aggab ,_bgfda :opts ,_ebaa :_gbgdg ,_eabf :_bebc ,_ggdae :_fbgge ,_ddeda :_bdef ,_fggfc :_geccf -_caba ,_affc :_acdab ,_dbcf :_fged ,_bdeae :map[string ]map[int64 ]*_ec .CT_Ind {},_afgf :[]float64 {_ccc ,_geccf },_gcdaf :[]*_ec .CT_Tbl {},_efba :map[*_eg .TextChunk ]string {},_ffaad :map[string ]*_gf .PdfAnnotation {}};
+}else {_afge =_d .PointsFromTwips (int64 (*d .Settings .X ().DefaultTabStop .ValAttr .ST_UnsignedDecimalNumber ));};_gacg :=_eg .New ();_gacg .SetPageSize (_eg .PageSize {_ccc ,_geccf });_gacg .SetPageMargins (_fged ,_eddf ,_bdef ,_acdab );_fcage :=&convertContext {_fallback :newFallback (opts ),_fields :map[*_ec .CT_R ]*paragraph {},_fggf :_gacg ,_dcfba :d ,_aged :_cdcec ,_bbda :_abcc ,_afda :&_d .Rectangle {Top :_bdef ,Bottom :_geccf -_acdab ,Left :_fged ,Right :_ccc -_eddf },_bgfec :&_d .Rectangle {Top :_bdef ,Bottom :_acdab ,Left :_fged ,Right :_eddf },_ebc :[]note {},_acbd :map[int64 ]map[int64 ]int64 {},_bbef :_aggab ,_bgfda :opts ,_ebaa :_gbgdg ,_eabf :_bebc ,_ggdae :_fbgge ,_ddeda :_bdef ,_fggfc :_geccf -_caba ,_affc :_acdab ,_dbcf :_fged ,_bdeae :map[string ]map[int64 ]*_ec .CT_Ind {},_afgf :[]float64 {_ccc ,_geccf },_gcdaf :[]*_ec .CT_Tbl {},_efba :map[*_eg .TextChunk ]string {},_ffaad :map[string ]*_gf .PdfAnnotation {},_review :newReview (d ,opts )};
 _fcage .calculateHdrFtrContentHeight ();_ffbe :=d .X ().Body .EG_BlockLevelElts ;_cfgc :=len (_ffbe );_fcage ._fded =nil ;for _cdbb ,_egef :=range _ffbe {var _dbab []*_ec .EG_ContentBlockContent ;if _cdbb < _cfgc -1{_gafa :=_ffbe [_cdbb +1];_dbab =_gafa .BlockLevelEltsChoice .EG_ContentBlockContent ;
-};_fcage .addAbsoluteCBCs (_egef .BlockLevelEltsChoice .EG_ContentBlockContent ,_dbab );};_fcage .processInternalLinks ();_fcage .addTableGroup ();_fcage ._fded =nil ;_fcage .addEndnotes ();_fcage .alignSymbolsVertically ();_fcage .drawPages ();_fcage .drawHeaderFooter ();
-return _gacg ;};var _afge float64 ;func (_dcfb *convertContext )processCtr (_aagd *_ec .CT_R ,_bde *_ec .CT_PPr ,_aeae bool ,_ffef *link ,_fgfg *_eg .StyledParagraph ,_ddbc bool ,_efae int ,_caad int ,_acdc int ,_gfcg *_eg .Division ,_fbca bool )(bool ,int ,bool ,_eg .TextStyle ){var _eeag _eg .TextStyle ;
+};_fcage .addAbsoluteCBCs (_egef .BlockLevelEltsChoice .EG_ContentBlockContent ,_dbab );};_fcage .processInternalLinks ();_fcage .addTableGroup ();_fcage ._fded =nil ;_fcage .addEndnotes ();_fcage .alignSymbolsVertically ();
+return _fcage ;};var _afge float64 ;func (_dcfb *convertContext )processCtr (_aagd *_ec .CT_R ,_bde *_ec .CT_PPr ,_aeae bool ,_ffef *link ,_fgfg *_eg .StyledParagraph ,_ddbc bool ,_efae int ,_caad int ,_acdc int ,_gfcg *_eg .Division ,_fbca bool )(bool ,int ,bool ,_eg .TextStyle ){var _eeag _eg .TextStyle ;
 _acgf :=_dbgb (_dcfb ._dcfba ,_aagd .RPr ,_bde );for _ ,_cdcb :=range _aagd .EG_RunInnerContent {var _gddc *_eg .TextChunk ;if _cdcb .RunInnerContentChoice .T !=nil {_acaf :=_cdcb .RunInnerContentChoice .T .Content ;if _acgf !=nil &&_gecce (_acgf .Caps ){_acaf =_ea .ToUpper (_acaf );
 };if _acaf ==""{_acaf ="\u0020";};_aeae =true ;if _ffef ._bafe !=""{if _ffef ._cga ==_ff .ST_TargetModeExternal {_gddc =_fgfg .AddExternalLink (_acaf ,_ffef ._bafe );}else if _ffef ._cga ==_ff .ST_TargetModeInternal {_gddc =_fgfg .Append (_acaf );_dcfb ._efba [_gddc ]=_ffef ._bafe ;
-};}else {_gddc =_fgfg .Append (_acaf );};if _acgf .Highlight !=nil {_gddc .Highlight (_age .HighlightColorToCreatorColorMap [_acgf .Highlight .ValAttr ],1.0);};_eeag ,_ ,_ ,_ =_dcfb .makeRunStyle (_acgf ,false ,false ,false ,false ,false );_gddc .Style =_eeag ;
+};}else {_gddc =_fgfg .Append (_acaf );};if _acgf .Highlight !=nil {_gddc .Highlight (_age .HighlightColorToCreatorColorMap [_acgf .Highlight .ValAttr ],1.0);};_eeag ,_ ,_ ,_ =_dcfb .makeRunStyle (_acgf ,false ,false ,false ,false ,false );_gddc .Style =_eeag ;_dcfb ._fallback .Chunk (_fgfg ,_gddc );
 }else if _cdcb .RunInnerContentChoice .LastRenderedPageBreak !=nil &&!_ddbc &&_efae !=_caad {_acdc =_caad ;break ;}else if _cdcb .RunInnerContentChoice .Br !=nil {_fgfg .Append ("\u000a\u0020");_aeae =true ;}else if _cdcb .RunInnerContentChoice .Drawing !=nil {for _ ,_ageb :=range _cdcb .RunInnerContentChoice .Drawing .DrawingChoice {if _ageb .Inline ==nil {continue ;
 };_fdfc :=_ageb .Inline ;if _fbbf :=_fdfc .Graphic ;_fbbf !=nil {if _bffc :=_fbbf .GraphicData ;_bffc !=nil {_bdfeg :=_fdfc .Extent ;if _bdfeg ==nil {continue ;};_bdcg :=_fc .FromEMU (_bdfeg .CxAttr );_bgdfe :=_fc .FromEMU (_bdfeg .CyAttr );for _ ,_dagcd :=range _bffc .Any {if _cgef ,_aaabc :=_dagcd .(*_be .Pic );
 _aaabc {if _cgef .BlipFill !=nil {_cgde ,_ccfc :=_dcfb .makePdfImageFromGraphics (_cgef );if _ccfc !=nil {_eaa .Log .Debug ("C\u0061\u006e\u006e\u006ft \u006da\u006b\u0065\u0020\u0069\u006da\u0067\u0065\u003a\u0020\u0025\u0073",_ccfc );};if _cgde !=nil {_cgde .Scale (_bdcg /_cgde .Width (),_bgdfe /_cgde .Height ());
@@ -198,22 +197,22 @@ _dafe :=_gfcg .Add (_cgde );if _dafe !=nil {_eaa .Log .Debug ("\u0045\u0052\u005
 };if _ebdg .CellMarkupElementsChoice .CellDel ==nil {_ebdg .CellMarkupElementsChoice .CellDel =_febc .CellMarkupElementsChoice .CellDel ;};if _ebdg .CellMarkupElementsChoice .CellMerge ==nil {_ebdg .CellMarkupElementsChoice .CellMerge =_febc .CellMarkupElementsChoice .CellMerge ;
 };if _ebdg .TcPrChange ==nil {_ebdg .TcPrChange =_febc .TcPrChange ;};return _ebdg ;};func (_cfeda *convertContext )addCurrentParagraphFooterToCurrentPage (){_cfeda .alignParagraph ();_cfeda ._gfga ._ebb =append (_cfeda ._gfga ._ebb ,_cfeda ._fegf );};
 type romanMatch struct{_dbfdg int ;_bfbg string ;};var _ccbg =_dffg (2.5);func (_acb *convertContext )drawPage (_gcd *page ){if _gcd ._dg {_ddc :=_gcd ._gcc .Top +_adg *_aga ;_ddf :=_gcd ._gcc .Left ;_bcg :=_gcd ._gcc .Right ;_d .DrawLine (_acb ._fggf ,_ddf ,_ddc ,_bcg ,_ddc ,_beg ,_eg .ColorBlack );
-};for _ ,_cgf :=range _gcd ._bba {_bff (_acb ._fggf ,_cgf );};for _ ,_aff :=range _gcd ._cbf {_bbfg (_acb ._fggf ,_aff );};for _ ,_gga :=range _gcd ._ed {if _gga ._cfb {_aecb :=_gga ._fgf +_adg *_aga ;_dfb :=_gcd ._gcc .Left ;_adfe :=_dfb +_dffg (50);_d .DrawLine (_acb ._fggf ,_dfb ,_aecb ,_adfe ,_aecb ,_beg ,_eg .ColorBlack );
-}else {for _ ,_gdc :=range _gga ._abf {if _gdc ._ac {_acb .processRtlLine (_gdc );};for _ ,_fba :=range _gdc ._da {for _ ,_aee :=range _fba ._adb {for _ ,_bbgf :=range _aee ._db {if _bbgf ._ffge !=nil {_bbgf ._ffge .SetPos (_aee ._fgd +_bbgf ._ga ,_gga ._fgf +_gdc ._efe );
+};for _ ,_cgf :=range _gcd ._bba {_acb ._tags .anchor (_cgf );_bff (_acb ._fggf ,_cgf );};for _ ,_aff :=range _gcd ._cbf {_bbfg (_acb ._fggf ,_aff );};for _ ,_gga :=range _gcd ._ed {_acb ._tags .paragraph (_gga );if _gga ._cfb {_aecb :=_gga ._fgf +_adg *_aga ;_dfb :=_gcd ._gcc .Left ;_adfe :=_dfb +_dffg (50);_d .DrawLine (_acb ._fggf ,_dfb ,_aecb ,_adfe ,_aecb ,_beg ,_eg .ColorBlack );
+}else {for _ ,_gdc :=range _gga ._abf {reorderLine (_gdc ,_gga ._rtl );for _ ,_fba :=range _gdc ._da {for _ ,_aee :=range _fba ._adb {for _ ,_bbgf :=range _aee ._db {if _bbgf ._ffge !=nil {_bbgf ._ffge .SetPos (_aee ._fgd +_bbgf ._ga ,_gga ._fgf +_gdc ._efe );_acb ._tags .inline (_bbgf );
 _edcd :=_acb ._fggf .Draw (_bbgf ._ffge );if _edcd !=nil {_eaa .Log .Debug ("\u0045\u0072\u0072or\u0020\u0064\u0072\u0061\u0077\u0069\u006e\u0067\u0020\u0069\u006d\u0061\u0067\u0065\u003a\u0020\u0025\u0073",_edcd );};}else if _bbgf ._af !=nil {_bbgf ._af ._cca =_aee ._fgd +_bbgf ._ga ;
-_bbgf ._af ._dgf =_gga ._fgf +_gdc ._efe ;_bbfg (_acb ._fggf ,_bbgf ._af );}else {_begf :=_acb ._fggf .NewStyledParagraph ();if _bbgf ._gdb {_bbgf ._aedg =0;}else if _bbgf ._bbff {_bbgf ._aedg =1.2*_gdc ._cea -_bbgf ._dd ;};_gda :=_aee ._fgd +_bbgf ._ga ;
+_bbgf ._af ._dgf =_gga ._fgf +_gdc ._efe ;_bbfg (_acb ._fggf ,_bbgf ._af );}else {_begf :=_acb ._fggf .NewStyledParagraph ();_acb ._tags .mark (_begf );if _bbgf ._gdb {_bbgf ._aedg =0;}else if _bbgf ._bbff {_bbgf ._aedg =1.2*_gdc ._cea -_bbgf ._dd ;};_gda :=_aee ._fgd +_bbgf ._ga ;
 _bcf :=_gga ._fgf +_gdc ._efe +_bbgf ._aedg ;_begf .SetPos (_gda ,_bcf );var _bdc *_eg .TextChunk ;if _bbgf ._ccf !=""{_bdc =_begf .AddExternalLink (_bbgf ._ggg ,_bbgf ._ccf );}else {_bdc =_begf .Append (_bbgf ._ggg );};if _bbgf ._df !=nil {_bdc .Style =*_bbgf ._df ;
-};if _bbgf ._agc !=nil {_bdc .Highlight (*_bbgf ._agc ,1.0);};_bgd :=_acb ._fggf .Draw (_begf );if _bgd !=nil {_eaa .Log .Debug ("\u0045\u0072\u0072\u006fr \u0064\u0072\u0061\u0077\u0069\u006e\u0067\u0020\u0074\u0065\u0078\u0074\u003a\u0020%\u0073",_bgd );
-};if _bbgf ._fa !=nil {_gef :=_bcf +_bbgf ._gea +2.0;_d .DrawLine (_acb ._fggf ,_gda ,_gef ,_gda +_bbgf ._ceeg ,_gef ,1,*_bbgf ._fa );};};};};};};if _gga ._baf !=nil {switch _gga ._baf ._ega {case _eg .HorizontalAlignmentCenter :_gga ._baf ._fdf .SetPos (_gga ._aec +(_gcd ._gcc .Right -_gcd ._gcc .Left -_gga ._baf ._cab )/2,_gga ._fgf +_gga ._aa .Top );
+};for _ ,_bdcf :=range _acb ._fallback .Chunk (_begf ,_bdc ){if _bbgf ._agc !=nil {_bdcf .Highlight (*_bbgf ._agc ,1.0);};};_bgd :=_acb ._fggf .Draw (_begf );if _bgd !=nil {_eaa .Log .Debug ("\u0045\u0072\u0072\u006fr \u0064\u0072\u0061\u0077\u0069\u006e\u0067\u0020\u0074\u0065\u0078\u0074\u003a\u0020%\u0073",_bgd );
+};if _bbgf ._fa !=nil {_gef :=_bcf +_bbgf ._gea +2.0;_d .DrawLine (_acb ._fggf ,_gda ,_gef ,_gda +_bbgf ._ceeg ,_gef ,1,*_bbgf ._fa );};if _bbgf ._strike !=nil {_gef :=_bcf +_bbgf ._gea *0.6;_d .DrawLine (_acb ._fggf ,_gda ,_gef ,_gda +_bbgf ._ceeg ,_gef ,1,*_bbgf ._strike );};_acb .drawComments (_gcd ,_bbgf ,_gda ,_bcf );};};};};};if _gga ._baf !=nil {switch _gga ._baf ._ega {case _eg .HorizontalAlignmentCenter :_gga ._baf ._fdf .SetPos (_gga ._aec +(_gcd ._gcc .Right -_gcd ._gcc .Left -_gga ._baf ._cab )/2,_gga ._fgf +_gga ._aa .Top );
 case _eg .HorizontalAlignmentRight :_gga ._baf ._fdf .SetPos (_gcd ._gcc .Right -_gga ._baf ._cab -_gga ._aa .Right ,_gga ._fgf +_gga ._aa .Top );default:_cabd :=_gga ._baf ._cee ;if _cabd ==0{_cabd =_gga ._aec ;};_gga ._baf ._fdf .SetPos (_cabd ,_gga ._fgf +_gga ._aa .Top );
-};_cbgd :=_eg .NewBlock (_gga ._baf ._cab ,_acb ._fggf .Height ());_cbgd .SetPos (0,0);_ =_cbgd .Draw (_gga ._baf ._fdf );_ =_acb ._fggf .Draw (_cbgd );};if _gga ._bbe !=nil {_bffd :=(_gcd ._gcc .Left /_d .DefaultFontSize -1);_eafg :=1.5;for _ ,_beb :=range _gga ._bbe {_gag :=_gga ._cff +_beb ._bfde +_bffd ;
+};_cbgd :=_eg .NewBlock (_gga ._baf ._cab ,_acb ._fggf .Height ());_cbgd .SetPos (0,0);_ =_cbgd .Draw (_gga ._baf ._fdf );_ =_acb ._fggf .Draw (_acb ._tags .table (_gga ,_cbgd ,_acb ._afgf [0],_acb ._afgf [1]));};if _gga ._bbe !=nil {_bffd :=(_gcd ._gcc .Left /_d .DefaultFontSize -1);_eafg :=1.5;for _ ,_beb :=range _gga ._bbe {_gag :=_gga ._cff +_beb ._bfde +_bffd ;
 if _gag > _gga ._fcc +_bffd {_gag =_gga ._fcc +_bffd ;};switch _beb ._gggg {case _d .BorderPositionTop :_cec :=_gga ._fgf +_beb ._fe ;_d .DrawLine (_acb ._fggf ,_gga ._cff -_bffd ,_cec ,_gag ,_cec ,_beb ._bbg ,_beb ._cdc );case _d .BorderPositionLeft :_baa :=_gga ._fgf +_gga ._gd -_gga ._aa .Top -_gga ._aa .Bottom -_beb ._fe -_eafg ;
 _fbd :=_baa +_gga ._gd +_gga ._aa .Top +_gga ._aa .Bottom ;_afc :=_gga ._cff -_bffd ;_d .DrawLine (_acb ._fggf ,_afc ,_baa ,_afc ,_fbd ,_beb ._bfde ,_beb ._cdc );case _d .BorderPositionBottom :_cdf :=_gga ._fgf +_beb ._fe +_gga ._aa .Top +_gga ._gd +_gga ._aa .Bottom ;
 _d .DrawLine (_acb ._fggf ,_gga ._cff -_bffd ,_cdf ,_gag ,_cdf ,_beb ._bbg ,_beb ._cdc );case _d .BorderPositionRight :_gaa :=_gga ._fgf +_gga ._gd -_gga ._aa .Top -_gga ._aa .Bottom -_beb ._fe -_eafg ;_ead :=_gaa +_gga ._gd +_gga ._aa .Top +_gga ._aa .Bottom ;
-_aedgb :=_gga ._fcc +_bffd ;_d .DrawLine (_acb ._fggf ,_aedgb ,_gaa ,_aedgb ,_ead ,_beb ._bfde ,_beb ._cdc );};};};};};for _ ,_ade :=range _gcd ._bdd {_bff (_acb ._fggf ,_ade );};for _ ,_eda :=range _gcd ._ecc {_bbfg (_acb ._fggf ,_eda );};if len (_gcd ._eaf )> 0{_gdd :=_gcd ._gcc .Bottom +_adg *_aga ;
+_aedgb :=_gga ._fcc +_bffd ;_d .DrawLine (_acb ._fggf ,_aedgb ,_gaa ,_aedgb ,_ead ,_beb ._bfde ,_beb ._cdc );};};};};};for _ ,_ade :=range _gcd ._bdd {_acb ._tags .anchor (_ade );_bff (_acb ._fggf ,_ade );};for _ ,_eda :=range _gcd ._ecc {_bbfg (_acb ._fggf ,_eda );};if len (_gcd ._eaf )> 0{_gdd :=_gcd ._gcc .Bottom +_adg *_aga ;
 _dab :=_gcd ._gcc .Left ;_gcca :=_dab +_dffg (50);_d .DrawLine (_acb ._fggf ,_dab ,_gdd ,_gcca ,_gdd ,_beg ,_eg .ColorBlack );_edd :=_gcd ._gcc .Bottom +_adg ;for _ ,_beed :=range _gcd ._eaf {_beed ._ebe .SetPos (_gcd ._gcc .Left ,_edd );_bfb :=_acb ._fggf .Draw (_beed ._ebe );
 if _bfb !=nil {_eaa .Log .Debug ("\u0045\u0072\u0072\u006f\u0072\u0020\u0064\u0072\u0061\u0077\u0069n\u0067\u0020\u0066\u006f\u006f\u0074\u006e\u006f\u0074\u0065:\u0020\u0025\u0073",_bfb );};_edd +=_beed ._ebe .Height ();};};};const (FontStyle_Regular FontStyle =0;
-FontStyle_Bold FontStyle =1;FontStyle_Italic FontStyle =2;FontStyle_BoldItalic FontStyle =3;);func _aeegd (_gfce string )bool {for _ ,_bfbc :=range _gfce {if _bfbc >=0x0600&&_bfbc <=0x06E0{return true ;};};return false ;};func (_gded *convertContext )addCurrentWordToParagraph (){for {_gecfe :=_gded ._dfac ._ebf ;
+FontStyle_Bold FontStyle =1;FontStyle_Italic FontStyle =2;FontStyle_BoldItalic FontStyle =3;);func (_gded *convertContext )addCurrentWordToParagraph (){for {_gecfe :=_gded ._dfac ._ebf ;
 _dfeca :=_gecfe +_gded ._fagd ._aggc ;if _dfeca > _gded ._dfac ._de {if len (_gded ._fagd ._db )==1&&_gded ._fagd ._db [0]._ffge !=nil {break ;};_gded .newLine ();};_bfda :=_gded ._fegf ._fgf +_gded ._dfac ._efe ;_ebdc :=_bfda +_gded ._dfac ._cea ;_egad :=false ;
 _cbac :=append (_gded ._gfga ._bc ,_gded ._fegf ._fda ...);for _ ,_dccf :=range _cbac {_ecgc :=_dccf ._ebg ;_fffg :=(_gecfe > _ecgc .Left &&_gecfe < _ecgc .Right )||(_dfeca > _ecgc .Left &&_dfeca < _ecgc .Right )||(_gecfe < _ecgc .Left &&_dfeca > _ecgc .Right );
 _eecg :=(_bfda > _ecgc .Top &&_bfda < _ecgc .Bottom )||(_ebdc > _ecgc .Top &&_ebdc < _ecgc .Bottom )||(_bfda < _ecgc .Top &&_ebdc > _ecgc .Bottom );if _dccf ._ab .WrapSquare !=nil &&_fffg &&_eecg {_egad =true ;if _gded ._dfac ._ebf < _ecgc .Right {_gded ._gfgec ._cgb =_ecgc .Left ;
@@ -252,15 +251,41 @@ DefaultPageSize _d .PageSize ;
 // Possible values compatible with MS Word are 11 or 12, by default it is 12.
 DefaultFontSize int ;
 
-// RtlFontFile is applied for RTL paragraphs. Only one font is currently supported
-// for RTL paragraphs within one document.
+// RtlFontFile is applied for RTL paragraphs. Characters it has no glyphs for are displayed
+// with the fonts of RtlFontFiles.
 RtlFontFile string ;
 
+// RtlFontFiles are further fonts for RTL text, tried for the Arabic and Hebrew characters missing
+// from the font of a run after the fonts of the FontFallback chains. The first one is applied for
+// RTL paragraphs if RtlFontFile is not set.
+RtlFontFiles []string ;
+
+// FontFallback sets the fonts tried for the characters missing from the font of a run and the
+// substitutes of fonts that are not registered, such as Carlito for Calibri. Built-in chains and
+// substitutes are used for the scripts and fonts it does not list. The fonts replaced are
+// returned by ConvertToPdfWithSubstitutions.
+FontFallback *FontFallback ;
+
 // DefaultImageEncoder sets the default image encoder for the convert process.
 // Default value is nil, which will use the best suitable encoder based on image format.
 // If image is `jpg` or `jpeg` will use `DCTEncoder` if image is `png` or in other format will use `FlateEncoder`.
 // Available options are `FlateEncoder`, `DCTEncoder`, `LZWEncoder`, `JBIG2Encoder`, `CCITTFaxEncoder`, and `RawEncoder`.
-DefaultImageEncoder _eb .StreamEncoder ;};func _dfffg (_gegd string )bool {for _ ,_gadd :=range _gegd {if _gadd > 255{return false ;};};return true ;};type tableWrapper struct{_fdf *_eg .Table ;_cab float64 ;_ega _eg .HorizontalAlignment ;_cee float64 ;
+DefaultImageEncoder _eb .StreamEncoder ;
+
+// PdfA selects the PDF/A conformance level of the output, PdfANone by default. Fonts are embedded
+// in PDF/A output, so the fonts used by the document should be provided with FontFiles or FontDirectory.
+PdfA PdfAConformance ;
+
+// Tagged produces a tagged PDF with a structure tree of the headings, lists, paragraphs, tables and
+// images of the document body, with the alternative text of images.
+Tagged bool ;
+
+// Comments sets how comments are rendered, CommentsNone by default. Comments are anchored at the
+// text preceding their reference, comments in table cells are left out.
+Comments CommentsMode ;
+
+// TrackChanges sets how tracked insertions and deletions are rendered, as if accepted by default.
+TrackChanges TrackChangesMode ;};func _dfffg (_gegd string )bool {for _ ,_gadd :=range _gegd {if _gadd > 255{return false ;};};return true ;};type tableWrapper struct{_fdf *_eg .Table ;_cab float64 ;_ega _eg .HorizontalAlignment ;_cee float64 ;_cells []*_ec .CT_Tc ;_header bool ;
 };func _acecf (_ccae *_ec .CT_ParaRPr ,_dcgf *_ec .CT_RPr )*_ec .CT_ParaRPr {if _dcgf ==nil {return _ccae ;};if _ccae ==nil {_ccae =_ec .NewCT_ParaRPr ();if _dcgf .B !=nil {_ccae .B =_dcgf .B ;};if _dcgf .BCs !=nil {_ccae .BCs =_dcgf .BCs ;};if _dcgf .I !=nil {_ccae .I =_dcgf .I ;
 };if _dcgf .ICs !=nil {_ccae .ICs =_dcgf .ICs ;};if _dcgf .U !=nil {_ccae .U =_dcgf .U ;};if _dcgf .Color !=nil {_ccae .Color =_dcgf .Color ;};return _ccae ;};if _ccae .B !=_dcgf .B {_ccae .B =_dcgf .B ;};if _ccae .BCs !=_dcgf .BCs {_ccae .BCs =_dcgf .BCs ;
 };if _ccae .I !=_dcgf .I {_ccae .I =_dcgf .I ;};if _ccae .ICs !=_dcgf .ICs {_ccae .ICs =_dcgf .ICs ;};if _ccae .U !=_dcgf .U {_ccae .U =_dcgf .U ;};if _ccae .Color !=_dcgf .Color {_ccae .Color =_dcgf .Color ;};return _ccae ;};func (_cgggc *convertContext )getStyleProps (_ecadf string ,_afde _ba .Style )(*_ec .CT_PPrGeneral ,*_ec .CT_RPr ){var _ebddc *_ec .CT_PPrGeneral ;
@@ -288,8 +313,8 @@ _bbddc .SetPageSize (_eg .PageSize {_gdfe ._fggf .Width (),_gdfe ._fggf .Height
 _cbegc .drawPages ();_cbegc .drawHeaderFooter ();_cbfe :=_bbddc .Finalize ();if _cbfe !=nil {_eaa .Log .Error ("\u0045R\u0052\u004f\u0052\u003a\u0020\u0025v",_cbfe );};if _cbegc ._ddeda >=_gdfe ._bgfec .Top {_gdfe ._afda .Top =_cbegc ._ddeda +_gdfe ._ggdae ;
 _gdfe ._bgfec .Top =_cbegc ._ddeda +_gdfe ._ggdae ;_gdfe ._ddeda =_cbegc ._ddeda ;};if _cbegc ._affc < _gdfe ._bgfec .Bottom {_bffbe :=(_cbegc ._affc /_gdfe ._bgfec .Bottom )*(_cbegc ._affc *_ae );_gdfe ._fggfc -=_bffbe ;}else {_gdfe ._fggfc -=_cbegc ._affc ;
 _gdfe ._afda .Bottom =_gdfe ._fggfc ;};_gdfe ._fggf .SetPageMargins (_gdfe ._bgfec .Left ,_gdfe ._bgfec .Right ,_gdfe ._bgfec .Top ,_gdfe ._bgfec .Bottom );};func (_dgfg *convertContext )drawHeaderFooter (){_dgfg .setPagesHeaderFooterRefs ();_dgfg ._fggf .PageFinalize (func (_fagda _eg .PageFinalizeFunctionArgs )error {_daeb :=_dgfg ._dcaab [_fagda .PageNum -1];
-_dgfg ._gfga =_daeb ;_dgfg ._gfga ._ecg =nil ;_dgfg ._gfga ._ebb =nil ;_dgfg .assignHeaderFooterToPage (_daeb );_dadf :=_eg .NewBlock (_dgfg ._afgf [0],_dgfg ._ddeda );_dadf .SetPos (0,0);_dadf .SetMargins (0,0,0,0);_dcbb :=_bdba (_dgfg ._fggf ,_dadf ,_dgfg ._gfga ._ecg ,_dgfg ._ggdae ,_fagda );
-_dgfg ._ddeda =_dcbb ;_aagb :=_eg .NewBlock (_dgfg ._afgf [0],_dgfg ._affc );_aagb .SetPos (0,0);_aagb .SetMargins (0,0,0,0);_dcbb =_bdba (_dgfg ._fggf ,_aagb ,_dgfg ._gfga ._ebb ,_dgfg ._fggfc ,_fagda );_dgfg ._affc =_dcbb ;_dgfg ._fggf .Draw (_dadf );
+_dgfg ._gfga =_daeb ;_dgfg ._gfga ._ecg =nil ;_dgfg ._gfga ._ebb =nil ;_dgfg .assignHeaderFooterToPage (_daeb );_dadf :=_eg .NewBlock (_dgfg ._afgf [0],_dgfg ._ddeda );_dadf .SetPos (0,0);_dadf .SetMargins (0,0,0,0);_dcbb :=_bdba (_dgfg ._fggf ,_dadf ,_dgfg ._gfga ._ecg ,_dgfg ._ggdae ,_fagda ,_dgfg ._fallback );
+_dgfg ._ddeda =_dcbb ;_aagb :=_eg .NewBlock (_dgfg ._afgf [0],_dgfg ._affc );_aagb .SetPos (0,0);_aagb .SetMargins (0,0,0,0);_dcbb =_bdba (_dgfg ._fggf ,_aagb ,_dgfg ._gfga ._ebb ,_dgfg ._fggfc ,_fagda ,_dgfg ._fallback );_dgfg ._affc =_dcbb ;_dgfg ._fggf .Draw (_dadf );
 _dgfg ._fggf .Draw (_aagb );return nil ;});};func (_ffdf *convertContext )addEmptyLine (){_ffdf .addTextSymbol (&symbol {_ggg :"\u000d",_ceeg :0,_dd :_ffdf ._fegf ._bgg });};func _adac (_dgaf *_ec .EG_RunInnerContent )bool {if _bfebc :=_dgaf .RunInnerContentChoice .Br ;
 _bfebc !=nil {return _bfebc .TypeAttr ==_ec .ST_BrTypePage ;};return false ;};func _dffg (_aafea float64 )float64 {return _aafea *_fc .Millimeter };func (_ddad *convertContext )getPageAccessiblePart ()float64 {_gebf :=_ddad ._gfga ._gcc .Bottom -_ddad ._gfga ._fg -_ddad ._fegf ._aa .Top -_ddad ._fegf ._aa .Bottom ;
 return _gebf ;};func (_afe *convertContext )addTableGroup (){if _afe ._fegf ==nil {_afe .newParagraph ();_afe .determineParagraphBounds ();_afe .newLine ();_afe .newWord ();};_dbf :=len (_afe ._gcdaf );if _dbf ==0{return ;};if _dbf ==1{_afe .addAbsoluteTable (_afe ._gcdaf [0]);
@@ -321,18 +346,18 @@ _ecb !=nil {if _ecb .PosOffset !=nil {_ggdf =_fc .FromEMU (int64 (*_ecb .PosOffs
 _dadb :=_cad ._fegf ._cff +_gcaf ;_ddeg :=_dadb +_ccdd ;_aab :=_ggdf +_gaaf ;if _aab > _cad ._fegf ._gccg {_cad ._fegf ._gccg =_aab ;};if _fead .WrapTypeChoice !=nil &&_fead .WrapTypeChoice .WrapNone ==nil {_cad ._fegf ._fda =append (_cad ._fegf ._fda ,&zoneToSkip {_ebg :&_d .Rectangle {Top :_ebba ,Bottom :_dae ,Left :_dadb ,Right :_ddeg },_ab :_fead .WrapTypeChoice ,_bf :_fead .RelativeHeightAttr });
 };if _agb :=_fead .Graphic ;_agb !=nil {if _faggg :=_agb .GraphicData ;_faggg !=nil {for _ ,_cbae :=range _faggg .Any {if _gbfb ,_bfg :=_cbae .(*_ec .WdWsp );_bfg {_dcaa ,_abe :=_cad .makeBlockFromWdWsp (_gbfb );if _abe !=nil {_eaa .Log .Debug ("C\u0061\u006e\u006e\u006ft \u0072e\u0061\u0064\u0020\u0062\u006co\u0063\u006b\u003a\u0020\u0025\u0073",_abe );
 };if _dcaa !=nil {_dcaa ._cbg .Scale (_ccdd /_dcaa ._cbg .Width (),_gaaf /_dcaa ._cbg .Height ());_dcaa ._cca =_dadb ;_dcaa ._dgf =_ebba ;if _fead .BehindDocAttr {_cad ._fegf ._gcg =append (_cad ._fegf ._gcg ,_dcaa );}else {_cad ._fegf ._eac =append (_cad ._fegf ._eac ,_dcaa );
-};};};};};};};};};};};};};};};func (_dcag *convertContext )addAbsoluteHeaderFooterCBCs (_aabcg []*_ec .EG_ContentBlockContent ){for _ ,_ffbf :=range _aabcg {for _ ,_ffeb :=range _ffbf .ContentBlockContentChoice .P {_dcag .newParagraph ();if _ffeb .PPr !=nil &&_ffeb .PPr .PStyle ==nil {_abgc :=_dcag ._dcfba .Styles .ParagraphStyles ();
+};};};};};};};};};};};};};};};func (_dcag *convertContext )addAbsoluteHeaderFooterCBCs (_aabcg []*_ec .EG_ContentBlockContent ){for _ ,_ffbf :=range _aabcg {for _ ,_ffeb :=range _ffbf .ContentBlockContentChoice .P {_dcag .newParagraph ();_dcag ._fegf ._src =_ffeb ;if _ffeb .PPr !=nil &&_ffeb .PPr .PStyle ==nil {_abgc :=_dcag ._dcfba .Styles .ParagraphStyles ();
 for _ ,_ggac :=range _abgc {if _fega :=_ggac .X ().DefaultAttr ;_fega !=nil {if _aeebag :=_fega .Bool ;_aeebag !=nil &&*_aeebag {_ffeb .PPr =_cffb (_ffeb .PPr ,_ggac .X ().PPr ,_ggac .X ().RPr );};if _ccdg :=_fega .ST_OnOff1 ;_ccdg ==_ef .ST_OnOff1On {_ffeb .PPr =_cffb (_ffeb .PPr ,_ggac .X ().PPr ,_ggac .X ().RPr );
 };break ;};};};_ddaec ,_fcfcd :=_dcag .combinePPrWithStyles (_ffeb .PPr );if _fcfcd !=nil {_dcag ._bdde =_fcfcd ;};_dcag .assignPropsToAbsoluteParagraph (_ddaec ,_dcag ._fegf );_dcag .determineParagraphBounds ();_dcag .newLine ();_dcag .newWord ();_bfcc :=_ffeb .EG_PContent ;
 if len (_bfcc )==0{_dcag .addEmptyLine ();}else {_dcag .addAnchorBlocks (_bfcc );_dcag .addAnchorExtra (_bfcc );_dcag .addAbsoluteEGPC (_bfcc ,_ddaec );_dcag .addCurrentWordToParagraph ();};if _dcag ._fbae {_dcag .addCurrentParagraphHeaderToCurrentPage ();
 }else {_dcag .addCurrentParagraphFooterToCurrentPage ();};};for _ ,_ffdce :=range _ffbf .ContentBlockContentChoice .Tbl {if _dcag ._fegf ==nil {_dcag .newParagraph ();_dcag .determineParagraphBounds ();_dcag .newLine ();_dcag .newWord ();};_dcag .addAbsoluteHeaderFooterTable (_ffdce );
-};};};func (_dde *convertContext )drawPages (){for _ ,_aba :=range _dde ._dcaab {_dde ._fggf .NewPage ();_dde .drawPage (_aba );};};func (_afab *convertContext )makePdfImageFromRelId (_cdbce *string )(*_eg .Image ,error ){if _cdbce !=nil {_ddde ,_dcafb :=_afab ._dcfba .GetHeaderFooterImageObjByRelId (*_cdbce ,_afab ._fbae ,_afab ._egdf );
+};};};func (_dde *convertContext )drawPages (){for _ ,_aba :=range _dde ._dcaab {_dde ._fggf .NewPage ();_dde ._tags .newPage ();_dde ._review .newPage ();_dde .drawPage (_aba );};};func (_afab *convertContext )makePdfImageFromRelId (_cdbce *string )(*_eg .Image ,error ){if _cdbce !=nil {_ddde ,_dcafb :=_afab ._dcfba .GetHeaderFooterImageObjByRelId (*_cdbce ,_afab ._fbae ,_afab ._egdf );
 if _dcafb !=nil {return nil ,_dcafb ;};_eaba ,_dcafb :=_bb .Open (_ddde .Path );if _dcafb !=nil {return nil ,_dcafb ;};_aedd ,_dcafb :=_ag .ReadAll (_eaba );if _dcafb !=nil {return nil ,_dcafb ;};_debb ,_dcafb :=_afab ._fggf .NewImageFromData (_aedd );
 if _dcafb !=nil {return nil ,_dcafb ;};if _d .DefaultImageEncoder !=nil {_debb .SetEncoder (_d .DefaultImageEncoder );}else {_debb .SetEncoder (_eb .NewFlateEncoder ());if _ea .ToLower (_ddde .Format )=="\u006a\u0070\u0067"||_ea .ToLower (_ddde .Format )=="\u006a\u0070\u0065\u0067"{_debb .SetEncoder (_eb .NewDCTEncoder ());
 };};return _debb ,nil ;};return nil ,nil ;};func _gbag (_ecbce *_ec .CT_Border )(_eg .CellBorderStyle ,*_eg .Color ,float64 ){if _ecbce ==nil {return _eg .CellBorderStyleNone ,nil ,0;};var _cedfd _eg .CellBorderStyle ;switch _ecbce .ValAttr {case _ec .ST_BorderSingle :_cedfd =_eg .CellBorderStyleSingle ;
 case _ec .ST_BorderDouble :_cedfd =_eg .CellBorderStyleDouble ;default:_cedfd =_eg .CellBorderStyleNone ;};_dgbc :=0.0;if _geaef :=_ecbce .SzAttr ;_geaef !=nil {_dgbc =float64 (*_geaef )/8;};var _dcdag _eg .Color ;if _adbca :=_ecbce .ColorAttr ;_adbca !=nil {if _gccc :=_adbca .ST_HexColorRGB ;
 _gccc !=nil {_dcdag =_eg .ColorRGBFromHex ("\u0023"+*_gccc );}else if _gacc :=_adbca .ST_HexColorAuto ;_gacc ==_ec .ST_HexColorAutoAuto {_dcdag =_eg .ColorBlack ;if _dgbc ==0{_dgbc =2.0/8.0;};};};return _cedfd ,&_dcdag ,_dgbc ;};type image struct{_gcgd *_eg .Image ;
-_bbc float64 ;_bgc float64 ;};func (_ddgf *convertContext )addCurrentParagraphHeaderToCurrentPage (){_ddgf .alignParagraph ();_ddgf ._gfga ._ecg =append (_ddgf ._gfga ._ecg ,_ddgf ._fegf );};func (_gabfe *convertContext )makePdfBlockFromCBCs (_bacdd [][]*_ec .EG_ContentBlockContent ,_bdeec ,_adgd float64 ,_ggbfe *_d .Rectangle ,_adceg bool ,_fcbg *prefix )(*_eg .Block ,error ){if _ggbfe ==nil {_ggbfe =&_d .Rectangle {};
+_bbc float64 ;_bgc float64 ;_anchor *_ec .WdAnchor ;};func (_ddgf *convertContext )addCurrentParagraphHeaderToCurrentPage (){_ddgf .alignParagraph ();_ddgf ._gfga ._ecg =append (_ddgf ._gfga ._ecg ,_ddgf ._fegf );};func (_gabfe *convertContext )makePdfBlockFromCBCs (_bacdd [][]*_ec .EG_ContentBlockContent ,_bdeec ,_adgd float64 ,_ggbfe *_d .Rectangle ,_adceg bool ,_fcbg *prefix )(*_eg .Block ,error ){if _ggbfe ==nil {_ggbfe =&_d .Rectangle {};
 };_dege :=&_d .Rectangle {Top :_ggbfe .Top ,Bottom :_adgd -_ggbfe .Bottom ,Left :_ggbfe .Left ,Right :_bdeec -_ggbfe .Right };_edfag :=_d .MakeTempCreator (_bdeec ,_adgd );_efbb :=&convertContext {_fggf :_edfag ,_dcfba :_gabfe ._dcfba ,_afda :_dege ,_bdde :_fcbg ,_bgfda :_gabfe ._bgfda };
 for _ ,_bffbea :=range _bacdd {_efbb .addAbsoluteCBCs (_bffbea ,nil );};if _adceg {_gfcggd :=0.0;for _ ,_edgdd :=range _efbb ._dcaab {for _ ,_aadce :=range _edgdd ._ed {_gfcggd +=(_aadce ._gd +_aadce ._aa .Top +_aadce ._aa .Bottom );};};_dege .Bottom =_gfcggd -_ggbfe .Bottom ;
 _edfag =_d .MakeTempCreator (_bdeec ,_gfcggd );_efbb =&convertContext {_fggf :_edfag ,_dcfba :_gabfe ._dcfba ,_afda :_dege ,_bdde :_fcbg ,_bgfda :_gabfe ._bgfda };for _ ,_addg :=range _bacdd {_efbb .addAbsoluteCBCs (_addg ,nil );};};_efbb .alignSymbolsVertically ();
@@ -354,7 +379,7 @@ if _cgdee ==""&&_cfeg !=""{for _ ,_fcfe :=range _eeadf .MajorFont .Font {if _fcf
 if _cgdee ==""&&_cfeg !=""{for _ ,_egbc :=range _eeadf .MinorFont .Font {if _egbc .ScriptAttr ==_cfeg {_cgdee =_egbc .TypefaceAttr ;break ;};};};break ;};}else if _eeadf .MajorFont !=nil &&_gfgf ==_dgeae {if _eeadf .MajorFont .Latin !=nil {_cgdee =_eeadf .MajorFont .Latin .TypefaceAttr ;
 break ;};}else if _eeadf .MinorFont !=nil &&_gfgf ==_eaddg {if _eeadf .MinorFont .Latin !=nil {_cgdee =_eeadf .MinorFont .Latin .TypefaceAttr ;break ;};};};};};};};};};};if _cgdee !="\u0064e\u0066\u0061\u0075\u006c\u0074"&&!_dfffg (_cgdee ){if _bagd :=_ddeag ._dcfba .FontTable ();
 _bagd !=nil {for _ ,_bdeg :=range _bagd .Font {if _bdeg .NameAttr ==_cgdee &&_bdeg .AltName !=nil &&_dfffg (_bdeg .AltName .ValAttr ){_cgdee =_bdeg .AltName .ValAttr ;break ;};if _bdeg .AltName !=nil &&!_dfffg (_bdeg .AltName .ValAttr )&&_bdeg .AltName .ValAttr ==_cgdee {_cgdee =_bdeg .NameAttr ;
-break ;};};};};if _gecce (_eada .Rtl )&&_d .RtlFontFile !=nil {_efcdc .Font =_d .RtlFontFile ;}else if _bdaac ,_dbgad :=_d .StdFontsMap [_cgdee ];_dbgad {_efcdc .Font =_d .AssignStdFontByName (_efcdc ,_bdaac [_gcadf ]);}else if _gaeaf :=_d .GetRegisteredFont (_cgdee ,_gcadf );
+break ;};};};};if _bgfb :=_ddeag ._fallback .RTLFont ();_gecce (_eada .Rtl )&&_bgfb !=nil {_efcdc .Font =_bgfb ;}else if _bdaac ,_dbgad :=_d .StdFontsMap [_cgdee ];_dbgad {_efcdc .Font =_d .AssignStdFontByName (_efcdc ,_bdaac [_gcadf ]);}else if _gaeaf :=_ddeag ._fallback .FindFont (_cgdee ,_gcadf );
 _gaeaf !=nil {_efcdc .Font =_gaeaf ;}else {_eaa .Log .Debug ("\u0046\u006f\u006e\u0074\u0020\u0025\u0073\u0020\u0077\u0069\u0074h\u0020\u0073\u0074\u0079\u006c\u0065\u0020\u0025s\u0020i\u0073\u0020\u006e\u006f\u0074\u0020\u0066\u006f\u0075\u006e\u0064\u002c\u0020\u0072\u0065\u0073\u0065\u0074 \u0074\u006f\u0020\u0064\u0065\u0066\u0061\u0075\u006c\u0074\u002e",_cgdee ,_gcadf );
 _efcdc .Font =_d .AssignStdFontByName (_efcdc ,_d .StdFontsMap ["\u0064e\u0066\u0061\u0075\u006c\u0074"][_gcadf ]);};};_edafg :=_aefd (_eada .Sz ,_eada .SzCs );if _dfbe :=_eada .VertAlign ;_dfbe !=nil {_agfbd :=_dfbe .ValAttr ;_cag =_agfbd ==_ef .ST_VerticalAlignRunSuperscript ;
 _debec =_agfbd ==_ef .ST_VerticalAlignRunSubscript ;};if _edafg > _ddeag ._fcce {_ddeag ._fcce =_edafg ;};if _cag ||_debec {_edafg *=0.64;};if _ddgce {if _cag {_efcdc .TextRise =1.5;};if _debec {_efcdc .TextRise =-1.5;};};_efcdc .FontSize =_edafg ;_dfge :=0.0;
@@ -379,7 +404,7 @@ _dged !=nil {if _dged .PosOffset !=nil {_fafb =_fc .FromEMU (int64 (*_dged .PosO
 };if _baef .WrapTypeChoice !=nil &&_baef .WrapTypeChoice .WrapNone ==nil {_fbc ._fegf ._fda =append (_fbc ._fegf ._fda ,&zoneToSkip {_ebg :&_d .Rectangle {Top :_ceec -_adfb ,Bottom :_cgac +_gdca ,Left :_afba -_edg ,Right :_fbfb +_gffa },_ab :_baef .WrapTypeChoice ,_bf :_baef .RelativeHeightAttr });
 };if _gecf :=_baef .Graphic ;_gecf !=nil {if _bgdea :=_gecf .GraphicData ;_bgdea !=nil {for _ ,_daaa :=range _bgdea .Any {if _gbc ,_bfbe :=_daaa .(*_be .Pic );_bfbe {_dcg ,_gca :=_fbc .makePdfImageFromGraphics (_gbc );if _gca !=nil {_eaa .Log .Debug ("C\u0061\u006e\u006e\u006ft \u0072e\u0061\u0064\u0020\u0069\u006da\u0067\u0065\u003a\u0020\u0025\u0073",_gca );
 };_gcda :=false ;_cedg :=0.0;if _gbc .SpPr !=nil &&_gbc .SpPr .Xfrm !=nil {if _gbc .SpPr .Xfrm .RotAttr !=nil {_cedg =_fc .DegreeFromSTAngle (*_gbc .SpPr .Xfrm .RotAttr );};if _ecfa :=_gbc .SpPr .Xfrm .Ext ;_ecfa !=nil {_gcda =true ;};};if _dcg !=nil {if !_gcda {_dcg .Scale (_bfdc /_dcg .Width (),_dfec /_dcg .Height ());
-}else {_dcg .ScaleToWidth (_bfdc );};_dcg .SetAngle (_cedg );_ggde :=&image {_gcgd :_dcg ,_bbc :_afba ,_bgc :_ceec };if _baef .BehindDocAttr {_fbc ._fegf ._gdg =append (_fbc ._fegf ._gdg ,_ggde );}else {_fbc ._fegf ._aed =append (_fbc ._fegf ._aed ,_ggde );
+}else {_dcg .ScaleToWidth (_bfdc );};_dcg .SetAngle (_cedg );_ggde :=&image {_gcgd :_dcg ,_bbc :_afba ,_bgc :_ceec ,_anchor :_baef };if _baef .BehindDocAttr {_fbc ._fegf ._gdg =append (_fbc ._fegf ._gdg ,_ggde );}else {_fbc ._fegf ._aed =append (_fbc ._fegf ._aed ,_ggde );
 };};}else if _fcd ,_bbb :=_daaa .(*_gg .Chart );_bbb {_bfba ,_cbc :=_fbc .makePdfBlockFromChart (_fcd ,_bfdc ,_dfec );if _cbc !=nil {_eaa .Log .Debug ("C\u0061\u006e\u006e\u006ft \u0072e\u0061\u0064\u0020\u0062\u006co\u0063\u006b\u003a\u0020\u0025\u0073",_cbc );
 };if _bfba !=nil {_cfga :=&block {_cbg :_bfba ,_cca :_afba ,_dgf :_ceec };if _baef .BehindDocAttr {_fbc ._fegf ._gcg =append (_fbc ._fegf ._gcg ,_cfga );}else {_fbc ._fegf ._eac =append (_fbc ._fegf ._eac ,_cfga );};};};};};};};};};};};};};func _geeg (_fbbcf *_ba .Document ,_eeaab *_ec .CT_TblPr )(*_ec .CT_TblPr ,*_ec .CT_PPrGeneral ,*_ec .CT_RPr ){_fgec :=_ec .NewCT_PPrGeneral ();
 _ddab :=_ec .NewCT_RPr ();if _eeaab ==nil {_eeaab =_ec .NewCT_TblPr ();}else {if _eeaab .TblStyle !=nil {_eeaab ,_fgec ,_ddab =_aacdb (_fbbcf ,_eeaab .TblStyle .ValAttr ,_eeaab ,_fgec ,_ddab );};};return _eeaab ,_fgec ,_ddab ;};func (_gcad *convertContext )setPagesHeaderFooterRefs (){var _gcee int ;
@@ -391,7 +416,7 @@ len (_cggg ._fb )> 0{_ccfcg ._fb =_cggg ._fb ;};};_gcad ._dcaab [_abdd ]._fb =_c
 break ;};if _ffbg ._ageda ==_ec .ST_HdrFtrDefault &&_abdd >=_ffbg ._ebgc &&_abdd <=_ffbg ._cfcd &&!_ggbfb &&!_gggc {_ccfcg ._efg =append (_ccfcg ._efg ,_ffbg );break ;};if _ffbg ._ageda ==_ec .ST_HdrFtrDefault &&_gggc {_ccfcg ._efg =append (_ccfcg ._efg ,_ffbg );
 break ;};};if _gggc ||len (_ccfcg ._efg )> 0{_dacc =true ;};_gggc =len (_ccfcg ._efg )< 1&&!_gggc &&!_ggbfb ;_ggbfb =false ;};}else if _abdd > 0{if _bbedc :=_gcad ._dcaab [_abdd -1];len (_bbedc ._efg )> 0{_ccfcg ._efg =_bbedc ._efg ;};};_gcad ._dcaab [_abdd ]._efg =_ccfcg ._efg ;
 };};};func (_cgae *convertContext )addAbsoluteCBCs (_cce []*_ec .EG_ContentBlockContent ,_fag []*_ec .EG_ContentBlockContent ){_ddg :="";_gfg :=false ;for _ ,_bcd :=range _fag {if len (_bcd .ContentBlockContentChoice .P )< 1{_gfg =true ;break ;};for _ ,_aad :=range _bcd .ContentBlockContentChoice .P {if len (_aad .EG_PContent )==0{break ;
-};if _aad .PPr !=nil &&_aad .PPr .PStyle !=nil {_ddg =_aad .PPr .PStyle .ValAttr ;break ;};};};for _ ,_dcb :=range _cce {for _ ,_aae :=range _dcb .ContentBlockContentChoice .P {_cgae .addTableGroup ();_cgae .newParagraph ();for _ ,_gad :=range _aae .EG_PContent {for _ ,_bddd :=range _gad .PContentChoice .EG_ContentRunContent {for _ ,_ggc :=range _bddd .ContentRunContentChoice .EG_RunLevelElts {for _ ,_egbf :=range _ggc .RunLevelEltsChoice .EG_RangeMarkupElements {if _egbf .RangeMarkupElementsChoice .BookmarkStart !=nil {_bbd :=_gf .NewPdfAnnotationLink ();
+};if _aad .PPr !=nil &&_aad .PPr .PStyle !=nil {_ddg =_aad .PPr .PStyle .ValAttr ;break ;};};};for _ ,_dcb :=range _cce {for _ ,_aae :=range _dcb .ContentBlockContentChoice .P {_cgae .addTableGroup ();_cgae .newParagraph ();_cgae ._fegf ._src =_aae ;for _ ,_gad :=range _aae .EG_PContent {for _ ,_bddd :=range _gad .PContentChoice .EG_ContentRunContent {for _ ,_ggc :=range _bddd .ContentRunContentChoice .EG_RunLevelElts {for _ ,_egbf :=range _ggc .RunLevelEltsChoice .EG_RangeMarkupElements {if _egbf .RangeMarkupElementsChoice .BookmarkStart !=nil {_bbd :=_gf .NewPdfAnnotationLink ();
 _abad :=_gf .NewBorderStyle ();_abad .SetBorderWidth (0);_bbd .BS =_abad .ToPdfObject ();_bbd .Dest =_eb .MakeArray (_eb .MakeInteger (int64 (len (_cgae ._dcaab )-1)),_eb .MakeName ("\u0058\u0059\u005a"),_eb .MakeFloat (_cgae ._dfac ._eba ),_eb .MakeFloat (_cgae ._dfac ._efe ),_eb .MakeFloat (0));
 _cgae ._ffaad [_egbf .RangeMarkupElementsChoice .BookmarkStart .NameAttr ]=_bbd .PdfAnnotation ;};};};};};if _aae .PPr !=nil &&_aae .PPr .PStyle ==nil {_bea :=_cgae ._dcfba .Styles .ParagraphStyles ();for _ ,_ggd :=range _bea {if _gaad :=_ggd .X ().DefaultAttr ;
 _gaad !=nil {if _bga :=_gaad .Bool ;_bga !=nil &&*_bga {_aae .PPr =_cffb (_aae .PPr ,_ggd .X ().PPr ,_ggd .X ().RPr );};if _gaag :=_gaad .ST_OnOff1 ;_gaag ==_ef .ST_OnOff1On {_aae .PPr =_cffb (_aae .PPr ,_ggd .X ().PPr ,_ggd .X ().RPr );};break ;};};};
@@ -432,7 +457,7 @@ _baaf :=map[string ]string {};for _ ,_gaef :=range _bfee .Tables (){for _ ,_caaf
 for _ ,_fgdg :=range _gdea .Tables (){for _ ,_fbbd :=range _fgdg .Rows (){for _ ,_bfeb :=range _fbbd .Cells (){_ffbcf =append (_ffbcf ,_bfeb .Paragraphs ()...);};};};};for _ ,_afgeg :=range _bfee .Footers (){_ffbcf =append (_ffbcf ,_afgeg .Paragraphs ()...);
 for _ ,_eaab :=range _afgeg .Tables (){for _ ,_cgba :=range _eaab .Rows (){for _ ,_fffb :=range _cgba .Cells (){_ffbcf =append (_ffbcf ,_fffb .Paragraphs ()...);};};};};for _ ,_cfag :=range _ffbcf {for _ ,_gegcd :=range _cfag .Runs (){for _ ,_baga :=range _gegcd .X ().EG_RunInnerContent {if _cefga :=_baga .RunInnerContentChoice .InstrText ;
 _cefga !=nil {_fgbd ,_decb :=_aabd (_cefga .Content );if _fgbd !=""&&_decb !=""{_baaf [_fgbd ]=_decb ;};};};};};return _baaf ;};type symbol struct{_ggg string ;_ga float64 ;_aedg float64 ;_ceeg float64 ;_dd float64 ;_gea float64 ;_df *_eg .TextStyle ;_ffge *_eg .Image ;
-_af *block ;_ccf string ;_gdb bool ;_bbff bool ;_dda bool ;_fa *_eg .Color ;_eaag bool ;_cd bool ;_agc *_eg .Color ;};func (_ecab *convertContext )assignPropsToAbsoluteParagraph (_dce *_ec .CT_PPr ,_gggbf *paragraph )(float64 ,float64 ){_ecab ._ebbgd =_dce ;
+_af *block ;_ccf string ;_gdb bool ;_bbff bool ;_dda bool ;_fa *_eg .Color ;_eaag bool ;_cd bool ;_agc *_eg .Color ;_inline *_ec .WdInline ;_strike *_eg .Color ;_comments []int64 ;_rtl bool ;};func (_ecab *convertContext )assignPropsToAbsoluteParagraph (_dce *_ec .CT_PPr ,_gggbf *paragraph )(float64 ,float64 ){_ecab ._ebbgd =_dce ;
 _dce =_cffb (_dce ,_ecab ._aged ,_ecab ._bbda );_gage :=12.4;if _dce ==nil {return 0,0;};_gggbf ._dc =0.0;if _cfgg :=_dce .RPr ;_cfgg !=nil {_ddbcd :=_aefd (_cfgg .Sz ,_cfgg .SzCs );if _ddbcd > _gage {_gage =_ddbcd ;}else {_gage =_ddbcd *_ae ;};_gggbf ._bgg =_gage ;
 };if _dce .Jc !=nil {switch _dce .Jc .ValAttr {case _ec .ST_JcRight :_gggbf ._bab =_eg .TextAlignmentRight ;case _ec .ST_JcCenter :_gggbf ._bab =_eg .TextAlignmentCenter ;case _ec .ST_JcBoth :_gggbf ._bab =_eg .TextAlignmentJustify ;case _ec .ST_JcEnd :_gggbf ._bab =_eg .TextAlignmentRight ;
 default:_gggbf ._bab =_eg .TextAlignmentLeft ;};};var _bdea ,_ddbd ,_aggee ,_dfdb ,_dded float64 ;if _ecfe :=_dce .Spacing ;_ecfe !=nil {if _bdee :=_ecfe .BeforeAttr ;_bdee !=nil {if _bdee .ST_UnsignedDecimalNumber !=nil {_bdea =_d .PointsFromTwips (int64 (*_bdee .ST_UnsignedDecimalNumber ));
@@ -511,7 +536,7 @@ if _bfc !=nil {_eaa .Log .Debug ("\u0045\u0052\u0052\u004f\u0052\u003a \u0055\u0
 };if _aeb {_beea :=_dcca .addRowToTable (_feba ,0,_bgff ,_gae );if _beea !=nil {_eaa .Log .Debug ("\u0045\u0052\u0052\u004f\u0052:\u0020\u0055\u006e\u0061\u0062\u006c\u0065\u0020\u0074\u006f\u0020\u0061\u0064d\u0020\u0068\u0065\u0061\u0064\u0065\u0072\u0020\u0074\u006f\u0020\u0074\u0061\u0062\u006c\u0065\u0020\u0028\u0025\u0073\u0029",_beea .Error ());
 };};for _dfbc :=_egfdb ;_dfbc <=_caac ;_dfbc ++{_abac :=_dcca .addRowToTable (_feba ,_dfbc ,_bgff ,_gae );if _abac !=nil {_eaa .Log .Debug ("\u0045\u0052\u0052\u004f\u0052\u003a\u0020\u0055\u006e\u0061\u0062\u006c\u0065\u0020\u0074\u006f\u0020\u0061\u0064\u0064\u0020\u0072\u006f\u0077 \u0074\u006f\u0020\u0074\u0061b\u006c\u0065 \u0028\u0025\u0073\u0029",_abac .Error ());
 };};_aebe :=_d .MakeTempCreatorMaxHeight (_badf );_bfc =_aebe .Draw (_bgff );if _bfc !=nil {_eaa .Log .Debug ("\u0045\u0052RO\u0052\u003a\u0020U\u006e\u0061\u0062\u006ce t\u006f s\u0065\u0074\u0020\u0064\u0072\u0061\u0077 t\u0061\u0062\u006c\u0065\u0020\u0028\u0025s\u0029",_bfc .Error ());
-};_dcca .addParagraphWithTable (*_bgff ,_badf ,_efge );};func _cffb (_aebeg *_ec .CT_PPr ,_ccfg *_ec .CT_PPrGeneral ,_gdcda *_ec .CT_RPr )*_ec .CT_PPr {if _aebeg ==nil {_aebeg =_ec .NewCT_PPr ();};if _ccfg !=nil {if _aebeg .Jc ==nil &&_ccfg .Jc !=nil {_aebeg .Jc =_ccfg .Jc ;
+};_dcca .addParagraphWithTable (*_bgff ,_badf ,_efge );_dcca ._fegf ._baf ._cells =rangeFirstCells (_feba ,_egfdb ,_caac ,_aeb );_dcca ._fegf ._baf ._header =_aeb ;};func _cffb (_aebeg *_ec .CT_PPr ,_ccfg *_ec .CT_PPrGeneral ,_gdcda *_ec .CT_RPr )*_ec .CT_PPr {if _aebeg ==nil {_aebeg =_ec .NewCT_PPr ();};if _ccfg !=nil {if _aebeg .Jc ==nil &&_ccfg .Jc !=nil {_aebeg .Jc =_ccfg .Jc ;
 };if _aebeg .Spacing ==nil {_aebeg .Spacing =_ccfg .Spacing ;}else if _cgaeg :=_ccfg .Spacing ;_cgaeg !=nil {if _aebeg .Spacing .BeforeAttr ==nil {_aebeg .Spacing .BeforeAttr =_cgaeg .BeforeAttr ;};if _aebeg .Spacing .AfterAttr ==nil {_aebeg .Spacing .AfterAttr =_cgaeg .AfterAttr ;
 };if _aebeg .Spacing .LineAttr ==nil {_aebeg .Spacing .LineAttr =_cgaeg .LineAttr ;};};if _aebeg .PageBreakBefore ==nil {_aebeg .PageBreakBefore =_ccfg .PageBreakBefore ;};if _ccfg .ContextualSpacing !=nil {_aebeg .ContextualSpacing =_ccfg .ContextualSpacing ;
 };if _ccfg .Ind !=nil {if _aebeg .Ind ==nil {_aebeg .Ind =_ccfg .Ind ;}else {_dbgd ,_bbecc :=_aebeg .Ind .FirstLineAttr ==nil ,_aebeg .Ind .HangingAttr ==nil ;if _dbgd &&_bbecc &&_ccfg .Ind .FirstLineAttr !=nil {_aebeg .Ind .FirstLineAttr =_ccfg .Ind .FirstLineAttr ;
@@ -562,11 +587,11 @@ _eebb !=nil {if _fefag :=_eebb .Blip ;_fefag !=nil {if _dcdgf :=_fefag .EmbedAtt
 if _cdcc !=nil {return nil ,_cdcc ;};_dcbbb ,_cdcc :=_ag .ReadAll (_fdbf );if _cdcc !=nil {return nil ,_cdcc ;};if _ea .ToLower (_cgbc .Format )=="\u0065\u006d\u0066"{_dfgg ,_ebfd :=_b .ReadFile (_dcbbb );if _ebfd !=nil {return nil ,_ebfd ;};_ffbeb :=new (_ad .Buffer );
 _dcbg :=_dfgg .Draw ();if _gfdf :=_g .Encode (_ffbeb ,_dcbg );_gfdf !=nil {return nil ,_gfdf ;};_dcbbb =_ffbeb .Bytes ();};_bgbba ,_cdcc :=_dfaf ._fggf .NewImageFromData (_dcbbb );if _cdcc !=nil {return nil ,_cdcc ;};if _d .DefaultImageEncoder !=nil {_bgbba .SetEncoder (_d .DefaultImageEncoder );
 }else {_bgbba .SetEncoder (_eb .NewFlateEncoder ());if _ea .ToLower (_cgbc .Format )=="\u006a\u0070\u0067"||_ea .ToLower (_cgbc .Format )=="\u006a\u0070\u0065\u0067"{_bgbba .SetEncoder (_eb .NewDCTEncoder ());};};return _bgbba ,nil ;};};};return nil ,nil ;
-};func _bdba (_cbcg *_eg .Creator ,_adeed *_eg .Block ,_fcbc []*paragraph ,_afdg float64 ,_baeab _eg .PageFinalizeFunctionArgs )float64 {_aeaee :=0.0;_dcded :=0.0;for _ ,_bfab :=range _fcbc {for _ ,_geadb :=range _bfab ._abf {for _ ,_gaagb :=range _geadb ._da {for _ ,_cbgdb :=range _gaagb ._adb {for _ ,_gdbc :=range _cbgdb ._db {if _gdbc ._ffge !=nil {_gdbc ._ffge .SetPos (_cbgdb ._fgd +_gdbc ._ga ,_afdg );
+};func _bdba (_cbcg *_eg .Creator ,_adeed *_eg .Block ,_fcbc []*paragraph ,_afdg float64 ,_baeab _eg .PageFinalizeFunctionArgs ,_cfba *_d .Fallback )float64 {_aeaee :=0.0;_dcded :=0.0;for _ ,_bfab :=range _fcbc {for _ ,_geadb :=range _bfab ._abf {reorderLine (_geadb ,_bfab ._rtl );for _ ,_gaagb :=range _geadb ._da {for _ ,_cbgdb :=range _gaagb ._adb {for _ ,_gdbc :=range _cbgdb ._db {if _gdbc ._ffge !=nil {_gdbc ._ffge .SetPos (_cbgdb ._fgd +_gdbc ._ga ,_afdg );
 _adeed .Draw (_gdbc ._ffge );}else if _gdbc ._af !=nil {if _gdbc ._af ._cca ==0{_gdbc ._af ._cca =_cbgdb ._fgd +_gdbc ._ga ;};if _gdbc ._af ._dgf ==0{_gdbc ._af ._dgf =_bfab ._fgf +_geadb ._efe ;};_bbfg (_cbcg ,_gdbc ._af );}else {_dbeb :=_cbcg .NewStyledParagraph ();
 if _gdbc ._gdb {_gdbc ._aedg =0;}else if _gdbc ._bbff {_gdbc ._aedg =1.2*_geadb ._cea -_gdbc ._dd ;};_beeee :=_cbgdb ._fgd +_gdbc ._ga +_dcded ;_fcac :=_afdg +_geadb ._efe +_gdbc ._aedg +_aeaee ;_dbeb .SetPos (_beeee ,_fcac );_bbfge :=false ;if _gdbc ._ggg =="\u005b\u0046\u0049E\u004c\u0044\u005f\u0050\u0041\u0047\u0045\u005d"{_gdbc ._ggg =_ca .Itoa (_baeab .PageNum );
 _bbfge =true ;};if _gdbc ._ggg =="\u005b\u0046I\u0045\u004c\u0044_\u004e\u0055\u004d\u0050\u0041\u0047\u0045\u0053\u005d"{_gdbc ._ggg =_ca .Itoa (_baeab .TotalPages );_bbfge =true ;};var _ggacf *_eg .TextChunk ;if _gdbc ._ccf !=""{_ggacf =_dbeb .AddExternalLink (_gdbc ._ggg ,_gdbc ._ccf );
-}else {_ggacf =_dbeb .Append (_gdbc ._ggg );};if _gdbc ._df !=nil {_ggacf .Style =*_gdbc ._df ;};if _bbfge {_dcded +=_dbeb .Width ();};_adeed .Draw (_dbeb );if _gdbc ._fa !=nil {_cffg :=_fcac +_gdbc ._dd ;_d .DrawLine (_cbcg ,_beeee ,_cffg ,_beeee +_gdbc ._ceeg ,_cffg ,1,*_gdbc ._fa );
-};};};};};};if _bfab ._baf !=nil {_dcbe :=_eg .NewBlock (_bfab ._baf ._cab ,_baeab .PageHeight );_dcbe .SetPos (_bfab ._aec ,_afdg );_dcbe .Draw (_bfab ._baf ._fdf );_adeed .Draw (_dcbe );_bfab ._gd =_bfab ._baf ._fdf .Height ();};_aeaee +=_bfab ._gd ;
+}else {_ggacf =_dbeb .Append (_gdbc ._ggg );};if _gdbc ._df !=nil {_ggacf .Style =*_gdbc ._df ;_cfba .Chunk (_dbeb ,_ggacf );};if _bbfge {_dcded +=_dbeb .Width ();};_adeed .Draw (_dbeb );if _gdbc ._fa !=nil {_cffg :=_fcac +_gdbc ._dd ;_d .DrawLine (_cbcg ,_beeee ,_cffg ,_beeee +_gdbc ._ceeg ,_cffg ,1,*_gdbc ._fa );
+};if _gdbc ._strike !=nil {_cffg :=_fcac +_gdbc ._gea *0.6;_d .DrawLine (_cbcg ,_beeee ,_cffg ,_beeee +_gdbc ._ceeg ,_cffg ,1,*_gdbc ._strike );};};};};};};if _bfab ._baf !=nil {_dcbe :=_eg .NewBlock (_bfab ._baf ._cab ,_baeab .PageHeight );_dcbe .SetPos (_bfab ._aec ,_afdg );_dcbe .Draw (_bfab ._baf ._fdf );_adeed .Draw (_dcbe );_bfab ._gd =_bfab ._baf ._fdf .Height ();};_aeaee +=_bfab ._gd ;
 };return _aeaee ;};type link struct{_bafe string ;_cga _ff .ST_TargetMode ;};func (_gfab *convertContext )addParagraphWithTable (_cgdb _eg .Table ,_feafg ,_cfbf float64 ){_gfab .newParagraph ();_gfab ._fegf ._aa =&_d .Rectangle {Top :_dffg (2),Bottom :_dffg (2),Left :0,Right :0};
 _gfab ._fegf ._baf =&tableWrapper {_fdf :&_cgdb ,_cab :_feafg };_gfab ._fegf ._bg =_cfbf ;_gfab ._fegf ._gd =_cgdb .Height ();_gfab .determineParagraphBounds ();_gfab .addCurrentParagraphToCurrentPage ();_gfab ._gfga ._cf =_gfab ._gfga ._fg ;};
\ No newline at end of file
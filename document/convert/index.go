@@ -0,0 +1,41 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	"github.com/unidoc/unioffice/v2/document"
+)
+
+// UpdateIndexes updates the INDEX fields of the body of d with the page
+// numbers the XE fields are on when laid out with the same engine and options
+// as ConvertToPdfWithOptions. Page numbers restart at the sections with a
+// starting page number, see Pagination.IndexEntryPage.
+func UpdateIndexes(d *document.Document, opts *Options) error {
+	indexes := d.Indexes()
+	if len(indexes) == 0 {
+		return nil
+	}
+	for _, ix := range indexes {
+		if _, err := ix.Options(); err != nil {
+			return err
+		}
+	}
+	// the first pass sets the length of the indexes, which moves the entries
+	// following them to their final pages
+	for pass := 0; pass < 2; pass++ {
+		pg := Paginate(d, opts)
+		for _, ix := range indexes {
+			if err := ix.Update(pg.IndexEntryPage); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,405 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+	"github.com/unidoc/unipdf/v4/creator"
+)
+
+// Box is the position and size of content on a page in points, measured from
+// the top left corner of the page.
+type Box struct {
+	X, Y          float64
+	Width, Height float64
+}
+
+// PageParagraph is a paragraph of the body, a header or a footer laid out on a
+// page.
+type PageParagraph struct {
+	Paragraph *wml.CT_P
+	Box       Box
+
+	// Continued is true if the paragraph started on a previous page.
+	Continued bool
+}
+
+// PageTableRow is a row of a body table laid out on a page. Rows of nested
+// tables are not included.
+type PageTableRow struct {
+	Table *wml.CT_Tbl
+	Row   *wml.CT_Row
+	Box   Box
+
+	// Header is true for a header row repeated at the top of a page.
+	Header bool
+}
+
+// PageImage is an image laid out on a page, either inline in a paragraph or
+// anchored.
+type PageImage struct {
+	Inline *wml.WdInline
+	Anchor *wml.WdAnchor
+	Box    Box
+}
+
+// Page is a laid out page.
+type Page struct {
+	// Number is the one-based page number.
+	Number int
+
+	// DisplayNumber is the number of the page in the page numbering of the
+	// document, which restarts at the sections with a starting page number.
+	DisplayNumber int
+
+	Width, Height float64
+
+	Paragraphs []PageParagraph
+	TableRows  []PageTableRow
+	Images     []PageImage
+
+	// Headers and Footers are the paragraphs of the header and footer of the
+	// page. Tables in headers and footers are not included.
+	Headers, Footers []PageParagraph
+}
+
+// Pagination is the result of laying out a document without drawing it.
+type Pagination struct {
+	Pages []*Page
+
+	_paragraphs map[*wml.CT_P]int
+	_rows       map[*wml.CT_Row]int
+	_cells      map[*wml.CT_P]*wml.CT_Row
+	_fields     map[*wml.CT_R]int
+}
+
+// Paginate lays out the document with the same engine and options as
+// ConvertToPdfWithOptions, without drawing the pages, and returns the content
+// of each page, including its header and footer.
+func Paginate(d *document.Document, opts *Options) *Pagination {
+	c := layoutDocument(d, opts)
+	pg := &Pagination{_paragraphs: map[*wml.CT_P]int{}, _rows: map[*wml.CT_Row]int{}, _cells: map[*wml.CT_P]*wml.CT_Row{}, _fields: map[*wml.CT_R]int{}}
+
+	// map the first cell of each body table row to the row and its table, and
+	// the paragraphs of the cells to their row
+	type tableRow struct {
+		tbl *wml.CT_Tbl
+		row *wml.CT_Row
+	}
+	firstCells := map[*wml.CT_Tc]tableRow{}
+	for _, ble := range d.X().Body.EG_BlockLevelElts {
+		bodyTables(ble.BlockLevelEltsChoice.EG_ContentBlockContent, func(tbl *wml.CT_Tbl) {
+			tableRows(tbl.EG_ContentRowContent, func(tr *wml.CT_Row) {
+				first := true
+				rowCells(tr.EG_ContentCellContent, func(tc *wml.CT_Tc) {
+					if first {
+						firstCells[tc] = tableRow{tbl, tr}
+						first = false
+					}
+					cellParagraphs(tc.EG_BlockLevelElts, func(p *wml.CT_P) { pg._cells[p] = tr })
+				})
+			})
+		})
+	}
+
+	width, height := 0.0, 0.0
+	if len(c._afgf) == 2 {
+		width, height = c._afgf[0], c._afgf[1]
+	}
+	parts := map[*paragraph]int{}
+	for i, p := range c._dcaab {
+		page := &Page{Number: i + 1, Width: width, Height: height}
+		for _, para := range p._ed {
+			parts[para] = i + 1
+			box := Box{X: para._cff, Y: para._fgf, Width: para._fcc - para._cff, Height: para._aa.Top + para._gd + para._aa.Bottom}
+			if tw := para._baf; tw != nil {
+				box = tableBox(p, para)
+				for row, tc := range tw._cells {
+					h, err := tw._fdf.GetRowHeight(row + 1)
+					if err != nil {
+						break
+					}
+					box.Height = h
+					header := tw._header && row == 0
+					if tr, ok := firstCells[tc]; ok {
+						page.TableRows = append(page.TableRows, PageTableRow{Table: tr.tbl, Row: tr.row, Box: box, Header: header})
+						if _, ok := pg._rows[tr.row]; !ok && !header {
+							pg._rows[tr.row] = i + 1
+						}
+					}
+					box.Y += h
+				}
+				continue
+			}
+			if para._src != nil {
+				_, continued := pg._paragraphs[para._src]
+				if !continued {
+					pg._paragraphs[para._src] = i + 1
+				}
+				page.Paragraphs = append(page.Paragraphs, PageParagraph{Paragraph: para._src, Box: box, Continued: continued})
+			}
+			for _, l := range para._abf {
+				for _, sp := range l._da {
+					for _, w := range sp._adb {
+						for _, sym := range w._db {
+							if sym._ffge == nil || sym._inline == nil {
+								continue
+							}
+							page.Images = append(page.Images, PageImage{Inline: sym._inline, Box: Box{
+								X: w._fgd + sym._ga, Y: para._fgf + l._efe, Width: sym._ffge.Width(), Height: sym._ffge.Height(),
+							}})
+						}
+					}
+				}
+			}
+		}
+		for _, imgs := range [][]*image{p._bdd, p._bba} {
+			for _, img := range imgs {
+				if img._anchor == nil || img._gcgd == nil {
+					continue
+				}
+				page.Images = append(page.Images, PageImage{Anchor: img._anchor, Box: Box{
+					X: img._bbc, Y: img._bgc, Width: img._gcgd.Width(), Height: img._gcgd.Height(),
+				}})
+			}
+		}
+		pg.Pages = append(pg.Pages, page)
+	}
+	for r, para := range c._fields {
+		if n, ok := parts[para]; ok {
+			pg._fields[r] = n
+		}
+	}
+	pg.numberPages(d)
+
+	// headers and footers are laid out for each page the way they are drawn
+	c.setPagesHeaderFooterRefs()
+	for i, p := range c._dcaab {
+		c._gfga = p
+		p._ecg, p._ebb = nil, nil
+		c.assignHeaderFooterToPage(p)
+		pg.Pages[i].Headers = headerFooterParagraphs(p._ecg, c._ggdae)
+		pg.Pages[i].Footers = headerFooterParagraphs(p._ebb, c._fggfc)
+	}
+	return pg
+}
+
+// headerFooterParagraphs returns the paragraphs of a header or footer, which
+// are stacked from y down.
+func headerFooterParagraphs(paras []*paragraph, y float64) []PageParagraph {
+	res := []PageParagraph{}
+	for _, para := range paras {
+		if para._src != nil && para._baf == nil {
+			res = append(res, PageParagraph{Paragraph: para._src, Box: Box{X: para._cff, Y: y, Width: para._fcc - para._cff, Height: para._gd}})
+		}
+		y += para._gd
+	}
+	return res
+}
+
+// numberPages sets the display numbers of the pages, restarting them on the
+// first page of each section with a starting page number.
+func (pg *Pagination) numberPages(d *document.Document) {
+	restarts := map[int]int{}
+	first := 0
+	endSection := func(sectPr *wml.CT_SectPr) {
+		if first > 0 && sectPr != nil && sectPr.PgNumType != nil && sectPr.PgNumType.StartAttr != nil {
+			restarts[first] = int(*sectPr.PgNumType.StartAttr)
+		}
+		first = 0
+	}
+	var walk func(cbcs []*wml.EG_ContentBlockContent)
+	walk = func(cbcs []*wml.EG_ContentBlockContent) {
+		for _, c := range cbcs {
+			if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+				walk(sdt.SdtContent.EG_ContentBlockContent)
+			}
+			for _, p := range c.ContentBlockContentChoice.P {
+				if n, ok := pg._paragraphs[p]; ok && first == 0 {
+					first = n
+				}
+				if p.PPr != nil && p.PPr.SectPr != nil {
+					endSection(p.PPr.SectPr)
+				}
+			}
+			for _, tbl := range c.ContentBlockContentChoice.Tbl {
+				tableRows(tbl.EG_ContentRowContent, func(tr *wml.CT_Row) {
+					if n, ok := pg._rows[tr]; ok && first == 0 {
+						first = n
+					}
+				})
+			}
+		}
+	}
+	for _, ble := range d.X().Body.EG_BlockLevelElts {
+		walk(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+	}
+	endSection(d.X().Body.SectPr)
+	n := 0
+	for _, page := range pg.Pages {
+		n++
+		if start, ok := restarts[page.Number]; ok {
+			n = start
+		}
+		page.DisplayNumber = n
+	}
+}
+
+// NumPages returns the number of pages.
+func (pg *Pagination) NumPages() int { return len(pg.Pages) }
+
+// PageOf returns the one-based number of the page a paragraph starts on. For
+// paragraphs in body tables it is the page on which their row starts. It
+// returns false if the paragraph is not in the body.
+func (pg *Pagination) PageOf(p document.Paragraph) (int, bool) {
+	if n, ok := pg._paragraphs[p.X()]; ok {
+		return n, true
+	}
+	n, ok := pg._rows[pg._cells[p.X()]]
+	return n, ok
+}
+
+// IndexEntryPage returns the display number of the page an index entry is on,
+// see Page.DisplayNumber. It is the page of the run holding the field, or
+// the page of its paragraph, see PageOf, for fields in tables and simple
+// fields. It returns false if the field is not in the body.
+func (pg *Pagination) IndexEntryPage(e document.IndexEntryField) (int, bool) {
+	n, ok := pg._fields[e.Run.X()]
+	if !ok {
+		n, ok = pg.PageOf(e.Paragraph)
+	}
+	if !ok || n < 1 || n > len(pg.Pages) {
+		return 0, false
+	}
+	return pg.Pages[n-1].DisplayNumber, true
+}
+
+// ParagraphPages lays out the document with the same engine and options as
+// ConvertToPdfWithOptions, without drawing the pages, and returns the
+// one-based number of the page on which each body paragraph starts.
+// Paragraphs inside body tables, including nested tables, are mapped to the
+// page on which their row of the body table starts.
+func ParagraphPages(d *document.Document, opts *Options) map[*wml.CT_P]int {
+	pg := Paginate(d, opts)
+	pages := map[*wml.CT_P]int{}
+	for p, n := range pg._paragraphs {
+		pages[p] = n
+	}
+	for p, row := range pg._cells {
+		if n, ok := pg._rows[row]; ok {
+			pages[p] = n
+		}
+	}
+	return pages
+}
+
+// bodyTables calls fn for the tables of cbcs, including the tables of
+// content controls but not nested tables.
+func bodyTables(cbcs []*wml.EG_ContentBlockContent, fn func(tbl *wml.CT_Tbl)) {
+	for _, c := range cbcs {
+		if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			bodyTables(sdt.SdtContent.EG_ContentBlockContent, fn)
+		}
+		for _, tbl := range c.ContentBlockContentChoice.Tbl {
+			fn(tbl)
+		}
+	}
+}
+
+func tableRows(rcs []*wml.EG_ContentRowContent, fn func(tr *wml.CT_Row)) {
+	for _, rc := range rcs {
+		if sdt := rc.ContentRowContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			tableRows(sdt.SdtContent.EG_ContentRowContent, fn)
+		}
+		for _, tr := range rc.ContentRowContentChoice.Tr {
+			fn(tr)
+		}
+	}
+}
+
+func rowCells(cccs []*wml.EG_ContentCellContent, fn func(tc *wml.CT_Tc)) {
+	for _, ccc := range cccs {
+		if sdt := ccc.ContentCellContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			rowCells(sdt.SdtContent.EG_ContentCellContent, fn)
+		}
+		for _, tc := range ccc.ContentCellContentChoice.Tc {
+			fn(tc)
+		}
+	}
+}
+
+// cellParagraphs calls fn for every paragraph of a cell, including the
+// paragraphs of nested tables.
+func cellParagraphs(blocks []*wml.EG_BlockLevelElts, fn func(p *wml.CT_P)) {
+	var walk func(cbcs []*wml.EG_ContentBlockContent)
+	walk = func(cbcs []*wml.EG_ContentBlockContent) {
+		for _, c := range cbcs {
+			if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+				walk(sdt.SdtContent.EG_ContentBlockContent)
+			}
+			for _, p := range c.ContentBlockContentChoice.P {
+				fn(p)
+			}
+			for _, tbl := range c.ContentBlockContentChoice.Tbl {
+				tableRows(tbl.EG_ContentRowContent, func(tr *wml.CT_Row) {
+					rowCells(tr.EG_ContentCellContent, func(tc *wml.CT_Tc) {
+						for _, ble := range tc.EG_BlockLevelElts {
+							walk(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+						}
+					})
+				})
+			}
+		}
+	}
+	for _, ble := range blocks {
+		walk(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+	}
+}
+
+// rangeFirstCells returns the first cell of each row in a range of table rows
+// being laid out, preceded by the first cell of the header row if it is
+// repeated. The cells line up with the rows of the laid out table, rows
+// without a first cell have a nil entry.
+func rangeFirstCells(rows map[int][]tableCellProperties, from, to int, header bool) []*wml.CT_Tc {
+	cells := []*wml.CT_Tc{}
+	first := func(r int) {
+		if rc := rows[r]; len(rc) > 0 {
+			cells = append(cells, rc[0]._gbg)
+		} else {
+			cells = append(cells, nil)
+		}
+	}
+	if header {
+		first(0)
+	}
+	for r := from; r <= to; r++ {
+		first(r)
+	}
+	return cells
+}
+
+// tableBox returns the box of the part of a table laid out as para on p, with
+// the height of the first row.
+func tableBox(p *page, para *paragraph) Box {
+	tw := para._baf
+	x := tw._cee
+	switch tw._ega {
+	case creator.HorizontalAlignmentCenter:
+		x = para._aec + (p._gcc.Right-p._gcc.Left-tw._cab)/2
+	case creator.HorizontalAlignmentRight:
+		x = p._gcc.Right - tw._cab - para._aa.Right
+	default:
+		if x == 0 {
+			x = para._aec
+		}
+	}
+	return Box{X: x, Y: para._fgf + para._aa.Top, Width: tw._cab}
+}
@@ -0,0 +1,32 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	goimage "image"
+	"io"
+
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/internal/convertutils"
+)
+
+// ConvertToImages renders the pages of the document to images with the
+// resolution dpi in dots per inch, 96 if dpi is not positive. The layout is
+// the same as that of ConvertToPdfWithOptions. Pages are given by their
+// one-based numbers, all pages are rendered if none are given.
+func ConvertToImages(d *document.Document, opts *Options, dpi float64, pages ...int) ([]goimage.Image, error) {
+	return convertutils.RenderPages(ConvertToPdfWithOptions(d, opts), dpi, pages...)
+}
+
+// ConvertToPNG renders the page with the one-based number page to w as a PNG
+// image with the resolution dpi, see ConvertToImages.
+func ConvertToPNG(d *document.Document, opts *Options, dpi float64, page int, w io.Writer) error {
+	return convertutils.RenderPNG(ConvertToPdfWithOptions(d, opts), dpi, page, w)
+}
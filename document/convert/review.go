@@ -0,0 +1,345 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/common/logger"
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+	"github.com/unidoc/unipdf/v4/core"
+	"github.com/unidoc/unipdf/v4/creator"
+	"github.com/unidoc/unipdf/v4/model"
+)
+
+// CommentsMode sets how comments are rendered, see Options.
+type CommentsMode int
+
+// CommentsMode constants.
+const (
+	// CommentsNone leaves comments out of the output.
+	CommentsNone CommentsMode = iota
+	// CommentsAnnotations adds comments as PDF text annotations at the
+	// commented text.
+	CommentsAnnotations
+	// CommentsBalloons draws comments in balloons in the right margin of the
+	// page, connected to the commented text.
+	CommentsBalloons
+)
+
+// TrackChangesMode sets how tracked changes are rendered, see Options.
+type TrackChangesMode int
+
+// TrackChangesMode constants.
+const (
+	// TrackChangesFinal renders the document as if all changes were accepted.
+	TrackChangesFinal TrackChangesMode = iota
+	// TrackChangesMarkup renders insertions underlined and deletions struck
+	// through, in a color per author.
+	TrackChangesMarkup
+)
+
+// reviewColors are the colors of the changes and comments of the authors of a
+// document, in order of their first appearance.
+var reviewColors = []string{"C0504D", "1F6FB2", "3C8A2E", "8064A2", "D9822B", "2B9BB0", "B2457D", "7F7F7F"}
+
+const (
+	balloonFontSize = 6.5
+	balloonPadding  = 2.0
+	balloonMinWidth = 40.0
+)
+
+// review holds the state of rendering the comments and tracked changes of a
+// document.
+type review struct {
+	_comments CommentsMode
+	_changes  TrackChangesMode
+	_byID     map[int64]*wml.CT_Comment
+	_authors  map[string]string
+
+	// _last is the last text symbol laid out and _pending holds comments
+	// referenced before any text
+	_last    *symbol
+	_pending []int64
+
+	// _balloonY is the bottom of the last balloon drawn on the page
+	_balloonY float64
+}
+
+func newReview(d *document.Document, opts *Options) *review {
+	r := &review{_byID: map[int64]*wml.CT_Comment{}, _authors: map[string]string{}}
+	if opts != nil {
+		r._comments, r._changes = opts.Comments, opts.TrackChanges
+	}
+	if r._comments != CommentsNone && d.HasComments() {
+		for _, c := range d.Comments() {
+			r._byID[c.X().IdAttr] = c.X()
+		}
+	}
+	return r
+}
+
+// color returns the RRGGBB color of an author.
+func (r *review) color(author string) string {
+	if c, ok := r._authors[author]; ok {
+		return c
+	}
+	c := reviewColors[len(r._authors)%len(reviewColors)]
+	r._authors[author] = c
+	return c
+}
+
+// trackedRuns returns the runs of the insertions and deletions of c to lay
+// out, restyled as markup if requested.
+func (c *convertContext) trackedRuns(crc *wml.EG_ContentRunContentChoice) []*wml.CT_R {
+	if crc == nil {
+		return nil
+	}
+	runs := []*wml.CT_R{}
+	for _, rle := range crc.EG_RunLevelElts {
+		ch := rle.RunLevelEltsChoice
+		for _, ins := range []*wml.CT_RunTrackChange{ch.Ins, ch.MoveTo} {
+			if ins != nil {
+				runs = append(runs, c.changedRuns(ins, false)...)
+			}
+		}
+		if c._review._changes != TrackChangesMarkup {
+			continue
+		}
+		for _, del := range []*wml.CT_RunTrackChange{ch.Del, ch.MoveFrom} {
+			if del != nil {
+				runs = append(runs, c.changedRuns(del, true)...)
+			}
+		}
+	}
+	return runs
+}
+
+// withTrackedRuns returns crcs with the runs of the tracked changes of each
+// content following it.
+func (c *convertContext) withTrackedRuns(crcs []*wml.EG_ContentRunContent) []*wml.EG_ContentRunContent {
+	res := make([]*wml.EG_ContentRunContent, 0, len(crcs))
+	for _, crc := range crcs {
+		res = append(res, crc)
+		for _, r := range c.trackedRuns(crc.ContentRunContentChoice) {
+			e := wml.NewEG_ContentRunContent()
+			e.ContentRunContentChoice.R = r
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+func (c *convertContext) changedRuns(tc *wml.CT_RunTrackChange, deleted bool) []*wml.CT_R {
+	runs := []*wml.CT_R{}
+	for _, ch := range tc.RunTrackChangeChoice {
+		if ch.ContentRunContentChoice == nil {
+			continue
+		}
+		if r := ch.ContentRunContentChoice.R; r != nil {
+			runs = append(runs, c.markupRun(r, tc.AuthorAttr, deleted))
+		}
+		runs = append(runs, c.trackedRuns(ch.ContentRunContentChoice)...)
+	}
+	return runs
+}
+
+// markupRun returns a copy of an inserted or deleted run underlined or struck
+// through in the color of the author, or r itself for final rendering.
+// Deleted text of the copy is turned into regular text.
+func (c *convertContext) markupRun(r *wml.CT_R, author string, deleted bool) *wml.CT_R {
+	if c._review._changes != TrackChangesMarkup {
+		return r
+	}
+	col := c._review.color(author)
+	m := *r
+	m.RPr = wml.NewCT_RPr()
+	if r.RPr != nil {
+		*m.RPr = *r.RPr
+	}
+	m.RPr.Color = wml.NewCT_Color()
+	m.RPr.Color.ValAttr.ST_HexColorRGB = &col
+	if !deleted {
+		m.RPr.U = wml.NewCT_Underline()
+		m.RPr.U.ValAttr = wml.ST_UnderlineSingle
+		return &m
+	}
+	m.RPr.Strike = wml.NewCT_OnOff()
+	m.EG_RunInnerContent = nil
+	for _, ric := range r.EG_RunInnerContent {
+		if dt := ric.RunInnerContentChoice.DelText; dt != nil {
+			ric = wml.NewEG_RunInnerContent()
+			ric.RunInnerContentChoice.T = wml.NewCT_Text()
+			ric.RunInnerContentChoice.T.Content = dt.Content
+		}
+		m.EG_RunInnerContent = append(m.EG_RunInnerContent, ric)
+	}
+	return &m
+}
+
+// strikeColor returns the color of the line through struck through text, or
+// nil.
+func strikeColor(rpr *wml.CT_RPr, style *creator.TextStyle) *creator.Color {
+	if rpr == nil || style == nil || !(_gecce(rpr.Strike) || _gecce(rpr.Dstrike)) {
+		return nil
+	}
+	col := style.Color
+	return &col
+}
+
+// commentReference anchors a comment at the text laid out last, or at the
+// next text if there is none.
+func (c *convertContext) commentReference(id int64) {
+	r := c._review
+	if _, ok := r._byID[id]; !ok {
+		return
+	}
+	if r._last != nil {
+		r._last._comments = append(r._last._comments, id)
+		return
+	}
+	r._pending = append(r._pending, id)
+}
+
+// textLaidOut records s as the text laid out last and anchors pending
+// comments at it.
+func (c *convertContext) textLaidOut(s *symbol) {
+	r := c._review
+	if r._comments == CommentsNone || s._ggg == "" {
+		return
+	}
+	r._last = s
+	if len(r._pending) > 0 {
+		s._comments = append(s._comments, r._pending...)
+		r._pending = nil
+	}
+}
+
+// commentText returns the author and text of a comment.
+func commentText(cm *wml.CT_Comment) (string, string) {
+	paras := []string{}
+	for _, ble := range cm.EG_BlockLevelElts {
+		for _, cbc := range ble.BlockLevelEltsChoice.EG_ContentBlockContent {
+			for _, p := range cbc.ContentBlockContentChoice.P {
+				text := ""
+				for _, pc := range p.EG_PContent {
+					for _, crc := range pc.PContentChoice.EG_ContentRunContent {
+						if r := crc.ContentRunContentChoice.R; r != nil {
+							for _, ric := range r.EG_RunInnerContent {
+								if t := ric.RunInnerContentChoice.T; t != nil {
+									text += t.Content
+								}
+							}
+						}
+					}
+				}
+				paras = append(paras, text)
+			}
+		}
+	}
+	return cm.AuthorAttr, strings.TrimSpace(strings.Join(paras, "\n"))
+}
+
+// drawComments draws the comments anchored at the text symbol s drawn at x, y
+// on page p.
+func (c *convertContext) drawComments(p *page, s *symbol, x, y float64) {
+	r := c._review
+	if r._comments == CommentsNone || len(s._comments) == 0 {
+		return
+	}
+	for _, id := range s._comments {
+		cm := r._byID[id]
+		author, text := commentText(cm)
+		col := creator.ColorRGBFromHex("#" + r.color(author))
+		switch r._comments {
+		case CommentsAnnotations:
+			c.drawCommentAnnotation(cm, author, text, col, x, y)
+		case CommentsBalloons:
+			c.drawCommentBalloon(p, author, text, col, x, y, s._gea)
+		}
+	}
+}
+
+// drawCommentAnnotation adds a text annotation with the comment at x, y.
+func (c *convertContext) drawCommentAnnotation(cm *wml.CT_Comment, author, text string, col creator.Color, x, y float64) {
+	width, height := c._afgf[0], c._afgf[1]
+	a := model.NewPdfAnnotationText()
+	a.Contents = core.MakeString(text)
+	a.T = core.MakeString(author)
+	a.Name = core.MakeName("Comment")
+	a.Open = core.MakeBool(false)
+	a.F = core.MakeInteger(4)
+	cr, cg, cb := col.ToRGB()
+	a.C = core.MakeArrayFromFloats([]float64{cr, cg, cb})
+	if cm.DateAttr != nil {
+		if d, err := model.NewPdfDateFromTime(*cm.DateAttr); err == nil {
+			a.M = d.ToPdfObject()
+			a.CreationDate = d.ToPdfObject()
+		}
+	}
+	a.Rect = core.MakeArrayFromFloats([]float64{x, height - y - 12, x + 12, height - y})
+	// annotations of a block drawn over the whole page keep page coordinates
+	b := creator.NewBlock(width, height)
+	b.SetPos(0, 0)
+	b.AddAnnotation(a.PdfAnnotation)
+	if err := c._fggf.Draw(b); err != nil {
+		logger.Log.Debug("Cannot draw comment: %s", err)
+	}
+}
+
+// drawCommentBalloon draws the comment in a balloon in the right margin of
+// page p, below the balloons drawn before, and connects it to the text at
+// x, y of height h.
+func (c *convertContext) drawCommentBalloon(p *page, author, text string, col creator.Color, x, y, h float64) {
+	left := p._gcc.Right + balloonPadding*2
+	width := c._afgf[0] - left - balloonPadding*2
+	if width < balloonMinWidth {
+		left, width = c._afgf[0]-balloonMinWidth-balloonPadding, balloonMinWidth
+	}
+	sp := c._fggf.NewStyledParagraph()
+	sp.SetMargins(0, 0, 0, 0)
+	sp.SetWidth(width - balloonPadding*2)
+	bold, err := model.NewStandard14Font(model.HelveticaBoldName)
+	if err != nil {
+		logger.Log.Debug("Cannot load balloon font: %s", err)
+		return
+	}
+	style := c._fggf.NewTextStyle()
+	style.FontSize = balloonFontSize
+	if author != "" {
+		chunk := sp.Append(author + ": ")
+		chunk.Style = style
+		chunk.Style.Font = bold
+	}
+	sp.Append(text).Style = style
+	top := y
+	if rv := c._review; top < rv._balloonY+balloonPadding {
+		top = rv._balloonY + balloonPadding
+	}
+	bh := sp.Height() + balloonPadding*2
+	rect := c._fggf.NewRectangle(left, top, width, bh)
+	rect.SetBorderColor(col)
+	rect.SetBorderWidth(0.75)
+	rect.SetFillColor(creator.ColorWhite)
+	line := c._fggf.NewLine(x, y+h, left, top+balloonPadding)
+	line.SetColor(col)
+	line.SetLineWidth(0.5)
+	sp.SetPos(left+balloonPadding, top+balloonPadding)
+	for _, d := range []creator.Drawable{line, rect, sp} {
+		if err := c._fggf.Draw(d); err != nil {
+			logger.Log.Debug("Cannot draw comment balloon: %s", err)
+		}
+	}
+	c._review._balloonY = top + bh
+}
+
+// newPage starts drawing the balloons of the next page.
+func (r *review) newPage() { r._balloonY = 0 }
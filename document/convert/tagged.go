@@ -0,0 +1,251 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/internal/convertutils"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+	"github.com/unidoc/unipdf/v4/core"
+	"github.com/unidoc/unipdf/v4/creator"
+	"github.com/unidoc/unipdf/v4/model"
+)
+
+// PdfAConformance is a PDF/A conformance level of the output, see Options.
+type PdfAConformance = convertutils.PdfAConformance
+
+// PdfAConformance constants.
+const (
+	PdfANone = convertutils.PdfANone
+	PdfA2B   = convertutils.PdfA2B
+	PdfA3B   = convertutils.PdfA3B
+)
+
+var headingTypes = []model.StructureType{model.StructureTypeHeading1, model.StructureTypeHeading2,
+	model.StructureTypeHeading3, model.StructureTypeHeading4, model.StructureTypeHeading5, model.StructureTypeHeading6}
+
+// documentTagger builds the structure tree of a tagged PDF while the laid out
+// pages are drawn. The methods of a nil documentTagger do nothing.
+type documentTagger struct {
+	_tagger     *convertutils.Tagger
+	_paragraphs map[*wml.CT_P]document.Paragraph
+	_rows       map[*wml.CT_Tc]taggedRow
+
+	// _elem is the element of the paragraph being drawn and _src its source
+	_elem  *model.KDict
+	_type  model.StructureType
+	_src   *wml.CT_P
+	_lists []taggedList
+}
+
+// taggedRow is a table row by its first cell.
+type taggedRow struct {
+	_cells  []string
+	_header bool
+}
+
+// taggedList is an open list and its last item.
+type taggedList struct {
+	_list, _item *model.KDict
+}
+
+// tagDocument starts building the structure tree if tagged output was
+// requested.
+func (c *convertContext) tagDocument(d *document.Document) {
+	if c._bgfda == nil || !c._bgfda.Tagged {
+		return
+	}
+	t := &documentTagger{_tagger: convertutils.NewTagger(), _paragraphs: map[*wml.CT_P]document.Paragraph{},
+		_rows: map[*wml.CT_Tc]taggedRow{}}
+	for _, p := range d.Paragraphs() {
+		t._paragraphs[p.X()] = p
+	}
+	for _, tbl := range d.Tables() {
+		for _, r := range tbl.Rows() {
+			cells := r.Cells()
+			if len(cells) == 0 {
+				continue
+			}
+			row := taggedRow{}
+			if pr := r.X().TrPr; pr != nil {
+				for _, ch := range pr.TrPrBaseChoice {
+					if ch.TblHeader != nil {
+						row._header = true
+					}
+				}
+			}
+			for _, cell := range cells {
+				text := []string{}
+				for _, p := range cell.Paragraphs() {
+					for _, run := range p.Runs() {
+						text = append(text, run.Text())
+					}
+				}
+				row._cells = append(row._cells, strings.TrimSpace(strings.Join(text, "")))
+			}
+			t._rows[cells[0].X()] = row
+		}
+	}
+	c._tags = t
+}
+
+// setPdfOutput applies the PDF/A and tagged output options to the creator.
+func (c *convertContext) setPdfOutput(d *document.Document) {
+	opts := c._bgfda
+	if opts == nil || (opts.PdfA == PdfANone && !opts.Tagged) {
+		return
+	}
+	var t *convertutils.Tagger
+	if c._tags != nil {
+		t = c._tags._tagger
+	}
+	lang := ""
+	if rpr := c._bbda; rpr != nil && rpr.Lang != nil && rpr.Lang.ValAttr != nil {
+		lang = *rpr.Lang.ValAttr
+	}
+	convertutils.SetPdfOutput(c._fggf, opts.PdfA, t, d.CoreProperties, lang)
+}
+
+// newPage starts tagging the next page.
+func (t *documentTagger) newPage() {
+	if t == nil {
+		return
+	}
+	t._tagger.NewPage()
+	t._elem = nil
+}
+
+// paragraph starts the element of a paragraph drawn on the current page,
+// which is a heading, a list item body or a plain paragraph. A paragraph
+// continued from the previous page gets an element of the same type.
+func (t *documentTagger) paragraph(p *paragraph) {
+	if t == nil {
+		return
+	}
+	continued := p._src != nil && p._src == t._src
+	t._elem, t._src = nil, p._src
+	if p._baf != nil || p._cfb {
+		return
+	}
+	if continued {
+		parent := (*model.KDict)(nil)
+		if t._type == model.StructureTypeListBody && len(t._lists) > 0 {
+			parent = t._lists[len(t._lists)-1]._item
+		}
+		t._elem = t._tagger.Element(parent, t._type)
+		return
+	}
+	if body := t.listBody(p._src); body != nil {
+		t._elem, t._type = body, model.StructureTypeListBody
+		return
+	}
+	t._type = model.StructureTypeParagraph
+	if para, ok := t._paragraphs[p._src]; ok {
+		if lvl := para.HeadingLevel(); lvl > 0 {
+			if lvl > len(headingTypes) {
+				lvl = len(headingTypes)
+			}
+			t._type = headingTypes[lvl-1]
+		}
+	}
+	t._elem = t._tagger.Element(nil, t._type)
+}
+
+// listBody returns the body of a new list item for a numbered paragraph,
+// opening lists up to its level, or closes all lists and returns nil for
+// other paragraphs.
+func (t *documentTagger) listBody(p *wml.CT_P) *model.KDict {
+	level := -1
+	if p != nil && p.PPr != nil && p.PPr.NumPr != nil && p.PPr.NumPr.NumId != nil && p.PPr.NumPr.NumId.ValAttr != 0 {
+		level = 0
+		if p.PPr.NumPr.Ilvl != nil {
+			level = int(p.PPr.NumPr.Ilvl.ValAttr)
+		}
+	}
+	if len(t._lists) > level+1 {
+		t._lists = t._lists[:level+1]
+	}
+	if level < 0 {
+		return nil
+	}
+	for len(t._lists) <= level {
+		// nested lists belong to the last item of the enclosing list
+		var parent *model.KDict
+		if n := len(t._lists); n > 0 {
+			parent = t._lists[n-1]._item
+		}
+		t._lists = append(t._lists, taggedList{_list: t._tagger.Element(parent, model.StructureTypeList)})
+	}
+	l := &t._lists[level]
+	l._item = t._tagger.Element(l._list, model.StructureTypeListItem)
+	return t._tagger.Element(l._item, model.StructureTypeListBody)
+}
+
+// mark marks text of the current paragraph.
+func (t *documentTagger) mark(sp *creator.StyledParagraph) {
+	if t == nil || t._elem == nil {
+		return
+	}
+	t._tagger.Mark(t._elem, sp)
+}
+
+// inline marks an inline image of the current paragraph as a figure.
+func (t *documentTagger) inline(s *symbol) {
+	if t == nil || s._ffge == nil {
+		return
+	}
+	alt := ""
+	if s._inline != nil && s._inline.DocPr != nil && s._inline.DocPr.DescrAttr != nil {
+		alt = *s._inline.DocPr.DescrAttr
+	}
+	t._tagger.Mark(t._tagger.Figure(t._elem, alt), s._ffge)
+}
+
+// anchor marks a floating image as a figure.
+func (t *documentTagger) anchor(img *image) {
+	if t == nil || img._gcgd == nil {
+		return
+	}
+	alt := ""
+	if img._anchor != nil && img._anchor.DocPr != nil && img._anchor.DocPr.DescrAttr != nil {
+		alt = *img._anchor.DocPr.DescrAttr
+	}
+	t._tagger.Mark(t._tagger.Figure(nil, alt), img._gcgd)
+}
+
+// table returns the drawing of the part of a table on the current page marked
+// as a table. The table is drawn as a whole, so its rows and cells are
+// described by the text of the cells.
+func (t *documentTagger) table(p *paragraph, b creator.Drawable, width, height float64) creator.Drawable {
+	if t == nil || p._baf == nil {
+		return b
+	}
+	t._lists = nil
+	e := t._tagger.Element(nil, model.StructureTypeTable)
+	for _, tc := range p._baf._cells {
+		row, ok := t._rows[tc]
+		if !ok {
+			continue
+		}
+		tr := t._tagger.Element(e, model.StructureTypeTableRow)
+		for _, text := range row._cells {
+			st := model.StructureTypeTableData
+			if row._header {
+				st = model.StructureTypeTableHeaderCell
+			}
+			if td := t._tagger.Element(tr, st); text != "" {
+				td.ActualText = core.MakeString(text)
+			}
+		}
+	}
+	return t._tagger.MarkDrawable(e, b, width, height)
+}
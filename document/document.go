@@ -512,7 +512,47 @@ WithNumbering bool ;
 NumberingIndent string ;
 
 // RunsOnNewLine write each of runs text on new line if set to `true`.
-RunsOnNewLine bool ;};
+RunsOnNewLine bool ;
+
+// The options below are used by Document.ExtractTextWithOptions only.
+
+// IncludeHeaders extract text of the header parts if set to `true`.
+IncludeHeaders bool ;
+
+// IncludeFooters extract text of the footer parts if set to `true`.
+IncludeFooters bool ;
+
+// IncludeFootnotes extract text of the footnotes if set to `true`.
+IncludeFootnotes bool ;
+
+// IncludeEndnotes extract text of the endnotes if set to `true`.
+IncludeEndnotes bool ;
+
+// IncludeComments extract text of the comments if set to `true`.
+IncludeComments bool ;
+
+// ExcludeTextBoxes skip the text of text boxes if set to `true`.
+ExcludeTextBoxes bool ;
+
+// TableFormat controls how the tables are rendered.
+TableFormat TableFormat ;
+
+// ExcludeInsertedText skip the text of tracked insertions and of the
+// destinations of tracked moves if set to `true`.
+ExcludeInsertedText bool ;
+
+// IncludeDeletedText extract the text of tracked deletions and of the
+// sources of tracked moves if set to `true`.
+IncludeDeletedText bool ;
+
+// ParagraphPages maps the body paragraphs to the one-based number of the page
+// they start on, as returned by convert.ParagraphPages. When set,
+// PageSeparator is written between the pages.
+ParagraphPages map[*_gfe .CT_P ]int ;
+
+// PageSeparator is written on its own line at every page boundary, a form
+// feed is used if empty.
+PageSeparator string ;};
 
 // GetHeader gets a section Header for given type t [ST_HdrFtrDefault, ST_HdrFtrEven, ST_HdrFtrFirst]
 func (_dacgb Section )GetHeader (t _gfe .ST_HdrFtr )(Header ,bool ){for _ ,_gdbc :=range _dacgb ._fdbg .EG_HdrFtrReferences {if _gdbc .HdrFtrReferencesChoice .HeaderReference ==nil {continue ;};if _gdbc .HdrFtrReferencesChoice .HeaderReference .TypeAttr ==t {for _ ,_dgcca :=range _dacgb ._dgafe .Headers (){_abad :=_dacgb ._dgafe ._fgg .FindRIDForN (_dgcca .Index (),_cc .HeaderType );
@@ -534,9 +574,9 @@ _faba ._dcf =append (_faba ._dcf ,_cbga );case _cc .FooterType ,_cc .FooterTypeS
 _dedg :=_bgb .NewRelationships ();_baga .AddTarget (_ccf .RelationsPathFor (_eafge ),_dedg .X (),_ebb ,0);_faba ._abc =append (_faba ._abc ,_dedg );case _cc .ThemeType ,_cc .ThemeTypeStrict :_cbfbe :=_ee .NewTheme ();_baga .AddTarget (_eafge ,_cbfbe ,_ebb ,uint32 (len (_faba ._dde )));
 _faba ._dde =append (_faba ._dde ,_cbfbe );_bcgg .TargetAttr =_cc .RelativeFilename (_edccc ,_afcd .Typ ,_ebb ,len (_faba ._dde ));case _cc .WebSettingsType ,_cc .WebSettingsTypeStrict :_faba ._abb =_gfe .NewWebSettings ();_baga .AddTarget (_eafge ,_faba ._abb ,_ebb ,0);
 _bcgg .TargetAttr =_cc .RelativeFilename (_edccc ,_afcd .Typ ,_ebb ,0);case _cc .FontTableType ,_cc .FontTableTypeStrict :_faba ._gfda =_gfe .NewFonts ();_baga .AddTarget (_eafge ,_faba ._gfda ,_ebb ,0);_bcgg .TargetAttr =_cc .RelativeFilename (_edccc ,_afcd .Typ ,_ebb ,0);
-case _cc .FontEmbeddingType :_bcgc :=_bgb .NewRelationships ();_baga .AddTarget (_ccf .RelationsPathFor (_eafge ),_bcgc .X (),_ebb ,0);_faba ._ecbc =_bcgc ;case _cc .EndNotesType ,_cc .EndNotesTypeStrict :_faba ._dgde =_gfe .NewEndnotes ();_baga .AddTarget (_eafge ,_faba ._dgde ,_ebb ,0);
-_bcgg .TargetAttr =_cc .RelativeFilename (_edccc ,_afcd .Typ ,_ebb ,0);case _cc .FootNotesType ,_cc .FootNotesTypeStrict :_faba ._bac =_gfe .NewFootnotes ();_baga .AddTarget (_eafge ,_faba ._bac ,_ebb ,0);_bcgg .TargetAttr =_cc .RelativeFilename (_edccc ,_afcd .Typ ,_ebb ,0);
-case _cc .CommentsType :_faba ._ggad =_gfe .NewComments ();_baga .AddTarget (_eafge ,_faba ._ggad ,_ebb ,0);_bcgg .TargetAttr =_cc .RelativeFilename (_edccc ,_afcd .Typ ,_ebb ,0);case _cc .ImageType ,_cc .ImageTypeStrict :var _gdee _bgb .ImageRef ;for _bfff ,_dfbb :=range _bcbd {if _dfbb ==nil {continue ;
+case _cc .FontEmbeddingType :_bcgc :=_bgb .NewRelationships ();_baga .AddTarget (_ccf .RelationsPathFor (_eafge ),_bcgc .X (),_ebb ,0);_faba ._ecbc =_bcgc ;case _cc .EndNotesType ,_cc .EndNotesTypeStrict :_faba ._dgde =_gfe .NewEndnotes ();_baga .AddTarget (_eafge ,_faba ._dgde ,_ebb ,0);_faba ._enRels =_bgb .NewRelationships ();_baga .AddTarget (_ccf .RelationsPathFor (_eafge ),_faba ._enRels .X (),_ebb ,0);
+_bcgg .TargetAttr =_cc .RelativeFilename (_edccc ,_afcd .Typ ,_ebb ,0);case _cc .FootNotesType ,_cc .FootNotesTypeStrict :_faba ._bac =_gfe .NewFootnotes ();_baga .AddTarget (_eafge ,_faba ._bac ,_ebb ,0);_faba ._fnRels =_bgb .NewRelationships ();_baga .AddTarget (_ccf .RelationsPathFor (_eafge ),_faba ._fnRels .X (),_ebb ,0);_bcgg .TargetAttr =_cc .RelativeFilename (_edccc ,_afcd .Typ ,_ebb ,0);
+case _cc .CommentsType :_faba ._ggad =_gfe .NewComments ();_baga .AddTarget (_eafge ,_faba ._ggad ,_ebb ,0);_faba ._cmRels =_bgb .NewRelationships ();_baga .AddTarget (_ccf .RelationsPathFor (_eafge ),_faba ._cmRels .X (),_ebb ,0);_bcgg .TargetAttr =_cc .RelativeFilename (_edccc ,_afcd .Typ ,_ebb ,0);case _cc .ImageType ,_cc .ImageTypeStrict :var _gdee _bgb .ImageRef ;for _bfff ,_dfbb :=range _bcbd {if _dfbb ==nil {continue ;
 };_dfbd :=_afb .TrimPrefix (_dfbb .Name ,"\u0077\u006f\u0072d\u002f");if _afed :=_afb .TrimPrefix (_eafge ,"\u0077\u006f\u0072d\u002f");_dfbd ==_afed {_fcbb ,_bcc :=_ccf .ExtractToDiskTmp (_dfbb ,_faba .TmpPath );if _bcc !=nil {return _bcc ;};_fagd :=_g .Ext (_dfbb .Name );
 _adge :=_bgb .Image {};if _fagd [1:]!="\u0065\u006d\u0066"{_ccggd ,_acgf :=_bgb .ImageFromStorage (_fcbb );if _acgf !=nil {return _acgf ;};_adge =_ccggd ;}else {_adge .Path =_fcbb ;};_adge .Format =_fagd [1:];_gdee =_bgb .MakeImageRef (_adge ,&_faba .DocBase ,_faba ._fgg );
 _bcbd [_bfff ]=nil ;};};if _gdee .Format ()!=""{_gcdg :="\u002e"+_afb .ToLower (_gdee .Format ());_bcgg .TargetAttr =_cc .RelativeFilename (_edccc ,_afcd .Typ ,_ebb ,len (_faba .Images )+1);if _dacf :=_g .Ext (_bcgg .TargetAttr );_dacf !=_gcdg {_bcgg .TargetAttr =_bcgg .TargetAttr [0:len (_bcgg .TargetAttr )-len (_dacf )]+_gcdg ;
@@ -919,13 +959,13 @@ _cgd !=nil {return _cgd ;};if _caab .CustomProperties .X ()!=nil {if _ffcb :=_cc
 if _feef !=nil {return _feef ;};if _adad :=_eb .Encode (_eaag ,_caab .Thumbnail ,nil );_adad !=nil {return _adad ;};};if _eee :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .SettingsType ,_caab .Settings .X ());_eee !=nil {return _eee ;};_fggg :=_cc .AbsoluteFilename (_adef ,_cc .OfficeDocumentType ,0);
 if _daa :=_ccf .MarshalXML (_edf ,_fggg ,_caab ._ece );_daa !=nil {return _daa ;};if _cdf :=_ccf .MarshalXML (_edf ,_ccf .RelationsPathFor (_fggg ),_caab ._fgg .X ());_cdf !=nil {return _cdf ;};if _caab .Numbering .X ()!=nil {if _abfgc :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .NumberingType ,_caab .Numbering .X ());
 _abfgc !=nil {return _abfgc ;};};if _fcc :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .StylesType ,_caab .Styles .X ());_fcc !=nil {return _fcc ;};if _caab ._abb !=nil {if _baa :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .WebSettingsType ,_caab ._abb );_baa !=nil {return _baa ;
-};};if _caab ._gfda !=nil {if _cba :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .FontTableType ,_caab ._gfda );_cba !=nil {return _cba ;};};if _caab ._dgde !=nil {if _febe :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .EndNotesType ,_caab ._dgde );_febe !=nil {return _febe ;
-};};if _caab ._bac !=nil {if _ffde :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .FootNotesType ,_caab ._bac );_ffde !=nil {return _ffde ;};};if _caab ._ggad !=nil {if _cgdg :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .CommentsContentType ,_caab ._ggad );_cgdg !=nil {return _cgdg ;
-};};for _ace ,_bgfc :=range _caab ._dde {if _ageb :=_ccf .MarshalXMLByTypeIndex (_edf ,_adef ,_cc .ThemeType ,_ace +1,_bgfc );_ageb !=nil {return _ageb ;};};for _fff ,_gcd :=range _caab ._bfee {_ccfb ,_edfb :=_gcd .ExportToByteArray ();if _edfb !=nil {return _edfb ;
+};};if _caab ._gfda !=nil {if _cba :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .FontTableType ,_caab ._gfda );_cba !=nil {return _cba ;};if !_caab ._ecbc .IsEmpty (){if _cba :=_ccf .MarshalXML (_edf ,_ccf .RelationsPathFor (_cc .AbsoluteFilename (_adef ,_cc .FontTableType ,0)),_caab ._ecbc .X ());_cba !=nil {return _cba ;};};};if _caab ._dgde !=nil {if _febe :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .EndNotesType ,_caab ._dgde );_febe !=nil {return _febe ;
+};if !_caab ._enRels .IsEmpty (){if _febe :=_ccf .MarshalXML (_edf ,_ccf .RelationsPathFor (_cc .AbsoluteFilename (_adef ,_cc .EndNotesType ,0)),_caab ._enRels .X ());_febe !=nil {return _febe ;};};};if _caab ._bac !=nil {if _ffde :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .FootNotesType ,_caab ._bac );_ffde !=nil {return _ffde ;};if !_caab ._fnRels .IsEmpty (){if _ffde :=_ccf .MarshalXML (_edf ,_ccf .RelationsPathFor (_cc .AbsoluteFilename (_adef ,_cc .FootNotesType ,0)),_caab ._fnRels .X ());_ffde !=nil {return _ffde ;};};};if _caab ._ggad !=nil {if _cgdg :=_ccf .MarshalXMLByType (_edf ,_adef ,_cc .CommentsContentType ,_caab ._ggad );_cgdg !=nil {return _cgdg ;
+};if !_caab ._cmRels .IsEmpty (){if _cgdg :=_ccf .MarshalXML (_edf ,_ccf .RelationsPathFor (_cc .AbsoluteFilename (_adef ,_cc .CommentsContentType ,0)),_caab ._cmRels .X ());_cgdg !=nil {return _cgdg ;};};};for _ace ,_bgfc :=range _caab ._dde {if _ageb :=_ccf .MarshalXMLByTypeIndex (_edf ,_adef ,_cc .ThemeType ,_ace +1,_bgfc );_ageb !=nil {return _ageb ;};};for _fff ,_gcd :=range _caab ._bfee {_ccfb ,_edfb :=_gcd .ExportToByteArray ();if _edfb !=nil {return _edfb ;
 };_dddd :="\u0077\u006f\u0072d\u002f"+_gcd .TargetAttr [:len (_gcd .TargetAttr )-4]+"\u002e\u0062\u0069\u006e";if _dba :=_ccf .AddFileFromBytes (_edf ,_dddd ,_ccfb );_dba !=nil {return _dba ;};if _gcde :=_ccf .MarshalXMLByTypeIndex (_edf ,_adef ,_cc .ControlType ,_fff +1,_gcd .Ocx );
 _gcde !=nil {return _gcde ;};};for _dfadb ,_geebg :=range _caab ._ebg {_baec :=_cc .AbsoluteFilename (_adef ,_cc .HeaderType ,_dfadb +1);if _fbd :=_ccf .MarshalXML (_edf ,_baec ,_geebg );_fbd !=nil {return _fbd ;};if !_caab ._dcf [_dfadb ].IsEmpty (){_ccf .MarshalXML (_edf ,_ccf .RelationsPathFor (_baec ),_caab ._dcf [_dfadb ].X ());
 };};for _eag ,_afec :=range _caab ._cca {_cbcc :=_cc .AbsoluteFilename (_adef ,_cc .FooterType ,_eag +1);if _cfd :=_ccf .MarshalXMLByTypeIndex (_edf ,_adef ,_cc .FooterType ,_eag +1,_afec );_cfd !=nil {return _cfd ;};if !_caab ._abc [_eag ].IsEmpty (){_ccf .MarshalXML (_edf ,_ccf .RelationsPathFor (_cbcc ),_caab ._abc [_eag ].X ());
-};};for _cfc ,_bfaf :=range _caab .Images {if _fdf :=_bgb .AddImageToZip (_edf ,_bfaf ,_cfc +1,_cc .DocTypeDocument );_fdf !=nil {return _fdf ;};};for _ede ,_aed :=range _caab ._gabc {_addg :=_cc .AbsoluteFilename (_adef ,_cc .ChartType ,_ede +1);_ccf .MarshalXML (_edf ,_addg ,_aed ._bbd );
+};};for _cfc ,_bfaf :=range _caab .Images {if _fdf :=_bgb .AddImageToZip (_edf ,_bfaf ,_cfc +1,_cc .DocTypeDocument );_fdf !=nil {return _fdf ;};};for _ ,_aed :=range _caab ._gabc {_addg :="\u0077\u006f\u0072d\u002f"+_aed ._gaga ;_ccf .MarshalXML (_edf ,_addg ,_aed ._bbd );
 };if _dgfa :=_ccf .MarshalXML (_edf ,_cc .ContentTypesFilename ,_caab .ContentTypes .X ());_dgfa !=nil {return _dgfa ;};if _ddeb :=_caab .WriteExtraFiles (_edf );_ddeb !=nil {return _ddeb ;};return _edf .Close ();};
 
 // SetHeight allows controlling the height of a row within a table.
@@ -2505,7 +2545,7 @@ type Nodes struct{_abdgb []Node };
 // format. It can be opened from a file on disk and modified, or created from
 // scratch.
 type Document struct{_bgb .DocBase ;_ece *_gfe .Document ;Settings Settings ;Numbering Numbering ;Styles Styles ;_ebg []*_gfe .Hdr ;_dcf []_bgb .Relationships ;_cca []*_gfe .Ftr ;_abc []_bgb .Relationships ;_fgg _bgb .Relationships ;_dde []*_ee .Theme ;
-_abb *_gfe .WebSettings ;_gfda *_gfe .Fonts ;_ecbc _bgb .Relationships ;_dgde *_gfe .Endnotes ;_bac *_gfe .Footnotes ;_bfee []*_f .Control ;_gabc []*chart ;_ggad *_gfe .Comments ;_eaf string ;};
+_abb *_gfe .WebSettings ;_gfda *_gfe .Fonts ;_ecbc _bgb .Relationships ;_dgde *_gfe .Endnotes ;_bac *_gfe .Footnotes ;_bfee []*_f .Control ;_gabc []*chart ;_ggad *_gfe .Comments ;_eaf string ;_fnRels ,_enRels ,_cmRels _bgb .Relationships ;};
 
 // ParagraphProperties returns the paragraph properties controlling text formatting within the table.
 func (_fcbcgb TableConditionalFormatting )ParagraphProperties ()ParagraphStyleProperties {if _fcbcgb ._bdfe .PPr ==nil {_fcbcgb ._bdfe .PPr =_gfe .NewCT_PPrGeneral ();};return ParagraphStyleProperties {_fcbcgb ._bdfe .PPr };};
@@ -0,0 +1,351 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml/picture"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// ImageFit controls how the extent of a drawing is changed when its image is
+// replaced.
+type ImageFit byte
+
+// ImageFit constants.
+const (
+	// ImageFitExtent keeps the extent of the drawing, stretching the new image.
+	ImageFitExtent ImageFit = iota
+	// ImageFitContain scales the new image to fit within the extent of the
+	// drawing, keeping its aspect ratio.
+	ImageFitContain
+	// ImageFitNatural sizes the drawing to the size of the new image.
+	ImageFitNatural
+)
+
+// ImageQuery selects drawing images in FindImages. Empty fields match any
+// image.
+type ImageQuery struct {
+	Name        string // the name of the drawing
+	Description string // the description (alternative text) of the drawing
+	RelID       string // the relationship ID of the image
+}
+
+// DrawingImage is a picture placed by an inline or anchored drawing in the
+// body, a header or a footer.
+type DrawingImage struct {
+	_doc    *Document
+	_hdr    *wml.Hdr
+	_ftr    *wml.Ftr
+	_inline *wml.WdInline
+	_anchor *wml.WdAnchor
+	_pic    *picture.Pic
+}
+
+// DrawingImages returns the pictures of the inline and anchored drawings of
+// the body, headers and footers, including those in tables, content controls
+// and text boxes.
+func (d *Document) DrawingImages() []DrawingImage {
+	imgs := []DrawingImage{}
+	add := func(hdr *wml.Hdr, ftr *wml.Ftr, blocks []*wml.EG_BlockLevelElts) {
+		walkDrawings(blocks, func(inline *wml.WdInline, anchor *wml.WdAnchor, pic *picture.Pic) {
+			imgs = append(imgs, DrawingImage{d, hdr, ftr, inline, anchor, pic})
+		})
+	}
+	add(nil, nil, d.X().Body.EG_BlockLevelElts)
+	for _, hdr := range d._ebg {
+		add(hdr, nil, hdr.EG_BlockLevelElts)
+	}
+	for _, ftr := range d._cca {
+		add(nil, ftr, ftr.EG_BlockLevelElts)
+	}
+	return imgs
+}
+
+// FindImages returns the drawing images matching q.
+func (d *Document) FindImages(q ImageQuery) []DrawingImage {
+	imgs := []DrawingImage{}
+	for _, img := range d.DrawingImages() {
+		if q.Name != "" && img.Name() != q.Name {
+			continue
+		}
+		if q.Description != "" && img.Description() != q.Description {
+			continue
+		}
+		if q.RelID != "" && img.RelID() != q.RelID {
+			continue
+		}
+		imgs = append(imgs, img)
+	}
+	return imgs
+}
+
+// walkDrawings calls fn for every picture of a drawing in blocks.
+func walkDrawings(blocks []*wml.EG_BlockLevelElts, fn func(inline *wml.WdInline, anchor *wml.WdAnchor, pic *picture.Pic)) {
+	drawing := func(d *wml.CT_Drawing) {
+		for _, dc := range d.DrawingChoice {
+			var gd *dml.CT_GraphicalObjectData
+			if dc.Inline != nil && dc.Inline.Graphic != nil {
+				gd = dc.Inline.Graphic.GraphicData
+			}
+			if dc.Anchor != nil && dc.Anchor.Graphic != nil {
+				gd = dc.Anchor.Graphic.GraphicData
+			}
+			if gd == nil {
+				continue
+			}
+			for _, a := range gd.Any {
+				switch g := a.(type) {
+				case *picture.Pic:
+					fn(dc.Inline, dc.Anchor, g)
+				case *wml.WdWsp:
+					if g.WordprocessingShapeChoice1 != nil {
+						if tb := g.WordprocessingShapeChoice1.Txbx; tb != nil && tb.TxbxContent != nil {
+							walkDrawings(tb.TxbxContent.EG_BlockLevelElts, fn)
+						}
+					}
+				}
+			}
+		}
+	}
+	walkBlockParagraphs(blocks, func(p *wml.CT_P) {
+		walkParagraphRuns(p, func(r *wml.CT_R) {
+			for _, ric := range r.EG_RunInnerContent {
+				if d := ric.RunInnerContentChoice.Drawing; d != nil {
+					drawing(d)
+				}
+			}
+			for _, x := range r.Extra {
+				if acr, ok := x.(*wml.AlternateContentRun); ok && acr.Choice.Drawing != nil {
+					drawing(acr.Choice.Drawing)
+				}
+			}
+		})
+	})
+}
+
+func (i DrawingImage) docPr() *dml.CT_NonVisualDrawingProps {
+	if i._inline != nil {
+		return i._inline.DocPr
+	}
+	return i._anchor.DocPr
+}
+
+func (i DrawingImage) extent() *dml.CT_PositiveSize2D {
+	if i._inline != nil {
+		return i._inline.Extent
+	}
+	return i._anchor.Extent
+}
+
+// rels returns the relationships of the part containing the drawing.
+func (i DrawingImage) rels() common.Relationships {
+	for idx, hdr := range i._doc._ebg {
+		if hdr == i._hdr {
+			return i._doc._dcf[idx]
+		}
+	}
+	for idx, ftr := range i._doc._cca {
+		if ftr == i._ftr {
+			return i._doc._abc[idx]
+		}
+	}
+	return i._doc._fgg
+}
+
+// Name returns the name of the drawing.
+func (i DrawingImage) Name() string {
+	if pr := i.docPr(); pr != nil {
+		return pr.NameAttr
+	}
+	return ""
+}
+
+// Description returns the description (alternative text) of the drawing.
+func (i DrawingImage) Description() string {
+	if pr := i.docPr(); pr != nil && pr.DescrAttr != nil {
+		return *pr.DescrAttr
+	}
+	return ""
+}
+
+// RelID returns the relationship ID of the image.
+func (i DrawingImage) RelID() string {
+	if i._pic.BlipFill != nil && i._pic.BlipFill.Blip != nil && i._pic.BlipFill.Blip.EmbedAttr != nil {
+		return *i._pic.BlipFill.Blip.EmbedAttr
+	}
+	return ""
+}
+
+// Image returns the image of the drawing.
+func (i DrawingImage) Image() (common.ImageRef, bool) {
+	if idx := i.imageIndex(i.RelID()); idx >= 0 {
+		return i._doc.Images[idx], true
+	}
+	return common.ImageRef{}, false
+}
+
+// imageIndex returns the index in the images of the document of the image
+// the relationship of the part containing the drawing refers to, -1 if there
+// is none.
+func (i DrawingImage) imageIndex(id string) int {
+	return i._doc.imageIndex(i.rels(), id)
+}
+
+// imageIndex returns the index in the images of the document of the image
+// the relationship id of rels refers to, -1 if there is none.
+func (d *Document) imageIndex(rels common.Relationships, id string) int {
+	if id == "" || rels.X() == nil {
+		return -1
+	}
+	target := rels.GetTargetByRelId(id)
+	if target == "" {
+		return -1
+	}
+	for idx, img := range d.Images {
+		if strings.TrimPrefix(img.Target(), "word/") == target {
+			return idx
+		}
+	}
+	return -1
+}
+
+// GetImageByRelID returns the image the relationship relID of the footnotes
+// part refers to.
+func (f Footnote) GetImageByRelID(relID string) (common.ImageRef, bool) {
+	if idx := f._fdfg.imageIndex(f._fdfg._fnRels, relID); idx >= 0 {
+		return f._fdfg.Images[idx], true
+	}
+	return common.ImageRef{}, false
+}
+
+// GetImageByRelID returns the image the relationship relID of the endnotes
+// part refers to.
+func (e Endnote) GetImageByRelID(relID string) (common.ImageRef, bool) {
+	if idx := e._bagg.imageIndex(e._bagg._enRels, relID); idx >= 0 {
+		return e._bagg.Images[idx], true
+	}
+	return common.ImageRef{}, false
+}
+
+// InlineDrawing returns the inline drawing holding the image, if it is inline.
+func (i DrawingImage) InlineDrawing() (InlineDrawing, bool) {
+	return InlineDrawing{i._doc, i._inline}, i._inline != nil
+}
+
+// AnchoredDrawing returns the anchored drawing holding the image, if it is
+// anchored.
+func (i DrawingImage) AnchoredDrawing() (AnchoredDrawing, bool) {
+	return AnchoredDrawing{i._doc, i._anchor}, i._anchor != nil
+}
+
+// Replace adds img to the part containing the drawing and displays it instead
+// of the current image. The current image and its relationship are removed
+// if nothing else in the document refers to them.
+func (i DrawingImage) Replace(img common.Image, fit ImageFit) error {
+	old := i.RelID()
+	var ref common.ImageRef
+	var err error
+	switch {
+	case i._hdr != nil:
+		ref, err = Header{i._doc, i._hdr}.AddImage(img)
+	case i._ftr != nil:
+		ref, err = Footer{i._doc, i._ftr}.AddImage(img)
+	default:
+		ref, err = i._doc.AddImage(img)
+	}
+	if err != nil {
+		return err
+	}
+	if err := i.SetImage(ref, fit); err != nil {
+		return err
+	}
+	return i.release(old)
+}
+
+// release removes the image relationship with the given ID from the part
+// containing the drawing if the part doesn't refer to it anymore, and the
+// image if no other relationship refers to it.
+func (i DrawingImage) release(id string) error {
+	idx := i.imageIndex(id)
+	if idx < 0 {
+		return nil
+	}
+	var part interface{} = i._doc.X()
+	switch {
+	case i._hdr != nil:
+		part = i._hdr
+	case i._ftr != nil:
+		part = i._ftr
+	}
+	// the relationship may also be used by VML shapes, objects or fills,
+	// which are found in the markup of the part
+	b, err := xml.Marshal(part)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(b, []byte(`="`+id+`"`)) {
+		return nil
+	}
+	rels := i.rels()
+	rels.Remove(rels.GetByRelId(id))
+	_, err = common.RemoveImage(&i._doc.DocBase, i._doc.partRels(), idx)
+	return err
+}
+
+// SetImage displays the image ref instead of the current image. ref must have
+// been added to the part containing the drawing with Document.AddImage,
+// Header.AddImage or Footer.AddImage.
+func (i DrawingImage) SetImage(ref common.ImageRef, fit ImageFit) error {
+	id := ref.RelID()
+	if id == "" || i.rels().GetTargetByRelId(id) == "" {
+		return errors.New("image is not part of the drawing's document part")
+	}
+	if i._pic.BlipFill == nil {
+		i._pic.BlipFill = dml.NewCT_BlipFillProperties()
+	}
+	if i._pic.BlipFill.Blip == nil {
+		i._pic.BlipFill.Blip = dml.NewCT_Blip()
+	}
+	i._pic.BlipFill.Blip.EmbedAttr = &id
+
+	ext := i.extent()
+	if ext == nil {
+		return nil
+	}
+	size := ref.Size()
+	cx, cy := ext.CxAttr, ext.CyAttr
+	switch fit {
+	case ImageFitContain:
+		if size.X > 0 && size.Y > 0 && cx > 0 && cy > 0 {
+			ratio := float64(size.X) / float64(size.Y)
+			if float64(cx)/float64(cy) > ratio {
+				cx = int64(float64(cy) * ratio)
+			} else {
+				cy = int64(float64(cx) / ratio)
+			}
+		}
+	case ImageFitNatural:
+		cx = int64(float64(measurement.Distance(size.X)*measurement.Pixel72) / measurement.EMU)
+		cy = int64(float64(measurement.Distance(size.Y)*measurement.Pixel72) / measurement.EMU)
+	}
+	ext.CxAttr, ext.CyAttr = cx, cy
+	if sp := i._pic.SpPr; sp != nil && sp.Xfrm != nil && sp.Xfrm.Ext != nil {
+		sp.Xfrm.Ext.CxAttr, sp.Xfrm.Ext.CyAttr = cx, cy
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+/*
+Package epub provides export of document.Document to EPUB 3 books.
+
+The document is split into chapters at its headings, paragraph and character
+styles are mapped to CSS classes of a common stylesheet and direct formatting
+to inline styles. The navigation document is built from the headings,
+footnotes and endnotes become popup notes, and images and embedded fonts are
+included in the book. Headers, footers and page setup have no counterpart in
+reflowable books and are left out.
+
+Example:
+
+	doc, err := document.Open("report.docx")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer doc.Close()
+	if err := epub.SaveToFile(doc, "report.epub", &epub.Options{ChapterLevel: 2}); err != nil {
+		log.Fatal(err)
+	}
+*/
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/internal/odf"
+)
+
+// MimeType is the media type of EPUB books.
+const MimeType = "application/epub+zip"
+
+// Options are the options of the EPUB export.
+type Options struct {
+	// ChapterLevel is the deepest heading level starting a new chapter, 1 if
+	// not set.
+	ChapterLevel int
+	// TOCDepth is the deepest heading level listed in the navigation, 3 if
+	// not set.
+	TOCDepth int
+	// Identifier is the unique identifier of the book, a random UUID URN if
+	// not set.
+	Identifier string
+	// Language is the language tag of the book, "en" if not set.
+	Language string
+}
+
+func (o *Options) withDefaults() Options {
+	res := Options{}
+	if o != nil {
+		res = *o
+	}
+	if res.ChapterLevel <= 0 {
+		res.ChapterLevel = 1
+	}
+	if res.TOCDepth <= 0 {
+		res.TOCDepth = 3
+	}
+	if res.Identifier == "" {
+		res.Identifier = newUUID()
+	}
+	if res.Language == "" {
+		res.Language = "en"
+	}
+	return res
+}
+
+// Write writes the document d to w as an EPUB book, opts may be nil.
+func Write(w io.Writer, d *document.Document, opts *Options) error {
+	b, err := newWriter(d, opts.withDefaults()).write()
+	if err != nil {
+		return err
+	}
+	return b.write(w)
+}
+
+// SaveToFile writes the document d to an EPUB book at path, opts may be nil.
+func SaveToFile(d *document.Document, path string, opts *Options) error {
+	buf := bytes.Buffer{}
+	if err := Write(&buf, d, opts); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// item is a publication resource stored under OEBPS/.
+type item struct {
+	id        string
+	href      string
+	mediaType string
+	// props are the manifest properties such as nav
+	props string
+	data  []byte
+}
+
+// book is the content of an EPUB container.
+type book struct {
+	opts  Options
+	meta  odf.Meta
+	title string
+	items []*item
+	// spine holds the ids of the chapters in reading order
+	spine []string
+}
+
+func (b *book) add(id, href, mediaType string, data []byte) *item {
+	it := &item{id: id, href: href, mediaType: mediaType, data: data}
+	b.items = append(b.items, it)
+	return it
+}
+
+// write writes the book, the mimetype is stored uncompressed as the first
+// entry as required by the specification.
+func (b *book) write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err = fw.Write([]byte(MimeType)); err != nil {
+		return err
+	}
+	add := func(name string, data []byte) error {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = fw.Write(data)
+		return err
+	}
+	if err = add("META-INF/container.xml", container()); err != nil {
+		return err
+	}
+	if err = add("OEBPS/content.opf", b.packageDocument()); err != nil {
+		return err
+	}
+	for _, it := range b.items {
+		if err = add("OEBPS/"+it.href, it.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func container() []byte {
+	x := odf.NewWriter()
+	x.Start("container", "version", "1.0", "xmlns", "urn:oasis:names:tc:opendocument:xmlns:container")
+	x.Start("rootfiles")
+	x.Empty("rootfile", "full-path", "OEBPS/content.opf", "media-type", "application/oebps-package+xml")
+	return x.Bytes()
+}
+
+// packageDocument returns the package document with the metadata, the
+// manifest of all resources and the spine.
+func (b *book) packageDocument() []byte {
+	x := odf.NewWriter()
+	x.Start("package", "xmlns", "http://www.idpf.org/2007/opf", "version", "3.0", "unique-identifier", "book-id",
+		"xml:lang", b.opts.Language)
+	x.Start("metadata", "xmlns:dc", "http://purl.org/dc/elements/1.1/")
+	element := func(name, text string, attrs ...string) {
+		if text == "" {
+			return
+		}
+		x.Start(name, attrs...)
+		x.Text(text)
+		x.End()
+	}
+	element("dc:identifier", b.opts.Identifier, "id", "book-id")
+	element("dc:title", b.title)
+	element("dc:language", b.opts.Language)
+	author := b.meta.Initial
+	if author == "" {
+		author = b.meta.Creator
+	}
+	element("dc:creator", author)
+	element("dc:subject", b.meta.Subject)
+	element("dc:description", b.meta.Description)
+	if !b.meta.Created.IsZero() {
+		element("dc:date", b.meta.Created.UTC().Format(time.RFC3339))
+	}
+	modified := b.meta.Modified
+	if modified.IsZero() {
+		modified = time.Now()
+	}
+	element("meta", modified.UTC().Format("2006-01-02T15:04:05Z"), "property", "dcterms:modified")
+	x.End()
+	x.Start("manifest")
+	for _, it := range b.items {
+		x.Empty("item", "id", it.id, "href", it.href, "media-type", it.mediaType, "properties", it.props)
+	}
+	x.End()
+	x.Start("spine")
+	for _, id := range b.spine {
+		x.Empty("itemref", "idref", id)
+	}
+	return x.Bytes()
+}
+
+// newUUID returns a random version 4 UUID URN.
+func newUUID() string {
+	u := make([]byte, 16)
+	if _, err := rand.Read(u); err != nil {
+		return fmt.Sprintf("urn:uuid:00000000-0000-4000-8000-%012x", time.Now().UnixNano()&0xffffffffffff)
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
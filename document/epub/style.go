@@ -0,0 +1,364 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package epub
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/internal/fontembed"
+	"github.com/unidoc/unioffice/v2/schema/soo/ofc/sharedTypes"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// baseCSS is the part of the stylesheet independent of the document. Text is
+// written without formatting whitespace, so spaces and tabs are preserved.
+const baseCSS = `body { white-space: pre-wrap; }
+p, li { margin: 0; }
+h1, h2, h3, h4, h5, h6 { font-size: inherit; }
+table { border-collapse: collapse; margin: 0.5em 0; }
+td { padding: 0.04in; vertical-align: top; }
+table.grid td { border: 0.5pt solid #000000; }
+img { max-width: 100%; }
+a.noteref { vertical-align: super; font-size: 0.6em; text-decoration: none; }
+aside { font-size: 0.85em; }
+nav ol { list-style-type: none; }
+`
+
+// stylesheet returns the CSS of the book, the @font-face rules of the
+// embedded fonts are followed by the document defaults and a class per
+// paragraph and character style.
+func (w *writer) stylesheet() []byte {
+	b := bytes.Buffer{}
+	w.fonts(&b)
+	b.WriteString(baseCSS)
+	if st := w.doc.Styles.X(); st != nil && st.DocDefaults != nil {
+		var decls []string
+		if d := st.DocDefaults.RPrDefault; d != nil {
+			decls = append(decls, textCSS(d.RPr)...)
+		}
+		writeRule(&b, "body", decls)
+		if d := st.DocDefaults.PPrDefault; d != nil && d.PPr != nil {
+			writeRule(&b, "p", paragraphCSS(generalPPr(d.PPr)))
+		}
+	}
+	for _, s := range w.doc.Styles.Styles() {
+		t := s.Type()
+		if t != wml.ST_StyleTypeParagraph && t != wml.ST_StyleTypeCharacter {
+			continue
+		}
+		var decls []string
+		for _, a := range w.styleChain(s.StyleID()) {
+			if t == wml.ST_StyleTypeParagraph && a.PPr != nil {
+				decls = append(decls, paragraphCSS(generalPPr(a.PPr))...)
+			}
+			decls = append(decls, textCSS(a.RPr)...)
+		}
+		writeRule(&b, "."+className(s.StyleID()), decls)
+		w.classes[s.StyleID()] = className(s.StyleID())
+	}
+	return b.Bytes()
+}
+
+// styleChain returns the style with the ID id preceded by the styles it is
+// based on, outermost first.
+func (w *writer) styleChain(id string) []*wml.CT_Style {
+	var chain []*wml.CT_Style
+	seen := map[string]bool{}
+	for id != "" && !seen[id] {
+		seen[id] = true
+		s, ok := w.doc.Styles.SearchStyleById(id)
+		if !ok || s.X() == nil {
+			break
+		}
+		chain = append([]*wml.CT_Style{s.X()}, chain...)
+		id = ""
+		if s.X().BasedOn != nil {
+			id = s.X().BasedOn.ValAttr
+		}
+	}
+	return chain
+}
+
+func writeRule(b *bytes.Buffer, selector string, decls []string) {
+	if len(decls) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s { %s; }\n", selector, strings.Join(decls, "; "))
+}
+
+// className returns the CSS class of a style ID.
+func className(styleID string) string {
+	b := strings.Builder{}
+	b.WriteString("s-")
+	for _, r := range styleID {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// fonts adds the fonts embedded in the document to the book and writes their
+// @font-face rules.
+func (w *writer) fonts(b *bytes.Buffer) {
+	ft := w.doc.FontTable()
+	if ft == nil {
+		return
+	}
+	n := 0
+	for _, f := range ft.Font {
+		faces := []struct {
+			rel           *wml.CT_FontRel
+			weight, style string
+		}{
+			{f.EmbedRegular, "normal", "normal"}, {f.EmbedBold, "bold", "normal"},
+			{f.EmbedItalic, "normal", "italic"}, {f.EmbedBoldItalic, "bold", "italic"},
+		}
+		for _, face := range faces {
+			if face.rel == nil {
+				continue
+			}
+			data, err := w.doc.GetFontBytesByRelId(face.rel.IdAttr)
+			if err != nil || len(data) == 0 {
+				continue
+			}
+			if face.rel.FontKeyAttr != "" {
+				if data, err = fontembed.Obfuscate(data, face.rel.FontKeyAttr); err != nil {
+					continue
+				}
+			}
+			ext, mediaType := "ttf", "font/ttf"
+			if bytes.HasPrefix(data, []byte("OTTO")) {
+				ext, mediaType = "otf", "font/otf"
+			}
+			n++
+			href := fmt.Sprintf("fonts/font%d.%s", n, ext)
+			w.book.add(fmt.Sprintf("font%d", n), href, mediaType, data)
+			fmt.Fprintf(b, "@font-face { font-family: %s; font-weight: %s; font-style: %s; src: url(%s); }\n",
+				strconv.Quote(f.NameAttr), face.weight, face.style, href)
+		}
+	}
+}
+
+// generalPPr returns the paragraph properties of a style or the document
+// defaults as direct paragraph properties.
+func generalPPr(g *wml.CT_PPrGeneral) *wml.CT_PPr {
+	if g == nil {
+		return nil
+	}
+	ppr := wml.NewCT_PPr()
+	ppr.Jc, ppr.Ind, ppr.Spacing, ppr.Shd = g.Jc, g.Ind, g.Spacing, g.Shd
+	ppr.KeepNext, ppr.KeepLines, ppr.PageBreakBefore = g.KeepNext, g.KeepLines, g.PageBreakBefore
+	return ppr
+}
+
+// points returns a length in twips as CSS points.
+func points(v int64) string {
+	return strconv.FormatFloat(float64(v)/20, 'f', -1, 64) + "pt"
+}
+
+func paragraphCSS(ppr *wml.CT_PPr) []string {
+	var decls []string
+	if ppr == nil {
+		return decls
+	}
+	add := func(k, v string) { decls = append(decls, k+": "+v) }
+	if ppr.Jc != nil {
+		switch ppr.Jc.ValAttr {
+		case wml.ST_JcCenter:
+			add("text-align", "center")
+		case wml.ST_JcRight, wml.ST_JcEnd:
+			add("text-align", "right")
+		case wml.ST_JcBoth, wml.ST_JcDistribute:
+			add("text-align", "justify")
+		case wml.ST_JcLeft, wml.ST_JcStart:
+			add("text-align", "left")
+		}
+	}
+	if ind := ppr.Ind; ind != nil {
+		if v, ok := signedTwips(ind.LeftAttr); ok {
+			add("margin-left", points(v))
+		} else if v, ok := signedTwips(ind.StartAttr); ok {
+			add("margin-left", points(v))
+		}
+		if v, ok := signedTwips(ind.RightAttr); ok {
+			add("margin-right", points(v))
+		} else if v, ok := signedTwips(ind.EndAttr); ok {
+			add("margin-right", points(v))
+		}
+		if v, ok := twips(ind.FirstLineAttr); ok {
+			add("text-indent", points(int64(v)))
+		} else if v, ok := twips(ind.HangingAttr); ok {
+			add("text-indent", points(-int64(v)))
+		}
+	}
+	if sp := ppr.Spacing; sp != nil {
+		if v, ok := twips(sp.BeforeAttr); ok {
+			add("margin-top", points(int64(v)))
+		}
+		if v, ok := twips(sp.AfterAttr); ok {
+			add("margin-bottom", points(int64(v)))
+		}
+		if v, ok := signedTwips(sp.LineAttr); ok && v > 0 {
+			switch sp.LineRuleAttr {
+			case wml.ST_LineSpacingRuleExact, wml.ST_LineSpacingRuleAtLeast:
+				add("line-height", points(v))
+			default:
+				add("line-height", strconv.FormatFloat(float64(v)/240, 'f', 2, 64))
+			}
+		}
+	}
+	if onOff(ppr.PageBreakBefore) {
+		add("page-break-before", "always")
+	}
+	if onOff(ppr.KeepNext) {
+		add("page-break-after", "avoid")
+	}
+	if onOff(ppr.KeepLines) {
+		add("page-break-inside", "avoid")
+	}
+	if shd := ppr.Shd; shd != nil && shd.FillAttr != nil && shd.FillAttr.ST_HexColorRGB != nil {
+		add("background-color", "#"+strings.ToLower(*shd.FillAttr.ST_HexColorRGB))
+	}
+	return decls
+}
+
+func textCSS(rpr *wml.CT_RPr) []string {
+	var decls []string
+	if rpr == nil {
+		return decls
+	}
+	add := func(k, v string) { decls = append(decls, k+": "+v) }
+	if rpr.B != nil {
+		if onOff(rpr.B) {
+			add("font-weight", "bold")
+		} else {
+			add("font-weight", "normal")
+		}
+	}
+	if rpr.I != nil {
+		if onOff(rpr.I) {
+			add("font-style", "italic")
+		} else {
+			add("font-style", "normal")
+		}
+	}
+	lines := []string{}
+	if u := rpr.U; u != nil && u.ValAttr != wml.ST_UnderlineNone && u.ValAttr != wml.ST_UnderlineUnset {
+		lines = append(lines, "underline")
+		switch u.ValAttr {
+		case wml.ST_UnderlineDouble:
+			add("text-decoration-style", "double")
+		case wml.ST_UnderlineDotted:
+			add("text-decoration-style", "dotted")
+		case wml.ST_UnderlineDash:
+			add("text-decoration-style", "dashed")
+		case wml.ST_UnderlineWave:
+			add("text-decoration-style", "wavy")
+		}
+	}
+	if onOff(rpr.Strike) || onOff(rpr.Dstrike) {
+		lines = append(lines, "line-through")
+	}
+	if len(lines) > 0 {
+		add("text-decoration-line", strings.Join(lines, " "))
+	}
+	if sz := rpr.Sz; sz != nil && sz.ValAttr.ST_UnsignedDecimalNumber != nil {
+		add("font-size", strconv.FormatFloat(float64(*sz.ValAttr.ST_UnsignedDecimalNumber)/2, 'f', -1, 64)+"pt")
+	}
+	if f := rpr.RFonts; f != nil {
+		name := ""
+		switch {
+		case f.AsciiAttr != nil:
+			name = *f.AsciiAttr
+		case f.HAnsiAttr != nil:
+			name = *f.HAnsiAttr
+		case f.CsAttr != nil:
+			name = *f.CsAttr
+		}
+		if name != "" {
+			add("font-family", strconv.Quote(name))
+		}
+	}
+	if c := rpr.Color; c != nil && c.ValAttr.ST_HexColorRGB != nil {
+		add("color", "#"+strings.ToLower(*c.ValAttr.ST_HexColorRGB))
+	}
+	if onOff(rpr.SmallCaps) {
+		add("font-variant", "small-caps")
+	}
+	if onOff(rpr.Caps) {
+		add("text-transform", "uppercase")
+	}
+	if onOff(rpr.Vanish) {
+		add("display", "none")
+	}
+	if va := rpr.VertAlign; va != nil {
+		switch va.ValAttr {
+		case sharedTypes.ST_VerticalAlignRunSuperscript:
+			add("vertical-align", "super")
+			add("font-size", "smaller")
+		case sharedTypes.ST_VerticalAlignRunSubscript:
+			add("vertical-align", "sub")
+			add("font-size", "smaller")
+		}
+	}
+	if hl := rpr.Highlight; hl != nil {
+		if c, ok := highlightColors[hl.ValAttr.String()]; ok {
+			add("background-color", c)
+		}
+	} else if shd := rpr.Shd; shd != nil && shd.FillAttr != nil && shd.FillAttr.ST_HexColorRGB != nil {
+		add("background-color", "#"+strings.ToLower(*shd.FillAttr.ST_HexColorRGB))
+	}
+	return decls
+}
+
+var highlightColors = map[string]string{
+	"black": "#000000", "blue": "#0000ff", "cyan": "#00ffff", "green": "#00ff00", "magenta": "#ff00ff",
+	"red": "#ff0000", "yellow": "#ffff00", "white": "#ffffff", "darkBlue": "#000080", "darkCyan": "#008080",
+	"darkGreen": "#008000", "darkMagenta": "#800080", "darkRed": "#800000", "darkYellow": "#808000",
+	"darkGray": "#808080", "lightGray": "#c0c0c0",
+}
+
+// inlineStyle returns declarations as the value of a style attribute.
+func inlineStyle(decls []string) string {
+	return strings.Join(decls, "; ")
+}
+
+func onOff(v *wml.CT_OnOff) bool {
+	if v == nil {
+		return false
+	}
+	if v.ValAttr == nil {
+		return true
+	}
+	if v.ValAttr.Bool != nil {
+		return *v.ValAttr.Bool
+	}
+	return v.ValAttr.ST_OnOff1 == sharedTypes.ST_OnOff1On
+}
+
+func twips(m *sharedTypes.ST_TwipsMeasure) (uint64, bool) {
+	if m == nil || m.ST_UnsignedDecimalNumber == nil {
+		return 0, false
+	}
+	return *m.ST_UnsignedDecimalNumber, true
+}
+
+func signedTwips(m *wml.ST_SignedTwipsMeasure) (int64, bool) {
+	if m == nil || m.Int64 == nil {
+		return 0, false
+	}
+	return *m.Int64, true
+}
@@ -0,0 +1,758 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package epub
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/internal/odf"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml/picture"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// chapter is a content document of the book.
+type chapter struct {
+	id    string
+	href  string
+	title string
+	x     *odf.Writer
+	// notes holds the popup notes referenced in the chapter
+	notes *odf.Writer
+	empty bool
+}
+
+// tocEntry is a heading listed in the navigation document.
+type tocEntry struct {
+	level int
+	title string
+	href  string
+}
+
+// field is a complex field being written, the instruction is skipped and the
+// result kept.
+type field struct {
+	inResult bool
+}
+
+// writer converts a document to the chapters and resources of a book.
+type writer struct {
+	doc  *document.Document
+	opts Options
+	book *book
+	// x is the output of the chapter or note currently written
+	x        *odf.Writer
+	chapters []*chapter
+	cur      *chapter
+	toc      []tocEntry
+	classes  map[string]string
+	headings map[string]int
+	// images maps the parts and relationship IDs of the images to their
+	// paths in the book
+	images map[string]string
+	// part is the document part being written, the body or a note, and
+	// imageRef looks up its images
+	part     string
+	imageRef func(relID string) (common.ImageRef, bool)
+	// anchors maps the bookmarks to the chapters holding them, links to
+	// bookmarks in other chapters are resolved once all chapters are written
+	anchors map[string]string
+	fields  []*field
+	// list is the depth of the open lists of the numbering instance listID
+	list   int
+	listID int64
+	tables int
+	notes  int
+}
+
+func newWriter(d *document.Document, opts Options) *writer {
+	return &writer{doc: d, opts: opts, book: &book{opts: opts}, classes: map[string]string{},
+		headings: map[string]int{}, images: map[string]string{}, anchors: map[string]string{},
+		part: "document", imageRef: d.GetImageByRelID}
+}
+
+func (w *writer) write() (*book, error) {
+	b := w.book
+	b.meta = odf.MetaFromCore(w.doc.CoreProperties)
+	b.title = b.meta.Title
+	css := b.add("css", "styles.css", "text/css", nil)
+	css.data = w.stylesheet()
+	if body := w.doc.X().Body; body != nil {
+		for _, ble := range body.EG_BlockLevelElts {
+			w.blockContent(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+		}
+	}
+	w.closeLists()
+	if w.cur == nil {
+		w.newChapter("")
+	}
+	if b.title == "" {
+		b.title = w.chapters[0].title
+	}
+	if b.title == "" {
+		b.title = "Untitled"
+	}
+	nav := b.add("nav", "nav.xhtml", "application/xhtml+xml", w.navigation())
+	nav.props = "nav"
+	for i, ch := range w.chapters {
+		if ch.title == "" {
+			ch.title = b.title
+			if i > 0 {
+				ch.title = fmt.Sprintf("%s %d", b.title, i+1)
+			}
+		}
+		b.add(ch.id, ch.href, "application/xhtml+xml", w.resolveLinks(ch, w.content(ch)))
+		b.spine = append(b.spine, ch.id)
+	}
+	return b, nil
+}
+
+// xhtml starts a content document titled title.
+func (w *writer) xhtml(title string) *odf.Writer {
+	x := odf.NewWriter()
+	x.Raw([]byte("<!DOCTYPE html>"))
+	x.Start("html", "xmlns", "http://www.w3.org/1999/xhtml", "xmlns:epub", "http://www.idpf.org/2007/ops",
+		"xml:lang", w.opts.Language, "lang", w.opts.Language)
+	x.Start("head")
+	x.Start("title")
+	x.Text(title)
+	x.End()
+	x.Empty("link", "rel", "stylesheet", "type", "text/css", "href", "styles.css")
+	x.End()
+	return x
+}
+
+func (w *writer) content(ch *chapter) []byte {
+	x := w.xhtml(ch.title)
+	x.Start("body")
+	x.Raw(ch.x.Bytes())
+	x.Raw(ch.notes.Bytes())
+	return x.Bytes()
+}
+
+// resolveLinks points the links of a chapter to bookmarks in other chapters
+// to the chapters holding them.
+func (w *writer) resolveLinks(ch *chapter, data []byte) []byte {
+	for name, href := range w.anchors {
+		if href == ch.href {
+			continue
+		}
+		attr := []byte(`href="#` + name + `"`)
+		if bytes.Contains(data, attr) {
+			data = bytes.ReplaceAll(data, attr, []byte(`href="`+href+`#`+name+`"`))
+		}
+	}
+	return data
+}
+
+// navigation returns the navigation document listing the headings, or the
+// chapters if there are no headings.
+func (w *writer) navigation() []byte {
+	entries := w.toc
+	if len(entries) == 0 {
+		for _, ch := range w.chapters {
+			entries = append(entries, tocEntry{1, ch.title, ch.href})
+		}
+	}
+	x := w.xhtml(w.book.title)
+	x.Start("body")
+	x.Start("nav", "epub:type", "toc", "id", "toc")
+	x.Start("h1")
+	x.Text(w.book.title)
+	x.End()
+	x.Start("ol")
+	levels := []int{entries[0].level}
+	open := false
+	for _, e := range entries {
+		for len(levels) > 1 && e.level < levels[len(levels)-1] {
+			x.End()
+			x.End()
+			levels = levels[:len(levels)-1]
+		}
+		if open && e.level > levels[len(levels)-1] {
+			x.Start("ol")
+			levels = append(levels, e.level)
+		} else if open {
+			x.End()
+		}
+		x.Start("li")
+		x.Start("a", "href", e.href)
+		x.Text(e.title)
+		x.End()
+		open = true
+	}
+	return x.Bytes()
+}
+
+// newChapter ends the current chapter and starts a new one.
+func (w *writer) newChapter(title string) {
+	w.closeLists()
+	n := len(w.chapters) + 1
+	w.cur = &chapter{id: fmt.Sprintf("chapter%d", n), href: fmt.Sprintf("chapter%03d.xhtml", n), title: title,
+		x: odf.NewFragmentWriter(), notes: odf.NewFragmentWriter(), empty: true}
+	w.chapters = append(w.chapters, w.cur)
+	w.x = w.cur.x
+}
+
+// ensureChapter starts the first chapter for content preceding the first
+// heading.
+func (w *writer) ensureChapter() {
+	if w.cur == nil {
+		w.newChapter("")
+	}
+}
+
+func (w *writer) blockContent(cbc []*wml.EG_ContentBlockContent) {
+	for _, c := range cbc {
+		if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			w.blockContent(sdt.SdtContent.EG_ContentBlockContent)
+		}
+		for _, p := range c.ContentBlockContentChoice.P {
+			w.paragraph(p)
+		}
+		for _, tbl := range c.ContentBlockContentChoice.Tbl {
+			w.table(tbl)
+		}
+	}
+}
+
+// inBody reports whether body text of a chapter is written, as opposed to a
+// table cell or a note, where headings don't start chapters.
+func (w *writer) inBody() bool {
+	return w.tables == 0 && w.cur != nil && w.x == w.cur.x
+}
+
+func (w *writer) paragraph(p *wml.CT_P) {
+	level := w.paragraphLevel(p)
+	if level > 0 && level <= w.opts.ChapterLevel && (w.cur == nil || w.inBody()) {
+		title := paragraphText(p)
+		if w.cur == nil || !w.cur.empty {
+			w.newChapter(title)
+		} else if w.cur.title == "" {
+			w.cur.title = title
+		}
+	}
+	w.ensureChapter()
+	if np := numPr(p); np != nil && level <= 0 {
+		ilvl := int64(0)
+		if np.Ilvl != nil {
+			ilvl = np.Ilvl.ValAttr
+		}
+		w.enterList(np.NumId.ValAttr, ilvl)
+	} else {
+		w.closeLists()
+	}
+	class := w.classes["Normal"]
+	if p.PPr != nil && p.PPr.PStyle != nil {
+		class = w.classes[p.PPr.PStyle.ValAttr]
+	}
+	attrs := []string{"class", class, "style", inlineStyle(paragraphCSS(p.PPr))}
+	tag := "p"
+	if level > 0 {
+		tag = "h" + strconv.Itoa(min(level, 6))
+		if level <= w.opts.TOCDepth && w.inBody() {
+			id := fmt.Sprintf("toc-%d", len(w.toc)+1)
+			w.toc = append(w.toc, tocEntry{level, paragraphText(p), w.cur.href + "#" + id})
+			attrs = append(attrs, "id", id)
+		}
+	}
+	w.x.Start(tag, attrs...)
+	w.paragraphContent(p.EG_PContent)
+	w.x.End()
+	w.cur.empty = false
+}
+
+// paragraphLevel returns the heading level of a paragraph, zero for regular
+// paragraphs and -1 for the title.
+func (w *writer) paragraphLevel(p *wml.CT_P) int {
+	level := 0
+	if p.PPr != nil && p.PPr.PStyle != nil {
+		level = w.headingLevel(p.PPr.PStyle.ValAttr)
+	}
+	if level == 0 && p.PPr != nil && p.PPr.OutlineLvl != nil && p.PPr.OutlineLvl.ValAttr < 9 {
+		level = int(p.PPr.OutlineLvl.ValAttr) + 1
+	}
+	return level
+}
+
+// headingLevel returns the heading level of a paragraph style, zero for
+// regular styles and -1 for the title style.
+func (w *writer) headingLevel(styleID string) int {
+	if lvl, ok := w.headings[styleID]; ok {
+		return lvl
+	}
+	name := strings.ToLower(w.doc.GetStyleByID(styleID).Name())
+	if name == "" {
+		name = strings.ToLower(styleID)
+	}
+	name = strings.ReplaceAll(name, " ", "")
+	lvl := 0
+	switch {
+	case name == "title":
+		lvl = -1
+	case strings.HasPrefix(name, "heading"):
+		if v, err := strconv.Atoi(strings.TrimPrefix(name, "heading")); err == nil && v >= 1 && v <= 9 {
+			lvl = v
+		}
+	}
+	w.headings[styleID] = lvl
+	return lvl
+}
+
+// paragraphText returns the plain text of a paragraph.
+func paragraphText(p *wml.CT_P) string {
+	b := strings.Builder{}
+	var pcontent func([]*wml.EG_PContent)
+	var rcontent func([]*wml.EG_ContentRunContent)
+	rcontent = func(crcs []*wml.EG_ContentRunContent) {
+		for _, crc := range crcs {
+			if r := crc.ContentRunContentChoice.R; r != nil {
+				for _, ric := range r.EG_RunInnerContent {
+					if t := ric.RunInnerContentChoice.T; t != nil {
+						b.WriteString(t.Content)
+					}
+				}
+			}
+			if sdt := crc.ContentRunContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+				pcontent(sdt.SdtContent.EG_PContent)
+			}
+		}
+	}
+	pcontent = func(pcs []*wml.EG_PContent) {
+		for _, pc := range pcs {
+			rcontent(pc.PContentChoice.EG_ContentRunContent)
+			if hl := pc.PContentChoice.Hyperlink; hl != nil {
+				rcontent(hl.PContentChoice.EG_ContentRunContent)
+			}
+			for _, fs := range pc.PContentChoice.FldSimple {
+				pcontent(fs.EG_PContent)
+			}
+		}
+	}
+	pcontent(p.EG_PContent)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func numPr(p *wml.CT_P) *wml.CT_NumPr {
+	if p.PPr == nil || p.PPr.NumPr == nil || p.PPr.NumPr.NumId == nil || p.PPr.NumPr.NumId.ValAttr == 0 {
+		return nil
+	}
+	return p.PPr.NumPr
+}
+
+// enterList opens or continues the lists so that the next paragraph is a list
+// item at level ilvl of the list numID. Each level is an ol or ul element
+// holding li elements.
+func (w *writer) enterList(numID, ilvl int64) {
+	if w.list > 0 && w.listID != numID {
+		w.closeLists()
+	}
+	w.listID = numID
+	target := int(ilvl) + 1
+	if w.list > 0 && w.list >= target {
+		for w.list > target {
+			w.x.End()
+			w.x.End()
+			w.list--
+		}
+		w.x.End()
+		w.x.Start("li")
+	}
+	for w.list < target {
+		w.startList(numID, int64(w.list))
+		w.x.Start("li")
+		w.list++
+	}
+}
+
+// startList opens the list element of level ilvl of a numbering instance.
+func (w *writer) startList(numID, ilvl int64) {
+	lvl := w.doc.GetNumberingLevelByIds(numID, ilvl).X()
+	if lvl == nil || lvl.NumFmt == nil || lvl.NumFmt.ValAttr == wml.ST_NumberFormatBullet {
+		w.x.Start("ul")
+		return
+	}
+	start := ""
+	if lvl.Start != nil && lvl.Start.ValAttr != 1 {
+		start = strconv.FormatInt(lvl.Start.ValAttr, 10)
+	}
+	w.x.Start("ol", "style", "list-style-type: "+listStyleType(lvl.NumFmt.ValAttr), "start", start)
+}
+
+func listStyleType(f wml.ST_NumberFormat) string {
+	switch f {
+	case wml.ST_NumberFormatLowerLetter:
+		return "lower-alpha"
+	case wml.ST_NumberFormatUpperLetter:
+		return "upper-alpha"
+	case wml.ST_NumberFormatLowerRoman:
+		return "lower-roman"
+	case wml.ST_NumberFormatUpperRoman:
+		return "upper-roman"
+	case wml.ST_NumberFormatNone:
+		return "none"
+	}
+	return "decimal"
+}
+
+func (w *writer) closeLists() {
+	for w.list > 0 {
+		w.x.End()
+		w.x.End()
+		w.list--
+	}
+}
+
+func (w *writer) paragraphContent(pcs []*wml.EG_PContent) {
+	for _, pc := range pcs {
+		w.runContent(pc.PContentChoice.EG_ContentRunContent)
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			w.hyperlink(hl)
+		}
+		for _, fs := range pc.PContentChoice.FldSimple {
+			w.paragraphContent(fs.EG_PContent)
+		}
+	}
+}
+
+func (w *writer) hyperlink(hl *wml.CT_Hyperlink) {
+	href := ""
+	if hl.IdAttr != nil {
+		href = w.doc.GetTargetByRelId(*hl.IdAttr)
+	}
+	if hl.AnchorAttr != nil {
+		href += "#" + *hl.AnchorAttr
+	}
+	if href == "" {
+		w.runContent(hl.PContentChoice.EG_ContentRunContent)
+		return
+	}
+	w.x.Start("a", "href", href)
+	w.runContent(hl.PContentChoice.EG_ContentRunContent)
+	w.x.End()
+}
+
+func (w *writer) runContent(crcs []*wml.EG_ContentRunContent) {
+	for _, crc := range crcs {
+		if r := crc.ContentRunContentChoice.R; r != nil {
+			w.run(r)
+		}
+		if sdt := crc.ContentRunContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			w.paragraphContent(sdt.SdtContent.EG_PContent)
+		}
+		for _, rle := range crc.ContentRunContentChoice.EG_RunLevelElts {
+			for _, rme := range rle.RunLevelEltsChoice.EG_RangeMarkupElements {
+				if bs := rme.RangeMarkupElementsChoice.BookmarkStart; bs != nil && bs.NameAttr != "" && bs.NameAttr != "_GoBack" {
+					if _, ok := w.anchors[bs.NameAttr]; !ok {
+						w.anchors[bs.NameAttr] = w.cur.href
+						w.x.Empty("span", "id", bs.NameAttr)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (w *writer) activeField() *field {
+	if len(w.fields) == 0 {
+		return nil
+	}
+	return w.fields[len(w.fields)-1]
+}
+
+func (w *writer) run(r *wml.CT_R) {
+	class, style := "", ""
+	if r.RPr != nil {
+		if r.RPr.RStyle != nil {
+			class = w.classes[r.RPr.RStyle.ValAttr]
+		}
+		style = inlineStyle(textCSS(r.RPr))
+	}
+	open := false
+	ensureOpen := func() {
+		if !open && (class != "" || style != "") {
+			open = true
+			w.x.Start("span", "class", class, "style", style)
+		}
+	}
+	for _, ric := range r.EG_RunInnerContent {
+		c := ric.RunInnerContentChoice
+		if c.FldChar != nil {
+			switch c.FldChar.FldCharTypeAttr {
+			case wml.ST_FldCharTypeBegin:
+				w.fields = append(w.fields, &field{})
+			case wml.ST_FldCharTypeSeparate:
+				if f := w.activeField(); f != nil {
+					f.inResult = true
+				}
+			case wml.ST_FldCharTypeEnd:
+				if len(w.fields) > 0 {
+					w.fields = w.fields[:len(w.fields)-1]
+				}
+			}
+			continue
+		}
+		if f := w.activeField(); f != nil && !f.inResult {
+			continue
+		}
+		switch {
+		case c.T != nil:
+			ensureOpen()
+			w.x.Text(c.T.Content)
+		case c.Tab != nil:
+			ensureOpen()
+			w.x.Text("\t")
+		case c.Br != nil:
+			if c.Br.TypeAttr == wml.ST_BrTypePage || c.Br.TypeAttr == wml.ST_BrTypeColumn {
+				continue
+			}
+			ensureOpen()
+			w.x.Empty("br")
+		case c.Cr != nil:
+			ensureOpen()
+			w.x.Empty("br")
+		case c.NoBreakHyphen != nil:
+			ensureOpen()
+			w.x.Text("\u2011")
+		case c.SoftHyphen != nil:
+			ensureOpen()
+			w.x.Text("\u00ad")
+		case c.FootnoteReference != nil:
+			fn := w.doc.Footnote(c.FootnoteReference.IdAttr)
+			w.note("footnote", fn.Paragraphs(), fn.GetImageByRelID)
+		case c.EndnoteReference != nil:
+			en := w.doc.Endnote(c.EndnoteReference.IdAttr)
+			w.note("endnote", en.Paragraphs(), en.GetImageByRelID)
+		case c.Drawing != nil:
+			ensureOpen()
+			for _, dc := range c.Drawing.DrawingChoice {
+				if in := dc.Inline; in != nil && in.Graphic != nil && in.Graphic.GraphicData != nil {
+					w.image(in.Graphic.GraphicData.Any, in.Extent.CxAttr, in.Extent.CyAttr, in.DocPr)
+				}
+				if an := dc.Anchor; an != nil && an.Graphic != nil && an.Graphic.GraphicData != nil && an.Extent != nil {
+					w.image(an.Graphic.GraphicData.Any, an.Extent.CxAttr, an.Extent.CyAttr, an.DocPr)
+				}
+			}
+		}
+	}
+	if open {
+		w.x.End()
+	}
+}
+
+// note writes a reference to a popup note and the note itself to the notes
+// of the chapter. The images of the note are looked up by imageRef.
+func (w *writer) note(class string, paras []document.Paragraph, imageRef func(relID string) (common.ImageRef, bool)) {
+	w.notes++
+	id := fmt.Sprintf("note-%d", w.notes)
+	w.x.Start("a", "epub:type", "noteref", "class", "noteref", "href", "#"+id, "id", "ref-"+id)
+	w.x.Text(strconv.Itoa(w.notes))
+	w.x.End()
+
+	savedX, savedList, savedFields, savedPart, savedRef := w.x, w.list, w.fields, w.part, w.imageRef
+	w.x, w.list, w.fields, w.part, w.imageRef = w.cur.notes, 0, nil, class, imageRef
+	w.x.Start("aside", "epub:type", class, "id", id)
+	w.x.Start("p")
+	w.x.Start("a", "href", "#ref-"+id)
+	w.x.Text(strconv.Itoa(w.notes))
+	w.x.End()
+	w.x.End()
+	for _, p := range paras {
+		w.paragraph(p.X())
+	}
+	w.closeLists()
+	w.x.End()
+	w.x, w.list, w.fields, w.part, w.imageRef = savedX, savedList, savedFields, savedPart, savedRef
+}
+
+// image writes the pictures of a drawing with the size cx, cy in EMU.
+func (w *writer) image(any []interface{}, cx, cy int64, docPr *dml.CT_NonVisualDrawingProps) {
+	for _, a := range any {
+		pic, ok := a.(*picture.Pic)
+		if !ok || pic.BlipFill == nil || pic.BlipFill.Blip == nil || pic.BlipFill.Blip.EmbedAttr == nil {
+			continue
+		}
+		href, ok := w.imageHref(*pic.BlipFill.Blip.EmbedAttr)
+		if !ok {
+			continue
+		}
+		alt := ""
+		if docPr != nil && docPr.DescrAttr != nil {
+			alt = *docPr.DescrAttr
+		}
+		size := fmt.Sprintf("width: %.2fpt; height: %.2fpt", measurement.FromEMU(cx), measurement.FromEMU(cy))
+		// alt is required, Empty would omit it for images without a description
+		buf := bytes.Buffer{}
+		buf.WriteString("<img")
+		for _, a := range [][2]string{{"src", href}, {"alt", alt}, {"style", size}} {
+			buf.WriteString(" " + a[0] + "=\"")
+			xml.EscapeText(&buf, []byte(a[1]))
+			buf.WriteString("\"")
+		}
+		buf.WriteString("/>")
+		w.x.Raw(buf.Bytes())
+	}
+}
+
+// imageHref adds the image with the relationship relID of the current part
+// to the book on first use and returns its path. Only formats supported by
+// reading systems are included.
+func (w *writer) imageHref(relID string) (string, bool) {
+	key := w.part + "/" + relID
+	if href, ok := w.images[key]; ok {
+		return href, href != ""
+	}
+	w.images[key] = ""
+	ref, ok := w.imageRef(relID)
+	if !ok {
+		return "", false
+	}
+	format := strings.ToLower(ref.Format())
+	mediaType := map[string]string{"png": "image/png", "jpeg": "image/jpeg", "jpg": "image/jpeg", "gif": "image/gif",
+		"svg": "image/svg+xml", "webp": "image/webp"}[format]
+	if mediaType == "" {
+		return "", false
+	}
+	data, err := odf.ImageData(ref.Data(), ref.Path())
+	if err != nil {
+		return "", false
+	}
+	n := len(w.images)
+	href := fmt.Sprintf("images/image%d.%s", n, format)
+	w.book.add(fmt.Sprintf("image%d", n), href, mediaType, bytes.Clone(data))
+	w.images[key] = href
+	return href, true
+}
+
+type gridCell struct {
+	tc   *wml.CT_Tc
+	col  int
+	span int
+}
+
+func (w *writer) table(tbl *wml.CT_Tbl) {
+	w.ensureChapter()
+	w.closeLists()
+	w.tables++
+	var rows [][]gridCell
+	for _, rc := range tbl.EG_ContentRowContent {
+		for _, tr := range rc.ContentRowContentChoice.Tr {
+			var cells []gridCell
+			col := 0
+			for _, cc := range tr.EG_ContentCellContent {
+				for _, tc := range cc.ContentCellContentChoice.Tc {
+					span := 1
+					if tc.TcPr != nil && tc.TcPr.GridSpan != nil && tc.TcPr.GridSpan.ValAttr > 1 {
+						span = int(tc.TcPr.GridSpan.ValAttr)
+					}
+					cells = append(cells, gridCell{tc, col, span})
+					col += span
+				}
+			}
+			rows = append(rows, cells)
+		}
+	}
+	class := ""
+	if tbl.TblPr != nil && (tbl.TblPr.TblBorders != nil || tbl.TblPr.TblStyle != nil) {
+		class = "grid"
+	}
+	w.x.Start("table", "class", class)
+	if tbl.TblGrid != nil && len(tbl.TblGrid.GridCol) > 0 {
+		w.x.Start("colgroup")
+		for _, gc := range tbl.TblGrid.GridCol {
+			style := ""
+			if gc.WAttr != nil && gc.WAttr.ST_UnsignedDecimalNumber != nil {
+				style = "width: " + points(int64(*gc.WAttr.ST_UnsignedDecimalNumber))
+			}
+			w.x.Empty("col", "style", style)
+		}
+		w.x.End()
+	}
+	w.x.Start("tbody")
+	for ri, cells := range rows {
+		w.x.Start("tr")
+		for _, gc := range cells {
+			if isMergeContinue(gc.tc) {
+				continue
+			}
+			rowSpan := 1
+			if gc.tc.TcPr != nil && gc.tc.TcPr.VMerge != nil {
+				for _, next := range rows[ri+1:] {
+					c := cellAt(next, gc.col)
+					if c == nil || !isMergeContinue(c.tc) {
+						break
+					}
+					rowSpan++
+				}
+			}
+			attrs := []string{"style", cellStyle(gc.tc)}
+			if gc.span > 1 {
+				attrs = append(attrs, "colspan", strconv.Itoa(gc.span))
+			}
+			if rowSpan > 1 {
+				attrs = append(attrs, "rowspan", strconv.Itoa(rowSpan))
+			}
+			w.x.Start("td", attrs...)
+			savedList := w.list
+			w.list = 0
+			for _, ble := range gc.tc.EG_BlockLevelElts {
+				w.blockContent(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+			}
+			w.closeLists()
+			w.list = savedList
+			w.x.End()
+		}
+		w.x.End()
+	}
+	w.x.End()
+	w.x.End()
+	w.tables--
+	w.cur.empty = false
+}
+
+func isMergeContinue(tc *wml.CT_Tc) bool {
+	return tc.TcPr != nil && tc.TcPr.VMerge != nil && tc.TcPr.VMerge.ValAttr != wml.ST_MergeRestart
+}
+
+func cellAt(cells []gridCell, col int) *gridCell {
+	for i := range cells {
+		if cells[i].col == col {
+			return &cells[i]
+		}
+	}
+	return nil
+}
+
+func cellStyle(tc *wml.CT_Tc) string {
+	var decls []string
+	pr := tc.TcPr
+	if pr == nil {
+		return ""
+	}
+	if pr.Shd != nil && pr.Shd.FillAttr != nil && pr.Shd.FillAttr.ST_HexColorRGB != nil {
+		decls = append(decls, "background-color: #"+strings.ToLower(*pr.Shd.FillAttr.ST_HexColorRGB))
+	}
+	if pr.VAlign != nil {
+		switch pr.VAlign.ValAttr {
+		case wml.ST_VerticalJcCenter:
+			decls = append(decls, "vertical-align: middle")
+		case wml.ST_VerticalJcBottom:
+			decls = append(decls, "vertical-align: bottom")
+		}
+	}
+	return inlineStyle(decls)
+}
@@ -0,0 +1,540 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/unidoc/unioffice/v2/internal/formatutils"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+	"github.com/unidoc/unioffice/v2/schema/urn/schemas_microsoft_com/vml"
+)
+
+// TableFormat is the way tables are rendered by Document.ExtractTextWithOptions.
+type TableFormat byte
+
+const (
+	// TableFormatLines writes the text of every cell paragraph on its own line.
+	TableFormatLines TableFormat = iota
+	// TableFormatGrid writes tables as text grids with aligned columns.
+	TableFormatGrid
+	// TableFormatTSV writes a line per table row with the cells separated by
+	// tabs.
+	TableFormatTSV
+)
+
+// ExtractTextWithOptions returns the text of the document as one string with
+// a line per paragraph. In addition to the options of DocText.TextWithOptions
+// it controls which parts of the document are extracted (headers, footers,
+// notes, comments and text boxes), how tables and tracked changes are rendered
+// and where page boundaries are.
+//
+// Parts are written in the order headers, body, footnotes, endnotes, comments,
+// footers and are separated with an empty line. Page boundaries are only
+// written for the body and require options.ParagraphPages, which can be
+// computed with the layout engine of the convert package:
+//
+//	pages := convert.ParagraphPages(doc, nil)
+//	text := doc.ExtractTextWithOptions(document.ExtractTextOptions{ParagraphPages: pages})
+func (d *Document) ExtractTextWithOptions(options ExtractTextOptions) string {
+	if options.PageSeparator == "" {
+		options.PageSeparator = "\f"
+	}
+	e := &textExtractor{doc: d, opts: options, page: 1, counters: map[int64]map[int64]int64{}}
+	parts := [][]string{}
+	add := func(lines []string) {
+		if len(lines) > 0 {
+			parts = append(parts, lines)
+		}
+	}
+	if options.IncludeHeaders {
+		for _, h := range d.Headers() {
+			add(e.blockLevel(h.X().EG_BlockLevelElts))
+		}
+	}
+	e.body = true
+	add(e.blockLevel(d.X().Body.EG_BlockLevelElts))
+	e.body = false
+	if options.IncludeFootnotes {
+		for _, fn := range d.Footnotes() {
+			if isNoteContent(fn.X()) {
+				add(e.blockLevel(fn.X().EG_BlockLevelElts))
+			}
+		}
+	}
+	if options.IncludeEndnotes {
+		for _, en := range d.Endnotes() {
+			if isNoteContent(en.X()) {
+				add(e.blockLevel(en.X().EG_BlockLevelElts))
+			}
+		}
+	}
+	if options.IncludeComments {
+		for _, c := range d.Comments() {
+			add(e.blockLevel(c.X().EG_BlockLevelElts))
+		}
+	}
+	if options.IncludeFooters {
+		for _, f := range d.Footers() {
+			add(e.blockLevel(f.X().EG_BlockLevelElts))
+		}
+	}
+	text := make([]string, 0, len(parts))
+	for _, lines := range parts {
+		text = append(text, strings.Join(lines, "\n"))
+	}
+	return strings.Join(text, "\n\n")
+}
+
+// isNoteContent reports whether the note is a regular note and not one of
+// the separators.
+func isNoteContent(n *wml.CT_FtnEdn) bool {
+	return n.TypeAttr == wml.ST_FtnEdnUnset || n.TypeAttr == wml.ST_FtnEdnNormal
+}
+
+type textExtractor struct {
+	doc      *Document
+	opts     ExtractTextOptions
+	body     bool
+	page     int // one-based number of the current page of the body
+	counters map[int64]map[int64]int64
+
+	// text boxes found in the current paragraph, they are written after it
+	textBoxes []string
+}
+
+func (e *textExtractor) blockLevel(elts []*wml.EG_BlockLevelElts) []string {
+	lines := []string{}
+	for _, ble := range elts {
+		lines = append(lines, e.blockContent(ble.BlockLevelEltsChoice.EG_ContentBlockContent)...)
+	}
+	return lines
+}
+
+func (e *textExtractor) blockContent(cbcs []*wml.EG_ContentBlockContent) []string {
+	lines := []string{}
+	for _, c := range cbcs {
+		if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			lines = append(lines, e.blockContent(sdt.SdtContent.EG_ContentBlockContent)...)
+		}
+		for _, p := range c.ContentBlockContentChoice.P {
+			lines = append(lines, e.paragraph(p)...)
+		}
+		for _, tbl := range c.ContentBlockContentChoice.Tbl {
+			lines = append(lines, e.table(tbl)...)
+		}
+	}
+	return lines
+}
+
+// paragraph returns the text of p followed by the text of the text boxes
+// anchored in it.
+func (e *textExtractor) paragraph(p *wml.CT_P) []string {
+	lines := []string{}
+	if pg, ok := e.opts.ParagraphPages[p]; ok && e.body {
+		lines = e.pageBreaks(lines, pg)
+	}
+	outer := e.textBoxes
+	e.textBoxes = nil
+	runs := []string{}
+	for _, pc := range p.EG_PContent {
+		runs = e.paragraphContent(runs, pc)
+	}
+	sep := ""
+	if e.opts.RunsOnNewLine {
+		sep = "\n"
+	}
+	lines = append(lines, e.numbering(p)+strings.Join(runs, sep))
+	lines = append(lines, e.textBoxes...)
+	e.textBoxes = outer
+	return lines
+}
+
+func (e *textExtractor) paragraphContent(runs []string, pc *wml.EG_PContent) []string {
+	runs = e.runContent(runs, pc.PContentChoice.EG_ContentRunContent)
+	if hl := pc.PContentChoice.Hyperlink; hl != nil {
+		runs = e.runContent(runs, hl.PContentChoice.EG_ContentRunContent)
+	}
+	for _, fs := range pc.PContentChoice.FldSimple {
+		for _, fpc := range fs.EG_PContent {
+			runs = e.paragraphContent(runs, fpc)
+		}
+	}
+	return runs
+}
+
+func (e *textExtractor) runContent(runs []string, crcs []*wml.EG_ContentRunContent) []string {
+	for _, crc := range crcs {
+		runs = e.runContentChoice(runs, crc.ContentRunContentChoice, false)
+	}
+	return runs
+}
+
+func (e *textExtractor) runContentChoice(runs []string, c *wml.EG_ContentRunContentChoice, deleted bool) []string {
+	if c == nil {
+		return runs
+	}
+	if r := c.R; r != nil {
+		if t, ok := e.run(r, deleted); ok {
+			runs = append(runs, t)
+		}
+	}
+	if sdt := c.Sdt; sdt != nil && sdt.SdtContent != nil {
+		for _, pc := range sdt.SdtContent.EG_PContent {
+			runs = e.paragraphContent(runs, pc)
+		}
+	}
+	// moved text is treated as inserted at its destination and deleted at
+	// its source
+	for _, rle := range c.EG_RunLevelElts {
+		for _, ins := range []*wml.CT_RunTrackChange{rle.RunLevelEltsChoice.Ins, rle.RunLevelEltsChoice.MoveTo} {
+			if ins == nil || e.opts.ExcludeInsertedText {
+				continue
+			}
+			for _, tc := range ins.RunTrackChangeChoice {
+				runs = e.runContentChoice(runs, tc.ContentRunContentChoice, deleted)
+			}
+		}
+		for _, del := range []*wml.CT_RunTrackChange{rle.RunLevelEltsChoice.Del, rle.RunLevelEltsChoice.MoveFrom} {
+			if del == nil || !e.opts.IncludeDeletedText {
+				continue
+			}
+			for _, tc := range del.RunTrackChangeChoice {
+				runs = e.runContentChoice(runs, tc.ContentRunContentChoice, true)
+			}
+		}
+	}
+	return runs
+}
+
+// run returns the text of r and whether r contains any text. Deleted text is
+// only used if deleted is set.
+func (e *textExtractor) run(r *wml.CT_R, deleted bool) (string, bool) {
+	sb := strings.Builder{}
+	found := false
+	for _, ric := range r.EG_RunInnerContent {
+		c := ric.RunInnerContentChoice
+		switch {
+		case c.T != nil:
+			sb.WriteString(c.T.Content)
+			found = true
+		case c.DelText != nil && deleted:
+			sb.WriteString(c.DelText.Content)
+			found = true
+		case c.Tab != nil:
+			sb.WriteString("\t")
+			found = true
+		case c.Br != nil, c.Cr != nil:
+			sb.WriteString("\n")
+			found = true
+		case c.Pict != nil && !e.opts.ExcludeTextBoxes:
+			for _, a := range c.Pict.Any {
+				if shape, ok := a.(*vml.Shape); ok {
+					for _, sc := range shape.ShapeChoice {
+						if tb := sc.ShapeElementsChoice.Textbox; tb != nil && tb.TxbxContent != nil {
+							e.textBox(tb.TxbxContent.EG_BlockLevelElts)
+						}
+					}
+				}
+			}
+		case c.Drawing != nil && !e.opts.ExcludeTextBoxes:
+			e.drawing(c.Drawing)
+		}
+	}
+	if !e.opts.ExcludeTextBoxes {
+		for _, x := range r.Extra {
+			if acr, ok := x.(*wml.AlternateContentRun); ok && acr.Choice.Drawing != nil {
+				e.drawing(acr.Choice.Drawing)
+			}
+		}
+	}
+	return sb.String(), found
+}
+
+func (e *textExtractor) drawing(d *wml.CT_Drawing) {
+	for _, dc := range d.DrawingChoice {
+		var gd []interface{}
+		if dc.Anchor != nil && dc.Anchor.Graphic != nil && dc.Anchor.Graphic.GraphicData != nil {
+			gd = dc.Anchor.Graphic.GraphicData.Any
+		}
+		if dc.Inline != nil && dc.Inline.Graphic != nil && dc.Inline.Graphic.GraphicData != nil {
+			gd = dc.Inline.Graphic.GraphicData.Any
+		}
+		for _, a := range gd {
+			wsp, ok := a.(*wml.WdWsp)
+			if !ok || wsp.WordprocessingShapeChoice1 == nil {
+				continue
+			}
+			if tb := wsp.WordprocessingShapeChoice1.Txbx; tb != nil && tb.TxbxContent != nil {
+				e.textBox(tb.TxbxContent.EG_BlockLevelElts)
+			}
+		}
+	}
+}
+
+func (e *textExtractor) textBox(elts []*wml.EG_BlockLevelElts) {
+	body := e.body
+	e.body = false
+	lines := e.blockLevel(elts)
+	e.body = body
+	e.textBoxes = append(e.textBoxes, lines...)
+}
+
+// numbering returns the numbering text of p and advances the list counters.
+func (e *textExtractor) numbering(p *wml.CT_P) string {
+	if !e.opts.WithNumbering || p.PPr == nil || p.PPr.NumPr == nil {
+		return ""
+	}
+	numPr := p.PPr.NumPr
+	if numPr.NumId == nil || numPr.Ilvl == nil || e.doc.Numbering.X() == nil {
+		return ""
+	}
+	lvl := e.doc.GetNumberingLevelByIds(numPr.NumId.ValAttr, numPr.Ilvl.ValAttr).X()
+	if lvl == nil {
+		return ""
+	}
+	abstractID := int64(-1)
+	for _, num := range e.doc.Numbering.X().Num {
+		if num != nil && num.NumIdAttr == numPr.NumId.ValAttr && num.AbstractNumId != nil {
+			abstractID = num.AbstractNumId.ValAttr
+		}
+	}
+	levels, ok := e.counters[abstractID]
+	if !ok {
+		levels = map[int64]int64{}
+		e.counters[abstractID] = levels
+	}
+	ilvl := lvl.IlvlAttr
+	if _, ok := levels[ilvl]; !ok && lvl.Start != nil {
+		levels[ilvl] = lvl.Start.ValAttr - 1
+	}
+	levels[ilvl]++
+	for l := range levels {
+		if l > ilvl {
+			delete(levels, l)
+		}
+	}
+	lvlText := ""
+	if lvl.LvlText != nil && lvl.LvlText.ValAttr != nil {
+		lvlText = *lvl.LvlText.ValAttr
+	}
+	return formatutils.FormatNumberingText(levels[ilvl], ilvl, lvlText, lvl.NumFmt, levels) + e.opts.NumberingIndent
+}
+
+// gridCell is a table cell placed in the column grid of its table.
+type gridCell struct {
+	col, span int
+	lines     []string
+}
+
+// pageBreaks appends the page separators needed before content that starts
+// on page pg.
+func (e *textExtractor) pageBreaks(lines []string, pg int) []string {
+	for ; e.page < pg; e.page++ {
+		lines = append(lines, e.opts.PageSeparator)
+	}
+	return lines
+}
+
+// rowBreaks returns the page separators needed before a row of a body table,
+// the row starts on the page of its first paginated paragraph.
+func (e *textExtractor) rowBreaks(tr *wml.CT_Row) []string {
+	if !e.body || e.opts.ParagraphPages == nil {
+		return nil
+	}
+	pg, found := 0, false
+	walkRowCells(tr.EG_ContentCellContent, func(tc *wml.CT_Tc) {
+		walkBlockParagraphs(tc.EG_BlockLevelElts, func(p *wml.CT_P) {
+			if n, ok := e.opts.ParagraphPages[p]; ok && !found {
+				pg, found = n, true
+			}
+		})
+	})
+	if !found {
+		return nil
+	}
+	return e.pageBreaks(nil, pg)
+}
+
+func (e *textExtractor) table(tbl *wml.CT_Tbl) []string {
+	rows := [][]gridCell{}
+	breaks := [][]string{}
+	lines := []string{}
+	var addRows func(rcs []*wml.EG_ContentRowContent)
+	addRows = func(rcs []*wml.EG_ContentRowContent) {
+		for _, rc := range rcs {
+			if sdt := rc.ContentRowContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+				addRows(sdt.SdtContent.EG_ContentRowContent)
+			}
+			for _, tr := range rc.ContentRowContentChoice.Tr {
+				br := e.rowBreaks(tr)
+				row := e.row(tr)
+				if e.opts.TableFormat == TableFormatLines {
+					lines = append(lines, br...)
+					for _, c := range row {
+						lines = append(lines, c.lines...)
+					}
+				}
+				rows = append(rows, row)
+				breaks = append(breaks, br)
+			}
+		}
+	}
+	addRows(tbl.EG_ContentRowContent)
+	switch e.opts.TableFormat {
+	case TableFormatTSV:
+		for i, row := range rows {
+			lines = append(lines, breaks[i]...)
+			cells := make([]string, 0, len(row))
+			for _, c := range row {
+				cells = append(cells, tsvReplacer.Replace(strings.Join(c.lines, " ")))
+				for i := 1; i < c.span; i++ {
+					cells = append(cells, "")
+				}
+			}
+			lines = append(lines, strings.Join(cells, "\t"))
+		}
+	case TableFormatGrid:
+		lines = renderGrid(rows, breaks)
+	}
+	return lines
+}
+
+var (
+	tsvReplacer = strings.NewReplacer("\t", " ", "\n", " ")
+	tabReplacer = strings.NewReplacer("\t", " ")
+)
+
+func (e *textExtractor) row(tr *wml.CT_Row) []gridCell {
+	row := []gridCell{}
+	col := 0
+	var addCells func(cccs []*wml.EG_ContentCellContent)
+	addCells = func(cccs []*wml.EG_ContentCellContent) {
+		for _, ccc := range cccs {
+			if sdt := ccc.ContentCellContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+				addCells(sdt.SdtContent.EG_ContentCellContent)
+			}
+			for _, tc := range ccc.ContentCellContentChoice.Tc {
+				c := gridCell{col: col, span: 1}
+				if tc.TcPr != nil && tc.TcPr.GridSpan != nil && tc.TcPr.GridSpan.ValAttr > 1 {
+					c.span = int(tc.TcPr.GridSpan.ValAttr)
+				}
+				// continued vertical merges have the text in the first cell
+				if tc.TcPr == nil || tc.TcPr.VMerge == nil || tc.TcPr.VMerge.ValAttr == wml.ST_MergeRestart {
+					c.lines = e.cell(tc)
+				}
+				row = append(row, c)
+				col += c.span
+			}
+		}
+	}
+	addCells(tr.EG_ContentCellContent)
+	return row
+}
+
+func (e *textExtractor) cell(tc *wml.CT_Tc) []string {
+	body := e.body
+	e.body = false
+	lines := e.blockLevel(tc.EG_BlockLevelElts)
+	e.body = body
+	if e.opts.TableFormat == TableFormatLines {
+		return lines
+	}
+	// lines of a grid cell may not contain line breaks or tabs
+	return strings.Split(tabReplacer.Replace(strings.Join(lines, "\n")), "\n")
+}
+
+// renderGrid draws rows as a grid of aligned columns separated by '|' with
+// '+---+' lines between the rows. The lines of breaks are written before the
+// row with the same index.
+func renderGrid(rows [][]gridCell, breaks [][]string) []string {
+	ncols := 0
+	for _, row := range rows {
+		if n := len(row); n > 0 && row[n-1].col+row[n-1].span > ncols {
+			ncols = row[n-1].col + row[n-1].span
+		}
+	}
+	if ncols == 0 {
+		return nil
+	}
+	widths := make([]int, ncols)
+	cellWidth := func(c gridCell) int {
+		w := 0
+		for _, l := range c.lines {
+			if n := utf8.RuneCountInString(l); n > w {
+				w = n
+			}
+		}
+		return w
+	}
+	spanWidth := func(c gridCell) int {
+		w := 3 * (c.span - 1)
+		for i := c.col; i < c.col+c.span; i++ {
+			w += widths[i]
+		}
+		return w
+	}
+	for _, row := range rows {
+		for _, c := range row {
+			if c.span == 1 && cellWidth(c) > widths[c.col] {
+				widths[c.col] = cellWidth(c)
+			}
+		}
+	}
+	for _, row := range rows {
+		for _, c := range row {
+			if c.span > 1 && cellWidth(c) > spanWidth(c) {
+				widths[c.col+c.span-1] += cellWidth(c) - spanWidth(c)
+			}
+		}
+	}
+	border := strings.Builder{}
+	border.WriteString("+")
+	for _, w := range widths {
+		border.WriteString(strings.Repeat("-", w+2))
+		border.WriteString("+")
+	}
+	lines := []string{border.String()}
+	for ri, row := range rows {
+		lines = append(lines, breaks[ri]...)
+		// fill the columns missing at the end of the row
+		if n := len(row); n == 0 || row[n-1].col+row[n-1].span < ncols {
+			col := 0
+			if n > 0 {
+				col = row[n-1].col + row[n-1].span
+			}
+			row = append(row, gridCell{col: col, span: ncols - col})
+		}
+		height := 1
+		for _, c := range row {
+			if len(c.lines) > height {
+				height = len(c.lines)
+			}
+		}
+		for i := 0; i < height; i++ {
+			sb := strings.Builder{}
+			sb.WriteString("|")
+			for _, c := range row {
+				text := ""
+				if i < len(c.lines) {
+					text = c.lines[i]
+				}
+				sb.WriteString(" ")
+				sb.WriteString(text)
+				sb.WriteString(strings.Repeat(" ", spanWidth(c)-utf8.RuneCountInString(text)))
+				sb.WriteString(" |")
+			}
+			lines = append(lines, sb.String())
+		}
+		lines = append(lines, border.String())
+	}
+	return lines
+}
@@ -0,0 +1,121 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/internal/fontembed"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// FontEmbedStyle is the style of an embedded font.
+type FontEmbedStyle byte
+
+// FontEmbedStyle constants.
+const (
+	FontEmbedRegular FontEmbedStyle = iota
+	FontEmbedBold
+	FontEmbedItalic
+	FontEmbedBoldItalic
+)
+
+// EmbedFont embeds the TrueType font ttf as the given style of the font family
+// name, so the document is displayed with it where the font isn't installed.
+// If subset is set, only the glyphs of the characters used in the document are
+// embedded. The font is stored obfuscated as required by the format and the
+// font table and settings are updated.
+func (d *Document) EmbedFont(name string, style FontEmbedStyle, ttf []byte, subset bool) error {
+	if subset {
+		text := d.ExtractTextWithOptions(ExtractTextOptions{IncludeHeaders: true, IncludeFooters: true, IncludeFootnotes: true, IncludeEndnotes: true, IncludeComments: true})
+		var err error
+		if ttf, err = fontembed.Subset(ttf, text); err != nil {
+			return err
+		}
+	}
+	key, err := fontembed.NewKey()
+	if err != nil {
+		return err
+	}
+	data, err := fontembed.Obfuscate(ttf, key)
+	if err != nil {
+		return err
+	}
+	n := 1
+	for d.HasExtraFile(fmt.Sprintf("word/fonts/font%d.odttf", n)) {
+		n++
+	}
+	target := fmt.Sprintf("fonts/font%d.odttf", n)
+	if err := d.AddExtraFileFromBytes("word/"+target, data); err != nil {
+		return err
+	}
+	if d._ecbc.X() == nil {
+		d._ecbc = common.NewRelationships()
+	}
+	rel := wml.NewCT_FontRel()
+	rel.IdAttr = d._ecbc.AddRelationship(target, unioffice.FontEmbeddingType).ID()
+	rel.FontKeyAttr = key
+	d.ContentTypes.EnsureDefault("odttf", "application/vnd.openxmlformats-officedocument.obfuscatedFont")
+
+	font := d.fontTableEntry(name)
+	var old *wml.CT_FontRel
+	switch style {
+	case FontEmbedBold:
+		old, font.EmbedBold = font.EmbedBold, rel
+	case FontEmbedItalic:
+		old, font.EmbedItalic = font.EmbedItalic, rel
+	case FontEmbedBoldItalic:
+		old, font.EmbedBoldItalic = font.EmbedBoldItalic, rel
+	default:
+		old, font.EmbedRegular = font.EmbedRegular, rel
+	}
+	if old != nil {
+		// drop the replaced font with its relationship
+		if r := d._ecbc.GetByRelId(old.IdAttr); r.X() != nil {
+			target := r.Target()
+			if strings.HasPrefix(target, "/") {
+				target = target[1:]
+			} else {
+				target = path.Join("word", target)
+			}
+			d.RemoveExtraFile(target)
+			d._ecbc.Remove(r)
+		}
+	}
+
+	d.Settings.X().EmbedTrueTypeFonts = wml.NewCT_OnOff()
+	if subset {
+		d.Settings.X().SaveSubsetFonts = wml.NewCT_OnOff()
+	}
+	return nil
+}
+
+// fontTableEntry returns the font table entry of the font family name, adding
+// the font table and the entry if required.
+func (d *Document) fontTableEntry(name string) *wml.CT_Font {
+	if d._gfda == nil {
+		d._gfda = wml.NewFonts()
+		d._fgg.AddRelationship("fontTable.xml", unioffice.FontTableType)
+		d.ContentTypes.AddOverride("/word/fontTable.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.fontTable+xml")
+	}
+	for _, f := range d._gfda.Font {
+		if f.NameAttr == name {
+			return f
+		}
+	}
+	f := wml.NewCT_Font()
+	f.NameAttr = name
+	d._gfda.Font = append(d._gfda.Font, f)
+	return f
+}
@@ -0,0 +1,741 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/unidoc/unioffice/v2/schema/schemas.microsoft.com/office/word/2010/wordml"
+	"github.com/unidoc/unioffice/v2/schema/soo/ofc/sharedTypes"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// ContentControlType is the type of a content control.
+type ContentControlType byte
+
+// ContentControlType constants.
+const (
+	ContentControlTypeRichText ContentControlType = iota
+	ContentControlTypeText
+	ContentControlTypeComboBox
+	ContentControlTypeDropDownList
+	ContentControlTypeDate
+	ContentControlTypeCheckBox
+	// ContentControlTypeOther is pictures, equations, building block
+	// galleries, citations and groups.
+	ContentControlTypeOther
+)
+
+// ContentControl is a block-level or inline content control (structured
+// document tag) of the document body.
+type ContentControl struct {
+	_doc   *Document
+	_block *wml.CT_SdtBlock
+	_run   *wml.CT_SdtRun
+}
+
+// ContentControls returns the content controls of the document body,
+// including content controls nested in tables and other content controls.
+func (d *Document) ContentControls() []ContentControl {
+	ccs := []ContentControl{}
+	var blocks func(cbcs []*wml.EG_ContentBlockContent)
+	var inline func(pcs []*wml.EG_PContent)
+	runContent := func(crcs []*wml.EG_ContentRunContent) {
+		for _, crc := range crcs {
+			if c := crc.ContentRunContentChoice; c != nil && c.Sdt != nil {
+				ccs = append(ccs, ContentControl{d, nil, c.Sdt})
+				if c.Sdt.SdtContent != nil {
+					inline(c.Sdt.SdtContent.EG_PContent)
+				}
+			}
+		}
+	}
+	inline = func(pcs []*wml.EG_PContent) {
+		for _, pc := range pcs {
+			runContent(pc.PContentChoice.EG_ContentRunContent)
+			if hl := pc.PContentChoice.Hyperlink; hl != nil {
+				runContent(hl.PContentChoice.EG_ContentRunContent)
+			}
+		}
+	}
+	blocks = func(cbcs []*wml.EG_ContentBlockContent) {
+		for _, c := range cbcs {
+			if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil {
+				ccs = append(ccs, ContentControl{d, sdt, nil})
+				if sdt.SdtContent != nil {
+					blocks(sdt.SdtContent.EG_ContentBlockContent)
+				}
+			}
+			for _, p := range c.ContentBlockContentChoice.P {
+				inline(p.EG_PContent)
+			}
+			for _, tbl := range c.ContentBlockContentChoice.Tbl {
+				walkTableCells(tbl, func(tc *wml.CT_Tc) {
+					for _, ble := range tc.EG_BlockLevelElts {
+						blocks(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+					}
+				})
+			}
+		}
+	}
+	for _, ble := range d.X().Body.EG_BlockLevelElts {
+		blocks(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+	}
+	return ccs
+}
+
+func (c ContentControl) pr() *wml.CT_SdtPr {
+	if c._block != nil {
+		return c._block.SdtPr
+	}
+	return c._run.SdtPr
+}
+
+// IsInline reports whether the content control is within a paragraph rather
+// than holding paragraphs and tables.
+func (c ContentControl) IsInline() bool { return c._run != nil }
+
+// Tag returns the tag of the content control.
+func (c ContentControl) Tag() string {
+	if pr := c.pr(); pr != nil && pr.Tag != nil {
+		return pr.Tag.ValAttr
+	}
+	return ""
+}
+
+// Alias returns the title of the content control.
+func (c ContentControl) Alias() string {
+	if pr := c.pr(); pr != nil && pr.Alias != nil {
+		return pr.Alias.ValAttr
+	}
+	return ""
+}
+
+// Name returns the name the content control is filled by, its tag or its
+// title if it has no tag.
+func (c ContentControl) Name() string {
+	if tag := c.Tag(); tag != "" {
+		return tag
+	}
+	return c.Alias()
+}
+
+// Type returns the type of the content control.
+func (c ContentControl) Type() ContentControlType {
+	if c.checkBox() != nil {
+		return ContentControlTypeCheckBox
+	}
+	pr := c.pr()
+	if pr == nil || pr.SdtPrChoice == nil {
+		return ContentControlTypeRichText
+	}
+	switch ch := pr.SdtPrChoice; {
+	case ch.Text != nil:
+		return ContentControlTypeText
+	case ch.ComboBox != nil:
+		return ContentControlTypeComboBox
+	case ch.DropDownList != nil:
+		return ContentControlTypeDropDownList
+	case ch.Date != nil:
+		return ContentControlTypeDate
+	case ch.RichText != nil:
+		return ContentControlTypeRichText
+	case ch.Equation != nil, ch.Picture != nil, ch.DocPartObj != nil, ch.DocPartList != nil,
+		ch.Citation != nil, ch.Group != nil, ch.Bibliography != nil:
+		return ContentControlTypeOther
+	}
+	return ContentControlTypeRichText
+}
+
+// checkBox returns the check box properties of a check box content control,
+// which Word 2010 and later write as a w14:checkbox extension.
+func (c ContentControl) checkBox() *wordml.CT_SdtCheckbox {
+	pr := c.pr()
+	if pr == nil {
+		return nil
+	}
+	for _, x := range pr.Extra {
+		if cb, ok := x.(*wordml.Checkbox); ok {
+			return &cb.CT_SdtCheckbox
+		}
+	}
+	return nil
+}
+
+// IsChecked reports whether a check box content control is checked.
+func (c ContentControl) IsChecked() bool {
+	cb := c.checkBox()
+	if cb == nil || cb.Checked == nil {
+		return false
+	}
+	return cb.Checked.ValAttr == wordml.ST_OnOffTrue || cb.Checked.ValAttr == wordml.ST_OnOff1
+}
+
+// SetChecked checks or unchecks a check box content control and shows the
+// symbol of the new state. Other content controls are left as they are.
+func (c ContentControl) SetChecked(checked bool) {
+	cb := c.checkBox()
+	if cb == nil {
+		return
+	}
+	cb.Checked = wordml.NewCT_OnOff()
+	sym, r := cb.UncheckedState, '\u2610'
+	cb.Checked.ValAttr = wordml.ST_OnOff0
+	if checked {
+		sym, r = cb.CheckedState, '\u2612'
+		cb.Checked.ValAttr = wordml.ST_OnOff1
+	}
+	if sym != nil && sym.ValAttr != nil {
+		if v, err := strconv.ParseUint(*sym.ValAttr, 16, 32); err == nil {
+			r = rune(v)
+		}
+	}
+	c.SetText(string(r))
+}
+
+func (c ContentControl) listItems() []*wml.CT_SdtListItem {
+	pr := c.pr()
+	if pr == nil || pr.SdtPrChoice == nil {
+		return nil
+	}
+	if cb := pr.SdtPrChoice.ComboBox; cb != nil {
+		return cb.ListItem
+	}
+	if dd := pr.SdtPrChoice.DropDownList; dd != nil {
+		return dd.ListItem
+	}
+	return nil
+}
+
+// PossibleValues returns the display texts of the list items of a combo box
+// or drop-down list content control.
+func (c ContentControl) PossibleValues() []string {
+	values := []string{}
+	for _, li := range c.listItems() {
+		switch {
+		case li.DisplayTextAttr != nil:
+			values = append(values, *li.DisplayTextAttr)
+		case li.ValueAttr != nil:
+			values = append(values, *li.ValueAttr)
+		}
+	}
+	return values
+}
+
+// IsPlaceholder reports whether the content control shows its placeholder
+// text rather than a value.
+func (c ContentControl) IsPlaceholder() bool {
+	pr := c.pr()
+	return pr != nil && onOff(pr.ShowingPlcHdr)
+}
+
+// Text returns the text of the content control, with paragraphs separated by
+// new lines. It is empty while the placeholder text is shown.
+func (c ContentControl) Text() string {
+	if c.IsPlaceholder() {
+		return ""
+	}
+	sb := strings.Builder{}
+	appendRuns := func(pcs []*wml.EG_PContent) {
+		walkContentRuns(pcs, func(r *wml.CT_R) {
+			sb.WriteString(Run{c._doc, r}.Text())
+		})
+	}
+	if c._run != nil {
+		if c._run.SdtContent != nil {
+			appendRuns(c._run.SdtContent.EG_PContent)
+		}
+		return sb.String()
+	}
+	if c._block.SdtContent != nil {
+		first := true
+		walkContentParagraphs(c._block.SdtContent.EG_ContentBlockContent, func(p *wml.CT_P) {
+			if !first {
+				sb.WriteString("\n")
+			}
+			first = false
+			appendRuns(p.EG_PContent)
+		})
+	}
+	return sb.String()
+}
+
+// SetText replaces the content of the content control with text and turns
+// off its placeholder. The formatting of the first paragraph and run is kept
+// and new lines start new paragraphs in block-level content controls and
+// break lines otherwise.
+func (c ContentControl) SetText(text string) {
+	if pr := c.pr(); pr != nil {
+		pr.ShowingPlcHdr = nil
+	}
+	var rpr *wml.CT_RPr
+	if c._run != nil {
+		if c._run.SdtContent == nil {
+			c._run.SdtContent = wml.NewCT_SdtContentRun()
+		}
+		walkContentRuns(c._run.SdtContent.EG_PContent, func(r *wml.CT_R) {
+			if rpr == nil && r.RPr != nil {
+				rpr = r.RPr
+			}
+		})
+		c._run.SdtContent.EG_PContent = []*wml.EG_PContent{newTextContent(c._doc, rpr, text)}
+		return
+	}
+	if c._block.SdtContent == nil {
+		c._block.SdtContent = wml.NewCT_SdtContentBlock()
+	}
+	var ppr *wml.CT_PPr
+	first := true
+	walkContentParagraphs(c._block.SdtContent.EG_ContentBlockContent, func(p *wml.CT_P) {
+		if first {
+			first = false
+			ppr = p.PPr
+			walkParagraphRuns(p, func(r *wml.CT_R) {
+				if rpr == nil && r.RPr != nil {
+					rpr = r.RPr
+				}
+			})
+		}
+	})
+	cbcs := []*wml.EG_ContentBlockContent{}
+	for _, line := range strings.Split(text, "\n") {
+		p := wml.NewCT_P()
+		if ppr != nil {
+			cp := *ppr
+			p.PPr = &cp
+		}
+		p.EG_PContent = []*wml.EG_PContent{newTextContent(c._doc, rpr, line)}
+		cbc := wml.NewEG_ContentBlockContent()
+		cbc.ContentBlockContentChoice.P = []*wml.CT_P{p}
+		cbcs = append(cbcs, cbc)
+	}
+	c._block.SdtContent.EG_ContentBlockContent = cbcs
+}
+
+// MaxLength returns the maximum number of characters of a text form field,
+// zero if it is not limited.
+func (f FormField) MaxLength() int {
+	if ti := f.textInput(); ti != nil && ti.MaxLength != nil {
+		return int(ti.MaxLength.ValAttr)
+	}
+	return 0
+}
+
+func (f FormField) textInput() *wml.CT_FFTextInput {
+	for _, c := range f._gada.FFDataChoice {
+		if c.TextInput != nil {
+			return c.TextInput
+		}
+	}
+	return nil
+}
+
+// FormValueError is a value that can't be filled into the form fields or
+// content controls with the name Name.
+type FormValueError struct {
+	Name   string
+	Reason string
+}
+
+func (e FormValueError) Error() string { return fmt.Sprintf("%s: %s", e.Name, e.Reason) }
+
+// FormFillError is returned by FillForm for the values that couldn't be
+// filled.
+type FormFillError []FormValueError
+
+func (e FormFillError) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = v.Error()
+	}
+	return "filling form: " + strings.Join(msgs, "; ")
+}
+
+// formFill is a validated value for a form field or content control.
+type formFill struct {
+	field   *FormField
+	control *ContentControl
+	text    string
+	checked bool
+	date    *time.Time
+}
+
+// FillForm fills the legacy form fields named by the keys of values and the
+// content controls whose tag, or title if they have no tag, matches a key.
+// Check boxes take booleans, drop-down lists one of their possible values,
+// text fields and content controls strings, numbers or time.Time values,
+// which are formatted with the date format of the field. Nil values are
+// skipped.
+//
+// All values are validated before the document is changed. Unknown names,
+// values of the wrong type, values that aren't among the possible values of
+// a drop-down list and text longer than the maximum length of a field are
+// returned as a FormFillError, in which case nothing is filled.
+func (d *Document) FillForm(values map[string]interface{}) error {
+	fields := map[string][]FormField{}
+	for _, f := range d.FormFields() {
+		fields[f.Name()] = append(fields[f.Name()], f)
+	}
+	controls := map[string][]ContentControl{}
+	for _, c := range d.ContentControls() {
+		if name := c.Name(); name != "" {
+			controls[name] = append(controls[name], c)
+		}
+	}
+	errs := FormFillError{}
+	fills := []formFill{}
+	for _, name := range sortedKeys(values) {
+		v := values[name]
+		if v == nil {
+			continue
+		}
+		if len(fields[name]) == 0 && len(controls[name]) == 0 {
+			errs = append(errs, FormValueError{name, "no form field or content control with this name"})
+			continue
+		}
+		for i := range fields[name] {
+			fill, err := fieldFill(&fields[name][i], v)
+			if err != nil {
+				errs = append(errs, FormValueError{name, err.Error()})
+				continue
+			}
+			fills = append(fills, fill)
+		}
+		for i := range controls[name] {
+			fill, err := controlFill(&controls[name][i], v)
+			if err != nil {
+				errs = append(errs, FormValueError{name, err.Error()})
+				continue
+			}
+			fills = append(fills, fill)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	for _, f := range fills {
+		if f.field != nil {
+			if f.field.Type() == FormFieldTypeCheckBox {
+				f.field.SetChecked(f.checked)
+			} else {
+				f.field.SetValue(f.text)
+			}
+			continue
+		}
+		if f.control.Type() == ContentControlTypeCheckBox {
+			f.control.SetChecked(f.checked)
+			continue
+		}
+		f.control.SetText(f.text)
+		if f.date != nil {
+			t := *f.date
+			f.control.pr().SdtPrChoice.Date.FullDateAttr = &t
+		}
+	}
+	return nil
+}
+
+// FillFormFromStruct fills the form from the exported fields of the struct
+// or struct pointer v, see FillForm. Fields are filled into the form field
+// or content control named by their form tag, e.g. `form:"ApplicantName"`,
+// or by the field name. The tag option omitempty skips zero values and the
+// tag "-" skips the field.
+func (d *Document) FillFormFromStruct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return errors.New("nil form data")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("form data must be a struct, got %s", rv.Kind())
+	}
+	values := map[string]interface{}{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fv := rv.Field(i)
+		if opts == "omitempty" && fv.IsZero() {
+			continue
+		}
+		for (fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface) && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if (fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface) && fv.IsNil() {
+			continue
+		}
+		values[name] = fv.Interface()
+	}
+	return d.FillForm(values)
+}
+
+// FormValues returns the values of the legacy form fields and named content
+// controls of the document body by name. Check boxes have boolean values,
+// all other fields and content controls have their text as value. A name
+// shared by several fields or content controls has the value of the first
+// form field with the name, or of the first content control if no form field
+// has it.
+func (d *Document) FormValues() map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, f := range d.FormFields() {
+		if _, ok := values[f.Name()]; ok {
+			continue
+		}
+		if f.Type() == FormFieldTypeCheckBox {
+			values[f.Name()] = f.IsChecked()
+		} else {
+			values[f.Name()] = f.Value()
+		}
+	}
+	for _, c := range d.ContentControls() {
+		name := c.Name()
+		if _, ok := values[name]; name == "" || ok {
+			continue
+		}
+		if c.Type() == ContentControlTypeCheckBox {
+			values[name] = c.IsChecked()
+			continue
+		}
+		values[name] = c.Text()
+	}
+	return values
+}
+
+// FormValuesJSON returns FormValues encoded as a JSON object.
+func (d *Document) FormValuesJSON() ([]byte, error) {
+	return json.Marshal(d.FormValues())
+}
+
+func fieldFill(f *FormField, v interface{}) (formFill, error) {
+	fill := formFill{field: f}
+	switch f.Type() {
+	case FormFieldTypeCheckBox:
+		b, err := formBool(v)
+		fill.checked = b
+		return fill, err
+	case FormFieldTypeDropDown:
+		s, ok := v.(string)
+		if !ok {
+			return fill, fmt.Errorf("drop-down form field needs a string, got %T", v)
+		}
+		if !containsString(f.PossibleValues(), s) {
+			return fill, fmt.Errorf("%q is not one of %q", s, f.PossibleValues())
+		}
+		fill.text = s
+		return fill, nil
+	case FormFieldTypeText:
+		ti := f.textInput()
+		format := ""
+		if ti.Format != nil {
+			format = ti.Format.ValAttr
+		}
+		s, err := formText(v, format)
+		if err != nil {
+			return fill, err
+		}
+		if ti.Type != nil {
+			switch ti.Type.ValAttr {
+			case wml.ST_FFTextTypeNumber:
+				if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err != nil {
+					return fill, fmt.Errorf("number form field needs a number, got %q", s)
+				}
+			case wml.ST_FFTextTypeCurrentTime, wml.ST_FFTextTypeCurrentDate, wml.ST_FFTextTypeCalculated:
+				return fill, errors.New("form field is calculated and can't be filled")
+			}
+		}
+		if max := f.MaxLength(); max > 0 && utf8.RuneCountInString(s) > max {
+			return fill, fmt.Errorf("text is longer than the maximum length %d", max)
+		}
+		fill.text = s
+		return fill, nil
+	}
+	return fill, errors.New("form field has an unknown type")
+}
+
+func controlFill(c *ContentControl, v interface{}) (formFill, error) {
+	fill := formFill{control: c}
+	switch c.Type() {
+	case ContentControlTypeCheckBox:
+		b, err := formBool(v)
+		fill.checked = b
+		return fill, err
+	case ContentControlTypeDropDownList:
+		s, ok := v.(string)
+		if !ok {
+			return fill, fmt.Errorf("drop-down list content control needs a string, got %T", v)
+		}
+		for _, li := range c.listItems() {
+			if li.ValueAttr != nil && *li.ValueAttr == s || li.DisplayTextAttr != nil && *li.DisplayTextAttr == s {
+				fill.text = s
+				if li.DisplayTextAttr != nil {
+					fill.text = *li.DisplayTextAttr
+				}
+				return fill, nil
+			}
+		}
+		return fill, fmt.Errorf("%q is not one of %q", s, c.PossibleValues())
+	case ContentControlTypeDate:
+		format := ""
+		if df := c.pr().SdtPrChoice.Date.DateFormat; df != nil {
+			format = df.ValAttr
+		}
+		if t, ok := v.(time.Time); ok {
+			fill.date = &t
+		}
+		s, err := formText(v, format)
+		fill.text = s
+		return fill, err
+	case ContentControlTypeText:
+		s, err := formText(v, "")
+		if err != nil {
+			return fill, err
+		}
+		if ml := c.pr().SdtPrChoice.Text.MultiLineAttr; strings.Contains(s, "\n") && !stOnOff(ml) {
+			return fill, errors.New("text content control doesn't allow multiple lines")
+		}
+		fill.text = s
+		return fill, nil
+	case ContentControlTypeRichText, ContentControlTypeComboBox:
+		s, err := formText(v, "")
+		fill.text = s
+		return fill, err
+	}
+	return fill, errors.New("content control can't be filled with text")
+}
+
+func stOnOff(v *sharedTypes.ST_OnOff) bool {
+	if v == nil {
+		return false
+	}
+	if v.Bool != nil {
+		return *v.Bool
+	}
+	return v.ST_OnOff1 == sharedTypes.ST_OnOff1On
+}
+
+// formBool converts a check box value, a boolean or a string such as "true"
+// or "0".
+func formBool(v interface{}) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		if r, err := strconv.ParseBool(b); err == nil {
+			return r, nil
+		}
+	}
+	return false, fmt.Errorf("check box needs a boolean, got %T %v", v, v)
+}
+
+// formText converts a text value, formatting times with the Word date format.
+func formText(v interface{}, dateFormat string) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case time.Time:
+		if dateFormat == "" {
+			dateFormat = "M/d/yyyy"
+		}
+		return t.Format(wordDateLayout(dateFormat)), nil
+	case fmt.Stringer:
+		return t.String(), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	case reflect.String:
+		return rv.String(), nil
+	}
+	return "", fmt.Errorf("text needs a string, number or time, got %T", v)
+}
+
+// wordDateLayout converts a Word date and time picture such as "dd.MM.yyyy"
+// or "MMMM d, yyyy h:mm am/pm" to a Go time layout.
+func wordDateLayout(format string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(format); {
+		if strings.HasPrefix(strings.ToLower(format[i:]), "am/pm") {
+			if format[i] == 'a' {
+				sb.WriteString("pm")
+			} else {
+				sb.WriteString("PM")
+			}
+			i += 5
+			continue
+		}
+		c := format[i]
+		n := 1
+		for i+n < len(format) && format[i+n] == c {
+			n++
+		}
+		i += n
+		layouts := map[byte][]string{
+			'y': {"06", "06", "2006"},
+			'M': {"1", "01", "Jan", "January"},
+			'd': {"2", "02", "Mon", "Monday"},
+			'H': {"15", "15"},
+			'h': {"3", "03"},
+			'm': {"4", "04"},
+			's': {"5", "05"},
+		}
+		l, ok := layouts[c]
+		if !ok {
+			sb.WriteString(strings.Repeat(string(c), n))
+			continue
+		}
+		if n > len(l) {
+			n = len(l)
+		}
+		sb.WriteString(l[n-1])
+	}
+	return sb.String()
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
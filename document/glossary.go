@@ -0,0 +1,621 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+const (
+	glossaryType        = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/glossaryDocument"
+	glossaryContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document.glossary+xml"
+	documentContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"
+)
+
+// BuildingBlockGallery is the gallery a building block is listed in, the
+// values are those of the gallery element of the glossary document.
+type BuildingBlockGallery string
+
+// BuildingBlockGallery constants.
+const (
+	GalleryQuickParts       BuildingBlockGallery = "docParts"
+	GalleryAutoText         BuildingBlockGallery = "autoTxt"
+	GalleryCustomQuickParts BuildingBlockGallery = "custQuickParts"
+	GalleryCoverPages       BuildingBlockGallery = "coverPg"
+	GalleryHeaders          BuildingBlockGallery = "hdrs"
+	GalleryFooters          BuildingBlockGallery = "ftrs"
+	GalleryPageNumbers      BuildingBlockGallery = "pgNum"
+	GalleryTables           BuildingBlockGallery = "tbls"
+	GalleryTextBoxes        BuildingBlockGallery = "txtBox"
+	GalleryEquations        BuildingBlockGallery = "eq"
+	GalleryWatermarks       BuildingBlockGallery = "watermarks"
+	GalleryTableOfContents  BuildingBlockGallery = "tblOfContents"
+	GalleryBibliographies   BuildingBlockGallery = "bib"
+	// GalleryPlaceholder holds the placeholder text of content controls.
+	GalleryPlaceholder BuildingBlockGallery = "placeholder"
+)
+
+// BuildingBlock is an entry of the glossary document of a document or
+// template, shown by Word as Quick Parts, AutoText and the other building
+// block galleries.
+type BuildingBlock struct {
+	Name        string
+	Gallery     BuildingBlockGallery
+	Category    string
+	Description string
+	// GUID identifies the building block, one is generated when it is added
+	// if not set.
+	GUID string
+
+	// body is the content of the docPartBody element
+	body []byte
+}
+
+// glossaryPart is the parsed glossary document part.
+type glossaryPart struct {
+	// root holds the attributes of the root element with their prefixes
+	root  []xml.Attr
+	parts []*glossaryEntry
+}
+
+// glossaryEntry is a building block of the glossary with the XML content of
+// its docPart element, which is written back unchanged.
+type glossaryEntry struct {
+	block BuildingBlock
+	raw   []byte
+}
+
+type valElement struct {
+	Val string `xml:"val,attr"`
+}
+
+type docPartElement struct {
+	Raw []byte `xml:",innerxml"`
+	Pr  struct {
+		Name     valElement `xml:"name"`
+		Category struct {
+			Name    valElement `xml:"name"`
+			Gallery valElement `xml:"gallery"`
+		} `xml:"category"`
+		Description valElement `xml:"description"`
+		GUID        valElement `xml:"guid"`
+	} `xml:"docPartPr"`
+	Body struct {
+		Inner []byte `xml:",innerxml"`
+	} `xml:"docPartBody"`
+}
+
+// glossaryNamespaces are declared on the root of the glossary so that the
+// content of added building blocks can use their prefixes.
+var glossaryNamespaces = [][2]string{
+	{"w", "http://schemas.openxmlformats.org/wordprocessingml/2006/main"},
+	{"r", "http://schemas.openxmlformats.org/officeDocument/2006/relationships"},
+	{"wp", "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"},
+	{"a", "http://schemas.openxmlformats.org/drawingml/2006/main"},
+	{"pic", "http://schemas.openxmlformats.org/drawingml/2006/picture"},
+	{"m", "http://schemas.openxmlformats.org/officeDocument/2006/math"},
+	{"v", "urn:schemas-microsoft-com:vml"},
+	{"o", "urn:schemas-microsoft-com:office:office"},
+	{"w10", "urn:schemas-microsoft-com:office:word"},
+	{"mc", "http://schemas.openxmlformats.org/markup-compatibility/2006"},
+	{"w14", "http://schemas.microsoft.com/office/word/2010/wordml"},
+	{"wp14", "http://schemas.microsoft.com/office/word/2010/wordprocessingDrawing"},
+	{"wps", "http://schemas.microsoft.com/office/word/2010/wordprocessingShape"},
+	{"wpg", "http://schemas.microsoft.com/office/word/2010/wordprocessingGroup"},
+	{"wne", "http://schemas.microsoft.com/office/word/2006/wordml"},
+}
+
+// HasGlossary returns true if the document has a glossary document part
+// holding building blocks.
+func (d *Document) HasGlossary() bool { return d.glossaryPath() != "" }
+
+// BuildingBlocks returns the building blocks of the glossary document.
+func (d *Document) BuildingBlocks() ([]BuildingBlock, error) {
+	return d.FindBuildingBlocks("", "")
+}
+
+// FindBuildingBlocks returns the building blocks of the glossary document in
+// the gallery and category, an empty gallery or category matches all.
+func (d *Document) FindBuildingBlocks(gallery BuildingBlockGallery, category string) ([]BuildingBlock, error) {
+	g, err := d.glossary()
+	if err != nil || g == nil {
+		return nil, err
+	}
+	res := []BuildingBlock{}
+	for _, e := range g.parts {
+		if (gallery == "" || e.block.Gallery == gallery) && (category == "" || e.block.Category == category) {
+			res = append(res, e.block)
+		}
+	}
+	return res, nil
+}
+
+// InsertBuildingBlockAfter inserts the content of a building block of the
+// glossary document after the paragraph relativeTo of the document body. The
+// styles, numbering, images and hyperlinks used by the content are carried
+// over.
+func (d *Document) InsertBuildingBlockAfter(relativeTo Paragraph, bb BuildingBlock) error {
+	return d.insertBuildingBlock(relativeTo, bb, false)
+}
+
+// InsertBuildingBlockBefore inserts the content of a building block of the
+// glossary document before the paragraph relativeTo of the document body.
+func (d *Document) InsertBuildingBlockBefore(relativeTo Paragraph, bb BuildingBlock) error {
+	return d.insertBuildingBlock(relativeTo, bb, true)
+}
+
+func (d *Document) insertBuildingBlock(relativeTo Paragraph, bb BuildingBlock, before bool) error {
+	g, err := d.glossary()
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		return errors.New("document has no glossary")
+	}
+	src, err := d.openGlossary(g, bb.body)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	blocks := src.X().Body.EG_BlockLevelElts
+	if err := d.importBlocks(src, blocks); err != nil {
+		return err
+	}
+	c, i := findParagraphUnit(newBlockContainer(&d.X().Body.EG_BlockLevelElts), relativeTo.X())
+	if c == nil {
+		return errors.New("paragraph not found in the document body")
+	}
+	if !before {
+		i++
+	}
+	c.units = concatUnits(c.units[:i], blocks, c.units[i:])
+	c.store(c.units)
+	return nil
+}
+
+// AddBuildingBlock adds the body of src to the glossary document as a
+// building block, replacing a building block with the same name, gallery and
+// category. The glossary is created if the document has none, the usual
+// target is a template opened with OpenTemplate. The styles, images and
+// hyperlinks used by the content are carried over, numbering only if the
+// glossary has numbering definitions.
+func (d *Document) AddBuildingBlock(bb BuildingBlock, src *Document) error {
+	if bb.Name == "" {
+		return errors.New("building block has no name")
+	}
+	if bb.Gallery == "" {
+		bb.Gallery = GalleryQuickParts
+	}
+	if bb.Category == "" {
+		bb.Category = "General"
+	}
+	if bb.GUID == "" {
+		bb.GUID = newGUID()
+	}
+	g, err := d.glossary()
+	if err != nil {
+		return err
+	}
+	if g == nil {
+		g = &glossaryPart{}
+	}
+	gd, err := d.openGlossary(g, nil)
+	if err != nil {
+		return err
+	}
+	defer gd.Close()
+	cp, err := src.Copy()
+	if err != nil {
+		return err
+	}
+	defer cp.Close()
+	blocks := cp.X().Body.EG_BlockLevelElts
+	if err := gd.importBlocks(cp, blocks); err != nil {
+		return err
+	}
+	body := wml.NewCT_Body()
+	body.EG_BlockLevelElts = blocks
+	raw, err := docPartXML(bb, body)
+	if err != nil {
+		return err
+	}
+	parts := g.parts[:0]
+	for _, e := range g.parts {
+		if e.block.Name != bb.Name || e.block.Gallery != bb.Gallery || e.block.Category != bb.Category {
+			parts = append(parts, e)
+		}
+	}
+	g.parts = append(parts, &glossaryEntry{bb, raw})
+	return d.storeGlossary(g, gd)
+}
+
+// glossaryPath returns the package path of the glossary document part, or
+// "" if there is none.
+func (d *Document) glossaryPath() string {
+	for _, rel := range d._fgg.X().Relationship {
+		if rel.TypeAttr == glossaryType {
+			if strings.HasPrefix(rel.TargetAttr, "/") {
+				return strings.TrimPrefix(rel.TargetAttr, "/")
+			}
+			return path.Join("word", rel.TargetAttr)
+		}
+	}
+	return ""
+}
+
+// glossary reads the glossary document part, which is kept as an extra file
+// of the package, it returns nil if there is none.
+func (d *Document) glossary() (*glossaryPart, error) {
+	p := d.glossaryPath()
+	if p == "" {
+		return nil, nil
+	}
+	data, err := d.ExtraFileBytes(p)
+	if err != nil {
+		return nil, err
+	}
+	g := &glossaryPart{}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for g.root == nil {
+		tok, err := dec.RawToken()
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			g.root = append([]xml.Attr{}, se.Attr...)
+		}
+	}
+	var doc struct {
+		DocParts []docPartElement `xml:"docParts>docPart"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	for _, dp := range doc.DocParts {
+		bb := BuildingBlock{Name: dp.Pr.Name.Val, Gallery: BuildingBlockGallery(dp.Pr.Category.Gallery.Val),
+			Category: dp.Pr.Category.Name.Val, Description: dp.Pr.Description.Val, GUID: dp.Pr.GUID.Val, body: dp.Body.Inner}
+		g.parts = append(g.parts, &glossaryEntry{bb, dp.Raw})
+	}
+	return g, nil
+}
+
+// rootAttrs returns the attributes of the glossary root, adding the
+// declarations of the prefixes used by generated content.
+func (g *glossaryPart) rootAttrs() []xml.Attr {
+	attrs := append([]xml.Attr{}, g.root...)
+	for _, ns := range glossaryNamespaces {
+		declared := false
+		for _, a := range attrs {
+			if a.Name.Space == "xmlns" && a.Name.Local == ns[0] {
+				declared = true
+				break
+			}
+		}
+		if !declared {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "xmlns", Local: ns[0]}, Value: ns[1]})
+		}
+	}
+	return attrs
+}
+
+func writeRawAttrs(b *bytes.Buffer, attrs []xml.Attr) {
+	for _, a := range attrs {
+		b.WriteByte(' ')
+		if a.Name.Space != "" {
+			b.WriteString(a.Name.Space + ":")
+		}
+		b.WriteString(a.Name.Local + `="`)
+		xml.EscapeText(b, []byte(a.Value))
+		b.WriteByte('"')
+	}
+}
+
+func (g *glossaryPart) bytes() []byte {
+	b := bytes.Buffer{}
+	b.WriteString(xml.Header)
+	b.WriteString("<w:glossaryDocument")
+	writeRawAttrs(&b, g.rootAttrs())
+	b.WriteString("><w:docParts>")
+	for _, e := range g.parts {
+		b.WriteString("<w:docPart>")
+		b.Write(e.raw)
+		b.WriteString("</w:docPart>")
+	}
+	b.WriteString("</w:docParts></w:glossaryDocument>")
+	return b.Bytes()
+}
+
+// docPartXML returns the content of the docPart element of a building block.
+func docPartXML(bb BuildingBlock, body *wml.CT_Body) ([]byte, error) {
+	b := bytes.Buffer{}
+	val := func(name, v string) {
+		b.WriteString("<w:" + name + ` w:val="`)
+		xml.EscapeText(&b, []byte(v))
+		b.WriteString(`"/>`)
+	}
+	b.WriteString("<w:docPartPr>")
+	val("name", bb.Name)
+	b.WriteString("<w:category>")
+	val("name", bb.Category)
+	val("gallery", string(bb.Gallery))
+	b.WriteString("</w:category><w:behaviors>")
+	val("behavior", "content")
+	b.WriteString("</w:behaviors>")
+	if bb.Description != "" {
+		val("description", bb.Description)
+	}
+	val("guid", bb.GUID)
+	b.WriteString("</w:docPartPr>")
+	enc := xml.NewEncoder(&b)
+	if err := enc.EncodeElement(body, xml.StartElement{Name: xml.Name{Local: "w:docPartBody"}}); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// glossaryFiles returns the files of the glossary part other than the
+// glossary document itself, keyed by their path in a package where the
+// glossary is the main document part.
+func (d *Document) glossaryFiles() (map[string][]byte, error) {
+	files := map[string][]byte{}
+	gp := d.glossaryPath()
+	if gp == "" {
+		return files, nil
+	}
+	dir := path.Dir(gp) + "/"
+	rels := dir + "_rels/" + path.Base(gp) + ".rels"
+	for _, ef := range d.ExtraFiles {
+		if !strings.HasPrefix(ef.ZipPath, dir) || ef.ZipPath == gp {
+			continue
+		}
+		data, err := d.ExtraFileBytes(ef.ZipPath)
+		if err != nil {
+			return nil, err
+		}
+		name := "word/" + strings.TrimPrefix(ef.ZipPath, dir)
+		if ef.ZipPath == rels {
+			name = "word/_rels/document.xml.rels"
+		}
+		files[name] = data
+	}
+	return files, nil
+}
+
+// openGlossary returns the glossary part as a document of its own with the
+// given body content, sharing the styles, numbering and relationships of the
+// glossary. A new glossary starts with the styles and numbering of d.
+func (d *Document) openGlossary(g *glossaryPart, body []byte) (*Document, error) {
+	files, err := d.glossaryFiles()
+	if err != nil {
+		return nil, err
+	}
+	overrides := map[string]string{"/word/document.xml": documentContentType}
+	if d.glossaryPath() != "" {
+		dir := "/" + path.Dir(d.glossaryPath()) + "/"
+		for _, tc := range d.ContentTypes.X().TypesChoice {
+			if o := tc.Override; o != nil && strings.HasPrefix(o.PartNameAttr, dir) {
+				overrides["/word/"+strings.TrimPrefix(o.PartNameAttr, dir)] = o.ContentTypeAttr
+			}
+		}
+	} else {
+		rels := bytes.Buffer{}
+		rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+		if st := d.Styles.X(); st != nil {
+			data, err := xml.Marshal(st)
+			if err != nil {
+				return nil, err
+			}
+			files["word/styles.xml"] = data
+			overrides["/word/styles.xml"] = "application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"
+			fmt.Fprintf(&rels, `<Relationship Id="rId1" Type="%s" Target="styles.xml"/>`, unioffice.StylesType)
+		}
+		if num := d.Numbering.X(); num != nil {
+			data, err := xml.Marshal(num)
+			if err != nil {
+				return nil, err
+			}
+			files["word/numbering.xml"] = data
+			overrides["/word/numbering.xml"] = "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"
+			fmt.Fprintf(&rels, `<Relationship Id="rId2" Type="%s" Target="numbering.xml"/>`, unioffice.NumberingType)
+		}
+		rels.WriteString("</Relationships>")
+		files["word/_rels/document.xml.rels"] = rels.Bytes()
+	}
+
+	doc := bytes.Buffer{}
+	doc.WriteString(xml.Header + "<w:document")
+	writeRawAttrs(&doc, g.rootAttrs())
+	doc.WriteString("><w:body>")
+	doc.Write(body)
+	doc.WriteString("</w:body></w:document>")
+	files["word/document.xml"] = doc.Bytes()
+	files["_rels/.rels"] = []byte(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + unioffice.OfficeDocumentType + `" Target="word/document.xml"/></Relationships>`)
+
+	types := bytes.Buffer{}
+	types.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	for _, tc := range d.ContentTypes.X().TypesChoice {
+		if df := tc.Default; df != nil {
+			fmt.Fprintf(&types, `<Default Extension="%s" ContentType="%s"/>`, df.ExtensionAttr, df.ContentTypeAttr)
+		}
+	}
+	for name, ct := range overrides {
+		fmt.Fprintf(&types, `<Override PartName="%s" ContentType="%s"/>`, name, ct)
+	}
+	types.WriteString("</Types>")
+	files["[Content_Types].xml"] = types.Bytes()
+
+	buf := bytes.Buffer{}
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
+
+// storeGlossary writes the glossary g back to the package of d, with the
+// parts other than the glossary document taken from the document gd opened
+// by openGlossary.
+func (d *Document) storeGlossary(g *glossaryPart, gd *Document) error {
+	gp := d.glossaryPath()
+	if gp == "" {
+		gp = "word/glossary/document.xml"
+		d._fgg.AddRelationship("glossary/document.xml", glossaryType)
+		d.ContentTypes.AddOverride("/"+gp, glossaryContentType)
+	}
+	dir := path.Dir(gp) + "/"
+	buf := bytes.Buffer{}
+	if err := gd.Save(&buf); err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		return err
+	}
+	// the parts of the glossary are written again, images may be renamed
+	kept := d.ExtraFiles[:0]
+	for _, ef := range d.ExtraFiles {
+		if !strings.HasPrefix(ef.ZipPath, dir) {
+			kept = append(kept, ef)
+		}
+	}
+	d.ExtraFiles = kept
+	for _, f := range zr.File {
+		var name string
+		switch {
+		case f.Name == "[Content_Types].xml":
+			if err := d.mergeGlossaryTypes(f, dir); err != nil {
+				return err
+			}
+			continue
+		case f.Name == "word/document.xml":
+			continue
+		case f.Name == "word/_rels/document.xml.rels":
+			name = dir + "_rels/" + path.Base(gp) + ".rels"
+		case strings.HasPrefix(f.Name, "word/"):
+			name = dir + strings.TrimPrefix(f.Name, "word/")
+		default:
+			continue
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			return err
+		}
+		if err := d.AddExtraFileFromBytes(name, data); err != nil {
+			return err
+		}
+	}
+	return d.AddExtraFileFromBytes(gp, g.bytes())
+}
+
+// mergeGlossaryTypes adds the content types of the parts of a saved glossary
+// document to the content types of d.
+func (d *Document) mergeGlossaryTypes(f *zip.File, dir string) error {
+	data, err := readZipFile(f)
+	if err != nil {
+		return err
+	}
+	var types struct {
+		Defaults []struct {
+			Extension   string `xml:"Extension,attr"`
+			ContentType string `xml:"ContentType,attr"`
+		} `xml:"Default"`
+		Overrides []struct {
+			PartName    string `xml:"PartName,attr"`
+			ContentType string `xml:"ContentType,attr"`
+		} `xml:"Override"`
+	}
+	if err := xml.Unmarshal(data, &types); err != nil {
+		return err
+	}
+	for _, df := range types.Defaults {
+		d.ContentTypes.EnsureDefault(df.Extension, df.ContentType)
+	}
+	for _, o := range types.Overrides {
+		if strings.HasPrefix(o.PartName, "/word/") && o.PartName != "/word/document.xml" {
+			d.ContentTypes.EnsureOverride("/"+dir+strings.TrimPrefix(o.PartName, "/word/"), o.ContentType)
+		}
+	}
+	return nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// findParagraphUnit returns the container holding the paragraph p within bc
+// and its tables and content controls, and the index of the unit holding it.
+func findParagraphUnit(bc *blockContainer, p *wml.CT_P) (*blockContainer, int) {
+	for i := range bc.units {
+		for _, c := range bc.units[i].BlockLevelEltsChoice.EG_ContentBlockContent {
+			cc := c.ContentBlockContentChoice
+			for _, q := range cc.P {
+				if q == p {
+					return bc, i
+				}
+			}
+			if sdt := cc.Sdt; sdt != nil && sdt.SdtContent != nil {
+				if fc, fi := findParagraphUnit(newContentBlockContainer(&sdt.SdtContent.EG_ContentBlockContent), p); fc != nil {
+					return fc, fi
+				}
+			}
+			var found *blockContainer
+			idx := -1
+			for _, tbl := range cc.Tbl {
+				walkTableCells(tbl, func(tc *wml.CT_Tc) {
+					if found == nil {
+						found, idx = findParagraphUnit(newBlockContainer(&tc.EG_BlockLevelElts), p)
+					}
+				})
+			}
+			if found != nil {
+				return found, idx
+			}
+		}
+	}
+	return nil, -1
+}
+
+// newGUID returns a random GUID in registry format.
+func newGUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("{%X-%X-%X-%X-%X}", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
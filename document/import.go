@@ -0,0 +1,453 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml/picture"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// importBlocks prepares blocks of the document src for being inserted into d.
+// The images and hyperlinks, styles, numbering definitions, footnotes and
+// endnotes the blocks refer to are copied into d and the references in the
+// blocks are updated. src is modified and should be a copy owned by the
+// caller. Relationships of notes, charts and embedded objects are not carried
+// over.
+func (d *Document) importBlocks(src *Document, blocks []*wml.EG_BlockLevelElts) error {
+	rels, err := d.importRelationships(src)
+	if err != nil {
+		return err
+	}
+	footnotes, endnotes := d.importNotes(src, blocks)
+	numIDs := d.importNumbering(src, blocks)
+	d.importStyles(src, blocks)
+	remap := func(id *string) {
+		if id == nil {
+			return
+		}
+		if to, ok := rels[*id]; ok {
+			*id = to
+		}
+	}
+	walkDrawings(blocks, func(_ *wml.WdInline, _ *wml.WdAnchor, pic *picture.Pic) {
+		if pic.BlipFill != nil && pic.BlipFill.Blip != nil {
+			remap(pic.BlipFill.Blip.EmbedAttr)
+		}
+	})
+	walkBlockParagraphs(blocks, func(p *wml.CT_P) {
+		walkContentHyperlinks(p.EG_PContent, func(hl *wml.CT_Hyperlink) {
+			remap(hl.IdAttr)
+		})
+		walkParagraphRuns(p, func(r *wml.CT_R) {
+			for _, ric := range r.EG_RunInnerContent {
+				if ref := ric.RunInnerContentChoice.FootnoteReference; ref != nil {
+					if id, ok := footnotes[ref.IdAttr]; ok {
+						ref.IdAttr = id
+					}
+				}
+				if ref := ric.RunInnerContentChoice.EndnoteReference; ref != nil {
+					if id, ok := endnotes[ref.IdAttr]; ok {
+						ref.IdAttr = id
+					}
+				}
+			}
+		})
+		if p.PPr != nil {
+			// sections of src must not split the sections of d
+			p.PPr.SectPr = nil
+			if np := p.PPr.NumPr; np != nil && np.NumId != nil {
+				if id, ok := numIDs[np.NumId.ValAttr]; ok {
+					np.NumId.ValAttr = id
+				} else {
+					p.PPr.NumPr = nil
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// importRelationships adds the images and hyperlinks of src to d and returns
+// the mapping of the relationship IDs of src to the IDs in d.
+func (d *Document) importRelationships(src *Document) (map[string]string, error) {
+	ids := map[string]string{}
+	for _, rel := range src._fgg.X().Relationship {
+		switch rel.TypeAttr {
+		case unioffice.ImageType:
+			target := "word/" + rel.TargetAttr
+			for _, img := range src.Images {
+				if img.Target() != target {
+					continue
+				}
+				i, err := common.ImageFromStorage(img.Path())
+				if err != nil {
+					return nil, err
+				}
+				ref, err := d.AddImage(i)
+				if err != nil {
+					return nil, err
+				}
+				ids[rel.IdAttr] = ref.RelID()
+				break
+			}
+		case unioffice.HyperLinkType:
+			hl := d.AddHyperlink(rel.TargetAttr)
+			ids[rel.IdAttr] = common.Relationship(hl).ID()
+		}
+	}
+	return ids, nil
+}
+
+// importNotes copies the footnotes and endnotes referenced from blocks into d
+// and returns the mappings of their IDs.
+func (d *Document) importNotes(src *Document, blocks []*wml.EG_BlockLevelElts) (map[int64]int64, map[int64]int64) {
+	footnotes := map[int64]int64{}
+	endnotes := map[int64]int64{}
+	walkBlockParagraphs(blocks, func(p *wml.CT_P) {
+		walkParagraphRuns(p, func(r *wml.CT_R) {
+			for _, ric := range r.EG_RunInnerContent {
+				if ref := ric.RunInnerContentChoice.FootnoteReference; ref != nil {
+					footnotes[ref.IdAttr] = 0
+				}
+				if ref := ric.RunInnerContentChoice.EndnoteReference; ref != nil {
+					endnotes[ref.IdAttr] = 0
+				}
+			}
+		})
+	})
+	if len(footnotes) > 0 && src.HasFootnotes() {
+		if !d.HasFootnotes() {
+			d.addFootnotes()
+		}
+		next := int64(1)
+		for _, fn := range d._bac.Footnote {
+			if fn.IdAttr >= next {
+				next = fn.IdAttr + 1
+			}
+		}
+		for _, fn := range src._bac.Footnote {
+			if _, ok := footnotes[fn.IdAttr]; ok && isNoteContent(fn) {
+				footnotes[fn.IdAttr] = next
+				fn.IdAttr = next
+				d._bac.Footnote = append(d._bac.Footnote, fn)
+				next++
+			}
+		}
+	}
+	if len(endnotes) > 0 && src.HasEndnotes() {
+		if !d.HasEndnotes() {
+			d.addEndnotes()
+		}
+		next := int64(1)
+		for _, en := range d._dgde.Endnote {
+			if en.IdAttr >= next {
+				next = en.IdAttr + 1
+			}
+		}
+		for _, en := range src._dgde.Endnote {
+			if _, ok := endnotes[en.IdAttr]; ok && isNoteContent(en) {
+				endnotes[en.IdAttr] = next
+				en.IdAttr = next
+				d._dgde.Endnote = append(d._dgde.Endnote, en)
+				next++
+			}
+		}
+	}
+	return footnotes, endnotes
+}
+
+// importNumbering copies the numbering definitions used by the paragraphs of
+// blocks into d and returns the mapping of the numbering IDs. Nothing is
+// copied if d has no numbering part.
+func (d *Document) importNumbering(src *Document, blocks []*wml.EG_BlockLevelElts) map[int64]int64 {
+	ids := map[int64]int64{}
+	dst, from := d.Numbering.X(), src.Numbering.X()
+	if dst == nil || from == nil {
+		return ids
+	}
+	nextNum, nextAbstract := int64(1), int64(0)
+	for _, n := range dst.Num {
+		if n.NumIdAttr >= nextNum {
+			nextNum = n.NumIdAttr + 1
+		}
+	}
+	for _, an := range dst.AbstractNum {
+		if an.AbstractNumIdAttr >= nextAbstract {
+			nextAbstract = an.AbstractNumIdAttr + 1
+		}
+	}
+	abstractIDs := map[int64]int64{}
+	walkBlockParagraphs(blocks, func(p *wml.CT_P) {
+		if p.PPr == nil || p.PPr.NumPr == nil || p.PPr.NumPr.NumId == nil {
+			return
+		}
+		id := p.PPr.NumPr.NumId.ValAttr
+		if _, ok := ids[id]; ok || id == 0 {
+			return
+		}
+		for _, n := range from.Num {
+			if n.NumIdAttr != id || n.AbstractNumId == nil {
+				continue
+			}
+			abstractID, ok := abstractIDs[n.AbstractNumId.ValAttr]
+			if !ok {
+				for _, an := range from.AbstractNum {
+					if an.AbstractNumIdAttr == n.AbstractNumId.ValAttr {
+						abstractID = nextAbstract
+						nextAbstract++
+						abstractIDs[an.AbstractNumIdAttr] = abstractID
+						an.AbstractNumIdAttr = abstractID
+						dst.AbstractNum = append(dst.AbstractNum, an)
+						ok = true
+						break
+					}
+				}
+			}
+			if !ok {
+				break
+			}
+			ids[id] = nextNum
+			n.NumIdAttr = nextNum
+			n.AbstractNumId.ValAttr = abstractID
+			dst.Num = append(dst.Num, n)
+			nextNum++
+			break
+		}
+	})
+	return ids
+}
+
+// importStyles copies the styles used by blocks that d does not define, along
+// with the styles they are based on, from src into d.
+func (d *Document) importStyles(src *Document, blocks []*wml.EG_BlockLevelElts) {
+	if d.Styles.X() == nil || src.Styles.X() == nil {
+		return
+	}
+	used := []string{}
+	use := func(s *wml.CT_String) {
+		if s != nil {
+			used = append(used, s.ValAttr)
+		}
+	}
+	walkBlockParagraphs(blocks, func(p *wml.CT_P) {
+		if p.PPr != nil {
+			use(p.PPr.PStyle)
+		}
+		walkParagraphRuns(p, func(r *wml.CT_R) {
+			if r.RPr != nil {
+				use(r.RPr.RStyle)
+			}
+		})
+	})
+	walkBlockTables(blocks, func(t *wml.CT_Tbl) {
+		if t.TblPr != nil {
+			use(t.TblPr.TblStyle)
+		}
+	})
+	for len(used) > 0 {
+		id := used[len(used)-1]
+		used = used[:len(used)-1]
+		if d.GetStyleByID(id).X() != nil {
+			continue
+		}
+		s := src.GetStyleByID(id).X()
+		if s == nil {
+			continue
+		}
+		d.Styles.X().Style = append(d.Styles.X().Style, s)
+		use(s.BasedOn)
+		use(s.Next)
+		use(s.Link)
+	}
+}
+
+// walkBlockParagraphs calls fn for every paragraph of blocks, including the
+// paragraphs of tables and content controls.
+func walkBlockParagraphs(blocks []*wml.EG_BlockLevelElts, fn func(p *wml.CT_P)) {
+	for _, ble := range blocks {
+		walkContentParagraphs(ble.BlockLevelEltsChoice.EG_ContentBlockContent, fn)
+	}
+}
+
+func walkContentParagraphs(cbcs []*wml.EG_ContentBlockContent, fn func(p *wml.CT_P)) {
+	for _, c := range cbcs {
+		if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			walkContentParagraphs(sdt.SdtContent.EG_ContentBlockContent, fn)
+		}
+		for _, p := range c.ContentBlockContentChoice.P {
+			fn(p)
+		}
+		for _, tbl := range c.ContentBlockContentChoice.Tbl {
+			walkTableCells(tbl, func(tc *wml.CT_Tc) {
+				walkBlockParagraphs(tc.EG_BlockLevelElts, fn)
+			})
+		}
+	}
+}
+
+// walkBlockTables calls fn for every table of blocks, including nested tables.
+func walkBlockTables(blocks []*wml.EG_BlockLevelElts, fn func(t *wml.CT_Tbl)) {
+	var walk func(cbcs []*wml.EG_ContentBlockContent)
+	walk = func(cbcs []*wml.EG_ContentBlockContent) {
+		for _, c := range cbcs {
+			if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+				walk(sdt.SdtContent.EG_ContentBlockContent)
+			}
+			for _, tbl := range c.ContentBlockContentChoice.Tbl {
+				fn(tbl)
+				walkTableCells(tbl, func(tc *wml.CT_Tc) {
+					for _, ble := range tc.EG_BlockLevelElts {
+						walk(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+					}
+				})
+			}
+		}
+	}
+	for _, ble := range blocks {
+		walk(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+	}
+}
+
+// walkTableCells calls fn for every cell of tbl, not including the cells of
+// nested tables.
+func walkTableCells(tbl *wml.CT_Tbl, fn func(tc *wml.CT_Tc)) {
+	walkTableRows(tbl, func(tr *wml.CT_Row) {
+		walkRowCells(tr.EG_ContentCellContent, fn)
+	})
+}
+
+// walkTableRows calls fn for every row of tbl, including the rows of content
+// controls but not those of nested tables.
+func walkTableRows(tbl *wml.CT_Tbl, fn func(tr *wml.CT_Row)) {
+	var rows func(rcs []*wml.EG_ContentRowContent)
+	rows = func(rcs []*wml.EG_ContentRowContent) {
+		for _, rc := range rcs {
+			if sdt := rc.ContentRowContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+				rows(sdt.SdtContent.EG_ContentRowContent)
+			}
+			for _, tr := range rc.ContentRowContentChoice.Tr {
+				fn(tr)
+			}
+		}
+	}
+	rows(tbl.EG_ContentRowContent)
+}
+
+func walkRowCells(cccs []*wml.EG_ContentCellContent, fn func(tc *wml.CT_Tc)) {
+	for _, ccc := range cccs {
+		if sdt := ccc.ContentCellContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			walkRowCells(sdt.SdtContent.EG_ContentCellContent, fn)
+		}
+		for _, tc := range ccc.ContentCellContentChoice.Tc {
+			fn(tc)
+		}
+	}
+}
+
+// walkParagraphRuns calls fn for every run of p, including the runs of
+// hyperlinks, simple fields, content controls and tracked changes. Moved text
+// is included at both its source and its destination, like deleted and
+// inserted text.
+func walkParagraphRuns(p *wml.CT_P, fn func(r *wml.CT_R)) {
+	walkContentRuns(p.EG_PContent, fn)
+}
+
+func walkContentRuns(pcs []*wml.EG_PContent, fn func(r *wml.CT_R)) {
+	for _, pc := range pcs {
+		walkRunContent(pc.PContentChoice.EG_ContentRunContent, fn)
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			walkRunContent(hl.PContentChoice.EG_ContentRunContent, fn)
+		}
+		for _, fs := range pc.PContentChoice.FldSimple {
+			walkContentRuns(fs.EG_PContent, fn)
+		}
+	}
+}
+
+func walkRunContent(crcs []*wml.EG_ContentRunContent, fn func(r *wml.CT_R)) {
+	for _, crc := range crcs {
+		walkRunContentChoice(crc.ContentRunContentChoice, fn)
+	}
+}
+
+func walkRunContentChoice(c *wml.EG_ContentRunContentChoice, fn func(r *wml.CT_R)) {
+	if c == nil {
+		return
+	}
+	if c.R != nil {
+		fn(c.R)
+	}
+	if sdt := c.Sdt; sdt != nil && sdt.SdtContent != nil {
+		walkContentRuns(sdt.SdtContent.EG_PContent, fn)
+	}
+	for _, rle := range c.EG_RunLevelElts {
+		for _, tc := range trackChanges(rle) {
+			walkRunContentChoice(tc.ContentRunContentChoice, fn)
+		}
+	}
+}
+
+// walkContentHyperlinks calls fn for every hyperlink of pcs, including the
+// hyperlinks of simple fields and content controls.
+func walkContentHyperlinks(pcs []*wml.EG_PContent, fn func(hl *wml.CT_Hyperlink)) {
+	for _, pc := range pcs {
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			fn(hl)
+		}
+		for _, fs := range pc.PContentChoice.FldSimple {
+			walkContentHyperlinks(fs.EG_PContent, fn)
+		}
+		for _, crc := range pc.PContentChoice.EG_ContentRunContent {
+			if c := crc.ContentRunContentChoice; c != nil && c.Sdt != nil && c.Sdt.SdtContent != nil {
+				walkContentHyperlinks(c.Sdt.SdtContent.EG_PContent, fn)
+			}
+		}
+	}
+}
+
+// walkSimpleFields calls fn for every simple field of pcs, including the
+// simple fields of hyperlinks, content controls and other simple fields.
+func walkSimpleFields(pcs []*wml.EG_PContent, fn func(fs *wml.CT_SimpleField)) {
+	fields := func(fss []*wml.CT_SimpleField) {
+		for _, fs := range fss {
+			fn(fs)
+			walkSimpleFields(fs.EG_PContent, fn)
+		}
+	}
+	controls := func(crcs []*wml.EG_ContentRunContent) {
+		for _, crc := range crcs {
+			if c := crc.ContentRunContentChoice; c != nil && c.Sdt != nil && c.Sdt.SdtContent != nil {
+				walkSimpleFields(c.Sdt.SdtContent.EG_PContent, fn)
+			}
+		}
+	}
+	for _, pc := range pcs {
+		fields(pc.PContentChoice.FldSimple)
+		controls(pc.PContentChoice.EG_ContentRunContent)
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			fields(hl.PContentChoice.FldSimple)
+			controls(hl.PContentChoice.EG_ContentRunContent)
+		}
+	}
+}
+
+// trackChanges returns the tracked insertions, deletions and moves of rle.
+func trackChanges(rle *wml.EG_RunLevelElts) []*wml.CT_RunTrackChangeChoice {
+	var tcs []*wml.CT_RunTrackChangeChoice
+	c := rle.RunLevelEltsChoice
+	for _, rtc := range []*wml.CT_RunTrackChange{c.Ins, c.Del, c.MoveFrom, c.MoveTo} {
+		if rtc != nil {
+			tcs = append(tcs, rtc.RunTrackChangeChoice...)
+		}
+	}
+	return tcs
+}
@@ -0,0 +1,71 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+func TestImportBlocksFootnoteInHyperlink(t *testing.T) {
+	dst := New()
+	dst.AddParagraph().AddFootnote("existing")
+
+	src := New()
+	src.AddParagraph().AddFootnote("other")
+	p := src.AddParagraph()
+	p.AddFootnote("imported")
+	// move the footnote reference into a hyperlink
+	pcs := p.X().EG_PContent
+	hl := p.AddHyperLink()
+	hl.SetTarget("http://example.com")
+	for _, pc := range pcs {
+		hl.X().PContentChoice.EG_ContentRunContent = append(hl.X().PContentChoice.EG_ContentRunContent, pc.PContentChoice.EG_ContentRunContent...)
+	}
+	p.X().EG_PContent = p.X().EG_PContent[len(pcs):]
+
+	blocks := src.X().Body.EG_BlockLevelElts[1:]
+	if err := dst.importBlocks(src, blocks); err != nil {
+		t.Fatalf("importBlocks: %s", err)
+	}
+
+	refs := []int64{}
+	walkBlockParagraphs(blocks, func(p *wml.CT_P) {
+		walkParagraphRuns(p, func(r *wml.CT_R) {
+			for _, ric := range r.EG_RunInnerContent {
+				if ref := ric.RunInnerContentChoice.FootnoteReference; ref != nil {
+					refs = append(refs, ref.IdAttr)
+				}
+			}
+		})
+	})
+	if len(refs) != 1 {
+		t.Fatalf("expected 1 footnote reference, got %d", len(refs))
+	}
+	text := ""
+	for _, fn := range dst.Footnotes() {
+		if fn.X().IdAttr != refs[0] {
+			continue
+		}
+		for _, fp := range fn.Paragraphs() {
+			for _, r := range fp.Runs() {
+				text += r.Text()
+			}
+		}
+	}
+	if !strings.Contains(text, "imported") {
+		t.Errorf("expected the reference %d to point to the imported footnote, got %q", refs[0], text)
+	}
+	if id := hl.X().IdAttr; id == nil || dst.GetTargetByRelId(*id) != "http://example.com" {
+		t.Errorf("expected the hyperlink to be remapped to the relationships of the destination")
+	}
+}
@@ -0,0 +1,667 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/ofc/sharedTypes"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// Index field codes.
+const (
+	FieldIndexEntry = "XE"
+	FieldIndex      = "INDEX"
+)
+
+// Styles of the paragraphs of an index.
+const (
+	StyleIndex1       = "Index1"
+	StyleIndex2       = "Index2"
+	StyleIndexHeading = "IndexHeading"
+)
+
+// IndexEntry is an entry of the index marked by an XE field.
+type IndexEntry struct {
+	// Main is the text of the main entry.
+	Main string
+
+	// Sub is the text of the subentry listed below Main, empty if the field
+	// marks the main entry.
+	Sub string
+
+	// CrossReference is shown in place of the page number, such as
+	// "See Widgets".
+	CrossReference string
+
+	// Bold and Italic format the page number of the entry.
+	Bold, Italic bool
+}
+
+// IndexEntryField is an XE field of the body.
+type IndexEntryField struct {
+	IndexEntry
+
+	// Paragraph is the paragraph holding the field.
+	Paragraph Paragraph
+
+	// Run is the run holding the instruction of the field, its page is the
+	// page number of the entry. Its X is nil for a simple field.
+	Run Run
+}
+
+// IndexOptions are the options of an INDEX field.
+type IndexOptions struct {
+	// Columns is the number of columns of the index, one if zero. An index
+	// with more than one column is placed in its own continuous section.
+	Columns int
+
+	// Headings adds a heading with the first letter before the entries
+	// starting with it.
+	Headings bool
+
+	// Separator is placed between an entry and its page numbers, ", " if
+	// empty.
+	Separator string
+}
+
+// Index is an INDEX field in the body. Its result is the list of the index
+// entries of the document in paragraphs of their own.
+type Index struct {
+	_doc *Document
+	_p   *wml.CT_P
+}
+
+// AddIndexEntry adds an XE field marking an index entry at the run. The field
+// has no result and is not displayed.
+func (r Run) AddIndexEntry(e IndexEntry) {
+	r.addFieldCode(e.instruction())
+}
+
+func (e IndexEntry) instruction() string {
+	text := escapeIndexText(e.Main)
+	if e.Sub != "" {
+		text += ":" + escapeIndexText(e.Sub)
+	}
+	code := " " + FieldIndexEntry + " \"" + text + "\""
+	if e.CrossReference != "" {
+		code += " \\t " + quoteFieldArg(e.CrossReference)
+	}
+	if e.Bold {
+		code += " \\b"
+	}
+	if e.Italic {
+		code += " \\i"
+	}
+	return code + " "
+}
+
+// addFieldCode adds a complex field without a result to the run.
+func (r Run) addFieldCode(code string) {
+	ic := r.newIC()
+	ic.RunInnerContentChoice.FldChar = wml.NewCT_FldChar()
+	ic.RunInnerContentChoice.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeBegin
+	ic = r.newIC()
+	ic.RunInnerContentChoice.InstrText = wml.NewCT_Text()
+	ic.RunInnerContentChoice.InstrText.Content = code
+	ic = r.newIC()
+	ic.RunInnerContentChoice.FldChar = wml.NewCT_FldChar()
+	ic.RunInnerContentChoice.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeEnd
+}
+
+// escapeIndexText escapes the backslashes and quotes of a level of an entry
+// as quoteFieldArg does, and its colons, which would separate the levels, as
+// Word does.
+func escapeIndexText(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", ":", "\\:").Replace(s)
+}
+
+// protectIndexColons replaces the escaped colons of instr with a private use
+// character, leaving escaped backslashes to fieldArgs.
+func protectIndexColons(instr string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(instr); i++ {
+		if instr[i] == '\\' && i+1 < len(instr) {
+			switch instr[i+1] {
+			case ':':
+				sb.WriteRune('\ue000')
+				i++
+				continue
+			case '\\':
+				sb.WriteString("\\\\")
+				i++
+				continue
+			}
+		}
+		sb.WriteByte(instr[i])
+	}
+	return sb.String()
+}
+
+// IndexEntries returns the XE fields of the body, including the fields in
+// tables, hyperlinks and content controls. The complex fields of a paragraph
+// come before its simple fields.
+func (d *Document) IndexEntries() []IndexEntryField {
+	entries := []IndexEntryField{}
+	var cur *wml.CT_P
+	s := &fieldScanner{end: func(f *scannedField) {
+		if e, ok := parseIndexEntry(f.instr.String()); ok {
+			entries = append(entries, IndexEntryField{e, Paragraph{d, cur}, Run{d, f.run}})
+		}
+	}}
+	walkBlockParagraphs(d.X().Body.EG_BlockLevelElts, func(p *wml.CT_P) {
+		cur = p
+		s.paragraph(p)
+		walkSimpleFields(p.EG_PContent, func(fs *wml.CT_SimpleField) {
+			if e, ok := parseIndexEntry(fs.InstrAttr); ok {
+				entries = append(entries, IndexEntryField{e, Paragraph{d, p}, Run{d, nil}})
+			}
+		})
+	})
+	return entries
+}
+
+// parseIndexEntry parses instr if it is an XE field instruction.
+func parseIndexEntry(instr string) (IndexEntry, bool) {
+	// keep escaped colons apart from the level separators
+	args := fieldArgs(protectIndexColons(instr))
+	if len(args) < 2 || !strings.EqualFold(args[0], FieldIndexEntry) {
+		return IndexEntry{}, false
+	}
+	e := IndexEntry{}
+	text := ""
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case strings.EqualFold(arg, "\\b"):
+			e.Bold = true
+		case strings.EqualFold(arg, "\\i"):
+			e.Italic = true
+		case strings.EqualFold(arg, "\\t") && i+1 < len(args):
+			e.CrossReference = strings.Replace(args[i+1], "\ue000", ":", -1)
+			i++
+		case strings.HasPrefix(arg, "\\"):
+			if len(arg) == 2 && strings.ContainsAny(arg[1:], "fFrRyY") {
+				i++
+			}
+		case text == "":
+			text = arg
+		}
+	}
+	levels := strings.SplitN(text, ":", 2)
+	e.Main = strings.TrimSpace(strings.Replace(levels[0], "\ue000", ":", -1))
+	if len(levels) == 2 {
+		e.Sub = strings.TrimSpace(strings.Replace(levels[1], "\ue000", ":", -1))
+	}
+	return e, e.Main != ""
+}
+
+// AddIndex adds an empty INDEX field to the end of the body. The index is
+// filled by Update, or by UpdateIndexes of the convert package with the page
+// numbers of the laid out document.
+func (d *Document) AddIndex(opts IndexOptions) Index {
+	d.addIndexStyles()
+	if opts.Columns > 1 {
+		// end the preceding section and make the final section a continuous
+		// one with columns
+		body := d.X().Body
+		if body.SectPr == nil {
+			body.SectPr = wml.NewCT_SectPr()
+		}
+		prev := *body.SectPr
+		d.AddParagraph().Properties().X().SectPr = &prev
+		body.SectPr.Type = wml.NewCT_SectType()
+		body.SectPr.Type.ValAttr = wml.ST_SectionMarkContinuous
+		num := int64(opts.Columns)
+		space := uint64(measurement.Inch / 2 / measurement.Twips)
+		body.SectPr.Cols = wml.NewCT_Columns()
+		body.SectPr.Cols.NumAttr = &num
+		body.SectPr.Cols.SpaceAttr = &sharedTypes.ST_TwipsMeasure{ST_UnsignedDecimalNumber: &space}
+	}
+	p := d.AddParagraph()
+	p.SetStyle(StyleIndex1)
+	r := p.AddRun()
+	r.addFieldStart(opts.instruction())
+	ic := r.newIC()
+	ic.RunInnerContentChoice.FldChar = wml.NewCT_FldChar()
+	ic.RunInnerContentChoice.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeEnd
+	return Index{d, p.X()}
+}
+
+func (o IndexOptions) instruction() string {
+	code := " " + FieldIndex
+	if o.Columns > 1 {
+		code += " \\c " + quoteFieldArg(strconv.Itoa(o.Columns))
+	}
+	if o.Headings {
+		code += " \\h \"A\""
+	}
+	if o.Separator != "" {
+		code += " \\e " + quoteFieldArg(o.Separator)
+	}
+	return code + " "
+}
+
+// parseIndexOptions parses instr if it is an INDEX field instruction. It
+// returns false if it is not and an error if a switch has an invalid value.
+func parseIndexOptions(instr string) (IndexOptions, bool, error) {
+	args := fieldArgs(instr)
+	if len(args) == 0 || !strings.EqualFold(args[0], FieldIndex) {
+		return IndexOptions{}, false, nil
+	}
+	o := IndexOptions{}
+	for i := 1; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "\\h":
+			// the argument is the text of the headings, \h "A" for the letters
+			o.Headings = true
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "\\") {
+				i++
+			}
+		case "\\c":
+			if i+1 == len(args) {
+				return o, true, errors.New("missing number of index columns")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return o, true, fmt.Errorf("invalid number of index columns %q", args[i+1])
+			}
+			o.Columns = n
+			i++
+		case "\\e":
+			if i+1 < len(args) {
+				o.Separator = args[i+1]
+				i++
+			}
+		}
+	}
+	return o, true, nil
+}
+
+// Indexes returns the INDEX fields of the body.
+func (d *Document) Indexes() []Index {
+	indexes := []Index{}
+	s := &fieldScanner{end: func(f *scannedField) {
+		if _, ok, _ := parseIndexOptions(f.instr.String()); ok {
+			indexes = append(indexes, Index{d, f.begin})
+		}
+	}}
+	bc := newBlockContainer(&d.X().Body.EG_BlockLevelElts)
+	for i := range bc.units {
+		if p := bc.unitParagraph(i); p != nil {
+			s.paragraph(p)
+		}
+	}
+	return indexes
+}
+
+// Options returns the options of the index, or an error if its field has an
+// invalid switch.
+func (ix Index) Options() (IndexOptions, error) {
+	var opts IndexOptions
+	var err error
+	ix.locate(func(f *scannedField) { opts, _, err = parseIndexOptions(f.instr.String()) })
+	return opts, err
+}
+
+// Paragraphs returns the paragraphs of the result of the index.
+func (ix Index) Paragraphs() []Paragraph {
+	bc, first, last := ix.locate(nil)
+	ps := []Paragraph{}
+	for i := first; i >= 0 && i <= last; i++ {
+		if p := bc.unitParagraph(i); p != nil {
+			ps = append(ps, Paragraph{ix._doc, p})
+		}
+	}
+	return ps
+}
+
+// locate returns the body units from the start to the end of the index and
+// calls fn with the index field if it is not nil. first is -1 if the index
+// is no longer in the body.
+func (ix Index) locate(fn func(f *scannedField)) (bc *blockContainer, first, last int) {
+	bc = newBlockContainer(&ix._doc.X().Body.EG_BlockLevelElts)
+	first, last = -1, -1
+	cur := 0
+	s := &fieldScanner{end: func(f *scannedField) {
+		if last < 0 && f.outer && f.begin == ix._p {
+			last = cur
+			if fn != nil {
+				fn(f)
+			}
+		}
+	}}
+	for i := range bc.units {
+		p := bc.unitParagraph(i)
+		if first < 0 && p == ix._p {
+			first = i
+		}
+		if first < 0 || p == nil {
+			continue
+		}
+		cur = i
+		s.paragraph(p)
+		if last >= 0 {
+			break
+		}
+	}
+	if last < 0 {
+		return bc, -1, -1
+	}
+	return bc, first, last
+}
+
+// Update replaces the result of the index with the index entries of the
+// document, see IndexEntries. pageOf returns the page number of an entry,
+// entries without a page are left out unless they have a cross-reference.
+func (ix Index) Update(pageOf func(e IndexEntryField) (int, bool)) error {
+	var opts IndexOptions
+	var err error
+	bc, first, last := ix.locate(func(f *scannedField) { opts, _, err = parseIndexOptions(f.instr.String()) })
+	if first < 0 {
+		return errors.New("index not found in the body")
+	}
+	if err != nil {
+		return err
+	}
+	d := ix._doc
+	d.addIndexStyles()
+	paras := d.indexParagraphs(opts, pageOf)
+
+	// the first paragraph keeps its identity and starts the field, the last
+	// one ends it and keeps a section break of the old result
+	var sectPr *wml.CT_SectPr
+	if lp := bc.unitParagraph(last); lp != nil && lp.PPr != nil {
+		sectPr = lp.PPr.SectPr
+	}
+	*ix._p = *paras[0]
+	paras[0] = ix._p
+	begin := wml.NewCT_R()
+	Run{d, begin}.addFieldStart(opts.instruction())
+	pc := wml.NewEG_PContent()
+	crc := wml.NewEG_ContentRunContent()
+	crc.ContentRunContentChoice.R = begin
+	pc.PContentChoice.EG_ContentRunContent = []*wml.EG_ContentRunContent{crc}
+	ix._p.EG_PContent = append([]*wml.EG_PContent{pc}, ix._p.EG_PContent...)
+	end := Paragraph{d, paras[len(paras)-1]}
+	ic := end.AddRun().newIC()
+	ic.RunInnerContentChoice.FldChar = wml.NewCT_FldChar()
+	ic.RunInnerContentChoice.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeEnd
+	if sectPr != nil {
+		end.Properties().X().SectPr = sectPr
+	}
+
+	units := []*wml.EG_BlockLevelElts{}
+	for _, p := range paras {
+		cbc := wml.NewEG_ContentBlockContent()
+		cbc.ContentBlockContentChoice.P = []*wml.CT_P{p}
+		units = append(units, newBlockUnit(cbc))
+	}
+	bc.units = concatUnits(bc.units[:first], units, bc.units[last+1:])
+	bc.store(bc.units)
+	return nil
+}
+
+// addFieldStart adds the begin character, instruction and separator of a
+// complex field to the run.
+func (r Run) addFieldStart(code string) {
+	ic := r.newIC()
+	ic.RunInnerContentChoice.FldChar = wml.NewCT_FldChar()
+	ic.RunInnerContentChoice.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeBegin
+	ic = r.newIC()
+	ic.RunInnerContentChoice.InstrText = wml.NewCT_Text()
+	ic.RunInnerContentChoice.InstrText.Content = code
+	ic = r.newIC()
+	ic.RunInnerContentChoice.FldChar = wml.NewCT_FldChar()
+	ic.RunInnerContentChoice.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeSeparate
+}
+
+// indexPage is a page number of an index entry.
+type indexPage struct {
+	n            int
+	bold, italic bool
+}
+
+// indexTerm is a main entry or subentry of the index with its page numbers
+// and cross-references.
+type indexTerm struct {
+	text  string
+	pages []indexPage
+	refs  []string
+	subs  map[string]*indexTerm
+}
+
+func (t *indexTerm) add(e IndexEntry, page int, ok bool) {
+	switch {
+	case e.CrossReference != "":
+		for _, r := range t.refs {
+			if r == e.CrossReference {
+				return
+			}
+		}
+		t.refs = append(t.refs, e.CrossReference)
+	case ok:
+		for i := range t.pages {
+			if t.pages[i].n == page {
+				t.pages[i].bold = t.pages[i].bold || e.Bold
+				t.pages[i].italic = t.pages[i].italic || e.Italic
+				return
+			}
+		}
+		t.pages = append(t.pages, indexPage{page, e.Bold, e.Italic})
+	}
+}
+
+func (t *indexTerm) empty() bool {
+	return len(t.pages) == 0 && len(t.refs) == 0 && len(t.subs) == 0
+}
+
+// sortedTerms returns the non-empty terms sorted ignoring case.
+func sortedTerms(terms map[string]*indexTerm) []*indexTerm {
+	sorted := []*indexTerm{}
+	for _, t := range terms {
+		if !t.empty() {
+			sorted = append(sorted, t)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := strings.ToLower(sorted[i].text), strings.ToLower(sorted[j].text)
+		if a != b {
+			return a < b
+		}
+		return sorted[i].text < sorted[j].text
+	})
+	return sorted
+}
+
+// indexParagraphs returns the paragraphs listing the index entries of d.
+func (d *Document) indexParagraphs(opts IndexOptions, pageOf func(e IndexEntryField) (int, bool)) []*wml.CT_P {
+	terms := map[string]*indexTerm{}
+	for _, e := range d.IndexEntries() {
+		page, ok := pageOf(e)
+		t, found := terms[e.Main]
+		if !found {
+			t = &indexTerm{text: e.Main, subs: map[string]*indexTerm{}}
+			terms[e.Main] = t
+		}
+		if e.Sub == "" {
+			t.add(e.IndexEntry, page, ok)
+			continue
+		}
+		st, found := t.subs[e.Sub]
+		if !found {
+			st = &indexTerm{text: e.Sub}
+			t.subs[e.Sub] = st
+		}
+		st.add(e.IndexEntry, page, ok)
+	}
+	sep := opts.Separator
+	if sep == "" {
+		sep = ", "
+	}
+	paras := []*wml.CT_P{}
+	heading := ""
+	for _, t := range sortedTerms(terms) {
+		if opts.Headings {
+			if h := indexHeading(t.text); h != heading {
+				heading = h
+				p := Paragraph{d, wml.NewCT_P()}
+				p.SetStyle(StyleIndexHeading)
+				p.AddRun().AddText(h)
+				paras = append(paras, p.X())
+			}
+		}
+		paras = append(paras, d.indexTermParagraph(t, StyleIndex1, sep))
+		for _, st := range sortedTerms(t.subs) {
+			paras = append(paras, d.indexTermParagraph(st, StyleIndex2, sep))
+		}
+	}
+	if len(paras) == 0 {
+		p := Paragraph{d, wml.NewCT_P()}
+		p.SetStyle(StyleIndex1)
+		p.AddRun().AddText("No index entries found.")
+		paras = append(paras, p.X())
+	}
+	return paras
+}
+
+// indexHeading returns the letter heading of an entry, "#" for entries not
+// starting with a letter.
+func indexHeading(text string) string {
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			return string(unicode.ToUpper(r))
+		}
+		break
+	}
+	return "#"
+}
+
+func (d *Document) indexTermParagraph(t *indexTerm, style, sep string) *wml.CT_P {
+	p := Paragraph{d, wml.NewCT_P()}
+	p.SetStyle(style)
+	p.AddRun().AddText(t.text)
+	if len(t.pages) == 0 && len(t.refs) == 0 {
+		return p.X()
+	}
+	p.AddRun().AddText(sep)
+	sort.Slice(t.pages, func(i, j int) bool { return t.pages[i].n < t.pages[j].n })
+	for i, pg := range t.pages {
+		if i > 0 {
+			p.AddRun().AddText(", ")
+		}
+		r := p.AddRun()
+		if pg.bold {
+			r.Properties().SetBold(true)
+		}
+		if pg.italic {
+			r.Properties().SetItalic(true)
+		}
+		r.AddText(strconv.Itoa(pg.n))
+	}
+	for i, ref := range t.refs {
+		if i > 0 || len(t.pages) > 0 {
+			p.AddRun().AddText(". ")
+		}
+		p.AddRun().AddText(ref)
+	}
+	return p.X()
+}
+
+// addIndexStyles adds the styles of the index paragraphs that are missing.
+func (d *Document) addIndexStyles() {
+	for i, id := range []string{StyleIndex1, StyleIndex2} {
+		if _, ok := d.Styles.SearchStyleById(id); ok {
+			continue
+		}
+		s := d.Styles.AddStyle(id, wml.ST_StyleTypeParagraph, false)
+		s.SetName("index " + strconv.Itoa(i+1))
+		s.SetBasedOn("Normal")
+		s.SetNextStyle("Normal")
+		s.SetSemiHidden(true)
+		s.SetUnhideWhenUsed(true)
+		s.ParagraphProperties().SetLeftIndent(measurement.Distance(220*(i+1)) * measurement.Twips)
+		s.ParagraphProperties().SetHangingIndent(220 * measurement.Twips)
+	}
+	if _, ok := d.Styles.SearchStyleById(StyleIndexHeading); !ok {
+		s := d.Styles.AddStyle(StyleIndexHeading, wml.ST_StyleTypeParagraph, false)
+		s.SetName("index heading")
+		s.SetBasedOn("Normal")
+		s.SetNextStyle(StyleIndex1)
+		s.SetSemiHidden(true)
+		s.SetUnhideWhenUsed(true)
+		s.ParagraphProperties().SetKeepNext(true)
+		s.ParagraphProperties().SetSpacing(6*measurement.Point, 0)
+		s.RunProperties().SetBold(true)
+	}
+}
+
+// fieldScanner follows the complex fields of paragraphs visited in document
+// order and calls end with each field when it ends.
+type fieldScanner struct {
+	stack []*scannedField
+	end   func(f *scannedField)
+}
+
+// scannedField is a complex field found by a fieldScanner.
+type scannedField struct {
+	instr     strings.Builder
+	separated bool
+
+	// begin is the paragraph of the begin character and outer is set if the
+	// field is not nested in another field.
+	begin *wml.CT_P
+	outer bool
+
+	// run is the first run holding instruction text.
+	run *wml.CT_R
+}
+
+func (s *fieldScanner) paragraph(p *wml.CT_P) {
+	walkParagraphRuns(p, func(r *wml.CT_R) {
+		for _, ric := range r.EG_RunInnerContent {
+			c := ric.RunInnerContentChoice
+			var top *scannedField
+			if len(s.stack) > 0 {
+				top = s.stack[len(s.stack)-1]
+			}
+			switch {
+			case c.FldChar != nil:
+				switch c.FldChar.FldCharTypeAttr {
+				case wml.ST_FldCharTypeBegin:
+					s.stack = append(s.stack, &scannedField{begin: p, outer: top == nil})
+				case wml.ST_FldCharTypeSeparate:
+					if top != nil {
+						top.separated = true
+					}
+				case wml.ST_FldCharTypeEnd:
+					if top != nil {
+						s.stack = s.stack[:len(s.stack)-1]
+						s.end(top)
+					}
+				}
+			case c.InstrText != nil:
+				if top != nil && !top.separated {
+					top.instr.WriteString(c.InstrText.Content)
+					if top.run == nil {
+						top.run = r
+					}
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,60 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"testing"
+)
+
+func TestIndexEntryRoundTrip(t *testing.T) {
+	td := []IndexEntry{
+		{Main: "Apple"},
+		{Main: "Fruit", Sub: "Apple", Bold: true},
+		{Main: "Time 10:30", Sub: "a:b", Italic: true},
+		{Main: `C:\dir`, Sub: `say "hi"`},
+		{Main: `trailing\`, Sub: "sub"},
+		{Main: "Pears", CrossReference: `See Fruit:Pears \ Apples`},
+	}
+	for _, e := range td {
+		instr := e.instruction()
+		got, ok := parseIndexEntry(instr)
+		if !ok || got != e {
+			t.Errorf("round trip of %+v via %q: expected %+v, got %+v", e, instr, e, got)
+		}
+	}
+}
+
+func TestParseIndexEntryWord(t *testing.T) {
+	got, ok := parseIndexEntry(` XE "Fruit\:Apple:Green" \t "See Pears" `)
+	exp := IndexEntry{Main: "Fruit:Apple", Sub: "Green", CrossReference: "See Pears"}
+	if !ok || got != exp {
+		t.Errorf("expected %+v, got %+v", exp, got)
+	}
+}
+
+func TestParseIndexOptions(t *testing.T) {
+	td := []struct {
+		instr string
+		exp   IndexOptions
+	}{
+		{` INDEX \c "2" \h "A" \e ", " `, IndexOptions{Columns: 2, Headings: true, Separator: ", "}},
+		{` INDEX \e " - " \h`, IndexOptions{Headings: true, Separator: " - "}},
+		{` INDEX \h \c "3"`, IndexOptions{Columns: 3, Headings: true}},
+	}
+	for _, tc := range td {
+		got, ok, err := parseIndexOptions(tc.instr)
+		if !ok || err != nil || got != tc.exp {
+			t.Errorf("parseIndexOptions(%q): expected %+v, got %+v, %v", tc.instr, tc.exp, got, err)
+		}
+	}
+	if _, _, err := parseIndexOptions(` INDEX \c`); err == nil {
+		t.Errorf("expected an error for a missing number of columns")
+	}
+}
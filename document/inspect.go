@@ -0,0 +1,266 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// Inspect reports the personal information and hidden data in the document
+// without changing it, see RemovePersonalInformation.
+func (d *Document) Inspect() ([]common.InspectionFinding, error) {
+	return d.inspect(common.NewInspector(false))
+}
+
+// RemovePersonalInformation removes the given kinds of personal information
+// and hidden data, or all kinds if none are given, and returns the findings.
+// It covers the document properties, comment authors, the authors and dates
+// of tracked changes, revision IDs, hidden text, the thumbnail and file paths
+// of external links. Comment and revision authors are replaced by "Author".
+// Word is also told to remove personal information when saving the document.
+func (d *Document) RemovePersonalInformation(kinds ...common.InspectionKind) ([]common.InspectionFinding, error) {
+	return d.inspect(common.NewInspector(true, kinds...))
+}
+
+// anonymousAuthor replaces the names of comment and revision authors.
+const anonymousAuthor = "Author"
+
+func (d *Document) inspect(i *common.Inspector) ([]common.InspectionFinding, error) {
+	if err := i.DocBase(&d.DocBase); err != nil {
+		return i.Findings, err
+	}
+	i.Relationships("document", d._fgg)
+	for n, rels := range d._dcf {
+		i.Relationships(fmt.Sprintf("header %d", n+1), rels)
+	}
+	for n, rels := range d._abc {
+		i.Relationships(fmt.Sprintf("footer %d", n+1), rels)
+	}
+
+	for n, c := range d.Comments() {
+		if c.X().AuthorAttr == "" && c.X().InitialsAttr == nil {
+			continue
+		}
+		if i.Found(common.InspectionCommentAuthors, fmt.Sprintf("comment %d", n+1), c.X().AuthorAttr) {
+			c.X().AuthorAttr = anonymousAuthor
+			initials := anonymousAuthor[:1]
+			c.X().InitialsAttr = &initials
+			c.X().DateAttr = nil
+		}
+	}
+	for _, rel := range d._fgg.Relationships() {
+		if strings.HasSuffix(rel.Type(), "/people") && i.Found(common.InspectionCommentAuthors, "word/"+rel.Target(), "list of people") {
+			d._fgg.Remove(rel)
+			d.ContentTypes.RemoveOverride("word/" + rel.Target())
+			files := d.ExtraFiles[:0]
+			for _, ef := range d.ExtraFiles {
+				if ef.ZipPath != "word/"+rel.Target() {
+					files = append(files, ef)
+				}
+			}
+			d.ExtraFiles = files
+		}
+	}
+
+	s := inspectedStories{_authors: map[string]bool{}}
+	names := []string{"body"}
+	for n := range d.Headers() {
+		names = append(names, fmt.Sprintf("header %d", n+1))
+	}
+	for n := range d.Footers() {
+		names = append(names, fmt.Sprintf("footer %d", n+1))
+	}
+	for n, story := range d.stories() {
+		name := "notes and comments"
+		if n < len(names) {
+			name = names[n]
+		}
+		s.story(i, name, *story)
+	}
+	authors := []string{}
+	for a := range s._authors {
+		authors = append(authors, a)
+	}
+	sort.Strings(authors)
+	for _, a := range authors {
+		i.Found(common.InspectionRevisionAuthors, "tracked changes", a)
+	}
+
+	if settings := d.Settings.X(); settings != nil {
+		if settings.Rsids != nil {
+			s._rsids += len(settings.Rsids.Rsid)
+		}
+		if s._rsids > 0 && i.Found(common.InspectionRevisionIDs, "document", fmt.Sprintf("%d revision IDs", s._rsids)) {
+			settings.Rsids = nil
+		}
+		if settings.AttachedTemplate != nil && i.Removes(common.InspectionPersonalPaths) {
+			// the template is referenced from the settings relationships,
+			// which are not kept
+			settings.AttachedTemplate = nil
+		}
+		if i.Removes(common.InspectionDocumentProperties) {
+			settings.RemovePersonalInformation = wml.NewCT_OnOff()
+		}
+	}
+	return i.Findings, nil
+}
+
+// inspectedStories collects the tracked change authors, revision IDs and
+// hidden text of the stories of a document.
+type inspectedStories struct {
+	_authors map[string]bool
+	_rsids   int
+}
+
+func (s *inspectedStories) story(i *common.Inspector, name string, blocks []*wml.EG_BlockLevelElts) {
+	revisions := i.Removes(common.InspectionRevisionAuthors)
+	track := func(author *string, date **time.Time) {
+		s._authors[*author] = true
+		if revisions {
+			*author = anonymousAuthor
+			*date = nil
+		}
+	}
+	rsids := i.Removes(common.InspectionRevisionIDs)
+	hidden := 0
+	walkBlockParagraphs(blocks, func(p *wml.CT_P) {
+		for _, a := range []**string{&p.RsidRAttr, &p.RsidRPrAttr, &p.RsidDelAttr, &p.RsidPAttr, &p.RsidRDefaultAttr} {
+			if *a != nil {
+				s._rsids++
+				if rsids {
+					*a = nil
+				}
+			}
+		}
+		if ppr := p.PPr; ppr != nil {
+			if ppr.PPrChange != nil {
+				track(&ppr.PPrChange.AuthorAttr, &ppr.PPrChange.DateAttr)
+			}
+			if rpr := ppr.RPr; rpr != nil {
+				for _, tc := range []*wml.CT_TrackChange{rpr.Ins, rpr.Del, rpr.MoveFrom, rpr.MoveTo} {
+					if tc != nil {
+						track(&tc.AuthorAttr, &tc.DateAttr)
+					}
+				}
+				if rpr.RPrChange != nil {
+					track(&rpr.RPrChange.AuthorAttr, &rpr.RPrChange.DateAttr)
+				}
+			}
+		}
+		walkRunLevelElts(p.EG_PContent, func(rle *wml.EG_RunLevelElts) {
+			c := rle.RunLevelEltsChoice
+			for _, rtc := range []*wml.CT_RunTrackChange{c.Ins, c.Del, c.MoveFrom, c.MoveTo} {
+				if rtc != nil {
+					track(&rtc.AuthorAttr, &rtc.DateAttr)
+				}
+			}
+		})
+		walkParagraphRuns(p, func(r *wml.CT_R) {
+			for _, a := range []**string{&r.RsidRAttr, &r.RsidRPrAttr, &r.RsidDelAttr} {
+				if *a != nil {
+					s._rsids++
+					if rsids {
+						*a = nil
+					}
+				}
+			}
+			if r.RPr == nil {
+				return
+			}
+			if r.RPr.RPrChange != nil {
+				track(&r.RPr.RPrChange.AuthorAttr, &r.RPr.RPrChange.DateAttr)
+			}
+			if !onOff(r.RPr.Vanish) {
+				return
+			}
+			kept := []*wml.EG_RunInnerContent{}
+			for _, ric := range r.EG_RunInnerContent {
+				if isRunStructure(ric) {
+					kept = append(kept, ric)
+				}
+			}
+			if len(kept) < len(r.EG_RunInnerContent) {
+				hidden++
+				if i.Removes(common.InspectionHiddenText) {
+					r.EG_RunInnerContent = kept
+				}
+			}
+		})
+	})
+	if hidden > 0 {
+		i.Found(common.InspectionHiddenText, name, fmt.Sprintf("%d runs of hidden text", hidden))
+	}
+}
+
+// isRunStructure reports whether ric is a field character, a field code or a
+// reference mark rather than content, these are kept when removing hidden
+// text so that fields and notes stay intact.
+func isRunStructure(ric *wml.EG_RunInnerContent) bool {
+	c := ric.RunInnerContentChoice
+	return c.FldChar != nil || c.InstrText != nil || c.DelInstrText != nil ||
+		c.FootnoteReference != nil || c.EndnoteReference != nil || c.CommentReference != nil ||
+		c.FootnoteRef != nil || c.EndnoteRef != nil || c.AnnotationRef != nil ||
+		c.Separator != nil || c.ContinuationSeparator != nil || c.LastRenderedPageBreak != nil
+}
+
+// walkRunLevelElts calls fn for the run level elements of pcs, such as
+// tracked changes, including those nested in insertions and moved text.
+func walkRunLevelElts(pcs []*wml.EG_PContent, fn func(rle *wml.EG_RunLevelElts)) {
+	var choice func(c *wml.EG_ContentRunContentChoice)
+	runs := func(crcs []*wml.EG_ContentRunContent) {
+		for _, crc := range crcs {
+			choice(crc.ContentRunContentChoice)
+		}
+	}
+	choice = func(c *wml.EG_ContentRunContentChoice) {
+		if c == nil {
+			return
+		}
+		if sdt := c.Sdt; sdt != nil && sdt.SdtContent != nil {
+			walkRunLevelElts(sdt.SdtContent.EG_PContent, fn)
+		}
+		for _, rle := range c.EG_RunLevelElts {
+			fn(rle)
+			for _, rtc := range []*wml.CT_RunTrackChange{rle.RunLevelEltsChoice.Ins, rle.RunLevelEltsChoice.MoveTo} {
+				if rtc != nil {
+					for _, tc := range rtc.RunTrackChangeChoice {
+						choice(tc.ContentRunContentChoice)
+					}
+				}
+			}
+		}
+	}
+	for _, pc := range pcs {
+		runs(pc.PContentChoice.EG_ContentRunContent)
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			runs(hl.PContentChoice.EG_ContentRunContent)
+		}
+		for _, fs := range pc.PContentChoice.FldSimple {
+			walkRunLevelElts(fs.EG_PContent, fn)
+		}
+	}
+}
+
+// onOff reports whether an on/off property is set and not turned off.
+func onOff(v *wml.CT_OnOff) bool {
+	if v == nil {
+		return false
+	}
+	if v.ValAttr == nil || v.ValAttr.Bool == nil {
+		return true
+	}
+	return *v.ValAttr.Bool
+}
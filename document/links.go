@@ -0,0 +1,735 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"strings"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml/picture"
+	"github.com/unidoc/unioffice/v2/schema/soo/pkg/relationships"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+	"github.com/unidoc/unioffice/v2/schema/urn/schemas_microsoft_com/vml"
+)
+
+// LinkKind is the kind of element holding a link.
+type LinkKind byte
+
+// LinkKind constants.
+const (
+	LinkKindHyperlink LinkKind = iota // a hyperlink element
+	LinkKindField                     // a HYPERLINK field
+	LinkKindImage                     // a click hyperlink of a drawing
+)
+
+// LinkLocation is the part of the document a link is in.
+type LinkLocation byte
+
+// LinkLocation constants.
+const (
+	LinkLocationBody LinkLocation = iota
+	LinkLocationHeader
+	LinkLocationFooter
+	LinkLocationFootnote
+	LinkLocationEndnote
+	LinkLocationComment
+)
+
+// Link is a hyperlink found in a document by Document.Links. Its target can be
+// changed or removed in place.
+type Link struct {
+	_part     *linkPart
+	_kind     LinkKind
+	_text     string
+	_hl       *wml.CT_Hyperlink
+	_pc       *wml.EG_PContent
+	_owner    *[]*wml.EG_PContent
+	_simple   *wml.CT_SimpleField
+	_field    *complexField
+	_drawing  []*dml.CT_Hyperlink
+	_target   string
+	_anchor   string
+	_external bool
+}
+
+// linkPart is a part of the document together with the relationships its
+// links refer to.
+type linkPart struct {
+	_doc    *Document
+	_loc    LinkLocation
+	_rels   common.Relationships
+	_blocks [][]*wml.EG_BlockLevelElts
+}
+
+// complexField is a field made of field characters and instruction text
+// spread over runs.
+type complexField struct {
+	instr  []*wml.CT_Text
+	codes  []fieldCode
+	result bool
+	text   strings.Builder
+}
+
+// fieldCode is a field character or instruction text of a run.
+type fieldCode struct {
+	r  *wml.CT_R
+	ic *wml.EG_RunInnerContent
+}
+
+func (f *complexField) instruction() string {
+	sb := strings.Builder{}
+	for _, t := range f.instr {
+		sb.WriteString(t.Content)
+	}
+	return sb.String()
+}
+
+// Links returns the hyperlinks of the document: hyperlink elements, HYPERLINK
+// fields and drawing click hyperlinks in the body, headers, footers,
+// footnotes, endnotes, comments and the text boxes they contain.
+func (d *Document) Links() []Link {
+	links := []Link{}
+	for _, part := range d.linkParts() {
+		links = append(links, part.links()...)
+	}
+	return links
+}
+
+// RewriteLinkTargets calls rewrite with the URL of every external link of the
+// document and updates the links for which a different URL is returned. An
+// empty URL removes the link, keeping its text. It returns the number of links
+// changed.
+func (d *Document) RewriteLinkTargets(rewrite func(url string) string) int {
+	parts := d.linkParts()
+	refs := countLinkRefs(parts)
+	links := []Link{}
+	for _, part := range parts {
+		links = append(links, part.links()...)
+	}
+	n := 0
+	for _, l := range links {
+		if !l.IsExternal() {
+			continue
+		}
+		url := rewrite(l.Target())
+		switch {
+		case url == l.Target():
+			continue
+		case url == "":
+			l.remove(refs)
+		default:
+			l.setTarget(url, refs)
+		}
+		n++
+	}
+	return n
+}
+
+func (d *Document) linkParts() []*linkPart {
+	main := &linkPart{_doc: d, _loc: LinkLocationBody, _rels: d._fgg, _blocks: [][]*wml.EG_BlockLevelElts{d.X().Body.EG_BlockLevelElts}}
+	parts := []*linkPart{main}
+	for i, hdr := range d._ebg {
+		parts = append(parts, &linkPart{_doc: d, _loc: LinkLocationHeader, _rels: d._dcf[i], _blocks: [][]*wml.EG_BlockLevelElts{hdr.EG_BlockLevelElts}})
+	}
+	for i, ftr := range d._cca {
+		parts = append(parts, &linkPart{_doc: d, _loc: LinkLocationFooter, _rels: d._abc[i], _blocks: [][]*wml.EG_BlockLevelElts{ftr.EG_BlockLevelElts}})
+	}
+	notes := func(loc LinkLocation, blocks [][]*wml.EG_BlockLevelElts) {
+		if len(blocks) > 0 {
+			parts = append(parts, &linkPart{_doc: d, _loc: loc, _rels: d.noteRels(loc), _blocks: blocks})
+		}
+	}
+	var fns, ens, cms [][]*wml.EG_BlockLevelElts
+	for _, fn := range d.Footnotes() {
+		fns = append(fns, fn.X().EG_BlockLevelElts)
+	}
+	for _, en := range d.Endnotes() {
+		ens = append(ens, en.X().EG_BlockLevelElts)
+	}
+	for _, c := range d.Comments() {
+		cms = append(cms, c.X().EG_BlockLevelElts)
+	}
+	notes(LinkLocationFootnote, fns)
+	notes(LinkLocationEndnote, ens)
+	notes(LinkLocationComment, cms)
+	return parts
+}
+
+// noteRels returns the relationships of the footnotes, endnotes or comments
+// part, adding them if the part was created without any.
+func (d *Document) noteRels(loc LinkLocation) common.Relationships {
+	var rels *common.Relationships
+	switch loc {
+	case LinkLocationFootnote:
+		rels = &d._fnRels
+	case LinkLocationEndnote:
+		rels = &d._enRels
+	case LinkLocationComment:
+		rels = &d._cmRels
+	default:
+		return d._fgg
+	}
+	if rels.X() == nil {
+		*rels = common.NewRelationships()
+	}
+	return *rels
+}
+
+// partRels returns the relationships of the main document part and of the
+// headers, footers, footnotes, endnotes and comments that have any.
+func (d *Document) partRels() []common.Relationships {
+	rels := []common.Relationships{d._fgg}
+	rels = append(rels, d._dcf...)
+	rels = append(rels, d._abc...)
+	for _, r := range []common.Relationships{d._fnRels, d._enRels, d._cmRels} {
+		if r.X() != nil {
+			rels = append(rels, r)
+		}
+	}
+	return rels
+}
+
+func (p *linkPart) links() []Link {
+	lc := &linkCollector{part: p}
+	for _, blocks := range p._blocks {
+		lc.fields = nil
+		lc.blocks(blocks)
+	}
+	return lc.links
+}
+
+// relRef is a relationship of a part referred to by links.
+type relRef struct {
+	rels *relationships.Relationships
+	id   string
+}
+
+// countLinkRefs returns the number of links of the parts referring to each
+// relationship.
+func countLinkRefs(parts []*linkPart) map[relRef]int {
+	refs := map[relRef]int{}
+	for _, p := range parts {
+		for _, l := range p.links() {
+			if id := l.relID(); id != "" {
+				refs[relRef{p._rels.X(), id}]++
+			}
+		}
+	}
+	return refs
+}
+
+// Kind returns the kind of element holding the link.
+func (l Link) Kind() LinkKind { return l._kind }
+
+// Location returns the part of the document the link is in.
+func (l Link) Location() LinkLocation { return l._part._loc }
+
+// Text returns the display text of the link, for drawings this is their
+// description.
+func (l Link) Text() string { return l._text }
+
+// Target returns the URL the link points to, or an empty string for links to a
+// bookmark.
+func (l Link) Target() string { return l._target }
+
+// Anchor returns the name of the bookmark the link points to, if any.
+func (l Link) Anchor() string { return l._anchor }
+
+// IsExternal reports whether the link points to a URL.
+func (l Link) IsExternal() bool { return l._external }
+
+// X returns the inner wrapped hyperlink element for links of kind
+// LinkKindHyperlink and nil otherwise.
+func (l Link) X() *wml.CT_Hyperlink { return l._hl }
+
+// relID returns the ID of the relationship used by the link, if any.
+func (l Link) relID() string {
+	switch {
+	case l._hl != nil && l._hl.IdAttr != nil:
+		return *l._hl.IdAttr
+	case len(l._drawing) > 0 && l._drawing[0].IdAttr != nil:
+		return *l._drawing[0].IdAttr
+	}
+	return ""
+}
+
+// hyperlinkRel returns the ID of a hyperlink relationship of the part with the
+// given target, adding one if required.
+func (p *linkPart) hyperlinkRel(url string) string {
+	for _, rel := range p._rels.X().Relationship {
+		if rel.TypeAttr == unioffice.HyperLinkType && rel.TargetAttr == url {
+			return rel.IdAttr
+		}
+	}
+	return common.Relationship(p._rels.AddHyperlink(url)).ID()
+}
+
+// SetTarget points the link to url. Hyperlink relationships of the part are
+// reused or added as required and relationships no longer used are removed.
+func (l *Link) SetTarget(url string) {
+	l.setTarget(url, countLinkRefs(l._part._doc.linkParts()))
+}
+
+// setTarget points the link to url, refs are the reference counts of the
+// relationships, see countLinkRefs.
+func (l *Link) setTarget(url string, refs map[relRef]int) {
+	old := l.relID()
+	switch l._kind {
+	case LinkKindHyperlink:
+		l._hl.IdAttr = unioffice.String(l._part.hyperlinkRel(url))
+		l._hl.AnchorAttr = nil
+	case LinkKindField:
+		args := parseHyperlinkField(l._field.instruction())
+		args.target = url
+		args.anchor = ""
+		setInstruction(l._field.instr, args.String())
+		if l._simple != nil {
+			l._simple.InstrAttr = args.String()
+		}
+	case LinkKindImage:
+		id := l._part.hyperlinkRel(url)
+		for _, h := range l._drawing {
+			h.IdAttr = unioffice.String(id)
+		}
+	}
+	l._target, l._anchor, l._external = url, "", true
+	if id := l.relID(); id != "" {
+		refs[relRef{l._part._rels.X(), id}]++
+	}
+	l._part.release(old, refs)
+}
+
+// Remove removes the link, keeping its text.
+func (l *Link) Remove() {
+	l.remove(countLinkRefs(l._part._doc.linkParts()))
+}
+
+// remove removes the link, refs are the reference counts of the
+// relationships, see countLinkRefs.
+func (l *Link) remove(refs map[relRef]int) {
+	old := l.relID()
+	switch l._kind {
+	case LinkKindHyperlink:
+		l._pc.PContentChoice.Hyperlink = nil
+		l._pc.PContentChoice.EG_ContentRunContent = append(l._pc.PContentChoice.EG_ContentRunContent, l._hl.PContentChoice.EG_ContentRunContent...)
+	case LinkKindField:
+		if l._simple != nil {
+			l.removeSimpleField()
+		} else {
+			removeFieldCodes(l._field.codes)
+		}
+	case LinkKindImage:
+		for _, h := range l._drawing {
+			h.IdAttr = nil
+		}
+	}
+	l._target, l._anchor, l._external = "", "", false
+	l._part.release(old, refs)
+}
+
+// removeSimpleField replaces the simple field of the link with its result.
+func (l *Link) removeSimpleField() {
+	pcs := []*wml.EG_PContent{}
+	for _, pc := range *l._owner {
+		if pc != l._pc {
+			pcs = append(pcs, pc)
+			continue
+		}
+		kept := []*wml.CT_SimpleField{}
+		for _, fs := range pc.PContentChoice.FldSimple {
+			if fs != l._simple {
+				kept = append(kept, fs)
+			}
+		}
+		pc.PContentChoice.FldSimple = kept
+		if len(kept) > 0 || len(pc.PContentChoice.EG_ContentRunContent) > 0 || pc.PContentChoice.Hyperlink != nil {
+			pcs = append(pcs, pc)
+		}
+		pcs = append(pcs, l._simple.EG_PContent...)
+	}
+	*l._owner = pcs
+}
+
+func removeFieldCodes(codes []fieldCode) {
+	for _, c := range codes {
+		rics := []*wml.EG_RunInnerContent{}
+		for _, ic := range c.r.EG_RunInnerContent {
+			if ic != c.ic {
+				rics = append(rics, ic)
+			}
+		}
+		c.r.EG_RunInnerContent = rics
+	}
+}
+
+// release drops a reference of a link to the relationship with the given ID
+// and removes the relationship if no link of the document uses it anymore.
+func (p *linkPart) release(id string, refs map[relRef]int) {
+	if id == "" {
+		return
+	}
+	ref := relRef{p._rels.X(), id}
+	if refs[ref]--; refs[ref] > 0 {
+		return
+	}
+	rel := p._rels.GetByRelId(id)
+	if rel.X() != nil && rel.Type() == unioffice.HyperLinkType {
+		p._rels.Remove(rel)
+	}
+}
+
+// linkCollector walks the content of a part and collects its links.
+type linkCollector struct {
+	part   *linkPart
+	links  []Link
+	fields []*complexField
+}
+
+func (lc *linkCollector) add(l Link) {
+	l._part = lc.part
+	lc.links = append(lc.links, l)
+}
+
+// resolve fills in the target of a link using the relationship with the
+// given ID and the anchor.
+func (lc *linkCollector) resolve(l *Link, id, anchor *string) {
+	if id != nil && *id != "" {
+		l._target = lc.part._rels.GetTargetByRelId(*id)
+		l._external = true
+	}
+	if anchor != nil {
+		l._anchor = *anchor
+	}
+}
+
+func (lc *linkCollector) blocks(blocks []*wml.EG_BlockLevelElts) {
+	for _, ble := range blocks {
+		lc.contentBlocks(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+	}
+}
+
+func (lc *linkCollector) contentBlocks(cbcs []*wml.EG_ContentBlockContent) {
+	for _, c := range cbcs {
+		if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			lc.contentBlocks(sdt.SdtContent.EG_ContentBlockContent)
+		}
+		for _, p := range c.ContentBlockContentChoice.P {
+			lc.paragraphContent(&p.EG_PContent)
+		}
+		for _, tbl := range c.ContentBlockContentChoice.Tbl {
+			walkTableCells(tbl, func(tc *wml.CT_Tc) {
+				lc.blocks(tc.EG_BlockLevelElts)
+			})
+		}
+	}
+}
+
+func (lc *linkCollector) paragraphContent(pcs *[]*wml.EG_PContent) {
+	for _, pc := range *pcs {
+		lc.runContent(pc.PContentChoice.EG_ContentRunContent)
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			l := Link{_kind: LinkKindHyperlink, _hl: hl, _pc: pc, _owner: pcs, _text: runsText(hl.PContentChoice.EG_ContentRunContent)}
+			lc.resolve(&l, hl.IdAttr, hl.AnchorAttr)
+			lc.add(l)
+			lc.runContent(hl.PContentChoice.EG_ContentRunContent)
+		}
+		for _, fs := range pc.PContentChoice.FldSimple {
+			if args, ok := hyperlinkField(fs.InstrAttr); ok {
+				l := Link{_kind: LinkKindField, _simple: fs, _pc: pc, _owner: pcs, _text: contentText(fs.EG_PContent)}
+				l._field = &complexField{instr: []*wml.CT_Text{{Content: fs.InstrAttr}}}
+				l.setFieldTarget(args)
+				lc.add(l)
+			}
+			lc.paragraphContent(&fs.EG_PContent)
+		}
+	}
+}
+
+func (lc *linkCollector) runContent(crcs []*wml.EG_ContentRunContent) {
+	for _, crc := range crcs {
+		lc.runContentChoice(crc.ContentRunContentChoice)
+	}
+}
+
+func (lc *linkCollector) runContentChoice(c *wml.EG_ContentRunContentChoice) {
+	if c == nil {
+		return
+	}
+	if c.R != nil {
+		lc.run(c.R)
+	}
+	if sdt := c.Sdt; sdt != nil && sdt.SdtContent != nil {
+		lc.paragraphContent(&sdt.SdtContent.EG_PContent)
+	}
+	for _, rle := range c.EG_RunLevelElts {
+		for _, tc := range trackChanges(rle) {
+			lc.runContentChoice(tc.ContentRunContentChoice)
+		}
+	}
+}
+
+func (lc *linkCollector) run(r *wml.CT_R) {
+	for _, ric := range r.EG_RunInnerContent {
+		c := ric.RunInnerContentChoice
+		var top *complexField
+		if len(lc.fields) > 0 {
+			top = lc.fields[len(lc.fields)-1]
+		}
+		switch {
+		case c.FldChar != nil:
+			switch c.FldChar.FldCharTypeAttr {
+			case wml.ST_FldCharTypeBegin:
+				lc.fields = append(lc.fields, &complexField{codes: []fieldCode{{r, ric}}})
+			case wml.ST_FldCharTypeSeparate:
+				if top != nil {
+					top.result = true
+					top.codes = append(top.codes, fieldCode{r, ric})
+				}
+			case wml.ST_FldCharTypeEnd:
+				if top != nil {
+					top.codes = append(top.codes, fieldCode{r, ric})
+					lc.fields = lc.fields[:len(lc.fields)-1]
+					lc.endField(top)
+				}
+			}
+		case c.InstrText != nil:
+			if top != nil && !top.result {
+				top.instr = append(top.instr, c.InstrText)
+				top.codes = append(top.codes, fieldCode{r, ric})
+			}
+		case c.T != nil:
+			for _, f := range lc.fields {
+				if f.result {
+					f.text.WriteString(c.T.Content)
+				}
+			}
+		case c.Drawing != nil:
+			lc.drawing(c.Drawing)
+		case c.Pict != nil:
+			for _, a := range c.Pict.Any {
+				if shape, ok := a.(*vml.Shape); ok {
+					for _, sc := range shape.ShapeChoice {
+						if tb := sc.ShapeElementsChoice.Textbox; tb != nil && tb.TxbxContent != nil {
+							lc.blocks(tb.TxbxContent.EG_BlockLevelElts)
+						}
+					}
+				}
+			}
+		}
+	}
+	for _, x := range r.Extra {
+		if acr, ok := x.(*wml.AlternateContentRun); ok && acr.Choice.Drawing != nil {
+			lc.drawing(acr.Choice.Drawing)
+		}
+	}
+}
+
+func (lc *linkCollector) endField(f *complexField) {
+	args, ok := hyperlinkField(f.instruction())
+	if !ok {
+		return
+	}
+	l := Link{_kind: LinkKindField, _field: f, _text: f.text.String()}
+	l.setFieldTarget(args)
+	lc.add(l)
+}
+
+func (l *Link) setFieldTarget(args hyperlinkArgs) {
+	l._target, l._anchor = args.target, args.anchor
+	l._external = args.target != ""
+}
+
+func (lc *linkCollector) drawing(d *wml.CT_Drawing) {
+	for _, dc := range d.DrawingChoice {
+		var docPr *dml.CT_NonVisualDrawingProps
+		var gd *dml.CT_GraphicalObjectData
+		if dc.Anchor != nil {
+			docPr = dc.Anchor.DocPr
+			if dc.Anchor.Graphic != nil {
+				gd = dc.Anchor.Graphic.GraphicData
+			}
+		}
+		if dc.Inline != nil {
+			docPr = dc.Inline.DocPr
+			if dc.Inline.Graphic != nil {
+				gd = dc.Inline.Graphic.GraphicData
+			}
+		}
+		var hls []*dml.CT_Hyperlink
+		if docPr != nil && docPr.HlinkClick != nil {
+			hls = append(hls, docPr.HlinkClick)
+		}
+		if gd != nil {
+			for _, a := range gd.Any {
+				switch g := a.(type) {
+				case *picture.Pic:
+					if g.NvPicPr != nil && g.NvPicPr.CNvPr != nil && g.NvPicPr.CNvPr.HlinkClick != nil {
+						hls = append(hls, g.NvPicPr.CNvPr.HlinkClick)
+					}
+				case *wml.WdWsp:
+					if g.WordprocessingShapeChoice1 != nil {
+						if tb := g.WordprocessingShapeChoice1.Txbx; tb != nil && tb.TxbxContent != nil {
+							lc.blocks(tb.TxbxContent.EG_BlockLevelElts)
+						}
+					}
+				}
+			}
+		}
+		if len(hls) == 0 || hls[0].IdAttr == nil || *hls[0].IdAttr == "" {
+			continue
+		}
+		l := Link{_kind: LinkKindImage, _drawing: hls}
+		if docPr.DescrAttr != nil {
+			l._text = *docPr.DescrAttr
+		}
+		lc.resolve(&l, hls[0].IdAttr, nil)
+		lc.add(l)
+	}
+}
+
+// runsText returns the text of the runs of crcs.
+func runsText(crcs []*wml.EG_ContentRunContent) string {
+	sb := strings.Builder{}
+	walkRunContent(crcs, func(r *wml.CT_R) {
+		for _, ric := range r.EG_RunInnerContent {
+			if t := ric.RunInnerContentChoice.T; t != nil {
+				sb.WriteString(t.Content)
+			}
+		}
+	})
+	return sb.String()
+}
+
+// contentText returns the text of the runs of pcs.
+func contentText(pcs []*wml.EG_PContent) string {
+	sb := strings.Builder{}
+	walkContentRuns(pcs, func(r *wml.CT_R) {
+		for _, ric := range r.EG_RunInnerContent {
+			if t := ric.RunInnerContentChoice.T; t != nil {
+				sb.WriteString(t.Content)
+			}
+		}
+	})
+	return sb.String()
+}
+
+// hyperlinkArgs are the arguments of a HYPERLINK field.
+type hyperlinkArgs struct {
+	target string
+	anchor string
+	// switches holds the remaining switches with their arguments
+	switches []string
+}
+
+// String returns the field instruction for the arguments.
+func (a hyperlinkArgs) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(" HYPERLINK")
+	if a.target != "" {
+		sb.WriteString(" " + quoteFieldArg(a.target))
+	}
+	if a.anchor != "" {
+		sb.WriteString(" \\l " + quoteFieldArg(a.anchor))
+	}
+	for _, s := range a.switches {
+		sb.WriteString(" " + s)
+	}
+	sb.WriteString(" ")
+	return sb.String()
+}
+
+// quoteFieldArg quotes a field argument, escaping the backslashes and quotes
+// in it, see fieldArgs.
+func quoteFieldArg(s string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(s) + "\""
+}
+
+// hyperlinkField parses instr if it is a HYPERLINK field instruction.
+func hyperlinkField(instr string) (hyperlinkArgs, bool) {
+	args := fieldArgs(instr)
+	if len(args) == 0 || !strings.EqualFold(args[0], "HYPERLINK") {
+		return hyperlinkArgs{}, false
+	}
+	return parseHyperlinkField(instr), true
+}
+
+func parseHyperlinkField(instr string) hyperlinkArgs {
+	a := hyperlinkArgs{}
+	args := fieldArgs(instr)
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case strings.EqualFold(arg, "\\l") && i+1 < len(args):
+			a.anchor = args[i+1]
+			i++
+		case (strings.EqualFold(arg, "\\o") || strings.EqualFold(arg, "\\t")) && i+1 < len(args):
+			a.switches = append(a.switches, arg+" "+quoteFieldArg(args[i+1]))
+			i++
+		case strings.HasPrefix(arg, "\\"):
+			a.switches = append(a.switches, arg)
+		case a.target == "":
+			a.target = arg
+		}
+	}
+	return a
+}
+
+// fieldArgs splits a field instruction into its arguments, removing the quotes
+// of quoted arguments.
+func fieldArgs(instr string) []string {
+	args := []string{}
+	sb := strings.Builder{}
+	inArg, quoted, escaped := false, false, false
+	for _, c := range instr {
+		switch {
+		case escaped:
+			sb.WriteRune(c)
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			if quoted {
+				args = append(args, sb.String())
+				sb.Reset()
+				inArg = false
+			}
+			quoted = !quoted
+		case quoted:
+			sb.WriteRune(c)
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, sb.String())
+				sb.Reset()
+				inArg = false
+			}
+		default:
+			sb.WriteRune(c)
+			inArg = true
+		}
+	}
+	if inArg || quoted {
+		args = append(args, sb.String())
+	}
+	return args
+}
+
+// setInstruction replaces the instruction held by the instruction texts of a
+// field.
+func setInstruction(texts []*wml.CT_Text, instr string) {
+	for i, t := range texts {
+		if i == 0 {
+			t.Content = instr
+		} else {
+			t.Content = ""
+		}
+	}
+}
@@ -0,0 +1,75 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+func TestFieldArgs(t *testing.T) {
+	td := []struct {
+		in  string
+		exp []string
+	}{
+		{`HYPERLINK "http://example.com"`, []string{"HYPERLINK", "http://example.com"}},
+		{`HYPERLINK \l "bm1" \o "say \"hi\""`, []string{"HYPERLINK", `\l`, "bm1", `\o`, `say "hi"`}},
+		{`HYPERLINK "C:\\dir\\file.docx"`, []string{"HYPERLINK", `C:\dir\file.docx`}},
+	}
+	for _, tc := range td {
+		if got := fieldArgs(tc.in); !reflect.DeepEqual(got, tc.exp) {
+			t.Errorf("fieldArgs(%q): expected %q, got %q", tc.in, tc.exp, got)
+		}
+	}
+}
+
+func TestQuoteFieldArgRoundTrip(t *testing.T) {
+	td := []string{
+		"http://example.com/a b",
+		`say "hi"`,
+		`C:\dir\file.docx`,
+		`\\server\share\file.docx`,
+		`trailing\`,
+		`\"`,
+	}
+	for _, s := range td {
+		instr := "HYPERLINK " + quoteFieldArg(s)
+		if got := fieldArgs(instr); len(got) != 2 || got[1] != s {
+			t.Errorf("round trip of %q via %q: expected %q, got %q", s, instr, s, got)
+		}
+	}
+}
+
+func TestLinksInMovedText(t *testing.T) {
+	d := New()
+	p := d.AddParagraph()
+	p.AddRun().addFieldStart(` HYPERLINK "http://example.com" `)
+	p.AddRun().AddText("link")
+	ic := p.AddRun().newIC()
+	ic.RunInnerContentChoice.FldChar = wml.NewCT_FldChar()
+	ic.RunInnerContentChoice.FldChar.FldCharTypeAttr = wml.ST_FldCharTypeEnd
+	moveInto(p)
+
+	links := d.Links()
+	if len(links) != 1 {
+		t.Fatalf("expected the link in the moved text, got %d links", len(links))
+	}
+	if l := links[0]; l.Kind() != LinkKindField || l.Target() != "http://example.com" || l.Text() != "link" {
+		t.Errorf("expected the HYPERLINK field to http://example.com, got %v %q %q", l.Kind(), l.Target(), l.Text())
+	}
+	if n := d.RewriteLinkTargets(func(string) string { return "https://example.org" }); n != 1 {
+		t.Errorf("expected 1 rewritten link, got %d", n)
+	}
+	if links := d.Links(); len(links) != 1 || links[0].Target() != "https://example.org" {
+		t.Errorf("expected the rewritten target in the moved text")
+	}
+}
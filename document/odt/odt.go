@@ -0,0 +1,79 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+/*
+Package odt provides conversion between OpenDocument text files (.odt) and
+document.Document.
+
+Paragraph and character formatting, headings, lists, tables with merged
+cells, images, hyperlinks, bookmarks, footnotes, page setup with the default
+header and footer and the document metadata are converted in both directions.
+
+Example:
+
+	doc, err := document.Open("report.docx")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer doc.Close()
+	if err := odt.SaveToFile(doc, "report.odt"); err != nil {
+		log.Fatal(err)
+	}
+*/
+package odt
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/internal/odf"
+)
+
+// Open opens and reads an OpenDocument text file into a new document.
+func Open(filename string) (*document.Document, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Read(f, fi.Size())
+}
+
+// Read reads an OpenDocument text file from r and builds a new document.
+func Read(r io.ReaderAt, size int64) (*document.Document, error) {
+	pkg, err := odf.ReadPackage(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return newReader(pkg).read()
+}
+
+// Write writes the document d to w as an OpenDocument text file.
+func Write(w io.Writer, d *document.Document) error {
+	pkg, err := newWriter(d).write()
+	if err != nil {
+		return err
+	}
+	return pkg.Write(w)
+}
+
+// SaveToFile writes the document d to an OpenDocument text file at path.
+func SaveToFile(d *document.Document, path string) error {
+	buf := bytes.Buffer{}
+	if err := Write(&buf, d); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
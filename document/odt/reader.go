@@ -0,0 +1,884 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package odt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/unidoc/unioffice/v2/color"
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/internal/odf"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/ofc/sharedTypes"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// maxRepeat limits repeated rows and cells, OpenDocument producers use huge
+// repeat counts for trailing empty cells.
+const maxRepeat = 1024
+
+// target is a part content is added to: the body, a header or footer, a
+// table cell or a note.
+type target struct {
+	addParagraph func() document.Paragraph
+	addTable     func() document.Table
+	addImage     func(common.Image) (common.ImageRef, error)
+}
+
+type charProps struct {
+	bold      bool
+	italic    bool
+	underline wml.ST_Underline
+	strike    bool
+	dstrike   bool
+	size      float64
+	font      string
+	color     string
+	vertAlign sharedTypes.ST_VerticalAlignRun
+	caps      bool
+	smallCaps bool
+	hidden    bool
+}
+
+// inline is the state of a paragraph being filled.
+type inline struct {
+	t        target
+	p        document.Paragraph
+	link     *document.HyperLink
+	run      *document.Run
+	runProps charProps
+	runLink  *document.HyperLink
+	props    charProps
+	// lastSpace is set after white space, OpenDocument collapses white
+	// space sequences and drops leading white space.
+	lastSpace bool
+}
+
+type reader struct {
+	pkg       *odf.Package
+	doc       *document.Document
+	styles    *odf.Styles
+	fontFaces map[string]string
+	lists     map[string]document.NumberingDefinition
+}
+
+func newReader(pkg *odf.Package) *reader {
+	return &reader{pkg: pkg, fontFaces: map[string]string{}, lists: map[string]document.NumberingDefinition{}}
+}
+
+func (r *reader) read() (*document.Document, error) {
+	if r.pkg.MimeType != odf.MimeTypeText && r.pkg.MimeType != odf.MimeTypeText+"-template" {
+		return nil, fmt.Errorf("unsupported mime type %s", r.pkg.MimeType)
+	}
+	content, err := r.pkg.Part("content.xml")
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		return nil, errors.New("missing content.xml")
+	}
+	styles, err := r.pkg.Part("styles.xml")
+	if err != nil {
+		return nil, err
+	}
+	meta, err := r.pkg.Part("meta.xml")
+	if err != nil {
+		return nil, err
+	}
+	r.styles = odf.ParseStyles(styles, content)
+	for _, root := range []*odf.Node{styles, content} {
+		for _, ff := range root.Child(odf.NSOffice, "font-face-decls").ChildrenNamed(odf.NSStyle, "font-face") {
+			family := strings.Trim(ff.AttrValue(odf.NSSVG, "font-family"), "'\"")
+			if family == "" {
+				family = ff.AttrValue(odf.NSStyle, "name")
+			}
+			r.fontFaces[ff.AttrValue(odf.NSStyle, "name")] = family
+		}
+	}
+	r.doc = document.New()
+	odf.ParseMeta(meta).ApplyCore(r.doc.CoreProperties)
+	body := target{addParagraph: r.doc.AddParagraph, addTable: r.doc.AddTable, addImage: r.doc.AddImage}
+	r.blocks(content.Child(odf.NSOffice, "body").Child(odf.NSOffice, "text"), body)
+	r.pageSetup()
+	return r.doc, nil
+}
+
+// pageSetup applies the page layout and the header and footer of the
+// default master page.
+func (r *reader) pageSetup() {
+	var master *odf.Node
+	for _, mp := range r.styles.MasterPages {
+		if master == nil || mp.AttrValue(odf.NSStyle, "name") == "Standard" {
+			master = mp
+		}
+	}
+	if master == nil {
+		return
+	}
+	sec := r.doc.BodySection()
+	hdr, ftr := master.Child(odf.NSStyle, "header"), master.Child(odf.NSStyle, "footer")
+	if hdr != nil {
+		h := r.doc.AddHeader()
+		r.blocks(hdr, target{addParagraph: h.AddParagraph, addTable: h.AddTable, addImage: h.AddImage})
+		sec.SetHeader(h, wml.ST_HdrFtrDefault)
+	}
+	if ftr != nil {
+		f := r.doc.AddFooter()
+		r.blocks(ftr, target{addParagraph: f.AddParagraph, addTable: f.AddTable, addImage: f.AddImage})
+		sec.SetFooter(f, wml.ST_HdrFtrDefault)
+	}
+	layout := r.styles.PageLayouts[master.AttrValue(odf.NSStyle, "page-layout-name")]
+	if layout == nil {
+		return
+	}
+	props := layout.Props["page-layout-properties"]
+	width, okW := odf.ParseLength(props["fo:page-width"])
+	height, okH := odf.ParseLength(props["fo:page-height"])
+	if okW && okH {
+		orient := wml.ST_PageOrientationPortrait
+		if props["style:print-orientation"] == "landscape" {
+			orient = wml.ST_PageOrientationLandscape
+		}
+		sec.SetPageSizeAndOrientation(width, height, orient)
+	}
+	margin := func(name string, def measurement.Distance) measurement.Distance {
+		v, ok := odf.ParseLength(props[name])
+		if !ok {
+			v, ok = odf.ParseLength(props["fo:margin"])
+		}
+		if !ok {
+			return def
+		}
+		return v
+	}
+	top, bottom := margin("fo:margin-top", measurement.Inch), margin("fo:margin-bottom", measurement.Inch)
+	headerDist, footerDist := measurement.Distance(0.5*measurement.Inch), measurement.Distance(0.5*measurement.Inch)
+	// the header and footer of an OpenDocument page are inside the margins,
+	// in OOXML they are placed in the margins
+	if hdr != nil {
+		headerDist = top
+		top += 0.4 * measurement.Inch
+	}
+	if ftr != nil {
+		footerDist = bottom
+		bottom += 0.4 * measurement.Inch
+	}
+	sec.SetPageMargins(top, margin("fo:margin-right", measurement.Inch), bottom, margin("fo:margin-left", measurement.Inch),
+		headerDist, footerDist, 0)
+}
+
+// blocks converts the block level children of n.
+func (r *reader) blocks(n *odf.Node, t target) {
+	if n == nil {
+		return
+	}
+	for _, c := range n.Children {
+		r.block(c, t)
+	}
+}
+
+func (r *reader) block(c *odf.Node, t target) {
+	switch {
+	case c.Is(odf.NSText, "p"), c.Is(odf.NSText, "h"):
+		r.paragraph(c, t, nil, 0)
+	case c.Is(odf.NSText, "list"):
+		r.list(c, t, "", nil, 0)
+	case c.Is(odf.NSTable, "table"):
+		if t.addTable != nil {
+			r.table(c, t)
+		} else {
+			for _, p := range r.flatten(c) {
+				r.paragraph(p, t, nil, 0)
+			}
+		}
+	case c.Is(odf.NSDraw, "frame"), c.Is(odf.NSDraw, "a"):
+		in := &inline{t: t, p: t.addParagraph(), lastSpace: true}
+		r.inlineContent(c, in)
+	case c.Is(odf.NSText, "section"), c.Is(odf.NSText, "index-body"), c.Is(odf.NSText, "table-of-content"),
+		c.Is(odf.NSText, "illustration-index"), c.Is(odf.NSText, "alphabetical-index"), c.Is(odf.NSText, "bibliography"),
+		c.Is(odf.NSText, "user-index"), c.Is(odf.NSText, "object-index"), c.Is(odf.NSText, "table-index"):
+		r.blocks(c, t)
+	}
+}
+
+// flatten returns the paragraphs of a table, used where OOXML does not
+// allow tables.
+func (r *reader) flatten(n *odf.Node) []*odf.Node {
+	var res []*odf.Node
+	for _, c := range n.Children {
+		if c.Is(odf.NSText, "p") || c.Is(odf.NSText, "h") {
+			res = append(res, c)
+			continue
+		}
+		res = append(res, r.flatten(c)...)
+	}
+	return res
+}
+
+func (r *reader) paragraph(n *odf.Node, t target, nd *document.NumberingDefinition, level int) {
+	p := t.addParagraph()
+	styleName := n.AttrValue(odf.NSText, "style-name")
+	heading := 0
+	if n.Is(odf.NSText, "h") {
+		heading = 1
+		if v, err := strconv.Atoi(n.AttrValue(odf.NSText, "outline-level")); err == nil && v >= 1 {
+			heading = v
+		}
+	}
+	title := false
+	for _, st := range r.styles.Ancestors(styleName) {
+		name := strings.ToLower(strings.ReplaceAll(st.DisplayName, "_20_", " "))
+		if strings.HasPrefix(name, "heading ") && heading == 0 {
+			if v, err := strconv.Atoi(strings.TrimPrefix(name, "heading ")); err == nil {
+				heading = v
+			}
+		}
+		if name == "title" {
+			title = true
+		}
+	}
+	switch {
+	case heading > 0:
+		if heading > 9 {
+			heading = 9
+		}
+		p.SetStyle(fmt.Sprintf("Heading%d", heading))
+	case title:
+		p.SetStyle("Title")
+	}
+	// headings take their formatting from the heading styles, only the
+	// direct formatting is applied
+	direct := heading > 0 || title
+	prop := func(kind, attr string) string {
+		if direct {
+			if st := r.styles.Style(styleName); st != nil {
+				return st.Props[kind][attr]
+			}
+			return ""
+		}
+		return r.styles.Lookup(styleName, kind, attr)
+	}
+	r.applyParagraphProps(p, func(attr string) string { return prop("paragraph-properties", attr) })
+	if nd != nil {
+		p.SetNumberingDefinition(*nd)
+		p.SetNumberingLevel(level)
+	}
+	in := &inline{t: t, p: p, lastSpace: true}
+	in.props = r.charProps(charProps{}, func(attr string) string { return prop("text-properties", attr) })
+	r.inlineContent(n, in)
+}
+
+func (r *reader) applyParagraphProps(p document.Paragraph, prop func(string) string) {
+	switch prop("fo:text-align") {
+	case "center":
+		p.SetAlignment(wml.ST_JcCenter)
+	case "end", "right":
+		p.SetAlignment(wml.ST_JcRight)
+	case "justify":
+		p.SetAlignment(wml.ST_JcBoth)
+	}
+	if v, ok := odf.ParseLength(prop("fo:margin-left")); ok && v != 0 {
+		p.SetLeftIndent(v)
+	}
+	if v, ok := odf.ParseLength(prop("fo:margin-right")); ok && v != 0 {
+		p.SetRightIndent(v)
+	}
+	if v, ok := odf.ParseLength(prop("fo:text-indent")); ok {
+		if v > 0 {
+			p.SetFirstLineIndent(v)
+		} else if v < 0 {
+			p.SetHangingIndent(-v)
+		}
+	}
+	if v, ok := odf.ParseLength(prop("fo:margin-top")); ok {
+		p.SetBeforeSpacing(v)
+	}
+	if v, ok := odf.ParseLength(prop("fo:margin-bottom")); ok {
+		p.SetAfterSpacing(v)
+	}
+	if lh := prop("fo:line-height"); lh != "" {
+		if f, ok := odf.ParsePercent(lh); ok {
+			// auto line spacing is measured in 240ths of a line
+			p.SetLineSpacing(measurement.Distance(f*240)*measurement.Twips, wml.ST_LineSpacingRuleAuto)
+		} else if v, ok := odf.ParseLength(lh); ok {
+			p.SetLineSpacing(v, wml.ST_LineSpacingRuleExact)
+		}
+	} else if v, ok := odf.ParseLength(prop("style:line-height-at-least")); ok {
+		p.SetLineSpacing(v, wml.ST_LineSpacingRuleAtLeast)
+	}
+	if prop("fo:break-before") == "page" {
+		p.Properties().SetPageBreakBefore(true)
+	}
+	if prop("fo:keep-with-next") == "always" {
+		p.Properties().SetKeepWithNext(true)
+	}
+	if prop("fo:keep-together") == "always" {
+		p.Properties().SetKeepOnOnePage(true)
+	}
+}
+
+// charProps returns base updated by the text properties returned by prop.
+func (r *reader) charProps(base charProps, prop func(string) string) charProps {
+	cp := base
+	if v := prop("fo:font-weight"); v != "" {
+		n, err := strconv.Atoi(v)
+		cp.bold = v == "bold" || (err == nil && n >= 600)
+	}
+	if v := prop("fo:font-style"); v != "" {
+		cp.italic = v == "italic" || v == "oblique"
+	}
+	if v := prop("style:text-underline-style"); v != "" {
+		cp.underline = wml.ST_UnderlineUnset
+		if v != "none" {
+			cp.underline = wml.ST_UnderlineSingle
+			switch {
+			case prop("style:text-underline-type") == "double":
+				cp.underline = wml.ST_UnderlineDouble
+			case v == "dotted":
+				cp.underline = wml.ST_UnderlineDotted
+			case v == "dash", v == "long-dash":
+				cp.underline = wml.ST_UnderlineDash
+			case v == "wave":
+				cp.underline = wml.ST_UnderlineWave
+			case prop("style:text-underline-mode") == "skip-white-space":
+				cp.underline = wml.ST_UnderlineWords
+			}
+		}
+	}
+	if v := prop("style:text-line-through-style"); v != "" {
+		double := prop("style:text-line-through-type") == "double"
+		cp.strike = v != "none" && !double
+		cp.dstrike = v != "none" && double
+	}
+	if v := prop("fo:font-size"); v != "" {
+		if d, ok := odf.ParseLength(v); ok {
+			cp.size = float64(d / measurement.Point)
+		} else if f, ok := odf.ParsePercent(v); ok && base.size > 0 {
+			cp.size = base.size * f
+		}
+	}
+	if v := prop("style:font-name"); v != "" {
+		if family, ok := r.fontFaces[v]; ok {
+			cp.font = family
+		} else {
+			cp.font = v
+		}
+	} else if v := prop("fo:font-family"); v != "" {
+		cp.font = strings.Trim(v, "'\"")
+	}
+	if v := prop("fo:color"); v != "" {
+		if c, ok := odf.ParseColor(v); ok {
+			cp.color = c
+		}
+	}
+	if v := prop("style:text-position"); v != "" {
+		cp.vertAlign = sharedTypes.ST_VerticalAlignRunUnset
+		f := strings.Fields(v)
+		switch {
+		case f[0] == "super":
+			cp.vertAlign = sharedTypes.ST_VerticalAlignRunSuperscript
+		case f[0] == "sub":
+			cp.vertAlign = sharedTypes.ST_VerticalAlignRunSubscript
+		default:
+			if pct, ok := odf.ParsePercent(f[0]); ok && pct > 0 {
+				cp.vertAlign = sharedTypes.ST_VerticalAlignRunSuperscript
+			} else if ok && pct < 0 {
+				cp.vertAlign = sharedTypes.ST_VerticalAlignRunSubscript
+			}
+		}
+	}
+	if v := prop("fo:font-variant"); v != "" {
+		cp.smallCaps = v == "small-caps"
+	}
+	if v := prop("fo:text-transform"); v != "" {
+		cp.caps = v == "uppercase"
+	}
+	if v := prop("text:display"); v != "" {
+		cp.hidden = v == "none"
+	}
+	return cp
+}
+
+func applyCharProps(rp document.RunProperties, cp charProps) {
+	if cp.bold {
+		rp.SetBold(true)
+	}
+	if cp.italic {
+		rp.SetItalic(true)
+	}
+	if cp.strike {
+		rp.SetStrikeThrough(true)
+	}
+	if cp.dstrike {
+		rp.SetDoubleStrikeThrough(true)
+	}
+	if cp.caps {
+		rp.SetAllCaps(true)
+	}
+	if cp.smallCaps {
+		rp.SetSmallCaps(true)
+	}
+	if cp.hidden {
+		rp.X().Vanish = wml.NewCT_OnOff()
+	}
+	if cp.underline != wml.ST_UnderlineUnset {
+		rp.X().U = wml.NewCT_Underline()
+		rp.X().U.ValAttr = cp.underline
+	}
+	if cp.size > 0 {
+		rp.SetSize(measurement.Distance(cp.size) * measurement.Point)
+	}
+	if cp.font != "" {
+		rp.SetFontFamily(cp.font)
+	}
+	if cp.color != "" {
+		rp.SetColor(color.FromHex(cp.color))
+	}
+	if cp.vertAlign != sharedTypes.ST_VerticalAlignRunUnset {
+		rp.SetVerticalAlignment(cp.vertAlign)
+	}
+}
+
+// currentRun returns a run with the current properties, the last run is
+// reused while the properties do not change.
+func (in *inline) currentRun() document.Run {
+	if in.run != nil && in.runProps == in.props && in.runLink == in.link {
+		return *in.run
+	}
+	var run document.Run
+	if in.link != nil {
+		run = in.link.AddRun()
+	} else {
+		run = in.p.AddRun()
+	}
+	applyCharProps(run.Properties(), in.props)
+	in.run, in.runProps, in.runLink = &run, in.props, in.link
+	return run
+}
+
+func (in *inline) text(s string) {
+	b := strings.Builder{}
+	for _, c := range s {
+		if unicode.IsSpace(c) {
+			if !in.lastSpace {
+				b.WriteByte(' ')
+				in.lastSpace = true
+			}
+			continue
+		}
+		b.WriteRune(c)
+		in.lastSpace = false
+	}
+	if b.Len() > 0 {
+		in.currentRun().AddText(b.String())
+	}
+}
+
+func (r *reader) inlineContent(n *odf.Node, in *inline) {
+	for _, c := range n.Children {
+		if !c.IsElement() {
+			in.text(c.Text)
+			continue
+		}
+		switch {
+		case c.Is(odf.NSText, "s"):
+			count := 1
+			if v, err := strconv.Atoi(c.AttrValue(odf.NSText, "c")); err == nil && v > 0 {
+				count = v
+			}
+			in.currentRun().AddText(strings.Repeat(" ", count))
+			in.lastSpace = false
+		case c.Is(odf.NSText, "tab"):
+			in.currentRun().AddTab()
+			in.lastSpace = true
+		case c.Is(odf.NSText, "line-break"):
+			in.currentRun().AddBreak()
+			in.lastSpace = true
+		case c.Is(odf.NSText, "soft-hyphen"):
+			in.currentRun().AddText("­")
+		case c.Is(odf.NSText, "span"):
+			saved := in.props
+			style := c.AttrValue(odf.NSText, "style-name")
+			in.props = r.charProps(in.props, func(attr string) string {
+				return r.styles.Lookup(style, "text-properties", attr)
+			})
+			r.inlineContent(c, in)
+			in.props = saved
+		case c.Is(odf.NSText, "a"):
+			r.hyperlink(c, in)
+		case c.Is(odf.NSText, "bookmark"), c.Is(odf.NSText, "bookmark-start"):
+			if name := c.AttrValue(odf.NSText, "name"); name != "" {
+				in.p.AddBookmark(name)
+				in.run = nil
+			}
+		case c.Is(odf.NSText, "note"):
+			r.note(c, in)
+		case c.Is(odf.NSText, "page-number"):
+			in.currentRun().AddField(document.FieldCurrentPage)
+		case c.Is(odf.NSText, "page-count"):
+			in.currentRun().AddField(document.FieldNumberOfPages)
+		case c.Is(odf.NSDraw, "frame"):
+			r.frame(c, in)
+		case c.Is(odf.NSDraw, "a"):
+			r.inlineContent(c, in)
+		case c.Is(odf.NSText, "bookmark-end"), c.Is(odf.NSText, "soft-page-break"), c.Is(odf.NSOffice, "annotation"),
+			c.Is(odf.NSText, "reference-mark-start"), c.Is(odf.NSText, "reference-mark-end"), c.Is(odf.NSText, "change"),
+			c.Is(odf.NSText, "change-start"), c.Is(odf.NSText, "change-end"), c.Is(odf.NSDraw, "custom-shape"):
+		default:
+			// fields and other text elements keep their current text
+			r.inlineContent(c, in)
+		}
+	}
+}
+
+func (r *reader) hyperlink(n *odf.Node, in *inline) {
+	href := n.AttrValue(odf.NSXLink, "href")
+	if href == "" {
+		r.inlineContent(n, in)
+		return
+	}
+	hl := in.p.AddHyperLink()
+	if strings.HasPrefix(href, "#") {
+		anchor := strings.TrimPrefix(href, "#")
+		hl.X().AnchorAttr = &anchor
+	} else {
+		hl.SetTarget(href)
+	}
+	saved := in.props
+	in.props.underline = wml.ST_UnderlineSingle
+	if in.props.color == "" {
+		in.props.color = "0563C1"
+	}
+	in.link = &hl
+	r.inlineContent(n, in)
+	in.link, in.props, in.run = nil, saved, nil
+}
+
+func (r *reader) note(n *odf.Node, in *inline) {
+	body := n.Child(odf.NSText, "note-body")
+	var paras []document.Paragraph
+	var addParagraph func() document.Paragraph
+	if n.AttrValue(odf.NSText, "note-class") == "endnote" {
+		en := in.p.AddEndnote("")
+		paras, addParagraph = en.Paragraphs(), en.AddParagraph
+	} else {
+		fn := in.p.AddFootnote("")
+		paras, addParagraph = fn.Paragraphs(), fn.AddParagraph
+	}
+	in.run = nil
+	first := true
+	// the first paragraph of the note already holds the note reference
+	t := target{addParagraph: func() document.Paragraph {
+		if first && len(paras) > 0 {
+			first = false
+			return paras[0]
+		}
+		return addParagraph()
+	}}
+	r.blocks(body, t)
+}
+
+func (r *reader) frame(n *odf.Node, in *inline) {
+	width, _ := odf.ParseLength(n.AttrValue(odf.NSSVG, "width"))
+	height, _ := odf.ParseLength(n.AttrValue(odf.NSSVG, "height"))
+	for _, c := range n.Children {
+		switch {
+		case c.Is(odf.NSDraw, "image"):
+			if in.t.addImage == nil {
+				continue
+			}
+			data, ok := r.pkg.File(strings.TrimPrefix(c.AttrValue(odf.NSXLink, "href"), "./"))
+			if !ok {
+				continue
+			}
+			img, err := common.ImageFromBytes(data)
+			if err != nil {
+				// vector images have a raster replacement in a following
+				// draw:image element
+				continue
+			}
+			ref, err := in.t.addImage(img)
+			if err != nil {
+				continue
+			}
+			inl, err := in.currentRun().AddDrawingInline(ref)
+			if err != nil {
+				continue
+			}
+			if width > 0 && height > 0 {
+				inl.SetSize(width, height)
+			}
+			alt := n.Child(odf.NSSVG, "desc").TextContent()
+			if alt == "" {
+				alt = n.Child(odf.NSSVG, "title").TextContent()
+			}
+			if alt != "" && inl.X().DocPr != nil {
+				inl.X().DocPr.DescrAttr = &alt
+			}
+			in.lastSpace = false
+			return
+		case c.Is(odf.NSDraw, "text-box"):
+			// text boxes are flattened into the paragraph
+			for i, p := range r.flatten(c) {
+				if i > 0 {
+					in.currentRun().AddBreak()
+				}
+				r.inlineContent(p, in)
+			}
+			return
+		}
+	}
+}
+
+// list converts a text:list, nested lists are levels of the numbering of
+// the outermost list.
+func (r *reader) list(n *odf.Node, t target, style string, nd *document.NumberingDefinition, level int) {
+	if s := n.AttrValue(odf.NSText, "style-name"); s != "" && nd == nil {
+		style = s
+	}
+	if nd == nil {
+		def := r.numbering(style, n.AttrValue(odf.NSText, "continue-numbering") == "true" ||
+			n.AttrValue(odf.NSText, "continue-list") != "")
+		nd = &def
+	}
+	if level > 8 {
+		level = 8
+	}
+	for _, item := range n.Children {
+		header := item.Is(odf.NSText, "list-header")
+		if !header && !item.Is(odf.NSText, "list-item") {
+			continue
+		}
+		for _, c := range item.Children {
+			switch {
+			case c.Is(odf.NSText, "p"), c.Is(odf.NSText, "h"):
+				if header {
+					r.paragraph(c, t, nil, 0)
+				} else {
+					r.paragraph(c, t, nd, level)
+					// further paragraphs of the item are not numbered
+					header = true
+				}
+			case c.Is(odf.NSText, "list"):
+				r.list(c, t, style, nd, level+1)
+			default:
+				r.block(c, t)
+			}
+		}
+	}
+}
+
+// numbering returns the numbering definition for a list style, a new
+// definition restarts the numbering.
+func (r *reader) numbering(style string, continued bool) document.NumberingDefinition {
+	if nd, ok := r.lists[style]; ok && continued {
+		return nd
+	}
+	levels := r.styles.ListStyles[style]
+	nd := r.doc.Numbering.AddDefinition()
+	for i := 0; i < 9; i++ {
+		lv := odf.ListLevel{Bullet: true, Start: 1}
+		if i < len(levels) && (levels[i].Bullet || levels[i].NumFormat != "") {
+			lv = levels[i]
+		}
+		l := nd.AddLevel()
+		if lv.Bullet {
+			l.SetFormat(wml.ST_NumberFormatBullet)
+			bullet := lv.BulletChar
+			if bullet == "" {
+				bullet = "•"
+			}
+			l.SetText(bullet)
+		} else {
+			l.SetFormat(numberFormat(lv.NumFormat))
+			l.SetText(fmt.Sprintf("%s%%%d%s", lv.Prefix, i+1, lv.Suffix))
+			l.X().Start.ValAttr = int64(lv.Start)
+		}
+		l.Properties().SetLeftIndent(measurement.Distance(i+1) * 0.5 * measurement.Inch)
+		l.Properties().SetHangingIndent(0.25 * measurement.Inch)
+	}
+	r.lists[style] = nd
+	return nd
+}
+
+func numberFormat(f string) wml.ST_NumberFormat {
+	switch f {
+	case "a":
+		return wml.ST_NumberFormatLowerLetter
+	case "A":
+		return wml.ST_NumberFormatUpperLetter
+	case "i":
+		return wml.ST_NumberFormatLowerRoman
+	case "I":
+		return wml.ST_NumberFormatUpperRoman
+	case "":
+		return wml.ST_NumberFormatNone
+	}
+	return wml.ST_NumberFormatDecimal
+}
+
+type rowSpan struct {
+	remaining int
+	span      int
+}
+
+func (r *reader) table(n *odf.Node, t target) {
+	tbl := t.addTable()
+	tstyle := n.AttrValue(odf.NSTable, "style-name")
+	if v, ok := odf.ParseLength(r.styles.Lookup(tstyle, "table-properties", "style:width")); ok {
+		tbl.Properties().SetWidth(v)
+	}
+	var widths []measurement.Distance
+	var rows []*odf.Node
+	var headers []bool
+	var collect func(*odf.Node, bool)
+	collect = func(n *odf.Node, header bool) {
+		for _, c := range n.Children {
+			switch {
+			case c.Is(odf.NSTable, "table-column"):
+				w, _ := odf.ParseLength(r.styles.Lookup(c.AttrValue(odf.NSTable, "style-name"),
+					"table-column-properties", "style:column-width"))
+				for i := 0; i < repeat(c, "number-columns-repeated"); i++ {
+					widths = append(widths, w)
+				}
+			case c.Is(odf.NSTable, "table-row"):
+				for i := 0; i < repeat(c, "number-rows-repeated"); i++ {
+					rows = append(rows, c)
+					headers = append(headers, header)
+				}
+			case c.Is(odf.NSTable, "table-header-rows"):
+				collect(c, true)
+			case c.Is(odf.NSTable, "table-columns"), c.Is(odf.NSTable, "table-header-columns"),
+				c.Is(odf.NSTable, "table-column-group"), c.Is(odf.NSTable, "table-rows"), c.Is(odf.NSTable, "table-row-group"):
+				collect(c, header)
+			}
+		}
+	}
+	collect(n, false)
+	borders := false
+	spans := map[int]*rowSpan{}
+	for ri, rn := range rows {
+		row := tbl.AddRow()
+		if headers[ri] {
+			row.Properties().SetTblHeader(true)
+		}
+		rs := rn.AttrValue(odf.NSTable, "style-name")
+		if v, ok := odf.ParseLength(r.styles.Lookup(rs, "table-row-properties", "style:row-height")); ok {
+			row.Properties().SetHeight(v, wml.ST_HeightRuleExact)
+		} else if v, ok := odf.ParseLength(r.styles.Lookup(rs, "table-row-properties", "style:min-row-height")); ok {
+			row.Properties().SetHeight(v, wml.ST_HeightRuleAtLeast)
+		}
+		col, skip := 0, 0
+		for _, c := range rn.Children {
+			covered := c.Is(odf.NSTable, "covered-table-cell")
+			if !covered && !c.Is(odf.NSTable, "table-cell") {
+				continue
+			}
+			for i := 0; i < repeat(c, "number-columns-repeated"); i++ {
+				if covered {
+					if skip > 0 {
+						skip--
+						col++
+						continue
+					}
+					cell := row.AddCell()
+					if sp := spans[col]; sp != nil && sp.remaining > 0 {
+						cell.Properties().SetVerticalMerge(wml.ST_MergeContinue)
+						if sp.span > 1 {
+							cell.Properties().SetColumnSpan(sp.span)
+						}
+						skip = sp.span - 1
+						sp.remaining--
+					}
+					cell.AddParagraph()
+					col++
+					continue
+				}
+				cs, rsp := span(c, "number-columns-spanned"), span(c, "number-rows-spanned")
+				cell := row.AddCell()
+				if cs > 1 {
+					cell.Properties().SetColumnSpan(cs)
+				}
+				if rsp > 1 {
+					cell.Properties().SetVerticalMerge(wml.ST_MergeRestart)
+					spans[col] = &rowSpan{remaining: rsp - 1, span: cs}
+				}
+				width := measurement.Distance(0)
+				for j := col; j < col+cs && j < len(widths); j++ {
+					width += widths[j]
+				}
+				if width > 0 {
+					cell.Properties().SetWidth(width)
+				}
+				if r.cellStyle(cell, c.AttrValue(odf.NSTable, "style-name")) {
+					borders = true
+				}
+				added := false
+				r.blocks(c, target{addParagraph: func() document.Paragraph {
+					added = true
+					return cell.AddParagraph()
+				}, addTable: cell.AddTable, addImage: t.addImage})
+				if !added {
+					cell.AddParagraph()
+				}
+				skip = cs - 1
+				col++
+			}
+		}
+	}
+	if borders {
+		tbl.Properties().Borders().SetAll(wml.ST_BorderSingle, color.Auto, 0.5*measurement.Point)
+	}
+}
+
+// cellStyle applies the cell properties of a table cell style and reports
+// whether the cell has borders.
+func (r *reader) cellStyle(cell document.Cell, style string) bool {
+	prop := func(attr string) string { return r.styles.Lookup(style, "table-cell-properties", attr) }
+	if c, ok := odf.ParseColor(prop("fo:background-color")); ok {
+		cell.Properties().SetShading(wml.ST_ShdClear, color.Auto, color.FromHex(c))
+	}
+	switch prop("style:vertical-align") {
+	case "middle":
+		cell.Properties().SetVerticalAlignment(wml.ST_VerticalJcCenter)
+	case "bottom":
+		cell.Properties().SetVerticalAlignment(wml.ST_VerticalJcBottom)
+	}
+	for _, attr := range []string{"fo:border", "fo:border-top", "fo:border-bottom", "fo:border-left", "fo:border-right"} {
+		if v := prop(attr); v != "" && v != "none" {
+			return true
+		}
+	}
+	return false
+}
+
+func repeat(n *odf.Node, attr string) int {
+	v, err := strconv.Atoi(n.AttrValue(odf.NSTable, attr))
+	if err != nil || v < 1 {
+		return 1
+	}
+	if v > maxRepeat {
+		return maxRepeat
+	}
+	return v
+}
+
+func span(n *odf.Node, attr string) int {
+	v, err := strconv.Atoi(n.AttrValue(odf.NSTable, attr))
+	if err != nil || v < 1 {
+		return 1
+	}
+	return v
+}
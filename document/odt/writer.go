@@ -0,0 +1,1074 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package odt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/internal/odf"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml/picture"
+	"github.com/unidoc/unioffice/v2/schema/soo/ofc/sharedTypes"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+type partKind byte
+
+const (
+	partBody partKind = iota
+	partHeader
+	partFooter
+)
+
+type listState struct {
+	numID int64
+	depth int
+}
+
+type listKey struct {
+	set   *odf.StyleSet
+	numID int64
+}
+
+// field is a complex field being written, only the page number fields are
+// converted to their OpenDocument elements, other fields keep their result.
+type field struct {
+	instr    strings.Builder
+	inResult bool
+	skip     bool
+}
+
+// writer converts a document to an OpenDocument text package.
+type writer struct {
+	doc *document.Document
+	pkg *odf.Package
+	// x and styles are the output and automatic style set of the part
+	// currently written, the header and footer go to styles.xml.
+	x          *odf.Writer
+	styles     *odf.StyleSet
+	part       partKind
+	content    *odf.StyleSet
+	common     *odf.StyleSet
+	list       listState
+	listStyles map[listKey]string
+	seenLists  map[int64]bool
+	bookmarks  map[int64]string
+	fields     []*field
+	headings   map[string]int
+	pageBreak  bool
+	notes      int
+	tables     int
+	frames     int
+}
+
+func newWriter(d *document.Document) *writer {
+	return &writer{doc: d, content: odf.NewStyleSet(), common: odf.NewStyleSet(),
+		listStyles: map[listKey]string{}, seenLists: map[int64]bool{}, bookmarks: map[int64]string{},
+		headings: map[string]int{}}
+}
+
+func (w *writer) write() (*odf.Package, error) {
+	w.pkg = odf.NewPackage(odf.MimeTypeText)
+	body := odf.NewFragmentWriter()
+	w.x, w.styles, w.part = body, w.content, partBody
+	if b := w.doc.X().Body; b != nil {
+		for _, ble := range b.EG_BlockLevelElts {
+			w.blockContent(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+		}
+	}
+	w.closeLists()
+	styles := w.stylesPart()
+
+	content := odf.NewWriter()
+	content.StartRoot("office:document-content")
+	content.Start("office:automatic-styles")
+	content.Raw(w.content.Bytes())
+	content.End()
+	content.Start("office:body")
+	content.Start("office:text")
+	content.Raw(body.Bytes())
+	w.pkg.Add("content.xml", content.Bytes(), "text/xml")
+	w.pkg.Add("styles.xml", styles, "text/xml")
+	w.pkg.Add("meta.xml", odf.MetaFromCore(w.doc.CoreProperties).Bytes(), "text/xml")
+	return w.pkg, nil
+}
+
+// stylesPart builds styles.xml with the common paragraph styles, the page
+// layout and the master page holding the default header and footer.
+func (w *writer) stylesPart() []byte {
+	sec := w.doc.BodySection()
+	master := odf.NewFragmentWriter()
+	hasHeader, hasFooter := false, false
+	if hdr, ok := sec.GetHeader(wml.ST_HdrFtrDefault); ok && hdr.X() != nil {
+		hasHeader = true
+		w.headerFooter(master, "style:header", partHeader, hdr.X().EG_BlockLevelElts)
+	}
+	if ftr, ok := sec.GetFooter(wml.ST_HdrFtrDefault); ok && ftr.X() != nil {
+		hasFooter = true
+		w.headerFooter(master, "style:footer", partFooter, ftr.X().EG_BlockLevelElts)
+	}
+
+	x := odf.NewWriter()
+	x.StartRoot("office:document-styles")
+	x.Start("office:styles")
+	var defaults []string
+	if st := w.doc.Styles.X(); st != nil && st.DocDefaults != nil && st.DocDefaults.RPrDefault != nil {
+		defaults = textProps(st.DocDefaults.RPrDefault.RPr).Attrs
+	}
+	x.Start("style:default-style", "style:family", "paragraph")
+	x.Empty("style:text-properties", append([]string{"fo:font-size", "11pt"}, defaults...)...)
+	x.End()
+	x.Empty("style:style", "style:name", "Standard", "style:family", "paragraph", "style:class", "text")
+	x.Start("style:style", "style:name", "Heading", "style:family", "paragraph", "style:parent-style-name", "Standard",
+		"style:class", "text")
+	x.Empty("style:paragraph-properties", "fo:margin-top", "0.1665in", "fo:margin-bottom", "0.0835in", "fo:keep-with-next", "always")
+	x.Empty("style:text-properties", "fo:font-weight", "bold")
+	x.End()
+	sizes := []string{"16pt", "14pt", "13pt", "12pt", "11pt", "11pt", "11pt", "11pt", "11pt"}
+	for i, sz := range sizes {
+		x.Start("style:style", "style:name", fmt.Sprintf("Heading_20_%d", i+1), "style:display-name", fmt.Sprintf("Heading %d", i+1),
+			"style:family", "paragraph", "style:parent-style-name", "Heading", "style:default-outline-level", strconv.Itoa(i+1),
+			"style:class", "text")
+		x.Empty("style:text-properties", "fo:font-size", sz)
+		x.End()
+	}
+	x.Start("style:style", "style:name", "Title", "style:family", "paragraph", "style:parent-style-name", "Heading",
+		"style:class", "chapter")
+	x.Empty("style:paragraph-properties", "fo:text-align", "center")
+	x.Empty("style:text-properties", "fo:font-size", "28pt")
+	x.End()
+	x.End()
+
+	x.Start("office:automatic-styles")
+	x.Start("style:page-layout", "style:name", "pm1")
+	x.Empty("style:page-layout-properties", w.pageLayout(hasHeader, hasFooter)...)
+	if hasHeader {
+		x.Start("style:header-style")
+		x.Empty("style:header-footer-properties", "fo:min-height", "0in", "fo:margin-bottom", "0.1in")
+		x.End()
+	}
+	if hasFooter {
+		x.Start("style:footer-style")
+		x.Empty("style:header-footer-properties", "fo:min-height", "0in", "fo:margin-top", "0.1in")
+		x.End()
+	}
+	x.End()
+	x.Raw(w.common.Bytes())
+	x.End()
+
+	x.Start("office:master-styles")
+	x.Start("style:master-page", "style:name", "Standard", "style:page-layout-name", "pm1")
+	x.Raw(master.Bytes())
+	return x.Bytes()
+}
+
+func (w *writer) headerFooter(out *odf.Writer, elem string, part partKind, bles []*wml.EG_BlockLevelElts) {
+	savedX, savedStyles, savedList := w.x, w.styles, w.list
+	w.x, w.styles, w.part, w.list = out, w.common, part, listState{}
+	w.x.Start(elem)
+	for _, ble := range bles {
+		w.blockContent(ble.BlockLevelEltsChoice.EG_ContentBlockContent)
+	}
+	w.closeLists()
+	w.x.End()
+	w.x, w.styles, w.part, w.list = savedX, savedStyles, partBody, savedList
+}
+
+func (w *writer) pageLayout(hasHeader, hasFooter bool) []string {
+	attrs := []string{}
+	sect := w.doc.X().Body.SectPr
+	if sect == nil {
+		return attrs
+	}
+	if sz := sect.PgSz; sz != nil {
+		if v, ok := twips(sz.WAttr); ok {
+			attrs = append(attrs, "fo:page-width", odf.Length(measurement.Distance(v)*measurement.Twips))
+		}
+		if v, ok := twips(sz.HAttr); ok {
+			attrs = append(attrs, "fo:page-height", odf.Length(measurement.Distance(v)*measurement.Twips))
+		}
+		if sz.OrientAttr == wml.ST_PageOrientationLandscape {
+			attrs = append(attrs, "style:print-orientation", "landscape")
+		} else {
+			attrs = append(attrs, "style:print-orientation", "portrait")
+		}
+	}
+	if m := sect.PgMar; m != nil {
+		tw := func(v int64) string { return odf.Length(measurement.Distance(v) * measurement.Twips) }
+		top, bottom := int64(0), int64(0)
+		if m.TopAttr.Int64 != nil {
+			top = *m.TopAttr.Int64
+		}
+		if m.BottomAttr.Int64 != nil {
+			bottom = *m.BottomAttr.Int64
+		}
+		// in OpenDocument the header is inside the page margins
+		if v, ok := twips(&m.HeaderAttr); ok && hasHeader && int64(v) < top {
+			top = int64(v)
+		}
+		if v, ok := twips(&m.FooterAttr); ok && hasFooter && int64(v) < bottom {
+			bottom = int64(v)
+		}
+		attrs = append(attrs, "fo:margin-top", tw(top), "fo:margin-bottom", tw(bottom))
+		if v, ok := twips(&m.LeftAttr); ok {
+			attrs = append(attrs, "fo:margin-left", tw(int64(v)))
+		}
+		if v, ok := twips(&m.RightAttr); ok {
+			attrs = append(attrs, "fo:margin-right", tw(int64(v)))
+		}
+	}
+	return attrs
+}
+
+func (w *writer) blockContent(cbc []*wml.EG_ContentBlockContent) {
+	for _, c := range cbc {
+		if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			w.blockContent(sdt.SdtContent.EG_ContentBlockContent)
+		}
+		for _, p := range c.ContentBlockContentChoice.P {
+			w.paragraph(p)
+		}
+		for _, tbl := range c.ContentBlockContentChoice.Tbl {
+			w.table(tbl)
+		}
+	}
+}
+
+func (w *writer) paragraph(p *wml.CT_P) {
+	if np := numPr(p); np != nil {
+		ilvl := int64(0)
+		if np.Ilvl != nil {
+			ilvl = np.Ilvl.ValAttr
+		}
+		w.enterList(np.NumId.ValAttr, ilvl)
+	} else {
+		w.closeLists()
+	}
+	parent, level := "Standard", 0
+	if p.PPr != nil && p.PPr.PStyle != nil {
+		level = w.headingLevel(p.PPr.PStyle.ValAttr)
+		if level < 0 {
+			parent = "Title"
+		}
+	}
+	if level == 0 && p.PPr != nil && p.PPr.OutlineLvl != nil && p.PPr.OutlineLvl.ValAttr < 9 {
+		level = int(p.PPr.OutlineLvl.ValAttr) + 1
+	}
+	if level > 0 {
+		parent = fmt.Sprintf("Heading_20_%d", level)
+	}
+	props := paragraphProps(p.PPr)
+	if w.pageBreak {
+		props.Attrs = append(props.Attrs, "fo:break-before", "page")
+		w.pageBreak = false
+	}
+	style := w.styles.Add("P", "paragraph", parent, props)
+	if level > 0 {
+		w.x.Start("text:h", "text:style-name", style, "text:outline-level", strconv.Itoa(level))
+	} else {
+		w.x.Start("text:p", "text:style-name", style)
+	}
+	w.paragraphContent(p.EG_PContent)
+	w.x.End()
+}
+
+func numPr(p *wml.CT_P) *wml.CT_NumPr {
+	if p.PPr == nil || p.PPr.NumPr == nil || p.PPr.NumPr.NumId == nil || p.PPr.NumPr.NumId.ValAttr == 0 {
+		return nil
+	}
+	return p.PPr.NumPr
+}
+
+// headingLevel returns the heading level of a paragraph style, zero for
+// regular styles and -1 for the title style.
+func (w *writer) headingLevel(styleID string) int {
+	if lvl, ok := w.headings[styleID]; ok {
+		return lvl
+	}
+	name := strings.ToLower(w.doc.GetStyleByID(styleID).Name())
+	if name == "" {
+		name = strings.ToLower(styleID)
+	}
+	name = strings.ReplaceAll(name, " ", "")
+	lvl := 0
+	switch {
+	case name == "title":
+		lvl = -1
+	case strings.HasPrefix(name, "heading"):
+		if v, err := strconv.Atoi(strings.TrimPrefix(name, "heading")); err == nil && v >= 1 && v <= 9 {
+			lvl = v
+		}
+	}
+	w.headings[styleID] = lvl
+	return lvl
+}
+
+// enterList opens or continues the text:list elements so that the next
+// paragraph is a list item at level ilvl of the list numID.
+func (w *writer) enterList(numID, ilvl int64) {
+	if w.list.depth > 0 && w.list.numID != numID {
+		w.closeLists()
+	}
+	target := int(ilvl) + 1
+	if w.list.depth == 0 {
+		attrs := []string{"text:style-name", w.listStyle(numID)}
+		if w.seenLists[numID] {
+			attrs = append(attrs, "text:continue-numbering", "true")
+		}
+		w.seenLists[numID] = true
+		w.x.Start("text:list", attrs...)
+		w.x.Start("text:list-item")
+		w.list = listState{numID: numID, depth: 1}
+	} else if w.list.depth >= target {
+		for w.list.depth > target {
+			w.x.End()
+			w.x.End()
+			w.list.depth--
+		}
+		w.x.End()
+		w.x.Start("text:list-item")
+	}
+	for w.list.depth < target {
+		w.x.Start("text:list")
+		w.x.Start("text:list-item")
+		w.list.depth++
+	}
+}
+
+func (w *writer) closeLists() {
+	for w.list.depth > 0 {
+		w.x.End()
+		w.x.End()
+		w.list.depth--
+	}
+}
+
+// listStyle returns the name of the list style for a numbering instance,
+// writing its definition to the current automatic styles on first use.
+func (w *writer) listStyle(numID int64) string {
+	key := listKey{w.styles, numID}
+	if name, ok := w.listStyles[key]; ok {
+		return name
+	}
+	name := fmt.Sprintf("L%d", numID)
+	w.listStyles[key] = name
+	x := odf.NewFragmentWriter()
+	x.Start("text:list-style", "style:name", name)
+	for ilvl := int64(0); ilvl < 9; ilvl++ {
+		lvl := w.doc.GetNumberingLevelByIds(numID, ilvl).X()
+		level := strconv.Itoa(int(ilvl) + 1)
+		indent := measurement.Distance(ilvl+1) * 0.25 * measurement.Inch
+		hanging := measurement.Distance(0.25 * measurement.Inch)
+		if lvl != nil && lvl.PPr != nil && lvl.PPr.Ind != nil {
+			if v, ok := signedTwips(lvl.PPr.Ind.LeftAttr); ok {
+				indent = measurement.Distance(v) * measurement.Twips
+			}
+			if v, ok := twips(lvl.PPr.Ind.HangingAttr); ok {
+				hanging = measurement.Distance(v) * measurement.Twips
+			}
+		}
+		text := ""
+		if lvl != nil && lvl.LvlText != nil && lvl.LvlText.ValAttr != nil {
+			text = *lvl.LvlText.ValAttr
+		}
+		if lvl == nil || lvl.NumFmt == nil || lvl.NumFmt.ValAttr == wml.ST_NumberFormatBullet {
+			x.Start("text:list-level-style-bullet", "text:level", level, "text:bullet-char", bulletChar(text))
+		} else {
+			start := "1"
+			if lvl.Start != nil {
+				start = strconv.FormatInt(lvl.Start.ValAttr, 10)
+			}
+			prefix, suffix, display := splitLevelText(text, ilvl)
+			x.Start("text:list-level-style-number", "text:level", level, "style:num-format", numFormat(lvl.NumFmt.ValAttr),
+				"style:num-prefix", prefix, "style:num-suffix", suffix, "text:start-value", start, "text:display-levels", display)
+		}
+		x.Start("style:list-level-properties", "text:list-level-position-and-space-mode", "label-alignment")
+		x.Empty("style:list-level-label-alignment", "text:label-followed-by", "listtab",
+			"text:list-tab-stop-position", odf.Length(indent), "fo:text-indent", odf.Length(-hanging), "fo:margin-left", odf.Length(indent))
+		x.End()
+		x.End()
+	}
+	x.End()
+	w.styles.Raw(x.Bytes())
+	return name
+}
+
+// bulletChar returns the bullet of a level text, symbol font bullets in the
+// private use area are replaced by a regular bullet.
+func bulletChar(text string) string {
+	for _, r := range text {
+		if r >= 0xE000 && r <= 0xF8FF || r == '%' {
+			break
+		}
+		return string(r)
+	}
+	return "•"
+}
+
+// splitLevelText splits a level text such as "%1.%2)" into the prefix, the
+// suffix and the number of displayed levels.
+func splitLevelText(text string, ilvl int64) (prefix, suffix, display string) {
+	first := strings.Index(text, "%")
+	if first < 0 {
+		return "", text, ""
+	}
+	last := strings.LastIndex(text, "%")
+	prefix = text[:first]
+	if last+2 <= len(text) {
+		suffix = text[last+2:]
+	}
+	n := strings.Count(text, "%")
+	if n > 1 && int64(n) <= ilvl+1 {
+		display = strconv.Itoa(n)
+	}
+	return prefix, suffix, display
+}
+
+func numFormat(f wml.ST_NumberFormat) string {
+	switch f {
+	case wml.ST_NumberFormatLowerLetter:
+		return "a"
+	case wml.ST_NumberFormatUpperLetter:
+		return "A"
+	case wml.ST_NumberFormatLowerRoman:
+		return "i"
+	case wml.ST_NumberFormatUpperRoman:
+		return "I"
+	case wml.ST_NumberFormatNone:
+		return ""
+	}
+	return "1"
+}
+
+func paragraphProps(ppr *wml.CT_PPr) odf.Props {
+	props := odf.Props{Element: "style:paragraph-properties"}
+	if ppr == nil {
+		return props
+	}
+	add := func(k, v string) { props.Attrs = append(props.Attrs, k, v) }
+	tw := func(v int64) string { return odf.Length(measurement.Distance(v) * measurement.Twips) }
+	if ppr.Jc != nil {
+		switch ppr.Jc.ValAttr {
+		case wml.ST_JcCenter:
+			add("fo:text-align", "center")
+		case wml.ST_JcRight, wml.ST_JcEnd:
+			add("fo:text-align", "end")
+		case wml.ST_JcBoth, wml.ST_JcDistribute:
+			add("fo:text-align", "justify")
+		case wml.ST_JcLeft, wml.ST_JcStart:
+			add("fo:text-align", "start")
+		}
+	}
+	if ind := ppr.Ind; ind != nil {
+		if v, ok := signedTwips(ind.LeftAttr); ok {
+			add("fo:margin-left", tw(v))
+		} else if v, ok := signedTwips(ind.StartAttr); ok {
+			add("fo:margin-left", tw(v))
+		}
+		if v, ok := signedTwips(ind.RightAttr); ok {
+			add("fo:margin-right", tw(v))
+		} else if v, ok := signedTwips(ind.EndAttr); ok {
+			add("fo:margin-right", tw(v))
+		}
+		if v, ok := twips(ind.FirstLineAttr); ok {
+			add("fo:text-indent", tw(int64(v)))
+		} else if v, ok := twips(ind.HangingAttr); ok {
+			add("fo:text-indent", tw(-int64(v)))
+		}
+	}
+	if sp := ppr.Spacing; sp != nil {
+		if v, ok := twips(sp.BeforeAttr); ok {
+			add("fo:margin-top", tw(int64(v)))
+		}
+		if v, ok := twips(sp.AfterAttr); ok {
+			add("fo:margin-bottom", tw(int64(v)))
+		}
+		if v, ok := signedTwips(sp.LineAttr); ok && v > 0 {
+			switch sp.LineRuleAttr {
+			case wml.ST_LineSpacingRuleExact:
+				add("fo:line-height", tw(v))
+			case wml.ST_LineSpacingRuleAtLeast:
+				add("style:line-height-at-least", tw(v))
+			default:
+				add("fo:line-height", strconv.FormatInt(v*100/240, 10)+"%")
+			}
+		}
+	}
+	if onOff(ppr.PageBreakBefore) {
+		add("fo:break-before", "page")
+	}
+	if onOff(ppr.KeepNext) {
+		add("fo:keep-with-next", "always")
+	}
+	if onOff(ppr.KeepLines) {
+		add("fo:keep-together", "always")
+	}
+	if shd := ppr.Shd; shd != nil && shd.FillAttr != nil && shd.FillAttr.ST_HexColorRGB != nil {
+		add("fo:background-color", odf.HexColor(*shd.FillAttr.ST_HexColorRGB))
+	}
+	return props
+}
+
+func textProps(rpr *wml.CT_RPr) odf.Props {
+	props := odf.Props{Element: "style:text-properties"}
+	if rpr == nil {
+		return props
+	}
+	add := func(kv ...string) { props.Attrs = append(props.Attrs, kv...) }
+	if onOff(rpr.B) {
+		add("fo:font-weight", "bold", "style:font-weight-complex", "bold")
+	}
+	if onOff(rpr.I) {
+		add("fo:font-style", "italic", "style:font-style-complex", "italic")
+	}
+	if u := rpr.U; u != nil && u.ValAttr != wml.ST_UnderlineNone && u.ValAttr != wml.ST_UnderlineUnset {
+		style, typ := "solid", ""
+		switch u.ValAttr {
+		case wml.ST_UnderlineDouble:
+			typ = "double"
+		case wml.ST_UnderlineDotted:
+			style = "dotted"
+		case wml.ST_UnderlineDash:
+			style = "dash"
+		case wml.ST_UnderlineWave:
+			style = "wave"
+		}
+		add("style:text-underline-style", style, "style:text-underline-type", typ,
+			"style:text-underline-width", "auto", "style:text-underline-color", "font-color")
+		if u.ValAttr == wml.ST_UnderlineWords {
+			add("style:text-underline-mode", "skip-white-space")
+		}
+	}
+	if onOff(rpr.Strike) {
+		add("style:text-line-through-style", "solid")
+	}
+	if onOff(rpr.Dstrike) {
+		add("style:text-line-through-style", "solid", "style:text-line-through-type", "double")
+	}
+	if sz := rpr.Sz; sz != nil && sz.ValAttr.ST_UnsignedDecimalNumber != nil {
+		pt := strconv.FormatFloat(float64(*sz.ValAttr.ST_UnsignedDecimalNumber)/2, 'f', -1, 64) + "pt"
+		add("fo:font-size", pt, "style:font-size-complex", pt)
+	}
+	if f := rpr.RFonts; f != nil {
+		name := ""
+		switch {
+		case f.AsciiAttr != nil:
+			name = *f.AsciiAttr
+		case f.HAnsiAttr != nil:
+			name = *f.HAnsiAttr
+		case f.CsAttr != nil:
+			name = *f.CsAttr
+		}
+		if name != "" {
+			if strings.ContainsAny(name, " ,") {
+				name = "'" + name + "'"
+			}
+			add("fo:font-family", name)
+		}
+	}
+	if c := rpr.Color; c != nil && c.ValAttr.ST_HexColorRGB != nil {
+		add("fo:color", odf.HexColor(*c.ValAttr.ST_HexColorRGB))
+	}
+	if onOff(rpr.SmallCaps) {
+		add("fo:font-variant", "small-caps")
+	}
+	if onOff(rpr.Caps) {
+		add("fo:text-transform", "uppercase")
+	}
+	if onOff(rpr.Vanish) {
+		add("text:display", "none")
+	}
+	if va := rpr.VertAlign; va != nil {
+		switch va.ValAttr {
+		case sharedTypes.ST_VerticalAlignRunSuperscript:
+			add("style:text-position", "super 58%")
+		case sharedTypes.ST_VerticalAlignRunSubscript:
+			add("style:text-position", "sub 58%")
+		}
+	}
+	if hl := rpr.Highlight; hl != nil {
+		if c, ok := highlightColors[hl.ValAttr.String()]; ok {
+			add("fo:background-color", c)
+		}
+	}
+	return props
+}
+
+var highlightColors = map[string]string{
+	"black": "#000000", "blue": "#0000ff", "cyan": "#00ffff", "green": "#00ff00", "magenta": "#ff00ff",
+	"red": "#ff0000", "yellow": "#ffff00", "white": "#ffffff", "darkBlue": "#000080", "darkCyan": "#008080",
+	"darkGreen": "#008000", "darkMagenta": "#800080", "darkRed": "#800000", "darkYellow": "#808000",
+	"darkGray": "#808080", "lightGray": "#c0c0c0",
+}
+
+func (w *writer) paragraphContent(pcs []*wml.EG_PContent) {
+	for _, pc := range pcs {
+		w.runContent(pc.PContentChoice.EG_ContentRunContent)
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			w.hyperlink(hl)
+		}
+		for _, fs := range pc.PContentChoice.FldSimple {
+			if !w.simpleField(fs.InstrAttr) {
+				w.paragraphContent(fs.EG_PContent)
+			}
+		}
+	}
+}
+
+func (w *writer) hyperlink(hl *wml.CT_Hyperlink) {
+	href := ""
+	if hl.IdAttr != nil {
+		href = w.doc.GetTargetByRelId(*hl.IdAttr)
+	}
+	if hl.AnchorAttr != nil {
+		href += "#" + *hl.AnchorAttr
+	}
+	if href == "" {
+		w.runContent(hl.PContentChoice.EG_ContentRunContent)
+		return
+	}
+	w.x.Start("text:a", "xlink:type", "simple", "xlink:href", href)
+	w.runContent(hl.PContentChoice.EG_ContentRunContent)
+	w.x.End()
+}
+
+// simpleField writes the OpenDocument element of a page number field and
+// reports whether it did.
+func (w *writer) simpleField(instr string) bool {
+	f := strings.Fields(instr)
+	if len(f) == 0 {
+		return false
+	}
+	switch strings.ToUpper(f[0]) {
+	case "PAGE":
+		w.x.Start("text:page-number", "text:select-page", "current")
+		w.x.Text("1")
+		w.x.End()
+		return true
+	case "NUMPAGES":
+		w.x.Start("text:page-count")
+		w.x.Text("1")
+		w.x.End()
+		return true
+	}
+	return false
+}
+
+func (w *writer) runContent(crcs []*wml.EG_ContentRunContent) {
+	for _, crc := range crcs {
+		if r := crc.ContentRunContentChoice.R; r != nil {
+			w.run(r)
+		}
+		if sdt := crc.ContentRunContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			w.paragraphContent(sdt.SdtContent.EG_PContent)
+		}
+		for _, rle := range crc.ContentRunContentChoice.EG_RunLevelElts {
+			for _, rme := range rle.RunLevelEltsChoice.EG_RangeMarkupElements {
+				c := rme.RangeMarkupElementsChoice
+				if bs := c.BookmarkStart; bs != nil && bs.NameAttr != "" && bs.NameAttr != "_GoBack" {
+					w.bookmarks[bs.IdAttr] = bs.NameAttr
+					w.x.Empty("text:bookmark-start", "text:name", bs.NameAttr)
+				}
+				if be := c.BookmarkEnd; be != nil {
+					if name, ok := w.bookmarks[be.IdAttr]; ok {
+						w.x.Empty("text:bookmark-end", "text:name", name)
+						delete(w.bookmarks, be.IdAttr)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (w *writer) activeField() *field {
+	if len(w.fields) == 0 {
+		return nil
+	}
+	return w.fields[len(w.fields)-1]
+}
+
+func (w *writer) run(r *wml.CT_R) {
+	open := false
+	ensureOpen := func() {
+		if open {
+			return
+		}
+		open = true
+		if style := w.styles.Add("T", "text", "", textProps(r.RPr)); style != "" {
+			w.x.Start("text:span", "text:style-name", style)
+		} else {
+			w.x.Start("text:span")
+		}
+	}
+	for _, ric := range r.EG_RunInnerContent {
+		c := ric.RunInnerContentChoice
+		if c.FldChar != nil {
+			switch c.FldChar.FldCharTypeAttr {
+			case wml.ST_FldCharTypeBegin:
+				w.fields = append(w.fields, &field{})
+			case wml.ST_FldCharTypeSeparate:
+				if f := w.activeField(); f != nil {
+					f.inResult = true
+					ensureOpen()
+					f.skip = w.simpleField(f.instr.String())
+				}
+			case wml.ST_FldCharTypeEnd:
+				if len(w.fields) > 0 {
+					w.fields = w.fields[:len(w.fields)-1]
+				}
+			}
+			continue
+		}
+		if f := w.activeField(); f != nil {
+			if c.InstrText != nil && !f.inResult {
+				f.instr.WriteString(c.InstrText.Content)
+			}
+			if !f.inResult || f.skip {
+				continue
+			}
+		}
+		switch {
+		case c.T != nil:
+			ensureOpen()
+			w.x.WriteText(c.T.Content)
+		case c.Tab != nil:
+			ensureOpen()
+			w.x.Empty("text:tab")
+		case c.Br != nil:
+			if c.Br.TypeAttr == wml.ST_BrTypePage {
+				// OpenDocument has no inline page breaks, the break is moved to
+				// the start of the next paragraph
+				w.pageBreak = true
+				continue
+			}
+			ensureOpen()
+			w.x.Empty("text:line-break")
+		case c.Cr != nil:
+			ensureOpen()
+			w.x.Empty("text:line-break")
+		case c.NoBreakHyphen != nil:
+			ensureOpen()
+			w.x.Text("‑")
+		case c.SoftHyphen != nil:
+			ensureOpen()
+			w.x.Empty("text:soft-hyphen")
+		case c.FootnoteReference != nil:
+			ensureOpen()
+			w.note("footnote", w.doc.Footnote(c.FootnoteReference.IdAttr).Paragraphs())
+		case c.EndnoteReference != nil:
+			ensureOpen()
+			w.note("endnote", w.doc.Endnote(c.EndnoteReference.IdAttr).Paragraphs())
+		case c.Drawing != nil:
+			ensureOpen()
+			for _, dc := range c.Drawing.DrawingChoice {
+				if in := dc.Inline; in != nil && in.Graphic != nil && in.Graphic.GraphicData != nil {
+					w.frame(in.Graphic.GraphicData.Any, in.Extent.CxAttr, in.Extent.CyAttr, in.DocPr, "as-char")
+				}
+				if an := dc.Anchor; an != nil && an.Graphic != nil && an.Graphic.GraphicData != nil && an.Extent != nil {
+					w.frame(an.Graphic.GraphicData.Any, an.Extent.CxAttr, an.Extent.CyAttr, an.DocPr, "char")
+				}
+			}
+		}
+	}
+	if open {
+		w.x.End()
+	}
+}
+
+func (w *writer) note(class string, paras []document.Paragraph) {
+	w.notes++
+	savedList, savedBreak := w.list, w.pageBreak
+	w.list, w.pageBreak = listState{}, false
+	w.x.Start("text:note", "text:id", fmt.Sprintf("ftn%d", w.notes), "text:note-class", class)
+	w.x.Start("text:note-citation")
+	w.x.Text(strconv.Itoa(w.notes))
+	w.x.End()
+	w.x.Start("text:note-body")
+	for _, p := range paras {
+		w.paragraph(p.X())
+	}
+	w.closeLists()
+	w.x.End()
+	w.x.End()
+	w.list, w.pageBreak = savedList, savedBreak
+}
+
+func (w *writer) frame(any []interface{}, cx, cy int64, docPr *dml.CT_NonVisualDrawingProps, anchor string) {
+	for _, a := range any {
+		pic, ok := a.(*picture.Pic)
+		if !ok || pic.BlipFill == nil || pic.BlipFill.Blip == nil || pic.BlipFill.Blip.EmbedAttr == nil {
+			continue
+		}
+		data, format, ok := w.image(*pic.BlipFill.Blip.EmbedAttr)
+		if !ok {
+			continue
+		}
+		w.frames++
+		href := w.pkg.AddPicture(data, format)
+		name := fmt.Sprintf("Image%d", w.frames)
+		width := odf.Length(measurement.Distance(measurement.FromEMU(cx)))
+		height := odf.Length(measurement.Distance(measurement.FromEMU(cy)))
+		w.x.Start("draw:frame", "draw:name", name, "text:anchor-type", anchor, "svg:width", width, "svg:height", height)
+		w.x.Empty("draw:image", "xlink:href", href, "xlink:type", "simple", "xlink:show", "embed", "xlink:actuate", "onLoad")
+		if docPr != nil && docPr.DescrAttr != nil && *docPr.DescrAttr != "" {
+			w.x.Start("svg:desc")
+			w.x.Text(*docPr.DescrAttr)
+			w.x.End()
+		}
+		w.x.End()
+	}
+}
+
+// image returns the data and format of an image referenced from the part
+// being written.
+func (w *writer) image(relID string) ([]byte, string, bool) {
+	if w.part == partBody {
+		ref, ok := w.doc.GetImageByRelID(relID)
+		if !ok {
+			return nil, "", false
+		}
+		data, err := odf.ImageData(ref.Data(), ref.Path())
+		if err != nil {
+			return nil, "", false
+		}
+		return data, ref.Format(), true
+	}
+	img, err := w.doc.GetHeaderFooterImageObjByRelId(relID, w.part == partHeader, w.part == partFooter)
+	if err != nil {
+		return nil, "", false
+	}
+	data, err := odf.ImageData(img.Data, img.Path)
+	if err != nil {
+		return nil, "", false
+	}
+	return data, img.Format, true
+}
+
+type gridCell struct {
+	tc   *wml.CT_Tc
+	col  int
+	span int
+}
+
+func (w *writer) table(tbl *wml.CT_Tbl) {
+	w.closeLists()
+	w.tables++
+	var rows [][]gridCell
+	var trs []*wml.CT_Row
+	for _, rc := range tbl.EG_ContentRowContent {
+		for _, tr := range rc.ContentRowContentChoice.Tr {
+			var cells []gridCell
+			col := 0
+			for _, cc := range tr.EG_ContentCellContent {
+				for _, tc := range cc.ContentCellContentChoice.Tc {
+					span := 1
+					if tc.TcPr != nil && tc.TcPr.GridSpan != nil && tc.TcPr.GridSpan.ValAttr > 1 {
+						span = int(tc.TcPr.GridSpan.ValAttr)
+					}
+					cells = append(cells, gridCell{tc, col, span})
+					col += span
+				}
+			}
+			rows = append(rows, cells)
+			trs = append(trs, tr)
+		}
+	}
+	var grid []uint64
+	total := uint64(0)
+	if tbl.TblGrid != nil {
+		for _, gc := range tbl.TblGrid.GridCol {
+			v := uint64(0)
+			if gc.WAttr != nil && gc.WAttr.ST_UnsignedDecimalNumber != nil {
+				v = *gc.WAttr.ST_UnsignedDecimalNumber
+			}
+			grid = append(grid, v)
+			total += v
+		}
+	}
+	tprops := odf.Props{Element: "style:table-properties", Attrs: []string{"table:align", "margins"}}
+	if total > 0 {
+		tprops.Attrs = append(tprops.Attrs, "style:width", odf.Length(measurement.Distance(total)*measurement.Twips))
+	}
+	tstyle := w.styles.Add("Table", "table", "", tprops)
+	w.x.Start("table:table", "table:name", fmt.Sprintf("Table%d", w.tables), "table:style-name", tstyle)
+	cols := len(grid)
+	for _, cells := range rows {
+		if n := len(cells); n > 0 && cells[n-1].col+cells[n-1].span > cols {
+			cols = cells[n-1].col + cells[n-1].span
+		}
+	}
+	for i := 0; i < cols; i++ {
+		style := ""
+		if i < len(grid) && grid[i] > 0 {
+			style = w.styles.Add("Col", "table-column", "", odf.Props{Element: "style:table-column-properties",
+				Attrs: []string{"style:column-width", odf.Length(measurement.Distance(grid[i]) * measurement.Twips)}})
+		}
+		w.x.Empty("table:table-column", "table:style-name", style)
+	}
+	borders := tbl.TblPr != nil && (tbl.TblPr.TblBorders != nil || tbl.TblPr.TblStyle != nil)
+	inHeader := false
+	for ri, cells := range rows {
+		header := isHeaderRow(trs[ri])
+		if header && !inHeader && ri == 0 {
+			w.x.Start("table:table-header-rows")
+			inHeader = true
+		} else if !header && inHeader {
+			w.x.End()
+			inHeader = false
+		}
+		w.x.Start("table:table-row", "table:style-name", w.rowStyle(trs[ri]))
+		for _, gc := range cells {
+			if isMergeContinue(gc.tc) {
+				for i := 0; i < gc.span; i++ {
+					w.x.Empty("table:covered-table-cell")
+				}
+				continue
+			}
+			rowSpan := 1
+			if gc.tc.TcPr != nil && gc.tc.TcPr.VMerge != nil {
+				for _, next := range rows[ri+1:] {
+					c := cellAt(next, gc.col)
+					if c == nil || !isMergeContinue(c.tc) {
+						break
+					}
+					rowSpan++
+				}
+			}
+			attrs := []string{"table:style-name", w.cellStyle(gc.tc, borders), "office:value-type", "string"}
+			if gc.span > 1 {
+				attrs = append(attrs, "table:number-columns-spanned", strconv.Itoa(gc.span))
+			}
+			if rowSpan > 1 {
+				attrs = append(attrs, "table:number-rows-spanned", strconv.Itoa(rowSpan))
+			}
+			w.x.Start("table:table-cell", attrs...)
+			savedList := w.list
+			w.list = listState{}
+			empty := true
+			for _, ble := range gc.tc.EG_BlockLevelElts {
+				cbc := ble.BlockLevelEltsChoice.EG_ContentBlockContent
+				if len(cbc) > 0 {
+					empty = false
+				}
+				w.blockContent(cbc)
+			}
+			if empty {
+				w.x.Empty("text:p")
+			}
+			w.closeLists()
+			w.list = savedList
+			w.x.End()
+			for i := 1; i < gc.span; i++ {
+				w.x.Empty("table:covered-table-cell")
+			}
+		}
+		w.x.End()
+	}
+	if inHeader {
+		w.x.End()
+	}
+	w.x.End()
+}
+
+func isHeaderRow(tr *wml.CT_Row) bool {
+	if tr.TrPr == nil {
+		return false
+	}
+	for _, c := range tr.TrPr.TrPrBaseChoice {
+		if c.TblHeader != nil && onOff(c.TblHeader) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMergeContinue(tc *wml.CT_Tc) bool {
+	return tc.TcPr != nil && tc.TcPr.VMerge != nil && tc.TcPr.VMerge.ValAttr != wml.ST_MergeRestart
+}
+
+func cellAt(cells []gridCell, col int) *gridCell {
+	for i := range cells {
+		if cells[i].col == col {
+			return &cells[i]
+		}
+	}
+	return nil
+}
+
+func (w *writer) rowStyle(tr *wml.CT_Row) string {
+	if tr.TrPr == nil {
+		return ""
+	}
+	for _, c := range tr.TrPr.TrPrBaseChoice {
+		h := c.TrHeight
+		if h == nil || h.ValAttr == nil || h.ValAttr.ST_UnsignedDecimalNumber == nil {
+			continue
+		}
+		v := odf.Length(measurement.Distance(*h.ValAttr.ST_UnsignedDecimalNumber) * measurement.Twips)
+		attr := "style:min-row-height"
+		if h.HRuleAttr == wml.ST_HeightRuleExact {
+			attr = "style:row-height"
+		}
+		return w.styles.Add("Row", "table-row", "", odf.Props{Element: "style:table-row-properties", Attrs: []string{attr, v}})
+	}
+	return ""
+}
+
+func (w *writer) cellStyle(tc *wml.CT_Tc, borders bool) string {
+	props := odf.Props{Element: "style:table-cell-properties", Attrs: []string{"fo:padding", "0.04in"}}
+	if borders {
+		props.Attrs = append(props.Attrs, "fo:border", "0.5pt solid #000000")
+	}
+	if pr := tc.TcPr; pr != nil {
+		if pr.Shd != nil && pr.Shd.FillAttr != nil && pr.Shd.FillAttr.ST_HexColorRGB != nil {
+			props.Attrs = append(props.Attrs, "fo:background-color", odf.HexColor(*pr.Shd.FillAttr.ST_HexColorRGB))
+		}
+		if pr.VAlign != nil {
+			switch pr.VAlign.ValAttr {
+			case wml.ST_VerticalJcCenter:
+				props.Attrs = append(props.Attrs, "style:vertical-align", "middle")
+			case wml.ST_VerticalJcBottom:
+				props.Attrs = append(props.Attrs, "style:vertical-align", "bottom")
+			}
+		}
+	}
+	return w.styles.Add("Cell", "table-cell", "", props)
+}
+
+func onOff(v *wml.CT_OnOff) bool {
+	if v == nil {
+		return false
+	}
+	if v.ValAttr == nil {
+		return true
+	}
+	if v.ValAttr.Bool != nil {
+		return *v.ValAttr.Bool
+	}
+	return v.ValAttr.ST_OnOff1 == sharedTypes.ST_OnOff1On
+}
+
+func twips(m *sharedTypes.ST_TwipsMeasure) (uint64, bool) {
+	if m == nil || m.ST_UnsignedDecimalNumber == nil {
+		return 0, false
+	}
+	return *m.ST_UnsignedDecimalNumber, true
+}
+
+func signedTwips(m *wml.ST_SignedTwipsMeasure) (int64, bool) {
+	if m == nil || m.Int64 == nil {
+		return 0, false
+	}
+	return *m.Int64, true
+}
@@ -0,0 +1,122 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"path"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+// AddEmbeddedObject embeds the file data named fileName in the document and
+// displays it in the run as its preview image or as an icon. Office files are
+// embedded as packages, other files as OLE packages, see
+// common.DocBase.AddEmbeddedObject. The embedded objects of a document are
+// returned by EmbeddedObjects.
+func (r Run) AddEmbeddedObject(fileName string, data []byte, opts common.EmbedOptions) (InlineDrawing, common.EmbeddedObject, error) {
+	d := r._fgggg
+	obj, err := d.AddEmbeddedObject("word", fileName, data, opts.AsPackage)
+	if err != nil {
+		return InlineDrawing{}, common.EmbeddedObject{}, err
+	}
+	img := opts.Preview
+	if img == nil {
+		icon, err := common.EmbeddingIcon(fileName)
+		if err != nil {
+			return InlineDrawing{}, common.EmbeddedObject{}, err
+		}
+		img = &icon
+	}
+	rels, addImage := r.part()
+	iref, err := addImage(*img)
+	if err != nil {
+		return InlineDrawing{}, common.EmbeddedObject{}, err
+	}
+	inl, err := r.AddDrawingInline(iref)
+	if err != nil {
+		return InlineDrawing{}, common.EmbeddedObject{}, err
+	}
+
+	// the preview drawing moves into the object
+	ic := r._bggce.EG_RunInnerContent[len(r._bggce.EG_RunInnerContent)-1]
+	o := wml.NewCT_Object()
+	o.Drawing = ic.RunInnerContentChoice.Drawing
+	ic.RunInnerContentChoice.Drawing = nil
+	ic.RunInnerContentChoice.Object = o
+
+	embed := wml.NewCT_ObjectEmbed()
+	embed.IdAttr = rels.AddRelationship("embeddings/"+path.Base(obj.ZipPath), obj.RelationshipType()).ID()
+	embed.ProgIdAttr = unioffice.String(obj.ProgID)
+	embed.DrawAspectAttr = wml.ST_ObjectDrawAspectContent
+	if opts.ShowAsIcon {
+		embed.DrawAspectAttr = wml.ST_ObjectDrawAspectIcon
+	}
+	o.ObjectChoice = wml.NewCT_ObjectChoice()
+	o.ObjectChoice.ObjectEmbed = embed
+	return inl, obj, nil
+}
+
+// part returns the relationships of the part containing the run and a
+// function adding images to that part. Runs which aren't found in a header,
+// footer, footnote, endnote or comment belong to the main document part.
+func (r Run) part() (common.Relationships, func(common.Image) (common.ImageRef, error)) {
+	d := r._fgggg
+	has := func(blocks []*wml.EG_BlockLevelElts) bool {
+		found := false
+		walkBlockParagraphs(blocks, func(p *wml.CT_P) {
+			walkParagraphRuns(p, func(run *wml.CT_R) {
+				found = found || run == r._bggce
+			})
+		})
+		return found
+	}
+	for idx, hdr := range d._ebg {
+		if has(hdr.EG_BlockLevelElts) {
+			return d._dcf[idx], Header{d, hdr}.AddImage
+		}
+	}
+	for idx, ftr := range d._cca {
+		if has(ftr.EG_BlockLevelElts) {
+			return d._abc[idx], Footer{d, ftr}.AddImage
+		}
+	}
+	stories := map[LinkLocation][][]*wml.EG_BlockLevelElts{}
+	for _, fn := range d.Footnotes() {
+		stories[LinkLocationFootnote] = append(stories[LinkLocationFootnote], fn.X().EG_BlockLevelElts)
+	}
+	for _, en := range d.Endnotes() {
+		stories[LinkLocationEndnote] = append(stories[LinkLocationEndnote], en.X().EG_BlockLevelElts)
+	}
+	for _, c := range d.Comments() {
+		stories[LinkLocationComment] = append(stories[LinkLocationComment], c.X().EG_BlockLevelElts)
+	}
+	for loc, blocks := range stories {
+		for _, b := range blocks {
+			if has(b) {
+				rels := d.noteRels(loc)
+				return rels, func(img common.Image) (common.ImageRef, error) {
+					// the image is added to the document and its
+					// relationship moved to the part
+					ref, err := d.AddImage(img)
+					if err != nil {
+						return ref, err
+					}
+					rel := d._fgg.GetByRelId(ref.RelID())
+					ref.SetRelID(rels.AddRelationship(rel.Target(), unioffice.ImageType).ID())
+					d._fgg.Remove(rel)
+					return ref, nil
+				}
+			}
+		}
+	}
+	return d._fgg, d.AddImage
+}
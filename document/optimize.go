@@ -0,0 +1,28 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import "github.com/unidoc/unioffice/v2/common"
+
+// OptimizeImages reduces the size of the images of the document as described
+// for common.OptimizeImages. Pictures of drawings in the body, headers and
+// footers are downsampled to their displayed size.
+func (d *Document) OptimizeImages(opts common.ImageOptimizeOptions) (common.ImageOptimizeResult, error) {
+	rels := d.partRels()
+	placements := []common.ImagePlacement{}
+	for _, img := range d.DrawingImages() {
+		p := common.ImagePlacement{Rels: img.rels(), RelID: img.RelID(), BlipFill: img._pic.BlipFill}
+		if ext := img.extent(); ext != nil {
+			p.Width, p.Height = ext.CxAttr, ext.CyAttr
+		}
+		placements = append(placements, p)
+	}
+	return common.OptimizeImages(&d.DocBase, rels, placements, opts)
+}
@@ -0,0 +1,175 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+	"github.com/unidoc/unioffice/v2/schema/urn/schemas_microsoft_com/vml"
+)
+
+// Redact removes or masks the text matching opts in the body, headers,
+// footers, footnotes, endnotes and comments, including field codes and
+// results, text boxes, VML shapes, the alternative text of drawings, the
+// targets of hyperlinks, the authors of comments, the document properties and
+// custom XML parts. Matches may span runs.
+//
+// Tracked deletions and the sources of moved text are removed before
+// redacting, so deleted text can't be recovered by rejecting changes, and the
+// revision IDs are dropped from the settings. It returns the number of matches redacted.
+func (d *Document) Redact(opts common.RedactOptions) (int, error) {
+	r, err := common.NewRedactor(opts)
+	if err != nil {
+		return 0, err
+	}
+	for _, story := range d.stories() {
+		removeDeletions(*story)
+		redactBlocks(r, *story)
+	}
+	for _, c := range d.Comments() {
+		c.X().AuthorAttr = r.String(c.X().AuthorAttr)
+		r.StringPtr(c.X().InitialsAttr)
+	}
+	for _, rels := range d.partRels() {
+		for _, rel := range rels.X().Relationship {
+			if rel.TypeAttr == unioffice.HyperLinkType {
+				rel.TargetAttr = r.String(rel.TargetAttr)
+			}
+		}
+	}
+	if d.Settings.X() != nil {
+		d.Settings.X().Rsids = nil
+	}
+	if err := r.DocBase(&d.DocBase); err != nil {
+		return r.Count, err
+	}
+	return r.Count, nil
+}
+
+// removeDeletions removes the tracked deletions and the sources of moved text
+// of blocks, and drops the deletion marks of paragraph marks and table rows.
+func removeDeletions(blocks []*wml.EG_BlockLevelElts) {
+	walkBlockParagraphs(blocks, func(p *wml.CT_P) {
+		walkRunLevelElts(p.EG_PContent, func(rle *wml.EG_RunLevelElts) {
+			rle.RunLevelEltsChoice.Del = nil
+			rle.RunLevelEltsChoice.MoveFrom = nil
+		})
+		if p.PPr != nil && p.PPr.RPr != nil {
+			p.PPr.RPr.Del = nil
+			p.PPr.RPr.MoveFrom = nil
+		}
+	})
+	walkBlockTables(blocks, func(tbl *wml.CT_Tbl) {
+		walkTableRows(tbl, func(tr *wml.CT_Row) {
+			if tr.TrPr != nil {
+				tr.TrPr.Del = nil
+			}
+		})
+	})
+}
+
+// redactBlocks redacts the paragraphs of blocks. The visible text, the field
+// codes and the deleted text of a paragraph are redacted separately so that
+// matches may span runs but not mix them.
+func redactBlocks(r *common.Redactor, blocks []*wml.EG_BlockLevelElts) {
+	walkBlockParagraphs(blocks, func(p *wml.CT_P) {
+		var text, instr, del []*string
+		walkParagraphRuns(p, func(run *wml.CT_R) {
+			for _, ric := range run.EG_RunInnerContent {
+				c := ric.RunInnerContentChoice
+				if c.T != nil {
+					text = append(text, &c.T.Content)
+				}
+				if c.DelText != nil {
+					del = append(del, &c.DelText.Content)
+				}
+				if c.InstrText != nil {
+					instr = append(instr, &c.InstrText.Content)
+				}
+				if c.DelInstrText != nil {
+					instr = append(instr, &c.DelInstrText.Content)
+				}
+				if c.Drawing != nil {
+					redactDrawing(r, c.Drawing)
+				}
+				if c.Pict != nil {
+					redactPict(r, c.Pict)
+				}
+			}
+			for _, x := range run.Extra {
+				if acr, ok := x.(*wml.AlternateContentRun); ok && acr.Choice.Drawing != nil {
+					redactDrawing(r, acr.Choice.Drawing)
+				}
+			}
+		})
+		r.Segments(text)
+		r.Segments(instr)
+		r.Segments(del)
+		var fields func(pcs []*wml.EG_PContent)
+		fields = func(pcs []*wml.EG_PContent) {
+			for _, pc := range pcs {
+				for _, fs := range pc.PContentChoice.FldSimple {
+					fs.InstrAttr = r.String(fs.InstrAttr)
+					fields(fs.EG_PContent)
+				}
+			}
+		}
+		fields(p.EG_PContent)
+	})
+}
+
+// redactDrawing redacts the names and alternative text of the graphics of d
+// and the content of their text boxes.
+func redactDrawing(r *common.Redactor, d *wml.CT_Drawing) {
+	for _, dc := range d.DrawingChoice {
+		var pr *dml.CT_NonVisualDrawingProps
+		var g *dml.Graphic
+		switch {
+		case dc.Inline != nil:
+			pr, g = dc.Inline.DocPr, dc.Inline.Graphic
+		case dc.Anchor != nil:
+			pr, g = dc.Anchor.DocPr, dc.Anchor.Graphic
+		default:
+			continue
+		}
+		r.DrawingProps(pr)
+		if g == nil || g.GraphicData == nil {
+			continue
+		}
+		for _, x := range g.GraphicData.Any {
+			if wsp, ok := x.(*wml.WdWsp); ok && wsp.WordprocessingShapeChoice1 != nil {
+				if tb := wsp.WordprocessingShapeChoice1.Txbx; tb != nil && tb.TxbxContent != nil {
+					removeDeletions(tb.TxbxContent.EG_BlockLevelElts)
+					redactBlocks(r, tb.TxbxContent.EG_BlockLevelElts)
+				}
+			}
+		}
+	}
+}
+
+// redactPict redacts the VML shapes of a picture, such as text watermarks and
+// legacy text boxes.
+func redactPict(r *common.Redactor, pict *wml.CT_Picture) {
+	for _, a := range pict.Any {
+		switch s := a.(type) {
+		case *unioffice.XSDAny:
+			r.XSDAny(s)
+		case *vml.Shape:
+			r.StringPtr(s.AltAttr)
+			for _, c := range s.ShapeChoice {
+				if c.ShapeElementsChoice != nil && c.ShapeElementsChoice.Textpath != nil {
+					r.StringPtr(c.ShapeElementsChoice.Textpath.StringAttr)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package rtf
+
+import "strconv"
+
+type tokenKind byte
+
+const (
+	tokenEOF tokenKind = iota
+	tokenGroupStart
+	tokenGroupEnd
+	tokenControlWord
+	tokenControlSymbol
+	tokenText
+	tokenBinary
+)
+
+// token is a single lexical element of a RTF stream.
+type token struct {
+	kind     tokenKind
+	word     string
+	param    int
+	hasParam bool
+	text     []byte
+}
+
+// lexer splits a RTF stream into tokens.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func (l *lexer) next() token {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch c {
+		case '{':
+			l.pos++
+			return token{kind: tokenGroupStart}
+		case '}':
+			l.pos++
+			return token{kind: tokenGroupEnd}
+		case '\\':
+			return l.control()
+		case '\r', '\n':
+			l.pos++
+		default:
+			return l.plainText()
+		}
+	}
+	return token{kind: tokenEOF}
+}
+
+func (l *lexer) plainText() token {
+	start := l.pos
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '{' || c == '}' || c == '\\' || c == '\r' || c == '\n' {
+			break
+		}
+		l.pos++
+	}
+	return token{kind: tokenText, text: l.data[start:l.pos]}
+}
+
+func (l *lexer) control() token {
+	l.pos++
+	if l.pos >= len(l.data) {
+		return token{kind: tokenEOF}
+	}
+	c := l.data[l.pos]
+	if !isLetter(c) {
+		l.pos++
+		if c == '\'' {
+			if l.pos+2 <= len(l.data) {
+				v, err := strconv.ParseUint(string(l.data[l.pos:l.pos+2]), 16, 8)
+				l.pos += 2
+				if err == nil {
+					return token{kind: tokenControlSymbol, word: "'", param: int(v), hasParam: true}
+				}
+			}
+			return token{kind: tokenControlSymbol, word: "'"}
+		}
+		if c == '\r' || c == '\n' {
+			return token{kind: tokenControlWord, word: "par"}
+		}
+		return token{kind: tokenControlSymbol, word: string(c)}
+	}
+	start := l.pos
+	for l.pos < len(l.data) && isLetter(l.data[l.pos]) {
+		l.pos++
+	}
+	tok := token{kind: tokenControlWord, word: string(l.data[start:l.pos])}
+	numStart := l.pos
+	if l.pos < len(l.data) && l.data[l.pos] == '-' {
+		l.pos++
+	}
+	for l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+		l.pos++
+	}
+	if l.pos > numStart && !(l.pos == numStart+1 && l.data[numStart] == '-') {
+		if v, err := strconv.Atoi(string(l.data[numStart:l.pos])); err == nil {
+			tok.param = v
+			tok.hasParam = true
+		}
+	} else {
+		l.pos = numStart
+	}
+	if l.pos < len(l.data) && l.data[l.pos] == ' ' {
+		l.pos++
+	}
+	if tok.word == "bin" && tok.hasParam && tok.param > 0 {
+		end := l.pos + tok.param
+		if end > len(l.data) {
+			end = len(l.data)
+		}
+		tok.kind = tokenBinary
+		tok.text = l.data[l.pos:end]
+		l.pos = end
+	}
+	return tok
+}
+
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+
+// cp1252 maps the bytes 0x80-0x9F of the Windows-1252 code page to runes,
+// the remaining bytes map to the identical Latin-1 code point.
+var cp1252 = [32]rune{
+	0x20AC, 0xFFFD, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0xFFFD, 0x017D, 0xFFFD,
+	0xFFFD, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0xFFFD, 0x017E, 0x0178,
+}
+
+func decodeANSI(b byte) rune {
+	if b >= 0x80 && b < 0xA0 {
+		return cp1252[b-0x80]
+	}
+	return rune(b)
+}
+
+// encodeANSI returns the Windows-1252 byte for r if there is one.
+func encodeANSI(r rune) (byte, bool) {
+	if r < 0x80 || (r >= 0xA0 && r <= 0xFF) {
+		return byte(r), true
+	}
+	for i, v := range cp1252 {
+		if v == r && v != 0xFFFD {
+			return byte(0x80 + i), true
+		}
+	}
+	return 0, false
+}
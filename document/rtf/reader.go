@@ -0,0 +1,1468 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package rtf
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	_ "image/jpeg"
+	_ "image/png"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/transform"
+
+	"github.com/unidoc/unioffice/v2/color"
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/common/logger"
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/ofc/sharedTypes"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+type destination byte
+
+const (
+	destText destination = iota
+	destSkip
+	destFontTable
+	destColorTable
+	destStyleSheet
+	destInfo
+	destInfoField
+	destPict
+	destFieldInst
+	destListTable
+	destListOverrideTable
+	destLevelText
+	destBookmark
+	destCollect
+)
+
+type collectKind byte
+
+const (
+	collectHeader collectKind = iota
+	collectFooter
+	collectFootnote
+)
+
+// collector accumulates the plain text of destinations such as headers and
+// footnotes which are added to the document once the group is closed.
+type collector struct {
+	kind collectKind
+	text strings.Builder
+}
+
+type charProps struct {
+	bold, italic, strike, dstrike bool
+	caps, smallCaps, hidden       bool
+	underline                     wml.ST_Underline
+	size                          int
+	font                          int
+	color                         int
+	vertAlign                     sharedTypes.ST_VerticalAlignRun
+}
+
+type paraProps struct {
+	align                      wml.ST_Jc
+	left, right, firstLine     int
+	before, after              int
+	line                       int
+	lineMult                   bool
+	inTable                    bool
+	depth                      int
+	list, level                int
+	outline                    int
+	style                      int
+	pageBreak, keepNext, keepL bool
+}
+
+type fieldState struct {
+	inst strings.Builder
+	link *document.HyperLink
+	para *document.Paragraph
+}
+
+type groupState struct {
+	dest       destination
+	textDest   destination
+	owner      bool
+	ignorable  bool
+	char       charProps
+	para       paraProps
+	uc         int
+	field      *fieldState
+	collect    *collector
+	infoTarget string
+}
+
+type pictState struct {
+	format         string
+	data           []byte
+	hex            strings.Builder
+	goalW, goalH   int
+	width, height  int
+	scaleX, scaleY int
+}
+
+type listLevel struct {
+	nfc, start      int
+	left, firstLine int
+	text            []rune
+}
+
+type listDef struct {
+	id     int
+	levels []*listLevel
+}
+
+type cellDef struct {
+	right  int
+	vmerge wml.ST_Merge
+	shade  int
+}
+
+// rowDef holds the row and cell properties of the current row of a table
+// nesting level.
+type rowDef struct {
+	cells   []cellDef
+	pending cellDef
+	height  int
+	header  bool
+	borders bool
+}
+
+// tableLevel is an open table, tables nested in its current cell follow it
+// in reader.tables.
+type tableLevel struct {
+	table       document.Table
+	row         *document.Row
+	cell        *document.Cell
+	cellHasPara bool
+}
+
+type reader struct {
+	lex   lexer
+	doc   *document.Document
+	state groupState
+	stack []groupState
+	skip  int
+
+	fonts      map[int]string
+	fontName   strings.Builder
+	fontIdx    int
+	defFont    int
+	codePage   int
+	fontPages  map[int]int
+	decoders   map[int]*encoding.Decoder
+	ansi       []byte
+	surrogate  rune
+	colors     []color.Color
+	r, g, b    int
+	styles     map[int]string
+	styleName  strings.Builder
+	styleIdx   int
+	info       strings.Builder
+	bookmark   strings.Builder
+	pict       *pictState
+	lists      map[int]*listDef
+	curList    *listDef
+	curLevel   *listLevel
+	overrides  map[int]int
+	overrideID int
+	numbering  map[int]document.NumberingDefinition
+
+	para     *document.Paragraph
+	run      *document.Run
+	runProps charProps
+	runLink  *document.HyperLink
+
+	tables  []*tableLevel
+	rowDefs map[int]*rowDef
+
+	pageW, pageH                int
+	marginL, marginR            int
+	marginT, marginB, landscape int
+	sawPage                     bool
+}
+
+func newReader(data []byte) *reader {
+	r := &reader{lex: lexer{data: data}, doc: document.New(),
+		fonts: map[int]string{}, fontPages: map[int]int{}, decoders: map[int]*encoding.Decoder{},
+		styles: map[int]string{}, lists: map[int]*listDef{},
+		overrides: map[int]int{}, numbering: map[int]document.NumberingDefinition{},
+		rowDefs: map[int]*rowDef{}, defFont: -1, styleIdx: -1}
+	r.state = groupState{dest: destText, textDest: destText, uc: 1}
+	r.state.char = defaultCharProps()
+	r.state.para = defaultParaProps()
+	return r
+}
+
+func defaultCharProps() charProps { return charProps{font: -1} }
+
+func defaultParaProps() paraProps { return paraProps{list: -1, outline: -1, style: -1} }
+
+func (r *reader) read() (*document.Document, error) {
+	if !strings.HasPrefix(string(r.lex.data[:min(len(r.lex.data), 6)]), "{\\rtf") {
+		return nil, errors.New("rtf: missing {\\rtf header")
+	}
+	for {
+		tok := r.lex.next()
+		switch tok.kind {
+		case tokenEOF:
+			r.finishSection()
+			return r.doc, nil
+		case tokenGroupStart:
+			r.stack = append(r.stack, r.state)
+			r.state.owner = false
+			r.state.ignorable = false
+		case tokenGroupEnd:
+			if len(r.stack) == 0 {
+				continue
+			}
+			closed := r.state
+			r.state = r.stack[len(r.stack)-1]
+			r.stack = r.stack[:len(r.stack)-1]
+			if closed.owner {
+				r.closeDestination(closed)
+			}
+		case tokenControlSymbol:
+			r.controlSymbol(tok)
+		case tokenControlWord:
+			r.controlWord(tok)
+		case tokenBinary:
+			if r.state.dest == destPict && r.pict != nil {
+				r.pict.data = append(r.pict.data, tok.text...)
+			}
+		case tokenText:
+			for _, c := range tok.text {
+				if r.skip > 0 {
+					r.skip--
+					continue
+				}
+				r.ansiChar(c)
+			}
+		}
+	}
+}
+
+// enter switches the current group to a new destination.
+func (r *reader) enter(d destination) {
+	r.state.dest = d
+	r.state.owner = true
+}
+
+// specialChars maps control words which stand for a single character.
+var specialChars = map[string]rune{
+	"tab": '\t', "emdash": '—', "endash": '–', "bullet": '•', "lquote": '‘', "rquote": '’',
+	"ldblquote": '“', "rdblquote": '”', "emspace": ' ', "enspace": ' ', "qmspace": ' ',
+}
+
+func (r *reader) controlSymbol(tok token) {
+	switch tok.word {
+	case "*":
+		r.state.ignorable = true
+	case "'":
+		if r.skip > 0 {
+			r.skip--
+			return
+		}
+		if tok.hasParam {
+			r.ansiChar(byte(tok.param))
+		}
+	case "~":
+		r.char(' ')
+	case "_":
+		r.char('‑')
+	case "-":
+	case "{", "}", "\\":
+		r.char(rune(tok.word[0]))
+	case "\t":
+		r.char('\t')
+	}
+}
+
+func (r *reader) controlWord(tok token) {
+	st := &r.state
+	p := tok.param
+	on := !tok.hasParam || p != 0
+	if st.dest == destSkip {
+		return
+	}
+	if r.destinationWord(tok) {
+		st.ignorable = false
+		return
+	}
+	if st.ignorable {
+		// an unknown destination marked with \* is skipped entirely
+		r.enter(destSkip)
+		return
+	}
+	switch tok.word {
+	case "uc":
+		st.uc = p
+		return
+	case "u":
+		r.unicodeChar(unicodeParam(p))
+		r.skip = st.uc
+		return
+	}
+	if c, ok := specialChars[tok.word]; ok {
+		r.char(c)
+		return
+	}
+	switch st.dest {
+	case destFontTable:
+		switch tok.word {
+		case "f":
+			r.fontIdx = p
+			r.fontName.Reset()
+		case "fcharset":
+			if cp := charsetCodePage(p); cp != 0 {
+				r.fontPages[r.fontIdx] = cp
+			}
+		case "cpg":
+			r.fontPages[r.fontIdx] = p
+		}
+		return
+	case destColorTable:
+		switch tok.word {
+		case "red":
+			r.r = p
+		case "green":
+			r.g = p
+		case "blue":
+			r.b = p
+		}
+		return
+	case destStyleSheet:
+		switch tok.word {
+		case "s":
+			r.styleIdx = p
+			r.styleName.Reset()
+		case "cs", "ds", "ts":
+			r.styleIdx = -1
+		}
+		return
+	case destPict:
+		r.pictWord(tok)
+		return
+	case destListTable:
+		r.listWord(tok)
+		return
+	case destListOverrideTable:
+		switch tok.word {
+		case "listid":
+			r.overrideID = p
+		case "ls":
+			r.overrides[p] = r.overrideID
+		}
+		return
+	case destCollect:
+		switch tok.word {
+		case "par", "sect", "line":
+			r.endParagraph()
+		}
+		return
+	case destText:
+	default:
+		return
+	}
+
+	switch tok.word {
+	// document level
+	case "deff":
+		r.defFont = p
+	case "ansicpg":
+		r.codePage = p
+	case "paperw":
+		r.pageW, r.sawPage = p, true
+	case "paperh":
+		r.pageH, r.sawPage = p, true
+	case "margl":
+		r.marginL, r.sawPage = p, true
+	case "margr":
+		r.marginR, r.sawPage = p, true
+	case "margt":
+		r.marginT, r.sawPage = p, true
+	case "margb":
+		r.marginB, r.sawPage = p, true
+	case "landscape":
+		r.landscape, r.sawPage = 1, true
+
+	// character formatting
+	case "plain":
+		st.char = defaultCharProps()
+	case "b":
+		st.char.bold = on
+	case "i":
+		st.char.italic = on
+	case "strike":
+		st.char.strike = on
+	case "striked":
+		st.char.dstrike = on
+	case "caps":
+		st.char.caps = on
+	case "scaps":
+		st.char.smallCaps = on
+	case "v":
+		st.char.hidden = on
+	case "ul":
+		st.char.underline = underlineIf(on, wml.ST_UnderlineSingle)
+	case "uld":
+		st.char.underline = underlineIf(on, wml.ST_UnderlineDotted)
+	case "uldb":
+		st.char.underline = underlineIf(on, wml.ST_UnderlineDouble)
+	case "ulw":
+		st.char.underline = underlineIf(on, wml.ST_UnderlineWords)
+	case "ulwave":
+		st.char.underline = underlineIf(on, wml.ST_UnderlineWave)
+	case "ulth":
+		st.char.underline = underlineIf(on, wml.ST_UnderlineThick)
+	case "uldash":
+		st.char.underline = underlineIf(on, wml.ST_UnderlineDash)
+	case "ulnone":
+		st.char.underline = wml.ST_UnderlineUnset
+	case "fs":
+		st.char.size = p
+	case "f":
+		st.char.font = p
+	case "cf":
+		st.char.color = p
+	case "super":
+		st.char.vertAlign = sharedTypes.ST_VerticalAlignRunSuperscript
+	case "sub":
+		st.char.vertAlign = sharedTypes.ST_VerticalAlignRunSubscript
+	case "nosupersub":
+		st.char.vertAlign = sharedTypes.ST_VerticalAlignRunUnset
+
+	// paragraph formatting
+	case "pard":
+		st.para = defaultParaProps()
+	case "ql":
+		st.para.align = wml.ST_JcLeft
+	case "qc":
+		st.para.align = wml.ST_JcCenter
+	case "qr":
+		st.para.align = wml.ST_JcRight
+	case "qj":
+		st.para.align = wml.ST_JcBoth
+	case "li", "lin":
+		st.para.left = p
+	case "ri", "rin":
+		st.para.right = p
+	case "fi":
+		st.para.firstLine = p
+	case "sb":
+		st.para.before = p
+	case "sa":
+		st.para.after = p
+	case "sl":
+		st.para.line = p
+	case "slmult":
+		st.para.lineMult = on
+	case "intbl":
+		st.para.inTable = true
+	case "itap":
+		st.para.depth = p
+	case "ls":
+		st.para.list = p
+	case "ilvl":
+		st.para.level = p
+	case "outlinelevel":
+		st.para.outline = p
+	case "s":
+		st.para.style = p
+	case "pagebb":
+		st.para.pageBreak = on
+	case "keepn":
+		st.para.keepNext = on
+	case "keep":
+		st.para.keepL = on
+
+	// special characters
+	case "par", "sect":
+		r.endParagraph()
+	case "line":
+		r.ensureRun().AddBreak()
+	case "page":
+		r.ensureRun().AddPageBreak()
+
+	// tables
+	case "trowd":
+		r.rowDefs[max(r.depth(), 1)] = &rowDef{}
+	case "cellx":
+		rd := r.rowDef()
+		rd.pending.right = p
+		rd.cells = append(rd.cells, rd.pending)
+		rd.pending = cellDef{}
+	case "clvmgf":
+		r.rowDef().pending.vmerge = wml.ST_MergeRestart
+	case "clvmrg":
+		r.rowDef().pending.vmerge = wml.ST_MergeContinue
+	case "clcbpat":
+		r.rowDef().pending.shade = p
+	case "trrh":
+		r.rowDef().height = p
+	case "trhdr":
+		r.rowDef().header = true
+	case "clbrdrt", "clbrdrb", "clbrdrl", "clbrdrr", "trbrdrt", "trbrdrb", "trbrdrl", "trbrdrr", "trbrdrh", "trbrdrv":
+		r.rowDef().borders = true
+	case "cell":
+		r.endCell(1)
+	case "row":
+		r.endRow(1)
+	case "nestcell":
+		r.endCell(max(r.depth(), 2))
+	case "nestrow":
+		r.endRow(max(r.depth(), 2))
+	}
+}
+
+// destinationWord handles control words which start a new destination and
+// reports whether tok was one of them.
+func (r *reader) destinationWord(tok token) bool {
+	st := &r.state
+	switch tok.word {
+	case "fonttbl":
+		r.enter(destFontTable)
+	case "colortbl":
+		r.enter(destColorTable)
+		r.colors = nil
+		r.r, r.g, r.b = 0, 0, 0
+	case "stylesheet":
+		r.enter(destStyleSheet)
+	case "info":
+		r.enter(destInfo)
+	case "title", "author", "operator", "doccomm", "company", "subject", "keywords":
+		if st.dest != destInfo {
+			r.enter(destSkip)
+			return true
+		}
+		r.enter(destInfoField)
+		st.infoTarget = tok.word
+		r.info.Reset()
+	case "pict":
+		r.enter(destPict)
+		r.pict = &pictState{scaleX: 100, scaleY: 100}
+	case "field":
+		st.field = &fieldState{}
+	case "fldinst":
+		r.enter(destFieldInst)
+	case "fldrslt":
+		st.dest = st.textDest
+		if st.field != nil {
+			st.field.para = nil
+		}
+	case "listtable":
+		r.enter(destListTable)
+	case "listoverridetable":
+		r.enter(destListOverrideTable)
+	case "leveltext":
+		if st.dest != destListTable {
+			return false
+		}
+		r.enter(destLevelText)
+		if r.curLevel != nil {
+			r.curLevel.text = nil
+		}
+	case "bkmkstart":
+		r.enter(destBookmark)
+		r.bookmark.Reset()
+	case "header", "headerr", "headerl", "headerf", "footer", "footerr", "footerl", "footerf", "footnote":
+		if st.dest != destText {
+			r.enter(destSkip)
+			return true
+		}
+		kind := collectFootnote
+		if strings.HasPrefix(tok.word, "header") {
+			kind = collectHeader
+		} else if strings.HasPrefix(tok.word, "footer") {
+			kind = collectFooter
+		}
+		if (kind == collectHeader && tok.word != "header" && tok.word != "headerr") ||
+			(kind == collectFooter && tok.word != "footer" && tok.word != "footerr") {
+			r.enter(destSkip)
+			return true
+		}
+		r.enter(destCollect)
+		st.textDest = destCollect
+		st.collect = &collector{kind: kind}
+	case "shppict", "nesttableprops":
+		// the picture or the nested row properties inside are read as part
+		// of the current destination
+	case "bkmkend", "pntext", "listtext", "nonshppict", "themedata", "colorschememapping",
+		"latentstyles", "datastore", "xmlnstbl", "rsidtbl", "generator", "annotation",
+		"object", "xe", "tc", "txe", "revtbl", "pgdsctbl", "mmathPr", "filetbl", "nonesttables":
+		r.enter(destSkip)
+	default:
+		return false
+	}
+	return true
+}
+
+func (r *reader) closeDestination(closed groupState) {
+	switch closed.dest {
+	case destInfoField:
+		r.setInfo(closed.infoTarget, strings.TrimSpace(r.info.String()))
+	case destPict:
+		r.addPicture()
+		r.pict = nil
+	case destBookmark:
+		if name := strings.TrimSpace(r.bookmark.String()); name != "" {
+			r.ensureParagraph().AddBookmark(name)
+		}
+	case destCollect:
+		r.addCollected(closed.collect)
+	}
+}
+
+// ansiChar adds a character given as a byte in the code page of the current
+// font or of the document. The lead bytes of double byte code pages are kept
+// until the trail byte follows.
+func (r *reader) ansiChar(b byte) {
+	dec := r.decoder()
+	if dec == nil || b < 0x80 && len(r.ansi) == 0 {
+		r.ansi = r.ansi[:0]
+		r.char(decodeANSI(b))
+		return
+	}
+	r.ansi = append(r.ansi, b)
+	out := make([]byte, 16)
+	dec.Reset()
+	n, _, err := dec.Transform(out, r.ansi, false)
+	if err == transform.ErrShortSrc && len(r.ansi) == 1 {
+		return
+	}
+	r.ansi = r.ansi[:0]
+	for _, c := range string(out[:n]) {
+		r.char(c)
+	}
+}
+
+// decoder returns the decoder of the code page of the current font or of the
+// document, nil for Windows-1252 which decodeANSI handles.
+func (r *reader) decoder() *encoding.Decoder {
+	font := r.state.char.font
+	if r.state.dest == destFontTable {
+		font = r.fontIdx
+	} else if font < 0 {
+		font = r.defFont
+	}
+	cp, ok := r.fontPages[font]
+	if !ok {
+		cp = r.codePage
+	}
+	if dec, ok := r.decoders[cp]; ok {
+		return dec
+	}
+	var dec *encoding.Decoder
+	if enc, ok := codePages[cp]; ok {
+		dec = enc.NewDecoder()
+	}
+	r.decoders[cp] = dec
+	return dec
+}
+
+// unicodeChar adds a \u character, combining UTF-16 surrogate pairs.
+func (r *reader) unicodeChar(c rune) {
+	if r.surrogate != 0 {
+		high := r.surrogate
+		r.surrogate = 0
+		if d := utf16.DecodeRune(high, c); d != utf8.RuneError {
+			r.char(d)
+			return
+		}
+		r.char(utf8.RuneError)
+	}
+	switch {
+	case c >= 0xD800 && c < 0xDC00:
+		r.surrogate = c
+		return
+	case utf16.IsSurrogate(c):
+		c = utf8.RuneError
+	}
+	r.char(c)
+}
+
+func (r *reader) char(c rune) {
+	if r.surrogate != 0 {
+		// a high surrogate not followed by a low one
+		r.surrogate = 0
+		r.char(utf8.RuneError)
+	}
+	st := &r.state
+	switch st.dest {
+	case destText:
+		r.addText(c)
+	case destCollect:
+		if st.collect != nil {
+			st.collect.text.WriteRune(c)
+		}
+	case destFontTable:
+		if c == ';' {
+			r.fonts[r.fontIdx] = strings.TrimSpace(r.fontName.String())
+			r.fontName.Reset()
+		} else {
+			r.fontName.WriteRune(c)
+		}
+	case destColorTable:
+		if c == ';' {
+			if len(r.colors) == 0 && r.r == 0 && r.g == 0 && r.b == 0 {
+				r.colors = append(r.colors, color.Auto)
+			} else {
+				r.colors = append(r.colors, color.RGB(uint8(r.r), uint8(r.g), uint8(r.b)))
+			}
+			r.r, r.g, r.b = 0, 0, 0
+		}
+	case destStyleSheet:
+		if c == ';' {
+			if r.styleIdx >= 0 {
+				r.styles[r.styleIdx] = strings.TrimSpace(r.styleName.String())
+			}
+			r.styleName.Reset()
+		} else {
+			r.styleName.WriteRune(c)
+		}
+	case destInfoField:
+		r.info.WriteRune(c)
+	case destPict:
+		if isHexDigit(c) {
+			r.pict.hex.WriteRune(c)
+		}
+	case destFieldInst:
+		if st.field != nil {
+			st.field.inst.WriteRune(c)
+		}
+	case destLevelText:
+		r.levelTextRune(c)
+	case destBookmark:
+		r.bookmark.WriteRune(c)
+	}
+}
+
+func (r *reader) addText(c rune) {
+	run := r.ensureRun()
+	if c == '\t' {
+		run.AddTab()
+		return
+	}
+	// consecutive characters are appended to the last text element of the run
+	x := run.X()
+	if n := len(x.EG_RunInnerContent); n > 0 {
+		if t := x.EG_RunInnerContent[n-1].RunInnerContentChoice.T; t != nil {
+			t.Content += string(c)
+			if c == ' ' {
+				preserve := "preserve"
+				t.SpaceAttr = &preserve
+			}
+			return
+		}
+	}
+	run.AddText(string(c))
+}
+
+func (r *reader) ensureParagraph() document.Paragraph {
+	if r.para != nil {
+		return *r.para
+	}
+	pp := r.state.para
+	var p document.Paragraph
+	if depth := r.depth(); depth > 0 {
+		p = r.cellAt(depth).AddParagraph()
+		r.tables[depth-1].cellHasPara = true
+	} else {
+		r.closeTable()
+		p = r.doc.AddParagraph()
+	}
+	r.applyParagraphProps(p, pp)
+	r.para = &p
+	r.run = nil
+	return p
+}
+
+func (r *reader) applyParagraphProps(p document.Paragraph, pp paraProps) {
+	if pp.align != wml.ST_JcUnset {
+		p.SetAlignment(pp.align)
+	}
+	if pp.left != 0 {
+		p.SetLeftIndent(measurement.Distance(pp.left) * measurement.Twips)
+	}
+	if pp.right != 0 {
+		p.SetRightIndent(measurement.Distance(pp.right) * measurement.Twips)
+	}
+	if pp.firstLine > 0 {
+		p.SetFirstLineIndent(measurement.Distance(pp.firstLine) * measurement.Twips)
+	} else if pp.firstLine < 0 {
+		p.SetHangingIndent(measurement.Distance(-pp.firstLine) * measurement.Twips)
+	}
+	if pp.before > 0 {
+		p.SetBeforeSpacing(measurement.Distance(pp.before) * measurement.Twips)
+	}
+	if pp.after > 0 {
+		p.SetAfterSpacing(measurement.Distance(pp.after) * measurement.Twips)
+	}
+	switch {
+	case pp.line == 0 || pp.line == 1000:
+	case pp.lineMult:
+		p.SetLineSpacing(measurement.Distance(pp.line)*measurement.Twips, wml.ST_LineSpacingRuleAuto)
+	case pp.line < 0:
+		p.SetLineSpacing(measurement.Distance(-pp.line)*measurement.Twips, wml.ST_LineSpacingRuleExact)
+	default:
+		p.SetLineSpacing(measurement.Distance(pp.line)*measurement.Twips, wml.ST_LineSpacingRuleAtLeast)
+	}
+	if name, ok := r.styles[pp.style]; ok {
+		lname := strings.ToLower(name)
+		if strings.HasPrefix(lname, "heading ") {
+			if lvl, err := strconv.Atoi(strings.TrimPrefix(lname, "heading ")); err == nil && lvl >= 1 && lvl <= 9 {
+				p.SetStyle(fmt.Sprintf("Heading%d", lvl))
+			}
+		} else if lname == "title" {
+			p.SetStyle("Title")
+		}
+	}
+	if pp.outline >= 0 {
+		p.SetOutlineLvl(int64(pp.outline))
+	}
+	if pp.list >= 0 {
+		if nd, ok := r.numberingFor(pp.list); ok {
+			p.SetNumberingDefinition(nd)
+			p.SetNumberingLevel(pp.level)
+		}
+	}
+	if pp.pageBreak {
+		p.Properties().SetPageBreakBefore(true)
+	}
+	if pp.keepNext {
+		p.Properties().SetKeepWithNext(true)
+	}
+	if pp.keepL {
+		p.Properties().SetKeepOnOnePage(true)
+	}
+}
+
+func (r *reader) ensureRun() document.Run {
+	p := r.ensureParagraph()
+	link := r.activeLink(p)
+	if r.run != nil && r.runProps == r.state.char && r.runLink == link {
+		return *r.run
+	}
+	var run document.Run
+	if link != nil {
+		run = link.AddRun()
+	} else {
+		run = p.AddRun()
+	}
+	r.applyCharProps(run.Properties(), r.state.char)
+	r.run = &run
+	r.runProps = r.state.char
+	r.runLink = link
+	return run
+}
+
+func (r *reader) applyCharProps(rp document.RunProperties, cp charProps) {
+	if cp.bold {
+		rp.SetBold(true)
+	}
+	if cp.italic {
+		rp.SetItalic(true)
+	}
+	if cp.strike {
+		rp.SetStrikeThrough(true)
+	}
+	if cp.dstrike {
+		rp.SetDoubleStrikeThrough(true)
+	}
+	if cp.caps {
+		rp.SetAllCaps(true)
+	}
+	if cp.smallCaps {
+		rp.SetSmallCaps(true)
+	}
+	if cp.hidden {
+		rp.X().Vanish = wml.NewCT_OnOff()
+	}
+	if cp.underline != wml.ST_UnderlineUnset {
+		rp.X().U = wml.NewCT_Underline()
+		rp.X().U.ValAttr = cp.underline
+	}
+	if cp.size > 0 {
+		rp.SetSize(measurement.Distance(cp.size) * measurement.HalfPoint)
+	}
+	if name, ok := r.fonts[cp.font]; ok && name != "" {
+		rp.SetFontFamily(name)
+	}
+	if cp.color > 0 && cp.color < len(r.colors) {
+		rp.SetColor(r.colors[cp.color])
+	}
+	if cp.vertAlign != sharedTypes.ST_VerticalAlignRunUnset {
+		rp.SetVerticalAlignment(cp.vertAlign)
+	}
+}
+
+// activeLink returns the hyperlink runs should be added to, if the current
+// group is the result of a HYPERLINK field.
+func (r *reader) activeLink(p document.Paragraph) *document.HyperLink {
+	f := r.state.field
+	if f == nil || r.state.dest != destText {
+		return nil
+	}
+	target, anchor := parseHyperlink(f.inst.String())
+	if target == "" && anchor == "" {
+		return nil
+	}
+	if f.link != nil && f.para != nil && f.para.X() == p.X() {
+		return f.link
+	}
+	hl := p.AddHyperLink()
+	if target != "" {
+		hl.SetTarget(target)
+	}
+	if anchor != "" {
+		a := anchor
+		hl.X().AnchorAttr = &a
+	}
+	f.link = &hl
+	f.para = &p
+	return f.link
+}
+
+// parseHyperlink extracts the target and bookmark anchor of a HYPERLINK
+// field instruction.
+func parseHyperlink(inst string) (target, anchor string) {
+	fields := splitInstruction(inst)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], "HYPERLINK") {
+		return "", ""
+	}
+	for i := 1; i < len(fields); i++ {
+		switch fields[i] {
+		case "\\l":
+			if i+1 < len(fields) {
+				anchor = fields[i+1]
+				i++
+			}
+		case "\\o", "\\t":
+			i++
+		default:
+			if !strings.HasPrefix(fields[i], "\\") && target == "" {
+				target = fields[i]
+			}
+		}
+	}
+	return target, anchor
+}
+
+// splitInstruction splits a field instruction into its arguments, honoring
+// double quotes.
+func splitInstruction(inst string) []string {
+	var res []string
+	cur := strings.Builder{}
+	quoted := false
+	flush := func() {
+		if cur.Len() > 0 {
+			res = append(res, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, c := range inst {
+		switch {
+		case c == '"':
+			if quoted {
+				res = append(res, cur.String())
+				cur.Reset()
+			} else {
+				flush()
+			}
+			quoted = !quoted
+		case (c == ' ' || c == '\t') && !quoted:
+			flush()
+		default:
+			cur.WriteRune(c)
+		}
+	}
+	flush()
+	return res
+}
+
+func (r *reader) endParagraph() {
+	if r.state.dest == destCollect {
+		if r.state.collect != nil {
+			r.state.collect.text.WriteByte('\n')
+		}
+		return
+	}
+	if r.state.dest != destText {
+		return
+	}
+	r.ensureParagraph()
+	r.para = nil
+	r.run = nil
+}
+
+// depth returns the table nesting level of the current paragraph, zero
+// outside of tables.
+func (r *reader) depth() int {
+	switch pp := r.state.para; {
+	case pp.depth > 0:
+		return pp.depth
+	case pp.inTable:
+		return 1
+	}
+	return 0
+}
+
+// rowDef returns the row definition of the current table nesting level.
+func (r *reader) rowDef() *rowDef {
+	depth := max(r.depth(), 1)
+	rd, ok := r.rowDefs[depth]
+	if !ok {
+		rd = &rowDef{}
+		r.rowDefs[depth] = rd
+	}
+	return rd
+}
+
+// cellAt returns the current cell of the table at the nesting level depth,
+// closing deeper tables and opening the tables, rows and cells required.
+func (r *reader) cellAt(depth int) document.Cell {
+	if len(r.tables) > depth {
+		r.tables = r.tables[:depth]
+	}
+	for len(r.tables) < depth {
+		var t document.Table
+		if len(r.tables) == 0 {
+			t = r.doc.AddTable()
+		} else {
+			t = r.cellAt(len(r.tables)).AddTable()
+			// a cell has to end with a paragraph
+			r.tables[len(r.tables)-1].cellHasPara = false
+		}
+		r.tables = append(r.tables, &tableLevel{table: t})
+	}
+	tl := r.tables[depth-1]
+	if tl.row == nil {
+		row := tl.table.AddRow()
+		tl.row = &row
+	}
+	if tl.cell == nil {
+		c := tl.row.AddCell()
+		tl.cell = &c
+		tl.cellHasPara = false
+	}
+	return *tl.cell
+}
+
+// endCell ends the current cell of the table at the nesting level depth.
+func (r *reader) endCell(depth int) {
+	if r.state.dest != destText {
+		return
+	}
+	c := r.cellAt(depth)
+	tl := r.tables[depth-1]
+	if !tl.cellHasPara {
+		r.applyParagraphProps(c.AddParagraph(), r.state.para)
+	}
+	r.para = nil
+	r.run = nil
+	tl.cell = nil
+	tl.cellHasPara = false
+}
+
+// endRow ends the current row of the table at the nesting level depth and
+// applies the row definition of the level to it.
+func (r *reader) endRow(depth int) {
+	if len(r.tables) < depth {
+		return
+	}
+	r.tables = r.tables[:depth]
+	tl := r.tables[depth-1]
+	if tl.row == nil {
+		return
+	}
+	rd := r.rowDefs[depth]
+	if rd == nil {
+		rd = &rowDef{}
+	}
+	left := 0
+	for i, c := range tl.row.Cells() {
+		if i >= len(rd.cells) {
+			break
+		}
+		def := rd.cells[i]
+		cp := c.Properties()
+		if w := def.right - left; w > 0 {
+			cp.SetWidth(measurement.Distance(w) * measurement.Twips)
+		}
+		left = def.right
+		if def.vmerge != wml.ST_MergeUnset {
+			cp.SetVerticalMerge(def.vmerge)
+		}
+		if def.shade > 0 && def.shade < len(r.colors) {
+			cp.SetShading(wml.ST_ShdClear, color.Auto, r.colors[def.shade])
+		}
+	}
+	if rd.height > 0 {
+		tl.row.Properties().SetHeight(measurement.Distance(rd.height)*measurement.Twips, wml.ST_HeightRuleAtLeast)
+	} else if rd.height < 0 {
+		tl.row.Properties().SetHeight(measurement.Distance(-rd.height)*measurement.Twips, wml.ST_HeightRuleExact)
+	}
+	if rd.header {
+		tl.row.Properties().SetTblHeader(true)
+	}
+	if rd.borders && tl.table.Properties().X().TblBorders == nil {
+		tl.table.Properties().Borders().SetAll(wml.ST_BorderSingle, color.Auto, measurement.HalfPoint)
+	}
+	tl.row = nil
+	tl.cell = nil
+	r.para = nil
+	r.run = nil
+}
+
+func (r *reader) closeTable() {
+	r.tables = nil
+}
+
+func (r *reader) pictWord(tok token) {
+	pc := r.pict
+	if pc == nil {
+		return
+	}
+	switch tok.word {
+	case "pngblip":
+		pc.format = "png"
+	case "jpegblip":
+		pc.format = "jpeg"
+	case "emfblip", "wmetafile", "macpict", "dibitmap", "wbitmap", "pmmetafile":
+		pc.format = ""
+	case "picwgoal":
+		pc.goalW = tok.param
+	case "pichgoal":
+		pc.goalH = tok.param
+	case "picw":
+		pc.width = tok.param
+	case "pich":
+		pc.height = tok.param
+	case "picscalex":
+		pc.scaleX = tok.param
+	case "picscaley":
+		pc.scaleY = tok.param
+	}
+}
+
+func (r *reader) addPicture() {
+	pc := r.pict
+	if pc == nil || pc.format == "" || r.state.dest != destText {
+		return
+	}
+	data := pc.data
+	if len(data) == 0 {
+		h := pc.hex.String()
+		if len(h)%2 == 1 {
+			h = h[:len(h)-1]
+		}
+		var err error
+		if data, err = hex.DecodeString(h); err != nil {
+			logger.Log.Debug("rtf: invalid picture data: %s", err)
+			return
+		}
+	}
+	img, err := common.ImageFromBytes(data)
+	if err != nil {
+		logger.Log.Debug("rtf: unable to decode picture: %s", err)
+		return
+	}
+	ref, err := r.doc.AddImage(img)
+	if err != nil {
+		logger.Log.Debug("rtf: unable to add picture: %s", err)
+		return
+	}
+	inl, err := r.ensureRun().AddDrawingInline(ref)
+	if err != nil {
+		logger.Log.Debug("rtf: unable to add picture: %s", err)
+		return
+	}
+	if pc.goalW > 0 && pc.goalH > 0 {
+		w := measurement.Distance(pc.goalW*pc.scaleX/100) * measurement.Twips
+		h := measurement.Distance(pc.goalH*pc.scaleY/100) * measurement.Twips
+		inl.SetSize(w, h)
+	}
+}
+
+func (r *reader) listWord(tok token) {
+	switch tok.word {
+	case "list":
+		r.curList = &listDef{}
+		r.curLevel = nil
+	case "listlevel":
+		if r.curList != nil {
+			r.curLevel = &listLevel{start: 1}
+			r.curList.levels = append(r.curList.levels, r.curLevel)
+		}
+	case "listid":
+		if r.curList != nil {
+			r.curList.id = tok.param
+			r.lists[tok.param] = r.curList
+		}
+	case "levelnfc", "levelnfcn":
+		if r.curLevel != nil {
+			r.curLevel.nfc = tok.param
+		}
+	case "levelstartat":
+		if r.curLevel != nil {
+			r.curLevel.start = tok.param
+		}
+	case "li", "lin":
+		if r.curLevel != nil {
+			r.curLevel.left = tok.param
+		}
+	case "fi":
+		if r.curLevel != nil {
+			r.curLevel.firstLine = tok.param
+		}
+	}
+}
+
+func (r *reader) levelTextRune(c rune) {
+	if r.curLevel != nil && c != ';' {
+		r.curLevel.text = append(r.curLevel.text, c)
+	}
+}
+
+// numberingFor returns the numbering definition for a list override index,
+// creating it on first use.
+func (r *reader) numberingFor(ls int) (document.NumberingDefinition, bool) {
+	if nd, ok := r.numbering[ls]; ok {
+		return nd, true
+	}
+	def, ok := r.lists[r.overrides[ls]]
+	if !ok {
+		defs := r.doc.Numbering.Definitions()
+		if len(defs) == 0 {
+			return document.NumberingDefinition{}, false
+		}
+		r.numbering[ls] = defs[0]
+		return defs[0], true
+	}
+	nd := r.doc.Numbering.AddDefinition()
+	for i, lv := range def.levels {
+		if i >= 9 {
+			break
+		}
+		l := nd.AddLevel()
+		l.SetFormat(numberFormat(lv.nfc))
+		l.SetText(levelText(lv))
+		l.X().Start.ValAttr = int64(lv.start)
+		if lv.left != 0 {
+			l.Properties().SetLeftIndent(measurement.Distance(lv.left) * measurement.Twips)
+		}
+		if lv.firstLine < 0 {
+			l.Properties().SetHangingIndent(measurement.Distance(-lv.firstLine) * measurement.Twips)
+		} else if lv.firstLine > 0 {
+			l.Properties().SetFirstLineIndent(measurement.Distance(lv.firstLine) * measurement.Twips)
+		}
+	}
+	r.numbering[ls] = nd
+	return nd, true
+}
+
+func numberFormat(nfc int) wml.ST_NumberFormat {
+	switch nfc {
+	case 0:
+		return wml.ST_NumberFormatDecimal
+	case 1:
+		return wml.ST_NumberFormatUpperRoman
+	case 2:
+		return wml.ST_NumberFormatLowerRoman
+	case 3:
+		return wml.ST_NumberFormatUpperLetter
+	case 4:
+		return wml.ST_NumberFormatLowerLetter
+	case 5:
+		return wml.ST_NumberFormatOrdinal
+	case 22:
+		return wml.ST_NumberFormatDecimalZero
+	case 23:
+		return wml.ST_NumberFormatBullet
+	case 255:
+		return wml.ST_NumberFormatNone
+	}
+	return wml.ST_NumberFormatDecimal
+}
+
+// levelText converts a RTF \leveltext, whose first character is its length
+// and whose level placeholders are the characters 0 to 8, into the
+// OOXML lvlText syntax.
+func levelText(lv *listLevel) string {
+	if len(lv.text) == 0 {
+		if lv.nfc == 23 {
+			return "•"
+		}
+		return "%1."
+	}
+	n := int(lv.text[0])
+	b := strings.Builder{}
+	for i, c := range lv.text[1:] {
+		if i >= n {
+			break
+		}
+		if c < 9 {
+			fmt.Fprintf(&b, "%%%d", c+1)
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func (r *reader) setInfo(target, value string) {
+	if value == "" {
+		return
+	}
+	switch target {
+	case "title":
+		r.doc.CoreProperties.SetTitle(value)
+	case "author":
+		r.doc.CoreProperties.SetAuthor(value)
+	case "operator":
+		r.doc.CoreProperties.SetLastModifiedBy(value)
+	case "doccomm":
+		r.doc.CoreProperties.SetDescription(value)
+	case "company":
+		r.doc.AppProperties.SetCompany(value)
+	}
+}
+
+func (r *reader) addCollected(c *collector) {
+	if c == nil {
+		return
+	}
+	text := strings.TrimRight(c.text.String(), "\n")
+	switch c.kind {
+	case collectFootnote:
+		if text != "" {
+			r.ensureParagraph().AddFootnote(strings.TrimSpace(text))
+			r.run = nil
+		}
+	case collectHeader:
+		hdr := r.doc.AddHeader()
+		for _, line := range strings.Split(text, "\n") {
+			hdr.AddParagraph().AddRun().AddText(line)
+		}
+		r.doc.BodySection().SetHeader(hdr, wml.ST_HdrFtrDefault)
+	case collectFooter:
+		ftr := r.doc.AddFooter()
+		for _, line := range strings.Split(text, "\n") {
+			ftr.AddParagraph().AddRun().AddText(line)
+		}
+		r.doc.BodySection().SetFooter(ftr, wml.ST_HdrFtrDefault)
+	}
+}
+
+func (r *reader) finishSection() {
+	if !r.sawPage {
+		return
+	}
+	sec := r.doc.BodySection()
+	if r.pageW > 0 && r.pageH > 0 {
+		orient := wml.ST_PageOrientationPortrait
+		if r.landscape == 1 {
+			orient = wml.ST_PageOrientationLandscape
+		}
+		sec.SetPageSizeAndOrientation(measurement.Distance(r.pageW)*measurement.Twips,
+			measurement.Distance(r.pageH)*measurement.Twips, orient)
+	}
+	if r.marginL > 0 || r.marginR > 0 || r.marginT > 0 || r.marginB > 0 {
+		tw := func(v, def int) measurement.Distance {
+			if v <= 0 {
+				v = def
+			}
+			return measurement.Distance(v) * measurement.Twips
+		}
+		sec.SetPageMargins(tw(r.marginT, 1440), tw(r.marginR, 1800), tw(r.marginB, 1440), tw(r.marginL, 1800),
+			tw(0, 720), tw(0, 720), 0)
+	}
+}
+
+func underlineIf(on bool, u wml.ST_Underline) wml.ST_Underline {
+	if on {
+		return u
+	}
+	return wml.ST_UnderlineUnset
+}
+
+// codePages maps the code pages of RTF documents and fonts to their
+// encodings. Windows-1252 is decoded by decodeANSI.
+var codePages = map[int]encoding.Encoding{
+	437: charmap.CodePage437, 850: charmap.CodePage850, 852: charmap.CodePage852, 866: charmap.CodePage866,
+	874: charmap.Windows874, 932: japanese.ShiftJIS, 936: simplifiedchinese.GBK, 949: korean.EUCKR,
+	950: traditionalchinese.Big5, 1250: charmap.Windows1250, 1251: charmap.Windows1251, 1253: charmap.Windows1253,
+	1254: charmap.Windows1254, 1255: charmap.Windows1255, 1256: charmap.Windows1256, 1257: charmap.Windows1257,
+	1258: charmap.Windows1258, 10000: charmap.Macintosh,
+}
+
+// charsetCodePage returns the code page of a \fcharset value, zero if the
+// code page of the document applies.
+func charsetCodePage(charset int) int {
+	switch charset {
+	case 77:
+		return 10000
+	case 128:
+		return 932
+	case 129:
+		return 949
+	case 134:
+		return 936
+	case 136:
+		return 950
+	case 161:
+		return 1253
+	case 162:
+		return 1254
+	case 163:
+		return 1258
+	case 177:
+		return 1255
+	case 178:
+		return 1256
+	case 186:
+		return 1257
+	case 204:
+		return 1251
+	case 222:
+		return 874
+	case 238:
+		return 1250
+	case 255:
+		return 437
+	}
+	return 0
+}
+
+func unicodeParam(p int) rune {
+	if p < 0 {
+		p += 65536
+	}
+	return rune(p)
+}
+
+func isHexDigit(c rune) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,73 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+/*
+Package rtf provides conversion between Rich Text Format (RTF) files and
+document.Document.
+
+The reader understands paragraph and character formatting, the font and color
+tables, tables, lists, PNG/JPEG pictures, hyperlink fields and document
+information.  The writer produces RTF 1.9 compatible output from the body of
+a document.
+
+Example:
+
+	doc, err := rtf.Open("letter.rtf")
+	if err != nil {
+		log.Fatal(err)
+	}
+	doc.SaveToFile("letter.docx")
+*/
+package rtf
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/unidoc/unioffice/v2/document"
+)
+
+// Open opens and reads a RTF file into a new document.
+func Open(filename string) (*document.Document, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read reads RTF content from r and builds a new document from it.
+func Read(r io.Reader) (*document.Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return newReader(data).read()
+}
+
+// Write writes the document d to w in the RTF format.
+func Write(w io.Writer, d *document.Document) error {
+	bw := bufio.NewWriter(w)
+	if err := newWriter(d).write(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// SaveToFile writes the document d to a RTF file at path.
+func SaveToFile(d *document.Document, path string) error {
+	buf := bytes.Buffer{}
+	if err := Write(&buf, d); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
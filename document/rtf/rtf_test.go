@@ -0,0 +1,261 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package rtf
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+func TestLexer(t *testing.T) {
+	td := []struct {
+		in  string
+		exp []token
+	}{
+		{`{\b bold}`, []token{
+			{kind: tokenGroupStart},
+			{kind: tokenControlWord, word: "b"},
+			{kind: tokenText, text: []byte("bold")},
+			{kind: tokenGroupEnd},
+		}},
+		{`\fs24\li-360 x`, []token{
+			{kind: tokenControlWord, word: "fs", param: 24, hasParam: true},
+			{kind: tokenControlWord, word: "li", param: -360, hasParam: true},
+			{kind: tokenText, text: []byte("x")},
+		}},
+		{`\'e9\{\\`, []token{
+			{kind: tokenControlSymbol, word: "'", param: 0xe9, hasParam: true},
+			{kind: tokenControlSymbol, word: "{"},
+			{kind: tokenControlSymbol, word: "\\"},
+		}},
+		{"a\r\nb\\\nc", []token{
+			{kind: tokenText, text: []byte("a")},
+			{kind: tokenText, text: []byte("b")},
+			{kind: tokenControlWord, word: "par"},
+			{kind: tokenText, text: []byte("c")},
+		}},
+		{`\bin3 {}\x`, []token{
+			{kind: tokenBinary, word: "bin", param: 3, hasParam: true, text: []byte("{}\\")},
+			{kind: tokenText, text: []byte("x")},
+		}},
+		{`\u-3913?`, []token{
+			{kind: tokenControlWord, word: "u", param: -3913, hasParam: true},
+			{kind: tokenText, text: []byte("?")},
+		}},
+		{`\x-`, []token{
+			{kind: tokenControlWord, word: "x"},
+			{kind: tokenText, text: []byte("-")},
+		}},
+	}
+	for _, tc := range td {
+		l := lexer{data: []byte(tc.in)}
+		got := []token{}
+		for tok := l.next(); tok.kind != tokenEOF; tok = l.next() {
+			got = append(got, tok)
+		}
+		if !reflect.DeepEqual(got, tc.exp) {
+			t.Errorf("lexing %q gave %+v, expected %+v", tc.in, got, tc.exp)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	td := []struct {
+		in  string
+		exp string
+	}{
+		{"plain", "plain"},
+		{`a\b{c}`, `a\\b\{c\}`},
+		{"a\tb", `a\tab b`},
+		{"é€", `\'e9\'80`},
+		{"Ω", `\u937?`},
+		{"ﬁ", `\u-1279?`},
+		{"😀", `\u-10179?\u-8704?`},
+	}
+	for _, tc := range td {
+		if got := escape(tc.in); got != tc.exp {
+			t.Errorf("escape(%q) = %q, expected %q", tc.in, got, tc.exp)
+		}
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	td := []string{
+		"plain text",
+		`back\slash {braces}`,
+		"café €5 – “quoted”",
+		"Ωμέγα ﬁ 😀",
+	}
+	for _, s := range td {
+		d, err := Read(strings.NewReader(`{\rtf1\ansi ` + escape(s) + `\par}`))
+		if err != nil {
+			t.Errorf("error reading %q: %s", s, err)
+			continue
+		}
+		if got := paragraphTexts(d); len(got) != 1 || got[0] != s {
+			t.Errorf("round trip of %q gave %q", s, got)
+		}
+	}
+}
+
+func TestReadCharacters(t *testing.T) {
+	td := []struct {
+		in, exp string
+	}{
+		// surrogate pair
+		{`{\rtf1\ansi\uc1 smile \u-10179?\u-8704?\par}`, "smile 😀"},
+		{`{\rtf1\ansi\uc0 \u55357\u56832 \par}`, "😀"},
+		// unpaired surrogates
+		{`{\rtf1\ansi\uc1 a\u-10179?b\par}`, "a\uFFFDb"},
+		// document code page
+		{`{\rtf1\ansi\ansicpg1251 \'cf\'f0\'e8\'e2\'e5\'f2\par}`, "Привет"},
+		// font character sets, including a double byte one
+		{`{\rtf1\ansi\ansicpg1252\deff0{\fonttbl{\f0\fcharset0 Arial;}{\f1\fcharset204 Arial;}{\f2\fcharset128 MS Mincho;}}` +
+			`\'e9 {\f1 \'e4\'e0} {\f2 \'93\'fa\'96\'7b}\par}`, "é да 日本"},
+	}
+	for _, tc := range td {
+		d, err := Read(strings.NewReader(tc.in))
+		if err != nil {
+			t.Errorf("error reading %q: %s", tc.in, err)
+			continue
+		}
+		if got := paragraphTexts(d); len(got) != 1 || got[0] != tc.exp {
+			t.Errorf("reading %q gave %q, expected %q", tc.in, got, tc.exp)
+		}
+	}
+}
+
+func TestReadNestedTable(t *testing.T) {
+	in := `{\rtf1\ansi
+\trowd\cellx2000\cellx4000
+\pard\intbl\itap1 outer1\cell
+\pard\intbl\itap1 before\par
+\pard\intbl\itap2 a\nestcell b\nestcell
+{\*\nesttableprops\trowd\cellx1000\cellx2000\nestrow}{\nonesttables\par}
+\pard\intbl\itap2 c\nestcell d\nestcell
+{\*\nesttableprops\trowd\cellx1000\cellx2000\nestrow}{\nonesttables\par}
+\pard\intbl\itap1 after\cell
+\pard\intbl\itap1 {\trowd\cellx2000\cellx4000\row}
+\pard text\par}`
+	d, err := Read(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("error reading: %s", err)
+	}
+	tables := d.Tables()
+	if len(tables) != 1 || len(tables[0].Rows()) != 1 {
+		t.Fatalf("expected one table with one row, got %d tables", len(tables))
+	}
+	cells := tables[0].Rows()[0].Cells()
+	if len(cells) != 2 {
+		t.Fatalf("expected 2 cells, got %d", len(cells))
+	}
+	texts := []string{}
+	for _, p := range cells[1].Paragraphs() {
+		s := ""
+		for _, r := range p.Runs() {
+			s += r.Text()
+		}
+		texts = append(texts, s)
+	}
+	if exp := []string{"before", "after"}; !reflect.DeepEqual(texts, exp) {
+		t.Errorf("expected the paragraphs %q in the second cell, got %q", exp, texts)
+	}
+	nested := []*wml.CT_Tbl{}
+	for _, ble := range cells[1].X().EG_BlockLevelElts {
+		for _, c := range ble.BlockLevelEltsChoice.EG_ContentBlockContent {
+			nested = append(nested, c.ContentBlockContentChoice.Tbl...)
+		}
+	}
+	if len(nested) != 1 || len(nested[0].EG_ContentRowContent) != 2 {
+		t.Errorf("expected a nested table with 2 rows in the second cell, got %d tables", len(nested))
+	}
+	if got := paragraphTexts(d); !reflect.DeepEqual(got, []string{"text"}) {
+		t.Errorf("expected the nested table not to be flattened, got body paragraphs %q", got)
+	}
+}
+
+func TestParseHyperlink(t *testing.T) {
+	td := []struct {
+		inst, target, anchor string
+	}{
+		{`HYPERLINK "https://example.com"`, "https://example.com", ""},
+		{`HYPERLINK \l "top"`, "", "top"},
+		{`HYPERLINK "a b.docx" \l "sec" \o "tip"`, "a b.docx", "sec"},
+		{`hyperlink \t "_blank" "x"`, "x", ""},
+		{`PAGE`, "", ""},
+	}
+	for _, tc := range td {
+		target, anchor := parseHyperlink(tc.inst)
+		if target != tc.target || anchor != tc.anchor {
+			t.Errorf("parseHyperlink(%q) = %q, %q, expected %q, %q", tc.inst, target, anchor, tc.target, tc.anchor)
+		}
+	}
+}
+
+func TestLevelText(t *testing.T) {
+	td := []struct {
+		in, exp string
+	}{
+		{"%1.", `\'02\'00.`},
+		{"%1.%2)", `\'04\'00.\'01)`},
+		{"•", `\'01\'95`},
+	}
+	for _, tc := range td {
+		if got := levelTextRTF(tc.in); got != tc.exp {
+			t.Errorf("levelTextRTF(%q) = %q, expected %q", tc.in, got, tc.exp)
+		}
+	}
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	d := document.New()
+	p := d.AddParagraph()
+	r := p.AddRun()
+	r.Properties().SetBold(true)
+	r.AddText("Bold")
+	p.AddRun().AddText(" and {plain}")
+	d.AddParagraph().AddRun().AddText("Second ü paragraph")
+	buf := bytes.Buffer{}
+	if err := Write(&buf, d); err != nil {
+		t.Fatalf("error writing: %s", err)
+	}
+	rd, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("error reading: %s", err)
+	}
+	exp := []string{"Bold and {plain}", "Second ü paragraph"}
+	if got := paragraphTexts(rd); !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected paragraphs %q, got %q", exp, got)
+	}
+	if ps := rd.Paragraphs(); len(ps) > 0 {
+		runs := ps[0].Runs()
+		if len(runs) == 0 || !runs[0].Properties().IsBold() {
+			t.Errorf("expected the first run to be bold")
+		}
+	}
+}
+
+// paragraphTexts returns the text of each body paragraph of d.
+func paragraphTexts(d *document.Document) []string {
+	res := []string{}
+	for _, p := range d.Paragraphs() {
+		s := ""
+		for _, r := range p.Runs() {
+			s += r.Text()
+		}
+		res = append(res, s)
+	}
+	return res
+}
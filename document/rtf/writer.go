@@ -0,0 +1,953 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package rtf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/common/tempstorage"
+	"github.com/unidoc/unioffice/v2/document"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml/picture"
+	"github.com/unidoc/unioffice/v2/schema/soo/ofc/sharedTypes"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+const defaultCellWidth = 2000
+
+// writer serializes a document as RTF. The body is written first so the font
+// and color tables can be collected on the way.
+type writer struct {
+	doc      *document.Document
+	body     bytes.Buffer
+	fonts    []string
+	fontIdx  map[string]int
+	colors   []string
+	colorIdx map[string]int
+	styles   map[string]int
+	numbers  map[[2]int64]int
+	lists    []int64
+	listIdx  map[int64]int
+}
+
+func newWriter(d *document.Document) *writer {
+	return &writer{doc: d, fontIdx: map[string]int{}, colorIdx: map[string]int{},
+		styles: map[string]int{}, numbers: map[[2]int64]int{}, listIdx: map[int64]int{}}
+}
+
+func (w *writer) write(out *bufio.Writer) error {
+	w.font("Calibri")
+	if body := w.doc.X().Body; body != nil {
+		for _, ble := range body.EG_BlockLevelElts {
+			w.blockContent(ble.BlockLevelEltsChoice.EG_ContentBlockContent, false)
+		}
+		if sect := body.SectPr; sect != nil {
+			w.section(sect)
+		}
+	}
+	out.WriteString("{\\rtf1\\ansi\\ansicpg1252\\deff0\\uc1\n{\\fonttbl")
+	for i, f := range w.fonts {
+		fmt.Fprintf(out, "{\\f%d\\fnil %s;}", i, escape(f))
+	}
+	out.WriteString("}\n{\\colortbl;")
+	for _, c := range w.colors {
+		var r, g, b uint8
+		fmt.Sscanf(c, "%02x%02x%02x", &r, &g, &b)
+		fmt.Fprintf(out, "\\red%d\\green%d\\blue%d;", r, g, b)
+	}
+	out.WriteString("}\n{\\stylesheet{\\s0 Normal;}")
+	for lvl := 1; lvl <= 9; lvl++ {
+		fmt.Fprintf(out, "{\\s%d\\outlinelevel%d heading %d;}", lvl, lvl-1, lvl)
+	}
+	out.WriteString("}\n")
+	w.listTable(out)
+	w.info(out)
+	_, err := out.Write(w.body.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = out.WriteString("}\n")
+	return err
+}
+
+func (w *writer) info(out *bufio.Writer) {
+	cp := w.doc.CoreProperties
+	if cp.X() == nil {
+		return
+	}
+	out.WriteString("{\\info")
+	if t := cp.Title(); t != "" {
+		fmt.Fprintf(out, "{\\title %s}", escape(t))
+	}
+	if a := cp.Author(); a != "" {
+		fmt.Fprintf(out, "{\\author %s}", escape(a))
+	}
+	if m := cp.LastModifiedBy(); m != "" {
+		fmt.Fprintf(out, "{\\operator %s}", escape(m))
+	}
+	if d := cp.Description(); d != "" {
+		fmt.Fprintf(out, "{\\doccomm %s}", escape(d))
+	}
+	out.WriteString("}\n")
+}
+
+func (w *writer) font(name string) int {
+	if i, ok := w.fontIdx[name]; ok {
+		return i
+	}
+	w.fonts = append(w.fonts, name)
+	w.fontIdx[name] = len(w.fonts) - 1
+	return len(w.fonts) - 1
+}
+
+// color returns the color table index of a hex RGB color, index zero is
+// the automatic color.
+func (w *writer) color(rgb string) int {
+	rgb = strings.ToLower(strings.TrimPrefix(rgb, "#"))
+	if len(rgb) != 6 {
+		return 0
+	}
+	if i, ok := w.colorIdx[rgb]; ok {
+		return i
+	}
+	w.colors = append(w.colors, rgb)
+	w.colorIdx[rgb] = len(w.colors)
+	return len(w.colors)
+}
+
+func (w *writer) section(sect *wml.CT_SectPr) {
+	b := &w.body
+	if sz := sect.PgSz; sz != nil {
+		if sz.WAttr != nil && sz.WAttr.ST_UnsignedDecimalNumber != nil {
+			fmt.Fprintf(b, "\\paperw%d", *sz.WAttr.ST_UnsignedDecimalNumber)
+		}
+		if sz.HAttr != nil && sz.HAttr.ST_UnsignedDecimalNumber != nil {
+			fmt.Fprintf(b, "\\paperh%d", *sz.HAttr.ST_UnsignedDecimalNumber)
+		}
+		if sz.OrientAttr == wml.ST_PageOrientationLandscape {
+			b.WriteString("\\landscape")
+		}
+	}
+	if m := sect.PgMar; m != nil {
+		if m.LeftAttr.ST_UnsignedDecimalNumber != nil {
+			fmt.Fprintf(b, "\\margl%d", *m.LeftAttr.ST_UnsignedDecimalNumber)
+		}
+		if m.RightAttr.ST_UnsignedDecimalNumber != nil {
+			fmt.Fprintf(b, "\\margr%d", *m.RightAttr.ST_UnsignedDecimalNumber)
+		}
+		if m.TopAttr.Int64 != nil {
+			fmt.Fprintf(b, "\\margt%d", *m.TopAttr.Int64)
+		}
+		if m.BottomAttr.Int64 != nil {
+			fmt.Fprintf(b, "\\margb%d", *m.BottomAttr.Int64)
+		}
+	}
+	b.WriteString("\n")
+}
+
+func (w *writer) blockContent(cbc []*wml.EG_ContentBlockContent, inTable bool) {
+	for _, c := range cbc {
+		if sdt := c.ContentBlockContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			w.blockContent(sdt.SdtContent.EG_ContentBlockContent, inTable)
+		}
+		for _, p := range c.ContentBlockContentChoice.P {
+			w.paragraph(p, inTable)
+		}
+		for _, tbl := range c.ContentBlockContentChoice.Tbl {
+			if inTable {
+				// nested tables are flattened into the enclosing cell
+				w.flattenTable(tbl)
+				continue
+			}
+			w.table(tbl)
+		}
+	}
+}
+
+func (w *writer) paragraph(p *wml.CT_P, inTable bool) {
+	w.paragraphStart(p, inTable)
+	w.paragraphContent(p.EG_PContent)
+	if !inTable {
+		w.body.WriteString("\\par\n")
+	}
+}
+
+func (w *writer) paragraphStart(p *wml.CT_P, inTable bool) {
+	b := &w.body
+	b.WriteString("\\pard\\plain")
+	if inTable {
+		b.WriteString("\\intbl")
+	}
+	ppr := p.PPr
+	if ppr == nil {
+		b.WriteByte(' ')
+		return
+	}
+	if ppr.PStyle != nil {
+		if lvl := w.headingLevel(ppr.PStyle.ValAttr); lvl > 0 {
+			fmt.Fprintf(b, "\\s%d\\outlinelevel%d", lvl, lvl-1)
+		}
+	}
+	if ppr.OutlineLvl != nil {
+		fmt.Fprintf(b, "\\outlinelevel%d", ppr.OutlineLvl.ValAttr)
+	}
+	if ppr.Jc != nil {
+		switch ppr.Jc.ValAttr {
+		case wml.ST_JcCenter:
+			b.WriteString("\\qc")
+		case wml.ST_JcRight, wml.ST_JcEnd:
+			b.WriteString("\\qr")
+		case wml.ST_JcBoth, wml.ST_JcDistribute:
+			b.WriteString("\\qj")
+		default:
+			b.WriteString("\\ql")
+		}
+	}
+	if ind := ppr.Ind; ind != nil {
+		if v, ok := signedTwips(ind.LeftAttr); ok {
+			fmt.Fprintf(b, "\\li%d", v)
+		} else if v, ok := signedTwips(ind.StartAttr); ok {
+			fmt.Fprintf(b, "\\li%d", v)
+		}
+		if v, ok := signedTwips(ind.RightAttr); ok {
+			fmt.Fprintf(b, "\\ri%d", v)
+		} else if v, ok := signedTwips(ind.EndAttr); ok {
+			fmt.Fprintf(b, "\\ri%d", v)
+		}
+		if v, ok := twips(ind.FirstLineAttr); ok {
+			fmt.Fprintf(b, "\\fi%d", v)
+		} else if v, ok := twips(ind.HangingAttr); ok {
+			fmt.Fprintf(b, "\\fi-%d", v)
+		}
+	}
+	if sp := ppr.Spacing; sp != nil {
+		if v, ok := twips(sp.BeforeAttr); ok {
+			fmt.Fprintf(b, "\\sb%d", v)
+		}
+		if v, ok := twips(sp.AfterAttr); ok {
+			fmt.Fprintf(b, "\\sa%d", v)
+		}
+		if v, ok := signedTwips(sp.LineAttr); ok {
+			switch sp.LineRuleAttr {
+			case wml.ST_LineSpacingRuleExact:
+				fmt.Fprintf(b, "\\sl-%d\\slmult0", v)
+			case wml.ST_LineSpacingRuleAtLeast:
+				fmt.Fprintf(b, "\\sl%d\\slmult0", v)
+			default:
+				fmt.Fprintf(b, "\\sl%d\\slmult1", v)
+			}
+		}
+	}
+	if ppr.PageBreakBefore != nil {
+		b.WriteString("\\pagebb")
+	}
+	if ppr.KeepNext != nil {
+		b.WriteString("\\keepn")
+	}
+	if ppr.KeepLines != nil {
+		b.WriteString("\\keep")
+	}
+	np := ppr.NumPr
+	if np != nil && np.NumId != nil {
+		ilvl := int64(0)
+		if np.Ilvl != nil {
+			ilvl = np.Ilvl.ValAttr
+		}
+		fmt.Fprintf(b, "\\ls%d\\ilvl%d", w.list(np.NumId.ValAttr), ilvl)
+	}
+	b.WriteByte(' ')
+	if np != nil && np.NumId != nil {
+		w.listText(np)
+	}
+}
+
+// headingLevel returns the heading level of a paragraph style or zero.
+func (w *writer) headingLevel(styleID string) int {
+	if lvl, ok := w.styles[styleID]; ok {
+		return lvl
+	}
+	lvl := 0
+	name := strings.ToLower(w.doc.GetStyleByID(styleID).Name())
+	if name == "" {
+		name = strings.ToLower(styleID)
+	}
+	name = strings.ReplaceAll(name, " ", "")
+	if strings.HasPrefix(name, "heading") {
+		fmt.Sscanf(strings.TrimPrefix(name, "heading"), "%d", &lvl)
+		if lvl < 1 || lvl > 9 {
+			lvl = 0
+		}
+	}
+	w.styles[styleID] = lvl
+	return lvl
+}
+
+// listText writes the rendered list label of a numbered paragraph, which
+// RTF readers without list support display as is.
+func (w *writer) listText(np *wml.CT_NumPr) {
+	numID := np.NumId.ValAttr
+	ilvl := int64(0)
+	if np.Ilvl != nil {
+		ilvl = np.Ilvl.ValAttr
+	}
+	lvl := w.doc.GetNumberingLevelByIds(numID, ilvl).X()
+	if lvl == nil {
+		return
+	}
+	key := [2]int64{numID, ilvl}
+	for k := range w.numbers {
+		// restart deeper levels when a higher level advances
+		if k[0] == numID && k[1] > ilvl {
+			delete(w.numbers, k)
+		}
+	}
+	start := 1
+	if lvl.Start != nil {
+		start = int(lvl.Start.ValAttr)
+	}
+	n, ok := w.numbers[key]
+	if !ok {
+		n = start
+	} else {
+		n++
+	}
+	w.numbers[key] = n
+	label := "•"
+	if lvl.NumFmt != nil && lvl.NumFmt.ValAttr != wml.ST_NumberFormatBullet {
+		text := "%1."
+		if lvl.LvlText != nil && lvl.LvlText.ValAttr != nil {
+			text = *lvl.LvlText.ValAttr
+		}
+		label = strings.ReplaceAll(text, fmt.Sprintf("%%%d", ilvl+1), formatNumber(n, lvl.NumFmt.ValAttr))
+		for i := int64(0); i < ilvl; i++ {
+			label = strings.ReplaceAll(label, fmt.Sprintf("%%%d", i+1), fmt.Sprint(w.numbers[[2]int64{numID, i}]))
+		}
+	}
+	fmt.Fprintf(&w.body, "{\\listtext %s\\tab}", escape(label))
+}
+
+// list returns the list override index of a numbering instance, the list
+// is written to the list table.
+func (w *writer) list(numID int64) int {
+	if i, ok := w.listIdx[numID]; ok {
+		return i
+	}
+	w.lists = append(w.lists, numID)
+	w.listIdx[numID] = len(w.lists)
+	return len(w.lists)
+}
+
+// listTable writes the list and list override tables of the numbering
+// instances used by the body, list i has the ID i and override index i.
+func (w *writer) listTable(out *bufio.Writer) {
+	if len(w.lists) == 0 {
+		return
+	}
+	out.WriteString("{\\*\\listtable")
+	for i, numID := range w.lists {
+		out.WriteString("{\\list\\listtemplateid0\\listhybrid")
+		for ilvl := int64(0); ilvl < 9; ilvl++ {
+			lvl := w.doc.GetNumberingLevelByIds(numID, ilvl).X()
+			if lvl == nil {
+				break
+			}
+			start := int64(1)
+			if lvl.Start != nil {
+				start = lvl.Start.ValAttr
+			}
+			nfc, text := 0, "%1."
+			if lvl.NumFmt != nil {
+				nfc = levelNfc(lvl.NumFmt.ValAttr)
+			}
+			if lvl.LvlText != nil && lvl.LvlText.ValAttr != nil {
+				text = *lvl.LvlText.ValAttr
+			}
+			fmt.Fprintf(out, "{\\listlevel\\levelnfc%d\\levelnfcn%d\\leveljc0\\levelfollow0\\levelstartat%d", nfc, nfc, start)
+			fmt.Fprintf(out, "{\\leveltext%s;}{\\levelnumbers;}", levelTextRTF(text))
+			if ppr := lvl.PPr; ppr != nil && ppr.Ind != nil {
+				if v, ok := signedTwips(ppr.Ind.LeftAttr); ok {
+					fmt.Fprintf(out, "\\li%d", v)
+				}
+				if v, ok := twips(ppr.Ind.HangingAttr); ok {
+					fmt.Fprintf(out, "\\fi-%d", v)
+				} else if v, ok := twips(ppr.Ind.FirstLineAttr); ok {
+					fmt.Fprintf(out, "\\fi%d", v)
+				}
+			}
+			out.WriteString("}")
+		}
+		fmt.Fprintf(out, "\\listid%d}", i+1)
+	}
+	out.WriteString("}\n{\\*\\listoverridetable")
+	for i := range w.lists {
+		fmt.Fprintf(out, "{\\listoverride\\listid%d\\listoverridecount0\\ls%d}", i+1, i+1)
+	}
+	out.WriteString("}\n")
+}
+
+// levelNfc returns the RTF number type of a numbering format.
+func levelNfc(f wml.ST_NumberFormat) int {
+	switch f {
+	case wml.ST_NumberFormatUpperRoman:
+		return 1
+	case wml.ST_NumberFormatLowerRoman:
+		return 2
+	case wml.ST_NumberFormatUpperLetter:
+		return 3
+	case wml.ST_NumberFormatLowerLetter:
+		return 4
+	case wml.ST_NumberFormatOrdinal:
+		return 5
+	case wml.ST_NumberFormatDecimalZero:
+		return 22
+	case wml.ST_NumberFormatBullet:
+		return 23
+	case wml.ST_NumberFormatNone:
+		return 255
+	}
+	return 0
+}
+
+// levelTextRTF converts an OOXML lvlText into a RTF \leveltext, whose first
+// character is its length and whose level placeholders are the characters 0
+// to 8.
+func levelTextRTF(text string) string {
+	b := strings.Builder{}
+	n := 0
+	rs := []rune(text)
+	for i := 0; i < len(rs); i++ {
+		if rs[i] == '%' && i+1 < len(rs) && rs[i+1] >= '1' && rs[i+1] <= '9' {
+			fmt.Fprintf(&b, "\\'%02x", rs[i+1]-'1')
+			i++
+		} else {
+			b.WriteString(escape(string(rs[i])))
+		}
+		n++
+	}
+	return fmt.Sprintf("\\'%02x", n) + b.String()
+}
+
+func formatNumber(n int, f wml.ST_NumberFormat) string {
+	switch f {
+	case wml.ST_NumberFormatLowerLetter:
+		return string(rune('a' + (n-1)%26))
+	case wml.ST_NumberFormatUpperLetter:
+		return string(rune('A' + (n-1)%26))
+	case wml.ST_NumberFormatLowerRoman:
+		return strings.ToLower(roman(n))
+	case wml.ST_NumberFormatUpperRoman:
+		return roman(n)
+	}
+	return fmt.Sprint(n)
+}
+
+func roman(n int) string {
+	vals := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	syms := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+	b := strings.Builder{}
+	for i, v := range vals {
+		for n >= v {
+			b.WriteString(syms[i])
+			n -= v
+		}
+	}
+	return b.String()
+}
+
+func (w *writer) paragraphContent(pcs []*wml.EG_PContent) {
+	for _, pc := range pcs {
+		w.runContent(pc.PContentChoice.EG_ContentRunContent)
+		if hl := pc.PContentChoice.Hyperlink; hl != nil {
+			w.hyperlink(hl)
+		}
+		for _, fs := range pc.PContentChoice.FldSimple {
+			fmt.Fprintf(&w.body, "{\\field{\\*\\fldinst %s}{\\fldrslt ", escape(fs.InstrAttr))
+			w.paragraphContent(fs.EG_PContent)
+			w.body.WriteString("}}")
+		}
+	}
+}
+
+func (w *writer) hyperlink(hl *wml.CT_Hyperlink) {
+	inst := ""
+	if hl.IdAttr != nil {
+		if target := w.doc.GetTargetByRelId(*hl.IdAttr); target != "" {
+			inst = fmt.Sprintf("HYPERLINK \"%s\"", target)
+		}
+	}
+	if hl.AnchorAttr != nil {
+		if inst == "" {
+			inst = "HYPERLINK"
+		}
+		inst += fmt.Sprintf(" \\l \"%s\"", *hl.AnchorAttr)
+	}
+	if inst == "" {
+		w.runContent(hl.PContentChoice.EG_ContentRunContent)
+		return
+	}
+	fmt.Fprintf(&w.body, "{\\field{\\*\\fldinst %s}{\\fldrslt ", escape(inst))
+	w.runContent(hl.PContentChoice.EG_ContentRunContent)
+	w.body.WriteString("}}")
+}
+
+func (w *writer) runContent(crcs []*wml.EG_ContentRunContent) {
+	for _, crc := range crcs {
+		if r := crc.ContentRunContentChoice.R; r != nil {
+			w.run(r)
+		}
+		if sdt := crc.ContentRunContentChoice.Sdt; sdt != nil && sdt.SdtContent != nil {
+			w.paragraphContent(sdt.SdtContent.EG_PContent)
+		}
+		for _, rle := range crc.ContentRunContentChoice.EG_RunLevelElts {
+			for _, rme := range rle.RunLevelEltsChoice.EG_RangeMarkupElements {
+				if bs := rme.RangeMarkupElementsChoice.BookmarkStart; bs != nil && bs.NameAttr != "" {
+					fmt.Fprintf(&w.body, "{\\*\\bkmkstart %s}{\\*\\bkmkend %s}", escape(bs.NameAttr), escape(bs.NameAttr))
+				}
+			}
+		}
+	}
+}
+
+func (w *writer) run(r *wml.CT_R) {
+	b := &w.body
+	open := false
+	ensureOpen := func() {
+		if !open {
+			b.WriteByte('{')
+			b.WriteString(w.runFormatting(r.RPr))
+			b.WriteByte(' ')
+			open = true
+		}
+	}
+	closeGroup := func() {
+		if open {
+			b.WriteByte('}')
+			open = false
+		}
+	}
+	for _, ric := range r.EG_RunInnerContent {
+		c := ric.RunInnerContentChoice
+		switch {
+		case c.T != nil:
+			ensureOpen()
+			b.WriteString(escape(c.T.Content))
+		case c.Tab != nil:
+			ensureOpen()
+			b.WriteString("\\tab ")
+		case c.Br != nil:
+			ensureOpen()
+			if c.Br.TypeAttr == wml.ST_BrTypePage {
+				b.WriteString("\\page ")
+			} else {
+				b.WriteString("\\line ")
+			}
+		case c.Cr != nil:
+			ensureOpen()
+			b.WriteString("\\line ")
+		case c.NoBreakHyphen != nil:
+			ensureOpen()
+			b.WriteString("\\_")
+		case c.FldChar != nil:
+			closeGroup()
+			switch c.FldChar.FldCharTypeAttr {
+			case wml.ST_FldCharTypeBegin:
+				b.WriteString("{\\field{\\*\\fldinst ")
+			case wml.ST_FldCharTypeSeparate:
+				b.WriteString("}{\\fldrslt ")
+			case wml.ST_FldCharTypeEnd:
+				b.WriteString("}}")
+			}
+		case c.InstrText != nil:
+			closeGroup()
+			b.WriteString(escape(c.InstrText.Content))
+		case c.FootnoteReference != nil:
+			ensureOpen()
+			w.noteReference(w.doc.Footnote(c.FootnoteReference.IdAttr).Paragraphs(), "")
+		case c.EndnoteReference != nil:
+			ensureOpen()
+			w.noteReference(w.doc.Endnote(c.EndnoteReference.IdAttr).Paragraphs(), "\\ftnalt")
+		case c.Drawing != nil:
+			closeGroup()
+			for _, dc := range c.Drawing.DrawingChoice {
+				if dc.Inline != nil {
+					w.picture(dc.Inline.Graphic.GraphicData.Any, dc.Inline.Extent.CxAttr, dc.Inline.Extent.CyAttr)
+				}
+				if dc.Anchor != nil && dc.Anchor.Graphic != nil && dc.Anchor.Extent != nil {
+					w.picture(dc.Anchor.Graphic.GraphicData.Any, dc.Anchor.Extent.CxAttr, dc.Anchor.Extent.CyAttr)
+				}
+			}
+		}
+	}
+	closeGroup()
+}
+
+func (w *writer) noteReference(paras []document.Paragraph, kind string) {
+	b := &w.body
+	fmt.Fprintf(b, "{\\super\\chftn}{\\footnote%s\\pard\\plain {\\super\\chftn} ", kind)
+	for i, p := range paras {
+		if i > 0 {
+			b.WriteString("\\par ")
+		}
+		for _, r := range p.Runs() {
+			if isNoteRef(r.X()) {
+				continue
+			}
+			w.run(r.X())
+		}
+	}
+	b.WriteString("}")
+}
+
+func isNoteRef(r *wml.CT_R) bool {
+	for _, ric := range r.EG_RunInnerContent {
+		c := ric.RunInnerContentChoice
+		if c.FootnoteRef != nil || c.EndnoteRef != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *writer) runFormatting(rpr *wml.CT_RPr) string {
+	if rpr == nil {
+		return ""
+	}
+	b := strings.Builder{}
+	if onOff(rpr.B) {
+		b.WriteString("\\b")
+	}
+	if onOff(rpr.I) {
+		b.WriteString("\\i")
+	}
+	if onOff(rpr.Strike) {
+		b.WriteString("\\strike")
+	}
+	if onOff(rpr.Dstrike) {
+		b.WriteString("\\striked1")
+	}
+	if onOff(rpr.Caps) {
+		b.WriteString("\\caps")
+	}
+	if onOff(rpr.SmallCaps) {
+		b.WriteString("\\scaps")
+	}
+	if onOff(rpr.Vanish) {
+		b.WriteString("\\v")
+	}
+	if u := rpr.U; u != nil {
+		switch u.ValAttr {
+		case wml.ST_UnderlineUnset, wml.ST_UnderlineNone:
+		case wml.ST_UnderlineDouble:
+			b.WriteString("\\uldb")
+		case wml.ST_UnderlineDotted:
+			b.WriteString("\\uld")
+		case wml.ST_UnderlineWords:
+			b.WriteString("\\ulw")
+		case wml.ST_UnderlineWave:
+			b.WriteString("\\ulwave")
+		case wml.ST_UnderlineThick:
+			b.WriteString("\\ulth")
+		case wml.ST_UnderlineDash:
+			b.WriteString("\\uldash")
+		default:
+			b.WriteString("\\ul")
+		}
+	}
+	if sz := rpr.Sz; sz != nil && sz.ValAttr.ST_UnsignedDecimalNumber != nil {
+		fmt.Fprintf(&b, "\\fs%d", *sz.ValAttr.ST_UnsignedDecimalNumber)
+	}
+	if f := rpr.RFonts; f != nil {
+		name := ""
+		switch {
+		case f.AsciiAttr != nil:
+			name = *f.AsciiAttr
+		case f.HAnsiAttr != nil:
+			name = *f.HAnsiAttr
+		case f.CsAttr != nil:
+			name = *f.CsAttr
+		}
+		if name != "" {
+			fmt.Fprintf(&b, "\\f%d", w.font(name))
+		}
+	}
+	if c := rpr.Color; c != nil && c.ValAttr.ST_HexColorRGB != nil {
+		if i := w.color(*c.ValAttr.ST_HexColorRGB); i > 0 {
+			fmt.Fprintf(&b, "\\cf%d", i)
+		}
+	}
+	if va := rpr.VertAlign; va != nil {
+		switch va.ValAttr {
+		case sharedTypes.ST_VerticalAlignRunSuperscript:
+			b.WriteString("\\super")
+		case sharedTypes.ST_VerticalAlignRunSubscript:
+			b.WriteString("\\sub")
+		}
+	}
+	if onOff(rpr.Rtl) {
+		b.WriteString("\\rtlch")
+	}
+	return b.String()
+}
+
+func (w *writer) picture(any []interface{}, cx, cy int64) {
+	for _, a := range any {
+		pic, ok := a.(*picture.Pic)
+		if !ok || pic.BlipFill == nil || pic.BlipFill.Blip == nil || pic.BlipFill.Blip.EmbedAttr == nil {
+			continue
+		}
+		ref, ok := w.doc.GetImageByRelID(*pic.BlipFill.Blip.EmbedAttr)
+		if !ok {
+			continue
+		}
+		data, err := imageData(ref)
+		if err != nil {
+			continue
+		}
+		blip := ""
+		switch strings.ToLower(ref.Format()) {
+		case "png":
+			blip = "\\pngblip"
+		case "jpg", "jpeg":
+			blip = "\\jpegblip"
+		default:
+			continue
+		}
+		sz := ref.Size()
+		goalW := int64(measurement.FromEMU(cx) / measurement.Twips)
+		goalH := int64(measurement.FromEMU(cy) / measurement.Twips)
+		fmt.Fprintf(&w.body, "{\\*\\shppict{\\pict%s\\picw%d\\pich%d\\picwgoal%d\\pichgoal%d\n", blip, sz.X, sz.Y, goalW, goalH)
+		enc := hex.EncodeToString(data)
+		for len(enc) > 128 {
+			w.body.WriteString(enc[:128])
+			w.body.WriteByte('\n')
+			enc = enc[128:]
+		}
+		w.body.WriteString(enc)
+		w.body.WriteString("}}")
+	}
+}
+
+// imageData returns the encoded bytes of an image, images of opened
+// documents are kept in the temporary storage.
+func imageData(ref common.ImageRef) ([]byte, error) {
+	if d := ref.Data(); d != nil {
+		return *d, nil
+	}
+	f, err := tempstorage.Open(ref.Path())
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func (w *writer) table(tbl *wml.CT_Tbl) {
+	var grid []int64
+	if tbl.TblGrid != nil {
+		for _, gc := range tbl.TblGrid.GridCol {
+			v := int64(defaultCellWidth)
+			if gc.WAttr != nil && gc.WAttr.ST_UnsignedDecimalNumber != nil {
+				v = int64(*gc.WAttr.ST_UnsignedDecimalNumber)
+			}
+			grid = append(grid, v)
+		}
+	}
+	borders := tbl.TblPr != nil && tbl.TblPr.TblBorders != nil
+	for _, rc := range tbl.EG_ContentRowContent {
+		for _, tr := range rc.ContentRowContentChoice.Tr {
+			w.row(tr, grid, borders)
+		}
+	}
+}
+
+func (w *writer) row(tr *wml.CT_Row, grid []int64, borders bool) {
+	b := &w.body
+	b.WriteString("\\trowd\\trgaph108")
+	if tr.TrPr != nil {
+		for _, c := range tr.TrPr.TrPrBaseChoice {
+			if c.TblHeader != nil {
+				b.WriteString("\\trhdr")
+			}
+			if h := c.TrHeight; h != nil && h.ValAttr != nil && h.ValAttr.ST_UnsignedDecimalNumber != nil {
+				v := int64(*h.ValAttr.ST_UnsignedDecimalNumber)
+				if h.HRuleAttr == wml.ST_HeightRuleExact {
+					v = -v
+				}
+				fmt.Fprintf(b, "\\trrh%d", v)
+			}
+		}
+	}
+	var cells []*wml.CT_Tc
+	for _, cc := range tr.EG_ContentCellContent {
+		cells = append(cells, cc.ContentCellContentChoice.Tc...)
+	}
+	right, col := int64(0), 0
+	for _, tc := range cells {
+		span := 1
+		if tc.TcPr != nil && tc.TcPr.GridSpan != nil && tc.TcPr.GridSpan.ValAttr > 1 {
+			span = int(tc.TcPr.GridSpan.ValAttr)
+		}
+		width := int64(0)
+		for i := 0; i < span; i++ {
+			if col+i < len(grid) {
+				width += grid[col+i]
+			}
+		}
+		col += span
+		if width == 0 {
+			width = cellWidth(tc)
+		}
+		if tc.TcPr != nil && tc.TcPr.VMerge != nil {
+			if tc.TcPr.VMerge.ValAttr == wml.ST_MergeRestart {
+				b.WriteString("\\clvmgf")
+			} else {
+				b.WriteString("\\clvmrg")
+			}
+		}
+		if tc.TcPr != nil && tc.TcPr.Shd != nil && tc.TcPr.Shd.FillAttr != nil && tc.TcPr.Shd.FillAttr.ST_HexColorRGB != nil {
+			if i := w.color(*tc.TcPr.Shd.FillAttr.ST_HexColorRGB); i > 0 {
+				fmt.Fprintf(b, "\\clcbpat%d", i)
+			}
+		}
+		if borders {
+			b.WriteString("\\clbrdrt\\brdrs\\clbrdrl\\brdrs\\clbrdrb\\brdrs\\clbrdrr\\brdrs")
+		}
+		right += width
+		fmt.Fprintf(b, "\\cellx%d", right)
+	}
+	b.WriteString("\n")
+	for _, tc := range cells {
+		first := true
+		for _, ble := range tc.EG_BlockLevelElts {
+			for _, cbc := range ble.BlockLevelEltsChoice.EG_ContentBlockContent {
+				for _, p := range cbc.ContentBlockContentChoice.P {
+					if !first {
+						b.WriteString("\\par\n")
+					}
+					first = false
+					w.paragraph(p, true)
+				}
+				for _, nested := range cbc.ContentBlockContentChoice.Tbl {
+					if !first {
+						b.WriteString("\\par\n")
+					}
+					first = false
+					w.flattenTable(nested)
+				}
+			}
+		}
+		if first {
+			b.WriteString("\\pard\\plain\\intbl ")
+		}
+		b.WriteString("\\cell\n")
+	}
+	b.WriteString("\\row\n")
+}
+
+// flattenTable writes a nested table as tab separated lines within a cell.
+func (w *writer) flattenTable(tbl *wml.CT_Tbl) {
+	firstRow := true
+	for _, rc := range tbl.EG_ContentRowContent {
+		for _, tr := range rc.ContentRowContentChoice.Tr {
+			if !firstRow {
+				w.body.WriteString("\\line ")
+			}
+			firstRow = false
+			firstCell := true
+			for _, cc := range tr.EG_ContentCellContent {
+				for _, tc := range cc.ContentCellContentChoice.Tc {
+					if !firstCell {
+						w.body.WriteString("\\tab ")
+					}
+					firstCell = false
+					for _, ble := range tc.EG_BlockLevelElts {
+						for _, cbc := range ble.BlockLevelEltsChoice.EG_ContentBlockContent {
+							for _, p := range cbc.ContentBlockContentChoice.P {
+								w.paragraphContent(p.EG_PContent)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func cellWidth(tc *wml.CT_Tc) int64 {
+	if tc.TcPr != nil && tc.TcPr.TcW != nil && tc.TcPr.TcW.TypeAttr == wml.ST_TblWidthDxa {
+		if wa := tc.TcPr.TcW.WAttr; wa != nil && wa.ST_DecimalNumberOrPercent != nil && wa.ST_DecimalNumberOrPercent.ST_UnqualifiedPercentage != nil {
+			return *wa.ST_DecimalNumberOrPercent.ST_UnqualifiedPercentage
+		}
+	}
+	return defaultCellWidth
+}
+
+func onOff(v *wml.CT_OnOff) bool {
+	if v == nil {
+		return false
+	}
+	if v.ValAttr == nil {
+		return true
+	}
+	if v.ValAttr.Bool != nil {
+		return *v.ValAttr.Bool
+	}
+	return v.ValAttr.ST_OnOff1 == sharedTypes.ST_OnOff1On
+}
+
+func twips(m *sharedTypes.ST_TwipsMeasure) (uint64, bool) {
+	if m == nil || m.ST_UnsignedDecimalNumber == nil {
+		return 0, false
+	}
+	return *m.ST_UnsignedDecimalNumber, true
+}
+
+func signedTwips(m *wml.ST_SignedTwipsMeasure) (int64, bool) {
+	if m == nil || m.Int64 == nil {
+		return 0, false
+	}
+	return *m.Int64, true
+}
+
+// escape encodes text for use in RTF, characters outside of Windows-1252
+// are written as \u escapes with a '?' fallback.
+func escape(s string) string {
+	b := strings.Builder{}
+	for _, r := range s {
+		switch r {
+		case '\\', '{', '}':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\t':
+			b.WriteString("\\tab ")
+		case '\n':
+			b.WriteString("\\line ")
+		default:
+			if r < 0x80 {
+				b.WriteRune(r)
+			} else if c, ok := encodeANSI(r); ok {
+				fmt.Fprintf(&b, "\\'%02x", c)
+			} else if r > 0xFFFF {
+				// characters outside the BMP are written as surrogate pairs
+				r -= 0x10000
+				fmt.Fprintf(&b, "\\u%d?\\u%d?", int16(0xD800+(r>>10)), int16(0xDC00+(r&0x3FF)))
+			} else {
+				fmt.Fprintf(&b, "\\u%d?", int16(r))
+			}
+		}
+	}
+	return b.String()
+}
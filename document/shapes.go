@@ -0,0 +1,624 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"math/rand"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/color"
+	"github.com/unidoc/unioffice/v2/drawing"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+)
+
+const (
+	wpsURI = "http://schemas.microsoft.com/office/word/2010/wordprocessingShape"
+	wpgURI = "http://schemas.microsoft.com/office/word/2010/wordprocessingGroup"
+)
+
+var (
+	defaultShapeFill    = color.RGB(0x44, 0x72, 0xC4)
+	defaultShapeOutline = color.RGB(0x2F, 0x52, 0x8F)
+)
+
+// Shape is a DrawingML shape of a drawing, a preset geometry, a line or
+// connector, or a text box.
+type Shape struct {
+	_doc *Document
+	_wsp *wml.WdWsp
+	// _ext is the extent of the drawing holding the shape, nil for shapes
+	// within a group
+	_ext *dml.CT_PositiveSize2D
+}
+
+// ShapeGroup is a group of shapes of a drawing. The positions of the shapes
+// in a group are relative to the top left corner of the group.
+type ShapeGroup struct {
+	_doc *Document
+	_grp *wml.WdCT_WordprocessingGroup
+	_ext *dml.CT_PositiveSize2D
+}
+
+// AddShapeInline adds an inline drawing holding a shape with the preset
+// geometry geom. Lines and connectors such as dml.ST_ShapeTypeLine and
+// dml.ST_ShapeTypeStraightConnector1 are drawn from the top left to the
+// bottom right corner of the shape.
+func (r Run) AddShapeInline(geom dml.ST_ShapeType, width, height measurement.Distance) (InlineDrawing, Shape) {
+	inline := r.addInlineGraphic(wpsURI, "Shape", width, height)
+	s := Shape{r._fgggg, newShape(geom, width, height), inline.Extent}
+	inline.Graphic.GraphicData.Any = append(inline.Graphic.GraphicData.Any, s._wsp)
+	return InlineDrawing{r._fgggg, inline}, s
+}
+
+// AddShapeAnchored adds an anchored (floating) drawing holding a shape with
+// the preset geometry geom, the text wraps around it as for anchored images.
+func (r Run) AddShapeAnchored(geom dml.ST_ShapeType, width, height measurement.Distance) (AnchoredDrawing, Shape) {
+	anchor := r.addAnchoredGraphic(wpsURI, "Shape", width, height)
+	s := Shape{r._fgggg, newShape(geom, width, height), anchor.Extent}
+	anchor.Graphic.GraphicData.Any = append(anchor.Graphic.GraphicData.Any, s._wsp)
+	return AnchoredDrawing{r._fgggg, anchor}, s
+}
+
+// AddTextBoxInline adds an inline drawing holding a text box.
+func (r Run) AddTextBoxInline(width, height measurement.Distance) (InlineDrawing, Shape) {
+	inline := r.addInlineGraphic(wpsURI, "Text Box", width, height)
+	s := Shape{r._fgggg, newTextBox(width, height), inline.Extent}
+	inline.Graphic.GraphicData.Any = append(inline.Graphic.GraphicData.Any, s._wsp)
+	return InlineDrawing{r._fgggg, inline}, s
+}
+
+// AddTextBoxAnchored adds an anchored (floating) drawing holding a text box.
+func (r Run) AddTextBoxAnchored(width, height measurement.Distance) (AnchoredDrawing, Shape) {
+	anchor := r.addAnchoredGraphic(wpsURI, "Text Box", width, height)
+	s := Shape{r._fgggg, newTextBox(width, height), anchor.Extent}
+	anchor.Graphic.GraphicData.Any = append(anchor.Graphic.GraphicData.Any, s._wsp)
+	return AnchoredDrawing{r._fgggg, anchor}, s
+}
+
+// AddShapeGroupInline adds an inline drawing holding an empty group of
+// shapes.
+func (r Run) AddShapeGroupInline(width, height measurement.Distance) (InlineDrawing, ShapeGroup) {
+	inline := r.addInlineGraphic(wpgURI, "Group", width, height)
+	g := newShapeGroup(width, height)
+	inline.Graphic.GraphicData.Any = append(inline.Graphic.GraphicData.Any, g)
+	return InlineDrawing{r._fgggg, inline}, ShapeGroup{r._fgggg, &g.WdCT_WordprocessingGroup, inline.Extent}
+}
+
+// AddShapeGroupAnchored adds an anchored (floating) drawing holding an empty
+// group of shapes.
+func (r Run) AddShapeGroupAnchored(width, height measurement.Distance) (AnchoredDrawing, ShapeGroup) {
+	anchor := r.addAnchoredGraphic(wpgURI, "Group", width, height)
+	g := newShapeGroup(width, height)
+	anchor.Graphic.GraphicData.Any = append(anchor.Graphic.GraphicData.Any, g)
+	return AnchoredDrawing{r._fgggg, anchor}, ShapeGroup{r._fgggg, &g.WdCT_WordprocessingGroup, anchor.Extent}
+}
+
+func (r Run) addInlineGraphic(uri, name string, width, height measurement.Distance) *wml.WdInline {
+	ic := r.newIC()
+	ic.RunInnerContentChoice.Drawing = wml.NewCT_Drawing()
+	inline := wml.NewWdInline()
+	ic.RunInnerContentChoice.Drawing.DrawingChoice = append(ic.RunInnerContentChoice.Drawing.DrawingChoice, &wml.CT_DrawingChoice{Inline: inline})
+	inline.CNvGraphicFramePr = dml.NewCT_NonVisualGraphicFrameProperties()
+	inline.DistTAttr = unioffice.Uint32(0)
+	inline.DistLAttr = unioffice.Uint32(0)
+	inline.DistBAttr = unioffice.Uint32(0)
+	inline.DistRAttr = unioffice.Uint32(0)
+	inline.Extent.CxAttr = emus(width)
+	inline.Extent.CyAttr = emus(height)
+	inline.DocPr.IdAttr = 0x7FFFFFFF & rand.Uint32()
+	inline.DocPr.NameAttr = name
+	inline.Graphic = dml.NewGraphic()
+	inline.Graphic.GraphicData = dml.NewCT_GraphicalObjectData()
+	inline.Graphic.GraphicData.UriAttr = uri
+	return inline
+}
+
+func (r Run) addAnchoredGraphic(uri, name string, width, height measurement.Distance) *wml.WdAnchor {
+	ic := r.newIC()
+	ic.RunInnerContentChoice.Drawing = wml.NewCT_Drawing()
+	anchor := wml.NewWdAnchor()
+	ic.RunInnerContentChoice.Drawing.DrawingChoice = append(ic.RunInnerContentChoice.Drawing.DrawingChoice, &wml.CT_DrawingChoice{Anchor: anchor})
+	anchor.SimplePosAttr = unioffice.Bool(false)
+	anchor.AllowOverlapAttr = true
+	anchor.CNvGraphicFramePr = dml.NewCT_NonVisualGraphicFrameProperties()
+	anchor.SimplePos.XAttr.ST_CoordinateUnqualified = unioffice.Int64(0)
+	anchor.SimplePos.YAttr.ST_CoordinateUnqualified = unioffice.Int64(0)
+	anchor.PositionH.RelativeFromAttr = wml.WdST_RelFromHPage
+	anchor.PositionH.PosHChoice = &wml.WdCT_PosHChoice{PosOffset: unioffice.Int32(0)}
+	anchor.PositionV.RelativeFromAttr = wml.WdST_RelFromVPage
+	anchor.PositionV.PosVChoice = &wml.WdCT_PosVChoice{PosOffset: unioffice.Int32(0)}
+	anchor.Extent.CxAttr = emus(width)
+	anchor.Extent.CyAttr = emus(height)
+	anchor.WrapTypeChoice = &wml.WdEG_WrapTypeChoice{}
+	anchor.WrapTypeChoice.WrapSquare = wml.NewWdCT_WrapSquare()
+	anchor.WrapTypeChoice.WrapSquare.WrapTextAttr = wml.WdST_WrapTextBothSides
+	anchor.DocPr.IdAttr = 0x7FFFFFFF & rand.Uint32()
+	anchor.DocPr.NameAttr = name
+	anchor.Graphic = dml.NewGraphic()
+	anchor.Graphic.GraphicData = dml.NewCT_GraphicalObjectData()
+	anchor.Graphic.GraphicData.UriAttr = uri
+	return anchor
+}
+
+func emus(d measurement.Distance) int64 { return int64(d / measurement.EMU) }
+
+// isConnector returns true for the preset geometries of lines and
+// connectors, which have no fill and no text.
+func isConnector(geom dml.ST_ShapeType) bool {
+	switch geom {
+	case dml.ST_ShapeTypeLine, dml.ST_ShapeTypeLineInv, dml.ST_ShapeTypeStraightConnector1,
+		dml.ST_ShapeTypeBentConnector2, dml.ST_ShapeTypeBentConnector3, dml.ST_ShapeTypeBentConnector4,
+		dml.ST_ShapeTypeBentConnector5, dml.ST_ShapeTypeCurvedConnector2, dml.ST_ShapeTypeCurvedConnector3,
+		dml.ST_ShapeTypeCurvedConnector4, dml.ST_ShapeTypeCurvedConnector5:
+		return true
+	}
+	return false
+}
+
+func newShape(geom dml.ST_ShapeType, width, height measurement.Distance) *wml.WdWsp {
+	wsp := wml.NewWdWsp()
+	wsp.WordprocessingShapeChoice = &wml.WdCT_WordprocessingShapeChoice{}
+	wsp.SpPr = dml.NewCT_ShapeProperties()
+	wsp.BodyPr = dml.NewCT_TextBodyProperties()
+	sp := drawing.MakeShapeProperties(wsp.SpPr)
+	sp.SetPosition(0, 0)
+	sp.SetSize(width, height)
+	sp.SetGeometry(geom)
+	if isConnector(geom) {
+		wsp.WordprocessingShapeChoice.CNvCnPr = dml.NewCT_NonVisualConnectorProperties()
+		sp.LineProperties().SetWidth(0.75 * measurement.Point)
+		sp.LineProperties().SetSolidFill(color.Black)
+		return wsp
+	}
+	wsp.WordprocessingShapeChoice.CNvSpPr = dml.NewCT_NonVisualDrawingShapeProps()
+	sp.SetSolidFill(defaultShapeFill)
+	sp.LineProperties().SetWidth(1 * measurement.Point)
+	sp.LineProperties().SetSolidFill(defaultShapeOutline)
+	wsp.BodyPr.AnchorAttr = dml.ST_TextAnchoringTypeCtr
+	return wsp
+}
+
+func newTextBox(width, height measurement.Distance) *wml.WdWsp {
+	wsp := newShape(dml.ST_ShapeTypeRect, width, height)
+	wsp.WordprocessingShapeChoice.CNvSpPr.TxBoxAttr = unioffice.Bool(true)
+	sp := drawing.MakeShapeProperties(wsp.SpPr)
+	sp.SetSolidFill(color.White)
+	sp.LineProperties().SetWidth(0.75 * measurement.Point)
+	sp.LineProperties().SetSolidFill(color.Black)
+	wsp.BodyPr.AnchorAttr = dml.ST_TextAnchoringTypeT
+	wsp.BodyPr.WrapAttr = dml.ST_TextWrappingTypeSquare
+	Shape{_wsp: wsp}.textBox()
+	return wsp
+}
+
+func newShapeGroup(width, height measurement.Distance) *wml.WdWgp {
+	wgp := wml.NewWdWgp()
+	wgp.CNvGrpSpPr = dml.NewCT_NonVisualGroupDrawingShapeProps()
+	wgp.GrpSpPr = dml.NewCT_GroupShapeProperties()
+	setGroupTransform(wgp.GrpSpPr, 0, 0, emus(width), emus(height))
+	return wgp
+}
+
+// setGroupTransform sets the position and size of a group, its children
+// are positioned in EMUs from its top left corner.
+func setGroupTransform(pr *dml.CT_GroupShapeProperties, x, y, cx, cy int64) {
+	pr.Xfrm = dml.NewCT_GroupTransform2D()
+	pr.Xfrm.Off = dml.NewCT_Point2D()
+	pr.Xfrm.Off.XAttr.ST_CoordinateUnqualified = unioffice.Int64(x)
+	pr.Xfrm.Off.YAttr.ST_CoordinateUnqualified = unioffice.Int64(y)
+	pr.Xfrm.Ext = dml.NewCT_PositiveSize2D()
+	pr.Xfrm.Ext.CxAttr = cx
+	pr.Xfrm.Ext.CyAttr = cy
+	pr.Xfrm.ChOff = dml.NewCT_Point2D()
+	pr.Xfrm.ChOff.XAttr.ST_CoordinateUnqualified = unioffice.Int64(0)
+	pr.Xfrm.ChOff.YAttr.ST_CoordinateUnqualified = unioffice.Int64(0)
+	pr.Xfrm.ChExt = dml.NewCT_PositiveSize2D()
+	pr.Xfrm.ChExt.CxAttr = cx
+	pr.Xfrm.ChExt.CyAttr = cy
+}
+
+// X returns the inner wrapped XML type.
+func (s Shape) X() *wml.WdWsp { return s._wsp }
+
+// Properties returns the shape properties controlling the geometry, fill and
+// outline of the shape.
+func (s Shape) Properties() drawing.ShapeProperties {
+	if s._wsp.SpPr == nil {
+		s._wsp.SpPr = dml.NewCT_ShapeProperties()
+	}
+	return drawing.MakeShapeProperties(s._wsp.SpPr)
+}
+
+// SetSize sets the size of the shape and of the drawing holding it.
+func (s Shape) SetSize(width, height measurement.Distance) {
+	s.Properties().SetSize(width, height)
+	if s._ext != nil {
+		s._ext.CxAttr = emus(width)
+		s._ext.CyAttr = emus(height)
+	}
+}
+
+// Geometry returns the preset geometry of the shape, or
+// dml.ST_ShapeTypeUnset for a custom geometry.
+func (s Shape) Geometry() dml.ST_ShapeType {
+	if sp := s._wsp.SpPr; sp != nil && sp.GeometryChoice != nil && sp.GeometryChoice.PrstGeom != nil {
+		return sp.GeometryChoice.PrstGeom.PrstAttr
+	}
+	return dml.ST_ShapeTypeUnset
+}
+
+// IsConnector returns true if the shape is a line or connector.
+func (s Shape) IsConnector() bool {
+	c := s._wsp.WordprocessingShapeChoice
+	return (c != nil && c.CNvCnPr != nil) || isConnector(s.Geometry())
+}
+
+// IsTextBox returns true if the shape is a text box.
+func (s Shape) IsTextBox() bool {
+	c := s._wsp.WordprocessingShapeChoice
+	return c != nil && c.CNvSpPr != nil && c.CNvSpPr.TxBoxAttr != nil && *c.CNvSpPr.TxBoxAttr
+}
+
+// SetArrowheads sets the line ends of a line or connector, use
+// dml.ST_LineEndTypeUnset to remove one.
+func (s Shape) SetArrowheads(head, tail dml.ST_LineEndType) {
+	ln := s.Properties().LineProperties().X()
+	ln.HeadEnd, ln.TailEnd = nil, nil
+	if head != dml.ST_LineEndTypeUnset {
+		ln.HeadEnd = dml.NewCT_LineEndProperties()
+		ln.HeadEnd.TypeAttr = head
+	}
+	if tail != dml.ST_LineEndTypeUnset {
+		ln.TailEnd = dml.NewCT_LineEndProperties()
+		ln.TailEnd.TypeAttr = tail
+	}
+}
+
+func (s Shape) effects() *dml.CT_EffectList {
+	sp := s.Properties().X()
+	if sp.EffectPropertiesChoice == nil {
+		sp.EffectPropertiesChoice = dml.NewEG_EffectPropertiesChoice()
+	}
+	if sp.EffectPropertiesChoice.EffectLst == nil {
+		sp.EffectPropertiesChoice.EffectDag = nil
+		sp.EffectPropertiesChoice.EffectLst = dml.NewCT_EffectList()
+	}
+	return sp.EffectPropertiesChoice.EffectLst
+}
+
+// SetShadow adds an outer shadow to the shape, offset by distance in the
+// direction given in degrees clockwise from the positive x axis.
+func (s Shape) SetShadow(c color.Color, blur, distance measurement.Distance, direction float64) {
+	shdw := dml.NewCT_OuterShadowEffect()
+	shdw.BlurRadAttr = unioffice.Int64(emus(blur))
+	shdw.DistAttr = unioffice.Int64(emus(distance))
+	shdw.DirAttr = unioffice.Int32(int32(direction * 60000))
+	shdw.RotWithShapeAttr = unioffice.Bool(false)
+	shdw.SrgbClr = dml.NewCT_SRgbColor()
+	shdw.SrgbClr.ValAttr = *c.AsRGBString()
+	s.effects().OuterShdw = shdw
+}
+
+// SetGlow adds a glow of the given color and radius around the shape.
+func (s Shape) SetGlow(c color.Color, radius measurement.Distance) {
+	glow := dml.NewCT_GlowEffect()
+	glow.RadAttr = unioffice.Int64(emus(radius))
+	glow.SrgbClr = dml.NewCT_SRgbColor()
+	glow.SrgbClr.ValAttr = *c.AsRGBString()
+	s.effects().Glow = glow
+}
+
+// SetSoftEdges softens the edges of the shape by radius.
+func (s Shape) SetSoftEdges(radius measurement.Distance) {
+	se := dml.NewCT_SoftEdgesEffect()
+	se.RadAttr = emus(radius)
+	s.effects().SoftEdge = se
+}
+
+// ClearEffects removes the shadow, glow and other effects of the shape.
+func (s Shape) ClearEffects() { s.Properties().X().EffectPropertiesChoice = nil }
+
+// SetTextAnchor controls the vertical alignment of the text of the shape.
+func (s Shape) SetTextAnchor(a dml.ST_TextAnchoringType) { s.bodyPr().AnchorAttr = a }
+
+// SetTextInsets sets the distances between the text and the edges of the
+// shape.
+func (s Shape) SetTextInsets(left, top, right, bottom measurement.Distance) {
+	inset := func(d measurement.Distance) *dml.ST_Coordinate32 {
+		return &dml.ST_Coordinate32{ST_Coordinate32Unqualified: unioffice.Int32(int32(emus(d)))}
+	}
+	bp := s.bodyPr()
+	bp.LInsAttr, bp.TInsAttr, bp.RInsAttr, bp.BInsAttr = inset(left), inset(top), inset(right), inset(bottom)
+}
+
+// SetAutoFit controls if the shape grows to fit its text.
+func (s Shape) SetAutoFit(b bool) {
+	bp := s.bodyPr()
+	bp.TextAutofitChoice = &dml.EG_TextAutofitChoice{}
+	if b {
+		bp.TextAutofitChoice.SpAutoFit = dml.NewCT_TextShapeAutofit()
+	} else {
+		bp.TextAutofitChoice.NoAutofit = dml.NewCT_TextNoAutofit()
+	}
+}
+
+func (s Shape) bodyPr() *dml.CT_TextBodyProperties {
+	if s._wsp.BodyPr == nil {
+		s._wsp.BodyPr = dml.NewCT_TextBodyProperties()
+	}
+	return s._wsp.BodyPr
+}
+
+// textBox returns the content of the text of the shape, creating it with an
+// empty paragraph if the shape has no text.
+func (s Shape) textBox() *wml.CT_TxbxContent {
+	c := s._wsp.WordprocessingShapeChoice1
+	if c == nil || c.Txbx == nil || c.Txbx.TxbxContent == nil {
+		s._wsp.WordprocessingShapeChoice1 = &wml.WdCT_WordprocessingShapeChoice1{}
+		s._wsp.WordprocessingShapeChoice1.Txbx = &wml.WdCT_TextboxInfo{TxbxContent: wml.NewCT_TxbxContent()}
+		c = s._wsp.WordprocessingShapeChoice1
+		addBlockParagraph(&c.Txbx.TxbxContent.EG_BlockLevelElts)
+	}
+	return c.Txbx.TxbxContent
+}
+
+// AddParagraph adds a paragraph to the text of the shape, any shape but a
+// connector can hold text. The first call fills the empty paragraph a new
+// text box starts with.
+func (s Shape) AddParagraph() Paragraph {
+	txbx := s.textBox()
+	if ps := s.Paragraphs(); len(ps) == 1 && len(ps[0].X().EG_PContent) == 0 && ps[0].X().PPr == nil {
+		return ps[0]
+	}
+	return Paragraph{s._doc, addBlockParagraph(&txbx.EG_BlockLevelElts)}
+}
+
+// Paragraphs returns the paragraphs of the text of the shape.
+func (s Shape) Paragraphs() []Paragraph {
+	ps := []Paragraph{}
+	if c := s._wsp.WordprocessingShapeChoice1; c != nil && c.Txbx != nil && c.Txbx.TxbxContent != nil {
+		walkBlockParagraphs(c.Txbx.TxbxContent.EG_BlockLevelElts, func(p *wml.CT_P) {
+			ps = append(ps, Paragraph{s._doc, p})
+		})
+	}
+	return ps
+}
+
+// Text returns the text of the shape with its paragraphs separated by
+// newlines.
+func (s Shape) Text() string {
+	lines := []string{}
+	for _, p := range s.Paragraphs() {
+		sb := strings.Builder{}
+		for _, r := range p.Runs() {
+			sb.WriteString(r.Text())
+		}
+		lines = append(lines, sb.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// addBlockParagraph appends a new paragraph to blocks.
+func addBlockParagraph(blocks *[]*wml.EG_BlockLevelElts) *wml.CT_P {
+	p := wml.NewCT_P()
+	c := wml.NewEG_ContentBlockContent()
+	c.ContentBlockContentChoice.P = []*wml.CT_P{p}
+	*blocks = append(*blocks, newBlockUnit(c))
+	return p
+}
+
+// X returns the inner wrapped XML type.
+func (g ShapeGroup) X() *wml.WdCT_WordprocessingGroup { return g._grp }
+
+func (g ShapeGroup) add(wsp *wml.WdWsp, name string, x, y measurement.Distance) Shape {
+	wsp.CNvPr = dml.NewCT_NonVisualDrawingProps()
+	wsp.CNvPr.IdAttr = 0x7FFFFFFF & rand.Uint32()
+	wsp.CNvPr.NameAttr = name
+	drawing.MakeShapeProperties(wsp.SpPr).SetPosition(x, y)
+	g._grp.WordprocessingGroupChoice = append(g._grp.WordprocessingGroupChoice, &wml.WdCT_WordprocessingGroupChoice{Wsp: []*wml.WdWsp{wsp}})
+	return Shape{g._doc, wsp, nil}
+}
+
+// AddShape adds a shape with the preset geometry geom to the group at
+// position x, y.
+func (g ShapeGroup) AddShape(geom dml.ST_ShapeType, x, y, width, height measurement.Distance) Shape {
+	return g.add(newShape(geom, width, height), "Shape", x, y)
+}
+
+// AddTextBox adds a text box to the group at position x, y.
+func (g ShapeGroup) AddTextBox(x, y, width, height measurement.Distance) Shape {
+	return g.add(newTextBox(width, height), "Text Box", x, y)
+}
+
+// AddGroup adds an empty group to the group at position x, y.
+func (g ShapeGroup) AddGroup(x, y, width, height measurement.Distance) ShapeGroup {
+	grp := &wml.WdCT_WordprocessingGroup{}
+	grp.CNvPr = dml.NewCT_NonVisualDrawingProps()
+	grp.CNvPr.IdAttr = 0x7FFFFFFF & rand.Uint32()
+	grp.CNvPr.NameAttr = "Group"
+	grp.CNvGrpSpPr = dml.NewCT_NonVisualGroupDrawingShapeProps()
+	grp.GrpSpPr = dml.NewCT_GroupShapeProperties()
+	setGroupTransform(grp.GrpSpPr, emus(x), emus(y), emus(width), emus(height))
+	g._grp.WordprocessingGroupChoice = append(g._grp.WordprocessingGroupChoice, &wml.WdCT_WordprocessingGroupChoice{GrpSp: []*wml.WdCT_WordprocessingGroup{grp}})
+	return ShapeGroup{g._doc, grp, nil}
+}
+
+// AddConnector adds a connector with the preset geometry geom, such as
+// dml.ST_ShapeTypeBentConnector3, joining the shapes from and to of the
+// group. The connector runs between the facing middles of the left and right
+// edges of the shapes, which are the connection sites 1 and 3 of rectangles.
+func (g ShapeGroup) AddConnector(geom dml.ST_ShapeType, from, to Shape) Shape {
+	fx, fy, fcx, fcy := from.bounds()
+	tx, ty, tcx, tcy := to.bounds()
+	x1, y1, x2, y2 := fx+fcx, fy+fcy/2, tx, ty+tcy/2
+	startSite, endSite := uint32(3), uint32(1)
+	if tx+tcx <= fx {
+		x1, x2 = fx, tx+tcx
+		startSite, endSite = 1, 3
+	}
+	wsp := newShape(geom, 0, 0)
+	if wsp.WordprocessingShapeChoice.CNvCnPr == nil {
+		wsp.WordprocessingShapeChoice.CNvSpPr = nil
+		wsp.WordprocessingShapeChoice.CNvCnPr = dml.NewCT_NonVisualConnectorProperties()
+	}
+	cn := wsp.WordprocessingShapeChoice.CNvCnPr
+	if from._wsp.CNvPr != nil {
+		cn.StCxn = &dml.CT_Connection{IdAttr: from._wsp.CNvPr.IdAttr, IdxAttr: startSite}
+	}
+	if to._wsp.CNvPr != nil {
+		cn.EndCxn = &dml.CT_Connection{IdAttr: to._wsp.CNvPr.IdAttr, IdxAttr: endSite}
+	}
+	s := g.add(wsp, "Connector", 0, 0)
+	xfrm := wsp.SpPr.Xfrm
+	xfrm.Off.XAttr.ST_CoordinateUnqualified = unioffice.Int64(min64(x1, x2))
+	xfrm.Off.YAttr.ST_CoordinateUnqualified = unioffice.Int64(min64(y1, y2))
+	xfrm.Ext.CxAttr = abs64(x2 - x1)
+	xfrm.Ext.CyAttr = abs64(y2 - y1)
+	sp := s.Properties()
+	sp.SetFlipHorizontal(x2 < x1)
+	sp.SetFlipVertical(y2 < y1)
+	return s
+}
+
+// bounds returns the position and size of the shape in EMUs.
+func (s Shape) bounds() (x, y, cx, cy int64) {
+	if sp := s._wsp.SpPr; sp != nil && sp.Xfrm != nil {
+		if off := sp.Xfrm.Off; off != nil {
+			if off.XAttr.ST_CoordinateUnqualified != nil {
+				x = *off.XAttr.ST_CoordinateUnqualified
+			}
+			if off.YAttr.ST_CoordinateUnqualified != nil {
+				y = *off.YAttr.ST_CoordinateUnqualified
+			}
+		}
+		if ext := sp.Xfrm.Ext; ext != nil {
+			cx, cy = ext.CxAttr, ext.CyAttr
+		}
+	}
+	return x, y, cx, cy
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func abs64(a int64) int64 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Shapes returns the shapes of the group, not including those of nested
+// groups.
+func (g ShapeGroup) Shapes() []Shape {
+	shapes := []Shape{}
+	for _, c := range g._grp.WordprocessingGroupChoice {
+		for _, wsp := range c.Wsp {
+			shapes = append(shapes, Shape{g._doc, wsp, nil})
+		}
+	}
+	return shapes
+}
+
+// Groups returns the groups nested in the group.
+func (g ShapeGroup) Groups() []ShapeGroup {
+	groups := []ShapeGroup{}
+	for _, c := range g._grp.WordprocessingGroupChoice {
+		for _, grp := range c.GrpSp {
+			groups = append(groups, ShapeGroup{g._doc, grp, nil})
+		}
+	}
+	return groups
+}
+
+// Shapes returns the DrawingML shapes of the body, headers and footers,
+// including those in groups, tables, content controls and text boxes.
+func (d *Document) Shapes() []Shape {
+	shapes := []Shape{}
+	add := func(blocks []*wml.EG_BlockLevelElts) {
+		walkShapes(blocks, func(wsp *wml.WdWsp, ext *dml.CT_PositiveSize2D) {
+			shapes = append(shapes, Shape{d, wsp, ext})
+		})
+	}
+	add(d.X().Body.EG_BlockLevelElts)
+	for _, hdr := range d._ebg {
+		add(hdr.EG_BlockLevelElts)
+	}
+	for _, ftr := range d._cca {
+		add(ftr.EG_BlockLevelElts)
+	}
+	return shapes
+}
+
+// walkShapes calls fn for every shape of a drawing in blocks, ext is the
+// extent of the drawing for shapes not in a group.
+func walkShapes(blocks []*wml.EG_BlockLevelElts, fn func(wsp *wml.WdWsp, ext *dml.CT_PositiveSize2D)) {
+	shape := func(wsp *wml.WdWsp, ext *dml.CT_PositiveSize2D) {
+		fn(wsp, ext)
+		if c := wsp.WordprocessingShapeChoice1; c != nil && c.Txbx != nil && c.Txbx.TxbxContent != nil {
+			walkShapes(c.Txbx.TxbxContent.EG_BlockLevelElts, fn)
+		}
+	}
+	var group func(grp *wml.WdCT_WordprocessingGroup)
+	group = func(grp *wml.WdCT_WordprocessingGroup) {
+		for _, c := range grp.WordprocessingGroupChoice {
+			for _, wsp := range c.Wsp {
+				shape(wsp, nil)
+			}
+			for _, g := range c.GrpSp {
+				group(g)
+			}
+		}
+	}
+	visit := func(d *wml.CT_Drawing) {
+		for _, dc := range d.DrawingChoice {
+			var gd *dml.CT_GraphicalObjectData
+			var ext *dml.CT_PositiveSize2D
+			if dc.Inline != nil && dc.Inline.Graphic != nil {
+				gd, ext = dc.Inline.Graphic.GraphicData, dc.Inline.Extent
+			}
+			if dc.Anchor != nil && dc.Anchor.Graphic != nil {
+				gd, ext = dc.Anchor.Graphic.GraphicData, dc.Anchor.Extent
+			}
+			if gd == nil {
+				continue
+			}
+			for _, a := range gd.Any {
+				switch g := a.(type) {
+				case *wml.WdWsp:
+					shape(g, ext)
+				case *wml.WdWgp:
+					group(&g.WdCT_WordprocessingGroup)
+				}
+			}
+		}
+	}
+	walkBlockParagraphs(blocks, func(p *wml.CT_P) {
+		walkParagraphRuns(p, func(r *wml.CT_R) {
+			for _, ric := range r.EG_RunInnerContent {
+				if d := ric.RunInnerContentChoice.Drawing; d != nil {
+					visit(d)
+				}
+			}
+			for _, x := range r.Extra {
+				if acr, ok := x.(*wml.AlternateContentRun); ok && acr.Choice.Drawing != nil {
+					visit(acr.Choice.Drawing)
+				}
+			}
+		})
+	})
+}
@@ -0,0 +1,236 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package document
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/wml"
+	"github.com/unidoc/unioffice/v2/schema/urn/schemas_microsoft_com/vml"
+)
+
+// Watermark is a text or picture watermark found in a header.
+type Watermark struct {
+	_hdr  Header
+	_run  *wml.CT_R
+	_pict *wml.CT_Picture
+}
+
+// Watermarks returns the text and picture watermarks of all headers. Text
+// watermarks are VML shapes with a text path, picture watermarks are VML
+// shapes with image data that were created as watermarks.
+func (d *Document) Watermarks() []Watermark {
+	wms := []Watermark{}
+	for _, hdr := range d.Headers() {
+		walkBlockParagraphs(hdr.X().EG_BlockLevelElts, func(p *wml.CT_P) {
+			walkParagraphRuns(p, func(r *wml.CT_R) {
+				for _, ric := range r.EG_RunInnerContent {
+					pict := ric.RunInnerContentChoice.Pict
+					if pict == nil {
+						continue
+					}
+					if w := (Watermark{hdr, r, pict}); w.IsText() || w.IsPicture() {
+						wms = append(wms, w)
+					}
+				}
+			})
+		})
+	}
+	return wms
+}
+
+// RemoveWatermarks removes all watermarks and returns the number removed.
+func (d *Document) RemoveWatermarks() int {
+	wms := d.Watermarks()
+	for _, w := range wms {
+		w.Remove()
+	}
+	return len(wms)
+}
+
+// shape returns the VML shape of the watermark, which is either a *vml.Shape
+// for watermarks added with AddWatermarkText or AddWatermarkPicture, or a
+// *unioffice.XSDAny for watermarks read from a file.
+func (w Watermark) shape() (*vml.Shape, *unioffice.XSDAny) {
+	for _, a := range w._pict.Any {
+		switch s := a.(type) {
+		case *vml.Shape:
+			return s, nil
+		case *unioffice.XSDAny:
+			if s.XMLName.Local == "shape" || s.XMLName.Local == "v:shape" {
+				return nil, s
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (w Watermark) shapeType() *vml.Shapetype {
+	for _, a := range w._pict.Any {
+		if st, ok := a.(*vml.Shapetype); ok {
+			return st
+		}
+	}
+	return nil
+}
+
+// element reports whether the shape of the watermark has a child element
+// with the given local name.
+func (w Watermark) element(name string) bool {
+	shp, x := w.shape()
+	switch {
+	case shp != nil:
+		for _, c := range shp.ShapeChoice {
+			if c.ShapeElementsChoice == nil {
+				continue
+			}
+			if name == "textpath" && c.ShapeElementsChoice.Textpath != nil || name == "imagedata" && c.ShapeElementsChoice.Imagedata != nil {
+				return true
+			}
+		}
+	case x != nil:
+		for _, n := range x.Nodes {
+			if n.XMLName.Local == name || n.XMLName.Local == "v:"+name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ID returns the ID of the watermark shape.
+func (w Watermark) ID() string {
+	shp, x := w.shape()
+	switch {
+	case shp != nil && shp.IdAttr != nil:
+		return *shp.IdAttr
+	case x != nil:
+		for _, a := range x.Attrs {
+			if a.Name.Local == "id" {
+				return a.Value
+			}
+		}
+	}
+	return ""
+}
+
+// Header returns the header containing the watermark.
+func (w Watermark) Header() Header { return w._hdr }
+
+// Pict returns the pict object of the watermark.
+func (w Watermark) Pict() *wml.CT_Picture { return w._pict }
+
+// IsText reports whether the watermark is a text watermark.
+func (w Watermark) IsText() bool { return w.element("textpath") }
+
+// IsPicture reports whether the watermark is a picture watermark.
+func (w Watermark) IsPicture() bool {
+	return !w.IsText() && w.element("imagedata") && strings.HasPrefix(w.ID(), "WordPictureWatermark")
+}
+
+// Text returns the watermark as a text watermark, allowing to read and change
+// its text and style.
+func (w Watermark) Text() (WatermarkText, bool) {
+	if !w.IsText() {
+		return WatermarkText{}, false
+	}
+	shp, _ := w.shape()
+	return WatermarkText{_ccfc: w._pict, _dbgbe: shp, _baega: w.shapeType()}, true
+}
+
+// Picture returns the watermark as a picture watermark, allowing to read and
+// change its picture and style.
+func (w Watermark) Picture() (WatermarkPicture, bool) {
+	if !w.IsPicture() {
+		return WatermarkPicture{}, false
+	}
+	shp, _ := w.shape()
+	return WatermarkPicture{_ggcce: w._pict, _eaeca: shp, _ebage: w.shapeType()}, true
+}
+
+// Image returns the image of a picture watermark.
+func (w Watermark) Image() (common.ImageRef, bool) {
+	if !w.IsPicture() {
+		return common.ImageRef{}, false
+	}
+	id := ""
+	shp, x := w.shape()
+	switch {
+	case shp != nil:
+		for _, c := range shp.ShapeChoice {
+			if c.ShapeElementsChoice != nil && c.ShapeElementsChoice.Imagedata != nil && c.ShapeElementsChoice.Imagedata.IdAttr != nil {
+				id = *c.ShapeElementsChoice.Imagedata.IdAttr
+			}
+		}
+	case x != nil:
+		for _, n := range x.Nodes {
+			if n.XMLName.Local != "imagedata" && n.XMLName.Local != "v:imagedata" {
+				continue
+			}
+			for _, a := range n.Attrs {
+				if a.Name.Local == "id" {
+					id = a.Value
+				}
+			}
+		}
+	}
+	d := w._hdr._fdcg
+	target := ""
+	for i, hdr := range d._ebg {
+		if hdr == w._hdr._adeb {
+			target = d._dcf[i].GetTargetByRelId(id)
+		}
+	}
+	for _, img := range d.Images {
+		if target != "" && strings.Replace(img.Target(), "word/", "", 1) == target {
+			return img, true
+		}
+	}
+	return common.ImageRef{}, false
+}
+
+// SetText changes the text of a text watermark.
+func (w Watermark) SetText(text string) error {
+	wt, ok := w.Text()
+	if !ok {
+		return errors.New("watermark is not a text watermark")
+	}
+	wt.SetText(text)
+	return nil
+}
+
+// SetImage changes the image of a picture watermark. The image is added to the
+// header containing the watermark if required.
+func (w Watermark) SetImage(img common.ImageRef) error {
+	wp, ok := w.Picture()
+	if !ok {
+		return errors.New("watermark is not a picture watermark")
+	}
+	ref, err := w._hdr.AddImageRef(img)
+	if err != nil {
+		return err
+	}
+	wp.SetPicture(ref)
+	return nil
+}
+
+// Remove removes the watermark from its header.
+func (w Watermark) Remove() {
+	rics := w._run.EG_RunInnerContent[:0]
+	for _, ric := range w._run.EG_RunInnerContent {
+		if ric.RunInnerContentChoice.Pict != w._pict {
+			rics = append(rics, ric)
+		}
+	}
+	w._run.EG_RunInnerContent = rics
+}
@@ -0,0 +1,353 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convertutils
+
+import (
+	"sync"
+	"unicode"
+
+	"github.com/unidoc/unioffice/v2/common/logger"
+	"github.com/unidoc/unipdf/v4/creator"
+	"github.com/unidoc/unipdf/v4/model"
+)
+
+// Script is a writing system with its own chain of fallback fonts.
+type Script int
+
+// Script constants.
+const (
+	// ScriptLatin covers the Latin, Greek and Cyrillic alphabets.
+	ScriptLatin Script = iota
+	// ScriptCJK covers Chinese, Japanese and Korean text.
+	ScriptCJK
+	ScriptArabic
+	ScriptHebrew
+	ScriptDevanagari
+	ScriptEmoji
+)
+
+func (s Script) String() string {
+	switch s {
+	case ScriptLatin:
+		return "Latin"
+	case ScriptCJK:
+		return "CJK"
+	case ScriptArabic:
+		return "Arabic"
+	case ScriptHebrew:
+		return "Hebrew"
+	case ScriptDevanagari:
+		return "Devanagari"
+	case ScriptEmoji:
+		return "Emoji"
+	}
+	return "Unknown"
+}
+
+// ScriptOf returns the script of r. Characters shared by all scripts such as
+// spaces, digits and punctuation have no script.
+func ScriptOf(r rune) (Script, bool) {
+	switch {
+	case r < 0x80:
+		if unicode.IsLetter(r) {
+			return ScriptLatin, true
+		}
+		return 0, false
+	case r >= 0x1F000 && r <= 0x1FAFF, r >= 0x2600 && r <= 0x27BF, r == 0xFE0F:
+		return ScriptEmoji, true
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Bopomofo),
+		r >= 0x3000 && r <= 0x303F, r >= 0xFF00 && r <= 0xFFEF:
+		return ScriptCJK, true
+	case unicode.Is(unicode.Arabic, r):
+		return ScriptArabic, true
+	case unicode.Is(unicode.Hebrew, r):
+		return ScriptHebrew, true
+	case unicode.Is(unicode.Devanagari, r):
+		return ScriptDevanagari, true
+	case unicode.In(r, unicode.Latin, unicode.Greek, unicode.Cyrillic):
+		return ScriptLatin, true
+	}
+	return 0, false
+}
+
+// FontFallback configures the fonts used in place of fonts that are not
+// registered and for characters the font of a run has no glyphs for. Only
+// registered fonts are used, see RegisterFont.
+type FontFallback struct {
+	// Chains lists for each script the names of the fonts tried in order for
+	// characters missing from the font of a run. The chain of a script
+	// replaces its chain in DefaultFontChains.
+	Chains map[Script][]string
+
+	// Substitutes lists for a font name the names of the fonts tried in order
+	// when the font is not registered. The substitutes of a font replace its
+	// substitutes in DefaultFontSubstitutes.
+	Substitutes map[string][]string
+}
+
+// DefaultFontChains are the fallback fonts of each script used unless
+// replaced by FontFallback.Chains.
+var DefaultFontChains = map[Script][]string{
+	ScriptLatin:      {"Liberation Sans", "DejaVu Sans", "Arial"},
+	ScriptCJK:        {"Noto Sans CJK SC", "Noto Sans SC", "Source Han Sans", "Microsoft YaHei", "SimSun", "MS Gothic", "Malgun Gothic"},
+	ScriptArabic:     {"Noto Naskh Arabic", "Noto Sans Arabic", "Arial", "Times New Roman"},
+	ScriptHebrew:     {"Noto Sans Hebrew", "Arial", "Times New Roman"},
+	ScriptDevanagari: {"Noto Sans Devanagari", "Mangal", "Nirmala UI"},
+	ScriptEmoji:      {"Noto Emoji", "Segoe UI Emoji", "Noto Color Emoji"},
+}
+
+// DefaultFontSubstitutes are metric compatible replacements of common Office
+// fonts used unless replaced by FontFallback.Substitutes.
+var DefaultFontSubstitutes = map[string][]string{
+	"Calibri":         {"Carlito"},
+	"Cambria":         {"Caladea"},
+	"Arial":           {"Liberation Sans", "Arimo"},
+	"Arial Narrow":    {"Liberation Sans Narrow"},
+	"Times New Roman": {"Liberation Serif", "Tinos"},
+	"Courier New":     {"Liberation Mono", "Cousine"},
+	"Georgia":         {"Gelasio"},
+}
+
+// FontSubstitution is a font replaced during conversion.
+type FontSubstitution struct {
+	// Font is the name of the font requested by the document.
+	Font  string
+	Style FontStyle
+
+	// Substitute is the name of the font used instead, empty if the default
+	// font was used.
+	Substitute string
+
+	// Fallback is set if Substitute was used for the characters of Script
+	// missing from Font rather than in place of Font.
+	Fallback bool
+	Script   Script
+}
+
+// Fallback is the font fallback state of a single conversion, it holds the
+// configuration of the conversion and collects the substitutions made. A nil
+// Fallback uses the default chains and substitutes and records nothing.
+type Fallback struct {
+	config  FontFallback
+	rtl     []*model.PdfFont
+	rtlFont *model.PdfFont
+
+	mu            sync.Mutex
+	substitutions []FontSubstitution
+	seen          map[FontSubstitution]bool
+}
+
+// NewFallback returns the fallback state of a conversion configured by f,
+// which may be nil. The fonts of the files rtlFiles are tried for Arabic and
+// Hebrew characters after their chains. The font of the file rtlFont, or the
+// first of rtlFiles if it is empty, is the font of RTL paragraphs.
+func NewFallback(f *FontFallback, rtlFont string, rtlFiles []string) *Fallback {
+	fb := &Fallback{seen: map[FontSubstitution]bool{}}
+	if f != nil {
+		fb.config = *f
+	}
+	for _, fn := range rtlFiles {
+		font, err := LoadFontFromFile(fn)
+		if err != nil {
+			logger.Log.Debug("Cannot load RTL font %s: %s", fn, err)
+			continue
+		}
+		fb.rtl = append(fb.rtl, font)
+	}
+	if rtlFont != "" {
+		font, err := LoadFontFromFile(rtlFont)
+		if err != nil {
+			logger.Log.Debug("Cannot load RTL font %s: %s", rtlFont, err)
+		}
+		fb.rtlFont = font
+	} else if len(fb.rtl) > 0 {
+		fb.rtlFont = fb.rtl[0]
+	}
+	return fb
+}
+
+// RTLFont returns the font of RTL paragraphs, nil if there is none.
+func (fb *Fallback) RTLFont() *model.PdfFont {
+	if fb == nil {
+		return nil
+	}
+	return fb.rtlFont
+}
+
+// Substitutions returns the substitutions made so far, in the order they
+// were first made.
+func (fb *Fallback) Substitutions() []FontSubstitution {
+	if fb == nil {
+		return nil
+	}
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	return append([]FontSubstitution(nil), fb.substitutions...)
+}
+
+func (fb *Fallback) record(s FontSubstitution) {
+	if fb == nil {
+		return
+	}
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	if fb.seen[s] {
+		return
+	}
+	fb.seen[s] = true
+	fb.substitutions = append(fb.substitutions, s)
+	logger.Log.Debug("Font %s with style %v replaced by %q", s.Font, s.Style, s.Substitute)
+}
+
+// chain returns the fallback fonts of s.
+func (fb *Fallback) chain(s Script) []string {
+	if fb != nil {
+		if chain, ok := fb.config.Chains[s]; ok {
+			return chain
+		}
+	}
+	return DefaultFontChains[s]
+}
+
+// substitutes returns the fonts tried in place of name.
+func (fb *Fallback) substitutes(name string) []string {
+	if fb != nil {
+		if subs, ok := fb.config.Substitutes[name]; ok {
+			return subs
+		}
+	}
+	return DefaultFontSubstitutes[name]
+}
+
+// FindFont returns the registered font name with style or its first
+// registered substitute. If neither is registered it returns nil and the
+// font is reported as replaced by the default font.
+func (fb *Fallback) FindFont(name string, style FontStyle) *model.PdfFont {
+	if f := GetRegisteredFont(name, style); f != nil {
+		return f
+	}
+	for _, s := range fb.substitutes(name) {
+		if f := GetRegisteredFont(s, style); f != nil {
+			fb.record(FontSubstitution{Font: name, Style: style, Substitute: s})
+			return f
+		}
+	}
+	if name != "" {
+		fb.record(FontSubstitution{Font: name, Style: style})
+	}
+	return nil
+}
+
+// Chunk splits off the characters of c that its font has no glyphs for into
+// chunks with the fonts of their scripts' chains, so that mixed language text
+// is displayed. c must be the last chunk of p, the new chunks are appended
+// after it with the same style and c keeps its links. It returns c and the
+// new chunks.
+func (fb *Fallback) Chunk(p *creator.StyledParagraph, c *creator.TextChunk) []*creator.TextChunk {
+	chunks := []*creator.TextChunk{c}
+	font := c.Style.Font
+	if font == nil || c.Text == "" {
+		return chunks
+	}
+	type run struct {
+		font *model.PdfFont
+		text []rune
+	}
+	var name string
+	var style FontStyle
+	var runs []run
+	for _, r := range c.Text {
+		f := font
+		if n := len(runs); n > 0 && runs[n-1].font != font {
+			if _, ok := ScriptOf(r); !ok && hasGlyph(runs[n-1].font, r) {
+				// shared characters stay with the text they are in
+				f = runs[n-1].font
+			}
+		}
+		if f == font && !hasGlyph(font, r) {
+			if s, ok := ScriptOf(r); ok {
+				if name == "" {
+					name, style = fontName(font)
+				}
+				if ff := fb.fallbackFont(name, style, s, r); ff != nil {
+					f = ff
+				}
+			}
+		}
+		if n := len(runs); n > 0 && runs[n-1].font == f {
+			runs[n-1].text = append(runs[n-1].text, r)
+		} else {
+			runs = append(runs, run{f, []rune{r}})
+		}
+	}
+	if len(runs) == 1 && runs[0].font == font {
+		return chunks
+	}
+	c.Text = string(runs[0].text)
+	c.Style.Font = runs[0].font
+	for _, rn := range runs[1:] {
+		n := p.Append(string(rn.text))
+		n.Style = c.Style
+		n.Style.Font = rn.font
+		n.VerticalAlignment = c.VerticalAlignment
+		chunks = append(chunks, n)
+	}
+	return chunks
+}
+
+// fallbackFont returns the first font of the chain of s with a glyph for r.
+func (fb *Fallback) fallbackFont(name string, style FontStyle, s Script, r rune) *model.PdfFont {
+	for _, fn := range fb.chain(s) {
+		f := GetRegisteredFont(fn, style)
+		if f == nil {
+			f = GetRegisteredFont(fn, FontStyle_Regular)
+		}
+		if f != nil && hasGlyph(f, r) {
+			fb.record(FontSubstitution{Font: name, Style: style, Substitute: fn, Fallback: true, Script: s})
+			return f
+		}
+	}
+	if fb != nil && (s == ScriptArabic || s == ScriptHebrew) {
+		for _, f := range fb.rtl {
+			if hasGlyph(f, r) {
+				fn, _ := fontName(f)
+				fb.record(FontSubstitution{Font: name, Style: style, Substitute: fn, Fallback: true, Script: s})
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// hasGlyph returns true if f has a glyph for r.
+func hasGlyph(f *model.PdfFont, r rune) bool {
+	if enc := f.Encoder(); enc != nil {
+		if _, ok := enc.RuneToCharcode(r); !ok {
+			return false
+		}
+	}
+	m, ok := f.GetRuneMetrics(r)
+	return ok && (m.Wx != 0 || unicode.In(r, unicode.Mn, unicode.Cf, unicode.Zs))
+}
+
+// fontName returns the name and style f is registered with, or its base font
+// name if it is not registered.
+func fontName(f *model.PdfFont) (string, FontStyle) {
+	_fgbg._baa.Lock()
+	defer _fgbg._baa.Unlock()
+	for name, styles := range _fgbg._geee {
+		for style, rf := range styles {
+			if rf == f {
+				return name, style
+			}
+		}
+	}
+	return f.BaseFont(), FontStyle_Regular
+}
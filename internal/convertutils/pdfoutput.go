@@ -0,0 +1,219 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convertutils
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/common/logger"
+	"github.com/unidoc/unipdf/v4/core"
+	"github.com/unidoc/unipdf/v4/creator"
+	"github.com/unidoc/unipdf/v4/model"
+	"github.com/unidoc/unipdf/v4/model/pdfa"
+)
+
+// PdfAConformance is a PDF/A conformance level of converted documents.
+type PdfAConformance int
+
+// PdfAConformance constants.
+const (
+	// PdfANone produces regular PDF output.
+	PdfANone PdfAConformance = iota
+	// PdfA2B produces PDF/A-2b output.
+	PdfA2B
+	// PdfA3B produces PDF/A-3b output.
+	PdfA3B
+)
+
+// SetPdfOutput sets up c to write the document information from the core
+// properties and, depending on the arguments, PDF/A output and a tagged PDF
+// with the structure tree built by t. PDF/A output gets embedded fonts, an
+// sRGB output intent and XMP metadata generated from the document
+// information.
+func SetPdfOutput(c *creator.Creator, level PdfAConformance, t *Tagger, cp common.CoreProperties, lang string) {
+	if cp.X() != nil && lang == "" && cp.X().Language != nil {
+		lang = string(cp.X().Language.Data)
+	}
+	if t != nil {
+		c.SetStructTreeRoot(t._root)
+		if lang != "" {
+			c.SetLanguage(lang)
+		}
+		prefs := model.NewViewerPreferences()
+		prefs.SetDisplayDocTitle(true)
+		c.SetViewerPreferences(prefs)
+	}
+	c.SetPdfWriterAccessFunc(func(w *model.PdfWriter) error {
+		if info := pdfInfo(cp); info != nil {
+			w.SetDocInfo(info)
+		}
+		if t != nil {
+			mark := core.MakeDict()
+			mark.Set("Marked", core.MakeBool(true))
+			if err := w.SetCatalogMarkInfo(mark); err != nil {
+				return err
+			}
+		}
+		switch level {
+		case PdfA2B:
+			w.ApplyStandard(pdfa.NewProfile2B(pdfa.DefaultProfile2Options()))
+		case PdfA3B:
+			w.ApplyStandard(pdfa.NewProfile3B(pdfa.DefaultProfile3Options()))
+		}
+		return nil
+	})
+}
+
+// pdfInfo returns the document information from the core properties, or nil
+// if there are none.
+func pdfInfo(cp common.CoreProperties) *model.PdfInfo {
+	if cp.X() == nil {
+		return nil
+	}
+	info := &model.PdfInfo{Producer: core.MakeString("UniOffice")}
+	if s := cp.Title(); s != "" {
+		info.Title = core.MakeString(s)
+	}
+	if s := cp.Author(); s != "" {
+		info.Author = core.MakeString(s)
+	}
+	if s := cp.Description(); s != "" {
+		info.Subject = core.MakeString(s)
+	}
+	if t := cp.Created(); !t.IsZero() {
+		if d, err := model.NewPdfDateFromTime(t); err == nil {
+			info.CreationDate = &d
+		}
+	}
+	if t := cp.Modified(); !t.IsZero() {
+		if d, err := model.NewPdfDateFromTime(t); err == nil {
+			info.ModifiedDate = &d
+		}
+	}
+	return info
+}
+
+// Tagger builds the structure tree of a tagged PDF while the pages of a
+// converted document are drawn. The methods of a nil Tagger do nothing, so
+// converters call them whether or not tagged output was requested.
+type Tagger struct {
+	_root *model.StructTreeRoot
+	_doc  *model.KDict
+	_page int64
+	_mcid int
+}
+
+// NewTagger returns a tagger with a Document element as the root of the
+// structure tree.
+func NewTagger() *Tagger {
+	t := &Tagger{_root: model.NewStructTreeRoot()}
+	t._doc = model.NewKDictionary()
+	t._doc.S = core.MakeName(string(model.StructureTypeDocument))
+	t._root.AddKDict(t._doc)
+	return t
+}
+
+// NewPage starts the next page, marked content IDs are numbered per page.
+func (t *Tagger) NewPage() {
+	if t == nil {
+		return
+	}
+	t._page++
+	t._mcid = 0
+}
+
+// Element adds a structure element of the given type to parent, or to the
+// Document element if parent is nil, and returns it.
+func (t *Tagger) Element(parent *model.KDict, st model.StructureType) *model.KDict {
+	if t == nil {
+		return nil
+	}
+	if parent == nil {
+		parent = t._doc
+	}
+	e := model.NewKDictionary()
+	e.S = core.MakeName(string(st))
+	e.SetPageNumber(t._page)
+	parent.AddKChild(e)
+	return e
+}
+
+// Figure adds a Figure element with the alternate text to parent.
+func (t *Tagger) Figure(parent *model.KDict, alt string) *model.KDict {
+	e := t.Element(parent, model.StructureTypeFigure)
+	if e != nil && alt != "" {
+		e.Alt = core.MakeString(alt)
+	}
+	return e
+}
+
+// markable is a creator component that can be marked as content of a
+// structure element.
+type markable interface {
+	SetMarkedContentID(mcid int64)
+}
+
+// Mark marks the next drawing of d as content of the element e.
+func (t *Tagger) Mark(e *model.KDict, d markable) {
+	if t == nil || e == nil {
+		return
+	}
+	d.SetMarkedContentID(int64(t._mcid))
+	e.AddMCIDChild(t._mcid)
+	t._mcid++
+}
+
+// MarkDrawable returns a block of the given page size drawing d as content of
+// the element e. It is used for components without support for marked content,
+// such as blocks. If e is nil the content is marked as an artifact.
+func (t *Tagger) MarkDrawable(e *model.KDict, d creator.Drawable, width, height float64) creator.Drawable {
+	if t == nil {
+		return d
+	}
+	tmp := MakeTempCreator(width, height)
+	tmp.NewPage()
+	tmp.MoveTo(0, 0)
+	if err := tmp.Draw(d); err != nil {
+		logger.Log.Debug("Cannot draw marked content: %s", err)
+		return d
+	}
+	page, err := GetPageFromCreator(tmp)
+	if err != nil {
+		logger.Log.Debug("Cannot get page of marked content: %s", err)
+		return d
+	}
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		logger.Log.Debug("Cannot get marked content: %s", err)
+		return d
+	}
+	begin := "/Artifact BMC\n"
+	if e != nil {
+		if n, ok := core.GetName(e.S); ok {
+			begin = fmt.Sprintf("/%s <</MCID %d>> BDC\n", string(*n), t._mcid)
+		}
+	}
+	if err := page.SetContentStreams([]string{begin + content + "\nEMC\n"}, core.NewFlateEncoder()); err != nil {
+		logger.Log.Debug("Cannot set marked content: %s", err)
+		return d
+	}
+	b, err := creator.NewBlockFromPage(page)
+	if err != nil {
+		logger.Log.Debug("Cannot make block of marked content: %s", err)
+		return d
+	}
+	if e != nil {
+		e.AddMCIDChild(t._mcid)
+		t._mcid++
+	}
+	b.SetPos(0, 0)
+	return b
+}
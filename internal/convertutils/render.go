@@ -0,0 +1,88 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convertutils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/unidoc/unipdf/v4/creator"
+	"github.com/unidoc/unipdf/v4/model"
+	"github.com/unidoc/unipdf/v4/render"
+)
+
+// DefaultDPI is the resolution of rendered pages if none is given.
+const DefaultDPI = 96.0
+
+// RenderPages renders pages of the PDF drawn by c to images with the
+// resolution dpi, DefaultDPI if dpi is not positive. Pages are given by their
+// one-based numbers, all pages are rendered if none are given.
+//
+// The creator only gives access to its pages through the written PDF, so the
+// whole PDF is written, but only the objects of the requested pages are read
+// back and rendered.
+func RenderPages(c *creator.Creator, dpi float64, pages ...int) ([]image.Image, error) {
+	if dpi <= 0 {
+		dpi = DefaultDPI
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := c.Write(buf); err != nil {
+		return nil, err
+	}
+	r, err := model.NewPdfReaderLazy(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	n, err := r.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		for i := 1; i <= n; i++ {
+			pages = append(pages, i)
+		}
+	}
+	images := make([]image.Image, 0, len(pages))
+	for _, num := range pages {
+		if num < 1 || num > n {
+			return nil, fmt.Errorf("page %d out of range, there are %d pages", num, n)
+		}
+		page, err := r.GetPage(num)
+		if err != nil {
+			return nil, err
+		}
+		box, err := page.GetMediaBox()
+		if err != nil {
+			return nil, err
+		}
+		device := render.NewImageDevice()
+		device.OutputWidth = int(math.Round(box.Width() / 72 * dpi))
+		img, err := device.Render(page)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// RenderPNG renders the page with the one-based number page of the PDF drawn
+// by c to w as a PNG image with the resolution dpi, see RenderPages.
+func RenderPNG(c *creator.Creator, dpi float64, page int, w io.Writer) error {
+	images, err := RenderPages(c, dpi, page)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, images[0])
+}
@@ -0,0 +1,440 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convertutils
+
+import (
+	"unicode"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+// arabicForms are the isolated, final, initial and medial presentation forms
+// of the Arabic letters. Letters joining only to the preceding letter have no
+// initial and medial forms.
+var arabicForms = map[rune][4]rune{
+	0x0621: {0xFE80, 0, 0, 0},
+	0x0622: {0xFE81, 0xFE82, 0, 0},
+	0x0623: {0xFE83, 0xFE84, 0, 0},
+	0x0624: {0xFE85, 0xFE86, 0, 0},
+	0x0625: {0xFE87, 0xFE88, 0, 0},
+	0x0626: {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C},
+	0x0627: {0xFE8D, 0xFE8E, 0, 0},
+	0x0628: {0xFE8F, 0xFE90, 0xFE91, 0xFE92},
+	0x0629: {0xFE93, 0xFE94, 0, 0},
+	0x062A: {0xFE95, 0xFE96, 0xFE97, 0xFE98},
+	0x062B: {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C},
+	0x062C: {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0},
+	0x062D: {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4},
+	0x062E: {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8},
+	0x062F: {0xFEA9, 0xFEAA, 0, 0},
+	0x0630: {0xFEAB, 0xFEAC, 0, 0},
+	0x0631: {0xFEAD, 0xFEAE, 0, 0},
+	0x0632: {0xFEAF, 0xFEB0, 0, 0},
+	0x0633: {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4},
+	0x0634: {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8},
+	0x0635: {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC},
+	0x0636: {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0},
+	0x0637: {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4},
+	0x0638: {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8},
+	0x0639: {0xFEC9, 0xFECA, 0xFECB, 0xFECC},
+	0x063A: {0xFECD, 0xFECE, 0xFECF, 0xFED0},
+	0x0641: {0xFED1, 0xFED2, 0xFED3, 0xFED4},
+	0x0642: {0xFED5, 0xFED6, 0xFED7, 0xFED8},
+	0x0643: {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC},
+	0x0644: {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0},
+	0x0645: {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4},
+	0x0646: {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8},
+	0x0647: {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC},
+	0x0648: {0xFEED, 0xFEEE, 0, 0},
+	0x0649: {0xFEEF, 0xFEF0, 0, 0},
+	0x064A: {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4},
+	0x0671: {0xFB50, 0xFB51, 0, 0},
+	0x0679: {0xFB66, 0xFB67, 0xFB68, 0xFB69},
+	0x067E: {0xFB56, 0xFB57, 0xFB58, 0xFB59},
+	0x0686: {0xFB7A, 0xFB7B, 0xFB7C, 0xFB7D},
+	0x0688: {0xFB88, 0xFB89, 0, 0},
+	0x0691: {0xFB8C, 0xFB8D, 0, 0},
+	0x0698: {0xFB8A, 0xFB8B, 0, 0},
+	0x06A9: {0xFB8E, 0xFB8F, 0xFB90, 0xFB91},
+	0x06AF: {0xFB92, 0xFB93, 0xFB94, 0xFB95},
+	0x06BA: {0xFB9E, 0xFB9F, 0, 0},
+	0x06BE: {0xFBAA, 0xFBAB, 0xFBAC, 0xFBAD},
+	0x06C1: {0xFBA6, 0xFBA7, 0xFBA8, 0xFBA9},
+	0x06CC: {0xFBFC, 0xFBFD, 0xFBFE, 0xFBFF},
+	0x06D2: {0xFBAE, 0xFBAF, 0, 0},
+}
+
+// lamAlef are the isolated and final forms of the ligatures of lam with the
+// alef variants.
+var lamAlef = map[rune][2]rune{
+	0x0622: {0xFEF5, 0xFEF6},
+	0x0623: {0xFEF7, 0xFEF8},
+	0x0625: {0xFEF9, 0xFEFA},
+	0x0627: {0xFEFB, 0xFEFC},
+}
+
+// mirrored are the characters displayed mirrored in right to left text.
+var mirrored = map[rune]rune{
+	'(': ')', ')': '(', '[': ']', ']': '[', '{': '}', '}': '{', '<': '>', '>': '<',
+	'«': '»', '»': '«', '‹': '›', '›': '‹', '≤': '≥', '≥': '≤',
+}
+
+// ShapeText returns text with the Arabic letters replaced by their contextual
+// presentation forms, lam followed by alef by their ligature, and the
+// Devanagari vowel sign I moved before the consonant cluster it follows.
+// The text stays in logical order, see ReorderLine for the display
+// order. Hebrew and Thai need no contextual forms and are left as
+// they are.
+//
+// This is not OpenType shaping. Neither the font parser nor the PDF creator
+// reads the GSUB and GPOS tables, and text is drawn character by character
+// through the cmap of the font, so glyphs without a code point cannot be
+// drawn at all. ShapeText therefore only covers what Unicode encodes as
+// presentation forms: the Arabic contextual forms and lam-alef. Other
+// ligatures, the Indic conjuncts and reph, and the positioning of marks are
+// not supported; marks are drawn with the default glyphs and advances of
+// the fonts.
+func ShapeText(text string) string {
+	shape := false
+	for _, r := range text {
+		if (r >= 0x0600 && r <= 0x06FF) || r == 0x093F {
+			shape = true
+			break
+		}
+	}
+	if !shape {
+		return text
+	}
+	return string(reorderIndic(shapeArabic([]rune(text))))
+}
+
+// joinsBefore returns true if the letter before rs[i] joins to it.
+func joinsBefore(rs []rune, i int) bool {
+	for j := i - 1; j >= 0; j-- {
+		if isTransparent(rs[j]) {
+			continue
+		}
+		return joinsDual(rs[j])
+	}
+	return false
+}
+
+// joinsAfter returns true if rs[i] joins to the letter after it.
+func joinsAfter(rs []rune, i int) bool {
+	if !joinsDual(rs[i]) {
+		return false
+	}
+	for j := i + 1; j < len(rs); j++ {
+		if isTransparent(rs[j]) {
+			continue
+		}
+		f, ok := arabicForms[rs[j]]
+		return (ok && f[1] != 0) || rs[j] == 0x0640 || rs[j] == 0x200D
+	}
+	return false
+}
+
+// joinsDual returns true if r joins to the letters on both sides.
+func joinsDual(r rune) bool {
+	if r == 0x0640 || r == 0x200D {
+		return true
+	}
+	f, ok := arabicForms[r]
+	return ok && f[2] != 0
+}
+
+func isTransparent(r rune) bool {
+	return r != 0x200C && r != 0x200D && unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf)
+}
+
+func shapeArabic(rs []rune) []rune {
+	out := make([]rune, 0, len(rs))
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		forms, ok := arabicForms[r]
+		if !ok {
+			out = append(out, r)
+			continue
+		}
+		// letters without a final form, such as hamza, never join
+		before := forms[1] != 0 && joinsBefore(rs, i)
+		if r == 0x0644 {
+			// the marks of the lam are kept after the ligature
+			j := i + 1
+			for j < len(rs) && isTransparent(rs[j]) {
+				j++
+			}
+			if j < len(rs) {
+				if lig, ok := lamAlef[rs[j]]; ok {
+					if before {
+						out = append(out, lig[1])
+					} else {
+						out = append(out, lig[0])
+					}
+					out = append(out, rs[i+1:j]...)
+					i = j
+					continue
+				}
+			}
+		}
+		after := joinsAfter(rs, i)
+		switch {
+		case before && after:
+			out = append(out, forms[3])
+		case before:
+			out = append(out, forms[1])
+		case after:
+			out = append(out, forms[2])
+		default:
+			out = append(out, forms[0])
+		}
+	}
+	return out
+}
+
+func isDevanagariConsonant(r rune) bool {
+	return (r >= 0x0915 && r <= 0x0939) || (r >= 0x0958 && r <= 0x095F)
+}
+
+// reorderIndic moves the Devanagari vowel sign I, which is written after the
+// consonant cluster it follows in speech, before the cluster.
+func reorderIndic(rs []rune) []rune {
+	for i, r := range rs {
+		if r != 0x093F {
+			continue
+		}
+		k := i - 1
+		if k >= 0 && rs[k] == 0x093C {
+			k--
+		}
+		if k < 0 || !isDevanagariConsonant(rs[k]) {
+			continue
+		}
+		start := k
+		for start >= 2 && rs[start-1] == 0x094D {
+			c := start - 2
+			if rs[c] == 0x093C && c > 0 {
+				c--
+			}
+			if !isDevanagariConsonant(rs[c]) {
+				break
+			}
+			start = c
+		}
+		copy(rs[start+1:i+1], rs[start:i])
+		rs[start] = r
+	}
+	return rs
+}
+
+// hasRTL returns true if text has right to left characters.
+func hasRTL(text []rune) bool {
+	for _, r := range text {
+		p, _ := bidi.LookupRune(r)
+		if c := p.Class(); c == bidi.R || c == bidi.AL {
+			return true
+		}
+	}
+	return false
+}
+
+// bidiLevels returns the embedding levels of the characters of a paragraph
+// per the Unicode Bidirectional Algorithm, where the paragraph direction is
+// right to left if rtl. The neutral characters at the paragraph level in the
+// right to left runs marked in rtlRuns, which may be nil, take the direction
+// of the run.
+func bidiLevels(text []rune, rtl bool, rtlRuns []bool) []int {
+	base, mark := 0, '\u200e'
+	if rtl {
+		base, mark = 1, '\u200f'
+	}
+	levels := make([]int, len(text))
+	for i := range levels {
+		levels[i] = base
+	}
+	// the mark fixes the paragraph direction
+	rs := make([]rune, 0, len(text)+1)
+	rs = append(rs, mark)
+	for _, r := range text {
+		if p, _ := bidi.LookupRune(r); p.Class() == bidi.B {
+			r = ' '
+		}
+		rs = append(rs, r)
+	}
+	var p bidi.Paragraph
+	if _, err := p.SetString(string(rs)); err != nil {
+		return levels
+	}
+	o, err := p.Order()
+	if err != nil {
+		return levels
+	}
+	dirs := make([]bidi.Direction, o.NumRuns())
+	for i := range dirs {
+		run := o.Run(i)
+		dirs[i] = run.Direction()
+	}
+	for i := range dirs {
+		run := o.Run(i)
+		lvl := base
+		switch {
+		case dirs[i] == bidi.RightToLeft:
+			lvl = 1
+		case rtl:
+			lvl = 2
+		case i > 0 && i+1 < len(dirs) && dirs[i-1] == bidi.RightToLeft && dirs[i+1] == bidi.RightToLeft && !hasStrongLTR(run.String()):
+			// numbers within right to left text
+			lvl = 2
+		}
+		start, end := run.Pos()
+		for j := start; j <= end; j++ {
+			if j > 0 {
+				levels[j-1] = lvl
+			}
+		}
+	}
+	for i, r := range text {
+		if i < len(rtlRuns) && rtlRuns[i] && levels[i] == 0 && isNeutral(r) {
+			levels[i] = 1
+		}
+	}
+	return levels
+}
+
+func hasStrongLTR(s string) bool {
+	for _, r := range s {
+		if p, _ := bidi.LookupRune(r); p.Class() == bidi.L && r != '\u200e' {
+			return true
+		}
+	}
+	return false
+}
+
+func isNeutral(r rune) bool {
+	p, _ := bidi.LookupRune(r)
+	switch p.Class() {
+	case bidi.WS, bidi.ON, bidi.S, bidi.B:
+		return true
+	}
+	return false
+}
+
+// reorderPositions moves the characters of a line to their visual positions.
+// x and w are the positions and advance widths of the characters laid out
+// left to right in logical order and levels their embedding levels, see
+// bidiLevels. The runs of each level are reversed within the space they
+// take, so the gaps between the characters are kept, and combining marks
+// stay after their base characters.
+func reorderPositions(text []rune, levels []int, x, w []float64) {
+	// clusters of a base character and its marks, by their first index
+	var clusters []int
+	for i, r := range text {
+		if i == 0 || !unicode.In(r, unicode.Mn, unicode.Me) || levels[i] != levels[i-1] {
+			clusters = append(clusters, i)
+		}
+	}
+	clusters = append(clusters, len(text))
+	maxLevel, minOdd := 0, -1
+	for _, l := range levels {
+		if l > maxLevel {
+			maxLevel = l
+		}
+		if l%2 == 1 && (minOdd < 0 || l < minOdd) {
+			minOdd = l
+		}
+	}
+	if minOdd < 0 {
+		return
+	}
+	extent := func(from, to int) (float64, float64) {
+		lo, hi := x[from], x[from]+w[from]
+		for i := from + 1; i < to; i++ {
+			if x[i] < lo {
+				lo = x[i]
+			}
+			if x[i]+w[i] > hi {
+				hi = x[i] + w[i]
+			}
+		}
+		return lo, hi
+	}
+	for lvl := maxLevel; lvl >= minOdd; lvl-- {
+		for c := 0; c+1 < len(clusters); {
+			if levels[clusters[c]] < lvl {
+				c++
+				continue
+			}
+			end := c
+			for end+1 < len(clusters) && levels[clusters[end]] >= lvl {
+				end++
+			}
+			lo, hi := extent(clusters[c], clusters[end])
+			for k := c; k < end; k++ {
+				from, to := clusters[k], clusters[k+1]
+				clo, chi := extent(from, to)
+				d := lo + hi - chi - clo
+				for i := from; i < to; i++ {
+					x[i] += d
+				}
+			}
+			c = end
+		}
+	}
+}
+
+// LineGlyph is a character of a line laid out left to right in logical
+// order, at X with advance width W. RTL is true if it is in a right to left
+// run.
+type LineGlyph struct {
+	Text string
+	RTL  bool
+	X, W float64
+}
+
+// ReorderLine moves the glyphs of a line to their visual positions per the
+// Unicode Bidirectional Algorithm, where the paragraph direction is right to
+// left if rtl, and replaces the characters displayed right to left by their
+// mirrored forms. The line keeps the space it takes. It returns false and
+// leaves the glyphs as they are if the line has no right to left text.
+func ReorderLine(glyphs []LineGlyph, rtl bool) bool {
+	text := make([]rune, len(glyphs))
+	rtlRuns := make([]bool, len(glyphs))
+	x := make([]float64, len(glyphs))
+	w := make([]float64, len(glyphs))
+	for i, g := range glyphs {
+		text[i] = '\ufffc'
+		if rs := []rune(g.Text); len(rs) == 1 {
+			text[i] = rs[0]
+		}
+		rtlRuns[i] = g.RTL
+		x[i], w[i] = g.X, g.W
+	}
+	if len(glyphs) == 0 || (!rtl && !hasRTL(text)) {
+		return false
+	}
+	levels := bidiLevels(text, rtl, rtlRuns)
+	reorderPositions(text, levels, x, w)
+	for i := range glyphs {
+		glyphs[i].X = x[i]
+		if levels[i]%2 == 1 {
+			if m, ok := mirror(text[i]); ok {
+				glyphs[i].Text = string(m)
+			}
+		}
+	}
+	return true
+}
+
+// mirror returns the mirrored form of r for right to left text, false if r
+// is not mirrored.
+func mirror(r rune) (rune, bool) {
+	m, ok := mirrored[r]
+	return m, ok
+}
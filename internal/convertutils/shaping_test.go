@@ -0,0 +1,105 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convertutils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShapeText(t *testing.T) {
+	td := []struct {
+		name string
+		in   string
+		exp  []rune
+	}{
+		{"latin", "abc", []rune("abc")},
+		{"isolated", "ب", []rune{0xFE8F}},
+		{"initial medial final", "ببب", []rune{0xFE91, 0xFE92, 0xFE90}},
+		{"right joining", "باب", []rune{0xFE91, 0xFE8E, 0xFE8F}},
+		{"hamza does not join", "شيء", []rune{0xFEB7, 0xFEF2, 0xFE80}},
+		{"marks are transparent", "بَب", []rune{0xFE91, 0x064E, 0xFE90}},
+		{"tatweel", "بـ", []rune{0xFE91, 0x0640}},
+		{"zero width non-joiner", "ب‌ب", []rune{0xFE8F, 0x200C, 0xFE8F}},
+		{"lam alef", "لا", []rune{0xFEFB}},
+		{"joined lam alef", "بلا", []rune{0xFE91, 0xFEFC}},
+		{"lam mark alef", "لَا", []rune{0xFEFB, 0x064E}},
+		{"lam hamza alef", "لأ", []rune{0xFEF7}},
+		{"devanagari i", "कि", []rune{0x093F, 0x0915}},
+		{"devanagari cluster", "स्थि", []rune{0x093F, 0x0938, 0x094D, 0x0925}},
+		{"devanagari nukta", "ड़ि", []rune{0x093F, 0x0921, 0x093C}},
+		{"hebrew", "שלום", []rune("שלום")},
+		{"thai", "กิน", []rune("กิน")},
+	}
+	for _, tc := range td {
+		if got := []rune(ShapeText(tc.in)); !reflect.DeepEqual(got, tc.exp) {
+			t.Errorf("%s: expected %U, got %U", tc.name, tc.exp, got)
+		}
+	}
+}
+
+func TestBidiLevels(t *testing.T) {
+	td := []struct {
+		name    string
+		in      string
+		rtl     bool
+		rtlRuns []bool
+		exp     []int
+	}{
+		{"latin", "ab c", false, nil, []int{0, 0, 0, 0}},
+		{"hebrew in latin", "ab אב", false, nil, []int{0, 0, 0, 1, 1}},
+		{"latin in rtl paragraph", "א ab", true, nil, []int{1, 1, 2, 2}},
+		{"numbers in rtl text", "א 12 ב", false, nil, []int{1, 1, 2, 2, 1, 1}},
+		{"rtl run", "א !", false, []bool{true, true, true}, []int{1, 1, 1}},
+	}
+	for _, tc := range td {
+		if got := bidiLevels([]rune(tc.in), tc.rtl, tc.rtlRuns); !reflect.DeepEqual(got, tc.exp) {
+			t.Errorf("%s: expected levels %v, got %v", tc.name, tc.exp, got)
+		}
+	}
+}
+
+func TestReorderLine(t *testing.T) {
+	td := []struct {
+		name    string
+		in      string
+		rtl     bool
+		ok      bool
+		expX    []float64
+		expText string
+	}{
+		{"latin", "abc", false, false, []float64{0, 10, 20}, "abc"},
+		{"hebrew", "אבג", false, true, []float64{20, 10, 0}, "אבג"},
+		{"mixed", "ab אב", false, true, []float64{0, 10, 20, 40, 30}, "ab אב"},
+		{"rtl paragraph", "א ab", true, true, []float64{30, 20, 0, 10}, "א ab"},
+		{"mirrored", "(א)", true, true, []float64{20, 10, 0}, ")א("},
+		{"mark after base", "אבּ", true, true, []float64{20, 0, 10}, "אבּ"},
+	}
+	for _, tc := range td {
+		glyphs := []LineGlyph{}
+		for i, r := range []rune(tc.in) {
+			glyphs = append(glyphs, LineGlyph{Text: string(r), X: 10 * float64(i), W: 10})
+		}
+		if ok := ReorderLine(glyphs, tc.rtl); ok != tc.ok {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.ok, ok)
+		}
+		x, text := []float64{}, ""
+		for _, g := range glyphs {
+			x = append(x, g.X)
+			text += g.Text
+		}
+		if !reflect.DeepEqual(x, tc.expX) {
+			t.Errorf("%s: expected positions %v, got %v", tc.name, tc.expX, x)
+		}
+		if text != tc.expText {
+			t.Errorf("%s: expected text %q, got %q", tc.name, tc.expText, text)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+// Package fontembed prepares TrueType fonts for being embedded into office
+// documents.
+package fontembed
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/unidoc/unitype"
+)
+
+// Subset returns the font ttf reduced to the glyphs of text.
+func Subset(ttf []byte, text string) ([]byte, error) {
+	fnt, err := unitype.Parse(bytes.NewReader(ttf))
+	if err != nil {
+		return nil, err
+	}
+	seen := map[rune]struct{}{}
+	runes := []rune{}
+	for _, r := range text {
+		if _, ok := seen[r]; !ok {
+			seen[r] = struct{}{}
+			runes = append(runes, r)
+		}
+	}
+	sub, err := fnt.SubsetKeepRunes(runes)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.Buffer{}
+	if err := sub.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NewKey returns a random font key in GUID form.
+func NewKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("{%X-%X-%X-%X-%X}", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// Obfuscate returns the font data obfuscated with key as described in
+// ECMA-376 Part 2, section 17.8.1. The operation is its own inverse.
+func Obfuscate(data []byte, key string) ([]byte, error) {
+	hex := strings.Map(func(r rune) rune {
+		if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return r
+		}
+		return -1
+	}, key)
+	if len(hex) != 32 {
+		return nil, errors.New("font key must be a GUID")
+	}
+	k := make([]byte, 16)
+	for i := 0; i < 16; i++ {
+		v, err := strconv.ParseUint(hex[30-2*i:32-2*i], 16, 8)
+		if err != nil {
+			return nil, err
+		}
+		k[i] = byte(v)
+	}
+	out := append([]byte{}, data...)
+	for i := 0; i < 32 && i < len(out); i++ {
+		out[i] ^= k[i%16]
+	}
+	return out, nil
+}
+
+// sfntTables returns the tables of a TrueType font by tag.
+func sfntTables(ttf []byte) (map[string][]byte, error) {
+	if len(ttf) < 12 {
+		return nil, errors.New("invalid font data")
+	}
+	n := int(binary.BigEndian.Uint16(ttf[4:]))
+	tables := map[string][]byte{}
+	for i := 0; i < n; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(ttf) {
+			return nil, errors.New("invalid font table directory")
+		}
+		off := int(binary.BigEndian.Uint32(ttf[rec+8:]))
+		length := int(binary.BigEndian.Uint32(ttf[rec+12:]))
+		if off < 0 || length < 0 || off+length > len(ttf) {
+			return nil, errors.New("invalid font table offset")
+		}
+		tables[string(ttf[rec:rec+4])] = ttf[off : off+length]
+	}
+	return tables, nil
+}
+
+// fontName returns the name with the given ID from a name table, preferring
+// Windows Unicode names.
+func fontName(name []byte, id uint16) string {
+	if len(name) < 6 {
+		return ""
+	}
+	count := int(binary.BigEndian.Uint16(name[2:]))
+	storage := int(binary.BigEndian.Uint16(name[4:]))
+	fallback := ""
+	for i := 0; i < count; i++ {
+		rec := 6 + 12*i
+		if rec+12 > len(name) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(name[rec:])
+		nameID := binary.BigEndian.Uint16(name[rec+6:])
+		length := int(binary.BigEndian.Uint16(name[rec+8:]))
+		off := storage + int(binary.BigEndian.Uint16(name[rec+10:]))
+		if nameID != id || off+length > len(name) {
+			continue
+		}
+		s := name[off : off+length]
+		switch platform {
+		case 0, 3:
+			u := make([]uint16, len(s)/2)
+			for j := range u {
+				u[j] = binary.BigEndian.Uint16(s[2*j:])
+			}
+			return string(utf16.Decode(u))
+		case 1:
+			fallback = string(s)
+		}
+	}
+	return fallback
+}
+
+// EOT wraps the TrueType font ttf into an uncompressed Embedded OpenType
+// (version 0x00020001) container as used for fonts embedded in presentations.
+func EOT(ttf []byte) ([]byte, error) {
+	tables, err := sfntTables(ttf)
+	if err != nil {
+		return nil, err
+	}
+	os2, head, name := tables["OS/2"], tables["head"], tables["name"]
+	if len(os2) < 78 || len(head) < 12 {
+		return nil, errors.New("font has no OS/2 or head table")
+	}
+	buf := bytes.Buffer{}
+	le := func(v interface{}) { binary.Write(&buf, binary.LittleEndian, v) }
+	le(uint32(0)) // EOTSize, set below
+	le(uint32(len(ttf)))
+	le(uint32(0x00020001))
+	le(uint32(0)) // flags
+	buf.Write(os2[32:42])
+	buf.WriteByte(1) // DEFAULT_CHARSET
+	buf.WriteByte(byte(binary.BigEndian.Uint16(os2[62:]) & 1))
+	le(uint32(binary.BigEndian.Uint16(os2[4:])))
+	le(binary.BigEndian.Uint16(os2[8:]))
+	le(uint16(0x504C))
+	for i := 0; i < 4; i++ {
+		le(binary.BigEndian.Uint32(os2[42+4*i:]))
+	}
+	for i := 0; i < 2; i++ {
+		v := uint32(0)
+		if binary.BigEndian.Uint16(os2) >= 1 && len(os2) >= 86 {
+			v = binary.BigEndian.Uint32(os2[78+4*i:])
+		}
+		le(v)
+	}
+	le(binary.BigEndian.Uint32(head[8:]))
+	for i := 0; i < 4; i++ {
+		le(uint32(0))
+	}
+	for _, id := range []uint16{1, 2, 5, 4} {
+		u := utf16.Encode([]rune(fontName(name, id)))
+		le(uint16(0))
+		le(uint16(2 * len(u)))
+		le(u)
+	}
+	le(uint16(0))
+	le(uint16(0)) // no root string
+	buf.Write(ttf)
+	out := buf.Bytes()
+	binary.LittleEndian.PutUint32(out, uint32(len(out)))
+	return out, nil
+}
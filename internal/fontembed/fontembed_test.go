@@ -0,0 +1,164 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package fontembed
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestObfuscate(t *testing.T) {
+	key := []byte{0xEF, 0xCD, 0xAB, 0x89, 0x67, 0x45, 0x23, 0x01, 0xEF, 0xCD, 0xAB, 0x89, 0x67, 0x45, 0x23, 0x01}
+	td := []struct {
+		name string
+		key  string
+		size int
+		ok   bool
+	}{
+		{"guid", "{01234567-89AB-CDEF-0123-456789ABCDEF}", 40, true},
+		{"lower case", "{01234567-89ab-cdef-0123-456789abcdef}", 40, true},
+		{"short data", "{01234567-89AB-CDEF-0123-456789ABCDEF}", 10, true},
+		{"short key", "{01234567-89AB}", 40, false},
+		{"empty key", "", 40, false},
+	}
+	for _, tc := range td {
+		data := make([]byte, tc.size)
+		got, err := Obfuscate(data, tc.key)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: expected ok = %v, got error %v", tc.name, tc.ok, err)
+			continue
+		}
+		if !tc.ok {
+			continue
+		}
+		for i, b := range got {
+			exp := byte(0)
+			if i < 32 {
+				exp = key[i%16]
+			}
+			if b != exp {
+				t.Errorf("%s: expected byte %d to be %#x, got %#x", tc.name, i, exp, b)
+				break
+			}
+		}
+		back, _ := Obfuscate(got, tc.key)
+		if !bytes.Equal(back, data) {
+			t.Errorf("%s: expected obfuscation to be its own inverse", tc.name)
+		}
+	}
+}
+
+// testFont returns a minimal sfnt with the tables EOT reads.
+func testFont(family string, version uint16) []byte {
+	be := binary.BigEndian
+	os2 := make([]byte, 78)
+	if version >= 1 {
+		os2 = make([]byte, 86)
+		be.PutUint32(os2[78:], 0x11223344)
+	}
+	be.PutUint16(os2, version)
+	be.PutUint16(os2[4:], 700)
+	be.PutUint16(os2[8:], 8)
+	be.PutUint32(os2[42:], 0x80000003)
+	be.PutUint16(os2[62:], 1)
+	head := make([]byte, 54)
+	be.PutUint32(head[8:], 0xCAFEBABE)
+	u := utf16.Encode([]rune(family))
+	name := make([]byte, 6+12, 6+12+2*len(u))
+	be.PutUint16(name[2:], 1)
+	be.PutUint16(name[4:], 18)
+	be.PutUint16(name[6:], 3)
+	be.PutUint16(name[12:], 1)
+	be.PutUint16(name[14:], uint16(2*len(u)))
+	for _, c := range u {
+		name = append(name, byte(c>>8), byte(c))
+	}
+	tables := []struct {
+		tag  string
+		data []byte
+	}{{"OS/2", os2}, {"head", head}, {"name", name}}
+	ttf := make([]byte, 12+16*len(tables))
+	be.PutUint32(ttf, 0x00010000)
+	be.PutUint16(ttf[4:], uint16(len(tables)))
+	for i, tb := range tables {
+		rec := ttf[12+16*i:]
+		copy(rec, tb.tag)
+		be.PutUint32(rec[8:], uint32(len(ttf)))
+		be.PutUint32(rec[12:], uint32(len(tb.data)))
+		ttf = append(ttf, tb.data...)
+	}
+	return ttf
+}
+
+func TestEOT(t *testing.T) {
+	td := []struct {
+		name     string
+		ttf      []byte
+		family   string
+		codePage uint32
+		ok       bool
+	}{
+		{"version 0", testFont("Test", 0), "Test", 0, true},
+		{"version 1", testFont("Fancy Font", 1), "Fancy Font", 0x11223344, true},
+		{"truncated", testFont("Test", 0)[:20], "", 0, false},
+		{"empty", nil, "", 0, false},
+	}
+	le := binary.LittleEndian
+	for _, tc := range td {
+		eot, err := EOT(tc.ttf)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: expected ok = %v, got error %v", tc.name, tc.ok, err)
+			continue
+		}
+		if !tc.ok {
+			continue
+		}
+		if got := le.Uint32(eot); int(got) != len(eot) {
+			t.Errorf("%s: expected EOT size %d, got %d", tc.name, len(eot), got)
+		}
+		if got := le.Uint32(eot[4:]); int(got) != len(tc.ttf) {
+			t.Errorf("%s: expected font data size %d, got %d", tc.name, len(tc.ttf), got)
+		}
+		if got := le.Uint32(eot[8:]); got != 0x00020001 {
+			t.Errorf("%s: expected version 0x00020001, got %#x", tc.name, got)
+		}
+		if eot[27] != 1 {
+			t.Errorf("%s: expected the italic flag to be set", tc.name)
+		}
+		if got := le.Uint32(eot[28:]); got != 700 {
+			t.Errorf("%s: expected weight 700, got %d", tc.name, got)
+		}
+		if got := le.Uint16(eot[34:]); got != 0x504C {
+			t.Errorf("%s: expected magic number 0x504C, got %#x", tc.name, got)
+		}
+		if got := le.Uint32(eot[36:]); got != 0x80000003 {
+			t.Errorf("%s: expected unicode range 0x80000003, got %#x", tc.name, got)
+		}
+		if got := le.Uint32(eot[52:]); got != tc.codePage {
+			t.Errorf("%s: expected code page range %#x, got %#x", tc.name, tc.codePage, got)
+		}
+		if got := le.Uint32(eot[60:]); got != 0xCAFEBABE {
+			t.Errorf("%s: expected checksum adjustment 0xCAFEBABE, got %#x", tc.name, got)
+		}
+		size := int(le.Uint16(eot[82:]))
+		u := make([]uint16, size/2)
+		for i := range u {
+			u[i] = le.Uint16(eot[84+2*i:])
+		}
+		if got := string(utf16.Decode(u)); got != tc.family {
+			t.Errorf("%s: expected family name %q, got %q", tc.name, tc.family, got)
+		}
+		if !bytes.HasSuffix(eot, tc.ttf) {
+			t.Errorf("%s: expected the font data at the end", tc.name)
+		}
+	}
+}
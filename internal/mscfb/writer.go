@@ -0,0 +1,226 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package mscfb
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// Stream is a stream stored in the root storage of a compound file written by
+// Write.
+type Stream struct {
+	Name string
+	Data []byte
+}
+
+const (
+	wSectorSize     = 512
+	wMiniSectorSize = 64
+	wMiniCutoff     = 4096
+	wDirEntrySize   = 128
+	wFreeSect       = 0xFFFFFFFF
+	wEndOfChain     = 0xFFFFFFFE
+	wFATSect        = 0xFFFFFFFD
+	wNoStream       = 0xFFFFFFFF
+	wHeaderDIFAT    = 109
+)
+
+// Write writes a version 3 compound file whose root storage has the class ID
+// clsid and holds streams. Streams smaller than 4096 bytes are stored in the
+// mini stream.
+func Write(w io.Writer, clsid [16]byte, streams ...Stream) error {
+	for _, s := range streams {
+		if n := len(utf16.Encode([]rune(s.Name))); n == 0 || n > 31 {
+			return errors.New("mscfb: stream names must have 1 to 31 characters")
+		}
+	}
+	sectors := [][]byte{}
+	fat := []uint32{}
+	// alloc appends data as a chain of sectors and returns its first sector
+	alloc := func(data []byte) uint32 {
+		if len(data) == 0 {
+			return wEndOfChain
+		}
+		start := uint32(len(sectors))
+		for off := 0; off < len(data); off += wSectorSize {
+			sec := make([]byte, wSectorSize)
+			copy(sec, data[off:])
+			sectors = append(sectors, sec)
+			fat = append(fat, uint32(len(sectors)))
+		}
+		fat[len(fat)-1] = wEndOfChain
+		return start
+	}
+
+	// small streams are packed into the mini stream in 64 byte sectors
+	starts := make([]uint32, len(streams))
+	mini, miniFAT := []byte{}, []uint32{}
+	for i, s := range streams {
+		if len(s.Data) >= wMiniCutoff || len(s.Data) == 0 {
+			continue
+		}
+		starts[i] = uint32(len(mini) / wMiniSectorSize)
+		n := (len(s.Data) + wMiniSectorSize - 1) / wMiniSectorSize
+		for j := 1; j < n; j++ {
+			miniFAT = append(miniFAT, starts[i]+uint32(j))
+		}
+		miniFAT = append(miniFAT, wEndOfChain)
+		mini = append(mini, s.Data...)
+		mini = append(mini, make([]byte, n*wMiniSectorSize-len(s.Data))...)
+	}
+
+	dir := make([]byte, ((len(streams)+1+3)/4)*4*wDirEntrySize)
+	dirStart := alloc(dir)
+	miniFATStart, miniFATSectors := uint32(wEndOfChain), 0
+	if len(miniFAT) > 0 {
+		b := make([]byte, 0, len(miniFAT)*4)
+		for _, v := range miniFAT {
+			b = binary.LittleEndian.AppendUint32(b, v)
+		}
+		for len(b)%wSectorSize != 0 {
+			b = binary.LittleEndian.AppendUint32(b, wFreeSect)
+		}
+		miniFATStart = alloc(b)
+		miniFATSectors = len(b) / wSectorSize
+	}
+	miniStart := alloc(mini)
+	for i, s := range streams {
+		if len(s.Data) >= wMiniCutoff {
+			starts[i] = alloc(s.Data)
+		} else if len(s.Data) == 0 {
+			starts[i] = wEndOfChain
+		}
+	}
+
+	// the FAT sectors follow the data and must also be covered by the FAT
+	fatSectors := 0
+	for fatSectors*wSectorSize/4 < len(fat)+fatSectors {
+		fatSectors++
+	}
+	if fatSectors > wHeaderDIFAT {
+		return errors.New("mscfb: compound file too large")
+	}
+	fatStart := uint32(len(sectors))
+	for i := 0; i < fatSectors; i++ {
+		fat = append(fat, wFATSect)
+	}
+	fatBytes := make([]byte, 0, fatSectors*wSectorSize)
+	for _, v := range fat {
+		fatBytes = binary.LittleEndian.AppendUint32(fatBytes, v)
+	}
+	for len(fatBytes) < fatSectors*wSectorSize {
+		fatBytes = binary.LittleEndian.AppendUint32(fatBytes, wFreeSect)
+	}
+	for off := 0; off < len(fatBytes); off += wSectorSize {
+		sectors = append(sectors, fatBytes[off:off+wSectorSize])
+	}
+
+	// directory: the root entry followed by the streams, which form a
+	// balanced binary tree below the root
+	order := make([]int, len(streams))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return compareNames(streams[order[a]].Name, streams[order[b]].Name) < 0
+	})
+	left := make([]uint32, len(streams))
+	right := make([]uint32, len(streams))
+	var tree func(lo, hi int) uint32
+	tree = func(lo, hi int) uint32 {
+		if lo >= hi {
+			return wNoStream
+		}
+		mid := (lo + hi) / 2
+		idx := order[mid]
+		left[idx] = tree(lo, mid)
+		right[idx] = tree(mid+1, hi)
+		return uint32(idx + 1)
+	}
+	rootChild := tree(0, len(streams))
+	writeDirEntry(dir[0:], "Root Entry", 5, wNoStream, wNoStream, rootChild, clsid, miniStart, uint64(len(mini)))
+	for i, s := range streams {
+		writeDirEntry(dir[(i+1)*wDirEntrySize:], s.Name, 2, left[i], right[i], wNoStream, [16]byte{}, starts[i], uint64(len(s.Data)))
+	}
+	for i := len(streams) + 1; i*wDirEntrySize < len(dir); i++ {
+		writeDirEntry(dir[i*wDirEntrySize:], "", 0, wNoStream, wNoStream, wNoStream, [16]byte{}, 0, 0)
+	}
+	for i := 0; i*wSectorSize < len(dir); i++ {
+		copy(sectors[int(dirStart)+i], dir[i*wSectorSize:])
+	}
+
+	hdr := make([]byte, 0, wSectorSize)
+	hdr = append(hdr, 0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1)
+	hdr = append(hdr, make([]byte, 16)...)
+	hdr = binary.LittleEndian.AppendUint16(hdr, 0x003E)
+	hdr = binary.LittleEndian.AppendUint16(hdr, 0x0003)
+	hdr = binary.LittleEndian.AppendUint16(hdr, 0xFFFE)
+	hdr = binary.LittleEndian.AppendUint16(hdr, 9)
+	hdr = binary.LittleEndian.AppendUint16(hdr, 6)
+	hdr = append(hdr, make([]byte, 6)...)
+	hdr = binary.LittleEndian.AppendUint32(hdr, 0)
+	hdr = binary.LittleEndian.AppendUint32(hdr, uint32(fatSectors))
+	hdr = binary.LittleEndian.AppendUint32(hdr, dirStart)
+	hdr = binary.LittleEndian.AppendUint32(hdr, 0)
+	hdr = binary.LittleEndian.AppendUint32(hdr, wMiniCutoff)
+	hdr = binary.LittleEndian.AppendUint32(hdr, miniFATStart)
+	hdr = binary.LittleEndian.AppendUint32(hdr, uint32(miniFATSectors))
+	hdr = binary.LittleEndian.AppendUint32(hdr, wEndOfChain)
+	hdr = binary.LittleEndian.AppendUint32(hdr, 0)
+	for i := 0; i < wHeaderDIFAT; i++ {
+		if i < fatSectors {
+			hdr = binary.LittleEndian.AppendUint32(hdr, fatStart+uint32(i))
+		} else {
+			hdr = binary.LittleEndian.AppendUint32(hdr, wFreeSect)
+		}
+	}
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	for _, sec := range sectors {
+		if _, err := w.Write(sec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeDirEntry(b []byte, name string, typ byte, left, right, child uint32, clsid [16]byte, start uint32, size uint64) {
+	u := utf16.Encode([]rune(name))
+	for i, c := range u {
+		binary.LittleEndian.PutUint16(b[i*2:], c)
+	}
+	if len(u) > 0 {
+		binary.LittleEndian.PutUint16(b[64:], uint16((len(u)+1)*2))
+	}
+	b[66] = typ
+	b[67] = 1 // black
+	binary.LittleEndian.PutUint32(b[68:], left)
+	binary.LittleEndian.PutUint32(b[72:], right)
+	binary.LittleEndian.PutUint32(b[76:], child)
+	copy(b[80:96], clsid[:])
+	binary.LittleEndian.PutUint32(b[116:], start)
+	binary.LittleEndian.PutUint64(b[120:], size)
+}
+
+// compareNames orders directory entry names the way compound files require,
+// shorter names first and then by their upper case form.
+func compareNames(a, b string) int {
+	la, lb := len(utf16.Encode([]rune(a))), len(utf16.Encode([]rune(b)))
+	if la != lb {
+		return la - lb
+	}
+	return strings.Compare(strings.ToUpper(a), strings.ToUpper(b))
+}
@@ -0,0 +1,111 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package mscfb
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func data(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i*7 + i/251)
+	}
+	return b
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	manyStreams := []Stream{}
+	for i := 0; i < 20; i++ {
+		manyStreams = append(manyStreams, Stream{strings.Repeat("s", i+1), data(100 * i)})
+	}
+	td := []struct {
+		name    string
+		streams []Stream
+	}{
+		{"single mini stream", []Stream{{"\x01Ole10Native", data(100)}}},
+		{"single large stream", []Stream{{"Package", data(5000)}}},
+		{"mixed", []Stream{{"CONTENTS", data(4096)}, {"\x01CompObj", data(4095)}, {"b", data(1)}, {"A", data(64)}}},
+		{"empty stream", []Stream{{"Empty", nil}, {"Full", data(10)}}},
+		{"many streams", manyStreams},
+		{"multiple FAT sectors", []Stream{{"Big", data(200 * 1024)}}},
+	}
+	for _, tc := range td {
+		buf := bytes.Buffer{}
+		if err := Write(&buf, [16]byte{1, 2, 3}, tc.streams...); err != nil {
+			t.Errorf("%s: error writing: %s", tc.name, err)
+			continue
+		}
+		if buf.Len()%wSectorSize != 0 {
+			t.Errorf("%s: expected the file size to be a multiple of the sector size, got %d", tc.name, buf.Len())
+		}
+		r, err := New(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Errorf("%s: error reading: %s", tc.name, err)
+			continue
+		}
+		got := map[string][]byte{}
+		for f, err := r.Next(); err == nil; f, err = r.Next() {
+			b, err := io.ReadAll(f)
+			if err != nil {
+				t.Errorf("%s: error reading %q: %s", tc.name, f.Name, err)
+			}
+			name := f.Name
+			if f.Initial < 0x20 {
+				// the reader keeps a leading control character apart
+				name = string(rune(f.Initial)) + name
+			}
+			got[name] = b
+		}
+		for _, s := range tc.streams {
+			b, ok := got[s.Name]
+			if !ok {
+				t.Errorf("%s: expected stream %q", tc.name, s.Name)
+			} else if !bytes.Equal(b, s.Data) {
+				t.Errorf("%s: stream %q has %d bytes, expected %d", tc.name, s.Name, len(b), len(s.Data))
+			}
+		}
+	}
+}
+
+func TestWriteInvalidNames(t *testing.T) {
+	td := []string{"", strings.Repeat("x", 32)}
+	for _, name := range td {
+		if err := Write(io.Discard, [16]byte{}, Stream{name, data(1)}); err == nil {
+			t.Errorf("expected an error for a stream named %q", name)
+		}
+	}
+}
+
+func TestCompareNames(t *testing.T) {
+	td := []struct {
+		a, b string
+		exp  int
+	}{
+		{"b", "AA", -1},
+		{"abc", "ABC", 0},
+		{"ABD", "abc", 1},
+		{"a", "b", -1},
+	}
+	for _, tc := range td {
+		got := compareNames(tc.a, tc.b)
+		if got < 0 {
+			got = -1
+		} else if got > 0 {
+			got = 1
+		}
+		if got != tc.exp {
+			t.Errorf("compareNames(%q, %q) = %d, expected %d", tc.a, tc.b, got, tc.exp)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package odf
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/common"
+)
+
+// Meta is the document metadata stored in meta.xml.
+type Meta struct {
+	Title       string
+	Subject     string
+	Description string
+	Creator     string
+	Initial     string
+	Created     time.Time
+	Modified    time.Time
+}
+
+// MetaFromCore returns the metadata of OOXML core properties.
+func MetaFromCore(cp common.CoreProperties) Meta {
+	m := Meta{}
+	x := cp.X()
+	if x == nil {
+		return m
+	}
+	m.Title = cp.Title()
+	m.Description = cp.Description()
+	m.Initial = cp.Author()
+	m.Creator = cp.LastModifiedBy()
+	if m.Creator == "" {
+		m.Creator = m.Initial
+	}
+	if x.Subject != nil {
+		m.Subject = string(x.Subject.Data)
+	}
+	m.Created = cp.Created()
+	m.Modified = cp.Modified()
+	return m
+}
+
+// ApplyCore stores the metadata in OOXML core properties.
+func (m Meta) ApplyCore(cp common.CoreProperties) {
+	if cp.X() == nil {
+		return
+	}
+	if m.Title != "" {
+		cp.SetTitle(m.Title)
+	}
+	if m.Description != "" {
+		cp.SetDescription(m.Description)
+	}
+	if m.Initial != "" {
+		cp.SetAuthor(m.Initial)
+	} else if m.Creator != "" {
+		cp.SetAuthor(m.Creator)
+	}
+	if m.Creator != "" {
+		cp.SetLastModifiedBy(m.Creator)
+	}
+	if m.Subject != "" {
+		cp.X().Subject = &unioffice.XSDAny{XMLName: xml.Name{Local: "dc:subject"}, Data: []byte(m.Subject)}
+	}
+	if !m.Created.IsZero() {
+		cp.SetCreated(m.Created)
+	}
+	if !m.Modified.IsZero() {
+		cp.SetModified(m.Modified)
+	}
+}
+
+// ParseMeta reads the metadata from a parsed meta.xml, a nil root yields
+// empty metadata.
+func ParseMeta(root *Node) Meta {
+	m := Meta{}
+	meta := root.Child(NSOffice, "meta")
+	if meta == nil {
+		return m
+	}
+	m.Title = meta.Child(NSDC, "title").TextContent()
+	m.Subject = meta.Child(NSDC, "subject").TextContent()
+	m.Description = meta.Child(NSDC, "description").TextContent()
+	m.Creator = meta.Child(NSDC, "creator").TextContent()
+	m.Initial = meta.Child(NSMeta, "initial-creator").TextContent()
+	m.Created = parseDate(meta.Child(NSMeta, "creation-date").TextContent())
+	m.Modified = parseDate(meta.Child(NSDC, "date").TextContent())
+	return m
+}
+
+func parseDate(s string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Bytes serializes the metadata as meta.xml.
+func (m Meta) Bytes() []byte {
+	w := NewWriter()
+	w.StartRoot("office:document-meta")
+	w.Start("office:meta")
+	w.Start("meta:generator")
+	w.Text("unioffice")
+	w.End()
+	elem := func(name, value string) {
+		if value == "" {
+			return
+		}
+		w.Start(name)
+		w.Text(value)
+		w.End()
+	}
+	elem("dc:title", m.Title)
+	elem("dc:subject", m.Subject)
+	elem("dc:description", m.Description)
+	elem("meta:initial-creator", m.Initial)
+	elem("dc:creator", m.Creator)
+	if !m.Created.IsZero() {
+		elem("meta:creation-date", m.Created.UTC().Format("2006-01-02T15:04:05"))
+	}
+	if !m.Modified.IsZero() {
+		elem("dc:date", m.Modified.UTC().Format("2006-01-02T15:04:05"))
+	}
+	return w.Bytes()
+}
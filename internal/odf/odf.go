@@ -0,0 +1,222 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+// Package odf contains the pieces shared by the OpenDocument converters: the
+// package container, a minimal XML tree, an XML writer and style helpers.
+package odf
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/common/tempstorage"
+)
+
+// OpenDocument mime types.
+const (
+	MimeTypeText         = "application/vnd.oasis.opendocument.text"
+	MimeTypeSpreadsheet  = "application/vnd.oasis.opendocument.spreadsheet"
+	MimeTypePresentation = "application/vnd.oasis.opendocument.presentation"
+)
+
+// Namespaces used by OpenDocument files.
+const (
+	NSOffice       = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	NSStyle        = "urn:oasis:names:tc:opendocument:xmlns:style:1.0"
+	NSText         = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	NSTable        = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	NSDraw         = "urn:oasis:names:tc:opendocument:xmlns:drawing:1.0"
+	NSFO           = "urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0"
+	NSXLink        = "http://www.w3.org/1999/xlink"
+	NSDC           = "http://purl.org/dc/elements/1.1/"
+	NSMeta         = "urn:oasis:names:tc:opendocument:xmlns:meta:1.0"
+	NSNumber       = "urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0"
+	NSPresentation = "urn:oasis:names:tc:opendocument:xmlns:presentation:1.0"
+	NSSVG          = "urn:oasis:names:tc:opendocument:xmlns:svg-compatible:1.0"
+	NSOf           = "urn:oasis:names:tc:opendocument:xmlns:of:1.2"
+	NSManifest     = "urn:oasis:names:tc:opendocument:xmlns:manifest:1.0"
+)
+
+// Namespaces is the list of namespace declarations written on the root
+// element of every generated part.
+var Namespaces = [][2]string{
+	{"office", NSOffice}, {"style", NSStyle}, {"text", NSText}, {"table", NSTable},
+	{"draw", NSDraw}, {"fo", NSFO}, {"xlink", NSXLink}, {"dc", NSDC}, {"meta", NSMeta},
+	{"number", NSNumber}, {"presentation", NSPresentation}, {"svg", NSSVG}, {"of", NSOf},
+}
+
+// Package is an OpenDocument package, a zip file with a mimetype entry and a
+// manifest listing the contained files.
+type Package struct {
+	MimeType string
+	files    map[string][]byte
+	types    map[string]string
+	order    []string
+}
+
+// NewPackage constructs an empty package of the given mime type.
+func NewPackage(mimeType string) *Package {
+	return &Package{MimeType: mimeType, files: map[string][]byte{}, types: map[string]string{}}
+}
+
+// ReadPackage reads an OpenDocument package.
+func ReadPackage(r io.ReaderAt, size int64) (*Package, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	p := NewPackage("")
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		switch f.Name {
+		case "mimetype":
+			p.MimeType = strings.TrimSpace(string(data))
+		case "META-INF/manifest.xml":
+		default:
+			p.Add(f.Name, data, "")
+		}
+	}
+	if p.MimeType == "" {
+		return nil, errors.New("not an OpenDocument package: missing mimetype")
+	}
+	return p, nil
+}
+
+// Add adds or replaces a file in the package.
+func (p *Package) Add(name string, data []byte, mediaType string) {
+	if _, ok := p.files[name]; !ok {
+		p.order = append(p.order, name)
+	}
+	p.files[name] = data
+	if mediaType == "" {
+		mediaType = mediaTypeFor(name)
+	}
+	p.types[name] = mediaType
+}
+
+// File returns the content of a file in the package.
+func (p *Package) File(name string) ([]byte, bool) {
+	d, ok := p.files[name]
+	return d, ok
+}
+
+// Part parses an XML file of the package, a missing file yields a nil tree.
+func (p *Package) Part(name string) (*Node, error) {
+	d, ok := p.files[name]
+	if !ok {
+		return nil, nil
+	}
+	return Parse(d)
+}
+
+// Write writes the package, the mimetype is stored uncompressed as the first
+// entry as required by the specification.
+func (p *Package) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err = fw.Write([]byte(p.MimeType)); err != nil {
+		return err
+	}
+	for _, name := range p.order {
+		if fw, err = zw.Create(name); err != nil {
+			return err
+		}
+		if _, err = fw.Write(p.files[name]); err != nil {
+			return err
+		}
+	}
+	if fw, err = zw.Create("META-INF/manifest.xml"); err != nil {
+		return err
+	}
+	if _, err = fw.Write(p.manifest()); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (p *Package) manifest() []byte {
+	x := NewWriter()
+	x.Start("manifest:manifest", "xmlns:manifest", NSManifest, "manifest:version", "1.2")
+	x.Empty("manifest:file-entry", "manifest:full-path", "/", "manifest:version", "1.2", "manifest:media-type", p.MimeType)
+	names := append([]string(nil), p.order...)
+	sort.Strings(names)
+	for _, name := range names {
+		x.Empty("manifest:file-entry", "manifest:full-path", name, "manifest:media-type", p.types[name])
+	}
+	x.End()
+	return x.Bytes()
+}
+
+func mediaTypeFor(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".xml":
+		return "text/xml"
+	case ".png":
+		return "image/png"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".gif":
+		return "image/gif"
+	case ".bmp":
+		return "image/bmp"
+	case ".tif", ".tiff":
+		return "image/tiff"
+	case ".svg":
+		return "image/svg+xml"
+	}
+	return "application/octet-stream"
+}
+
+// AddPicture stores image data under Pictures/ and returns its path, name
+// collisions are resolved with a counter.
+func (p *Package) AddPicture(data []byte, format string) string {
+	if format == "jpeg" {
+		format = "jpg"
+	}
+	n := 0
+	for _, name := range p.order {
+		if strings.HasPrefix(name, "Pictures/") {
+			n++
+		}
+	}
+	name := fmt.Sprintf("Pictures/image%d.%s", n+1, format)
+	p.Add(name, bytes.Clone(data), "")
+	return name
+}
+
+// ImageData returns the encoded bytes of an image, images of opened
+// documents are kept in the temporary storage at path.
+func ImageData(data *[]byte, path string) ([]byte, error) {
+	if data != nil {
+		return *data, nil
+	}
+	f, err := tempstorage.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
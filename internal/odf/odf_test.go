@@ -0,0 +1,158 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package odf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/v2/measurement"
+)
+
+func TestParseLength(t *testing.T) {
+	td := []struct {
+		in  string
+		exp measurement.Distance
+		ok  bool
+	}{
+		{"1in", measurement.Inch, true},
+		{"2.5cm", 2.5 * measurement.Centimeter, true},
+		{" 12pt ", 12 * measurement.Point, true},
+		{"1pc", 12 * measurement.Point, true},
+		{"10mm", 10 * measurement.Millimeter, true},
+		{"96px", 96 * measurement.Pixel96, true},
+		{"abc", 0, false},
+		{"xcm", 0, false},
+	}
+	for _, tc := range td {
+		got, ok := ParseLength(tc.in)
+		if ok != tc.ok || got != tc.exp {
+			t.Errorf("ParseLength(%q) = %v, %v; expected %v, %v", tc.in, got, ok, tc.exp, tc.ok)
+		}
+	}
+}
+
+func TestColors(t *testing.T) {
+	td := []struct {
+		in  string
+		exp string
+		ok  bool
+	}{
+		{"#ff8000", "FF8000", true},
+		{"transparent", "", false},
+		{"#12345", "", false},
+		{"#gg0000", "", false},
+	}
+	for _, tc := range td {
+		got, ok := ParseColor(tc.in)
+		if ok != tc.ok || got != tc.exp {
+			t.Errorf("ParseColor(%q) = %q, %v; expected %q, %v", tc.in, got, ok, tc.exp, tc.ok)
+		}
+	}
+	for in, exp := range map[string]string{"FF8000": "#ff8000", "80FF8000": "#ff8000", "#ABCDEF": "#abcdef", "123": ""} {
+		if got := HexColor(in); got != exp {
+			t.Errorf("HexColor(%q) = %q, expected %q", in, got, exp)
+		}
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	td := []struct {
+		in  string
+		exp string
+	}{
+		{"abc", "abc"},
+		{"a b", "a b"},
+		{"a  b", `a <text:s/>b`},
+		{"a    b", `a <text:s text:c="3"/>b`},
+		{" a", `<text:s/>a`},
+		{"a\tb", `a<text:tab/>b`},
+		{"a\nb", `a<text:line-break/>b`},
+		{"a<b", "a&lt;b"},
+	}
+	for _, tc := range td {
+		w := NewFragmentWriter()
+		w.WriteText(tc.in)
+		if got := string(w.Bytes()); got != tc.exp {
+			t.Errorf("WriteText(%q) = %q, expected %q", tc.in, got, tc.exp)
+		}
+	}
+}
+
+func TestStylesLookup(t *testing.T) {
+	w := NewWriter()
+	w.StartRoot("office:document-styles")
+	w.Start("office:styles")
+	w.Start("style:default-style", "style:family", "paragraph")
+	w.Empty("style:text-properties", "fo:font-size", "12pt", "fo:color", "#000000")
+	w.End()
+	w.Start("style:style", "style:name", "Base", "style:family", "paragraph")
+	w.Empty("style:text-properties", "fo:font-weight", "bold")
+	w.End()
+	w.Start("style:style", "style:name", "Child", "style:family", "paragraph", "style:parent-style-name", "Base")
+	w.Empty("style:text-properties", "fo:color", "#ff0000")
+	w.End()
+	w.End()
+	root, err := Parse(w.Bytes())
+	if err != nil {
+		t.Fatalf("error parsing styles: %s", err)
+	}
+	s := ParseStyles(root, nil)
+	td := []struct {
+		style, attr, exp string
+	}{
+		{"Child", "fo:color", "#ff0000"},
+		{"Child", "fo:font-weight", "bold"},
+		{"Child", "fo:font-size", "12pt"},
+		{"Base", "fo:color", "#000000"},
+		{"Missing", "fo:color", ""},
+	}
+	for _, tc := range td {
+		if got := s.Lookup(tc.style, "text-properties", tc.attr); got != tc.exp {
+			t.Errorf("Lookup(%q, %q) = %q, expected %q", tc.style, tc.attr, got, tc.exp)
+		}
+	}
+	if got := len(s.Ancestors("Child")); got != 2 {
+		t.Errorf("expected 2 ancestors, got %d", got)
+	}
+}
+
+func TestPackageRoundTrip(t *testing.T) {
+	p := NewPackage("application/vnd.oasis.opendocument.text")
+	p.Add("content.xml", []byte("<a/>"), "")
+	p.Add("Pictures/img.png", []byte{1, 2, 3}, "")
+	buf := bytes.Buffer{}
+	if err := p.Write(&buf); err != nil {
+		t.Fatalf("error writing package: %s", err)
+	}
+	if !bytes.Contains(buf.Bytes()[:100], []byte("mimetypeapplication/vnd.oasis.opendocument.text")) {
+		t.Errorf("expected the uncompressed mimetype first")
+	}
+	rp, err := ReadPackage(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading package: %s", err)
+	}
+	if rp.MimeType != p.MimeType {
+		t.Errorf("expected mime type %q, got %q", p.MimeType, rp.MimeType)
+	}
+	for _, name := range []string{"content.xml", "Pictures/img.png"} {
+		exp, _ := p.File(name)
+		if got, ok := rp.File(name); !ok || !bytes.Equal(got, exp) {
+			t.Errorf("%s: expected %v, got %v", name, exp, got)
+		}
+	}
+	if _, ok := rp.File("META-INF/manifest.xml"); ok {
+		t.Errorf("manifest should not be kept as a file")
+	}
+	if _, err := ReadPackage(strings.NewReader("not a zip"), 9); err == nil {
+		t.Errorf("expected an error reading an invalid package")
+	}
+}
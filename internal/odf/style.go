@@ -0,0 +1,330 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package odf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/measurement"
+)
+
+// Style is a parsed style:style element, properties are kept per property
+// element, e.g. "text-properties", keyed by the prefixed attribute name.
+type Style struct {
+	Name   string
+	Family string
+	Parent string
+	// DisplayName is the user visible name of common styles.
+	DisplayName string
+	Props       map[string]map[string]string
+}
+
+// ListLevel describes a level of a text:list-style.
+type ListLevel struct {
+	Bullet     bool
+	BulletChar string
+	NumFormat  string
+	Prefix     string
+	Suffix     string
+	Start      int
+}
+
+// Styles is the set of styles of a document, common and automatic styles
+// share the same name space.
+type Styles struct {
+	styles     map[string]*Style
+	ListStyles map[string][]ListLevel
+	// PageLayouts are keyed by the style:page-layout name.
+	PageLayouts map[string]*Style
+	// MasterPages are the style:master-page elements in document order.
+	MasterPages []*Node
+}
+
+var _prefixes = func() map[string]string {
+	m := map[string]string{}
+	for _, ns := range Namespaces {
+		m[ns[1]] = ns[0]
+	}
+	return m
+}()
+
+func prefixed(n xml.Name) string {
+	if p, ok := _prefixes[n.Space]; ok {
+		return p + ":" + n.Local
+	}
+	return n.Local
+}
+
+// ParseStyles collects the styles from the given office:document-content and
+// office:document-styles roots, nil roots are ignored.
+func ParseStyles(roots ...*Node) *Styles {
+	s := &Styles{styles: map[string]*Style{}, ListStyles: map[string][]ListLevel{}, PageLayouts: map[string]*Style{}}
+	for _, root := range roots {
+		if root == nil {
+			continue
+		}
+		for _, container := range []string{"font-face-decls", "styles", "automatic-styles", "master-styles"} {
+			c := root.Child(NSOffice, container)
+			if c == nil {
+				continue
+			}
+			for _, n := range c.Children {
+				switch {
+				case n.Is(NSStyle, "style"), n.Is(NSStyle, "default-style"):
+					st := parseStyle(n)
+					if n.Name.Local == "default-style" {
+						st.Name = "default-" + st.Family
+					}
+					s.styles[st.Name] = st
+				case n.Is(NSStyle, "page-layout"):
+					st := parseStyle(n)
+					s.PageLayouts[st.Name] = st
+				case n.Is(NSText, "list-style"):
+					s.ListStyles[n.AttrValue(NSStyle, "name")] = parseListStyle(n)
+				case n.Is(NSStyle, "master-page"):
+					s.MasterPages = append(s.MasterPages, n)
+				}
+			}
+		}
+	}
+	return s
+}
+
+func parseStyle(n *Node) *Style {
+	st := &Style{
+		Name:        n.AttrValue(NSStyle, "name"),
+		Family:      n.AttrValue(NSStyle, "family"),
+		Parent:      n.AttrValue(NSStyle, "parent-style-name"),
+		DisplayName: n.AttrValue(NSStyle, "display-name"),
+		Props:       map[string]map[string]string{},
+	}
+	if st.DisplayName == "" {
+		st.DisplayName = st.Name
+	}
+	for _, c := range n.Children {
+		if !c.IsElement() || !strings.HasSuffix(c.Name.Local, "-properties") {
+			continue
+		}
+		m := st.Props[c.Name.Local]
+		if m == nil {
+			m = map[string]string{}
+			st.Props[c.Name.Local] = m
+		}
+		for _, a := range c.Attr {
+			m[prefixed(a.Name)] = a.Value
+		}
+	}
+	return st
+}
+
+func parseListStyle(n *Node) []ListLevel {
+	levels := make([]ListLevel, 10)
+	for _, c := range n.Children {
+		if !c.IsElement() {
+			continue
+		}
+		lvl, err := strconv.Atoi(c.AttrValue(NSText, "level"))
+		if err != nil || lvl < 1 || lvl > 10 {
+			continue
+		}
+		l := ListLevel{Start: 1}
+		switch c.Name.Local {
+		case "list-level-style-bullet":
+			l.Bullet = true
+			l.BulletChar = c.AttrValue(NSText, "bullet-char")
+		case "list-level-style-number":
+			l.NumFormat = c.AttrValue(NSStyle, "num-format")
+			l.Prefix = c.AttrValue(NSStyle, "num-prefix")
+			l.Suffix = c.AttrValue(NSStyle, "num-suffix")
+			if v, err := strconv.Atoi(c.AttrValue(NSText, "start-value")); err == nil {
+				l.Start = v
+			}
+		default:
+			continue
+		}
+		levels[lvl-1] = l
+	}
+	return levels
+}
+
+// Style returns the style with the given name or nil.
+func (s *Styles) Style(name string) *Style { return s.styles[name] }
+
+// Lookup returns a property of a style following the parent chain and
+// finally the default style of the family.
+func (s *Styles) Lookup(name, kind, attr string) string {
+	seen := map[string]bool{}
+	family := ""
+	for name != "" && !seen[name] {
+		seen[name] = true
+		st := s.styles[name]
+		if st == nil {
+			break
+		}
+		family = st.Family
+		if v, ok := st.Props[kind][attr]; ok {
+			return v
+		}
+		name = st.Parent
+	}
+	if def := s.styles["default-"+family]; def != nil {
+		return def.Props[kind][attr]
+	}
+	return ""
+}
+
+// Ancestors returns the style and its parents, nearest first.
+func (s *Styles) Ancestors(name string) []*Style {
+	var res []*Style
+	seen := map[string]bool{}
+	for name != "" && !seen[name] {
+		seen[name] = true
+		st := s.styles[name]
+		if st == nil {
+			break
+		}
+		res = append(res, st)
+		name = st.Parent
+	}
+	return res
+}
+
+// StyleSet accumulates automatic styles while a part is written, identical
+// definitions share a name.
+type StyleSet struct {
+	names  map[string]string
+	counts map[string]int
+	w      *Writer
+}
+
+// NewStyleSet constructs an empty style set.
+func NewStyleSet() *StyleSet {
+	return &StyleSet{names: map[string]string{}, counts: map[string]int{}, w: NewFragmentWriter()}
+}
+
+// Props is a property element of a style, e.g. style:text-properties, with
+// alternating attribute names and values.
+type Props struct {
+	Element string
+	Attrs   []string
+}
+
+// Add registers a style and returns its name, prefix is used to build the
+// name, e.g. "P" for P1, P2... Styles without properties yield "" unless a
+// parent is given.
+func (s *StyleSet) Add(prefix, family, parent string, props ...Props) string {
+	return s.add(prefix, family, parent, nil, props)
+}
+
+// AddWithAttrs is like Add with extra attributes on the style:style element.
+func (s *StyleSet) AddWithAttrs(prefix, family, parent string, attrs []string, props ...Props) string {
+	return s.add(prefix, family, parent, attrs, props)
+}
+
+func (s *StyleSet) add(prefix, family, parent string, attrs []string, props []Props) string {
+	var nonEmpty []Props
+	for _, p := range props {
+		for i := 1; i < len(p.Attrs); i += 2 {
+			if p.Attrs[i] != "" {
+				nonEmpty = append(nonEmpty, p)
+				break
+			}
+		}
+	}
+	if len(nonEmpty) == 0 && parent == "" && len(attrs) == 0 {
+		return ""
+	}
+	key := fmt.Sprint(family, parent, attrs, nonEmpty)
+	if name, ok := s.names[key]; ok {
+		return name
+	}
+	s.counts[prefix]++
+	name := fmt.Sprintf("%s%d", prefix, s.counts[prefix])
+	s.names[key] = name
+	s.w.Start("style:style", append([]string{"style:name", name, "style:family", family, "style:parent-style-name", parent}, attrs...)...)
+	for _, p := range nonEmpty {
+		s.w.Empty(p.Element, p.Attrs...)
+	}
+	s.w.End()
+	return name
+}
+
+// Raw appends a pre-built style definition, e.g. a list style.
+func (s *StyleSet) Raw(b []byte) { s.w.Raw(b) }
+
+// Bytes returns the serialized style definitions.
+func (s *StyleSet) Bytes() []byte { return s.w.Bytes() }
+
+// Length formats a distance as an OpenDocument length.
+func Length(d measurement.Distance) string {
+	return strconv.FormatFloat(float64(d/measurement.Inch), 'f', 4, 64) + "in"
+}
+
+// ParseLength parses an OpenDocument length such as "2.5cm".
+func ParseLength(s string) (measurement.Distance, bool) {
+	s = strings.TrimSpace(s)
+	units := []struct {
+		suffix string
+		unit   measurement.Distance
+	}{{"cm", measurement.Centimeter}, {"mm", measurement.Millimeter}, {"in", measurement.Inch},
+		{"pt", measurement.Point}, {"pc", 12 * measurement.Point}, {"px", measurement.Pixel96}}
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), 64)
+			if err != nil {
+				return 0, false
+			}
+			return measurement.Distance(v) * u.unit, true
+		}
+	}
+	return 0, false
+}
+
+// ParsePercent parses a percentage such as "115%" into a fraction.
+func ParsePercent(s string) (float64, bool) {
+	if !strings.HasSuffix(s, "%") {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, false
+	}
+	return v / 100, true
+}
+
+// ParseColor parses a "#rrggbb" color into the upper case hex form used by
+// OOXML, "transparent" and invalid values are reported as not ok.
+func ParseColor(s string) (string, bool) {
+	if len(s) != 7 || s[0] != '#' {
+		return "", false
+	}
+	if _, err := strconv.ParseUint(s[1:], 16, 32); err != nil {
+		return "", false
+	}
+	return strings.ToUpper(s[1:]), true
+}
+
+// HexColor formats an RGB hex string, as stored in OOXML, for OpenDocument.
+func HexColor(rgb string) string {
+	rgb = strings.TrimPrefix(rgb, "#")
+	if len(rgb) == 8 {
+		// ARGB as used by SpreadsheetML
+		rgb = rgb[2:]
+	}
+	if len(rgb) != 6 {
+		return ""
+	}
+	return "#" + strings.ToLower(rgb)
+}
+
+func itoa(v int) string { return strconv.Itoa(v) }
@@ -0,0 +1,294 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package odf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Node is an element or a text node of a parsed XML part. OpenDocument
+// content is mixed, so a generic tree is easier to walk than typed structs.
+type Node struct {
+	Name     xml.Name
+	Attr     []xml.Attr
+	Children []*Node
+	// Text is the character data of a text node, text nodes have an empty
+	// Name.
+	Text string
+}
+
+// Parse parses an XML document into a tree and returns the root element.
+func Parse(data []byte) (*Node, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	root := &Node{}
+	stack := []*Node{root}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &Node{Name: t.Name, Attr: t.Copy().Attr}
+			parent.Children = append(parent.Children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.Children = append(parent.Children, &Node{Text: string(t)})
+		}
+	}
+	for _, c := range root.Children {
+		if c.IsElement() {
+			return c, nil
+		}
+	}
+	return nil, io.ErrUnexpectedEOF
+}
+
+// IsElement returns true if the node is an element and not character data.
+func (n *Node) IsElement() bool { return n.Name.Local != "" }
+
+// Is returns true if the node is the element space:local.
+func (n *Node) Is(space, local string) bool {
+	return n != nil && n.Name.Space == space && n.Name.Local == local
+}
+
+// AttrValue returns the value of the attribute space:local or "".
+func (n *Node) AttrValue(space, local string) string {
+	if n == nil {
+		return ""
+	}
+	for _, a := range n.Attr {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Child returns the first child element named space:local.
+func (n *Node) Child(space, local string) *Node {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if c.Is(space, local) {
+			return c
+		}
+	}
+	return nil
+}
+
+// ChildrenNamed returns the child elements named space:local.
+func (n *Node) ChildrenNamed(space, local string) []*Node {
+	if n == nil {
+		return nil
+	}
+	var res []*Node
+	for _, c := range n.Children {
+		if c.Is(space, local) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Find returns the first descendant element named space:local in document
+// order.
+func (n *Node) Find(space, local string) *Node {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if c.Is(space, local) {
+			return c
+		}
+		if f := c.Find(space, local); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// TextContent returns the concatenated character data of the node and its
+// descendants.
+func (n *Node) TextContent() string {
+	b := strings.Builder{}
+	var walk func(*Node)
+	walk = func(n *Node) {
+		if !n.IsElement() {
+			b.WriteString(n.Text)
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	if n != nil {
+		walk(n)
+	}
+	return b.String()
+}
+
+// Writer writes XML with literal, already prefixed element names.
+type Writer struct {
+	buf   bytes.Buffer
+	stack []string
+	open  bool
+}
+
+// NewWriter returns a writer that has the XML declaration written.
+func NewWriter() *Writer {
+	w := &Writer{}
+	w.buf.WriteString(xml.Header)
+	return w
+}
+
+// NewFragmentWriter returns a writer for a fragment without declaration.
+func NewFragmentWriter() *Writer { return &Writer{} }
+
+func (w *Writer) closeStart() {
+	if w.open {
+		w.buf.WriteByte('>')
+		w.open = false
+	}
+}
+
+// Start opens an element, attrs is a list of alternating names and values.
+// Attributes with empty values are omitted.
+func (w *Writer) Start(name string, attrs ...string) {
+	w.closeStart()
+	w.buf.WriteByte('<')
+	w.buf.WriteString(name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if attrs[i+1] == "" {
+			continue
+		}
+		w.buf.WriteByte(' ')
+		w.buf.WriteString(attrs[i])
+		w.buf.WriteString(`="`)
+		xml.EscapeText(&w.buf, []byte(attrs[i+1]))
+		w.buf.WriteByte('"')
+	}
+	w.stack = append(w.stack, name)
+	w.open = true
+}
+
+// StartRoot opens a root element declaring the OpenDocument namespaces.
+func (w *Writer) StartRoot(name string, attrs ...string) {
+	var all []string
+	for _, ns := range Namespaces {
+		all = append(all, "xmlns:"+ns[0], ns[1])
+	}
+	all = append(all, "office:version", "1.2")
+	w.Start(name, append(all, attrs...)...)
+}
+
+// Empty writes an element without content.
+func (w *Writer) Empty(name string, attrs ...string) {
+	w.Start(name, attrs...)
+	w.buf.WriteString("/>")
+	w.stack = w.stack[:len(w.stack)-1]
+	w.open = false
+}
+
+// End closes the most recently opened element.
+func (w *Writer) End() {
+	name := w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+	if w.open {
+		w.buf.WriteString("/>")
+		w.open = false
+		return
+	}
+	w.buf.WriteString("</")
+	w.buf.WriteString(name)
+	w.buf.WriteByte('>')
+}
+
+// Text writes escaped character data.
+func (w *Writer) Text(s string) {
+	if s == "" {
+		return
+	}
+	w.closeStart()
+	xml.EscapeText(&w.buf, []byte(s))
+}
+
+// Raw writes pre-serialized XML.
+func (w *Writer) Raw(b []byte) {
+	w.closeStart()
+	w.buf.Write(b)
+}
+
+// Bytes returns the written XML, all open elements are closed.
+func (w *Writer) Bytes() []byte {
+	for len(w.stack) > 0 {
+		w.End()
+	}
+	return w.buf.Bytes()
+}
+
+// WriteText writes text within a text:p or text:span element, runs of spaces,
+// tabs and line breaks are encoded with their text elements.
+func (w *Writer) WriteText(s string) {
+	b := strings.Builder{}
+	flush := func() {
+		w.Text(b.String())
+		b.Reset()
+	}
+	spaces := 0
+	prevSpace := true
+	for _, r := range s {
+		if r == ' ' {
+			if prevSpace {
+				spaces++
+				continue
+			}
+			b.WriteRune(r)
+			prevSpace = true
+			continue
+		}
+		if spaces > 0 {
+			flush()
+			if spaces == 1 {
+				w.Empty("text:s")
+			} else {
+				w.Empty("text:s", "text:c", itoa(spaces))
+			}
+			spaces = 0
+		}
+		prevSpace = false
+		switch r {
+		case '\t':
+			flush()
+			w.Empty("text:tab")
+		case '\n':
+			flush()
+			w.Empty("text:line-break")
+			prevSpace = true
+		default:
+			b.WriteRune(r)
+		}
+	}
+	flush()
+	if spaces == 1 {
+		w.Empty("text:s")
+	} else if spaces > 1 {
+		w.Empty("text:s", "text:c", itoa(spaces))
+	}
+}
@@ -0,0 +1,292 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package presentation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/pml"
+)
+
+// tableURI is the graphic data URI of tables.
+const tableURI = "http://schemas.openxmlformats.org/drawingml/2006/table"
+
+// CheckAccessibility checks the slides for accessibility issues: slides without
+// a title, pictures, charts and other graphics without alternative text,
+// tables without a header row or with merged cells, text with a low contrast
+// to its background, hyperlinks without text and shapes whose reading order
+// differs from their visual order.
+//
+// Only colors set on the slides are checked for contrast, colors inherited
+// from layouts, masters and table styles are not.
+func (p *Presentation) CheckAccessibility() []common.AccessibilityIssue {
+	var theme *dml.Theme
+	if len(p._fbf) > 0 {
+		theme = p._fbf[0]
+	}
+	issues := []common.AccessibilityIssue{}
+	for i, s := range p._ggd {
+		c := slideChecker{theme: theme, slide: fmt.Sprintf("slide %d", i+1), issues: &issues}
+		c.bg = p.slideBackground(s, theme)
+		if s.CSld == nil || s.CSld.SpTree == nil {
+			c.add("", common.AccessibilitySlideTitle, common.AccessibilityError, "slide has no title")
+			continue
+		}
+		shapes := s.CSld.SpTree.GroupShapeChoice
+		c.shapes(shapes, c.bg)
+		if !c.title {
+			c.add("", common.AccessibilitySlideTitle, common.AccessibilityError, "slide has no title")
+		}
+		c.readingOrder(shapes)
+	}
+	return issues
+}
+
+// slideBackground returns the background color of a slide, falling back to
+// the background of the first master and the theme's light color.
+func (p *Presentation) slideBackground(s *pml.Sld, theme *dml.Theme) string {
+	bg := func(csld *pml.CT_CommonSlideData) (string, bool) {
+		if csld == nil || csld.Bg == nil || csld.Bg.BackgroundChoice == nil || csld.Bg.BackgroundChoice.BgPr == nil {
+			return "", false
+		}
+		fpc := csld.Bg.BackgroundChoice.BgPr.FillPropertiesChoice
+		if fpc == nil {
+			return "", false
+		}
+		return common.SolidFillColor(theme, fpc.SolidFill)
+	}
+	if c, ok := bg(s.CSld); ok {
+		return c
+	}
+	if len(p._gad) > 0 {
+		if c, ok := bg(p._gad[0].CSld); ok {
+			return c
+		}
+	}
+	if c, ok := common.ThemeColor(theme, "bg1"); ok {
+		return c
+	}
+	return "FFFFFF"
+}
+
+type slideChecker struct {
+	theme  *dml.Theme
+	slide  string
+	bg     string
+	title  bool
+	issues *[]common.AccessibilityIssue
+}
+
+func (c *slideChecker) add(shape string, rule common.AccessibilityRule, sev common.AccessibilitySeverity, msg string) {
+	loc := c.slide
+	if shape != "" {
+		loc += fmt.Sprintf(", shape %q", shape)
+	}
+	*c.issues = append(*c.issues, common.AccessibilityIssue{Rule: rule, Severity: sev, Location: loc, Message: msg})
+}
+
+func (c *slideChecker) shapes(shapes []*pml.CT_GroupShapeChoice, bg string) {
+	for _, s := range shapes {
+		switch {
+		case s.Sp != nil:
+			c.shape(s.Sp, bg)
+		case s.Pic != nil:
+			if s.Pic.NvPicPr != nil && s.Pic.NvPicPr.CNvPr != nil && !common.HasAltText(s.Pic.NvPicPr.CNvPr) {
+				c.add(s.Pic.NvPicPr.CNvPr.NameAttr, common.AccessibilityAltText, common.AccessibilityError, "picture has no alternative text")
+			}
+		case s.GraphicFrame != nil:
+			c.graphicFrame(s.GraphicFrame, bg)
+		case s.GrpSp != nil:
+			c.shapes(s.GrpSp.GroupShapeChoice, bg)
+		}
+	}
+}
+
+func (c *slideChecker) shape(sp *pml.CT_Shape, bg string) {
+	name := ""
+	if sp.NvSpPr != nil && sp.NvSpPr.CNvPr != nil {
+		name = sp.NvSpPr.CNvPr.NameAttr
+	}
+	text := ""
+	if sp.TxBody != nil {
+		for _, p := range sp.TxBody.P {
+			for _, tr := range p.EG_TextRun {
+				if tr.TextRunChoice != nil && tr.TextRunChoice.R != nil {
+					text += tr.TextRunChoice.R.T
+				}
+			}
+		}
+	}
+	if sp.NvSpPr != nil && sp.NvSpPr.NvPr != nil && sp.NvSpPr.NvPr.Ph != nil {
+		switch sp.NvSpPr.NvPr.Ph.TypeAttr {
+		case pml.ST_PlaceholderTypeTitle, pml.ST_PlaceholderTypeCtrTitle:
+			if strings.TrimSpace(text) != "" {
+				c.title = true
+			}
+		}
+	}
+	if sp.NvSpPr != nil && sp.NvSpPr.CNvPr != nil && sp.NvSpPr.CNvPr.HlinkClick != nil && strings.TrimSpace(text) == "" && !common.HasAltText(sp.NvSpPr.CNvPr) {
+		c.add(name, common.AccessibilityLinkText, common.AccessibilityError, "hyperlink has no text or alternative text")
+	}
+	if sp.SpPr != nil && sp.SpPr.FillPropertiesChoice != nil {
+		if fill, ok := common.SolidFillColor(c.theme, sp.SpPr.FillPropertiesChoice.SolidFill); ok {
+			bg = fill
+		}
+	}
+	c.textBody(name, sp.TxBody, bg)
+}
+
+func (c *slideChecker) textBody(name string, tb *dml.CT_TextBody, bg string) {
+	if tb == nil {
+		return
+	}
+	for _, p := range tb.P {
+		for _, tr := range p.EG_TextRun {
+			if tr.TextRunChoice == nil || tr.TextRunChoice.R == nil {
+				continue
+			}
+			r := tr.TextRunChoice.R
+			if r.RPr == nil {
+				continue
+			}
+			if r.RPr.HlinkClick != nil && strings.TrimSpace(r.T) == "" {
+				c.add(name, common.AccessibilityLinkText, common.AccessibilityError, "hyperlink has no text")
+			}
+			if r.RPr.FillPropertiesChoice == nil || strings.TrimSpace(r.T) == "" {
+				continue
+			}
+			fg, ok := common.SolidFillColor(c.theme, r.RPr.FillPropertiesChoice.SolidFill)
+			if !ok {
+				continue
+			}
+			size := 18.0
+			if r.RPr.SzAttr != nil {
+				size = float64(*r.RPr.SzAttr) / 100
+			}
+			bold := r.RPr.BAttr != nil && *r.RPr.BAttr
+			if ratio, ok := common.ContrastRatio(fg, bg); ok && ratio < common.MinimumContrast(size, bold) {
+				c.add(name, common.AccessibilityContrast, common.AccessibilityWarning, fmt.Sprintf("text %q has a contrast ratio of %.2f:1", r.T, ratio))
+			}
+		}
+	}
+}
+
+func (c *slideChecker) graphicFrame(gf *pml.CT_GraphicalObjectFrame, bg string) {
+	name := ""
+	var pr *dml.CT_NonVisualDrawingProps
+	if gf.NvGraphicFramePr != nil && gf.NvGraphicFramePr.CNvPr != nil {
+		pr = gf.NvGraphicFramePr.CNvPr
+		name = pr.NameAttr
+	}
+	if gf.Graphic == nil || gf.Graphic.GraphicData == nil {
+		return
+	}
+	if gf.Graphic.GraphicData.UriAttr != tableURI {
+		if !common.HasAltText(pr) {
+			c.add(name, common.AccessibilityAltText, common.AccessibilityError, "graphic has no alternative text")
+		}
+		return
+	}
+	for _, a := range gf.Graphic.GraphicData.Any {
+		tbl, ok := a.(*dml.Tbl)
+		if !ok {
+			continue
+		}
+		if tbl.TblPr == nil || tbl.TblPr.FirstRowAttr == nil || !*tbl.TblPr.FirstRowAttr {
+			c.add(name, common.AccessibilityTableHeader, common.AccessibilityWarning, "table has no header row")
+		}
+		merged := false
+		for _, tr := range tbl.Tr {
+			for _, tc := range tr.Tc {
+				if tc.GridSpanAttr != nil && *tc.GridSpanAttr > 1 || tc.RowSpanAttr != nil && *tc.RowSpanAttr > 1 ||
+					tc.HMergeAttr != nil && *tc.HMergeAttr || tc.VMergeAttr != nil && *tc.VMergeAttr {
+					merged = true
+				}
+				cellBg := bg
+				if tc.TcPr != nil && tc.TcPr.FillPropertiesChoice != nil {
+					if fill, ok := common.SolidFillColor(c.theme, tc.TcPr.FillPropertiesChoice.SolidFill); ok {
+						cellBg = fill
+					}
+				}
+				c.textBody(name, tc.TxBody, cellBg)
+			}
+		}
+		if merged {
+			c.add(name, common.AccessibilityMergedCells, common.AccessibilityWarning, "table has merged cells")
+		}
+	}
+}
+
+// readingOrder checks that the title comes first in the reading order of the
+// slide and that the reading order of positioned shapes follows their visual
+// order, from top to bottom and left to right.
+func (c *slideChecker) readingOrder(shapes []*pml.CT_GroupShapeChoice) {
+	type placed struct {
+		name string
+		x, y int64
+	}
+	ordered := []placed{}
+	first := true
+	for _, s := range shapes {
+		var pr *dml.CT_NonVisualDrawingProps
+		var xfrm *dml.CT_Transform2D
+		title := false
+		switch {
+		case s.Sp != nil && s.Sp.NvSpPr != nil:
+			pr = s.Sp.NvSpPr.CNvPr
+			if s.Sp.SpPr != nil {
+				xfrm = s.Sp.SpPr.Xfrm
+			}
+			if nv := s.Sp.NvSpPr.NvPr; nv != nil && nv.Ph != nil {
+				title = nv.Ph.TypeAttr == pml.ST_PlaceholderTypeTitle || nv.Ph.TypeAttr == pml.ST_PlaceholderTypeCtrTitle
+			}
+		case s.Pic != nil && s.Pic.NvPicPr != nil:
+			pr = s.Pic.NvPicPr.CNvPr
+			if s.Pic.SpPr != nil {
+				xfrm = s.Pic.SpPr.Xfrm
+			}
+		case s.GraphicFrame != nil && s.GraphicFrame.NvGraphicFramePr != nil:
+			pr, xfrm = s.GraphicFrame.NvGraphicFramePr.CNvPr, s.GraphicFrame.Xfrm
+		case s.GrpSp != nil && s.GrpSp.NvGrpSpPr != nil:
+			pr = s.GrpSp.NvGrpSpPr.CNvPr
+		default:
+			continue
+		}
+		if pr == nil || pr.HiddenAttr != nil && *pr.HiddenAttr {
+			continue
+		}
+		if title && !first {
+			c.add(pr.NameAttr, common.AccessibilityReadingOrder, common.AccessibilityWarning, "title is not the first shape in reading order")
+		}
+		first = false
+		if xfrm != nil && xfrm.Off != nil && xfrm.Off.XAttr.ST_CoordinateUnqualified != nil && xfrm.Off.YAttr.ST_CoordinateUnqualified != nil {
+			ordered = append(ordered, placed{pr.NameAttr, *xfrm.Off.XAttr.ST_CoordinateUnqualified, *xfrm.Off.YAttr.ST_CoordinateUnqualified})
+		}
+	}
+	// shapes less than half an inch apart vertically are on the same line
+	const line = 457200
+	visual := append([]placed{}, ordered...)
+	sort.SliceStable(visual, func(i, j int) bool {
+		if yi, yj := visual[i].y/line, visual[j].y/line; yi != yj {
+			return yi < yj
+		}
+		return visual[i].x < visual[j].x
+	})
+	for i := range ordered {
+		if ordered[i] != visual[i] {
+			c.add(ordered[i].name, common.AccessibilityReadingOrder, common.AccessibilityInfo, fmt.Sprintf("reading order differs from visual order, %q is read before %q", ordered[i].name, visual[i].name))
+			return
+		}
+	}
+}
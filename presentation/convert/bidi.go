@@ -0,0 +1,57 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	"github.com/unidoc/unioffice/v2/internal/convertutils"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/ofc/sharedTypes"
+)
+
+// onOff returns true if b is set and not turned off.
+func onOff(b *dml.CT_Boolean) bool {
+	if b == nil {
+		return false
+	}
+	if v := b.ValAttr; v != nil {
+		if v.Bool != nil {
+			return *v.Bool
+		}
+		return v.ST_OnOff1 != sharedTypes.ST_OnOff1Off
+	}
+	return true
+}
+
+// reorderLine moves the symbols of l, which are laid out left to right in
+// logical order, to their visual positions per the Unicode Bidirectional
+// Algorithm, where the paragraph direction is right to left if rtl. The line
+// keeps the space it takes, so its alignment is unchanged. Lines without
+// right to left text are left as they are.
+func reorderLine(l *line, rtl bool) {
+	var (
+		syms   []*symbol
+		words  []*word
+		glyphs []convertutils.LineGlyph
+	)
+	for _, w := range l._baag {
+		for _, s := range w._dfec {
+			syms = append(syms, s)
+			words = append(words, w)
+			glyphs = append(glyphs, convertutils.LineGlyph{Text: s._gaf, RTL: s._rtl, X: w._dde + s._ffbe, W: s._aced})
+		}
+	}
+	if !convertutils.ReorderLine(glyphs, rtl) {
+		return
+	}
+	for i, s := range syms {
+		s._ffbe = glyphs[i].X - words[i]._dde
+		s._gaf = glyphs[i].Text
+	}
+}
@@ -33,7 +33,7 @@ switch _bdcf .AlgnAttr {case _gg .ST_TextAlignTypeR :_afg .SetTextAlignment (_cb
 };if len (_aba .EG_TextRun )==0{_afg .Append ("\u000a");_dff .Add (_afg );continue ;};for _ ,_adef :=range _aba .EG_TextRun {if _dbfd :=_adef .TextRunChoice .Br ;_dbfd !=nil {_afg .Append ("\u000a");}else if _fcgf :=_adef .TextRunChoice .R ;_fcgf !=nil {_bagg :=_aabd (_fcgf .RPr ,_ada );
 _bagg =_egdf (_bagg ,_bba );var _acag _cbc .Color ;if _bagg .FillPropertiesChoice .SolidFill !=nil {_acag ,_ =_ccbd .getColorFromSolidFill (_bagg .FillPropertiesChoice .SolidFill );}else {_acag =_cbc .ColorBlack ;};_caad ,_ebga ,_fcc ,_ :=_ccbd .makeStyleFromRPr (_bagg );
 _caad .Color =_acag ;if _ebga {_caad .TextRise =0.5;}else if _fcc {_caad .TextRise =-0.5;};_gdfg :=_fcgf .T ;if _bagg .CapAttr ==_gg .ST_TextCapsTypeAll {for _ ,_gcd :=range _gdfg {_gcd =[]rune (_cb .ToUpper (string (_gcd )))[0];};};_dbe :=_afg .Append (_gdfg );
-_dbe .Style =*_caad ;};};_ =_efdg ;_dff .Add (_afg );};};return _dff ;};func (_add *convertContext )getShapesFromSpPr (_dfb *_gg .CT_ShapeProperties ,_fcgg *_gg .CT_ShapeStyle ,_fgfa bool ,_gaddb float64 ,_ffg float64 )([]_cbc .Drawable ,float64 ,float64 ,float64 ,float64 ,_cbc .Color ,bool ){_badd :=[]_cbc .Drawable {};
+_dbe .Style =*_caad ;_ccbd ._fallback .Chunk (_afg ,_dbe );};};_ =_efdg ;_dff .Add (_afg );};};return _dff ;};func (_add *convertContext )getShapesFromSpPr (_dfb *_gg .CT_ShapeProperties ,_fcgg *_gg .CT_ShapeStyle ,_fgfa bool ,_gaddb float64 ,_ffg float64 )([]_cbc .Drawable ,float64 ,float64 ,float64 ,float64 ,_cbc .Color ,bool ){_badd :=[]_cbc .Drawable {};
 var _gba ,_gab ,_cfcd ,_aae ,_bbg float64 ;var _fac ,_acd ,_ecagd ,_fdb _cbc .Color ;var _dfba *_gg .CT_BlipFillProperties ;_bafe ,_deff :=1.0,1.0;if _fcgg !=nil {_fac ,_acd ,_fdb =_add .getStyleColors (_fcgg );};if _efed :=_dfb .Ln ;_efed !=nil {if _efed .LineFillPropertiesChoice .NoFill !=nil {_ecagd ,_bbg =nil ,0;
 }else {_ecagd ,_bbg ,_bafe =_add .getInfoFromLn (_efed );if _ecagd ==nil {_ecagd =_fdb ;};};};if _dfb .FillPropertiesChoice .NoFill !=nil {_acd ,_deff =nil ,0;}else if _fgfa {_acd =_add ._fef ._ebcg ;_deff =_add ._fef ._gbg ;_dfba =_add ._fef ._aace ;}else if _fdg :=_dfb .FillPropertiesChoice .SolidFill ;
 _fdg !=nil {_acd ,_deff =_add .getColorFromSolidFill (_fdg );};var _bac bool ;if _fceb :=_dfb .Xfrm ;_fceb !=nil {_gba ,_gab ,_cfcd ,_aae =_d .GetDataFromXfrm (_fceb );_gba +=_gaddb ;_gab +=_ffg ;_bac =true ;};if _bafc :=_dfb .GeometryChoice .CustGeom ;
@@ -46,25 +46,25 @@ _dee .SetBorderColor (_ecagd );_dee .SetBorderOpacity (_bafe );};if _cffa ||_geb
 };_badd =append (_badd ,_aca );};};return _badd ,_gba ,_gab ,_cfcd ,_aae ,_fac ,_bac ;};type prefixData struct{_gbcb string ;_dcb bool ;_egff float64 ;_bbga float64 ;};func (_gf *convertContext )addShapes (_bfb *_ce .CT_CommonSlideData ,_ddc bool ){if _bfb ==nil {return ;
 };_ccc :=&background {};if _edf :=_bfb .Bg ;_edf !=nil {if _fb :=_edf .BackgroundChoice .BgPr ;_fb !=nil {if _fb .FillPropertiesChoice .NoFill ==nil {if _cdd :=_fb .FillPropertiesChoice .SolidFill ;_cdd !=nil {_cg ,_df :=_gf .getColorFromSolidFill (_cdd );
 if _cg !=nil {_ccc ._ebcg =_cg ;_ccc ._gbg =_df ;};}else if _efg :=_fb .FillPropertiesChoice .BlipFill ;_efg !=nil {_ccc ._aace =_efg ;};};};};_gf ._fef =_ccc ;if _fdd :=_bfb .SpTree ;_fdd !=nil {for _ ,_af :=range _fdd .GroupShapeChoice {if _af !=nil {if _af .Sp !=nil {_ebf :=_gf .getShapes (_af .Sp ,_ddc ,false );
-_gf ._ccdd =append (_gf ._ccdd ,_ebf ...);};if _af .GraphicFrame !=nil {var _acb ,_ae ,_ecd ,_aadg float64 ;if _edd :=_af .GraphicFrame .Xfrm ;_edd !=nil {_acb ,_ae ,_ecd ,_aadg =_d .GetDataFromXfrm (_edd );};if _ecd ==0&&_aadg ==0{_ecd =_gf ._bfcac ;_aadg =_gf ._dcd ;
+_gf ._ccdd =append (_gf ._ccdd ,_ebf ...);_gf .tagShapes (len (_gf ._ccdd )-len (_ebf ),shapeTagOf (_af .Sp ),_ddc );};if _af .GraphicFrame !=nil {var _acb ,_ae ,_ecd ,_aadg float64 ;if _edd :=_af .GraphicFrame .Xfrm ;_edd !=nil {_acb ,_ae ,_ecd ,_aadg =_d .GetDataFromXfrm (_edd );};if _ecd ==0&&_aadg ==0{_ecd =_gf ._bfcac ;_aadg =_gf ._dcd ;
 };if _ccb :=_af .GraphicFrame .Graphic ;_ccb !=nil {if _dbb :=_ccb .GraphicData ;_dbb !=nil {for _ ,_fgf :=range _dbb .Any {if _bgd ,_ca :=_fgf .(*_f .Chart );_ca {_dc ,_dad :=_gf .makePdfBlockFromChart (_bgd ,_ecd ,_aadg );if _dad !=nil {_ege .Log .Debug ("C\u0061\u006e\u006e\u006ft \u0072e\u0061\u0064\u0020\u0062\u006co\u0063\u006b\u003a\u0020\u0025\u0073",_dad );
-};if _dc !=nil {_dc .SetPos (_acb ,_ae );_gf ._ccdd =append (_gf ._ccdd ,_dc );};}else if _aee ,_acg :=_fgf .(*_gg .Tbl );_acg {_ccbf :=_gf .makePdfBlockFromTable (_aee );if _ccbf !=nil {_bfbg :=_cbc .NewBlock (_ecd ,_aadg );_bfbg .SetPos (_acb ,_ae );
+};if _dc !=nil {_dc .SetPos (_acb ,_ae );_gf ._ccdd =append (_gf ._ccdd ,_dc );_gf .tagShapes (len (_gf ._ccdd )-1,frameTagOf (_af .GraphicFrame ,nil ),_ddc );};}else if _aee ,_acg :=_fgf .(*_gg .Tbl );_acg {_ccbf :=_gf .makePdfBlockFromTable (_aee );if _ccbf !=nil {_bfbg :=_cbc .NewBlock (_ecd ,_aadg );_bfbg .SetPos (_acb ,_ae );
 _dca :=_bfbg .Draw (_ccbf );if _dca !=nil {_ege .Log .Debug ("C\u0061\u006e\u006e\u006ft \u0064r\u0061\u0077\u0020\u0074\u0061b\u006c\u0065\u003a\u0020\u0025\u0073",_dca );if _dca ==_cbc .ErrContentNotFit {_bfbg =_cbc .NewBlock (_gf ._bfcac -1.5*_acb ,_gf ._dcd -1.5*_ae );
-_bfbg .SetPos (_acb ,_ae );_dca =_bfbg .Draw (_ccbf );};};if _dca ==nil {_gf ._ccdd =append (_gf ._ccdd ,_bfbg );};};};};};};};if _af .CxnSp !=nil {_fe :=_gf .getConnectors (_af .CxnSp );_gf ._ccdd =append (_gf ._ccdd ,_fe ...);};if _af .GrpSp !=nil {_bab :=0.0;
+_bfbg .SetPos (_acb ,_ae );_dca =_bfbg .Draw (_ccbf );};};if _dca ==nil {_gf ._ccdd =append (_gf ._ccdd ,_bfbg );_gf .tagShapes (len (_gf ._ccdd )-1,frameTagOf (_af .GraphicFrame ,_aee ),_ddc );};};};};};};};if _af .CxnSp !=nil {_fe :=_gf .getConnectors (_af .CxnSp );_gf ._ccdd =append (_gf ._ccdd ,_fe ...);};if _af .GrpSp !=nil {_bab :=0.0;
 _agb :=0.0;if _bgc :=_af .GrpSp .GrpSpPr .Xfrm ;_bgc !=nil {_bab ,_agb =_d .GetGroupOffsetFromXfrm (_bgc );};for _ ,_egb :=range _af .GrpSp .GroupShapeChoice {if _egb .CxnSp !=nil {_ade :=_gf .getGroupConnectors (_egb .CxnSp ,_bab ,_agb );_gf ._ccdd =append (_gf ._ccdd ,_ade ...);
 };};};if _af .Pic !=nil {_bga :=false ;var _cbd ,_efga ,_def ,_bbc float64 ;if _dba :=_af .Pic .SpPr ;_dba !=nil {if _fdda :=_dba .Xfrm ;_fdda !=nil {_cbd ,_efga ,_def ,_bbc =_d .GetDataFromXfrm (_fdda );_bga =true ;};};var _dfc _ce .ST_PlaceholderType ;
 var _eca *uint32 ;if _cfa :=_af .Pic .NvPicPr ;_cfa !=nil {if _cad :=_cfa .NvPr ;_cad !=nil {if _fed :=_cad .Ph ;_fed !=nil {_dfc =_fed .TypeAttr ;_eca =_fed .IdxAttr ;};};};_dgbe ,_ ,_ ,_ ,_ :=_ffab (_gf ._cgfd .CSld ,_dfc ,_eca );_ccg ,_ ,_ ,_ ,_ :=_ffab (_gf ._ccf .CSld ,_dfc ,_eca );
-if _ccg ==nil {_ccg =_dgbe ;};if _ccg !=nil &&!_bga {_cbd ,_efga ,_def ,_bbc =_d .GetDataFromXfrm (_ccg );};if _ff :=_af .Pic .BlipFill ;_ff !=nil {_eed :=_gf .getShapeFromBlipFill (_ff ,_cbd ,_efga ,_def ,_bbc ,_ddc );_gf ._ccdd =append (_gf ._ccdd ,_eed );
+if _ccg ==nil {_ccg =_dgbe ;};if _ccg !=nil &&!_bga {_cbd ,_efga ,_def ,_bbc =_d .GetDataFromXfrm (_ccg );};if _ff :=_af .Pic .BlipFill ;_ff !=nil {_eed :=_gf .getShapeFromBlipFill (_ff ,_cbd ,_efga ,_def ,_bbc ,_ddc );_gf ._ccdd =append (_gf ._ccdd ,_eed );_gf .tagShapes (len (_gf ._ccdd )-1,pictureTagOf (_af .Pic ),_ddc );
 };};};};};};const (FontStyle_Regular FontStyle =0;FontStyle_Bold FontStyle =1;FontStyle_Italic FontStyle =2;FontStyle_BoldItalic FontStyle =3;);func (_ecg *convertContext )makeStyleFromRPr (_gdfc *_gg .CT_TextCharacterProperties )(*_cbc .TextStyle ,bool ,bool ,bool ){var _aff ,_fcdb ,_cdg bool ;
 _adcc :=_ecg ._dedd .NewTextStyle ();if _gdfc !=nil {_gcb :=_d .FontStyle_Regular ;_beb :=_fcfc (_gdfc .BAttr );_bbgc :=_fcfc (_gdfc .IAttr );if _beb &&_bbgc {_gcb =_d .FontStyle_BoldItalic ;}else if _beb {_gcb =_d .FontStyle_Bold ;}else if _bbgc {_gcb =_d .FontStyle_Italic ;
 };_cdg =_gdfc .UAttr !=_gg .ST_TextUnderlineTypeUnset &&_gdfc .UAttr !=_gg .ST_TextUnderlineTypeNone ;_agfc :="\u0064e\u0066\u0061\u0075\u006c\u0074";if _agg :=_gdfc .Latin ;_agg !=nil {_agfc =_agg .TypefaceAttr ;}else if _cba :=_gdfc .Ea ;_cba !=nil {_agfc =_cba .TypefaceAttr ;
-}else if _fdf :=_gdfc .Cs ;_fdf !=nil {_agfc =_fdf .TypefaceAttr ;}else if _bgg :=_gdfc .Sym ;_bgg !=nil {_agfc =_bgg .TypefaceAttr ;};if _bde ,_agcf :=_d .StdFontsMap [_agfc ];_agcf {_adcc .Font =_d .AssignStdFontByName (_adcc ,_bde [_gcb ]);}else if _egfd :=_d .GetRegisteredFont (_agfc ,_gcb );
+}else if _fdf :=_gdfc .Cs ;_fdf !=nil {_agfc =_fdf .TypefaceAttr ;}else if _bgg :=_gdfc .Sym ;_bgg !=nil {_agfc =_bgg .TypefaceAttr ;};if _bde ,_agcf :=_d .StdFontsMap [_agfc ];_agcf {_adcc .Font =_d .AssignStdFontByName (_adcc ,_bde [_gcb ]);}else if _egfd :=_ecg ._fallback .FindFont (_agfc ,_gcb );
 _egfd !=nil {_adcc .Font =_egfd ;}else {_ege .Log .Debug ("\u0046\u006f\u006e\u0074\u0020\u0025\u0073\u0020\u0077\u0069\u0074h\u0020\u0073\u0074\u0079\u006c\u0065\u0020\u0025s\u0020i\u0073\u0020\u006e\u006f\u0074\u0020\u0066\u006f\u0075\u006e\u0064\u002c\u0020\u0072\u0065\u0073\u0065\u0074 \u0074\u006f\u0020\u0064\u0065\u0066\u0061\u0075\u006c\u0074\u002e",_agfc ,_gcb );
 _adcc .Font =_d .AssignStdFontByName (_adcc ,_d .StdFontsMap ["\u0064e\u0066\u0061\u0075\u006c\u0074"][_gcb ]);};var _aceg float64 ;if _baefc :=_gdfc .SzAttr ;_baefc !=nil {_aceg =_a .Round (float64 (*_baefc )/100)-0.5;}else {_aceg =_d .DefaultFontSize ;
 };if _ebdac :=_gdfc .BaselineAttr ;_ebdac !=nil {if _gdaa :=_ebdac .ST_PercentageDecimal ;_gdaa !=nil {if *_gdaa > 0{_aff =true ;}else if *_gdaa < 0{_fcdb =true ;};};};if _aff ||_fcdb {_aceg =_a .Round (_aceg *0.64);};_adcc .FontSize =_aceg ;_ebdb :=0.0;
 if _beeb :=_gdfc .SpcAttr ;_beeb !=nil {if _bgag :=_beeb .ST_TextPointUnqualified ;_bgag !=nil &&*_bgag > 0{_ebdb =float64 (*_bgag )/100;};};_adcc .CharSpacing =_ebdb ;};return &_adcc ,_aff ,_fcdb ,_cdg ;};func (_gfaf *textboxContext )drawParagraphs (){_gfaf ._dggc .NewPage ();
-for _ ,_bgaf :=range _gfaf ._fga {for _ ,_aacg :=range _bgaf ._acdg {for _ ,_gega :=range _aacg ._baag {for _ ,_eacc :=range _gega ._dfec {_cffb :=_gfaf ._dggc .NewStyledParagraph ();if _eacc ._dafa {_eacc ._gcg =0;}else if _eacc ._fgb {_eacc ._gcg =1.2*_aacg ._efab -_eacc ._dgbd ;
-};_ceg :=_gega ._dde +_eacc ._ffbe ;_dafb :=_bgaf ._eag +_aacg ._bbf +_eacc ._gcg ;_cffb .SetPos (_ceg ,_dafb );_cdaf :=_cffb .Append (_eacc ._gaf );if _eacc ._dae !=nil {_cdaf .Style =*_eacc ._dae ;};_gfaf ._dggc .Draw (_cffb );if _eacc ._ffce {_gddc :=_dafb +_eacc ._dgbd +2;
+for _ ,_bgaf :=range _gfaf ._fga {for _ ,_aacg :=range _bgaf ._acdg {reorderLine (_aacg ,_bgaf ._rtl );for _ ,_gega :=range _aacg ._baag {for _ ,_eacc :=range _gega ._dfec {_cffb :=_gfaf ._dggc .NewStyledParagraph ();if _eacc ._dafa {_eacc ._gcg =0;}else if _eacc ._fgb {_eacc ._gcg =1.2*_aacg ._efab -_eacc ._dgbd ;
+};_ceg :=_gega ._dde +_eacc ._ffbe ;_dafb :=_bgaf ._eag +_aacg ._bbf +_eacc ._gcg ;_cffb .SetPos (_ceg ,_dafb );_cdaf :=_cffb .Append (_eacc ._gaf );if _eacc ._dae !=nil {_cdaf .Style =*_eacc ._dae ;_gfaf ._eafe ._fallback .Chunk (_cffb ,_cdaf );};_gfaf ._dggc .Draw (_cffb );if _eacc ._ffce {_gddc :=_dafb +_eacc ._dgbd +2;
 _d .DrawLine (_gfaf ._dggc ,_ceg ,_gddc ,_ceg +_eacc ._aced ,_gddc ,1,_eacc ._dae .Color );};};};};};};func (_ebg *convertContext )tileImage (_bfa *_cbc .Image ,_fae *_gg .CT_TileInfoProperties ,_bc ,_bae float64 )*_cbc .Image {_be ,_aeb :=1.0,1.0;if _fgc :=_fae .SxAttr ;
 _fgc !=nil {_be =_d .FromSTPercentage (_fgc );};if _dbg :=_fae .SyAttr ;_dbg !=nil {_aeb =_d .FromSTPercentage (_dbg );};_fcb :=_d .MakeTempCreator (_bc ,_bae );_bfa .Scale (_be ,_aeb );_daf ,_fgg :=_bfa .Width (),_bfa .Height ();var _gfa ,_eec float64 ;
 if _fda :=_fae .TxAttr ;_fda !=nil {_gfa =_aa .FromEMU (_d .FromSTCoordinate (*_fda ));};if _bdcb :=_fae .TyAttr ;_bdcb !=nil {_eec =_aa .FromEMU (_d .FromSTCoordinate (*_bdcb ));};if _gfa > 0{_gfa -=_daf ;};if _eec > 0{_eec -=_fgg ;};_egf :=_ebg ._bfcac /_daf +1;
@@ -83,10 +83,10 @@ return nil ;};return _adc ;};func _eebf (_gceb ,_cebdf *_gg .CT_TextParagraphPro
 return _fdcd ;};
 
 // ConvertToPdfWithOptions convert a presentation to PDF with given options.
-func ConvertToPdfWithOptions (pr *_ac .Presentation ,opts *Options )*_cbc .Creator {_gad :=pr .X ().SldSz ;_aad :=_aa .FromEMU (int64 (_gad .CxAttr ));_gb :=_aa .FromEMU (int64 (_gad .CyAttr ));_fc :=_cbc .PageSize {_aad ,_gb };if (_fc ==_cbc .PageSize {}){_fc =_d .GetDefaultPageSize ();
-if opts !=nil &&opts .DefaultPageSize !=_d .DefaultPageSize {_fc =_d .GetPageDimensions (opts .DefaultPageSize );};};_dd :=_cbc .New ();_dd .SetPageSize (_fc );var _cbb *_gg .Theme ;if len (pr .Themes ())> 0{_cbb =pr .Themes ()[0];};for _ ,_deg :=range pr .Slides (){if _deg .X ()==nil {continue ;
-};_efd :=&convertContext {_dedd :_dd ,_fade :&_deg ,_ccf :_deg .GetSlideLayout (),_cgfd :pr .SlideMasters ()[0].X (),_bdeb :pr ,_bgca :_cbb ,_bfe :_deg .X ().ClrMapOvr ,_dcd :_fc [1],_bfcac :_fc [0]};_efd .extractDefaultProperties ();_efd .makeSlide ();
-_efd .drawSlide ();};return _dd ;};func (_ggde *convertContext )getInfoFromLn (_fcbf *_gg .CT_LineProperties )(_cbc .Color ,float64 ,float64 ){if _fcbf ==nil ||_fcbf .LineFillPropertiesChoice .NoFill !=nil {return nil ,0,0;};var _adaf float64 ;_fdab ,_gebg :=_ggde .getColorFromSolidFill (_fcbf .LineFillPropertiesChoice .SolidFill );
+func ConvertToPdfWithOptions (pr *_ac .Presentation ,opts *Options )*_cbc .Creator {return convertPresentation (pr ,opts ,newFallback (opts ));};func convertPresentation (pr *_ac .Presentation ,opts *Options ,_ecfb *_d .Fallback )*_cbc .Creator {_gad :=pr .X ().SldSz ;_aad :=_aa .FromEMU (int64 (_gad .CxAttr ));_gb :=_aa .FromEMU (int64 (_gad .CyAttr ));_fc :=_cbc .PageSize {_aad ,_gb };if (_fc ==_cbc .PageSize {}){_fc =_d .GetDefaultPageSize ();
+if opts !=nil &&opts .DefaultPageSize !=_d .DefaultPageSize {_fc =_d .GetPageDimensions (opts .DefaultPageSize );};};_dd :=_cbc .New ();_dd .SetPageSize (_fc );_dgt :=newTagger (opts );var _cbb *_gg .Theme ;if len (pr .Themes ())> 0{_cbb =pr .Themes ()[0];};for _ ,_deg :=range pr .Slides (){if _deg .X ()==nil {continue ;
+};_efd :=&convertContext {_fallback :_ecfb ,_tags :_dgt ,_dedd :_dd ,_fade :&_deg ,_ccf :_deg .GetSlideLayout (),_cgfd :pr .SlideMasters ()[0].X (),_bdeb :pr ,_bgca :_cbb ,_bfe :_deg .X ().ClrMapOvr ,_dcd :_fc [1],_bfcac :_fc [0]};_efd .extractDefaultProperties ();_efd .makeSlide ();
+_efd .drawSlide ();};setPdfOutput (_dd ,pr ,opts ,_dgt );return _dd ;};func (_ggde *convertContext )getInfoFromLn (_fcbf *_gg .CT_LineProperties )(_cbc .Color ,float64 ,float64 ){if _fcbf ==nil ||_fcbf .LineFillPropertiesChoice .NoFill !=nil {return nil ,0,0;};var _adaf float64 ;_fdab ,_gebg :=_ggde .getColorFromSolidFill (_fcbf .LineFillPropertiesChoice .SolidFill );
 if _cfab :=_fcbf .WAttr ;_cfab !=nil {_adaf =_aa .FromEMU (int64 (*_cfab ));}else {_adaf =1;};return _fdab ,_adaf ,_gebg ;};func (_cbde *textboxContext )addPrefix (_eea *prefixData ,_eecc *_cbc .TextStyle ){_ged :=_bgbg (_eea ._gbcb );_aedd :=*_eecc ;if _eea ._dcb {_aedd .Font =_d .AssignStdFontByName (_aedd ,"\u0053\u0079\u006d\u0062\u006f\u006c");
 };for _ ,_dadg :=range _ged {_dadg ._dae =&_aedd ;_cbde .addTextSymbol (_dadg );};_ffbc :=-(_eea ._bbga +_cbde ._gbf ._bbfd );if _ffbc < 0{_ffbc =0;};_aec :=&symbol {_gaf :"\u0020",_aced :_ffbc };_cbde .addTextSymbol (_aec );_cbde ._gbf ._dde +=(_eea ._bbga +_eea ._egff );
 };func (_afeb *convertContext )makePdfImageFromBlipFill (_fgae *_gg .CT_BlipFillProperties ,_gbbb bool )(*_cbc .Image ,[]*_gg .CT_BlipChoice ,error ){if _cgdf :=_fgae .Blip ;_cgdf !=nil {if _fcga :=_cgdf .EmbedAttr ;_fcga !=nil {var _defda _dg .ImageRef ;
@@ -106,7 +106,7 @@ return _cabf ;};_ccef ,_faba :=_gfcc .renderCurrentStateToGoImage ();if _faba !=
 return _cabf ;};_gccc :=_ecaa .Bounds ();_gebc :=_b .NewRGBA (_gccc );_ebcb ,_ecbg :=_cabf .Width (),_cabf .Height ();for _ ,_gaeg :=range _fgfab {if _bbab :=_gaeg .AlphaModFix ;_bbab ==nil {continue ;};if _cege :=_gaeg .AlphaModFix .AmtAttr ;_cege !=nil {if _fgda :=_cege .ST_PositivePercentageDecimal ;
 _fgda !=nil {_bgagb :=uint8 (255*(*_fgda )/100000);_bfce :=_b .NewUniform (_eb .Alpha {_bgagb });_eg .Draw (_gebc ,_gccc ,_ccef ,_b .Point {0,0},_eg .Src );_eg .DrawMask (_gebc ,_gccc ,_ecaa ,_b .Point {0,0},_bfce ,_b .Point {0,0},_eg .Over );};};};_daff :=_b .Rect (int (_caed ),int (_cdaa ),int (_caed +_ebcb )+1,int (_cdaa +_ecbg )+1);
 _bfed :=_d .CropImageByRect (_gebc ,_daff );_bedg ,_faba :=_gfcc ._dedd .NewImageFromGoImage (_bfed );if _faba !=nil {_ege .Log .Debug ("\u0045\u0072\u0072\u006f\u0072\u0020\u0061\u006e\u0020\u0069\u006d\u0061\u0067\u0065\u0020t\u006f \u0061\u0020\u0047\u006f\u0020\u0069\u006d\u0061\u0067\u0065\u003a\u0020\u0025\u0073",_faba );
-return _cabf ;};return _bedg ;};type paragraph struct{_eacbc float64 ;_fca float64 ;_aaca float64 ;_egfc float64 ;_abe float64 ;_bff float64 ;_eag float64 ;_bgaa float64 ;_ebaf _gg .ST_TextAlignType ;_ddga float64 ;_bdb bool ;_acdg []*line ;};func _fcdbe (_dcgd *_gg .CT_TableCellProperties ,_acfd *_gg .CT_TableStyleCellStyle ,_cafc ,_dgbcf ,_dfgb ,_ggdf bool )*_gg .CT_TableCellProperties {_bggf :=_gg .NewCT_TableCellProperties ();
+return _cabf ;};return _bedg ;};type paragraph struct{_eacbc float64 ;_fca float64 ;_aaca float64 ;_egfc float64 ;_abe float64 ;_bff float64 ;_eag float64 ;_bgaa float64 ;_ebaf _gg .ST_TextAlignType ;_ddga float64 ;_bdb bool ;_acdg []*line ;_rtl bool ;};func _fcdbe (_dcgd *_gg .CT_TableCellProperties ,_acfd *_gg .CT_TableStyleCellStyle ,_cafc ,_dgbcf ,_dfgb ,_ggdf bool )*_gg .CT_TableCellProperties {_bggf :=_gg .NewCT_TableCellProperties ();
 if _dcgd !=nil {*_bggf =*_dcgd ;};if _acfd ==nil {return _bggf ;};if _acdc :=_acfd .ThemeableFillStyleChoice .FillRef ;_acdc !=nil {_cfac :=_gg .NewCT_SolidColorFillProperties ();_cfac .ScrgbClr =_acdc .ScrgbClr ;_cfac .SrgbClr =_acdc .SrgbClr ;_cfac .HslClr =_acdc .HslClr ;
 _cfac .SysClr =_acdc .SysClr ;_cfac .SchemeClr =_acdc .SchemeClr ;_cfac .PrstClr =_acdc .PrstClr ;_bggf .FillPropertiesChoice .SolidFill =_cfac ;};if _bggf .FillPropertiesChoice .NoFill ==nil &&_bggf .FillPropertiesChoice .SolidFill ==nil {if _agbea :=_acfd .ThemeableFillStyleChoice .Fill ;
 _agbea !=nil {if _bggf .FillPropertiesChoice .NoFill ==nil {_bggf .FillPropertiesChoice .NoFill =_agbea .FillPropertiesChoice .NoFill ;};if _bggf .FillPropertiesChoice .SolidFill ==nil {_bggf .FillPropertiesChoice .SolidFill =_agbea .FillPropertiesChoice .SolidFill ;
@@ -123,9 +123,9 @@ type FontStyle =_d .FontStyle ;func (_dec *textboxContext )newParagraph (){_afd
 func RegisterFont (name string ,style FontStyle ,font *_de .PdfFont ){_d .RegisterFont (name ,style ,font );};func (_bcca *textboxContext )addTextRun (_eaa *_gg .EG_TextRun ,_dded *_gg .CT_TextCharacterProperties ,_face _cbc .Color ,_dddc *prefixData ){if _gdd :=_eaa .TextRunChoice .Br ;
 _gdd !=nil {_bcca .addCurrentWordToParagraph ();_bcca .newLine ();_bcca .newWord ();}else if _ffaf :=_eaa .TextRunChoice .R ;_ffaf !=nil {var _cfef _cbc .Color ;if _ffaf .RPr !=nil &&_ffaf .RPr .FillPropertiesChoice .SolidFill !=nil {_cfef ,_ =_bcca ._eafe .getColorFromSolidFill (_ffaf .RPr .FillPropertiesChoice .SolidFill );
 }else if _face !=nil {_cfef =_face ;}else if _dded .FillPropertiesChoice .SolidFill !=nil {_cfef ,_ =_bcca ._eafe .getColorFromSolidFill (_dded .FillPropertiesChoice .SolidFill );}else {_cfef =_cbc .ColorBlack ;};_cgfg :=_egdf (_ffaf .RPr ,_dded );_fggc ,_gcfc ,_deea ,_bgaac :=_bcca ._eafe .makeStyleFromRPr (_cgfg );
-_fggc .Color =_cfef ;if _dddc !=nil {_bcca .addPrefix (_dddc ,_fggc );};_dda :=_bgbg (_ffaf .T );for _ ,_agba :=range _dda {_agba ._dae =_fggc ;_agba ._dafa =_gcfc ;_agba ._fgb =_deea ;_agba ._ffce =_bgaac ;if _cgfg .CapAttr ==_gg .ST_TextCapsTypeAll {_agba ._gaf =_cb .ToUpper (_agba ._gaf );
+_fggc .Color =_cfef ;if _dddc !=nil {_bcca .addPrefix (_dddc ,_fggc );};_dda :=_bgbg (_d .ShapeText (_ffaf .T ));_fbrtl :=onOff (_cgfg .Rtl );for _ ,_agba :=range _dda {_agba ._rtl =_fbrtl ;_agba ._dae =_fggc ;_agba ._dafa =_gcfc ;_agba ._fgb =_deea ;_agba ._ffce =_bgaac ;if _cgfg .CapAttr ==_gg .ST_TextCapsTypeAll {_agba ._gaf =_cb .ToUpper (_agba ._gaf );
 };_bcca .addTextSymbol (_agba );};};};func _bgbg (_deeb string )[]*symbol {_dbfb :=[]*symbol {};for _ ,_edb :=range _deeb {_dbfb =append (_dbfb ,&symbol {_gaf :string (_edb )});};return _dbfb ;};func (_cbcg *textboxContext )assignPropsToCurrentParagraph (_ede *_gg .CT_TextParagraphProperties ){_dgge :=12.4;
-if _ede ==nil {_cbcg ._bcgb ._ddga =_dgge ;return ;};if _adae :=_ede .DefRPr ;_adae !=nil {_dfbb :=_adae .SzAttr ;if _dfbb !=nil {_ebac :=float64 (*_dfbb )/1200;if _dgge <=_ebac {_dgge =_ebac ;};};};if _dgbeg :=_ede .MarLAttr ;_dgbeg !=nil {_cbcg ._bcgb ._abe =_aa .FromEMU (int64 (*_dgbeg ));
+if _ede ==nil {_cbcg ._bcgb ._ddga =_dgge ;return ;};if _ede .RtlAttr !=nil {_cbcg ._bcgb ._rtl =*_ede .RtlAttr ;};if _adae :=_ede .DefRPr ;_adae !=nil {_dfbb :=_adae .SzAttr ;if _dfbb !=nil {_ebac :=float64 (*_dfbb )/1200;if _dgge <=_ebac {_dgge =_ebac ;};};};if _dgbeg :=_ede .MarLAttr ;_dgbeg !=nil {_cbcg ._bcgb ._abe =_aa .FromEMU (int64 (*_dgbeg ));
 };_cbcg ._bcgb ._bff =_cbcg ._dagd ;if _fadec :=_ede .MarRAttr ;_fadec !=nil {_cbcg ._bcgb ._bff -=_aa .FromEMU (int64 (*_fadec ));};if _cadb :=_ede .IndentAttr ;_cadb !=nil {_cbcg ._bcgb ._egfc =_aa .FromEMU (int64 (*_cadb ));};if _edc :=_ede .LatinLnBrkAttr ;
 _edc !=nil {_cbcg ._bcgb ._bdb =*_edc ;};if _dgggc :=_ede .LnSpc ;_dgggc !=nil &&_dgggc .TextSpacingChoice !=nil {if _beeg :=_dgggc .TextSpacingChoice .SpcPct ;_beeg !=nil {if _ddcf :=_beeg .ValAttr .ST_TextSpacingPercent ;_ddcf !=nil {_dgge =float64 (*_ddcf )/5000;
 };};};var _agag float64 ;if _gbgd :=_ede .SpcBef ;_gbgd !=nil &&_gbgd .TextSpacingChoice !=nil {if _cfb :=_gbgd .TextSpacingChoice .SpcPts ;_cfb !=nil {_agag =float64 (_cfb .ValAttr )/100;};};_bfbb :=_cbcg ._fga ;if len (_bfbb )> 0{_agag -=_bfbb [len (_bfbb )-1]._aaca ;
@@ -137,7 +137,21 @@ type Options struct{
 
 // DefaultPageSize is applied when there is no page size explicitly set in the document.
 // A4 is the default option.
-DefaultPageSize _d .PageSize ;};func (_afbd *convertContext )getColorFromMatrixReference (_agbe *_gg .CT_StyleMatrixReference )_cbc .Color {if _agbe ==nil {return nil ;};var _eaga _cbc .Color ;var _fbca string ;if _eddc :=_agbe .SrgbClr ;_eddc !=nil {_fbca =_eddc .ValAttr ;
+DefaultPageSize _d .PageSize ;
+
+// PdfA selects the PDF/A conformance level of the output, PdfANone by default. Fonts are embedded
+// in PDF/A output, so the fonts used by the presentation should be registered with RegisterFont.
+PdfA PdfAConformance ;
+
+// Tagged produces a tagged PDF with a section for each slide holding the titles, text, tables and
+// pictures of the slide, with the alternative text of pictures and charts.
+Tagged bool ;
+
+// FontFallback sets the fonts tried for the characters missing from the font of a run and the
+// substitutes of fonts that are not registered, such as Carlito for Calibri. Built-in chains and
+// substitutes are used for the scripts and fonts it does not list. The fonts replaced are
+// returned by ConvertToPdfWithSubstitutions.
+FontFallback *FontFallback ;};func (_afbd *convertContext )getColorFromMatrixReference (_agbe *_gg .CT_StyleMatrixReference )_cbc .Color {if _agbe ==nil {return nil ;};var _eaga _cbc .Color ;var _fbca string ;if _eddc :=_agbe .SrgbClr ;_eddc !=nil {_fbca =_eddc .ValAttr ;
 }else if _afcg :=_agbe .SchemeClr ;_afcg !=nil {_fbca =_d .GetColorStringFromDmlColor (_afbd ._bdeb .GetColorBySchemeColor (_afcg .ValAttr ));_fbca =_d .AdjustColor (_fbca ,_afcg .EG_ColorTransform );};if _fbca !=""{_eaga =_cbc .ColorRGBFromHex ("\u0023"+_fbca );
 };return _eaga ;};func _egd (_bcb int ,_cab bool )string {_dga :=(_bcb -1)/26+1;_ebca :=byte ((_bcb -1)%26);if _cab {_ebca +=byte (65);}else {_ebca +=byte (97);};_ceec :=_ee .NewBuffer ([]byte {});for _gace :=0;_gace < _dga ;_gace ++{_ceec .Write ([]byte {_ebca });
 };return _ceec .String ();};func (_dgf *convertContext )addCellToTable (_ffcb *_cbc .Table ,_agd *_gg .CT_TableCell ,_eggb *_gg .CT_TablePartStyle ,_bcd ,_ccaf ,_ceb ,_bdde bool )float64 {var _agdb *_cbc .TableCell ;_eaf :=1;if _agd .GridSpanAttr !=nil {_eaf =int (*_agd .GridSpanAttr );
@@ -161,7 +175,7 @@ if _efabb ==nil {return _afdf ;};if _afdf .RotAttr ==nil {_afdf .RotAttr =_efabb
 };if _afdf .Text3DChoice .FlatTx ==nil {_afdf .Text3DChoice .FlatTx =_efabb .Text3DChoice .FlatTx ;};if _afdf .ExtLst ==nil {_afdf .ExtLst =_efabb .ExtLst ;};return _afdf ;};func (_dcce *textboxContext )newWord (){_dcce ._gbf =&word {_dgggb :true ,_dde :_dcce ._bafea ._fge }};
 func (_cgbc *textboxContext )alignParagraphsVertically (_fgga _gg .ST_TextAnchoringType ){if _fgga ==_gg .ST_TextAnchoringTypeT {return ;};_bbed :=0.0;for _ ,_faf :=range _cgbc ._fga {_bbed +=_faf ._fca +_faf ._bgaa +_faf ._aaca ;};var _gbdg float64 ;switch _fgga {case _gg .ST_TextAnchoringTypeCtr :_gbdg =(_cgbc ._fab -_bbed )/2;
 case _gg .ST_TextAnchoringTypeB :_gbdg =_cgbc ._fab -_bbed ;};for _ ,_aaa :=range _cgbc ._fga {_aaa ._eag +=_gbdg ;};};func (_gege *textboxContext )addTextSymbol (_ced *symbol ){_gbaf :=_cbc .New ();_efgf :=_gbaf .NewStyledParagraph ();_efgf .SetMargins (0,0,0,0);
-_fec :=_efgf .Append (_ced ._gaf );_gcdbe :=0.0;if _ced ._dae !=nil {_fec .Style =*_ced ._dae ;if _ced ._dae .CharSpacing !=0{_gcdbe =_ced ._dae .CharSpacing ;};};_ced ._dgbd =_efgf .Height ();_ced ._dfcd =_efgf .Height ()*1.2;if _ced ._aced ==0{_ced ._aced =_efgf .Width ()+_gcdbe ;
+_fec :=_efgf .Append (_ced ._gaf );_gcdbe :=0.0;if _ced ._dae !=nil {_fec .Style =*_ced ._dae ;_gege ._eafe ._fallback .Chunk (_efgf ,_fec );if _ced ._dae .CharSpacing !=0{_gcdbe =_ced ._dae .CharSpacing ;};};_ced ._dgbd =_efgf .Height ();_ced ._dfcd =_efgf .Height ()*1.2;if _ced ._aced ==0{_ced ._aced =_efgf .Width ()+_gcdbe ;
 };if len (_gege ._gbf ._dfec )> 0{_bgcd :=_gege ._gbf ._dfec [len (_gege ._gbf ._dfec )-1]._gaf ;if _gege ._bcgb ._bdb ||_d .IsNoSpaceLanguage (_bgcd )||(_bgcd =="\u0020")!=(_ced ._gaf =="\u0020"){_gege .addCurrentWordToParagraph ();_gege .newWord ();};
 };_gege ._gbf ._dfec =append (_gege ._gbf ._dfec ,_ced );_ced ._ffbe =_gege ._gbf ._bbfd ;_gege ._gbf ._bbfd +=_ced ._aced ;if _ced ._gaf !="\u0020"{_gege ._gbf ._dgggb =false ;};if _gege ._gbf ._bbfd >=_gege ._bcgb ._bff -_gege ._bcgb ._abe {_gege .addCurrentWordToParagraph ();
 _gege .newLine ();_gege .newWord ();};};func (_aceba *textboxContext )alignVertically (_gcc _gg .ST_TextAnchoringType ){_aceba .alignParagraphsVertically (_gcc );_aceba .alignSymbolsVertically ();};func (_cdde *textboxContext )newLine (){if _cdde ._bcgb ==nil {_cdde .newParagraph ();
@@ -192,10 +206,10 @@ _gfac .FillPropertiesChoice .SolidFill .PrstClr =_cfff .PrstClr ;};if _bfcd :=_c
 type textboxContext struct{_eafe *convertContext ;_dagd float64 ;_fab float64 ;_dggc *_cbc .Creator ;_fccc float64 ;_fga []*paragraph ;_bcgb *paragraph ;_bafea *line ;_gbf *word ;_fgcc bool ;};func (_aaf *convertContext )getPhData (_dead *_ce .CT_Shape )(*_gg .CT_Transform2D ,*_gg .CT_TextBodyProperties ,*_gg .CT_TextListStyle ,bool ,bool ,bool ){_ffgc ,_fdfb :=_fcccd (_dead );
 _ccea ,_dabb ,_fagg ,_bdbc ,_dfbc :=_ffab (_aaf ._cgfd .CSld ,_ffgc ,_fdfb );_dcde ,_bcf ,_bceg ,_gdg ,_feea :=_ffab (_aaf ._ccf .CSld ,_ffgc ,_fdfb );if _dcde ==nil {_dcde =_ccea ;};_dgdf ,_cede :=_daa (_bcf ,_dabb );var _fefb ,_aaff ,_cadg bool ;if _bceg ==nil {if _fagg !=nil {_fefb =*_fagg ;
 };}else {_fefb =*_bceg ;};if _feea ==nil {if _dfbc !=nil {_cadg =*_dfbc ;};}else {_cadg =*_feea ;};if _gdg ==nil {if _bdbc !=nil {_aaff =*_bdbc ;};}else {_aaff =*_gdg ;};return _dcde ,_dgdf ,_cede ,_fefb ,_aaff ,_cadg ;};func (_bgdc *convertContext )renderCurrentStateToGoImage ()(_b .Image ,error ){_cced :=_d .MakeTempCreator (_bgdc ._bfcac ,_bgdc ._dcd );
-_cced .NewPage ();for _ ,_bfee :=range _bgdc ._ccdd {if _bfee !=nil {_cced .MoveTo (0,0);_cced .Draw (_bfee );};};_adfb ,_bge :=_d .GetPageFromCreator (_cced );if _bge !=nil {return nil ,_bge ;};return _cfe .NewImageDevice ().Render (_adfb );};type convertContext struct{_dedd *_cbc .Creator ;
+_cced .NewPage ();for _ ,_bfee :=range _bgdc ._ccdd {if _bfee !=nil {_cced .MoveTo (0,0);_cced .Draw (_bfee );};};_adfb ,_bge :=_d .GetPageFromCreator (_cced );if _bge !=nil {return nil ,_bge ;};return _cfe .NewImageDevice ().Render (_adfb );};type convertContext struct{_dedd *_cbc .Creator ;_fallback *_d .Fallback ;
 _gdfe *_d .Rectangle ;_bdeb *_ac .Presentation ;_fade *_ac .Slide ;_cgfd *_ce .SldMaster ;_ccf *_ce .SldLayout ;_dcd float64 ;_bfcac float64 ;_ccdd []_cbc .Drawable ;_fef *background ;_gbde *_gg .CT_TextParagraphProperties ;_eacb *_gg .CT_TextCharacterProperties ;
 _efdd *_gg .CT_TextParagraphProperties ;_cga *_gg .CT_TextCharacterProperties ;_fgd *_gg .CT_TextParagraphProperties ;_gcdb *_gg .CT_TextCharacterProperties ;_ggc []*_gg .CT_TextParagraphProperties ;_aebf []*_gg .CT_TextParagraphProperties ;_cccg []*_gg .CT_TextParagraphProperties ;
-_bgca *_gg .Theme ;_bfe *_gg .CT_ColorMappingOverride ;};func _cge (_ffcbe float64 )float64 {return _ffcbe *_aa .Inch };func _ccdb (_fegd ,_faab *_gg .CT_TableStyleCellStyle )*_gg .CT_TableStyleCellStyle {_bbef :=_gg .NewCT_TableStyleCellStyle ();if _fegd !=nil {*_bbef =*_fegd ;
+_bgca *_gg .Theme ;_bfe *_gg .CT_ColorMappingOverride ;_tags *_d .Tagger ;_shapeTags []*shapeTag ;_slideElem *_de .KDict ;};func _cge (_ffcbe float64 )float64 {return _ffcbe *_aa .Inch };func _ccdb (_fegd ,_faab *_gg .CT_TableStyleCellStyle )*_gg .CT_TableStyleCellStyle {_bbef :=_gg .NewCT_TableStyleCellStyle ();if _fegd !=nil {*_bbef =*_fegd ;
 };if _faab ==nil {return _bbef ;};if _bbef .TcBdr ==nil {_bbef .TcBdr =_faab .TcBdr ;}else {if _bbef .TcBdr .Left ==nil {_bbef .TcBdr .Left =_faab .TcBdr .Left ;};if _bbef .TcBdr .Right ==nil {_bbef .TcBdr .Right =_faab .TcBdr .Right ;};if _bbef .TcBdr .Top ==nil {_bbef .TcBdr .Top =_faab .TcBdr .Top ;
 };if _bbef .TcBdr .Bottom ==nil {_bbef .TcBdr .Bottom =_faab .TcBdr .Bottom ;};if _bbef .TcBdr .InsideH ==nil {_bbef .TcBdr .InsideH =_faab .TcBdr .InsideH ;};if _bbef .TcBdr .InsideV ==nil {_bbef .TcBdr .InsideV =_faab .TcBdr .InsideV ;};};if _bbef .ThemeableFillStyleChoice .Fill ==nil {_bbef .ThemeableFillStyleChoice .Fill =_faab .ThemeableFillStyleChoice .Fill ;
 };if _bbef .ThemeableFillStyleChoice .FillRef ==nil {_bbef .ThemeableFillStyleChoice .FillRef =_faab .ThemeableFillStyleChoice .FillRef ;};return _bbef ;};func (_gdda *textboxContext )addCurrentParagraph (){_gdda ._fccc =_gdda ._bcgb ._eag +_gdda ._bcgb ._fca +_gdda ._bcgb ._bgaa +_gdda ._bcgb ._aaca ;
@@ -225,13 +239,13 @@ return _ddce ;};func _beae (_bccg ,_afab *_gg .CT_TableStyleTextStyle )*_gg .CT_
 };if _feae .IAttr ==_gg .ST_OnOffStyleTypeUnset {_feae .IAttr =_afab .IAttr ;};if _feae .ThemeableFontStylesChoice .Font ==nil {_feae .ThemeableFontStylesChoice .Font =_afab .ThemeableFontStylesChoice .Font ;};if _feae .ThemeableFontStylesChoice .FontRef ==nil {_feae .ThemeableFontStylesChoice .FontRef =_afab .ThemeableFontStylesChoice .FontRef ;
 };if _feae .ScrgbClr ==nil {_feae .ScrgbClr =_afab .ScrgbClr ;};if _feae .SrgbClr ==nil {_feae .SrgbClr =_afab .SrgbClr ;};if _feae .HslClr ==nil {_feae .HslClr =_afab .HslClr ;};if _feae .SysClr ==nil {_feae .SysClr =_afab .SysClr ;};if _feae .SchemeClr ==nil {_feae .SchemeClr =_afab .SchemeClr ;
 };if _feae .PrstClr ==nil {_feae .PrstClr =_afab .PrstClr ;};return _feae ;};func _gga (_dbd int ,_cgba bool )string {_dfcga :=_ee .NewBuffer ([]byte {});for _ ,_dbeb :=range _adda {for {if _dbd < _dbeb ._acae {break ;};_dfcga .WriteString (_dbeb ._gdea );
-_dbd -=_dbeb ._acae ;};};_abd :=_dfcga .String ();if _cgba {_abd =_cb .ToUpper (_abd );};return _abd ;};type background struct{_ebcg _cbc .Color ;_gbg float64 ;_aace *_gg .CT_BlipFillProperties ;};func (_cee *convertContext )drawSlide (){_cee ._dedd .NewPage ();
-for _ ,_cef :=range _cee ._ccdd {if _cef !=nil {_cee ._dedd .MoveTo (0,0);_cee ._dedd .Draw (_cef );};};};func _fcccd (_abf *_ce .CT_Shape )(_ce .ST_PlaceholderType ,*uint32 ){if _ccda :=_abf .NvSpPr ;_ccda !=nil {if _cfga :=_ccda .NvPr ;_cfga !=nil {if _ddgg :=_cfga .Ph ;
+_dbd -=_dbeb ._acae ;};};_abd :=_dfcga .String ();if _cgba {_abd =_cb .ToUpper (_abd );};return _abd ;};type background struct{_ebcg _cbc .Color ;_gbg float64 ;_aace *_gg .CT_BlipFillProperties ;};func (_cee *convertContext )drawSlide (){_cee ._dedd .NewPage ();_cee .tagSlide ();
+for _fcgd ,_cef :=range _cee ._ccdd {if _cef !=nil {_cee ._dedd .MoveTo (0,0);_cee ._dedd .Draw (_cee .markShape (_fcgd ,_cef ));};};};func _fcccd (_abf *_ce .CT_Shape )(_ce .ST_PlaceholderType ,*uint32 ){if _ccda :=_abf .NvSpPr ;_ccda !=nil {if _cfga :=_ccda .NvPr ;_cfga !=nil {if _ddgg :=_cfga .Ph ;
 _ddgg !=nil {return _ddgg .TypeAttr ,_ddgg .IdxAttr ;};};};return _ce .ST_PlaceholderTypeUnset ,nil ;};func (_eege *convertContext )getColorFromSolidFill (_bebg *_gg .CT_SolidColorFillProperties )(_cbc .Color ,float64 ){if _bebg ==nil {return nil ,1;};
 var _gbbe string ;_aecf :=1.0;if _agge :=_bebg .SrgbClr ;_agge !=nil {_gbbe =_agge .ValAttr ;_aecf =_d .GetOpacityFromColorTransform (_agge .EG_ColorTransform );}else if _daef :=_bebg .SchemeClr ;_daef !=nil {_gbbe =_d .GetColorStringFromDmlColor (_eege ._fade .GetColorBySchemeColor (_daef .ValAttr ));
 _gbbe =_d .AdjustColor (_gbbe ,_daef .EG_ColorTransform );_aecf =_d .GetOpacityFromColorTransform (_daef .EG_ColorTransform );};if _gbbe !=""{_aaed :=_cbc .ColorRGBFromHex ("\u0023"+_gbbe );return _aaed ,_aecf ;};return nil ,1;};func (_dafg *convertContext )getConnectors (_eddf *_ce .CT_Connector )[]_cbc .Drawable {_ccd ,_ ,_ ,_ ,_ ,_ ,_ :=_dafg .getShapesFromSpPr (_eddf .SpPr ,_eddf .Style ,false ,0.0,0.0);
 return _ccd ;};type symbolStyle struct{_gbda *string ;_cbge *float64 ;_acdgc *string ;_geae *bool ;_bbe *bool ;_ddd *bool ;_abg *bool ;_dbaf *bool ;};type symbol struct{_gaf string ;_ffbe float64 ;_dfcd float64 ;_dgbd float64 ;_gcg float64 ;_aced float64 ;
-_dae *_cbc .TextStyle ;_fddf string ;_dafa bool ;_fgb bool ;_ffce bool ;};func (_ec *convertContext )makePdfBlockFromChart (_aac *_f .Chart ,_ed ,_fg float64 )(*_cbc .Block ,error ){_bg :=_aac .CT_RelId .IdAttr ;_ea :=_ec ._fade .GetChartSpaceByRelId (_bg );
+_dae *_cbc .TextStyle ;_fddf string ;_dafa bool ;_fgb bool ;_ffce bool ;_rtl bool ;};func (_ec *convertContext )makePdfBlockFromChart (_aac *_f .Chart ,_ed ,_fg float64 )(*_cbc .Block ,error ){_bg :=_aac .CT_RelId .IdAttr ;_ea :=_ec ._fade .GetChartSpaceByRelId (_bg );
 if _ea ==nil {return nil ,_e .New ("\u004e\u006f\u0020\u0063\u0068\u0061\u0072\u0074\u0073\u0070\u0061\u0063\u0065");};var _ef *_gg .Theme ;_ga :=_ec ._bdeb .Themes ();if len (_ga )> 0{_ef =_ga [0];};return _d .MakeBlockFromChartSpace (_ea ,_ed ,_fg ,_ef );
 };
 
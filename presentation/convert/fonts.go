@@ -0,0 +1,53 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	"github.com/unidoc/unioffice/v2/internal/convertutils"
+	"github.com/unidoc/unioffice/v2/presentation"
+	"github.com/unidoc/unipdf/v4/creator"
+)
+
+// FontFallback configures the fallback fonts of a conversion, see Options.
+type FontFallback = convertutils.FontFallback
+
+// Script is a writing system with its own chain of fallback fonts.
+type Script = convertutils.Script
+
+// Script constants.
+const (
+	ScriptLatin      = convertutils.ScriptLatin
+	ScriptCJK        = convertutils.ScriptCJK
+	ScriptArabic     = convertutils.ScriptArabic
+	ScriptHebrew     = convertutils.ScriptHebrew
+	ScriptDevanagari = convertutils.ScriptDevanagari
+	ScriptEmoji      = convertutils.ScriptEmoji
+)
+
+// FontSubstitution is a font replaced during conversion, see
+// ConvertToPdfWithSubstitutions.
+type FontSubstitution = convertutils.FontSubstitution
+
+// ConvertToPdfWithSubstitutions converts the presentation like
+// ConvertToPdfWithOptions and also returns the fonts replaced during the
+// conversion because they were not registered or had no glyphs for some of
+// the characters.
+func ConvertToPdfWithSubstitutions(pr *presentation.Presentation, opts *Options) (*creator.Creator, []FontSubstitution) {
+	fb := newFallback(opts)
+	return convertPresentation(pr, opts, fb), fb.Substitutions()
+}
+
+// newFallback returns the font fallback state of a conversion with opts.
+func newFallback(opts *Options) *convertutils.Fallback {
+	if opts == nil {
+		return convertutils.NewFallback(nil, "", nil)
+	}
+	return convertutils.NewFallback(opts.FontFallback, "", nil)
+}
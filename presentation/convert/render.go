@@ -0,0 +1,32 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	"image"
+	"io"
+
+	"github.com/unidoc/unioffice/v2/internal/convertutils"
+	"github.com/unidoc/unioffice/v2/presentation"
+)
+
+// ConvertToImages renders the slides of the presentation to images with the
+// resolution dpi in dots per inch, 96 if dpi is not positive. The layout is
+// the same as that of ConvertToPdfWithOptions. Slides are given by their
+// one-based numbers, all slides are rendered if none are given.
+func ConvertToImages(pr *presentation.Presentation, opts *Options, dpi float64, slides ...int) ([]image.Image, error) {
+	return convertutils.RenderPages(ConvertToPdfWithOptions(pr, opts), dpi, slides...)
+}
+
+// ConvertToPNG renders the slide with the one-based number slide to w as a PNG
+// image with the resolution dpi, see ConvertToImages.
+func ConvertToPNG(pr *presentation.Presentation, opts *Options, dpi float64, slide int, w io.Writer) error {
+	return convertutils.RenderPNG(ConvertToPdfWithOptions(pr, opts), dpi, slide, w)
+}
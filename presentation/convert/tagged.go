@@ -0,0 +1,195 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/internal/convertutils"
+	"github.com/unidoc/unioffice/v2/presentation"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/pml"
+	"github.com/unidoc/unipdf/v4/core"
+	"github.com/unidoc/unipdf/v4/creator"
+	"github.com/unidoc/unipdf/v4/model"
+)
+
+// PdfAConformance is a PDF/A conformance level of the output, see Options.
+type PdfAConformance = convertutils.PdfAConformance
+
+// PdfAConformance constants.
+const (
+	PdfANone = convertutils.PdfANone
+	PdfA2B   = convertutils.PdfA2B
+	PdfA3B   = convertutils.PdfA3B
+)
+
+// shapeTag is the structure element of the drawings of a slide shape.
+type shapeTag struct {
+	_type model.StructureType
+	_alt  string
+	_text string
+	_elem *model.KDict
+}
+
+// newTagger returns a tagger if tagged output was requested.
+func newTagger(opts *Options) *convertutils.Tagger {
+	if opts != nil && opts.Tagged {
+		return convertutils.NewTagger()
+	}
+	return nil
+}
+
+// setPdfOutput applies the PDF/A and tagged output options to the creator.
+func setPdfOutput(c *creator.Creator, pr *presentation.Presentation, opts *Options, t *convertutils.Tagger) {
+	if opts == nil || (opts.PdfA == PdfANone && !opts.Tagged) {
+		return
+	}
+	convertutils.SetPdfOutput(c, opts.PdfA, t, pr.CoreProperties, "")
+}
+
+// tagShapes sets the structure element of the drawings added to the slide
+// since index from. Shapes of the layout are left untagged and drawn as
+// artifacts.
+func (c *convertContext) tagShapes(from int, tag *shapeTag, layout bool) {
+	if c._tags == nil || layout || tag == nil {
+		return
+	}
+	for len(c._shapeTags) < len(c._ccdd) {
+		c._shapeTags = append(c._shapeTags, nil)
+	}
+	for i := from; i < len(c._ccdd); i++ {
+		c._shapeTags[i] = tag
+	}
+}
+
+// shapeTagOf returns the structure element of a shape with text, a heading
+// for titles and a paragraph otherwise, or a figure for shapes without text
+// with alternative text. Other shapes are decorative.
+func shapeTagOf(sp *pml.CT_Shape) *shapeTag {
+	text := []string{}
+	if sp.TxBody != nil {
+		for _, p := range sp.TxBody.P {
+			line := ""
+			for _, r := range p.EG_TextRun {
+				if r.TextRunChoice.R != nil {
+					line += r.TextRunChoice.R.T
+				}
+			}
+			if strings.TrimSpace(line) != "" {
+				text = append(text, line)
+			}
+		}
+	}
+	var pr *dml.CT_NonVisualDrawingProps
+	var ph *pml.CT_Placeholder
+	if nv := sp.NvSpPr; nv != nil {
+		pr = nv.CNvPr
+		if nv.NvPr != nil {
+			ph = nv.NvPr.Ph
+		}
+	}
+	if len(text) == 0 {
+		if alt := descr(pr); alt != "" {
+			return &shapeTag{_type: model.StructureTypeFigure, _alt: alt}
+		}
+		return nil
+	}
+	tag := &shapeTag{_type: model.StructureTypeParagraph}
+	if ph != nil {
+		switch ph.TypeAttr {
+		case pml.ST_PlaceholderTypeTitle, pml.ST_PlaceholderTypeCtrTitle:
+			tag._type = model.StructureTypeHeading1
+		case pml.ST_PlaceholderTypeSubTitle:
+			tag._type = model.StructureTypeHeading2
+		}
+	}
+	return tag
+}
+
+// pictureTagOf returns a figure for a picture.
+func pictureTagOf(pic *pml.CT_Picture) *shapeTag {
+	tag := &shapeTag{_type: model.StructureTypeFigure}
+	if pic.NvPicPr != nil {
+		tag._alt = descr(pic.NvPicPr.CNvPr)
+	}
+	return tag
+}
+
+// frameTagOf returns a figure for a chart or a table for a table, which is
+// drawn as a whole and described by the text of its cells.
+func frameTagOf(gf *pml.CT_GraphicalObjectFrame, tbl *dml.Tbl) *shapeTag {
+	tag := &shapeTag{_type: model.StructureTypeFigure}
+	if gf.NvGraphicFramePr != nil {
+		tag._alt = descr(gf.NvGraphicFramePr.CNvPr)
+	}
+	if tbl != nil {
+		tag._type = model.StructureTypeTable
+		rows := []string{}
+		for _, tr := range tbl.Tr {
+			cells := []string{}
+			for _, tc := range tr.Tc {
+				if tc.TxBody == nil {
+					continue
+				}
+				for _, p := range tc.TxBody.P {
+					for _, r := range p.EG_TextRun {
+						if r.TextRunChoice.R != nil {
+							cells = append(cells, r.TextRunChoice.R.T)
+						}
+					}
+				}
+			}
+			rows = append(rows, strings.Join(cells, " "))
+		}
+		tag._text = strings.TrimSpace(strings.Join(rows, "\n"))
+	}
+	return tag
+}
+
+// descr returns the alternative text of a drawing.
+func descr(pr *dml.CT_NonVisualDrawingProps) string {
+	if pr == nil || pr.DescrAttr == nil {
+		return ""
+	}
+	return strings.TrimSpace(*pr.DescrAttr)
+}
+
+// tagSlide starts tagging the page of the slide.
+func (c *convertContext) tagSlide() {
+	if c._tags == nil {
+		return
+	}
+	c._tags.NewPage()
+	c._slideElem = c._tags.Element(nil, model.StructureTypeSection)
+}
+
+// markShape returns the drawing of index i of the slide marked as content of
+// its structure element, or as an artifact.
+func (c *convertContext) markShape(i int, d creator.Drawable) creator.Drawable {
+	if c._tags == nil {
+		return d
+	}
+	var e *model.KDict
+	if i < len(c._shapeTags) && c._shapeTags[i] != nil {
+		tag := c._shapeTags[i]
+		if tag._elem == nil {
+			tag._elem = c._tags.Element(c._slideElem, tag._type)
+			if tag._alt != "" {
+				tag._elem.Alt = core.MakeString(tag._alt)
+			}
+			if tag._text != "" {
+				tag._elem.ActualText = core.MakeString(tag._text)
+			}
+		}
+		e = tag._elem
+	}
+	return c._tags.MarkDrawable(e, d, c._bfcac, c._dcd)
+}
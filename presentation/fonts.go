@@ -0,0 +1,105 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package presentation
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/internal/fontembed"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/pml"
+)
+
+// FontEmbedStyle is the style of an embedded font.
+type FontEmbedStyle byte
+
+// FontEmbedStyle constants.
+const (
+	FontEmbedRegular FontEmbedStyle = iota
+	FontEmbedBold
+	FontEmbedItalic
+	FontEmbedBoldItalic
+)
+
+// EmbedFont embeds the TrueType font ttf as the given style of the font family
+// name, so the presentation is displayed with it where the font isn't
+// installed. If subset is set, only the glyphs of the characters used on the
+// slides are embedded.
+func (p *Presentation) EmbedFont(name string, style FontEmbedStyle, ttf []byte, subset bool) error {
+	if subset {
+		var err error
+		if ttf, err = fontembed.Subset(ttf, p.ExtractText().Text()); err != nil {
+			return err
+		}
+	}
+	data, err := fontembed.EOT(ttf)
+	if err != nil {
+		return err
+	}
+	n := 1
+	for p.HasExtraFile(fmt.Sprintf("ppt/fonts/font%d.fntdata", n)) {
+		n++
+	}
+	target := fmt.Sprintf("fonts/font%d.fntdata", n)
+	if err := p.AddExtraFileFromBytes("ppt/"+target, data); err != nil {
+		return err
+	}
+	id := &pml.CT_EmbeddedFontDataId{IdAttr: p._acab.AddRelationship(target, unioffice.FontEmbeddingType).ID()}
+	p.ContentTypes.EnsureDefault("fntdata", "application/x-fontdata")
+
+	if p._ecec.EmbeddedFontLst == nil {
+		p._ecec.EmbeddedFontLst = pml.NewCT_EmbeddedFontList()
+	}
+	var entry *pml.CT_EmbeddedFontListEntry
+	for _, ef := range p._ecec.EmbeddedFontLst.EmbeddedFont {
+		if ef.Font != nil && ef.Font.TypefaceAttr == name {
+			entry = ef
+		}
+	}
+	if entry == nil {
+		entry = pml.NewCT_EmbeddedFontListEntry()
+		entry.Font = dml.NewCT_TextFont()
+		entry.Font.TypefaceAttr = name
+		p._ecec.EmbeddedFontLst.EmbeddedFont = append(p._ecec.EmbeddedFontLst.EmbeddedFont, entry)
+	}
+	var old *pml.CT_EmbeddedFontDataId
+	switch style {
+	case FontEmbedBold:
+		old, entry.Bold = entry.Bold, id
+	case FontEmbedItalic:
+		old, entry.Italic = entry.Italic, id
+	case FontEmbedBoldItalic:
+		old, entry.BoldItalic = entry.BoldItalic, id
+	default:
+		old, entry.Regular = entry.Regular, id
+	}
+	if old != nil {
+		// drop the replaced font with its relationship
+		if r := p._acab.GetByRelId(old.IdAttr); r.X() != nil {
+			target := r.Target()
+			if strings.HasPrefix(target, "/") {
+				target = target[1:]
+			} else {
+				target = path.Join("ppt", target)
+			}
+			p.RemoveExtraFile(target)
+			p._acab.Remove(r)
+		}
+	}
+
+	p._ecec.EmbedTrueTypeFontsAttr = unioffice.Bool(true)
+	if subset {
+		p._ecec.SaveSubsetFontsAttr = unioffice.Bool(true)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package presentation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+
+	"github.com/unidoc/unioffice/v2"
+)
+
+func TestEmbedFont(t *testing.T) {
+	p := New()
+	if err := p.EmbedFont("Go", FontEmbedRegular, goregular.TTF, false); err != nil {
+		t.Fatalf("EmbedFont: %s", err)
+	}
+	if err := p.EmbedFont("Go", FontEmbedBold, goregular.TTF, false); err != nil {
+		t.Fatalf("EmbedFont: %s", err)
+	}
+	// replacing the regular style drops the font embedded first
+	if err := p.EmbedFont("Go", FontEmbedRegular, goregular.TTF, false); err != nil {
+		t.Fatalf("EmbedFont: %s", err)
+	}
+
+	lst := p.X().EmbeddedFontLst
+	if lst == nil || len(lst.EmbeddedFont) != 1 {
+		t.Fatalf("expected one embedded font entry, got %v", lst)
+	}
+	entry := lst.EmbeddedFont[0]
+	if entry.Font.TypefaceAttr != "Go" || entry.Regular == nil || entry.Bold == nil || entry.Italic != nil {
+		t.Errorf("expected the regular and bold styles of Go, got %+v", entry)
+	}
+	if p.X().EmbedTrueTypeFontsAttr == nil || !*p.X().EmbedTrueTypeFontsAttr {
+		t.Errorf("expected embedTrueTypeFonts to be set")
+	}
+
+	fonts := []string{}
+	for _, ef := range p.ExtraFiles {
+		if strings.HasPrefix(ef.ZipPath, "ppt/fonts/") {
+			fonts = append(fonts, ef.ZipPath)
+		}
+	}
+	if len(fonts) != 2 {
+		t.Errorf("expected the files of the two embedded styles, got %q", fonts)
+	}
+	targets := map[string]string{}
+	for _, rel := range p._acab.X().Relationship {
+		if rel.TypeAttr == unioffice.FontEmbeddingType {
+			targets[rel.IdAttr] = "ppt/" + rel.TargetAttr
+		}
+	}
+	if len(targets) != 2 {
+		t.Errorf("expected two font relationships, got %d", len(targets))
+	}
+	for _, id := range []string{entry.Regular.IdAttr, entry.Bold.IdAttr} {
+		target, ok := targets[id]
+		if !ok {
+			t.Errorf("expected a font relationship %s", id)
+			continue
+		}
+		data, err := p.ExtraFileBytes(target)
+		if err != nil {
+			t.Errorf("expected the font file %s: %s", target, err)
+			continue
+		}
+		// EOT headers end with the font data
+		if !bytes.HasSuffix(data, goregular.TTF) {
+			t.Errorf("expected %s to hold the font", target)
+		}
+	}
+}
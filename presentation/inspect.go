@@ -0,0 +1,92 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package presentation
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/pml"
+)
+
+// commentAuthorsPath is the path of the comment authors part.
+const commentAuthorsPath = "ppt/commentAuthors.xml"
+
+// Inspect reports the personal information and hidden data in the
+// presentation without changing it, see RemovePersonalInformation.
+func (p *Presentation) Inspect() ([]common.InspectionFinding, error) {
+	return p.inspect(common.NewInspector(false))
+}
+
+// RemovePersonalInformation removes the given kinds of personal information
+// and hidden data, or all kinds if none are given, and returns the findings.
+// It covers the document properties, comment authors, printer settings, the
+// thumbnail and file paths of external links. Hidden slides are only
+// reported. Comment authors are replaced by "Author".
+func (p *Presentation) RemovePersonalInformation(kinds ...common.InspectionKind) ([]common.InspectionFinding, error) {
+	return p.inspect(common.NewInspector(true, kinds...))
+}
+
+func (p *Presentation) inspect(i *common.Inspector) ([]common.InspectionFinding, error) {
+	if err := i.DocBase(&p.DocBase); err != nil {
+		return i.Findings, err
+	}
+	i.Relationships("presentation", p._acab)
+	for n, s := range p._ggd {
+		loc := fmt.Sprintf("slide %d", n+1)
+		if s.ShowAttr != nil && !*s.ShowAttr {
+			i.Found(common.InspectionHiddenContent, loc, "hidden slide")
+		}
+		if n < len(p._gag) {
+			i.Relationships(loc, p._gag[n])
+		}
+	}
+	if err := p.inspectCommentAuthors(i); err != nil {
+		return i.Findings, err
+	}
+	return i.Findings, nil
+}
+
+// inspectCommentAuthors inspects the names and initials of comment authors,
+// which are kept as read in the comment authors part.
+func (p *Presentation) inspectCommentAuthors(i *common.Inspector) error {
+	if !p.HasExtraFile(commentAuthorsPath) {
+		return nil
+	}
+	data, err := p.ExtraFileBytes(commentAuthorsPath)
+	if err != nil {
+		return err
+	}
+	lst := pml.NewCmAuthorLst()
+	if err := xml.Unmarshal(data, lst); err != nil {
+		return err
+	}
+	changed := false
+	for _, a := range lst.CmAuthor {
+		if i.Found(common.InspectionCommentAuthors, commentAuthorsPath, a.NameAttr) {
+			a.NameAttr = "Author"
+			a.InitialsAttr = "A"
+			// the extensions hold the user ID of the author
+			a.ExtLst = nil
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	buf := bytes.Buffer{}
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(lst); err != nil {
+		return err
+	}
+	return p.AddExtraFileFromBytes(commentAuthorsPath, buf.Bytes())
+}
@@ -0,0 +1,82 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+/*
+Package odp provides conversion between OpenDocument presentations (.odp)
+and presentation.Presentation.
+
+Slides with their text shapes, simple geometric shapes and pictures, the
+slide size and the document metadata are converted in both directions.
+Placeholder positions inherited from the slide layout are resolved when
+writing.
+
+Example:
+
+	ppt, err := presentation.Open("deck.pptx")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer ppt.Close()
+	if err := odp.SaveToFile(ppt, "deck.odp"); err != nil {
+		log.Fatal(err)
+	}
+*/
+package odp
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/unidoc/unioffice/v2/internal/odf"
+	"github.com/unidoc/unioffice/v2/presentation"
+)
+
+// Open opens and reads an OpenDocument presentation into a new presentation.
+func Open(filename string) (*presentation.Presentation, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Read(f, fi.Size())
+}
+
+// Read reads an OpenDocument presentation from r and builds a new
+// presentation.
+func Read(r io.ReaderAt, size int64) (*presentation.Presentation, error) {
+	pkg, err := odf.ReadPackage(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return newReader(pkg).read()
+}
+
+// Write writes the presentation p to w as an OpenDocument presentation.
+func Write(w io.Writer, p *presentation.Presentation) error {
+	pkg, err := newWriter(p).write()
+	if err != nil {
+		return err
+	}
+	return pkg.Write(w)
+}
+
+// SaveToFile writes the presentation p to an OpenDocument presentation at
+// path.
+func SaveToFile(p *presentation.Presentation, path string) error {
+	buf := bytes.Buffer{}
+	if err := Write(&buf, p); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
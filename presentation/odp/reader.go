@@ -0,0 +1,514 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package odp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/unidoc/unioffice/v2/color"
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/drawing"
+	"github.com/unidoc/unioffice/v2/internal/odf"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/presentation"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+)
+
+type reader struct {
+	pkg    *odf.Package
+	prs    *presentation.Presentation
+	styles *odf.Styles
+}
+
+// geometry is the position and size of a shape on the slide.
+type geometry struct {
+	x, y, width, height measurement.Distance
+	// rotation is clockwise in degrees as used by DrawingML
+	rotation float64
+}
+
+type charProps struct {
+	bold      bool
+	italic    bool
+	underline bool
+	strike    bool
+	size      measurement.Distance
+	font      string
+	color     string
+}
+
+// segment is a run of text with the same properties, a nil props marks a
+// line break.
+type segment struct {
+	text  string
+	props *charProps
+}
+
+func newReader(pkg *odf.Package) *reader {
+	return &reader{pkg: pkg}
+}
+
+func (r *reader) read() (*presentation.Presentation, error) {
+	if r.pkg.MimeType != odf.MimeTypePresentation && r.pkg.MimeType != odf.MimeTypePresentation+"-template" {
+		return nil, fmt.Errorf("unsupported mime type %s", r.pkg.MimeType)
+	}
+	content, err := r.pkg.Part("content.xml")
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		return nil, errors.New("missing content.xml")
+	}
+	styles, err := r.pkg.Part("styles.xml")
+	if err != nil {
+		return nil, err
+	}
+	meta, err := r.pkg.Part("meta.xml")
+	if err != nil {
+		return nil, err
+	}
+	r.styles = odf.ParseStyles(styles, content)
+	r.prs = presentation.New()
+	odf.ParseMeta(meta).ApplyCore(r.prs.CoreProperties)
+
+	body := content.Child(odf.NSOffice, "body").Child(odf.NSOffice, "presentation")
+	if body == nil {
+		return nil, errors.New("missing office:presentation")
+	}
+	pages := body.ChildrenNamed(odf.NSDraw, "page")
+	if len(pages) > 0 {
+		r.slideSize(pages[0].AttrValue(odf.NSDraw, "master-page-name"))
+	}
+	for _, page := range pages {
+		slide := r.prs.AddSlide()
+		r.shapes(page, slide)
+	}
+	return r.prs, nil
+}
+
+// slideSize sets the slide size from the page layout of a master page.
+func (r *reader) slideSize(master string) {
+	for _, mp := range r.styles.MasterPages {
+		if mp.AttrValue(odf.NSStyle, "name") != master {
+			continue
+		}
+		layout := r.styles.PageLayouts[mp.AttrValue(odf.NSStyle, "page-layout-name")]
+		if layout == nil {
+			return
+		}
+		props := layout.Props["page-layout-properties"]
+		width, okW := odf.ParseLength(props["fo:page-width"])
+		height, okH := odf.ParseLength(props["fo:page-height"])
+		if okW && okH {
+			ss := r.prs.SlideSize()
+			ss.SetSize(presentation.NewSlideScreenSizeWithValue(int32(measurement.ToEMU(float64(width))),
+				int32(measurement.ToEMU(float64(height)))))
+		}
+		return
+	}
+}
+
+func (r *reader) shapes(n *odf.Node, slide presentation.Slide) {
+	for _, c := range n.Children {
+		switch {
+		case c.Is(odf.NSDraw, "frame"):
+			r.frame(c, slide)
+		case c.Is(odf.NSDraw, "custom-shape"), c.Is(odf.NSDraw, "rect"), c.Is(odf.NSDraw, "ellipse"):
+			r.shape(c, slide)
+		case c.Is(odf.NSDraw, "g"), c.Is(odf.NSDraw, "a"):
+			r.shapes(c, slide)
+		}
+	}
+}
+
+func parseGeometry(n *odf.Node) (geometry, bool) {
+	g := geometry{}
+	var ok bool
+	if g.width, ok = odf.ParseLength(n.AttrValue(odf.NSSVG, "width")); !ok {
+		return g, false
+	}
+	if g.height, ok = odf.ParseLength(n.AttrValue(odf.NSSVG, "height")); !ok {
+		return g, false
+	}
+	g.x, _ = odf.ParseLength(n.AttrValue(odf.NSSVG, "x"))
+	g.y, _ = odf.ParseLength(n.AttrValue(odf.NSSVG, "y"))
+	if tr := n.AttrValue(odf.NSDraw, "transform"); tr != "" {
+		g.transform(tr)
+	}
+	return g, true
+}
+
+// transform applies a draw:transform of the form "rotate(a) translate(x y)"
+// where the translation is the position of the rotated top left corner.
+func (g *geometry) transform(s string) {
+	angle := 0.0
+	var tx, ty measurement.Distance
+	translated := false
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		open, end := strings.Index(s, "("), strings.Index(s, ")")
+		if open < 0 || end < open {
+			return
+		}
+		name := strings.TrimSpace(s[:open])
+		args := strings.FieldsFunc(s[open+1:end], func(c rune) bool { return c == ',' || unicode.IsSpace(c) })
+		s = s[end+1:]
+		switch {
+		case name == "rotate" && len(args) == 1:
+			angle, _ = strconv.ParseFloat(args[0], 64)
+		case name == "translate" && len(args) == 2:
+			tx, _ = odf.ParseLength(args[0])
+			ty, _ = odf.ParseLength(args[1])
+			translated = true
+		}
+	}
+	if !translated {
+		return
+	}
+	// the center is the rotated half diagonal away from the origin
+	sin, cos := math.Sincos(angle)
+	hw, hh := float64(g.width)/2, float64(g.height)/2
+	cx := float64(tx) + hw*cos + hh*sin
+	cy := float64(ty) - hw*sin + hh*cos
+	g.x = measurement.Distance(cx - hw)
+	g.y = measurement.Distance(cy - hh)
+	g.rotation = -angle * 180 / math.Pi
+}
+
+func (g geometry) apply(sp drawing.ShapeProperties) {
+	sp.SetPosition(g.x, g.y)
+	sp.SetSize(g.width, g.height)
+	if g.rotation != 0 {
+		rot := int32(math.Round(math.Mod(g.rotation+360, 360) * 60000))
+		sp.X().Xfrm.RotAttr = &rot
+	}
+}
+
+func (r *reader) frame(n *odf.Node, slide presentation.Slide) {
+	g, ok := parseGeometry(n)
+	if !ok {
+		return
+	}
+	if n.AttrValue(odf.NSPresentation, "placeholder") == "true" {
+		// empty placeholders only hold the prompt text of the layout
+		return
+	}
+	for _, c := range n.Children {
+		switch {
+		case c.Is(odf.NSDraw, "image"):
+			if r.image(c, n, g, slide) {
+				return
+			}
+		case c.Is(odf.NSDraw, "text-box"):
+			tb := slide.AddTextBox()
+			g.apply(tb.Properties())
+			r.text(c, tb, r.shapeProps(n))
+			return
+		}
+	}
+}
+
+func (r *reader) image(img, frame *odf.Node, g geometry, slide presentation.Slide) bool {
+	data, ok := r.pkg.File(strings.TrimPrefix(img.AttrValue(odf.NSXLink, "href"), "./"))
+	if !ok {
+		return false
+	}
+	im, err := common.ImageFromBytes(data)
+	if err != nil {
+		// vector images have a raster replacement in a following
+		// draw:image element
+		return false
+	}
+	ref, err := r.prs.AddImage(im)
+	if err != nil {
+		return false
+	}
+	pic := slide.AddImage(ref)
+	g.apply(pic.Properties())
+	alt := frame.Child(odf.NSSVG, "desc").TextContent()
+	if alt == "" {
+		alt = frame.Child(odf.NSSVG, "title").TextContent()
+	}
+	shapes := slide.X().CSld.SpTree.GroupShapeChoice
+	if last := shapes[len(shapes)-1]; alt != "" && last.Pic != nil && last.Pic.NvPicPr != nil && last.Pic.NvPicPr.CNvPr != nil {
+		last.Pic.NvPicPr.CNvPr.DescrAttr = &alt
+	}
+	return true
+}
+
+// shapeProps returns the text properties of the graphic or presentation
+// style of a shape, they are the base of its text.
+func (r *reader) shapeProps(n *odf.Node) charProps {
+	cp := charProps{size: 18 * measurement.Point}
+	for _, style := range []string{n.AttrValue(odf.NSPresentation, "style-name"), n.AttrValue(odf.NSDraw, "style-name")} {
+		if style == "" {
+			continue
+		}
+		cp = r.charProps(cp, func(attr string) string { return r.styles.Lookup(style, "text-properties", attr) })
+	}
+	return cp
+}
+
+func (r *reader) shape(n *odf.Node, slide presentation.Slide) {
+	g, ok := parseGeometry(n)
+	if !ok {
+		return
+	}
+	tb := slide.AddTextBox()
+	g.apply(tb.Properties())
+	// shapes are not text boxes in DrawingML
+	if nv := tb.X().NvSpPr; nv != nil && nv.CNvSpPr != nil {
+		nv.CNvSpPr.TxBoxAttr = nil
+	}
+	shape := dml.ST_ShapeTypeRect
+	switch {
+	case n.Is(odf.NSDraw, "ellipse"):
+		shape = dml.ST_ShapeTypeEllipse
+	case n.Is(odf.NSDraw, "custom-shape"):
+		shape = shapeType(n.Child(odf.NSDraw, "enhanced-geometry").AttrValue(odf.NSDraw, "type"))
+	}
+	tb.Properties().SetGeometry(shape)
+
+	style := n.AttrValue(odf.NSDraw, "style-name")
+	prop := func(attr string) string { return r.styles.Lookup(style, "graphic-properties", attr) }
+	if prop("draw:fill") == "solid" {
+		if c, ok := odf.ParseColor(prop("draw:fill-color")); ok {
+			tb.Properties().SetSolidFill(color.FromHex(c))
+		}
+	} else {
+		tb.Properties().SetNoFill()
+	}
+	if prop("draw:stroke") != "none" && prop("draw:stroke") != "" {
+		c, ok := odf.ParseColor(prop("svg:stroke-color"))
+		if !ok {
+			c = "000000"
+		}
+		ln := tb.Properties().LineProperties()
+		ln.SetSolidFill(color.FromHex(c))
+		if w, ok := odf.ParseLength(prop("svg:stroke-width")); ok && w > 0 {
+			ln.SetWidth(w)
+		}
+	}
+	switch prop("draw:textarea-vertical-align") {
+	case "middle":
+		tb.SetTextAnchor(dml.ST_TextAnchoringTypeCtr)
+	case "bottom":
+		tb.SetTextAnchor(dml.ST_TextAnchoringTypeB)
+	}
+	r.text(n, tb, r.shapeProps(n))
+}
+
+// shapeType maps predefined custom shape types to DrawingML preset shapes.
+func shapeType(t string) dml.ST_ShapeType {
+	switch t {
+	case "round-rectangle":
+		return dml.ST_ShapeTypeRoundRect
+	case "ellipse", "circle":
+		return dml.ST_ShapeTypeEllipse
+	case "isosceles-triangle":
+		return dml.ST_ShapeTypeTriangle
+	case "right-triangle":
+		return dml.ST_ShapeTypeRtTriangle
+	case "diamond":
+		return dml.ST_ShapeTypeDiamond
+	case "parallelogram":
+		return dml.ST_ShapeTypeParallelogram
+	case "trapezoid":
+		return dml.ST_ShapeTypeTrapezoid
+	case "pentagon":
+		return dml.ST_ShapeTypePentagon
+	case "hexagon":
+		return dml.ST_ShapeTypeHexagon
+	case "octagon":
+		return dml.ST_ShapeTypeOctagon
+	case "star5":
+		return dml.ST_ShapeTypeStar5
+	case "right-arrow":
+		return dml.ST_ShapeTypeRightArrow
+	case "left-arrow":
+		return dml.ST_ShapeTypeLeftArrow
+	case "up-arrow":
+		return dml.ST_ShapeTypeUpArrow
+	case "down-arrow":
+		return dml.ST_ShapeTypeDownArrow
+	}
+	return dml.ST_ShapeTypeRect
+}
+
+// text converts the paragraphs and lists below n into the text box.
+func (r *reader) text(n *odf.Node, tb presentation.TextBox, base charProps) {
+	var walk func(n *odf.Node, level int32, bullet bool)
+	walk = func(n *odf.Node, level int32, bullet bool) {
+		for _, c := range n.Children {
+			switch {
+			case c.Is(odf.NSText, "p"), c.Is(odf.NSText, "h"):
+				r.paragraph(c, tb.AddParagraph(), base, level, bullet)
+				// only the first paragraph of a list item has a bullet
+				bullet = false
+			case c.Is(odf.NSText, "list"):
+				walk(c, level+1, true)
+			case c.Is(odf.NSText, "list-item"), c.Is(odf.NSText, "list-header"):
+				walk(c, level, c.Is(odf.NSText, "list-item"))
+			}
+		}
+	}
+	walk(n, -1, false)
+}
+
+func (r *reader) paragraph(n *odf.Node, p drawing.Paragraph, base charProps, level int32, bullet bool) {
+	style := n.AttrValue(odf.NSText, "style-name")
+	pp := p.Properties()
+	switch r.styles.Lookup(style, "paragraph-properties", "fo:text-align") {
+	case "center":
+		pp.SetAlign(dml.ST_TextAlignTypeCtr)
+	case "end", "right":
+		pp.SetAlign(dml.ST_TextAlignTypeR)
+	case "justify":
+		pp.SetAlign(dml.ST_TextAlignTypeJust)
+	}
+	if level > 0 {
+		pp.SetLevel(level)
+	}
+	if bullet {
+		pp.SetBulletChar("•")
+	}
+	props := r.charProps(base, func(attr string) string { return r.styles.Lookup(style, "text-properties", attr) })
+	var segs []segment
+	lastSpace := true
+	var walk func(n *odf.Node, props charProps)
+	walk = func(n *odf.Node, props charProps) {
+		add := func(s string) {
+			if len(segs) > 0 && segs[len(segs)-1].props != nil && *segs[len(segs)-1].props == props {
+				segs[len(segs)-1].text += s
+				return
+			}
+			cp := props
+			segs = append(segs, segment{text: s, props: &cp})
+		}
+		for _, c := range n.Children {
+			switch {
+			case !c.IsElement():
+				b := strings.Builder{}
+				for _, ch := range c.Text {
+					if unicode.IsSpace(ch) {
+						if !lastSpace {
+							b.WriteByte(' ')
+							lastSpace = true
+						}
+						continue
+					}
+					b.WriteRune(ch)
+					lastSpace = false
+				}
+				if b.Len() > 0 {
+					add(b.String())
+				}
+			case c.Is(odf.NSText, "s"):
+				cnt := 1
+				if v, err := strconv.Atoi(c.AttrValue(odf.NSText, "c")); err == nil && v > 0 {
+					cnt = v
+				}
+				add(strings.Repeat(" ", cnt))
+				lastSpace = false
+			case c.Is(odf.NSText, "tab"):
+				add("\t")
+				lastSpace = true
+			case c.Is(odf.NSText, "line-break"):
+				segs = append(segs, segment{})
+				lastSpace = true
+			case c.Is(odf.NSText, "span"):
+				style := c.AttrValue(odf.NSText, "style-name")
+				walk(c, r.charProps(props, func(attr string) string {
+					return r.styles.Lookup(style, "text-properties", attr)
+				}))
+			case c.Is(odf.NSOffice, "annotation"):
+			default:
+				walk(c, props)
+			}
+		}
+	}
+	walk(n, props)
+	for _, s := range segs {
+		if s.props == nil {
+			p.AddBreak()
+			continue
+		}
+		run := p.AddRun()
+		run.SetText(s.text)
+		applyCharProps(run, *s.props)
+	}
+}
+
+func (r *reader) charProps(base charProps, prop func(string) string) charProps {
+	cp := base
+	if v := prop("fo:font-weight"); v != "" {
+		n, err := strconv.Atoi(v)
+		cp.bold = v == "bold" || (err == nil && n >= 600)
+	}
+	if v := prop("fo:font-style"); v != "" {
+		cp.italic = v == "italic" || v == "oblique"
+	}
+	if v := prop("style:text-underline-style"); v != "" {
+		cp.underline = v != "none"
+	}
+	if v := prop("style:text-line-through-style"); v != "" {
+		cp.strike = v != "none"
+	}
+	if v := prop("fo:font-size"); v != "" {
+		if d, ok := odf.ParseLength(v); ok {
+			cp.size = d
+		} else if f, ok := odf.ParsePercent(v); ok {
+			cp.size = measurement.Distance(float64(base.size) * f)
+		}
+	}
+	if v := prop("fo:font-family"); v != "" {
+		cp.font = strings.Trim(v, "'\"")
+	} else if v := prop("style:font-name"); v != "" {
+		cp.font = v
+	}
+	if v := prop("fo:color"); v != "" {
+		if c, ok := odf.ParseColor(v); ok {
+			cp.color = c
+		}
+	}
+	return cp
+}
+
+func applyCharProps(run drawing.Run, cp charProps) {
+	rp := run.Properties()
+	// italic, underline and strike through have no setters
+	rpr := run.X().TextRunChoice.R.RPr
+	if cp.bold {
+		rp.SetBold(true)
+	}
+	if cp.italic {
+		italic := true
+		rpr.IAttr = &italic
+	}
+	if cp.underline {
+		rpr.UAttr = dml.ST_TextUnderlineTypeSng
+	}
+	if cp.strike {
+		rpr.StrikeAttr = dml.ST_TextStrikeTypeSngStrike
+	}
+	if cp.size > 0 {
+		rp.SetSize(cp.size)
+	}
+	if cp.font != "" {
+		rp.SetFont(cp.font)
+	}
+	if cp.color != "" {
+		rp.SetSolidFill(color.FromHex(cp.color))
+	}
+}
@@ -0,0 +1,497 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package odp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/internal/odf"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/presentation"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/pml"
+)
+
+// emuPerInch converts the EMU coordinates of DrawingML.
+const emuPerInch = 914400
+
+// transform maps the coordinates of a shape inside groups to slide
+// coordinates.
+type transform struct {
+	offX, offY     int64
+	chOffX, chOffY int64
+	scaleX, scaleY float64
+	parent         *transform
+}
+
+func (t *transform) apply(x, y, cx, cy int64) (int64, int64, int64, int64) {
+	for ; t != nil; t = t.parent {
+		x = t.offX + int64(float64(x-t.chOffX)*t.scaleX)
+		y = t.offY + int64(float64(y-t.chOffY)*t.scaleY)
+		cx = int64(float64(cx) * t.scaleX)
+		cy = int64(float64(cy) * t.scaleY)
+	}
+	return x, y, cx, cy
+}
+
+// writer converts a presentation to an OpenDocument presentation package.
+type writer struct {
+	prs    *presentation.Presentation
+	pkg    *odf.Package
+	x      *odf.Writer
+	styles *odf.StyleSet
+	slide  *presentation.Slide
+	shapes int
+}
+
+func newWriter(p *presentation.Presentation) *writer {
+	return &writer{prs: p, styles: odf.NewStyleSet()}
+}
+
+func (w *writer) write() (*odf.Package, error) {
+	w.pkg = odf.NewPackage(odf.MimeTypePresentation)
+	w.x = odf.NewFragmentWriter()
+	pageStyle := w.styles.Add("dp", "drawing-page", "", odf.Props{Element: "style:drawing-page-properties",
+		Attrs: []string{"presentation:background-visible", "true", "presentation:background-objects-visible", "true"}})
+	slides := w.prs.Slides()
+	for i := range slides {
+		w.slide = &slides[i]
+		w.x.Start("draw:page", "draw:name", fmt.Sprintf("page%d", i+1), "draw:style-name", pageStyle,
+			"draw:master-page-name", "Default")
+		if sp := slides[i].X().CSld; sp != nil && sp.SpTree != nil {
+			w.shapeTree(sp.SpTree.GroupShapeChoice, nil)
+		}
+		w.x.End()
+	}
+
+	content := odf.NewWriter()
+	content.StartRoot("office:document-content")
+	content.Start("office:automatic-styles")
+	content.Raw(w.styles.Bytes())
+	content.End()
+	content.Start("office:body")
+	content.Start("office:presentation")
+	content.Raw(w.x.Bytes())
+	w.pkg.Add("content.xml", content.Bytes(), "text/xml")
+	w.pkg.Add("styles.xml", w.stylesPart(), "text/xml")
+	w.pkg.Add("meta.xml", odf.MetaFromCore(w.prs.CoreProperties).Bytes(), "text/xml")
+	return w.pkg, nil
+}
+
+// stylesPart builds styles.xml with the page layout of the slide size and
+// the master page.
+func (w *writer) stylesPart() []byte {
+	width, height := int64(10*emuPerInch), int64(7.5*emuPerInch)
+	if sz := w.prs.X().SldSz; sz != nil {
+		width, height = int64(sz.CxAttr), int64(sz.CyAttr)
+	}
+	orientation := "landscape"
+	if height > width {
+		orientation = "portrait"
+	}
+	x := odf.NewWriter()
+	x.StartRoot("office:document-styles")
+	x.Start("office:styles")
+	x.Start("style:default-style", "style:family", "graphic")
+	x.Empty("style:text-properties", "fo:font-size", "18pt", "style:font-name", "Calibri", "fo:font-family", "Calibri")
+	x.End()
+	x.End()
+	x.Start("office:automatic-styles")
+	x.Start("style:page-layout", "style:name", "PM1")
+	x.Empty("style:page-layout-properties", "fo:margin-top", "0in", "fo:margin-bottom", "0in", "fo:margin-left", "0in",
+		"fo:margin-right", "0in", "fo:page-width", emu(width), "fo:page-height", emu(height),
+		"style:print-orientation", orientation)
+	x.End()
+	x.Start("style:style", "style:name", "dpm1", "style:family", "drawing-page")
+	x.Empty("style:drawing-page-properties", "draw:fill", "none")
+	x.End()
+	x.End()
+	x.Start("office:master-styles")
+	x.Empty("style:master-page", "style:name", "Default", "style:page-layout-name", "PM1", "draw:style-name", "dpm1")
+	return x.Bytes()
+}
+
+func emu(v int64) string {
+	return odf.Length(measurement.Distance(measurement.FromEMU(v)))
+}
+
+func (w *writer) shapeTree(shapes []*pml.CT_GroupShapeChoice, t *transform) {
+	for _, gs := range shapes {
+		switch {
+		case gs.Sp != nil:
+			w.shape(gs.Sp, t)
+		case gs.Pic != nil:
+			w.picture(gs.Pic, t)
+		case gs.GrpSp != nil:
+			w.group(gs.GrpSp, t)
+		}
+	}
+}
+
+func (w *writer) group(g *pml.CT_GroupShape, parent *transform) {
+	t := &transform{scaleX: 1, scaleY: 1, parent: parent}
+	if g.GrpSpPr != nil && g.GrpSpPr.Xfrm != nil {
+		xf := g.GrpSpPr.Xfrm
+		if xf.Off != nil {
+			t.offX, t.offY = coord(xf.Off.XAttr), coord(xf.Off.YAttr)
+		}
+		if xf.ChOff != nil {
+			t.chOffX, t.chOffY = coord(xf.ChOff.XAttr), coord(xf.ChOff.YAttr)
+		}
+		if xf.Ext != nil && xf.ChExt != nil && xf.ChExt.CxAttr > 0 && xf.ChExt.CyAttr > 0 {
+			t.scaleX = float64(xf.Ext.CxAttr) / float64(xf.ChExt.CxAttr)
+			t.scaleY = float64(xf.Ext.CyAttr) / float64(xf.ChExt.CyAttr)
+		}
+	}
+	w.x.Start("draw:g")
+	w.shapeTree(g.GroupShapeChoice, t)
+	w.x.End()
+}
+
+func coord(c dml.ST_Coordinate) int64 {
+	if c.ST_CoordinateUnqualified != nil {
+		return *c.ST_CoordinateUnqualified
+	}
+	if c.ST_UniversalMeasure != nil {
+		if d, ok := odf.ParseLength(*c.ST_UniversalMeasure); ok {
+			return int64(d / measurement.Inch * emuPerInch)
+		}
+	}
+	return 0
+}
+
+// position returns the geometry attributes of a shape, placeholders without
+// their own transform use the position of the layout placeholder.
+func (w *writer) position(spPr *dml.CT_ShapeProperties, ph *pml.CT_Placeholder, t *transform) []string {
+	var xfrm *dml.CT_Transform2D
+	if spPr != nil {
+		xfrm = spPr.Xfrm
+	}
+	if (xfrm == nil || xfrm.Off == nil) && ph != nil {
+		xfrm = w.layoutTransform(ph)
+	}
+	if xfrm == nil || xfrm.Off == nil || xfrm.Ext == nil {
+		return nil
+	}
+	x, y, cx, cy := t.apply(coord(xfrm.Off.XAttr), coord(xfrm.Off.YAttr), xfrm.Ext.CxAttr, xfrm.Ext.CyAttr)
+	attrs := []string{"svg:x", emu(x), "svg:y", emu(y), "svg:width", emu(cx), "svg:height", emu(cy)}
+	if xfrm.RotAttr != nil && *xfrm.RotAttr != 0 {
+		// DrawingML rotates clockwise around the center in 60000ths of a
+		// degree, ODF counter clockwise around the origin
+		deg := float64(*xfrm.RotAttr) / 60000
+		attrs = []string{"svg:width", emu(cx), "svg:height", emu(cy), "draw:transform",
+			fmt.Sprintf("rotate(%s) translate(%s %s)", strconv.FormatFloat(-deg*math.Pi/180, 'f', 6, 64),
+				rotatedOrigin(x, y, cx, cy, deg))}
+	}
+	return attrs
+}
+
+func (w *writer) layoutTransform(ph *pml.CT_Placeholder) *dml.CT_Transform2D {
+	layout := w.slide.GetSlideLayout()
+	if layout == nil || layout.CSld == nil || layout.CSld.SpTree == nil {
+		return nil
+	}
+	for _, gs := range layout.CSld.SpTree.GroupShapeChoice {
+		if gs.Sp == nil || gs.Sp.NvSpPr == nil || gs.Sp.NvSpPr.NvPr == nil || gs.Sp.NvSpPr.NvPr.Ph == nil {
+			continue
+		}
+		lph := gs.Sp.NvSpPr.NvPr.Ph
+		match := lph.TypeAttr == ph.TypeAttr
+		if ph.IdxAttr != nil && lph.IdxAttr != nil {
+			match = *ph.IdxAttr == *lph.IdxAttr
+		}
+		if match && gs.Sp.SpPr != nil {
+			return gs.Sp.SpPr.Xfrm
+		}
+	}
+	return nil
+}
+
+// rotatedOrigin returns the translation for a shape rotated around its
+// center.
+func rotatedOrigin(x, y, cx, cy int64, deg float64) string {
+	w, h := float64(measurement.FromEMU(cx)), float64(measurement.FromEMU(cy))
+	cxp, cyp := float64(measurement.FromEMU(x))+w/2, float64(measurement.FromEMU(y))+h/2
+	sin, cos := math.Sincos(-deg * math.Pi / 180)
+	// position of the top left corner after rotating around the center
+	ox := cxp - (w/2*cos + h/2*sin)
+	oy := cyp - (-w/2*sin + h/2*cos)
+	return odf.Length(measurement.Distance(ox)) + " " + odf.Length(measurement.Distance(oy))
+}
+
+func placeholder(sp *pml.CT_Shape) *pml.CT_Placeholder {
+	if sp.NvSpPr == nil || sp.NvSpPr.NvPr == nil {
+		return nil
+	}
+	return sp.NvSpPr.NvPr.Ph
+}
+
+func (w *writer) shape(sp *pml.CT_Shape, t *transform) {
+	ph := placeholder(sp)
+	pos := w.position(sp.SpPr, ph, t)
+	if pos == nil {
+		return
+	}
+	w.shapes++
+	name := fmt.Sprintf("Shape %d", w.shapes)
+	if sp.NvSpPr != nil && sp.NvSpPr.CNvPr != nil && sp.NvSpPr.CNvPr.NameAttr != "" {
+		name = sp.NvSpPr.CNvPr.NameAttr
+	}
+	defSize := "18pt"
+	class := ""
+	if ph != nil {
+		defSize = "24pt"
+		switch ph.TypeAttr {
+		case pml.ST_PlaceholderTypeTitle, pml.ST_PlaceholderTypeCtrTitle:
+			defSize, class = "44pt", "title"
+		case pml.ST_PlaceholderTypeSubTitle:
+			class = "subtitle"
+		case pml.ST_PlaceholderTypeDt, pml.ST_PlaceholderTypeFtr, pml.ST_PlaceholderTypeSldNum:
+			defSize = "12pt"
+		}
+	}
+	geometry := ""
+	if sp.SpPr != nil && sp.SpPr.GeometryChoice != nil && sp.SpPr.GeometryChoice.PrstGeom != nil {
+		geometry = presetGeometry(sp.SpPr.GeometryChoice.PrstGeom.PrstAttr)
+	}
+	fill, stroke := shapeFill(sp.SpPr)
+	style := w.styles.Add("gr", "graphic", "", odf.Props{Element: "style:graphic-properties", Attrs: append(append(
+		[]string{"draw:auto-grow-height", "false", "draw:textarea-vertical-align", anchor(sp.TxBody)}, fill...), stroke...)})
+
+	attrs := append([]string{"draw:name", name, "draw:style-name", style}, pos...)
+	plain := geometry == "rectangle" && len(fill) == 2 && len(stroke) == 2
+	if plain || geometry == "" {
+		// text boxes and placeholders are frames holding a text box
+		if class != "" {
+			attrs = append(attrs, "presentation:class", class)
+		}
+		w.x.Start("draw:frame", attrs...)
+		w.x.Start("draw:text-box")
+		w.textBody(sp.TxBody, defSize)
+		w.x.End()
+		w.x.End()
+		return
+	}
+	w.x.Start("draw:custom-shape", attrs...)
+	w.textBody(sp.TxBody, defSize)
+	w.x.Empty("draw:enhanced-geometry", "svg:viewBox", "0 0 21600 21600", "draw:type", geometry)
+	w.x.End()
+}
+
+// presetGeometry maps DrawingML preset shapes to the predefined custom
+// shape types of OpenDocument.
+func presetGeometry(t dml.ST_ShapeType) string {
+	switch t {
+	case dml.ST_ShapeTypeRect:
+		return "rectangle"
+	case dml.ST_ShapeTypeRoundRect:
+		return "round-rectangle"
+	case dml.ST_ShapeTypeEllipse:
+		return "ellipse"
+	case dml.ST_ShapeTypeTriangle:
+		return "isosceles-triangle"
+	case dml.ST_ShapeTypeRtTriangle:
+		return "right-triangle"
+	case dml.ST_ShapeTypeDiamond:
+		return "diamond"
+	case dml.ST_ShapeTypeParallelogram:
+		return "parallelogram"
+	case dml.ST_ShapeTypeTrapezoid:
+		return "trapezoid"
+	case dml.ST_ShapeTypePentagon:
+		return "pentagon"
+	case dml.ST_ShapeTypeHexagon:
+		return "hexagon"
+	case dml.ST_ShapeTypeOctagon:
+		return "octagon"
+	case dml.ST_ShapeTypeStar5:
+		return "star5"
+	case dml.ST_ShapeTypeRightArrow:
+		return "right-arrow"
+	case dml.ST_ShapeTypeLeftArrow:
+		return "left-arrow"
+	case dml.ST_ShapeTypeUpArrow:
+		return "up-arrow"
+	case dml.ST_ShapeTypeDownArrow:
+		return "down-arrow"
+	}
+	return "rectangle"
+}
+
+// shapeFill returns the fill and stroke attributes of a shape, only solid
+// RGB colors are converted.
+func shapeFill(spPr *dml.CT_ShapeProperties) ([]string, []string) {
+	fill := []string{"draw:fill", "none"}
+	stroke := []string{"draw:stroke", "none"}
+	if spPr == nil {
+		return fill, stroke
+	}
+	if fc := spPr.FillPropertiesChoice; fc != nil && fc.SolidFill != nil && fc.SolidFill.SrgbClr != nil {
+		fill = []string{"draw:fill", "solid", "draw:fill-color", odf.HexColor(fc.SolidFill.SrgbClr.ValAttr)}
+	}
+	if ln := spPr.Ln; ln != nil && ln.LineFillPropertiesChoice != nil && ln.LineFillPropertiesChoice.SolidFill != nil &&
+		ln.LineFillPropertiesChoice.SolidFill.SrgbClr != nil {
+		stroke = []string{"draw:stroke", "solid", "svg:stroke-color", odf.HexColor(ln.LineFillPropertiesChoice.SolidFill.SrgbClr.ValAttr)}
+		if ln.WAttr != nil {
+			stroke = append(stroke, "svg:stroke-width", emu(int64(*ln.WAttr)))
+		}
+	}
+	return fill, stroke
+}
+
+func anchor(tb *dml.CT_TextBody) string {
+	if tb == nil || tb.BodyPr == nil {
+		return "top"
+	}
+	switch tb.BodyPr.AnchorAttr {
+	case dml.ST_TextAnchoringTypeCtr:
+		return "middle"
+	case dml.ST_TextAnchoringTypeB:
+		return "bottom"
+	}
+	return "top"
+}
+
+func (w *writer) textBody(tb *dml.CT_TextBody, defSize string) {
+	if tb == nil {
+		return
+	}
+	for _, p := range tb.P {
+		var para []string
+		if ppr := p.PPr; ppr != nil {
+			switch ppr.AlgnAttr {
+			case dml.ST_TextAlignTypeCtr:
+				para = append(para, "fo:text-align", "center")
+			case dml.ST_TextAlignTypeR:
+				para = append(para, "fo:text-align", "end")
+			case dml.ST_TextAlignTypeJust:
+				para = append(para, "fo:text-align", "justify")
+			}
+			if ppr.MarLAttr != nil {
+				para = append(para, "fo:margin-left", emu(int64(*ppr.MarLAttr)))
+			} else if ppr.LvlAttr != nil && *ppr.LvlAttr > 0 {
+				para = append(para, "fo:margin-left", odf.Length(measurement.Distance(*ppr.LvlAttr)*0.5*measurement.Inch))
+			}
+		}
+		style := w.styles.Add("P", "paragraph", "", odf.Props{Element: "style:paragraph-properties", Attrs: para},
+			odf.Props{Element: "style:text-properties", Attrs: []string{"fo:font-size", defSize}})
+		w.x.Start("text:p", "text:style-name", style)
+		for _, tr := range p.EG_TextRun {
+			rc := tr.TextRunChoice
+			if rc == nil {
+				continue
+			}
+			switch {
+			case rc.R != nil:
+				w.span(rc.R.RPr, func() { w.x.WriteText(rc.R.T) })
+			case rc.Br != nil:
+				w.x.Empty("text:line-break")
+			case rc.Fld != nil:
+				fld := rc.Fld
+				w.span(fld.RPr, func() {
+					if fld.TypeAttr != nil && *fld.TypeAttr == "slidenum" {
+						w.x.Start("text:page-number")
+						if fld.T != nil {
+							w.x.Text(*fld.T)
+						}
+						w.x.End()
+					} else if fld.T != nil {
+						w.x.WriteText(*fld.T)
+					}
+				})
+			}
+		}
+		w.x.End()
+	}
+}
+
+func (w *writer) span(rpr *dml.CT_TextCharacterProperties, content func()) {
+	style := w.styles.Add("T", "text", "", odf.Props{Element: "style:text-properties", Attrs: textProps(rpr)})
+	if style == "" {
+		content()
+		return
+	}
+	w.x.Start("text:span", "text:style-name", style)
+	content()
+	w.x.End()
+}
+
+func textProps(rpr *dml.CT_TextCharacterProperties) []string {
+	if rpr == nil {
+		return nil
+	}
+	var attrs []string
+	if rpr.BAttr != nil && *rpr.BAttr {
+		attrs = append(attrs, "fo:font-weight", "bold")
+	}
+	if rpr.IAttr != nil && *rpr.IAttr {
+		attrs = append(attrs, "fo:font-style", "italic")
+	}
+	if rpr.UAttr != dml.ST_TextUnderlineTypeUnset && rpr.UAttr != dml.ST_TextUnderlineTypeNone {
+		attrs = append(attrs, "style:text-underline-style", "solid", "style:text-underline-width", "auto",
+			"style:text-underline-color", "font-color")
+	}
+	if rpr.StrikeAttr == dml.ST_TextStrikeTypeSngStrike || rpr.StrikeAttr == dml.ST_TextStrikeTypeDblStrike {
+		attrs = append(attrs, "style:text-line-through-style", "solid")
+	}
+	if rpr.SzAttr != nil {
+		attrs = append(attrs, "fo:font-size", strconv.FormatFloat(float64(*rpr.SzAttr)/100, 'f', -1, 64)+"pt")
+	}
+	if rpr.Latin != nil && rpr.Latin.TypefaceAttr != "" && !strings.HasPrefix(rpr.Latin.TypefaceAttr, "+") {
+		attrs = append(attrs, "fo:font-family", rpr.Latin.TypefaceAttr)
+	}
+	if fc := rpr.FillPropertiesChoice; fc != nil && fc.SolidFill != nil && fc.SolidFill.SrgbClr != nil {
+		attrs = append(attrs, "fo:color", odf.HexColor(fc.SolidFill.SrgbClr.ValAttr))
+	}
+	return attrs
+}
+
+func (w *writer) picture(pic *pml.CT_Picture, t *transform) {
+	if pic.BlipFill == nil || pic.BlipFill.Blip == nil || pic.BlipFill.Blip.EmbedAttr == nil {
+		return
+	}
+	pos := w.position(pic.SpPr, nil, t)
+	if pos == nil {
+		return
+	}
+	ref, ok := w.slide.GetImageByRelID(*pic.BlipFill.Blip.EmbedAttr)
+	if !ok {
+		return
+	}
+	data, err := odf.ImageData(ref.Data(), ref.Path())
+	if err != nil {
+		return
+	}
+	href := w.pkg.AddPicture(data, ref.Format())
+	w.shapes++
+	name := fmt.Sprintf("Image %d", w.shapes)
+	var descr string
+	if pic.NvPicPr != nil && pic.NvPicPr.CNvPr != nil {
+		if pic.NvPicPr.CNvPr.NameAttr != "" {
+			name = pic.NvPicPr.CNvPr.NameAttr
+		}
+		if pic.NvPicPr.CNvPr.DescrAttr != nil {
+			descr = *pic.NvPicPr.CNvPr.DescrAttr
+		}
+	}
+	style := w.styles.Add("gr", "graphic", "", odf.Props{Element: "style:graphic-properties",
+		Attrs: []string{"draw:fill", "none", "draw:stroke", "none"}})
+	w.x.Start("draw:frame", append([]string{"draw:name", name, "draw:style-name", style}, pos...)...)
+	w.x.Empty("draw:image", "xlink:href", href, "xlink:type", "simple", "xlink:show", "embed", "xlink:actuate", "onLoad")
+	if descr != "" {
+		w.x.Start("svg:desc")
+		w.x.Text(descr)
+		w.x.End()
+	}
+	w.x.End()
+}
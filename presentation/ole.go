@@ -0,0 +1,194 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package presentation
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/unidoc/unioffice/v2"
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/drawing"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/pml"
+)
+
+const oleURI = "http://schemas.openxmlformats.org/presentationml/2006/ole"
+
+// OLEObject is a graphic frame of a slide displaying an embedded object.
+type OLEObject struct {
+	_frame *pml.CT_GraphicalObjectFrame
+	_obj   *pml.OleObj
+}
+
+// X returns the inner wrapped XML type.
+func (o OLEObject) X() *pml.CT_GraphicalObjectFrame { return o._frame }
+
+// ProgID returns the ProgID of the embedded object.
+func (o OLEObject) ProgID() string {
+	if o._obj.ProgIdAttr == nil {
+		return ""
+	}
+	return *o._obj.ProgIdAttr
+}
+
+// RelID returns the ID of the relationship from the slide to the embedded
+// object.
+func (o OLEObject) RelID() string {
+	if o._obj.IdAttr == nil {
+		return ""
+	}
+	return *o._obj.IdAttr
+}
+
+// SetPosition sets the position of the object on the slide.
+func (o OLEObject) SetPosition(x, y measurement.Distance) {
+	o._frame.Xfrm.Off = dml.NewCT_Point2D()
+	o._frame.Xfrm.Off.XAttr.ST_CoordinateUnqualified = unioffice.Int64(int64(x / measurement.EMU))
+	o._frame.Xfrm.Off.YAttr.ST_CoordinateUnqualified = unioffice.Int64(int64(y / measurement.EMU))
+	if o._obj.Pic != nil {
+		drawing.MakeShapeProperties(o._obj.Pic.SpPr).SetPosition(x, y)
+	}
+}
+
+// SetSize sets the displayed size of the object.
+func (o OLEObject) SetSize(w, h measurement.Distance) {
+	o._frame.Xfrm.Ext = dml.NewCT_PositiveSize2D()
+	o._frame.Xfrm.Ext.CxAttr = int64(w / measurement.EMU)
+	o._frame.Xfrm.Ext.CyAttr = int64(h / measurement.EMU)
+	if o._obj.Pic != nil {
+		drawing.MakeShapeProperties(o._obj.Pic.SpPr).SetSize(w, h)
+	}
+}
+
+// AddEmbeddedObject embeds the file data named fileName in the presentation
+// and displays it on the slide as its preview image or as an icon, placed at
+// the top left corner at the size of the image. Office files are embedded as
+// packages, other files as OLE packages, see
+// common.DocBase.AddEmbeddedObject. The embedded objects of a presentation
+// are returned by EmbeddedObjects.
+func (s Slide) AddEmbeddedObject(fileName string, data []byte, opts common.EmbedOptions) (OLEObject, common.EmbeddedObject, error) {
+	p := s._aeg
+	rels, err := s.rels()
+	if err != nil {
+		return OLEObject{}, common.EmbeddedObject{}, err
+	}
+	obj, err := p.AddEmbeddedObject("ppt", fileName, data, opts.AsPackage)
+	if err != nil {
+		return OLEObject{}, common.EmbeddedObject{}, err
+	}
+	img := opts.Preview
+	if img == nil {
+		icon, err := common.EmbeddingIcon(fileName)
+		if err != nil {
+			return OLEObject{}, common.EmbeddedObject{}, err
+		}
+		img = &icon
+	}
+	iref, err := p.AddImage(*img)
+	if err != nil {
+		return OLEObject{}, common.EmbeddedObject{}, err
+	}
+	id := s.nextShapeID()
+
+	// the preview picture moves into the object
+	spTree := s._fdcg.CSld.SpTree
+	pic := s.AddImage(iref)._aae
+	spTree.GroupShapeChoice = spTree.GroupShapeChoice[:len(spTree.GroupShapeChoice)-1]
+	pic.NvPicPr.CNvPr.IdAttr = 0
+	pic.NvPicPr.CNvPr.NameAttr = ""
+
+	ole := pml.NewOleObj()
+	ole.ProgIdAttr = unioffice.String(obj.ProgID)
+	ole.NameAttr = unioffice.String(path.Base(obj.FileName))
+	ole.IdAttr = unioffice.String(rels.AddRelationship("../embeddings/"+path.Base(obj.ZipPath), obj.RelationshipType()).ID())
+	if opts.ShowAsIcon {
+		ole.ShowAsIconAttr = unioffice.Bool(true)
+	}
+	ole.OleObjectChoice.Embed = pml.NewCT_OleObjectEmbed()
+	ole.Pic = pic
+
+	frame := pml.NewCT_GraphicalObjectFrame()
+	frame.NvGraphicFramePr.CNvPr.IdAttr = id
+	frame.NvGraphicFramePr.CNvPr.NameAttr = fmt.Sprintf("Object %d", id)
+	frame.Graphic.GraphicData = dml.NewCT_GraphicalObjectData()
+	frame.Graphic.GraphicData.UriAttr = oleURI
+	frame.Graphic.GraphicData.Any = append(frame.Graphic.GraphicData.Any, ole)
+	gsc := pml.NewCT_GroupShapeChoice()
+	gsc.GraphicFrame = frame
+	spTree.GroupShapeChoice = append(spTree.GroupShapeChoice, gsc)
+
+	o := OLEObject{frame, ole}
+	size := img.Size
+	w := measurement.Distance(size.X) * measurement.Pixel72
+	h := measurement.Distance(size.Y) * measurement.Pixel72
+	o.SetPosition(0, 0)
+	o.SetSize(w, h)
+	ole.ImgWAttr = unioffice.Int32(int32(w / measurement.EMU))
+	ole.ImgHAttr = unioffice.Int32(int32(h / measurement.EMU))
+	return o, obj, nil
+}
+
+// OLEObjects returns the embedded objects displayed on the slide.
+func (s Slide) OLEObjects() []OLEObject {
+	objs := []OLEObject{}
+	for _, c := range s._fdcg.CSld.SpTree.GroupShapeChoice {
+		gf := c.GraphicFrame
+		if gf == nil || gf.Graphic == nil || gf.Graphic.GraphicData == nil || gf.Graphic.GraphicData.UriAttr != oleURI {
+			continue
+		}
+		for _, a := range gf.Graphic.GraphicData.Any {
+			if ole, ok := a.(*pml.OleObj); ok {
+				objs = append(objs, OLEObject{gf, ole})
+			}
+		}
+	}
+	return objs
+}
+
+// rels returns the relationships of the slide.
+func (s Slide) rels() (common.Relationships, error) {
+	for i, sl := range s._aeg.Slides() {
+		if sl._fdcg == s._fdcg {
+			return s._aeg._gag[i], nil
+		}
+	}
+	return common.Relationships{}, errors.New("slide not found in presentation")
+}
+
+// nextShapeID returns an ID not used by the shapes on the top level of the
+// slide.
+func (s Slide) nextShapeID() uint32 {
+	id := uint32(1)
+	if g := s._fdcg.CSld.SpTree.NvGrpSpPr; g != nil && g.CNvPr != nil {
+		id = g.CNvPr.IdAttr + 1
+	}
+	for _, c := range s._fdcg.CSld.SpTree.GroupShapeChoice {
+		var pr *dml.CT_NonVisualDrawingProps
+		switch {
+		case c.Sp != nil && c.Sp.NvSpPr != nil:
+			pr = c.Sp.NvSpPr.CNvPr
+		case c.Pic != nil && c.Pic.NvPicPr != nil:
+			pr = c.Pic.NvPicPr.CNvPr
+		case c.GraphicFrame != nil && c.GraphicFrame.NvGraphicFramePr != nil:
+			pr = c.GraphicFrame.NvGraphicFramePr.CNvPr
+		case c.CxnSp != nil && c.CxnSp.NvCxnSpPr != nil:
+			pr = c.CxnSp.NvCxnSpPr.CNvPr
+		case c.GrpSp != nil && c.GrpSp.NvGrpSpPr != nil:
+			pr = c.GrpSp.NvGrpSpPr.CNvPr
+		}
+		if pr != nil && pr.IdAttr >= id {
+			id = pr.IdAttr + 1
+		}
+	}
+	return id
+}
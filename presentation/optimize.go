@@ -0,0 +1,59 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package presentation
+
+import (
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/pml"
+)
+
+// OptimizeImages reduces the size of the images of the presentation as
+// described for common.OptimizeImages. Pictures on slides, layouts and masters
+// are downsampled to their displayed size.
+func (p *Presentation) OptimizeImages(opts common.ImageOptimizeOptions) (common.ImageOptimizeResult, error) {
+	rels := []common.Relationships{p._acab}
+	rels = append(rels, p._gag...)
+	rels = append(rels, p._aggd...)
+	rels = append(rels, p._daea...)
+	placements := []common.ImagePlacement{}
+	add := func(csld *pml.CT_CommonSlideData, r common.Relationships) {
+		if csld != nil && csld.SpTree != nil {
+			placements = picturePlacements(placements, csld.SpTree.GroupShapeChoice, r)
+		}
+	}
+	for i, s := range p._ggd {
+		add(s.CSld, p._gag[i])
+	}
+	for i, m := range p._gad {
+		add(m.CSld, p._aggd[i])
+	}
+	for i, l := range p._abd {
+		add(l.CSld, p._daea[i])
+	}
+	return common.OptimizeImages(&p.DocBase, rels, placements, opts)
+}
+
+func picturePlacements(placements []common.ImagePlacement, shapes []*pml.CT_GroupShapeChoice, rels common.Relationships) []common.ImagePlacement {
+	for _, s := range shapes {
+		if s.GrpSp != nil {
+			placements = picturePlacements(placements, s.GrpSp.GroupShapeChoice, rels)
+		}
+		pic := s.Pic
+		if pic == nil || pic.BlipFill == nil || pic.BlipFill.Blip == nil || pic.BlipFill.Blip.EmbedAttr == nil {
+			continue
+		}
+		pl := common.ImagePlacement{Rels: rels, RelID: *pic.BlipFill.Blip.EmbedAttr, BlipFill: pic.BlipFill}
+		if pic.SpPr != nil && pic.SpPr.Xfrm != nil && pic.SpPr.Xfrm.Ext != nil {
+			pl.Width, pl.Height = pic.SpPr.Xfrm.Ext.CxAttr, pic.SpPr.Xfrm.Ext.CyAttr
+		}
+		placements = append(placements, pl)
+	}
+	return placements
+}
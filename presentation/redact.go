@@ -0,0 +1,101 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package presentation
+
+import (
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	"github.com/unidoc/unioffice/v2/schema/soo/pml"
+)
+
+// Redact removes or masks the text matching opts in the shapes and tables of
+// the slides, layouts and masters, the names and alternative text of shapes,
+// pictures and graphics, notes, comments and their authors, the document
+// properties and custom XML parts. Matches may span runs. It returns the
+// number of matches redacted.
+func (p *Presentation) Redact(opts common.RedactOptions) (int, error) {
+	r, err := common.NewRedactor(opts)
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range p._ggd {
+		redactSlideData(r, s.CSld)
+	}
+	for _, l := range p._abd {
+		redactSlideData(r, l.CSld)
+	}
+	for _, m := range p._gad {
+		redactSlideData(r, m.CSld)
+	}
+	for _, x := range p._fag {
+		r.XSDAny(x)
+	}
+	// notes, comments and comment authors are kept as they were read
+	if err := r.ExtraFiles(&p.DocBase, func(zipPath string) bool {
+		return strings.HasPrefix(zipPath, "ppt/notesSlides/") || strings.HasPrefix(zipPath, "ppt/comments/") ||
+			zipPath == "ppt/commentAuthors.xml" || strings.HasPrefix(zipPath, "ppt/authors")
+	}); err != nil {
+		return r.Count, err
+	}
+	if err := r.DocBase(&p.DocBase); err != nil {
+		return r.Count, err
+	}
+	return r.Count, nil
+}
+
+func redactSlideData(r *common.Redactor, csld *pml.CT_CommonSlideData) {
+	if csld == nil || csld.SpTree == nil {
+		return
+	}
+	r.StringPtr(csld.NameAttr)
+	redactShapes(r, csld.SpTree.GroupShapeChoice)
+}
+
+func redactShapes(r *common.Redactor, shapes []*pml.CT_GroupShapeChoice) {
+	for _, s := range shapes {
+		switch {
+		case s.Sp != nil:
+			if s.Sp.NvSpPr != nil {
+				r.DrawingProps(s.Sp.NvSpPr.CNvPr)
+			}
+			r.TextBody(s.Sp.TxBody)
+		case s.GrpSp != nil:
+			if s.GrpSp.NvGrpSpPr != nil {
+				r.DrawingProps(s.GrpSp.NvGrpSpPr.CNvPr)
+			}
+			redactShapes(r, s.GrpSp.GroupShapeChoice)
+		case s.GraphicFrame != nil:
+			if s.GraphicFrame.NvGraphicFramePr != nil {
+				r.DrawingProps(s.GraphicFrame.NvGraphicFramePr.CNvPr)
+			}
+			if g := s.GraphicFrame.Graphic; g != nil && g.GraphicData != nil {
+				for _, a := range g.GraphicData.Any {
+					if tbl, ok := a.(*dml.Tbl); ok {
+						for _, tr := range tbl.Tr {
+							for _, tc := range tr.Tc {
+								r.TextBody(tc.TxBody)
+							}
+						}
+					}
+				}
+			}
+		case s.CxnSp != nil:
+			if s.CxnSp.NvCxnSpPr != nil {
+				r.DrawingProps(s.CxnSp.NvCxnSpPr.CNvPr)
+			}
+		case s.Pic != nil:
+			if s.Pic.NvPicPr != nil {
+				r.DrawingProps(s.Pic.NvPicPr.CNvPr)
+			}
+		}
+	}
+}
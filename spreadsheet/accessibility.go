@@ -0,0 +1,212 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package spreadsheet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	sd "github.com/unidoc/unioffice/v2/schema/soo/dml/spreadsheetDrawing"
+	"github.com/unidoc/unioffice/v2/schema/soo/sml"
+	"github.com/unidoc/unioffice/v2/spreadsheet/reference"
+)
+
+// CheckAccessibility checks the sheets for accessibility issues: pictures,
+// charts and shapes without alternative text, tables without a header row,
+// merged cells, cell text with a low contrast to the cell fill and hyperlinks
+// without text. Merged cells within tables are reported as warnings, other
+// merged cells as information.
+func (wb *Workbook) CheckAccessibility() []common.AccessibilityIssue {
+	var theme *dml.Theme
+	if len(wb._acca) > 0 {
+		theme = wb._acca[0]
+	}
+	issues := []common.AccessibilityIssue{}
+	add := func(loc string, rule common.AccessibilityRule, sev common.AccessibilitySeverity, msg string) {
+		issues = append(issues, common.AccessibilityIssue{Rule: rule, Severity: sev, Location: loc, Message: msg})
+	}
+	tableIdx := 0
+	for _, s := range wb.Sheets() {
+		ws := s.X()
+		name := s.Name()
+
+		var tables []*sml.Table
+		if ws.TableParts != nil {
+			n := len(ws.TableParts.TablePart)
+			if tableIdx+n <= len(wb._adbc) {
+				tables = wb._adbc[tableIdx : tableIdx+n]
+			}
+			tableIdx += n
+		}
+		for _, t := range tables {
+			if t.HeaderRowCountAttr != nil && *t.HeaderRowCountAttr == 0 {
+				add(name+"!"+t.RefAttr, common.AccessibilityTableHeader, common.AccessibilityWarning, fmt.Sprintf("table %q has no header row", t.DisplayNameAttr))
+			}
+		}
+		for _, mc := range s.MergedCells() {
+			ref := mc.Reference()
+			sev, msg := common.AccessibilityInfo, "cells are merged"
+			for _, t := range tables {
+				if rangesOverlap(ref, t.RefAttr) {
+					sev, msg = common.AccessibilityWarning, fmt.Sprintf("cells of table %q are merged", t.DisplayNameAttr)
+				}
+			}
+			add(name+"!"+ref, common.AccessibilityMergedCells, sev, msg)
+		}
+
+		if ws.Hyperlinks != nil {
+			for _, hl := range ws.Hyperlinks.Hyperlink {
+				if hl.DisplayAttr != nil && strings.TrimSpace(*hl.DisplayAttr) != "" {
+					continue
+				}
+				if from, to, err := reference.ParseRangeReference(hl.RefAttr); err == nil && from == to {
+					if strings.TrimSpace(s.Cell(hl.RefAttr).GetFormattedValue()) != "" {
+						continue
+					}
+				}
+				add(name+"!"+hl.RefAttr, common.AccessibilityLinkText, common.AccessibilityError, "hyperlink has no text")
+			}
+		}
+
+		if ws.SheetData != nil {
+			for _, r := range ws.SheetData.Row {
+				for _, c := range r.C {
+					if c.SAttr == nil || c.RAttr == nil {
+						continue
+					}
+					fg, bg, size, bold, ok := wb.cellColors(theme, *c.SAttr)
+					if !ok {
+						continue
+					}
+					if ratio, ok := common.ContrastRatio(fg, bg); ok && ratio < common.MinimumContrast(size, bold) {
+						if strings.TrimSpace(s.Cell(*c.RAttr).GetFormattedValue()) == "" {
+							continue
+						}
+						add(name+"!"+*c.RAttr, common.AccessibilityContrast, common.AccessibilityWarning, fmt.Sprintf("text has a contrast ratio of %.2f:1", ratio))
+					}
+				}
+			}
+		}
+
+		if dr, _ := s.GetDrawing(); dr != nil {
+			for _, a := range dr.EG_Anchor {
+				if a.AnchorChoice == nil {
+					continue
+				}
+				var obj *sd.EG_ObjectChoicesChoice
+				switch ac := a.AnchorChoice; {
+				case ac.TwoCellAnchor != nil:
+					obj = ac.TwoCellAnchor.ObjectChoicesChoice
+				case ac.OneCellAnchor != nil:
+					obj = ac.OneCellAnchor.ObjectChoicesChoice
+				case ac.AbsoluteAnchor != nil:
+					obj = ac.AbsoluteAnchor.ObjectChoicesChoice
+				}
+				if obj == nil {
+					continue
+				}
+				var pr *dml.CT_NonVisualDrawingProps
+				what := "graphic"
+				switch {
+				case obj.Pic != nil && obj.Pic.NvPicPr != nil:
+					pr, what = obj.Pic.NvPicPr.CNvPr, "picture"
+				case obj.GraphicFrame != nil && obj.GraphicFrame.NvGraphicFramePr != nil:
+					pr, what = obj.GraphicFrame.NvGraphicFramePr.CNvPr, "chart"
+				case obj.Sp != nil && obj.Sp.NvSpPr != nil && obj.Sp.TxBody == nil:
+					pr, what = obj.Sp.NvSpPr.CNvPr, "shape"
+				case obj.GrpSp != nil && obj.GrpSp.NvGrpSpPr != nil:
+					pr, what = obj.GrpSp.NvGrpSpPr.CNvPr, "group"
+				default:
+					continue
+				}
+				if pr != nil && !common.HasAltText(pr) {
+					add(fmt.Sprintf("%s, %s %q", name, what, pr.NameAttr), common.AccessibilityAltText, common.AccessibilityError, what+" has no alternative text")
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// cellColors returns the font color, fill color, font size and boldness of the
+// cell format with index xf, if the format sets a font color.
+func (wb *Workbook) cellColors(theme *dml.Theme, xf uint32) (string, string, float64, bool, bool) {
+	ss := wb.StyleSheet.X()
+	if ss.CellXfs == nil || int(xf) >= len(ss.CellXfs.Xf) {
+		return "", "", 0, false, false
+	}
+	x := ss.CellXfs.Xf[xf]
+	if x.FontIdAttr == nil || ss.Fonts == nil || int(*x.FontIdAttr) >= len(ss.Fonts.Font) {
+		return "", "", 0, false, false
+	}
+	fg, size, bold := "", 11.0, false
+	for _, fc := range ss.Fonts.Font[*x.FontIdAttr].FontChoice {
+		switch {
+		case fc.Color != nil:
+			c, ok := spreadsheetColor(theme, fc.Color)
+			if !ok {
+				return "", "", 0, false, false
+			}
+			fg = c
+		case fc.Sz != nil:
+			size = fc.Sz.ValAttr
+		case fc.B != nil:
+			bold = fc.B.ValAttr == nil || *fc.B.ValAttr
+		}
+	}
+	if fg == "" {
+		return "", "", 0, false, false
+	}
+	bg := "FFFFFF"
+	if c, ok := common.ThemeColor(theme, "lt1"); ok {
+		bg = c
+	}
+	if x.FillIdAttr != nil && ss.Fills != nil && int(*x.FillIdAttr) < len(ss.Fills.Fill) {
+		if fc := ss.Fills.Fill[*x.FillIdAttr].FillChoice; fc != nil && fc.PatternFill != nil && fc.PatternFill.PatternTypeAttr == sml.ST_PatternTypeSolid && fc.PatternFill.FgColor != nil {
+			c, ok := spreadsheetColor(theme, fc.PatternFill.FgColor)
+			if !ok {
+				return "", "", 0, false, false
+			}
+			bg = c
+		}
+	}
+	return fg, bg, size, bold, true
+}
+
+// spreadsheetThemeColors maps the theme attribute of spreadsheet colors to
+// color scheme names.
+var spreadsheetThemeColors = []string{"lt1", "dk1", "lt2", "dk2", "accent1", "accent2", "accent3", "accent4", "accent5", "accent6", "hlink", "folHlink"}
+
+// spreadsheetColor returns the RRGGBB value of a color given by RGB value or
+// theme index. Tints and indexed colors are not supported.
+func spreadsheetColor(theme *dml.Theme, c *sml.CT_Color) (string, bool) {
+	switch {
+	case c.RgbAttr != nil:
+		return *c.RgbAttr, true
+	case c.ThemeAttr != nil && int(*c.ThemeAttr) < len(spreadsheetThemeColors):
+		return common.ThemeColor(theme, spreadsheetThemeColors[*c.ThemeAttr])
+	}
+	return "", false
+}
+
+// rangesOverlap reports whether two cell ranges such as "A1:C4" overlap.
+func rangesOverlap(a, b string) bool {
+	af, at, err := reference.ParseRangeReference(a)
+	if err != nil {
+		return false
+	}
+	bf, bt, err := reference.ParseRangeReference(b)
+	if err != nil {
+		return false
+	}
+	return af.ColumnIdx <= bt.ColumnIdx && bf.ColumnIdx <= at.ColumnIdx && af.RowIdx <= bt.RowIdx && bf.RowIdx <= at.RowIdx
+}
@@ -26,14 +26,14 @@ _ebfb .alignSymbolsHorizontally (_dbg ,_aed );_ebfb .alignSymbolsVertically (_db
 func (_gccf *convertContext )makePages (){for _ ,_bba :=range _gccf ._cca {for _ ,_bfe :=range _gccf ._cag {_bba ._cceg =append (_bba ._cceg ,&page {_cfef :[]*pageRow {},_ded :_bba ,_bda :_bfe });};};};const _eeb =64.0;func (_bbfe *convertContext )drawPage (_deb *page ){_gfa :=_bbfe ._aedd ;
 _fdaf :=_bbfe ._ccea ;for _ ,_ged :=range _deb ._cfef {_afc :=_bbfe ._gcbg [_ged ._fcg ];for _ ,_bfab :=range _ged ._fba {var _dccd float64 ;if _ged ._fcg > 1{_dccd =_bbfe ._gcbg [_ged ._fcg -1]._fgd ;};var _agd ,_ceff float64 ;if _fgb :=_bfab ._eabe ;
 _fgb !=nil {_agd =_fgb ._feg ;};if _gbad :=_bfab ._ccfa ;_gbad !=nil {_ceff =_gbad ._feg ;};_fefb :=_gfa +_afc ._beee -0.5*(_dccd -_agd );_bcd :=_gfa +_afc ._beee +_afc ._dgbbd +0.5*(_afc ._fgd +_ceff );_afff :=_fdaf +_bfab ._aeag ;_cbd :=_afff +_bfab ._eed ;
-if _bfab ._bfbf !=nil &&_bfab ._bfbf !=_be .ColorBlack {_bf .FillRectangle (_bbfe ._ddaf ,_afff ,_fefb ,_cbd -_afff ,_bcd -_fefb ,_bfab ._bfbf );};};};for _ ,_dfb :=range _deb ._cfef {_fbe :=_bbfe ._gcbg [_dfb ._fcg ];for _ ,_aaeb :=range _dfb ._fba {_cedf :=_aaeb ._acg < _aaeb ._aeag ;
+if _bfab ._bfbf !=nil &&_bfab ._bfbf !=_be .ColorBlack {_bf .FillRectangle (_bbfe ._ddaf ,_afff ,_fefb ,_cbd -_afff ,_bcd -_fefb ,_bfab ._bfbf );};};};for _ ,_dfb :=range _deb ._cfef {_bbfe .tagRow ();_fbe :=_bbfe ._gcbg [_dfb ._fcg ];for _ ,_aaeb :=range _dfb ._fba {_bbfe .tagCell ();_cedf :=_aaeb ._acg < _aaeb ._aeag ;
 _egee :=_aaeb ._bcbd > _aaeb ._aeag +_aaeb ._gcca ;var _afcd ,_gdeg bool ;for _ ,_eab :=range _aaeb ._agac {for _ ,_bedd :=range _eab ._abbc {if _cedf &&!_afcd {_afcd =_bedd ._debg < 0;};if _egee &&!_gdeg {_gdeg =_aaeb ._gcca < _bedd ._debg +_bedd ._fbdg ;
-};if _aaeb ._aeag +_bedd ._debg >=_aaeb ._acg &&_aaeb ._aeag +_bedd ._debg +_bedd ._fbdg <=_aaeb ._bcbd {_gdge :=_bbfe ._ddaf .NewStyledParagraph ();_bgf :=_fdaf +_aaeb ._aeag +_bedd ._debg ;_daf :=_gfa +_fbe ._beee +_eab ._efca -_bedd ._ffgg -_ecfa (0.5);
-_gdge .SetPos (_bgf ,_daf );var _cecc *_be .TextChunk ;if _bedd ._egea !=""{_cecc =_gdge .AddExternalLink (_bedd ._bagd ,_bedd ._egea );}else {_cecc =_gdge .Append (_bedd ._bagd );};if _bedd ._fcac !=nil {_cecc .Style =*_bedd ._fcac ;};_bbfe ._ddaf .Draw (_gdge );
+};if _aaeb ._aeag +_bedd ._debg >=_aaeb ._acg &&_aaeb ._aeag +_bedd ._debg +_bedd ._fbdg <=_aaeb ._bcbd {_gdge :=_bbfe ._ddaf .NewStyledParagraph ();_bbfe .tagText (_gdge );_bgf :=_fdaf +_aaeb ._aeag +_bedd ._debg ;_daf :=_gfa +_fbe ._beee +_eab ._efca -_bedd ._ffgg -_ecfa (0.5);
+_gdge .SetPos (_bgf ,_daf );var _cecc *_be .TextChunk ;if _bedd ._egea !=""{_cecc =_gdge .AddExternalLink (_bedd ._bagd ,_bedd ._egea );}else {_cecc =_gdge .Append (_bedd ._bagd );};if _bedd ._fcac !=nil {_cecc .Style =*_bedd ._fcac ;_bbfe ._fallback .Chunk (_gdge ,_cecc );};_bbfe ._ddaf .Draw (_gdge );
 };};};var _dagc ,_abcf ,_fbd ,_cce ,_afd ,_cgag float64 ;var _afcc ,_eddb ,_agcag ,_gfgf _be .Color ;if _gaf :=_aaeb ._eabe ;_gaf !=nil {_dagc =_gaf ._feg ;_afcc =_gaf ._dacg ;};if _fcaf :=_aaeb ._ccfa ;_fcaf !=nil {_abcf =_fcaf ._feg ;_eddb =_fcaf ._dacg ;
 };if _afg :=_aaeb ._gfaa ;_afg !=nil {_fbd =_afg ._feg ;_afd =_fbd /2;_agcag =_afg ._dacg ;};if _facc :=_aaeb ._fcf ;_facc !=nil {_cce =_facc ._feg ;_cgag =_cce /2;_gfgf =_facc ._dacg ;};var _bbg float64 ;if _dfb ._fcg > 1{_bbg =_bbfe ._gcbg [_dfb ._fcg -1]._fgd ;
 };_eeg :=_gfa +_fbe ._beee -0.5*(_bbg -_dagc );_cfaa :=_gfa +_fbe ._beee +_fbe ._dgbbd +0.5*(_fbe ._fgd +_abcf );_agge :=_fdaf +_aaeb ._aeag ;_ccba :=_agge +_aaeb ._eed ;_bf .DrawLine (_bbfe ._ddaf ,_agge ,_eeg ,_ccba ,_eeg ,_dagc ,_afcc );_bf .DrawLine (_bbfe ._ddaf ,_agge ,_cfaa ,_ccba ,_cfaa ,_abcf ,_eddb );
-if !_afcd {_bf .DrawLine (_bbfe ._ddaf ,_agge -_afd ,_eeg ,_agge -_afd ,_cfaa ,_fbd ,_agcag );};if !_gdeg {_bf .DrawLine (_bbfe ._ddaf ,_ccba -_cgag ,_eeg ,_ccba -_cgag ,_cfaa ,_cce ,_gfgf );};};};for _ ,_dff :=range _deb ._ede {if _dff !=nil {_bbfe ._ddaf .Draw (_dff );
+if !_afcd {_bf .DrawLine (_bbfe ._ddaf ,_agge -_afd ,_eeg ,_agge -_afd ,_cfaa ,_fbd ,_agcag );};if !_gdeg {_bf .DrawLine (_bbfe ._ddaf ,_ccba -_cgag ,_eeg ,_ccba -_cgag ,_cfaa ,_cce ,_gfgf );};};};for _ ,_dff :=range _deb ._ede {if _dff !=nil {_bbfe .tagImage (_dff );_bbfe ._ddaf .Draw (_dff );
 };};};
 
 // Options contains the options for convert process
@@ -41,7 +41,21 @@ type Options struct{
 
 // DefaultPageSize is applied when there is no page size explicitly set in the document.
 // A4 is the default option.
-DefaultPageSize _bf .PageSize ;};type colInfo struct{_fbc float64 ;_aeba float64 ;_eggc *style ;};const _edg =3;func (_agab *convertContext )getImage (_dcgc _fc .Image ,_cgfa ,_ccdc ,_ecbg ,_adga ,_adc ,_eddd float64 ,_efag _bf .ImgPart )*_be .Image {_adga +=_agab ._aedd ;
+DefaultPageSize _bf .PageSize ;
+
+// PdfA selects the PDF/A conformance level of the output, PdfANone by default. Fonts are embedded
+// in PDF/A output, so the fonts used by the sheet should be registered with RegisterFont.
+PdfA PdfAConformance ;
+
+// Tagged produces a tagged PDF in which the cells of each page form a table and images and
+// charts are figures.
+Tagged bool ;
+
+// FontFallback sets the fonts tried for the characters missing from the font of a cell and the
+// substitutes of fonts that are not registered, such as Carlito for Calibri. Built-in chains and
+// substitutes are used for the scripts and fonts it does not list. The fonts replaced are
+// returned by ConvertToPdfWithSubstitutions.
+FontFallback *FontFallback ;};type colInfo struct{_fbc float64 ;_aeba float64 ;_eggc *style ;};const _edg =3;func (_agab *convertContext )getImage (_dcgc _fc .Image ,_cgfa ,_ccdc ,_ecbg ,_adga ,_adc ,_eddd float64 ,_efag _bf .ImgPart )*_be .Image {_adga +=_agab ._aedd ;
 _ecbg +=_agab ._ccea ;_ddbd ,_dbge :=_bf .GetImage (_agab ._ddaf ,_dcgc ,_cgfa ,_ccdc ,_ecbg ,_adga ,_adc ,_eddd ,_efag );if _dbge !=nil {_c .Log .Debug ("\u0043\u0061\u006eno\u0074\u0020\u0067\u0065\u0074\u0020\u0061\u006e\u0020\u0069\u006d\u0061\u0067\u0065\u003a\u0020\u0025\u0073",_dbge );
 return nil ;};return _ddbd ;};func (_eeag *convertContext )getStyleFromRPrElt (_cefg *_ef .CT_RPrElt )*style {if _cefg ==nil ||_cefg .RPrEltChoice ==nil ||len (_cefg .RPrEltChoice )==0{return nil ;};_gbcf :=&style {};for _ ,_ffb :=range _cefg .RPrEltChoice {if _ffb .RFont !=nil {_gbcf ._cbcd =&_ffb .RFont .ValAttr ;
 };if _cfdf :=_ffb .B ;_cfdf !=nil {_ebc :=_cfdf .ValAttr ==nil ||*_cfdf .ValAttr ;_gbcf ._acec =&_ebc ;};if _fbg :=_ffb .I ;_fbg !=nil {_egaa :=_fbg .ValAttr ==nil ||*_fbg .ValAttr ;_gbcf ._ddc =&_egaa ;};if _ffcf :=_ffb .U ;_ffcf !=nil {_gedg :=_ffcf .ValAttr ==_ef .ST_UnderlineValuesSingle ||_ffcf .ValAttr ==_ef .ST_UnderlineValuesUnset ;
@@ -77,17 +91,17 @@ _cea ._fbce =_bf .FromSTCoordinate (_bdg .RowOff );_cea ._dee =int (_bdg .Col );
 _af !=nil {if _ebf :=_af .EmbedAttr ;_ebf !=nil {for _ ,_cfd :=range _bg .X ().Relationship {if _cfd .IdAttr ==*_ebf {for _ ,_bfdb :=range _efd ._ddee .Images {if _bfdb .Target ()==_cfd .TargetAttr {_fef ,_abf :=_bb .Open (_bfdb .Path ());if _abf !=nil {_c .Log .Debug ("\u004fp\u0065\u006e\u0020\u0069m\u0061\u0067\u0065\u0020\u0066i\u006ce\u0020e\u0072\u0072\u006f\u0072\u003a\u0020\u0025s",_abf );
 continue ;};_bc ,_ ,_abf :=_fc .Decode (_fef );if _abf !=nil {_c .Log .Debug ("\u0044\u0065\u0063\u006fde\u0020\u0069\u006d\u0061\u0067\u0065\u0020\u0065\u0072\u0072\u006f\u0072\u003a\u0020%\u0073",_abf );continue ;};_cea ._baec =_bc ;};};};};};};};}else if _dga :=_ced .GraphicFrame ;
 _dga !=nil {if _gdg :=_dga .Graphic ;_gdg !=nil {if _aga :=_gdg .GraphicData ;_aga !=nil {for _ ,_dc :=range _aga .Any {if _degg ,_ace :=_dc .(*_ed .Chart );_ace {for _ ,_ff :=range _bg .X ().Relationship {if _ff .IdAttr ==_degg .IdAttr {_abd :=_efd ._ddee .GetChartByTargetId (_ff .TargetAttr );
-if _abd !=nil {_cea ._fgbe =_abd ;};};};};};};};};};};if _cea ._baec !=nil ||_cea ._fgbe !=nil {_efd ._ecag =append (_efd ._ecag ,_cea );};};};};const _de =2;type convertContext struct{_ddaf *_be .Creator ;_ddee *_g .Workbook ;_accd *_fe .Theme ;_fcb *_g .Sheet ;
+if _abd !=nil {_cea ._fgbe =_abd ;};};};};};};};};};};if _cea ._baec !=nil ||_cea ._fgbe !=nil {_efd ._ecag =append (_efd ._ecag ,_cea );};};};};const _de =2;type convertContext struct{_ddaf *_be .Creator ;_fallback *_bf .Fallback ;_ddee *_g .Workbook ;_accd *_fe .Theme ;_fcb *_g .Sheet ;
 _bde *_g .StyleSheet ;_egff int ;_fcd int ;_cca []*pagespan ;_eagd *page ;_dfdg []*colInfo ;_gcbg []*rowInfo ;_cag []*rowspan ;_aedd float64 ;_ccea float64 ;_bggb float64 ;_ceb float64 ;_bcb []*mergedCell ;_ecag []*anchor ;_agec float64 ;_gcfcd int ;_fcbd int ;
-_cgdc int ;_fgbc int ;_cddc bool ;_dagd []_g .Table ;};const _cf =15.0;type cell struct{_gca _ef .ST_CellType ;_bdgg int ;_aeag float64 ;_agac []*line ;_gcca float64 ;_eed float64 ;_dadac float64 ;_acg float64 ;_bcbd float64 ;_ccgb *_be .TextStyle ;_eabe *border ;
+_cgdc int ;_fgbc int ;_cddc bool ;_dagd []_g .Table ;_tags *sheetTagger ;};const _cf =15.0;type cell struct{_gca _ef .ST_CellType ;_bdgg int ;_aeag float64 ;_agac []*line ;_gcca float64 ;_eed float64 ;_dadac float64 ;_acg float64 ;_bcbd float64 ;_ccgb *_be .TextStyle ;_eabe *border ;
 _ccfa *border ;_gfaa *border ;_fcf *border ;_gbea bool ;_acda bool ;_bfbf _be .Color ;};func (_cab *convertContext )alignSymbolsVertically (_ffd *cell ,_cced _ef .ST_VerticalAlignment ){var _ceffc float64 ;switch _cced {case _ef .ST_VerticalAlignmentTop :_ceffc =_de ;
 if _ffd ._gbea {_ceffc -=_ga ;}else if _ffd ._acda {_ceffc +=4*_ga ;};for _ ,_ecef :=range _ffd ._agac {_ceffc +=_ecef ._cfag ;_ecef ._efca =_ceffc ;_ceffc +=_cg ;};case _ef .ST_VerticalAlignmentCenter :_dfc :=0.0;for _ ,_aee :=range _ffd ._agac {_dfc +=_aee ._cfag +_ecfa (1);
 };_ceffc =0.5*(_ffd ._dadac -_dfc );if _ffd ._gbea {_ceffc -=2*_ga ;}else if _ffd ._acda {_ceffc +=2*_ga ;};for _ ,_cee :=range _ffd ._agac {_ceffc +=_cee ._cfag +0.5*_cg ;_cee ._efca =_ceffc ;_ceffc +=0.5*_cg ;};default:_ceffc =_ffd ._dadac -_de ;if _ffd ._gbea {_ceffc -=4*_ga ;
 }else if _ffd ._acda {_ceffc +=_ga ;};for _faec :=len (_ffd ._agac )-1;_faec >=0;_faec --{_ffd ._agac [_faec ]._efca =_ceffc ;_ceffc -=_ffd ._agac [_faec ]._cfag ;_ceffc -=_cg ;};};};
 
 // RegisterFont makes a PdfFont accessible for using in converting to PDF.
-func RegisterFont (name string ,style FontStyle ,font *_e .PdfFont ){_bf .RegisterFont (name ,style ,font );};func _ffgb (_gfc *symbol ){_gefg :=_be .New ();_gda :=_gefg .NewStyledParagraph ();_gda .SetMargins (0,0,0,0);_fabb :=_gda .Append (_gfc ._bagd );
-if _gfc ._fcac !=nil {_fabb .Style =*_gfc ._fcac ;};_gfc ._ffgg =_gda .Height ();if _gfc ._fbdg ==0{_gfc ._fbdg =_gda .Width ();};};func (_cgef *convertContext )getColorFromTheme (_gfff uint32 )string {_debfc :=_cgef ._ddee .Themes ();if len (_debfc )!=0{_eefa :=_debfc [0];
+func RegisterFont (name string ,style FontStyle ,font *_e .PdfFont ){_bf .RegisterFont (name ,style ,font );};func _ffgb (_gfc *symbol ,_dbbg *_bf .Fallback ){_gefg :=_be .New ();_gda :=_gefg .NewStyledParagraph ();_gda .SetMargins (0,0,0,0);_fabb :=_gda .Append (_gfc ._bagd );
+if _gfc ._fcac !=nil {_fabb .Style =*_gfc ._fcac ;_dbbg .Chunk (_gda ,_fabb );};_gfc ._ffgg =_gda .Height ();if _gfc ._fbdg ==0{_gfc ._fbdg =_gda .Width ();};};func (_cgef *convertContext )getColorFromTheme (_gfff uint32 )string {_debfc :=_cgef ._ddee .Themes ();if len (_debfc )!=0{_eefa :=_debfc [0];
 if _aaee :=_eefa .ThemeElements ;_aaee !=nil {if _gabe :=_aaee .ClrScheme ;_gabe !=nil {switch _gfff {case 0:return _bf .GetColorStringFromDmlColor (_gabe .Lt1 );case 1:return _bf .GetColorStringFromDmlColor (_gabe .Dk1 );case 2:return _bf .GetColorStringFromDmlColor (_gabe .Lt2 );
 case 3:return _bf .GetColorStringFromDmlColor (_gabe .Dk2 );case 4:return _bf .GetColorStringFromDmlColor (_gabe .Accent1 );case 5:return _bf .GetColorStringFromDmlColor (_gabe .Accent2 );case 6:return _bf .GetColorStringFromDmlColor (_gabe .Accent3 );
 case 7:return _bf .GetColorStringFromDmlColor (_gabe .Accent4 );case 8:return _bf .GetColorStringFromDmlColor (_gabe .Accent5 );case 9:return _bf .GetColorStringFromDmlColor (_gabe .Accent6 );};};};};return "";};var _bgfg =[]string {"\u0030\u0030\u0030\u0030\u0030\u0030","\u0066\u0066\u0066\u0066\u0066\u0066","\u0066\u0066\u0030\u0030\u0030\u0030","\u0030\u0030\u0066\u0066\u0030\u0030","\u0030\u0030\u0030\u0030\u0066\u0066","\u0066\u0066\u0066\u0066\u0030\u0030","\u0066\u0066\u0030\u0030\u0066\u0066","\u0030\u0030\u0066\u0066\u0066\u0066","\u0030\u0030\u0030\u0030\u0030\u0030","\u0066\u0066\u0066\u0066\u0066\u0066","\u0066\u0066\u0030\u0030\u0030\u0030","\u0030\u0030\u0066\u0066\u0030\u0030","\u0030\u0030\u0030\u0030\u0066\u0066","\u0066\u0066\u0066\u0066\u0030\u0030","\u0066\u0066\u0030\u0030\u0066\u0066","\u0030\u0030\u0066\u0066\u0066\u0066","\u0038\u0030\u0030\u0030\u0030\u0030","\u0030\u0030\u0038\u0030\u0030\u0030","\u0030\u0030\u0030\u0030\u0038\u0030","\u0038\u0030\u0038\u0030\u0030\u0030","\u0038\u0030\u0030\u0030\u0038\u0030","\u0030\u0030\u0038\u0030\u0038\u0030","\u0063\u0030\u0063\u0030\u0063\u0030","\u0038\u0030\u0038\u0030\u0038\u0030","\u0039\u0039\u0039\u0039\u0066\u0066","\u0039\u0039\u0033\u0033\u0036\u0036","\u0066\u0066\u0066\u0066\u0063\u0063","\u0063\u0063\u0066\u0066\u0066\u0066","\u0036\u0036\u0030\u0030\u0036\u0036","\u0066\u0066\u0038\u0030\u0038\u0030","\u0030\u0030\u0036\u0036\u0063\u0063","\u0063\u0063\u0063\u0063\u0066\u0066","\u0030\u0030\u0030\u0030\u0038\u0030","\u0066\u0066\u0030\u0030\u0066\u0066","\u0066\u0066\u0066\u0066\u0030\u0030","\u0030\u0030\u0066\u0066\u0066\u0066","\u0038\u0030\u0030\u0030\u0038\u0030","\u0038\u0030\u0030\u0030\u0030\u0030","\u0030\u0030\u0038\u0030\u0038\u0030","\u0030\u0030\u0030\u0030\u0066\u0066","\u0030\u0030\u0063\u0063\u0066\u0066","\u0063\u0063\u0066\u0066\u0066\u0066","\u0063\u0063\u0066\u0066\u0063\u0063","\u0066\u0066\u0066\u0066\u0039\u0039","\u0039\u0039\u0063\u0063\u0066\u0066","\u0066\u0066\u0039\u0039\u0063\u0063","\u0063\u0063\u0039\u0039\u0066\u0066","\u0066\u0066\u0063\u0063\u0039\u0039","\u0033\u0033\u0036\u0036\u0066\u0066","\u0033\u0033\u0063\u0063\u0063\u0063","\u0039\u0039\u0063\u0063\u0030\u0030","\u0066\u0066\u0063\u0063\u0030\u0030","\u0066\u0066\u0039\u0039\u0030\u0030","\u0066\u0066\u0036\u0036\u0030\u0030","\u0036\u0036\u0036\u0036\u0039\u0039","\u0039\u0036\u0039\u0036\u0039\u0036","\u0030\u0030\u0033\u0033\u0036\u0036","\u0033\u0033\u0039\u0039\u0036\u0036","\u0030\u0030\u0033\u0033\u0030\u0030","\u0033\u0033\u0033\u0033\u0030\u0030","\u0039\u0039\u0033\u0033\u0030\u0030","\u0039\u0039\u0033\u0033\u0036\u0036","\u0033\u0033\u0033\u0033\u0039\u0039","\u0033\u0033\u0033\u0033\u0033\u0033"};
@@ -133,7 +147,7 @@ _abfa :=_def -_cgce ;_facf :=_bbcb +_geg -_cfab ;_ebg :=_cef .imageFromAnchor (_
 _ddd :=_dcb -_effe ;_bfee :=_cef .imageFromAnchor (_efad ,_dce ,_ddd );_dcgf ._ede =append (_dcgf ._ede ,_cef .getImage (_bfee ,_ddd ,_dce ,_feeb ,_effe ,0,0,_bf .ImgPart_whole ));};};};var _cg =_ecfa (1);func (_aage *convertContext )alignSymbolsHorizontally (_gdad *cell ,_cfed _ef .ST_HorizontalAlignment ){if _cfed ==_ef .ST_HorizontalAlignmentUnset {switch _gdad ._gca {case _ef .ST_CellTypeB :_cfed =_ef .ST_HorizontalAlignmentCenter ;
 case _ef .ST_CellTypeN :_cfed =_ef .ST_HorizontalAlignmentRight ;default:_cfed =_ef .ST_HorizontalAlignmentLeft ;};};var _eaed float64 ;for _ ,_defc :=range _gdad ._agac {switch _cfed {case _ef .ST_HorizontalAlignmentLeft :_eaed =_edg ;case _ef .ST_HorizontalAlignmentRight :_ccd :=_eef (_defc ._abbc );
 _eaed =_gdad ._gcca -_edg -_ccd ;case _ef .ST_HorizontalAlignmentCenter :_cde :=_eef (_defc ._abbc );_eaed =(_gdad ._gcca -_cde )/2;};for _ ,_bef :=range _defc ._abbc {_bef ._debg +=_eaed ;};};};var _gc =3.025/_ecfa (1);func (_ffc *convertContext )drawSheet (){for _gef ,_bbdd :=range _ffc ._cca {_fdb :=len (_bbdd ._cceg );
-if _gef ==len (_ffc ._cca )-1{for _age :=len (_bbdd ._cceg )-1;_age >=0;_age --{if !_bbdd ._cceg [_age ]._gbc {_fdb =_age ;};};};_bdc :=_bbdd ._cceg [:_fdb ];for _ ,_faaa :=range _bdc {_ffc ._ddaf .NewPage ();_ffc .drawPage (_faaa );};};};func (_aab *convertContext )imageFromAnchor (_efff *anchor ,_bca ,_abe float64 )_fc .Image {if _efff ._baec !=nil {return _efff ._baec ;
+if _gef ==len (_ffc ._cca )-1{for _age :=len (_bbdd ._cceg )-1;_age >=0;_age --{if !_bbdd ._cceg [_age ]._gbc {_fdb =_age ;};};};_bdc :=_bbdd ._cceg [:_fdb ];for _ ,_faaa :=range _bdc {_ffc ._ddaf .NewPage ();_ffc .tagPage ();_ffc .drawPage (_faaa );};};};func (_aab *convertContext )imageFromAnchor (_efff *anchor ,_bca ,_abe float64 )_fc .Image {if _efff ._baec !=nil {return _efff ._baec ;
 };if _efff ._fgbe !=nil {_ddfc ,_dbdd :=_bf .MakeImageFromChartSpace (_efff ._fgbe ,_bca ,_abe ,_aab ._accd ,_aab ._ddee );if _dbdd !=nil {_c .Log .Debug ("C\u0061\u006e\u006e\u006f\u0074\u0020\u006d\u0061\u006b\u0065\u0020\u0061\u006e\u0020\u0069\u006d\u0061\u0067e\u0020\u0066\u0072\u006f\u006d\u0020\u0063\u0068\u0061\u0072tS\u0070\u0061\u0063e\u003a \u0025\u0073",_dbdd );
 return nil ;};return _ddfc ;};return nil ;};const _ge =0.25;type rowInfo struct{_beee float64 ;_cebc bool ;_dgbbd float64 ;_fdbb *style ;_ecd []*cell ;_fgd float64 ;};const _ga =1.5;func (_fagf *convertContext )getStyleFromCell (_bceg _g .Cell ,_gac ,_bdfe ,_eac *style )*style {if _eac !=nil {_gafe (_eac ,_gac );
 _gafe (_eac ,_bdfe );return _eac ;};_cafc :=_bceg .X ();_caa :=_fagf .getStyle (_cafc .SAttr );_gafe (_caa ,_gac );_gafe (_caa ,_bdfe );return _caa ;};func _ecff (_cafa *bool )bool {return _cafa !=nil &&*_cafa };func (_feed *convertContext )combineCellStyleWithRPrElt (_gdf *style ,_aade *_ef .CT_RPrElt )*style {_aggf :=*_gdf ;
@@ -141,7 +155,7 @@ _bcfe :=_feed .getStyleFromRPrElt (_aade );if _bcfe ==nil {return &_aggf ;};if _
 };if _bcfe ._ddc !=nil {_aggf ._ddc =_bcfe ._ddc ;};if _bcfe ._eecc !=nil {_aggf ._eecc =_bcfe ._eecc ;};if _bcfe ._bfbd !=nil {_aggf ._bfbd =_bcfe ._bfbd ;};if _bcfe ._eba !=nil {_aggf ._eba =_bcfe ._eba ;};return &_aggf ;};func (_acde *convertContext )makeTextStyleFromCellStyle (_dae *style )*_be .TextStyle {_ecge :=_acde ._ddaf .NewTextStyle ();
 if _dae ==nil {_ecge .FontSize =_bf .DefaultFontSize ;_ecge .Font =_bf .AssignStdFontByName (_ecge ,_bf .StdFontsMap ["\u0064e\u0066\u0061\u0075\u006c\u0074"][FontStyle_Regular ]);return &_ecge ;};if _ecff (_dae ._eecc ){_ecge .Underline =true ;_ecge .UnderlineStyle =_be .TextDecorationLineStyle {Offset :0.5,Thickness :_ecfa (1/32)};
 };var _cffd FontStyle ;if _ecff (_dae ._acec )&&_ecff (_dae ._ddc ){_cffd =FontStyle_BoldItalic ;}else if _ecff (_dae ._acec ){_cffd =FontStyle_Bold ;}else if _ecff (_dae ._ddc ){_cffd =FontStyle_Italic ;}else {_cffd =FontStyle_Regular ;};_fcdc :="\u0064e\u0066\u0061\u0075\u006c\u0074";
-if _dae ._cbcd !=nil {_fcdc =*_dae ._cbcd ;};if _aca ,_daae :=_bf .StdFontsMap [_fcdc ];_daae {_ecge .Font =_bf .AssignStdFontByName (_ecge ,_aca [_cffd ]);}else if _dedb :=_bf .GetRegisteredFont (_fcdc ,_cffd );_dedb !=nil {_ecge .Font =_dedb ;}else {_c .Log .Debug ("\u0046\u006f\u006e\u0074\u0020\u0025\u0073\u0020\u0077\u0069\u0074h\u0020\u0073\u0074\u0079\u006c\u0065\u0020\u0025s\u0020i\u0073\u0020\u006e\u006f\u0074\u0020\u0066\u006f\u0075\u006e\u0064\u002c\u0020\u0072\u0065\u0073\u0065\u0074 \u0074\u006f\u0020\u0064\u0065\u0066\u0061\u0075\u006c\u0074\u002e",_fcdc ,_cffd );
+if _dae ._cbcd !=nil {_fcdc =*_dae ._cbcd ;};if _aca ,_daae :=_bf .StdFontsMap [_fcdc ];_daae {_ecge .Font =_bf .AssignStdFontByName (_ecge ,_aca [_cffd ]);}else if _dedb :=_acde ._fallback .FindFont (_fcdc ,_cffd );_dedb !=nil {_ecge .Font =_dedb ;}else {_c .Log .Debug ("\u0046\u006f\u006e\u0074\u0020\u0025\u0073\u0020\u0077\u0069\u0074h\u0020\u0073\u0074\u0079\u006c\u0065\u0020\u0025s\u0020i\u0073\u0020\u006e\u006f\u0074\u0020\u0066\u006f\u0075\u006e\u0064\u002c\u0020\u0072\u0065\u0073\u0065\u0074 \u0074\u006f\u0020\u0064\u0065\u0066\u0061\u0075\u006c\u0074\u002e",_fcdc ,_cffd );
 _ecge .Font =_bf .AssignStdFontByName (_ecge ,_bf .StdFontsMap ["\u0064e\u0066\u0061\u0075\u006c\u0074"][_cffd ]);};if _dae ._ddfg !=nil {_ecge .FontSize =_d .Round (*_dae ._ddfg *_acde ._agec );};if _dae ._fgac !=nil {_ecge .Color =_be .ColorRGBFromHex (*_dae ._fgac );
 };if _dae ._bfbd !=nil &&*_dae ._bfbd {_ecge .FontSize *=_eec ;}else if _dae ._eba !=nil &&*_dae ._eba {_ecge .FontSize *=_eec ;};return &_ecge ;};type pagespan struct{_gegf float64 ;_cceg []*page ;_fggg int ;_cedc int ;};
 
@@ -153,21 +167,21 @@ for _ ,_bdd :=range _dddg {if len (_bdd ._agac )!=0{_bdd ._acg =_gagc ;_gagc =_b
 if _ddb !=nil {_bab ,_geda :=_b .Atoi (*_ddb );if _geda ==nil {_acdd :=_fdca ._ddee .SharedStrings .X ().Si [_bab ];if _acdd .T !=nil {_eea =_fdca .getSymbolsFromString (*_acdd .T ,_dgcb );}else if _acdd .R !=nil {_eea =_fdca .getSymbolsFromR (_acdd .R ,_dgcb );
 };};};case _ef .ST_CellTypeB :_bdgb :=_cdfg .V ;if _bdgb !=nil {if *_bdgb =="\u0030"{_eea =_fdca .getSymbolsFromString ("\u0046\u0041\u004cS\u0045",_dgcb );}else {_eea =_fdca .getSymbolsFromString ("\u0054\u0052\u0055\u0045",_dgcb );};};case _ef .ST_CellTypeStr :if _cdfg .F !=nil {_bcf :=_a .NewEvaluator ();
 _gcfc :=_ffgd .FormulaContext ().Cell (_cdf .Reference (),_bcf );_eea =_fdca .getSymbolsFromString (_gcfc .Value (),_dgcb );};default:_eea =_fdca .getSymbolsFromString (_cdf .GetFormattedValue (),_dgcb );};_bee :=0.0;_adb :=0.0;var _daa []*line ;var _cbc bool ;
-if _dgcb !=nil {if _dgcb ._bfbd !=nil {if *_dgcb ._bfbd {_cbc =true ;};};if _dgcb ._eba !=nil {if *_dgcb ._eba {_cbc =true ;};};};if _gebd {_daa =[]*line {};_cgcd :=_bce -2*_edg ;_afe :=[]*symbol {};for _ ,_dad :=range _eea {_ffgb (_dad );if _bee +_dad ._fbdg >=_cgcd {_eegd :=_dgdg (_afe );
+if _dgcb !=nil {if _dgcb ._bfbd !=nil {if *_dgcb ._bfbd {_cbc =true ;};};if _dgcb ._eba !=nil {if *_dgcb ._eba {_cbc =true ;};};};if _gebd {_daa =[]*line {};_cgcd :=_bce -2*_edg ;_afe :=[]*symbol {};for _ ,_dad :=range _eea {_ffgb (_dad ,_fdca ._fallback );if _bee +_dad ._fbdg >=_cgcd {_eegd :=_dgdg (_afe );
 if _cbc {_eegd /=_eec ;};_daa =append (_daa ,&line {_efca :_adb ,_abbc :_afe ,_cfag :_eegd });_afe =[]*symbol {_dad };_bee =_dad ._fbdg ;_adb +=_eegd ;}else {_dad ._debg =_bee ;_bee +=_dad ._fbdg ;_afe =append (_afe ,_dad );};};_ade :=_dgdg (_afe );if _cbc {_ade /=_eec ;
-};if len (_afe )> 0{_daa =append (_daa ,&line {_efca :_adb ,_abbc :_afe ,_cfag :_ade });};}else {for _ ,_egbe :=range _eea {_ffgb (_egbe );_egbe ._debg =_bee ;_bee +=_egbe ._fbdg ;};if len (_eea )> 0{_daa =[]*line {&line {_abbc :_eea ,_cfag :_dgdg (_eea )}};
+};if len (_afe )> 0{_daa =append (_daa ,&line {_efca :_adb ,_abbc :_afe ,_cfag :_ade });};}else {for _ ,_egbe :=range _eea {_ffgb (_egbe ,_fdca ._fallback );_egbe ._debg =_bee ;_bee +=_egbe ._fbdg ;};if len (_eea )> 0{_daa =[]*line {&line {_abbc :_eea ,_cfag :_dgdg (_eea )}};
 };};_cff :=_cdfg .TAttr ;if _cff ==_ef .ST_CellTypeUnset {_cff =_ef .ST_CellTypeN ;};return _daa ,_cff ;};func _ecfa (_fcba float64 )float64 {return _fcba *_fd .Millimeter };type mergedCell struct{_aef uint32 ;_fafb uint32 ;_bfbfe uint32 ;_gbed uint32 ;
 _bac float64 ;_fgc float64 ;};type page struct{_cfef []*pageRow ;_gbc bool ;_ede []*_be .Image ;_ded *pagespan ;_bda *rowspan ;};type anchor struct{_baec _fc .Image ;_fgbe *_ed .ChartSpace ;_bddd int ;_cafe int64 ;_gdee int ;_ccgd int64 ;_ddff int ;_fbce int64 ;
 _dee int ;_gceb int64 ;};
 
 // ConvertToPdfWithOptions convert a sheet to PDF with given options.
-func ConvertToPdfWithOptions (s *_g .Sheet ,opts *Options )*_be .Creator {_cgc :=s .X ();if _cgc ==nil {return nil ;};var _bd _be .PageSize ;_fdd :=true ;_ag :=false ;if _cgc .SheetPr !=nil &&_cgc .SheetPr .PageSetUpPr !=nil &&_cgc .SheetPr .PageSetUpPr .FitToPageAttr !=nil &&*_cgc .SheetPr .PageSetUpPr .FitToPageAttr {_ag =true ;
+func ConvertToPdfWithOptions (s *_g .Sheet ,opts *Options )*_be .Creator {return convertSheet (s ,opts ,newFallback (opts ));};func convertSheet (s *_g .Sheet ,opts *Options ,_dbbg *_bf .Fallback )*_be .Creator {_cgc :=s .X ();if _cgc ==nil {return nil ;};var _bd _be .PageSize ;_fdd :=true ;_ag :=false ;if _cgc .SheetPr !=nil &&_cgc .SheetPr .PageSetUpPr !=nil &&_cgc .SheetPr .PageSetUpPr .FitToPageAttr !=nil &&*_cgc .SheetPr .PageSetUpPr .FitToPageAttr {_ag =true ;
 };if _ab :=_cgc .PageSetup ;_ab !=nil {_fdd =_ab .OrientationAttr ==_ef .ST_OrientationLandscape ;if _gg :=_ab .PaperSizeAttr ;_gg !=nil {_bd =_fbec [*_gg ];};};if (_bd ==_be .PageSize {}){_bd =_bf .GetDefaultPageSize ();if opts !=nil &&opts .DefaultPageSize !=_bf .DefaultPageSize {_bd =_bf .GetPageDimensions (opts .DefaultPageSize );
 };};if _fdd {_bd [0],_bd [1]=_bd [1],_bd [0];};_ac :=_be .New ();_ac .SetPageSize (_bd );var _ea ,_aa ,_ce ,_db float64 ;if _gb :=_cgc .PageMargins ;_gb !=nil {_ce =_gb .LeftAttr ;_db =_gb .RightAttr ;_ea =_gb .TopAttr ;_aa =_gb .BottomAttr ;};if _ce < _ge {_ce =_ge ;
 };if _db < _ge {_db =_ge ;};if _ea < _cc {_ea =_cc ;};if _aa < _cc {_aa =_cc ;};_ea *=_fd .Inch ;_aa *=_fd .Inch ;_ce *=_fd .Inch ;_db *=_fd .Inch ;_ac .SetPageMargins (_ce ,_db ,_ea ,_aa );_df :=s .Workbook ();var _ba *_fe .Theme ;if len (_df .Themes ())> 0{_ba =_df .Themes ()[0];
 };var _ca ,_ceg ,_dd ,_caf int ;for _ ,_ae :=range _df .DefinedNames (){if _ae .Name ()=="\u005f\u0078l\u006e\u006d\u002eP\u0072\u0069\u006e\u0074\u005f\u0041\u0072\u0065\u0061"{_cfc ,_aad ,_bbf ,_eb :=_bf .ParseExcelRange (_ae .Content ());if _eb ==nil &&s .Name ()==_cfc {_ca =int (_aad .ColumnIdx );
 _ceg =int (_bbf .ColumnIdx );_dd =int (_aad .RowIdx );_caf =int (_bbf .RowIdx );};};};_bed :=[]_g .Table {};if _cgc .TableParts !=nil &&_cgc .TableParts .TablePart !=nil {_fdf :=0;_gcc :=s .Workbook ().Tables ();_dg .Slice (_gcc [:],func (_agc ,_acd int )bool {return _gcc [_agc ].X ().IdAttr < _gcc [_acd ].X ().IdAttr });
 for _ ,_fec :=range s .Workbook ().Sheets (){if _fec .Name ()==s .Name (){break ;}else {if _fec .X ().TableParts !=nil &&_fec .X ().TableParts .TablePart !=nil {_fdf +=len (_fec .X ().TableParts .TablePart );};};};if len (_gcc )>=_fdf +len (_cgc .TableParts .TablePart ){_bed =append (_bed ,_gcc [_fdf :_fdf +len (_cgc .TableParts .TablePart )]...);
-};};_fa :=&convertContext {_ddaf :_ac ,_fcb :s ,_ddee :s .Workbook (),_accd :_ba ,_bde :&s .Workbook ().StyleSheet ,_aedd :_ea ,_ccea :_ce ,_bggb :_bd [1]-_aa -_ea ,_ceb :_bd [0]-_db -_ce ,_gcfcd :_ca ,_fcbd :_ceg ,_cgdc :_dd ,_fgbc :_caf ,_cddc :_ag ,_dagd :_bed };
+};};_fa :=&convertContext {_fallback :_dbbg ,_ddaf :_ac ,_fcb :s ,_ddee :s .Workbook (),_accd :_ba ,_bde :&s .Workbook ().StyleSheet ,_aedd :_ea ,_ccea :_ce ,_bggb :_bd [1]-_aa -_ea ,_ceb :_bd [0]-_db -_ce ,_gcfcd :_ca ,_fcbd :_ceg ,_cgdc :_dd ,_fgbc :_caf ,_cddc :_ag ,_dagd :_bed };
 _fa .makeAnchors ();_fa .determineMaxIndexes ();if _fa ._egff ==0&&_fa ._fcd ==0{_ac .NewPage ();return _ac ;};_fa .makeCols ();_fa .makeRows ();_fa .makeMergedCells ();_fa .makeCells ();_fa .makePagespans ();_fa .makeRowspans ();_fa .makePages ();_fa .fillPages ();
-_fa .distributeAnchors ();_fa .drawSheet ();return _ac ;};
\ No newline at end of file
+_fa .distributeAnchors ();_fa .tagSheet (opts );_fa .drawSheet ();_fa .setPdfOutput (opts );return _ac ;};
\ No newline at end of file
@@ -0,0 +1,32 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	"image"
+	"io"
+
+	"github.com/unidoc/unioffice/v2/internal/convertutils"
+	"github.com/unidoc/unioffice/v2/spreadsheet"
+)
+
+// ConvertToImages renders the print pages of the sheet to images with the
+// resolution dpi in dots per inch, 96 if dpi is not positive. The layout is
+// the same as that of ConvertToPdfWithOptions. Pages are given by their
+// one-based numbers, all pages are rendered if none are given.
+func ConvertToImages(s *spreadsheet.Sheet, opts *Options, dpi float64, pages ...int) ([]image.Image, error) {
+	return convertutils.RenderPages(ConvertToPdfWithOptions(s, opts), dpi, pages...)
+}
+
+// ConvertToPNG renders the page with the one-based number page to w as a PNG
+// image with the resolution dpi, see ConvertToImages.
+func ConvertToPNG(s *spreadsheet.Sheet, opts *Options, dpi float64, page int, w io.Writer) error {
+	return convertutils.RenderPNG(ConvertToPdfWithOptions(s, opts), dpi, page, w)
+}
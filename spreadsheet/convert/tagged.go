@@ -0,0 +1,90 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package convert
+
+import (
+	"github.com/unidoc/unioffice/v2/internal/convertutils"
+	"github.com/unidoc/unipdf/v4/creator"
+	"github.com/unidoc/unipdf/v4/model"
+)
+
+// PdfAConformance is a PDF/A conformance level of the output, see Options.
+type PdfAConformance = convertutils.PdfAConformance
+
+// PdfAConformance constants.
+const (
+	PdfANone = convertutils.PdfANone
+	PdfA2B   = convertutils.PdfA2B
+	PdfA3B   = convertutils.PdfA3B
+)
+
+// sheetTagger builds the structure tree of a tagged PDF while the pages of a
+// sheet are drawn, with a table for the cells of each page.
+type sheetTagger struct {
+	_tagger *convertutils.Tagger
+	_table  *model.KDict
+	_row    *model.KDict
+	_cell   *model.KDict
+}
+
+// tagSheet starts building the structure tree if tagged output was requested.
+func (c *convertContext) tagSheet(opts *Options) {
+	if opts != nil && opts.Tagged {
+		c._tags = &sheetTagger{_tagger: convertutils.NewTagger()}
+	}
+}
+
+// setPdfOutput applies the PDF/A and tagged output options to the creator.
+func (c *convertContext) setPdfOutput(opts *Options) {
+	if opts == nil || (opts.PdfA == PdfANone && !opts.Tagged) {
+		return
+	}
+	var t *convertutils.Tagger
+	if c._tags != nil {
+		t = c._tags._tagger
+	}
+	convertutils.SetPdfOutput(c._ddaf, opts.PdfA, t, c._ddee.CoreProperties, "")
+}
+
+// tagPage starts tagging the next page.
+func (c *convertContext) tagPage() {
+	if t := c._tags; t != nil {
+		t._tagger.NewPage()
+		t._table = t._tagger.Element(nil, model.StructureTypeTable)
+	}
+}
+
+// tagRow starts the next row of the table of the page.
+func (c *convertContext) tagRow() {
+	if t := c._tags; t != nil {
+		t._row = t._tagger.Element(t._table, model.StructureTypeTableRow)
+	}
+}
+
+// tagCell starts the next cell of the row.
+func (c *convertContext) tagCell() {
+	if t := c._tags; t != nil {
+		t._cell = t._tagger.Element(t._row, model.StructureTypeTableData)
+	}
+}
+
+// tagText marks text of the current cell.
+func (c *convertContext) tagText(sp *creator.StyledParagraph) {
+	if t := c._tags; t != nil {
+		t._tagger.Mark(t._cell, sp)
+	}
+}
+
+// tagImage marks an image or chart drawn on the page as a figure.
+func (c *convertContext) tagImage(img *creator.Image) {
+	if t := c._tags; t != nil {
+		t._tagger.Mark(t._tagger.Figure(nil, ""), img)
+	}
+}
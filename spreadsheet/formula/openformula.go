@@ -0,0 +1,487 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package formula
+
+import (
+	"regexp"
+	"strings"
+)
+
+// OpenFormulaPrefix is the namespace prefix of OpenFormula expressions stored
+// in OpenDocument spreadsheets.
+const OpenFormulaPrefix = "of:"
+
+const (
+	excelFuncPrefix = "_xlfn."
+	odfFuncPrefix   = "COM.MICROSOFT."
+)
+
+var (
+	_cellRefRe = regexp.MustCompile(`^\$?[A-Za-z]{1,3}\$?[0-9]+$`)
+	_colRefRe  = regexp.MustCompile(`^\$?[A-Za-z]{1,3}$`)
+	_rowRefRe  = regexp.MustCompile(`^\$?[0-9]+$`)
+)
+
+type ofTokenKind byte
+
+const (
+	ofTokenWord ofTokenKind = iota
+	ofTokenString
+	ofTokenSheet
+	ofTokenRef
+	ofTokenPunct
+	ofTokenSpace
+)
+
+type ofToken struct {
+	kind ofTokenKind
+	text string
+}
+
+// ToOpenFormula translates an Excel formula such as "SUM(Sheet2!A1:B3,C1)" to
+// its OpenFormula equivalent "of:=SUM([$Sheet2.A1:.B3];[.C1])". A leading '='
+// in the input is optional. The translation is syntactic, functions unknown to
+// the evaluator are passed through unchanged.
+func ToOpenFormula(excel string) string {
+	excel = strings.TrimPrefix(strings.TrimSpace(excel), "=")
+	toks := lexExcelFormula(excel)
+	b := strings.Builder{}
+	b.WriteString(OpenFormulaPrefix + "=")
+	var nesting []byte
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch t.kind {
+		case ofTokenString:
+			b.WriteString(t.text)
+		case ofTokenSheet, ofTokenRef:
+			n := excelReference(toks, i, &b)
+			i += n - 1
+		case ofTokenSpace:
+			// a space between two references is the intersection operator
+			if i > 0 && i+1 < len(toks) && isRefToken(toks[i-1]) && isRefToken(toks[i+1]) {
+				b.WriteByte('!')
+			} else {
+				b.WriteString(t.text)
+			}
+		case ofTokenWord:
+			upper := strings.ToUpper(t.text)
+			isCall := i+1 < len(toks) && toks[i+1].text == "("
+			switch {
+			case isCall && strings.HasPrefix(upper, strings.ToUpper(excelFuncPrefix)):
+				b.WriteString(odfFuncPrefix + upper[len(excelFuncPrefix):])
+			case isCall:
+				b.WriteString(upper)
+			case upper == "TRUE" || upper == "FALSE":
+				b.WriteString(upper + "()")
+			default:
+				b.WriteString(t.text)
+			}
+		case ofTokenPunct:
+			switch t.text {
+			case "(":
+				if i > 0 && toks[i-1].kind == ofTokenWord {
+					nesting = append(nesting, 'f')
+				} else {
+					nesting = append(nesting, 'g')
+				}
+				b.WriteByte('(')
+			case "{":
+				nesting = append(nesting, 'a')
+				b.WriteByte('{')
+			case ")", "}":
+				if len(nesting) > 0 {
+					nesting = nesting[:len(nesting)-1]
+				}
+				b.WriteString(t.text)
+			case ",":
+				switch top(nesting) {
+				case 'f', 'a':
+					b.WriteByte(';')
+				default:
+					b.WriteByte('~')
+				}
+			case ";":
+				if top(nesting) == 'a' {
+					b.WriteByte('|')
+				} else {
+					b.WriteByte(';')
+				}
+			default:
+				b.WriteString(t.text)
+			}
+		}
+	}
+	return b.String()
+}
+
+// FromOpenFormula translates an OpenFormula expression such as
+// "of:=SUM([.A1:.B3])" to the Excel syntax "SUM(A1:B3)". The "of:" namespace
+// prefix and the leading '=' are optional, the result has no leading '='.
+func FromOpenFormula(odf string) string {
+	odf = strings.TrimSpace(odf)
+	if i := strings.Index(odf, ":="); i >= 0 && i < 8 && !strings.ContainsAny(odf[:i], "[(\"") {
+		odf = odf[i+1:]
+	}
+	odf = strings.TrimPrefix(odf, "=")
+	b := strings.Builder{}
+	var nesting []byte
+	prevWord := false
+	for i := 0; i < len(odf); {
+		c := odf[i]
+		word := false
+		switch {
+		case c == '"':
+			j := scanQuoted(odf, i, '"')
+			b.WriteString(odf[i:j])
+			i = j
+		case c == '[':
+			j := i + 1
+			for j < len(odf) && odf[j] != ']' {
+				if odf[j] == '\'' {
+					j = scanQuoted(odf, j, '\'')
+					continue
+				}
+				j++
+			}
+			b.WriteString(odfReference(odf[i+1 : j]))
+			i = j + 1
+		case isFormulaWordStart(c):
+			j := i
+			for j < len(odf) && isFormulaWordChar(odf[j]) {
+				j++
+			}
+			w := odf[i:j]
+			upper := strings.ToUpper(w)
+			switch {
+			case (upper == "TRUE" || upper == "FALSE") && strings.HasPrefix(odf[j:], "()"):
+				b.WriteString(upper)
+				j += 2
+			case strings.HasPrefix(upper, odfFuncPrefix):
+				b.WriteString(excelFuncPrefix + w[len(odfFuncPrefix):])
+			default:
+				b.WriteString(w)
+				word = true
+			}
+			i = j
+		default:
+			switch c {
+			case '(':
+				if prevWord {
+					nesting = append(nesting, 'f')
+				} else {
+					nesting = append(nesting, 'g')
+				}
+			case '{':
+				nesting = append(nesting, 'a')
+			case ')', '}':
+				if len(nesting) > 0 {
+					nesting = nesting[:len(nesting)-1]
+				}
+			}
+			switch {
+			case c == ';':
+				b.WriteByte(',')
+			case c == '|' && top(nesting) == 'a':
+				b.WriteByte(';')
+			case c == '~':
+				b.WriteByte(',')
+			case c == '!':
+				b.WriteByte(' ')
+			default:
+				b.WriteByte(c)
+			}
+			i++
+		}
+		prevWord = word
+	}
+	return b.String()
+}
+
+func top(nesting []byte) byte {
+	if len(nesting) == 0 {
+		return 0
+	}
+	return nesting[len(nesting)-1]
+}
+
+func isRefToken(t ofToken) bool { return t.kind == ofTokenRef || t.kind == ofTokenSheet }
+
+func isFormulaWordStart(c byte) bool {
+	return c == '_' || c == '$' || c == '\\' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isFormulaWordChar(c byte) bool {
+	return isFormulaWordStart(c) || c == '.' || (c >= '0' && c <= '9')
+}
+
+// scanQuoted returns the index after the quoted string starting at i, a
+// doubled quote character is an escaped quote.
+func scanQuoted(s string, i int, q byte) int {
+	for j := i + 1; j < len(s); j++ {
+		if s[j] == q {
+			if j+1 < len(s) && s[j+1] == q {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// lexExcelFormula splits an Excel formula into the tokens needed for the
+// translation, references are recognized syntactically.
+func lexExcelFormula(s string) []ofToken {
+	var toks []ofToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"':
+			j := scanQuoted(s, i, '"')
+			toks = append(toks, ofToken{ofTokenString, s[i:j]})
+			i = j
+		case c == '\'':
+			j := scanQuoted(s, i, '\'')
+			if j < len(s) && s[j] == '!' {
+				toks = append(toks, ofToken{ofTokenSheet, s[i:j]})
+				j++
+			} else {
+				toks = append(toks, ofToken{ofTokenWord, s[i:j]})
+			}
+			i = j
+		case c == '#':
+			j := i + 1
+			for j < len(s) && (isFormulaWordChar(s[j]) || s[j] == '/') {
+				j++
+			}
+			if j < len(s) && (s[j] == '!' || s[j] == '?') {
+				j++
+			}
+			toks = append(toks, ofToken{ofTokenWord, s[i:j]})
+			i = j
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			j := i
+			for j < len(s) && (s[j] == ' ' || s[j] == '\t' || s[j] == '\r' || s[j] == '\n') {
+				j++
+			}
+			toks = append(toks, ofToken{ofTokenSpace, s[i:j]})
+			i = j
+		case isFormulaWordChar(c):
+			j := i
+			for j < len(s) && isFormulaWordChar(s[j]) {
+				j++
+			}
+			// numbers with exponents such as 1E+10
+			if j < len(s) && (s[j] == '+' || s[j] == '-') && isNumber(s[i:j-1]) && (s[j-1] == 'E' || s[j-1] == 'e') {
+				j++
+				for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+					j++
+				}
+			}
+			w := s[i:j]
+			switch {
+			case j < len(s) && s[j] == '!':
+				toks = append(toks, ofToken{ofTokenSheet, w})
+				j++
+			case _cellRefRe.MatchString(w) && !(j < len(s) && s[j] == '('):
+				// words such as LOG10 followed by a parenthesis are functions
+				toks = append(toks, ofToken{ofTokenRef, w})
+			default:
+				toks = append(toks, ofToken{ofTokenWord, w})
+			}
+			i = j
+		default:
+			n := 1
+			if i+1 < len(s) && (s[i:i+2] == "<=" || s[i:i+2] == ">=" || s[i:i+2] == "<>") {
+				n = 2
+			}
+			toks = append(toks, ofToken{ofTokenPunct, s[i : i+n]})
+			i += n
+		}
+	}
+	// sheet ranges of 3D references such as Sheet1:Sheet3!A1
+	for i := 0; i+2 < len(toks); i++ {
+		if toks[i].kind == ofTokenWord && toks[i+1].text == ":" && toks[i+2].kind == ofTokenSheet {
+			toks[i] = ofToken{ofTokenSheet, toks[i].text + ":" + toks[i+2].text}
+			toks = append(toks[:i+1], toks[i+3:]...)
+		}
+	}
+	// whole column and row ranges such as A:C and 1:3
+	for i := 0; i+2 < len(toks); i++ {
+		if toks[i+1].text != ":" {
+			continue
+		}
+		l, r := toks[i].text, toks[i+2].text
+		if (_colRefRe.MatchString(l) && _colRefRe.MatchString(r)) || (_rowRefRe.MatchString(l) && _rowRefRe.MatchString(r)) {
+			toks[i].kind, toks[i+2].kind = ofTokenRef, ofTokenRef
+		}
+	}
+	return toks
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && s[i] != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// excelReference writes the OpenFormula form of the reference starting at
+// toks[i] and returns the number of tokens consumed.
+func excelReference(toks []ofToken, i int, b *strings.Builder) int {
+	start := i
+	sheet := ""
+	if toks[i].kind == ofTokenSheet {
+		sheet = toks[i].text
+		i++
+		if i >= len(toks) || toks[i].kind == ofTokenSpace {
+			b.WriteString(sheet + "!")
+			return i - start
+		}
+	}
+	first, last := toks[i].text, ""
+	i++
+	if i+1 < len(toks) && toks[i].text == ":" && toks[i+1].kind == ofTokenRef {
+		last = toks[i+1].text
+		i += 2
+	}
+	b.WriteByte('[')
+	firstSheet, lastSheet := splitSheetRange(sheet)
+	if firstSheet != "" {
+		b.WriteString("$" + odfSheetName(firstSheet))
+	}
+	b.WriteString("." + first)
+	switch {
+	case lastSheet != "":
+		// a 3D reference spans the cells from the first to the last sheet
+		if last == "" {
+			last = first
+		}
+		b.WriteString(":$" + odfSheetName(lastSheet) + "." + last)
+	case last != "":
+		b.WriteString(":." + last)
+	}
+	b.WriteByte(']')
+	return i - start
+}
+
+// splitSheetRange splits the sheet part of a 3D reference such as
+// "Sheet1:Sheet3" or "'Sheet 1:Sheet 3'" into its first and last sheet, last
+// is empty for a single sheet.
+func splitSheetRange(sheet string) (string, string) {
+	quoted := strings.HasPrefix(sheet, "'") && strings.HasSuffix(sheet, "'") && len(sheet) > 1
+	name := sheet
+	if quoted {
+		name = strings.ReplaceAll(sheet[1:len(sheet)-1], "''", "'")
+	}
+	colon := strings.Index(name, ":")
+	if colon < 0 {
+		return sheet, ""
+	}
+	return name[:colon], name[colon+1:]
+}
+
+// odfSheetName quotes a sheet name for use in an OpenFormula reference if
+// needed.
+func odfSheetName(s string) string {
+	if strings.HasPrefix(s, "'") || !strings.ContainsAny(s, " .-'") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// odfReference converts the contents of an OpenFormula reference, e.g.
+// "$Sheet1.A1:.B2", to Excel syntax.
+func odfReference(ref string) string {
+	var parts []string
+	last := 0
+	for i := 0; i < len(ref); i++ {
+		switch ref[i] {
+		case '\'':
+			i = scanQuoted(ref, i, '\'') - 1
+		case ':':
+			parts = append(parts, ref[last:i])
+			last = i + 1
+		}
+	}
+	parts = append(parts, ref[last:])
+	var sheets, cells []string
+	for _, p := range parts {
+		sheet, cell := "", p
+		if dot := lastDotOutsideQuotes(p); dot >= 0 {
+			sheet, cell = strings.TrimPrefix(p[:dot], "$"), p[dot+1:]
+		}
+		sheets = append(sheets, sheet)
+		cells = append(cells, cell)
+	}
+	for _, c := range cells {
+		if strings.HasPrefix(c, "#REF") {
+			return "#REF!"
+		}
+	}
+	b := strings.Builder{}
+	if sheets[0] != "" {
+		if len(sheets) > 1 && sheets[1] != "" && sheets[1] != sheets[0] {
+			// Excel quotes both sheets of a 3D reference together
+			first, last := unquoteSheet(sheets[0]), unquoteSheet(sheets[1])
+			if excelSheetName(first) != first || excelSheetName(last) != last {
+				b.WriteString(excelSheetName(first + ":" + last))
+			} else {
+				b.WriteString(first + ":" + last)
+			}
+		} else {
+			b.WriteString(excelSheetName(sheets[0]))
+		}
+		b.WriteByte('!')
+	}
+	if len(cells) == 2 && cells[0] == cells[1] && len(sheets) == 2 && sheets[1] != "" && sheets[1] != sheets[0] {
+		// a single cell on a range of sheets
+		cells = cells[:1]
+	}
+	b.WriteString(strings.Join(cells, ":"))
+	return b.String()
+}
+
+func lastDotOutsideQuotes(s string) int {
+	dot := -1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			i = scanQuoted(s, i, '\'') - 1
+		case '.':
+			dot = i
+		}
+	}
+	return dot
+}
+
+func unquoteSheet(s string) string {
+	if len(s) > 1 && strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
+
+func excelSheetName(s string) string {
+	if strings.HasPrefix(s, "'") {
+		return s
+	}
+	for _, r := range s {
+		if !(r == '_' || r == '.' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+			return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+		}
+	}
+	return s
+}
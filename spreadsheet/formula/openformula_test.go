@@ -0,0 +1,55 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package formula
+
+import "testing"
+
+func TestToOpenFormula(t *testing.T) {
+	td := []struct {
+		excel string
+		odf   string
+	}{
+		{"A1+B2", "of:=[.A1]+[.B2]"},
+		{"SUM(A1:B3,C1)", "of:=SUM([.A1:.B3];[.C1])"},
+		{"$A$1*2", "of:=[.$A$1]*2"},
+		{"Sheet2!A1:B3", "of:=[$Sheet2.A1:.B3]"},
+		{"'My Sheet'!C4", "of:=[$'My Sheet'.C4]"},
+		{"LOG10(A1)", "of:=LOG10([.A1])"},
+		{"ATAN2(1,2)", "of:=ATAN2(1;2)"},
+		{"SUM(Sheet1:Sheet3!A1)", "of:=SUM([$Sheet1.A1:$Sheet3.A1])"},
+		{"SUM(Sheet1:Sheet3!A1:B2)", "of:=SUM([$Sheet1.A1:$Sheet3.B2])"},
+		{"'My Sheet:Other'!B2", "of:=[$'My Sheet'.B2:$Other.B2]"},
+		{"\"a,b\"&A1", "of:=\"a,b\"&[.A1]"},
+	}
+	for _, tc := range td {
+		if got := ToOpenFormula(tc.excel); got != tc.odf {
+			t.Errorf("ToOpenFormula(%q) = %q, expected %q", tc.excel, got, tc.odf)
+		}
+	}
+}
+
+func TestOpenFormulaRoundTrip(t *testing.T) {
+	td := []string{
+		"A1+B2",
+		"SUM(A1:B3,C1)",
+		"Sheet2!A1:B3",
+		"'My Sheet'!C4",
+		"LOG10(A1)",
+		"IF(A1>0,\"yes\",\"no\")",
+		"SUM(Sheet1:Sheet3!A1)",
+		"SUM(Sheet1:Sheet3!A1:B2)",
+		"'My Sheet:Other'!B2+1",
+	}
+	for _, f := range td {
+		if got := FromOpenFormula(ToOpenFormula(f)); got != f {
+			t.Errorf("round trip of %q gave %q", f, got)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package spreadsheet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/sml"
+)
+
+// Inspect reports the personal information and hidden data in the workbook
+// without changing it, see RemovePersonalInformation.
+func (wb *Workbook) Inspect() ([]common.InspectionFinding, error) {
+	return wb.inspect(common.NewInspector(false))
+}
+
+// RemovePersonalInformation removes the given kinds of personal information
+// and hidden data, or all kinds if none are given, and returns the findings.
+// It covers the document properties, comment authors, the revision log of
+// shared workbooks, printer settings, the thumbnail and file paths of
+// external links. Hidden sheets, rows and columns are only reported. Comment
+// authors are replaced by "Author".
+func (wb *Workbook) RemovePersonalInformation(kinds ...common.InspectionKind) ([]common.InspectionFinding, error) {
+	return wb.inspect(common.NewInspector(true, kinds...))
+}
+
+func (wb *Workbook) inspect(i *common.Inspector) ([]common.InspectionFinding, error) {
+	if err := i.DocBase(&wb.DocBase); err != nil {
+		return i.Findings, err
+	}
+	i.Relationships("workbook", wb._ffea)
+	if err := i.ExtraRelationships(&wb.DocBase, func(zipPath string) bool {
+		return strings.HasPrefix(zipPath, "xl/externalLinks/")
+	}); err != nil {
+		return i.Findings, err
+	}
+
+	var sheets []*sml.CT_Sheet
+	if wb.X().Sheets != nil {
+		sheets = wb.X().Sheets.Sheet
+	}
+	for n, s := range wb.Sheets() {
+		name := s.Name()
+		if n < len(sheets) {
+			switch sheets[n].StateAttr {
+			case sml.ST_SheetStateHidden:
+				i.Found(common.InspectionHiddenContent, name, "hidden sheet")
+			case sml.ST_SheetStateVeryHidden:
+				i.Found(common.InspectionHiddenContent, name, "very hidden sheet")
+			}
+		}
+		ws := s.X()
+		rows, cols := 0, 0
+		if ws.SheetData != nil {
+			for _, r := range ws.SheetData.Row {
+				if r.HiddenAttr != nil && *r.HiddenAttr {
+					rows++
+				}
+			}
+		}
+		for _, c := range ws.Cols {
+			for _, col := range c.Col {
+				if col.HiddenAttr != nil && *col.HiddenAttr {
+					cols += int(col.MaxAttr-col.MinAttr) + 1
+				}
+			}
+		}
+		if rows > 0 || cols > 0 {
+			i.Found(common.InspectionHiddenContent, name, fmt.Sprintf("%d hidden rows and %d hidden columns", rows, cols))
+		}
+		if n < len(wb._ade) {
+			rels := wb._ade[n]
+			i.Relationships(name, rels)
+			// drop the reference to removed printer settings
+			if ps := ws.PageSetup; ps != nil && ps.IdAttr != nil && rels.GetByRelId(*ps.IdAttr).X() == nil {
+				ps.IdAttr = nil
+			}
+		}
+	}
+
+	for n, cmts := range wb._gfcbc {
+		if cmts.Authors == nil {
+			continue
+		}
+		for a, author := range cmts.Authors.Author {
+			if author == "" {
+				continue
+			}
+			if i.Found(common.InspectionCommentAuthors, fmt.Sprintf("comments %d", n+1), author) {
+				cmts.Authors.Author[a] = "Author"
+			}
+		}
+	}
+
+	for _, ef := range wb.ExtraFiles {
+		if strings.HasPrefix(ef.ZipPath, "xl/revisions/") {
+			if i.Found(common.InspectionRevisionAuthors, "workbook", "revision log of a shared workbook") {
+				wb.removeRevisions()
+			}
+			break
+		}
+	}
+	return i.Findings, nil
+}
@@ -0,0 +1,78 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+/*
+Package ods provides conversion between OpenDocument spreadsheets (.ods) and
+spreadsheet.Workbook.
+
+Sheets, cell values, formulas, merged cells, column widths, row heights,
+basic cell formatting, named ranges and the document metadata are converted
+in both directions.  Formulas are translated between the Excel syntax and
+OpenFormula with the functions of the formula package.
+
+Example:
+
+	wb, err := ods.Open("budget.ods")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer wb.Close()
+	wb.SaveToFile("budget.xlsx")
+*/
+package ods
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/unidoc/unioffice/v2/internal/odf"
+	"github.com/unidoc/unioffice/v2/spreadsheet"
+)
+
+// Open opens and reads an OpenDocument spreadsheet into a new workbook.
+func Open(filename string) (*spreadsheet.Workbook, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Read(f, fi.Size())
+}
+
+// Read reads an OpenDocument spreadsheet from r and builds a new workbook.
+func Read(r io.ReaderAt, size int64) (*spreadsheet.Workbook, error) {
+	pkg, err := odf.ReadPackage(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return newReader(pkg).read()
+}
+
+// Write writes the workbook wb to w as an OpenDocument spreadsheet.
+func Write(w io.Writer, wb *spreadsheet.Workbook) error {
+	pkg, err := newWriter(wb).write()
+	if err != nil {
+		return err
+	}
+	return pkg.Write(w)
+}
+
+// SaveToFile writes the workbook wb to an OpenDocument spreadsheet at path.
+func SaveToFile(wb *spreadsheet.Workbook, path string) error {
+	buf := bytes.Buffer{}
+	if err := Write(&buf, wb); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,443 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package ods
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unidoc/unioffice/v2/color"
+	"github.com/unidoc/unioffice/v2/internal/odf"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/sml"
+	"github.com/unidoc/unioffice/v2/spreadsheet"
+	"github.com/unidoc/unioffice/v2/spreadsheet/formula"
+	"github.com/unidoc/unioffice/v2/spreadsheet/reference"
+)
+
+// maxRepeat limits repeated rows and cells with content, OpenDocument
+// producers use huge repeat counts for trailing empty cells.
+const maxRepeat = 1024
+
+type reader struct {
+	pkg        *odf.Package
+	wb         *spreadsheet.Workbook
+	styles     *odf.Styles
+	cellStyles map[string]spreadsheet.CellStyle
+}
+
+func newReader(pkg *odf.Package) *reader {
+	return &reader{pkg: pkg, cellStyles: map[string]spreadsheet.CellStyle{}}
+}
+
+func (r *reader) read() (*spreadsheet.Workbook, error) {
+	if r.pkg.MimeType != odf.MimeTypeSpreadsheet && r.pkg.MimeType != odf.MimeTypeSpreadsheet+"-template" {
+		return nil, fmt.Errorf("unsupported mime type %s", r.pkg.MimeType)
+	}
+	content, err := r.pkg.Part("content.xml")
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		return nil, errors.New("missing content.xml")
+	}
+	styles, err := r.pkg.Part("styles.xml")
+	if err != nil {
+		return nil, err
+	}
+	meta, err := r.pkg.Part("meta.xml")
+	if err != nil {
+		return nil, err
+	}
+	r.styles = odf.ParseStyles(styles, content)
+	r.wb = spreadsheet.New()
+	odf.ParseMeta(meta).ApplyCore(r.wb.CoreProperties)
+
+	body := content.Child(odf.NSOffice, "body").Child(odf.NSOffice, "spreadsheet")
+	if body == nil {
+		return nil, errors.New("missing office:spreadsheet")
+	}
+	for _, t := range body.ChildrenNamed(odf.NSTable, "table") {
+		r.table(t)
+	}
+	if len(r.wb.Sheets()) == 0 {
+		r.wb.AddSheet()
+	}
+	for _, ne := range body.ChildrenNamed(odf.NSTable, "named-expressions") {
+		r.namedExpressions(ne)
+	}
+	return r.wb, nil
+}
+
+// column is a column definition expanded from table:table-column.
+type column struct {
+	width     measurement.Distance
+	hidden    bool
+	cellStyle string
+}
+
+func (r *reader) table(t *odf.Node) {
+	sheet := r.wb.AddSheet()
+	if name := t.AttrValue(odf.NSTable, "name"); name != "" {
+		sheet.SetName(name)
+	}
+	var cols []column
+	var rows []*odf.Node
+	var collect func(n *odf.Node)
+	collect = func(n *odf.Node) {
+		for _, c := range n.Children {
+			switch {
+			case c.Is(odf.NSTable, "table-column"):
+				col := column{cellStyle: c.AttrValue(odf.NSTable, "default-cell-style-name"),
+					hidden: c.AttrValue(odf.NSTable, "visibility") == "collapse"}
+				col.width, _ = odf.ParseLength(r.styles.Lookup(c.AttrValue(odf.NSTable, "style-name"),
+					"table-column-properties", "style:column-width"))
+				n := count(c, "number-columns-repeated")
+				if n > maxRepeat {
+					n = maxRepeat
+				}
+				for i := 0; i < n; i++ {
+					cols = append(cols, col)
+				}
+			case c.Is(odf.NSTable, "table-row"):
+				rows = append(rows, c)
+			case c.Is(odf.NSTable, "table-columns"), c.Is(odf.NSTable, "table-header-columns"),
+				c.Is(odf.NSTable, "table-column-group"), c.Is(odf.NSTable, "table-rows"),
+				c.Is(odf.NSTable, "table-header-rows"), c.Is(odf.NSTable, "table-row-group"):
+				collect(c)
+			}
+		}
+	}
+	collect(t)
+
+	rowNum := uint32(1)
+	maxCol := uint32(0)
+	for _, rn := range rows {
+		n := count(rn, "number-rows-repeated")
+		if !hasContent(rn) {
+			rowNum += uint32(n)
+			continue
+		}
+		// only the first repeats are created but the following rows keep
+		// their position
+		for i := 0; i < n && i < maxRepeat; i++ {
+			if last := r.row(sheet, rn, rowNum+uint32(i), cols); last > maxCol {
+				maxCol = last
+			}
+		}
+		rowNum += uint32(n)
+	}
+	for i, col := range cols {
+		if uint32(i) >= maxCol {
+			break
+		}
+		if col.width > 0 || col.hidden {
+			c := sheet.Column(uint32(i + 1))
+			if col.width > 0 {
+				c.SetWidth(col.width)
+			}
+			if col.hidden {
+				c.SetHidden(true)
+			}
+		}
+	}
+}
+
+// hasContent reports whether a row or cell has values, formulas, styles or
+// spans that need to be converted.
+func hasContent(n *odf.Node) bool {
+	for _, a := range n.Attr {
+		if a.Name.Space == odf.NSOffice || (a.Name.Space == odf.NSTable && a.Name.Local != "number-columns-repeated" &&
+			a.Name.Local != "number-rows-repeated" && a.Name.Local != "style-name") {
+			return true
+		}
+	}
+	for _, c := range n.Children {
+		if !c.IsElement() {
+			continue
+		}
+		if c.Is(odf.NSTable, "table-cell") || c.Is(odf.NSTable, "covered-table-cell") {
+			if hasContent(c) {
+				return true
+			}
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// row converts a table row and returns the number of columns it uses.
+func (r *reader) row(sheet spreadsheet.Sheet, n *odf.Node, rowNum uint32, cols []column) uint32 {
+	row := sheet.AddNumberedRow(rowNum)
+	rs := n.AttrValue(odf.NSTable, "style-name")
+	if r.styles.Lookup(rs, "table-row-properties", "style:use-optimal-row-height") != "true" {
+		if h, ok := odf.ParseLength(r.styles.Lookup(rs, "table-row-properties", "style:row-height")); ok {
+			row.SetHeight(h)
+		}
+	}
+	if n.AttrValue(odf.NSTable, "visibility") == "collapse" {
+		row.SetHidden(true)
+	}
+	col := uint32(0)
+	used := uint32(0)
+	for _, c := range n.Children {
+		if !c.Is(odf.NSTable, "table-cell") && !c.Is(odf.NSTable, "covered-table-cell") {
+			continue
+		}
+		cnt := count(c, "number-columns-repeated")
+		// formatted empty cells are kept unless repeated, repeats usually
+		// fill the row to the last column
+		empty := !hasContent(c) && (c.AttrValue(odf.NSTable, "style-name") == "" || cnt > 1)
+		if c.Is(odf.NSTable, "covered-table-cell") || empty {
+			col += uint32(cnt)
+			continue
+		}
+		for i := 0; i < cnt && i < maxRepeat; i++ {
+			style := c.AttrValue(odf.NSTable, "style-name")
+			if style == "" && int(col)+i < len(cols) {
+				style = cols[int(col)+i].cellStyle
+			}
+			r.cell(sheet, row, c, col+uint32(i), style)
+			used = col + uint32(i) + 1
+		}
+		col += uint32(cnt)
+	}
+	return used
+}
+
+func (r *reader) cell(sheet spreadsheet.Sheet, row spreadsheet.Row, n *odf.Node, col uint32, style string) {
+	ref := fmt.Sprintf("%s%d", reference.IndexToColumn(col), row.RowNumber())
+	cell := row.AddNamedCell(reference.IndexToColumn(col))
+	if cs, ok := r.cellStyle(style); ok {
+		cell.SetStyle(cs)
+	}
+	if cs := count(n, "number-columns-spanned"); cs > 1 || count(n, "number-rows-spanned") > 1 {
+		rs := count(n, "number-rows-spanned")
+		to := fmt.Sprintf("%s%d", reference.IndexToColumn(col+uint32(cs)-1), row.RowNumber()+uint32(rs)-1)
+		sheet.AddMergedCells(ref, to)
+	}
+
+	valueType := n.AttrValue(odf.NSOffice, "value-type")
+	if f := n.AttrValue(odf.NSTable, "formula"); f != "" {
+		cell.SetFormulaRaw(formula.FromOpenFormula(f))
+		if cell.HasFormula() {
+			// keep the computed value as the cached result
+			switch valueType {
+			case "float", "percentage", "currency":
+				cell.SetCachedFormulaResult(n.AttrValue(odf.NSOffice, "value"))
+				cell.X().TAttr = sml.ST_CellTypeUnset
+			case "boolean":
+				v := "0"
+				if n.AttrValue(odf.NSOffice, "boolean-value") == "true" {
+					v = "1"
+				}
+				cell.SetCachedFormulaResult(v)
+				cell.X().TAttr = sml.ST_CellTypeB
+			case "string":
+				cell.SetCachedFormulaResult(r.text(n))
+			}
+			return
+		}
+	}
+	switch valueType {
+	case "float", "percentage", "currency":
+		if v, err := strconv.ParseFloat(n.AttrValue(odf.NSOffice, "value"), 64); err == nil {
+			if valueType == "percentage" && cell.X().SAttr == nil {
+				cell.SetNumberWithStyle(v, spreadsheet.StandardFormatPercent)
+			} else {
+				cell.SetNumber(v)
+			}
+		}
+	case "date":
+		if t, ok := parseDate(n.AttrValue(odf.NSOffice, "date-value")); ok {
+			if cell.X().SAttr == nil {
+				cell.SetDateWithStyle(t)
+			} else {
+				cell.SetDate(t)
+			}
+		}
+	case "time":
+		if d, ok := parseDuration(n.AttrValue(odf.NSOffice, "time-value")); ok {
+			cell.SetNumber(d.Hours() / 24)
+		}
+	case "boolean":
+		cell.SetBool(n.AttrValue(odf.NSOffice, "boolean-value") == "true")
+	default:
+		if s := r.text(n); s != "" {
+			cell.SetString(s)
+		}
+	}
+}
+
+// text returns the text of the paragraphs of a cell.
+func (r *reader) text(n *odf.Node) string {
+	if v := n.AttrValue(odf.NSOffice, "string-value"); v != "" {
+		return v
+	}
+	var lines []string
+	for _, p := range n.ChildrenNamed(odf.NSText, "p") {
+		lines = append(lines, paragraphText(p))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func paragraphText(n *odf.Node) string {
+	b := strings.Builder{}
+	for _, c := range n.Children {
+		switch {
+		case !c.IsElement():
+			b.WriteString(c.Text)
+		case c.Is(odf.NSText, "s"):
+			cnt := 1
+			if v, err := strconv.Atoi(c.AttrValue(odf.NSText, "c")); err == nil && v > 0 {
+				cnt = v
+			}
+			b.WriteString(strings.Repeat(" ", cnt))
+		case c.Is(odf.NSText, "tab"):
+			b.WriteByte('\t')
+		case c.Is(odf.NSText, "line-break"):
+			b.WriteByte('\n')
+		case c.Is(odf.NSOffice, "annotation"):
+		default:
+			b.WriteString(paragraphText(c))
+		}
+	}
+	return b.String()
+}
+
+func parseDate(s string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02T15:04:05.999999999", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseDuration parses an ISO 8601 duration such as PT12H30M00S.
+func parseDuration(s string) (time.Duration, bool) {
+	if !strings.HasPrefix(s, "PT") {
+		return 0, false
+	}
+	d, err := time.ParseDuration(strings.ToLower(strings.TrimPrefix(s, "PT")))
+	return d, err == nil
+}
+
+// cellStyle returns the SpreadsheetML cell style for an OpenDocument cell
+// style, styles are created once and cached.
+func (r *reader) cellStyle(name string) (spreadsheet.CellStyle, bool) {
+	if name == "" || name == "Default" {
+		return spreadsheet.CellStyle{}, false
+	}
+	if cs, ok := r.cellStyles[name]; ok {
+		return cs, true
+	}
+	prop := func(kind, attr string) string { return r.styles.Lookup(name, kind, attr) }
+	text := func(attr string) string { return prop("text-properties", attr) }
+	cellProp := func(attr string) string { return prop("table-cell-properties", attr) }
+
+	bold := text("fo:font-weight") == "bold"
+	italic := text("fo:font-style") == "italic"
+	fontColor, hasColor := odf.ParseColor(text("fo:color"))
+	size, hasSize := odf.ParseLength(text("fo:font-size"))
+	background, hasBackground := odf.ParseColor(cellProp("fo:background-color"))
+	align := prop("paragraph-properties", "fo:text-align")
+	valign := cellProp("style:vertical-align")
+	wrap := cellProp("fo:wrap-option") == "wrap"
+	if !bold && !italic && !hasColor && !hasSize && !hasBackground && align == "" && valign == "" && !wrap {
+		return spreadsheet.CellStyle{}, false
+	}
+
+	cs := r.wb.StyleSheet.AddCellStyle()
+	if bold || italic || hasColor || hasSize {
+		font := r.wb.StyleSheet.AddFont()
+		font.SetName("Calibri")
+		font.SetSize(11)
+		if bold {
+			font.SetBold(true)
+		}
+		if italic {
+			font.SetItalic(true)
+		}
+		if hasColor {
+			font.SetColor(color.FromHex(fontColor))
+		}
+		if hasSize {
+			font.SetSize(float64(size / measurement.Point))
+		}
+		cs.SetFont(font)
+	}
+	if hasBackground {
+		fill := r.wb.StyleSheet.Fills().AddFill()
+		pf := fill.SetPatternFill()
+		pf.SetPattern(sml.ST_PatternTypeSolid)
+		pf.SetFgColor(color.FromHex(background))
+		cs.SetFill(fill)
+	}
+	switch align {
+	case "center":
+		cs.SetHorizontalAlignment(sml.ST_HorizontalAlignmentCenter)
+	case "end", "right":
+		cs.SetHorizontalAlignment(sml.ST_HorizontalAlignmentRight)
+	case "start", "left":
+		cs.SetHorizontalAlignment(sml.ST_HorizontalAlignmentLeft)
+	case "justify":
+		cs.SetHorizontalAlignment(sml.ST_HorizontalAlignmentJustify)
+	}
+	switch valign {
+	case "top":
+		cs.SetVerticalAlignment(sml.ST_VerticalAlignmentTop)
+	case "middle":
+		cs.SetVerticalAlignment(sml.ST_VerticalAlignmentCenter)
+	case "bottom":
+		cs.SetVerticalAlignment(sml.ST_VerticalAlignmentBottom)
+	}
+	if wrap {
+		cs.SetWrapped(true)
+	}
+	r.cellStyles[name] = cs
+	return cs, true
+}
+
+// namedExpressions converts named ranges and expressions to defined names.
+func (r *reader) namedExpressions(n *odf.Node) {
+	for _, c := range n.Children {
+		name := c.AttrValue(odf.NSTable, "name")
+		if name == "" {
+			continue
+		}
+		switch {
+		case c.Is(odf.NSTable, "named-range"):
+			rng := strings.TrimPrefix(c.AttrValue(odf.NSTable, "cell-range-address"), "$")
+			if rng == "" {
+				continue
+			}
+			r.wb.AddDefinedName(name, formula.FromOpenFormula("[$"+rng+"]"))
+		case c.Is(odf.NSTable, "named-expression"):
+			if expr := c.AttrValue(odf.NSTable, "expression"); expr != "" {
+				r.wb.AddDefinedName(name, formula.FromOpenFormula(expr))
+			}
+		}
+	}
+}
+
+// count returns the positive integer value of a table repeat or span
+// attribute, defaulting to 1.
+func count(n *odf.Node, attr string) int {
+	v, err := strconv.Atoi(n.AttrValue(odf.NSTable, attr))
+	if err != nil || v < 1 {
+		return 1
+	}
+	return v
+}
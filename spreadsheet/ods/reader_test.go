@@ -0,0 +1,93 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package ods
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unioffice/v2/internal/odf"
+)
+
+// readTable reads a package with a single table made of the given rows.
+func readTable(t *testing.T, rows string) map[string]string {
+	w := odf.NewWriter()
+	w.StartRoot("office:document-content")
+	w.Start("office:body")
+	w.Start("office:spreadsheet")
+	w.Start("table:table", "table:name", "Sheet1")
+	w.Raw([]byte(rows))
+	w.End()
+	w.End()
+	w.End()
+	w.End()
+	pkg := odf.NewPackage(odf.MimeTypeSpreadsheet)
+	pkg.Add("content.xml", w.Bytes(), "")
+	buf := bytes.Buffer{}
+	if err := pkg.Write(&buf); err != nil {
+		t.Fatalf("error writing package: %s", err)
+	}
+	wb, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("error reading package: %s", err)
+	}
+	res := map[string]string{}
+	for _, row := range wb.Sheets()[0].Rows() {
+		for _, c := range row.Cells() {
+			if s := c.GetString(); s != "" {
+				res[c.Reference()] = s
+			}
+		}
+	}
+	return res
+}
+
+func cell(text string, attrs ...string) string {
+	return `<table:table-cell office:value-type="string" ` + strings.Join(attrs, " ") +
+		`><text:p>` + text + `</text:p></table:table-cell>`
+}
+
+func TestRepeatedRows(t *testing.T) {
+	td := []struct {
+		name string
+		rows string
+		exp  map[string]string
+	}{
+		{"empty rows", `<table:table-row table:number-rows-repeated="3"><table:table-cell/></table:table-row>` +
+			`<table:table-row>` + cell("x") + `</table:table-row>`,
+			map[string]string{"A4": "x"}},
+		{"repeated content", `<table:table-row table:number-rows-repeated="2">` + cell("a") + `</table:table-row>` +
+			`<table:table-row>` + cell("b") + `</table:table-row>`,
+			map[string]string{"A1": "a", "A2": "a", "A3": "b"}},
+		{"capped repeat", `<table:table-row table:number-rows-repeated="2000">` + cell("a") + `</table:table-row>` +
+			`<table:table-row>` + cell("b") + `</table:table-row>`,
+			map[string]string{"A1": "a", "A1024": "a", "A2001": "b"}},
+		{"repeated cells", `<table:table-row>` + cell("a", `table:number-columns-repeated="2"`) +
+			`<table:table-cell table:number-columns-repeated="3"/>` + cell("b") + `</table:table-row>`,
+			map[string]string{"A1": "a", "B1": "a", "F1": "b"}},
+		{"capped cells", `<table:table-row>` + cell("a", `table:number-columns-repeated="2000"`) +
+			cell("b") + `</table:table-row>`,
+			map[string]string{"A1": "a", "AMJ1": "a", "BXY1": "b"}},
+	}
+	for _, tc := range td {
+		got := readTable(t, tc.rows)
+		for ref, exp := range tc.exp {
+			if got[ref] != exp {
+				t.Errorf("%s: expected %s = %q, got %q", tc.name, ref, exp, got[ref])
+			}
+		}
+		if tc.name == "capped repeat" {
+			if _, ok := got["A1025"]; ok {
+				t.Errorf("%s: expected repeats past %d to be dropped", tc.name, maxRepeat)
+			}
+		}
+	}
+}
@@ -0,0 +1,479 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package ods
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/internal/odf"
+	"github.com/unidoc/unioffice/v2/measurement"
+	"github.com/unidoc/unioffice/v2/schema/soo/sml"
+	"github.com/unidoc/unioffice/v2/spreadsheet"
+	"github.com/unidoc/unioffice/v2/spreadsheet/formula"
+	"github.com/unidoc/unioffice/v2/spreadsheet/reference"
+)
+
+// writer converts a workbook to an OpenDocument spreadsheet package.
+type writer struct {
+	wb         *spreadsheet.Workbook
+	x          *odf.Writer
+	styles     *odf.StyleSet
+	cellStyles map[uint32]string
+}
+
+// merge is a merged cell range, keyed by its top left cell.
+type merge struct {
+	cols, rows uint32
+}
+
+type cellPos struct {
+	row, col uint32
+}
+
+func newWriter(wb *spreadsheet.Workbook) *writer {
+	return &writer{wb: wb, styles: odf.NewStyleSet(), cellStyles: map[uint32]string{}}
+}
+
+func (w *writer) write() (*odf.Package, error) {
+	pkg := odf.NewPackage(odf.MimeTypeSpreadsheet)
+	w.x = odf.NewFragmentWriter()
+	for _, sheet := range w.wb.Sheets() {
+		w.sheet(sheet)
+	}
+	w.namedExpressions()
+
+	content := odf.NewWriter()
+	content.StartRoot("office:document-content")
+	content.Start("office:automatic-styles")
+	content.Raw(w.styles.Bytes())
+	content.End()
+	content.Start("office:body")
+	content.Start("office:spreadsheet")
+	content.Raw(w.x.Bytes())
+	pkg.Add("content.xml", content.Bytes(), "text/xml")
+	pkg.Add("styles.xml", stylesPart(), "text/xml")
+	pkg.Add("meta.xml", odf.MetaFromCore(w.wb.CoreProperties).Bytes(), "text/xml")
+	return pkg, nil
+}
+
+// stylesPart builds styles.xml with the default cell style.
+func stylesPart() []byte {
+	x := odf.NewWriter()
+	x.StartRoot("office:document-styles")
+	x.Start("office:styles")
+	x.Start("style:default-style", "style:family", "table-cell")
+	x.Empty("style:text-properties", "style:font-name", "Calibri", "fo:font-family", "Calibri", "fo:font-size", "11pt")
+	x.End()
+	x.Empty("style:style", "style:name", "Default", "style:family", "table-cell")
+	return x.Bytes()
+}
+
+func (w *writer) sheet(sheet spreadsheet.Sheet) {
+	ws := sheet.X()
+	tableStyle := w.styles.Add("ta", "table", "", odf.Props{Element: "style:table-properties",
+		Attrs: []string{"table:display", "true"}})
+	w.x.Start("table:table", "table:name", sheet.Name(), "table:style-name", tableStyle)
+
+	merges := map[cellPos]merge{}
+	covered := map[cellPos]bool{}
+	maxCol := uint32(0)
+	for _, mc := range sheet.MergedCells() {
+		from, to, err := reference.ParseRangeReference(mc.Reference())
+		if err != nil {
+			continue
+		}
+		merges[cellPos{from.RowIdx, from.ColumnIdx}] = merge{cols: to.ColumnIdx - from.ColumnIdx + 1, rows: to.RowIdx - from.RowIdx + 1}
+		for r := from.RowIdx; r <= to.RowIdx; r++ {
+			for c := from.ColumnIdx; c <= to.ColumnIdx; c++ {
+				if r != from.RowIdx || c != from.ColumnIdx {
+					covered[cellPos{r, c}] = true
+				}
+			}
+		}
+		if to.ColumnIdx+1 > maxCol {
+			maxCol = to.ColumnIdx + 1
+		}
+	}
+	rows := sheet.Rows()
+	for _, row := range rows {
+		for _, c := range row.Cells() {
+			if ref, err := reference.ParseCellReference(c.Reference()); err == nil && ref.ColumnIdx+1 > maxCol {
+				maxCol = ref.ColumnIdx + 1
+			}
+		}
+	}
+	if maxCol == 0 {
+		maxCol = 1
+	}
+	w.columns(ws, maxCol)
+
+	next := uint32(1)
+	for _, row := range rows {
+		rn := row.RowNumber()
+		if rn < next {
+			continue
+		}
+		if rn > next {
+			w.emptyRows(rn-next, maxCol)
+		}
+		next = rn + 1
+		w.row(row, maxCol, merges, covered)
+	}
+	if next == 1 {
+		w.emptyRows(1, maxCol)
+	}
+	w.x.End()
+}
+
+// columns writes the column definitions, runs of columns with the same
+// width share a table:table-column element.
+func (w *writer) columns(ws *sml.Worksheet, count uint32) {
+	styles := make([]string, count)
+	hidden := make([]bool, count)
+	def := w.columnStyle(nil)
+	for i := range styles {
+		styles[i] = def
+	}
+	for _, cols := range ws.Cols {
+		for _, col := range cols.Col {
+			style := w.columnStyle(col.WidthAttr)
+			for i := col.MinAttr; i <= col.MaxAttr && i <= count; i++ {
+				if i == 0 {
+					continue
+				}
+				styles[i-1] = style
+				hidden[i-1] = col.HiddenAttr != nil && *col.HiddenAttr
+			}
+		}
+	}
+	for i := 0; i < len(styles); {
+		j := i + 1
+		for j < len(styles) && styles[j] == styles[i] && hidden[j] == hidden[i] {
+			j++
+		}
+		attrs := []string{"table:style-name", styles[i], "table:default-cell-style-name", "Default"}
+		if j-i > 1 {
+			attrs = append(attrs, "table:number-columns-repeated", strconv.Itoa(j-i))
+		}
+		if hidden[i] {
+			attrs = append(attrs, "table:visibility", "collapse")
+		}
+		w.x.Empty("table:table-column", attrs...)
+		i = j
+	}
+}
+
+func (w *writer) columnStyle(width *float64) string {
+	// the default column width of Excel is 8.43 characters
+	d := measurement.Distance(8.43 * measurement.Character)
+	if width != nil {
+		d = measurement.Distance(*width) * measurement.Character
+	}
+	return w.styles.Add("co", "table-column", "", odf.Props{Element: "style:table-column-properties",
+		Attrs: []string{"fo:break-before", "auto", "style:column-width", odf.Length(d)}})
+}
+
+func (w *writer) emptyRows(n, cols uint32) {
+	attrs := []string{"table:style-name", w.rowStyle(nil)}
+	if n > 1 {
+		attrs = append(attrs, "table:number-rows-repeated", strconv.Itoa(int(n)))
+	}
+	w.x.Start("table:table-row", attrs...)
+	w.emptyCells(cols)
+	w.x.End()
+}
+
+func (w *writer) emptyCells(n uint32) {
+	if n == 0 {
+		return
+	}
+	if n == 1 {
+		w.x.Empty("table:table-cell")
+		return
+	}
+	w.x.Empty("table:table-cell", "table:number-columns-repeated", strconv.Itoa(int(n)))
+}
+
+func (w *writer) rowStyle(r *sml.CT_Row) string {
+	height := "0.178in"
+	optimal := "true"
+	if r != nil && r.HtAttr != nil && r.CustomHeightAttr != nil && *r.CustomHeightAttr {
+		height = odf.Length(measurement.Distance(*r.HtAttr) * measurement.Point)
+		optimal = "false"
+	}
+	return w.styles.Add("ro", "table-row", "", odf.Props{Element: "style:table-row-properties",
+		Attrs: []string{"style:row-height", height, "fo:break-before", "auto", "style:use-optimal-row-height", optimal}})
+}
+
+func (w *writer) row(row spreadsheet.Row, cols uint32, merges map[cellPos]merge, covered map[cellPos]bool) {
+	attrs := []string{"table:style-name", w.rowStyle(row.X())}
+	if row.IsHidden() {
+		attrs = append(attrs, "table:visibility", "collapse")
+	}
+	w.x.Start("table:table-row", attrs...)
+	// row indexes of references are 1 based, column indexes 0 based
+	ri := row.RowNumber()
+	next := uint32(0)
+	cells := row.Cells()
+	sort.SliceStable(cells, func(i, j int) bool {
+		a, _ := reference.ParseCellReference(cells[i].Reference())
+		b, _ := reference.ParseCellReference(cells[j].Reference())
+		return a.ColumnIdx < b.ColumnIdx
+	})
+	gap := func(to uint32) {
+		for next < to {
+			if covered[cellPos{ri, next}] {
+				w.x.Empty("table:covered-table-cell")
+				next++
+				continue
+			}
+			start := next
+			for next < to && !covered[cellPos{ri, next}] {
+				if _, ok := merges[cellPos{ri, next}]; ok {
+					break
+				}
+				next++
+			}
+			w.emptyCells(next - start)
+			if m, ok := merges[cellPos{ri, next}]; ok && next < to {
+				w.x.Empty("table:table-cell", spanAttrs(m)...)
+				next++
+			}
+		}
+	}
+	for _, c := range cells {
+		ref, err := reference.ParseCellReference(c.Reference())
+		if err != nil || ref.ColumnIdx < next {
+			continue
+		}
+		gap(ref.ColumnIdx)
+		next = ref.ColumnIdx + 1
+		if covered[cellPos{ri, ref.ColumnIdx}] {
+			w.x.Empty("table:covered-table-cell")
+			continue
+		}
+		var attrs []string
+		if m, ok := merges[cellPos{ri, ref.ColumnIdx}]; ok {
+			attrs = spanAttrs(m)
+		}
+		w.cell(c, attrs)
+	}
+	gap(cols)
+	w.x.End()
+}
+
+func spanAttrs(m merge) []string {
+	return []string{"table:number-columns-spanned", strconv.Itoa(int(m.cols)),
+		"table:number-rows-spanned", strconv.Itoa(int(m.rows))}
+}
+
+func (w *writer) cell(c spreadsheet.Cell, attrs []string) {
+	x := c.X()
+	if x.SAttr != nil {
+		attrs = append(attrs, "table:style-name", w.cellStyle(*x.SAttr))
+	}
+	display := ""
+	switch {
+	case c.HasFormula():
+		attrs = append(attrs, "table:formula", formula.ToOpenFormula(c.GetFormula()))
+		cached := c.GetCachedFormulaResult()
+		switch x.TAttr {
+		case sml.ST_CellTypeB:
+			attrs = append(attrs, "office:value-type", "boolean", "office:boolean-value", strconv.FormatBool(cached == "1"))
+			display = strings.ToUpper(strconv.FormatBool(cached == "1"))
+		case sml.ST_CellTypeStr, sml.ST_CellTypeS, sml.ST_CellTypeE, sml.ST_CellTypeInlineStr:
+			attrs = append(attrs, "office:value-type", "string", "office:string-value", cached)
+			display = cached
+		default:
+			if _, err := strconv.ParseFloat(cached, 64); err == nil {
+				attrs = append(attrs, "office:value-type", "float", "office:value", cached)
+				display = c.GetFormattedValue()
+			}
+		}
+	case c.IsBool():
+		v, _ := c.GetValueAsBool()
+		attrs = append(attrs, "office:value-type", "boolean", "office:boolean-value", strconv.FormatBool(v))
+		display = strings.ToUpper(strconv.FormatBool(v))
+	case c.IsNumber():
+		v, err := c.GetValueAsNumber()
+		if err != nil {
+			break
+		}
+		display = c.GetFormattedValue()
+		if x.SAttr != nil && w.isDate(*x.SAttr) {
+			if t, err := c.GetValueAsTime(); err == nil {
+				attrs = append(attrs, "office:value-type", "date", "office:date-value", t.Format("2006-01-02T15:04:05"))
+				break
+			}
+		}
+		attrs = append(attrs, "office:value-type", "float", "office:value", strconv.FormatFloat(v, 'g', -1, 64))
+	case x.TAttr == sml.ST_CellTypeE:
+		display = c.GetFormattedValue()
+		attrs = append(attrs, "office:value-type", "string")
+	default:
+		display = c.GetString()
+		if display != "" {
+			attrs = append(attrs, "office:value-type", "string")
+		}
+	}
+	if display == "" {
+		w.x.Empty("table:table-cell", attrs...)
+		return
+	}
+	w.x.Start("table:table-cell", attrs...)
+	for _, line := range strings.Split(display, "\n") {
+		w.x.Start("text:p")
+		w.x.WriteText(line)
+		w.x.End()
+	}
+	w.x.End()
+}
+
+// isDate reports whether the number format of a cell style formats dates.
+func (w *writer) isDate(style uint32) bool {
+	cs := w.wb.StyleSheet.GetCellStyle(style)
+	if cs.IsEmpty() || !cs.HasNumberFormat() {
+		return false
+	}
+	id := cs.NumberFormat()
+	if (id >= 14 && id <= 22) || (id >= 45 && id <= 47) {
+		return true
+	}
+	if id < 164 {
+		return false
+	}
+	code := w.wb.StyleSheet.GetNumberFormat(id).GetFormat()
+	quoted := false
+	for _, c := range strings.ToLower(code) {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '[':
+			// elapsed time and colors, e.g. [h] or [Red]
+			return strings.Contains(code, "[h") || strings.Contains(code, "[m") || strings.Contains(code, "[s")
+		case c == 'y', c == 'd', c == 'h', c == 's':
+			return true
+		}
+	}
+	return false
+}
+
+// cellStyle returns the automatic style for a SpreadsheetML cell format.
+func (w *writer) cellStyle(idx uint32) string {
+	if name, ok := w.cellStyles[idx]; ok {
+		return name
+	}
+	cs := w.wb.StyleSheet.GetCellStyle(idx)
+	var cell, para, text []string
+	if !cs.IsEmpty() {
+		if font := cs.GetFont(); font != nil {
+			for _, fc := range font.FontChoice {
+				switch {
+				case fc.B != nil && (fc.B.ValAttr == nil || *fc.B.ValAttr):
+					text = append(text, "fo:font-weight", "bold")
+				case fc.I != nil && (fc.I.ValAttr == nil || *fc.I.ValAttr):
+					text = append(text, "fo:font-style", "italic")
+				case fc.Strike != nil && (fc.Strike.ValAttr == nil || *fc.Strike.ValAttr):
+					text = append(text, "style:text-line-through-style", "solid")
+				case fc.U != nil:
+					text = append(text, "style:text-underline-style", "solid", "style:text-underline-width", "auto",
+						"style:text-underline-color", "font-color")
+				case fc.Sz != nil:
+					text = append(text, "fo:font-size", strconv.FormatFloat(fc.Sz.ValAttr, 'f', -1, 64)+"pt")
+				case fc.Name != nil:
+					text = append(text, "fo:font-family", fc.Name.ValAttr)
+				case fc.Color != nil && fc.Color.RgbAttr != nil:
+					text = append(text, "fo:color", odf.HexColor(*fc.Color.RgbAttr))
+				}
+			}
+		}
+		if fill := cs.GetFill(); fill != nil && fill.FillChoice != nil && fill.FillChoice.PatternFill != nil {
+			pf := fill.FillChoice.PatternFill
+			if pf.PatternTypeAttr == sml.ST_PatternTypeSolid && pf.FgColor != nil && pf.FgColor.RgbAttr != nil {
+				cell = append(cell, "fo:background-color", odf.HexColor(*pf.FgColor.RgbAttr))
+			}
+		}
+		switch cs.GetHorizontalAlignment() {
+		case sml.ST_HorizontalAlignmentCenter, sml.ST_HorizontalAlignmentCenterContinuous:
+			para = append(para, "fo:text-align", "center")
+		case sml.ST_HorizontalAlignmentRight:
+			para = append(para, "fo:text-align", "end")
+		case sml.ST_HorizontalAlignmentLeft:
+			para = append(para, "fo:text-align", "start")
+		case sml.ST_HorizontalAlignmentJustify:
+			para = append(para, "fo:text-align", "justify")
+		}
+		if len(para) > 0 {
+			cell = append(cell, "style:text-align-source", "fix")
+		}
+		switch cs.GetVerticalAlignment() {
+		case sml.ST_VerticalAlignmentTop:
+			cell = append(cell, "style:vertical-align", "top")
+		case sml.ST_VerticalAlignmentCenter:
+			cell = append(cell, "style:vertical-align", "middle")
+		case sml.ST_VerticalAlignmentBottom:
+			cell = append(cell, "style:vertical-align", "bottom")
+		}
+		if cs.Wrapped() {
+			cell = append(cell, "fo:wrap-option", "wrap")
+		}
+	}
+	name := w.styles.Add("ce", "table-cell", "Default",
+		odf.Props{Element: "style:table-cell-properties", Attrs: cell},
+		odf.Props{Element: "style:paragraph-properties", Attrs: para},
+		odf.Props{Element: "style:text-properties", Attrs: text})
+	w.cellStyles[idx] = name
+	return name
+}
+
+// namedExpressions writes the workbook level defined names, cell ranges
+// become named ranges and other definitions named expressions.
+func (w *writer) namedExpressions() {
+	names := w.wb.DefinedNames()
+	if len(names) == 0 {
+		return
+	}
+	base := ""
+	if sheets := w.wb.Sheets(); len(sheets) > 0 {
+		// named expressions are relative to a base cell, use the first cell
+		base = cellAddress("'" + strings.ReplaceAll(sheets[0].Name(), "'", "''") + "'!$A$1")
+	}
+	w.x.Start("table:named-expressions")
+	for _, dn := range names {
+		if dn.X().LocalSheetIdAttr != nil || strings.HasPrefix(dn.Name(), "_xlnm.") {
+			continue
+		}
+		if rng := cellAddress(dn.Content()); rng != "" {
+			first := rng
+			if i := strings.Index(first, ":"); i >= 0 {
+				first = first[:i]
+			}
+			w.x.Empty("table:named-range", "table:name", dn.Name(), "table:base-cell-address", first,
+				"table:cell-range-address", rng)
+			continue
+		}
+		w.x.Empty("table:named-expression", "table:name", dn.Name(), "table:base-cell-address", base,
+			"table:expression", formula.ToOpenFormula(dn.Content()))
+	}
+	w.x.End()
+}
+
+// cellAddress converts a single Excel reference, e.g. Sheet1!$A$1:$B$2, to an
+// OpenDocument cell range address, other expressions yield "".
+func cellAddress(ref string) string {
+	expr := strings.TrimPrefix(formula.ToOpenFormula(ref), formula.OpenFormulaPrefix+"=")
+	if !strings.HasPrefix(expr, "[") || !strings.HasSuffix(expr, "]") || strings.Count(expr, "[") != 1 {
+		return ""
+	}
+	return expr[1 : len(expr)-1]
+}
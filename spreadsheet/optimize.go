@@ -0,0 +1,81 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package spreadsheet
+
+import (
+	"github.com/unidoc/unioffice/v2/common"
+	"github.com/unidoc/unioffice/v2/schema/soo/dml"
+	sd "github.com/unidoc/unioffice/v2/schema/soo/dml/spreadsheetDrawing"
+)
+
+// OptimizeImages reduces the size of the images of the workbook as described
+// for common.OptimizeImages. Pictures of drawings are downsampled to their
+// displayed size when it is known from the picture or its anchor.
+func (wb *Workbook) OptimizeImages(opts common.ImageOptimizeOptions) (common.ImageOptimizeResult, error) {
+	rels := []common.Relationships{wb._ffea}
+	rels = append(rels, wb._ade...)
+	rels = append(rels, wb._aaga...)
+	placements := []common.ImagePlacement{}
+	for i, dr := range wb._gceda {
+		for _, a := range dr.EG_Anchor {
+			if a.AnchorChoice == nil {
+				continue
+			}
+			var obj *sd.EG_ObjectChoicesChoice
+			var ext *dml.CT_PositiveSize2D
+			switch ac := a.AnchorChoice; {
+			case ac.TwoCellAnchor != nil:
+				obj = ac.TwoCellAnchor.ObjectChoicesChoice
+			case ac.OneCellAnchor != nil:
+				obj, ext = ac.OneCellAnchor.ObjectChoicesChoice, ac.OneCellAnchor.Ext
+			case ac.AbsoluteAnchor != nil:
+				obj, ext = ac.AbsoluteAnchor.ObjectChoicesChoice, ac.AbsoluteAnchor.Ext
+			}
+			if obj == nil {
+				continue
+			}
+			if obj.Pic != nil {
+				placements = drawingPicturePlacement(placements, obj.Pic, ext, wb._aaga[i])
+			}
+			if obj.GrpSp != nil {
+				placements = groupPicturePlacements(placements, obj.GrpSp.GroupShapeChoice, wb._aaga[i])
+			}
+		}
+	}
+	return common.OptimizeImages(&wb.DocBase, rels, placements, opts)
+}
+
+func groupPicturePlacements(placements []common.ImagePlacement, shapes []*sd.CT_GroupShapeChoice, rels common.Relationships) []common.ImagePlacement {
+	for _, s := range shapes {
+		if s.GrpSp != nil {
+			placements = groupPicturePlacements(placements, s.GrpSp.GroupShapeChoice, rels)
+		}
+		if s.Pic != nil {
+			placements = drawingPicturePlacement(placements, s.Pic, nil, rels)
+		}
+	}
+	return placements
+}
+
+// drawingPicturePlacement adds the placement of pic, using the extent of its
+// anchor ext if the picture has no size of its own.
+func drawingPicturePlacement(placements []common.ImagePlacement, pic *sd.CT_Picture, ext *dml.CT_PositiveSize2D, rels common.Relationships) []common.ImagePlacement {
+	if pic.BlipFill == nil || pic.BlipFill.Blip == nil || pic.BlipFill.Blip.EmbedAttr == nil {
+		return placements
+	}
+	if pic.SpPr != nil && pic.SpPr.Xfrm != nil && pic.SpPr.Xfrm.Ext != nil {
+		ext = pic.SpPr.Xfrm.Ext
+	}
+	pl := common.ImagePlacement{Rels: rels, RelID: *pic.BlipFill.Blip.EmbedAttr, BlipFill: pic.BlipFill}
+	if ext != nil {
+		pl.Width, pl.Height = ext.CxAttr, ext.CyAttr
+	}
+	return append(placements, pl)
+}
@@ -0,0 +1,169 @@
+//
+// Copyright 2020 FoxyUtils ehf. All rights reserved.
+//
+// This is a commercial product and requires a license to operate.
+// A trial license can be obtained at https://unidoc.io
+//
+// Use of this source code is governed by the UniDoc End User License Agreement
+// terms that can be accessed at https://unidoc.io/eula/
+
+package spreadsheet
+
+import (
+	"strings"
+
+	"github.com/unidoc/unioffice/v2/common"
+	sd "github.com/unidoc/unioffice/v2/schema/soo/dml/spreadsheetDrawing"
+	"github.com/unidoc/unioffice/v2/schema/soo/sml"
+)
+
+// Redact removes or masks the text matching opts in shared and inline strings,
+// cached string results of formulas, hyperlink texts and tooltips, comments
+// and their authors, the text and alternative text of drawings, the document
+// properties and custom XML parts. Formulas are not changed.
+//
+// The revision history of shared workbooks is removed, so redacted text can't
+// be recovered from it. It returns the number of matches redacted.
+func (wb *Workbook) Redact(opts common.RedactOptions) (int, error) {
+	r, err := common.NewRedactor(opts)
+	if err != nil {
+		return 0, err
+	}
+	if sst := wb.SharedStrings.X(); sst != nil {
+		for _, si := range sst.Si {
+			redactRst(r, si)
+		}
+		// rebuild the lookup of plain strings, which holds the original text
+		for k := range wb.SharedStrings._fbdc {
+			delete(wb.SharedStrings._fbdc, k)
+		}
+		for i, si := range sst.Si {
+			if si.T != nil && len(si.R) == 0 {
+				if _, ok := wb.SharedStrings._fbdc[*si.T]; !ok {
+					wb.SharedStrings._fbdc[*si.T] = i
+				}
+			}
+		}
+	}
+	for _, ws := range wb._fadc {
+		if ws.SheetData != nil {
+			for _, row := range ws.SheetData.Row {
+				for _, c := range row.C {
+					redactRst(r, c.Is)
+					if c.TAttr == sml.ST_CellTypeStr {
+						r.StringPtr(c.V)
+					}
+				}
+			}
+		}
+		if ws.Hyperlinks != nil {
+			for _, hl := range ws.Hyperlinks.Hyperlink {
+				r.StringPtr(hl.DisplayAttr)
+				r.StringPtr(hl.TooltipAttr)
+			}
+		}
+	}
+	for _, cmts := range wb._gfcbc {
+		if cmts.Authors != nil {
+			for i := range cmts.Authors.Author {
+				cmts.Authors.Author[i] = r.String(cmts.Authors.Author[i])
+			}
+		}
+		if cmts.CommentList != nil {
+			for _, c := range cmts.CommentList.Comment {
+				redactRst(r, c.Text)
+			}
+		}
+	}
+	for _, dr := range wb._gceda {
+		for _, a := range dr.EG_Anchor {
+			if a.AnchorChoice == nil {
+				continue
+			}
+			switch ac := a.AnchorChoice; {
+			case ac.TwoCellAnchor != nil:
+				redactObject(r, ac.TwoCellAnchor.ObjectChoicesChoice)
+			case ac.OneCellAnchor != nil:
+				redactObject(r, ac.OneCellAnchor.ObjectChoicesChoice)
+			case ac.AbsoluteAnchor != nil:
+				redactObject(r, ac.AbsoluteAnchor.ObjectChoicesChoice)
+			}
+		}
+	}
+	wb.removeRevisions()
+	if err := r.DocBase(&wb.DocBase); err != nil {
+		return r.Count, err
+	}
+	return r.Count, nil
+}
+
+// redactRst redacts rich text, matches may span runs.
+func redactRst(r *common.Redactor, rst *sml.CT_Rst) {
+	if rst == nil {
+		return
+	}
+	r.StringPtr(rst.T)
+	segs := []*string{}
+	for _, run := range rst.R {
+		segs = append(segs, &run.T)
+	}
+	r.Segments(segs)
+	for _, ph := range rst.RPh {
+		ph.T = r.String(ph.T)
+	}
+}
+
+func redactObject(r *common.Redactor, obj *sd.EG_ObjectChoicesChoice) {
+	if obj == nil {
+		return
+	}
+	redactShapes(r, []*sd.CT_GroupShapeChoice{{Sp: obj.Sp, GrpSp: obj.GrpSp, GraphicFrame: obj.GraphicFrame, CxnSp: obj.CxnSp, Pic: obj.Pic}})
+}
+
+func redactShapes(r *common.Redactor, shapes []*sd.CT_GroupShapeChoice) {
+	for _, s := range shapes {
+		switch {
+		case s.Sp != nil:
+			if s.Sp.NvSpPr != nil {
+				r.DrawingProps(s.Sp.NvSpPr.CNvPr)
+			}
+			r.TextBody(s.Sp.TxBody)
+		case s.GrpSp != nil:
+			if s.GrpSp.NvGrpSpPr != nil {
+				r.DrawingProps(s.GrpSp.NvGrpSpPr.CNvPr)
+			}
+			redactShapes(r, s.GrpSp.GroupShapeChoice)
+		case s.GraphicFrame != nil:
+			if s.GraphicFrame.NvGraphicFramePr != nil {
+				r.DrawingProps(s.GraphicFrame.NvGraphicFramePr.CNvPr)
+			}
+		case s.CxnSp != nil:
+			if s.CxnSp.NvCxnSpPr != nil {
+				r.DrawingProps(s.CxnSp.NvCxnSpPr.CNvPr)
+			}
+		case s.Pic != nil:
+			if s.Pic.NvPicPr != nil {
+				r.DrawingProps(s.Pic.NvPicPr.CNvPr)
+			}
+		}
+	}
+}
+
+// removeRevisions removes the revision log and user list of a shared
+// workbook.
+func (wb *Workbook) removeRevisions() {
+	for _, rel := range wb._ffea.Relationships() {
+		if strings.HasSuffix(rel.Type(), "/revisionHeaders") || strings.HasSuffix(rel.Type(), "/usernames") {
+			wb._ffea.Remove(rel)
+		}
+	}
+	files := wb.ExtraFiles[:0]
+	for _, ef := range wb.ExtraFiles {
+		if strings.HasPrefix(ef.ZipPath, "xl/revisions/") {
+			wb.ContentTypes.RemoveOverride(ef.ZipPath)
+			continue
+		}
+		files = append(files, ef)
+	}
+	wb.ExtraFiles = files
+}
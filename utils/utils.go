@@ -9,11 +9,10 @@
 // Use of this source code is governed by the UniDoc End User License Agreement
 // terms that can be accessed at https://unidoc.io/eula/
 
-package utils ;import (_d "bytes";_f "github.com/unidoc/unioffice/v2/document";_ef "github.com/unidoc/unioffice/v2/document/convert";_b "github.com/unidoc/unipdf/v4/model";);
+package utils ;import (_f "github.com/unidoc/unioffice/v2/document";_ef "github.com/unidoc/unioffice/v2/document/convert";);
 
-// GetNumPages will try to get actual document page count by converting the document to a PDF first
-// and then get the actual page count from the PDF result.
+// GetNumPages will try to get actual document page count by laying out the document with the
+// same engine as the PDF conversion, without producing a PDF.
 //
-// WARNING: This method is currently in experimental state as the PDF result might have incorrect page count.
-func GetNumPages (d *_f .Document )(int ,error ){var _a _d .Buffer ;_db :=_ef .ConvertToPdf (d );if _g :=_db .Write (&_a );_g !=nil {return 0,_g ;};_c ,_ca :=_b .NewPdfReader (_d .NewReader (_a .Bytes ()));if _ca !=nil {return 0,_ca ;};_ea ,_ca :=_c .GetNumPages ();
-if _ca !=nil {return 0,_ca ;};return _ea ,nil ;};
\ No newline at end of file
+// WARNING: This method is currently in experimental state as the layout might have incorrect page count.
+func GetNumPages (d *_f .Document )(int ,error ){return _ef .Paginate (d ,nil ).NumPages (),nil ;};
\ No newline at end of file